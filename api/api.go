@@ -43,6 +43,11 @@ func (a *API) RegisterRoutes(router forge.Router) error {
 		a.registerPolicyRoutes,
 		a.registerResourceTypeRoutes,
 		a.registerCheckLogRoutes,
+		a.registerAuditRoutes,
+		a.registerBreakGlassRoutes,
+		a.registerCalendarRoutes,
+		a.registerSoDRoutes,
+		a.registerPluginRoutes,
 	}
 	for _, fn := range registerers {
 		if err := fn(router); err != nil {
@@ -12,7 +12,7 @@ import (
 )
 
 func (a *API) registerAssignmentRoutes(router forge.Router) error {
-	g := router.Group("/v1", forge.WithGroupTags("assignments"))
+	g := router.Group("/v1", forge.WithGroupTags("assignments"), forge.WithGroupMiddleware(auditContext))
 
 	if err := g.POST("/assignments", a.assignRole,
 		forge.WithSummary("Assign role"),
@@ -6,6 +6,7 @@ import (
 	"github.com/xraph/forge"
 
 	"github.com/xraph/warden"
+	"github.com/xraph/warden/middleware"
 )
 
 // AuthZEN Authorization API 1.0 information model.
@@ -93,7 +94,7 @@ func (a *API) registerAuthZenRoutes(router forge.Router) error {
 }
 
 func (a *API) authzenEvaluation(ctx forge.Context, req *AuthZenEvaluationRequest) (*AuthZenEvaluationResponse, error) {
-	result, err := a.eng.Check(ctx.Context(), authzenToCheckRequest(req))
+	result, err := a.eng.Check(middleware.RequestContext(ctx), authzenToCheckRequest(req))
 	if err != nil {
 		return nil, mapError(err)
 	}
@@ -108,10 +109,11 @@ func (a *API) authzenEvaluations(ctx forge.Context, req *AuthZenEvaluationsReque
 		return nil, verr
 	}
 
+	reqCtx := middleware.RequestContext(ctx)
 	out := make([]AuthZenEvaluationResponse, len(req.Evaluations))
 	for i := range req.Evaluations {
 		item := resolveAuthZenDefaults(req, req.Evaluations[i])
-		result, err := a.eng.Check(ctx.Context(), authzenToCheckRequest(&item))
+		result, err := a.eng.Check(reqCtx, authzenToCheckRequest(&item))
 		if err != nil {
 			return nil, mapError(err)
 		}
@@ -197,6 +199,12 @@ func authzenToResponse(r *warden.CheckResult) *AuthZenEvaluationResponse {
 	if len(r.Obligations) > 0 {
 		rctx["obligations"] = r.Obligations
 	}
+	if len(r.StructuredObligations) > 0 {
+		rctx["structured_obligations"] = r.StructuredObligations
+	}
+	if len(r.Advice) > 0 {
+		rctx["advice"] = r.Advice
+	}
 	if len(r.MatchedBy) > 0 {
 		matched := make([]map[string]string, 0, len(r.MatchedBy))
 		for _, m := range r.MatchedBy {
@@ -77,6 +77,10 @@ func TestAuthzenToResponse_SurfacesDecisionAndReason(t *testing.T) {
 		Decision:    warden.DecisionDenyExplicit,
 		Reason:      "explicit deny policy",
 		Obligations: []string{"audit-log"},
+		StructuredObligations: []warden.Obligation{
+			{Name: "notify", Params: map[string]any{"channel": "#sec"}},
+		},
+		Advice: []warden.Obligation{{Name: "contact_owner"}},
 	})
 	if deny.Decision {
 		t.Fatal("expected decision false for denied result")
@@ -87,6 +91,12 @@ func TestAuthzenToResponse_SurfacesDecisionAndReason(t *testing.T) {
 	if obs, ok := deny.Context["obligations"].([]string); !ok || len(obs) != 1 {
 		t.Fatalf("obligations not surfaced: %+v", deny.Context)
 	}
+	if obs, ok := deny.Context["structured_obligations"].([]warden.Obligation); !ok || obs[0].Params["channel"] != "#sec" {
+		t.Fatalf("structured obligations not surfaced: %+v", deny.Context)
+	}
+	if adv, ok := deny.Context["advice"].([]warden.Obligation); !ok || adv[0].Name != "contact_owner" {
+		t.Fatalf("advice not surfaced: %+v", deny.Context)
+	}
 }
 
 func TestResolveAuthZenDefaults_InheritsAndMergesContext(t *testing.T) {
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xraph/forge"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/middleware"
+)
+
+func (a *API) registerBreakGlassRoutes(router forge.Router) error {
+	g := router.Group("/v1", forge.WithGroupTags("break-glass"), forge.WithGroupMiddleware(auditContext))
+
+	if err := g.POST("/break-glass", a.breakGlass,
+		forge.WithSummary("Break glass"),
+		forge.WithDescription("Grants the authenticated user time-boxed emergency access: eligible roles and bypasses of eligible deny policies. A justification is required; checks served by the grant carry a break_glass obligation."),
+		forge.WithOperationID("wardenBreakGlass"),
+		forge.WithRequestSchema(BreakGlassRequest{}),
+		forge.WithCreatedResponse(&breakglass.Grant{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	if err := g.POST("/break-glass/:grantId/revoke", a.revokeBreakGlass,
+		forge.WithSummary("Revoke break-glass grant"),
+		forge.WithOperationID("wardenRevokeBreakGlass"),
+		forge.WithRequestSchema(GetBreakGlassRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Revoked grant", &breakglass.Grant{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	return g.GET("/break-glass", a.listBreakGlass,
+		forge.WithSummary("List break-glass grants"),
+		forge.WithDescription("Returns break-glass grants, newest first."),
+		forge.WithOperationID("wardenListBreakGlass"),
+		forge.WithRequestSchema(ListBreakGlassRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Grant list", []*breakglass.Grant{}),
+		forge.WithErrorResponses(),
+	)
+}
+
+// breakGlass grants the authenticated user emergency access. The subject
+// is never taken from the request, so a caller cannot break glass on
+// behalf of another subject.
+func (a *API) breakGlass(ctx forge.Context, req *BreakGlassRequest) (*breakglass.Grant, error) {
+	reqCtx := middleware.RequestContext(ctx)
+	userID := forge.UserIDFromContext(reqCtx)
+	if userID == "" {
+		return nil, forge.Unauthorized("break glass requires an authenticated user")
+	}
+
+	var duration time.Duration
+	{
+		verr := forge.NewValidationErrors()
+		if req.Justification == "" {
+			verr.AddWithCode("justification", "justification is required", "REQUIRED", nil)
+		}
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil || d <= 0 {
+				verr.AddWithCode("duration", "duration must be a positive duration such as 30m", "FORMAT", req.Duration)
+			}
+			duration = d
+		}
+		if verr.HasErrors() {
+			return nil, verr
+		}
+	}
+
+	g, err := a.eng.BreakGlass(reqCtx, &warden.BreakGlassRequest{
+		Subject:        warden.Subject{Kind: warden.SubjectUser, ID: userID},
+		Justification:  req.Justification,
+		Duration:       duration,
+		Roles:          req.Roles,
+		BypassPolicies: req.BypassPolicies,
+		NamespacePath:  req.NamespacePath,
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return g, ctx.JSON(http.StatusCreated, g)
+}
+
+func (a *API) revokeBreakGlass(ctx forge.Context, _ *GetBreakGlassRequest) (*breakglass.Grant, error) {
+	grantID, err := id.ParseBreakGlassID(ctx.Param("grantId"))
+	if err != nil {
+		return nil, forge.BadRequest(fmt.Sprintf("invalid grant ID: %v", err))
+	}
+
+	reqCtx := middleware.RequestContext(ctx)
+	g, err := a.eng.RevokeBreakGlass(reqCtx, grantID, forge.UserIDFromContext(reqCtx))
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return g, ctx.JSON(http.StatusOK, g)
+}
+
+func (a *API) listBreakGlass(ctx forge.Context, req *ListBreakGlassRequest) (*BreakGlassListResponse, error) {
+	_, tenantID := scopeFromForgeContext(ctx)
+	filter := &breakglass.ListFilter{
+		TenantID:    tenantID,
+		SubjectKind: req.SubjectKind,
+		SubjectID:   req.SubjectID,
+		Limit:       defaultLimit(req.Limit),
+		Offset:      req.Offset,
+	}
+	if req.Active == "true" {
+		now := time.Now()
+		filter.ActiveAt = &now
+	}
+
+	grants, err := a.eng.Store().ListBreakGlassGrants(ctx.Context(), filter)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &BreakGlassListResponse{Body: grants}, nil
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/store/memory"
+)
+
+func TestRevokeBreakGlass_OtherTenantNotFound(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	eng, err := warden.NewEngine(warden.WithStore(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	g := &breakglass.Grant{
+		ID: id.NewBreakGlassID(), TenantID: "t1",
+		SubjectKind: string(warden.SubjectUser), SubjectID: "oncall-1",
+		Justification: "incident", ExpiresAt: now.Add(time.Hour), CreatedAt: now,
+	}
+	if err := s.CreateBreakGlassGrant(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	h := New(eng, nil).Handler()
+
+	revoke := func(tenantID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/break-glass/"+g.ID.String()+"/revoke", nil)
+		req = req.WithContext(warden.WithTenant(req.Context(), "app1", tenantID))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := revoke("t2"); code != http.StatusNotFound {
+		t.Fatalf("expected another tenant's revoke to be 404, got %d", code)
+	}
+	got, err := s.GetBreakGlassGrant(ctx, g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RevokedAt != nil {
+		t.Fatal("expected the grant to stay active after another tenant's revoke")
+	}
+
+	if code := revoke("t1"); code != http.StatusOK {
+		t.Fatalf("expected the grant's own tenant to revoke it, got %d", code)
+	}
+}
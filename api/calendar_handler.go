@@ -0,0 +1,198 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xraph/forge"
+
+	"github.com/xraph/warden/calendar"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/schedule"
+)
+
+func (a *API) registerCalendarRoutes(router forge.Router) error {
+	g := router.Group("/v1", forge.WithGroupTags("calendars"), forge.WithGroupMiddleware(auditContext))
+
+	if err := g.POST("/calendars", a.createCalendar,
+		forge.WithSummary("Create calendar"),
+		forge.WithDescription("Creates a named calendar of dates, such as public holidays, for in_calendar policy conditions."),
+		forge.WithOperationID("wardenCreateCalendar"),
+		forge.WithRequestSchema(CreateCalendarRequest{}),
+		forge.WithCreatedResponse(&calendar.Calendar{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	if err := g.GET("/calendars/:calendarId", a.getCalendar,
+		forge.WithSummary("Get calendar"),
+		forge.WithOperationID("wardenGetCalendar"),
+		forge.WithRequestSchema(GetCalendarRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Calendar details", &calendar.Calendar{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	if err := g.PUT("/calendars/:calendarId", a.updateCalendar,
+		forge.WithSummary("Update calendar"),
+		forge.WithOperationID("wardenUpdateCalendar"),
+		forge.WithRequestSchema(UpdateCalendarRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Updated calendar", &calendar.Calendar{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	if err := g.DELETE("/calendars/:calendarId", a.deleteCalendar,
+		forge.WithSummary("Delete calendar"),
+		forge.WithDescription("Deletes a calendar. Conditions that still name it become indeterminate."),
+		forge.WithOperationID("wardenDeleteCalendar"),
+		forge.WithRequestSchema(GetCalendarRequest{}),
+		forge.WithNoContentResponse(),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	return g.GET("/calendars", a.listCalendars,
+		forge.WithSummary("List calendars"),
+		forge.WithDescription("Lists the tenant's calendars by name."),
+		forge.WithOperationID("wardenListCalendars"),
+		forge.WithRequestSchema(ListCalendarsRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Calendar list", []*calendar.Calendar{}),
+		forge.WithErrorResponses(),
+	)
+}
+
+func (a *API) createCalendar(ctx forge.Context, req *CreateCalendarRequest) (*calendar.Calendar, error) {
+	{
+		verr := forge.NewValidationErrors()
+		if req.Name == "" {
+			verr.AddWithCode("name", "name is required", "REQUIRED", nil)
+		}
+		validateCalendar(verr, req.TimeZone, req.Dates)
+		if verr.HasErrors() {
+			return nil, verr
+		}
+	}
+
+	appID, tenantID := scopeFromForgeContext(ctx)
+	now := time.Now()
+	c := &calendar.Calendar{
+		ID:          id.NewCalendarID(),
+		TenantID:    tenantID,
+		AppID:       appID,
+		Name:        req.Name,
+		Description: req.Description,
+		TimeZone:    req.TimeZone,
+		Dates:       req.Dates,
+		Metadata:    req.Metadata,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := a.eng.Store().CreateCalendar(ctx.Context(), c); err != nil {
+		return nil, mapError(err)
+	}
+
+	return c, ctx.JSON(http.StatusCreated, c)
+}
+
+func (a *API) getCalendar(ctx forge.Context, _ *GetCalendarRequest) (*calendar.Calendar, error) {
+	calID, err := id.ParseCalendarID(ctx.Param("calendarId"))
+	if err != nil {
+		return nil, forge.BadRequest(fmt.Sprintf("invalid calendar ID: %v", err))
+	}
+
+	c, err := a.eng.Store().GetCalendar(ctx.Context(), calID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return c, ctx.JSON(http.StatusOK, c)
+}
+
+func (a *API) updateCalendar(ctx forge.Context, req *UpdateCalendarRequest) (*calendar.Calendar, error) {
+	calID, err := id.ParseCalendarID(ctx.Param("calendarId"))
+	if err != nil {
+		return nil, forge.BadRequest(fmt.Sprintf("invalid calendar ID: %v", err))
+	}
+
+	c, err := a.eng.Store().GetCalendar(ctx.Context(), calID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	if req.Name != "" {
+		c.Name = req.Name
+	}
+	if req.Description != "" {
+		c.Description = req.Description
+	}
+	if req.TimeZone != nil {
+		c.TimeZone = *req.TimeZone
+	}
+	if req.Dates != nil {
+		c.Dates = req.Dates
+	}
+	if req.Metadata != nil {
+		c.Metadata = req.Metadata
+	}
+	{
+		verr := forge.NewValidationErrors()
+		validateCalendar(verr, c.TimeZone, c.Dates)
+		if verr.HasErrors() {
+			return nil, verr
+		}
+	}
+
+	if err := a.eng.Store().UpdateCalendar(ctx.Context(), c); err != nil {
+		return nil, mapError(err)
+	}
+
+	return c, ctx.JSON(http.StatusOK, c)
+}
+
+func (a *API) deleteCalendar(ctx forge.Context, _ *GetCalendarRequest) (*struct{}, error) {
+	calID, err := id.ParseCalendarID(ctx.Param("calendarId"))
+	if err != nil {
+		return nil, forge.BadRequest(fmt.Sprintf("invalid calendar ID: %v", err))
+	}
+
+	if err := a.eng.Store().DeleteCalendar(ctx.Context(), calID); err != nil {
+		return nil, mapError(err)
+	}
+
+	return nil, ctx.NoContent(http.StatusNoContent)
+}
+
+func (a *API) listCalendars(ctx forge.Context, req *ListCalendarsRequest) (*CalendarListResponse, error) {
+	_, tenantID := scopeFromForgeContext(ctx)
+	filter := &calendar.ListFilter{
+		TenantID: tenantID,
+		Search:   req.Search,
+		Limit:    defaultLimit(req.Limit),
+		Offset:   req.Offset,
+	}
+
+	cals, err := a.eng.Store().ListCalendars(ctx.Context(), filter)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &CalendarListResponse{Body: cals}, nil
+}
+
+// validateCalendar records an unknown time zone or a malformed date.
+func validateCalendar(verr *forge.ValidationErrors, timeZone string, dates []calendar.Date) {
+	if _, err := schedule.LoadLocation(timeZone); err != nil {
+		verr.AddWithCode("time_zone", err.Error(), "INVALID", timeZone)
+	}
+	for i, d := range dates {
+		if err := d.Validate(); err != nil {
+			verr.AddWithCode(fmt.Sprintf("dates[%d].date", i), err.Error(), "FORMAT", d.Date)
+		}
+	}
+}
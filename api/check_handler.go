@@ -1,12 +1,16 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/xraph/forge"
 
 	"github.com/xraph/warden"
+	"github.com/xraph/warden/dsl"
+	"github.com/xraph/warden/middleware"
 )
 
 func (a *API) registerCheckRoutes(router forge.Router) error {
@@ -34,13 +38,24 @@ func (a *API) registerCheckRoutes(router forge.Router) error {
 		return err
 	}
 
-	return g.POST("/batch-check", a.batchCheck,
+	if err := g.POST("/batch-check", a.batchCheck,
 		forge.WithSummary("Batch authorization check"),
 		forge.WithDescription("Evaluates multiple authorization checks in one request."),
 		forge.WithOperationID("authzBatchCheck"),
 		forge.WithRequestSchema(BatchCheckRequest{}),
 		forge.WithResponseSchema(http.StatusOK, "Batch results", BatchCheckResponse{}),
 		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	return g.POST("/simulate", a.simulate,
+		forge.WithSummary("Simulate a model change"),
+		forge.WithDescription("Replays recorded checks (or the supplied checks) against the current model and against the model with the proposed .warden changes applied, and reports every decision that would flip. Nothing is written."),
+		forge.WithOperationID("authzSimulate"),
+		forge.WithRequestSchema(SimulateRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Simulation report", warden.SimulationReport{}),
+		forge.WithErrorResponses(),
 	)
 }
 
@@ -49,7 +64,7 @@ func (a *API) check(ctx forge.Context, req *CheckRequest) (*CheckResponse, error
 		return nil, err
 	}
 
-	result, err := a.eng.Check(ctx.Context(), toCheckRequest(req))
+	result, err := a.eng.Check(middleware.RequestContext(ctx), toCheckRequest(req))
 	if err != nil {
 		return nil, mapError(err)
 	}
@@ -63,7 +78,7 @@ func (a *API) enforce(ctx forge.Context, req *CheckRequest) (*CheckResponse, err
 		return nil, err
 	}
 
-	result, err := a.eng.Check(ctx.Context(), toCheckRequest(req))
+	result, err := a.eng.Check(middleware.RequestContext(ctx), toCheckRequest(req))
 	if err != nil {
 		return nil, mapError(err)
 	}
@@ -88,9 +103,10 @@ func (a *API) batchCheck(ctx forge.Context, req *BatchCheckRequest) (*BatchCheck
 		}
 	}
 
+	reqCtx := middleware.RequestContext(ctx)
 	results := make([]CheckResponse, len(req.Checks))
 	for i, c := range req.Checks {
-		result, err := a.eng.Check(ctx.Context(), toCheckRequest(&c))
+		result, err := a.eng.Check(reqCtx, toCheckRequest(&c))
 		if err != nil {
 			return nil, mapError(err)
 		}
@@ -101,6 +117,63 @@ func (a *API) batchCheck(ctx forge.Context, req *BatchCheckRequest) (*BatchCheck
 	return resp, ctx.JSON(http.StatusOK, resp)
 }
 
+func (a *API) simulate(ctx forge.Context, req *SimulateRequest) (*SimulationResponse, error) {
+	verr := forge.NewValidationErrors()
+	if strings.TrimSpace(req.Source) == "" {
+		verr.AddWithCode("source", "source is required", "REQUIRED", nil)
+	}
+	if req.Limit < 0 {
+		verr.AddWithCode("limit", "limit cannot be negative", "MIN", req.Limit)
+	}
+	if verr.HasErrors() {
+		return nil, verr
+	}
+	for i := range req.Requests {
+		if err := validateCheckRequestAt(&req.Requests[i], fmt.Sprintf("requests[%d]", i)); err != nil {
+			return nil, err
+		}
+	}
+
+	prog, diags := dsl.Parse("changes.warden", []byte(req.Source))
+	if len(diags) == 0 {
+		diags = dsl.Resolve(prog)
+	}
+	if len(diags) > 0 {
+		return nil, forge.BadRequest((&dsl.DiagnosticError{Diags: diags}).Error())
+	}
+	_, tenantID := scopeFromForgeContext(ctx)
+	changes := dsl.SimulationChanges(prog, dsl.ApplyOptions{TenantID: tenantID, Prune: req.Prune})
+
+	reqCtx := middleware.RequestContext(ctx)
+	var (
+		rep *warden.SimulationReport
+		err error
+	)
+	if len(req.Requests) > 0 {
+		checks := make([]*warden.CheckRequest, len(req.Requests))
+		for i := range req.Requests {
+			checks[i] = toCheckRequest(&req.Requests[i])
+		}
+		rep, err = a.eng.SimulateRequests(reqCtx, changes, checks)
+	} else {
+		filter, ferr := checkLogFilter(tenantID, &ListCheckLogsRequest{After: req.After, Before: req.Before})
+		if ferr != nil {
+			return nil, ferr
+		}
+		filter.Limit = req.Limit
+		rep, err = a.eng.Simulate(reqCtx, changes, filter)
+	}
+	if err != nil {
+		var derr *dsl.DiagnosticError
+		if errors.As(err, &derr) {
+			return nil, forge.BadRequest(derr.Error())
+		}
+		return nil, mapError(err)
+	}
+
+	return &SimulationResponse{Body: rep}, nil
+}
+
 func validateCheckRequest(req *CheckRequest) error {
 	return validateCheckRequestAt(req, "")
 }
@@ -130,7 +203,7 @@ func validateCheckRequestAt(req *CheckRequest, prefix string) error {
 
 func toCheckRequest(r *CheckRequest) *warden.CheckRequest {
 	return &warden.CheckRequest{
-		Subject:  warden.Subject{Kind: warden.SubjectKind(r.SubjectKind), ID: r.SubjectID},
+		Subject:  warden.Subject{Kind: warden.SubjectKind(r.SubjectKind), ID: r.SubjectID, ActiveRoles: r.ActiveRoles},
 		Action:   warden.Action{Name: r.Action},
 		Resource: warden.Resource{Type: r.ResourceType, ID: r.ResourceID},
 		Context:  r.Context,
@@ -147,9 +220,10 @@ func toCheckResponse(r *warden.CheckResult) *CheckResponse {
 	}
 	for _, m := range r.MatchedBy {
 		resp.MatchedBy = append(resp.MatchedBy, MatchInfo{
-			Source: m.Source,
-			RuleID: m.RuleID,
-			Detail: m.Detail,
+			Source:     m.Source,
+			RuleID:     m.RuleID,
+			Detail:     m.Detail,
+			Permission: m.Permission,
 		})
 	}
 	return resp
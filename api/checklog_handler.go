@@ -12,27 +12,93 @@ import (
 func (a *API) registerCheckLogRoutes(router forge.Router) error {
 	g := router.Group("/v1", forge.WithGroupTags("check-logs"))
 
-	return g.GET("/check-logs", a.listCheckLogs,
+	if err := g.GET("/check-logs", a.listCheckLogs,
 		forge.WithSummary("Query check logs"),
 		forge.WithDescription("Returns authorization check audit logs with optional filters."),
 		forge.WithOperationID("listCheckLogs"),
 		forge.WithRequestSchema(ListCheckLogsRequest{}),
 		forge.WithResponseSchema(http.StatusOK, "Check log list", []*checklog.Entry{}),
 		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	return g.GET("/check-logs/stats", a.checkLogStats,
+		forge.WithSummary("Check log statistics"),
+		forge.WithDescription("Aggregates check logs into decision counts over time buckets, evaluation latency percentiles and the most denied subjects and resources."),
+		forge.WithOperationID("checkLogStats"),
+		forge.WithRequestSchema(CheckLogStatsRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Check log statistics", checklog.Stats{}),
+		forge.WithErrorResponses(),
 	)
 }
 
 func (a *API) listCheckLogs(ctx forge.Context, req *ListCheckLogsRequest) (*CheckLogListResponse, error) {
 	_, tenantID := scopeFromForgeContext(ctx)
+	filter, err := checkLogFilter(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = defaultLimit(req.Limit)
+	filter.Offset = req.Offset
+
+	logs, err := a.eng.Store().ListCheckLogs(ctx.Context(), filter)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &CheckLogListResponse{Body: logs}, nil
+}
+
+func (a *API) checkLogStats(ctx forge.Context, req *CheckLogStatsRequest) (*CheckLogStatsResponse, error) {
+	_, tenantID := scopeFromForgeContext(ctx)
+	filter, err := checkLogFilter(tenantID, req.listRequest())
+	if err != nil {
+		return nil, err
+	}
+	q := &checklog.StatsQuery{
+		Filter:  *filter,
+		GroupBy: checklog.Dimension(req.GroupBy),
+		TopN:    req.Top,
+	}
+	if req.Bucket != "" {
+		q.Bucket, err = time.ParseDuration(req.Bucket)
+		if err != nil {
+			return nil, forge.BadRequest("invalid bucket duration")
+		}
+	}
+	if err := q.Normalize(); err != nil {
+		return nil, forge.BadRequest(err.Error())
+	}
+
+	stats, err := checklog.QueryStats(ctx.Context(), a.eng.Store(), q)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &CheckLogStatsResponse{Body: stats}, nil
+}
+
+// checkLogFilter maps list query parameters onto a QueryFilter scoped to
+// tenantID. Limit and Offset are left to the caller.
+func checkLogFilter(tenantID string, req *ListCheckLogsRequest) (*checklog.QueryFilter, error) {
 	filter := &checklog.QueryFilter{
-		TenantID:     tenantID,
-		SubjectKind:  req.SubjectKind,
-		SubjectID:    req.SubjectID,
-		Action:       req.Action,
-		ResourceType: req.ResourceType,
-		Decision:     req.Decision,
-		Limit:        defaultLimit(req.Limit),
-		Offset:       req.Offset,
+		TenantID:        tenantID,
+		SubjectKind:     req.SubjectKind,
+		SubjectID:       req.SubjectID,
+		Action:          req.Action,
+		ResourceType:    req.ResourceType,
+		ResourceID:      req.ResourceID,
+		Decision:        req.Decision,
+		NamespacePrefix: req.NamespacePrefix,
+		RequestIP:       req.RequestIP,
+		RequestID:       req.RequestID,
+		MatchedSource:   req.MatchedSource,
+		MatchedRuleID:   req.MatchedRuleID,
+		Obligation:      req.Obligation,
+	}
+	if req.NamespacePath != "" {
+		filter.NamespacePath = &req.NamespacePath
 	}
 
 	if req.After != "" {
@@ -49,11 +115,5 @@ func (a *API) listCheckLogs(ctx forge.Context, req *ListCheckLogsRequest) (*Chec
 		}
 		filter.Before = &t
 	}
-
-	logs, err := a.eng.Store().ListCheckLogs(ctx.Context(), filter)
-	if err != nil {
-		return nil, mapError(err)
-	}
-
-	return &CheckLogListResponse{Body: logs}, nil
+	return filter, nil
 }
@@ -25,28 +25,36 @@ func mapError(err error) error {
 	if errors.Is(err, warden.ErrSystemRoleImmutable) || errors.Is(err, warden.ErrSystemPermissionImmutable) {
 		return forge.BadRequest(err.Error())
 	}
-	if errors.Is(err, warden.ErrDuplicateAssignment) || errors.Is(err, warden.ErrDuplicateRelation) {
+	if errors.Is(err, warden.ErrDuplicateAssignment) || errors.Is(err, warden.ErrDuplicateRelation) ||
+		errors.Is(err, warden.ErrDuplicateCalendar) || errors.Is(err, warden.ErrDuplicateSoDConstraint) {
 		return forge.BadRequest(err.Error())
 	}
-	if errors.Is(err, warden.ErrCyclicRoleInheritance) || errors.Is(err, warden.ErrMaxMembersExceeded) {
+	if errors.Is(err, warden.ErrCyclicRoleInheritance) || errors.Is(err, warden.ErrMaxMembersExceeded) ||
+		errors.Is(err, warden.ErrSoDViolation) {
 		return forge.BadRequest(err.Error())
 	}
 	if errors.Is(err, warden.ErrInvalidCondition) {
 		return forge.BadRequest(err.Error())
 	}
-	if errors.Is(err, warden.ErrAccessDenied) {
+	if errors.Is(err, warden.ErrAccessDenied) || errors.Is(err, warden.ErrBreakGlassNotAllowed) {
 		return forge.Forbidden(err.Error())
 	}
+	if errors.Is(err, warden.ErrBreakGlassInactive) {
+		return forge.BadRequest(err.Error())
+	}
 	return err
 }
 
 func isNotFound(err error) bool {
-	return errors.Is(err, warden.ErrRoleNotFound) ||
+	return errors.Is(err, warden.ErrNotFound) ||
+		errors.Is(err, warden.ErrRoleNotFound) ||
 		errors.Is(err, warden.ErrPermissionNotFound) ||
 		errors.Is(err, warden.ErrAssignmentNotFound) ||
 		errors.Is(err, warden.ErrPolicyNotFound) ||
 		errors.Is(err, warden.ErrRelationNotFound) ||
-		errors.Is(err, warden.ErrResourceTypeNotFound)
+		errors.Is(err, warden.ErrResourceTypeNotFound) ||
+		errors.Is(err, warden.ErrCalendarNotFound) ||
+		errors.Is(err, warden.ErrSoDConstraintNotFound)
 }
 
 func defaultLimit(limit int) int {
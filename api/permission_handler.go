@@ -12,7 +12,7 @@ import (
 )
 
 func (a *API) registerPermissionRoutes(router forge.Router) error {
-	g := router.Group("/v1", forge.WithGroupTags("permissions"))
+	g := router.Group("/v1", forge.WithGroupTags("permissions"), forge.WithGroupMiddleware(auditContext))
 
 	if err := g.POST("/permissions", a.createPermission,
 		forge.WithSummary("Create permission"),
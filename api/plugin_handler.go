@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/xraph/forge"
+
+	"github.com/xraph/warden/plugin"
+)
+
+func (a *API) registerPluginRoutes(router forge.Router) error {
+	g := router.Group("/v1", forge.WithGroupTags("plugins"))
+
+	return g.GET("/plugins/stats", a.pluginStats,
+		forge.WithSummary("Plugin hook statistics"),
+		forge.WithDescription("Returns per-plugin hook call counts, errors, timeouts, panics, dropped async events and latency."),
+		forge.WithOperationID("pluginStats"),
+		forge.WithRequestSchema(PluginStatsRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Plugin statistics", []plugin.Stats{}),
+		forge.WithErrorResponses(),
+	)
+}
+
+func (a *API) pluginStats(_ forge.Context, _ *PluginStatsRequest) (*PluginStatsResponse, error) {
+	stats := []plugin.Stats{}
+	if a.eng.Plugins() != nil {
+		stats = a.eng.Plugins().Stats()
+	}
+	return &PluginStatsResponse{Body: stats}, nil
+}
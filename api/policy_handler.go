@@ -12,7 +12,7 @@ import (
 )
 
 func (a *API) registerPolicyRoutes(router forge.Router) error {
-	g := router.Group("/v1", forge.WithGroupTags("policies"))
+	g := router.Group("/v1", forge.WithGroupTags("policies"), forge.WithGroupMiddleware(auditContext))
 
 	if err := g.POST("/policies", a.createPolicy,
 		forge.WithSummary("Create policy"),
@@ -73,6 +73,11 @@ func (a *API) createPolicy(ctx forge.Context, req *CreatePolicyRequest) (*policy
 		if req.Effect != string(policy.EffectAllow) && req.Effect != string(policy.EffectDeny) {
 			verr.AddWithCode("effect", "effect must be 'allow' or 'deny'", "ENUM", req.Effect)
 		}
+		validateMode(verr, req.Mode)
+		validateOnError(verr, req.OnError)
+		validateObligations(verr, "structured_obligations", req.StructuredObligations)
+		validateObligations(verr, "advice", req.Advice)
+		validateConditions(verr, req.Conditions)
 		if verr.HasErrors() {
 			return nil, verr
 		}
@@ -81,32 +86,39 @@ func (a *API) createPolicy(ctx forge.Context, req *CreatePolicyRequest) (*policy
 	appID, tenantID := scopeFromForgeContext(ctx)
 	now := time.Now()
 	p := &policy.Policy{
-		ID:          id.NewPolicyID(),
-		TenantID:    tenantID,
-		AppID:       appID,
-		Name:        req.Name,
-		Description: req.Description,
-		Effect:      policy.Effect(req.Effect),
-		Priority:    req.Priority,
-		IsActive:    req.IsActive,
-		NotBefore:   req.NotBefore,
-		NotAfter:    req.NotAfter,
-		Obligations: req.Obligations,
-		Version:     1,
-		Subjects:    req.Subjects,
-		Actions:     req.Actions,
-		Resources:   req.Resources,
-		Metadata:    req.Metadata,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:                    id.NewPolicyID(),
+		TenantID:              tenantID,
+		AppID:                 appID,
+		Name:                  req.Name,
+		Description:           req.Description,
+		Effect:                policy.Effect(req.Effect),
+		Priority:              req.Priority,
+		IsActive:              req.IsActive,
+		Mode:                  policy.Mode(req.Mode),
+		OnError:               policy.OnError(req.OnError),
+		NotBefore:             req.NotBefore,
+		NotAfter:              req.NotAfter,
+		Obligations:           req.Obligations,
+		StructuredObligations: req.StructuredObligations,
+		Advice:                req.Advice,
+		Version:               1,
+		Subjects:              req.Subjects,
+		Actions:               req.Actions,
+		Resources:             req.Resources,
+		Metadata:              req.Metadata,
+		CreatedAt:             now,
+		UpdatedAt:             now,
 	}
 
 	for _, c := range req.Conditions {
 		p.Conditions = append(p.Conditions, policy.Condition{
-			ID:       id.NewConditionID(),
-			Field:    c.Field,
-			Operator: policy.Operator(c.Operator),
-			Value:    c.Value,
+			ID:         id.NewConditionID(),
+			Field:      c.Field,
+			Operator:   policy.Operator(c.Operator),
+			Value:      c.Value,
+			ValueRef:   c.ValueRef,
+			Expression: c.Expression,
+			TimeZone:   c.TimeZone,
 		})
 	}
 
@@ -161,6 +173,20 @@ func (a *API) updatePolicy(ctx forge.Context, req *UpdatePolicyRequest) (*policy
 	if req.IsActive != nil {
 		p.IsActive = *req.IsActive
 	}
+	if req.Mode != "" {
+		verr := forge.NewValidationErrors()
+		if validateMode(verr, req.Mode); verr.HasErrors() {
+			return nil, verr
+		}
+		p.Mode = policy.Mode(req.Mode)
+	}
+	if req.OnError != "" {
+		verr := forge.NewValidationErrors()
+		if validateOnError(verr, req.OnError); verr.HasErrors() {
+			return nil, verr
+		}
+		p.OnError = policy.OnError(req.OnError)
+	}
 	if req.NotBefore != nil {
 		p.NotBefore = req.NotBefore
 	}
@@ -170,6 +196,20 @@ func (a *API) updatePolicy(ctx forge.Context, req *UpdatePolicyRequest) (*policy
 	if req.Obligations != nil {
 		p.Obligations = req.Obligations
 	}
+	if req.StructuredObligations != nil || req.Advice != nil {
+		verr := forge.NewValidationErrors()
+		validateObligations(verr, "structured_obligations", req.StructuredObligations)
+		validateObligations(verr, "advice", req.Advice)
+		if verr.HasErrors() {
+			return nil, verr
+		}
+	}
+	if req.StructuredObligations != nil {
+		p.StructuredObligations = req.StructuredObligations
+	}
+	if req.Advice != nil {
+		p.Advice = req.Advice
+	}
 	if req.Subjects != nil {
 		p.Subjects = req.Subjects
 	}
@@ -180,13 +220,21 @@ func (a *API) updatePolicy(ctx forge.Context, req *UpdatePolicyRequest) (*policy
 		p.Resources = req.Resources
 	}
 	if req.Conditions != nil {
+		verr := forge.NewValidationErrors()
+		validateConditions(verr, req.Conditions)
+		if verr.HasErrors() {
+			return nil, verr
+		}
 		p.Conditions = nil
 		for _, c := range req.Conditions {
 			p.Conditions = append(p.Conditions, policy.Condition{
-				ID:       id.NewConditionID(),
-				Field:    c.Field,
-				Operator: policy.Operator(c.Operator),
-				Value:    c.Value,
+				ID:         id.NewConditionID(),
+				Field:      c.Field,
+				Operator:   policy.Operator(c.Operator),
+				Value:      c.Value,
+				ValueRef:   c.ValueRef,
+				Expression: c.Expression,
+				TimeZone:   c.TimeZone,
 			})
 		}
 	}
@@ -252,3 +300,48 @@ func (a *API) listPolicies(ctx forge.Context, req *ListPoliciesRequest) (*Policy
 
 	return &PolicyListResponse{Body: policies}, nil
 }
+
+func validateMode(verr *forge.ValidationErrors, mode string) {
+	if !policy.Mode(mode).Valid() {
+		verr.AddWithCode("mode", "mode must be 'enforce', 'shadow' or 'disabled'", "ENUM", mode)
+	}
+}
+
+func validateOnError(verr *forge.ValidationErrors, onError string) {
+	if !policy.OnError(onError).Valid() {
+		verr.AddWithCode("on_error", "on_error must be 'deny' or 'skip'", "ENUM", onError)
+	}
+}
+
+// validateConditions rejects conditions that could never evaluate: unknown
+// operators, expressions that do not compile, and invalid regexes or CIDRs.
+func validateConditions(verr *forge.ValidationErrors, conds []ConditionInput) {
+	for i, c := range conds {
+		cond := policy.Condition{
+			Field:      c.Field,
+			Operator:   policy.Operator(c.Operator),
+			Value:      c.Value,
+			ValueRef:   c.ValueRef,
+			Expression: c.Expression,
+			TimeZone:   c.TimeZone,
+		}
+		if err := cond.Validate(); err != nil {
+			field, value := fmt.Sprintf("conditions[%d]", i), c.Value
+			if c.Expression != "" {
+				field, value = field+".expression", c.Expression
+			}
+			verr.AddWithCode(field, err.Error(), "INVALID", value)
+		}
+	}
+}
+
+func validateObligations(verr *forge.ValidationErrors, field string, obs []policy.Obligation) {
+	for i, ob := range obs {
+		if ob.Name == "" {
+			verr.AddWithCode(fmt.Sprintf("%s[%d].name", field, i), "name is required", "REQUIRED", nil)
+		}
+		if !ob.FulfillOn.Valid() {
+			verr.AddWithCode(fmt.Sprintf("%s[%d].fulfill_on", field, i), "fulfill_on must be 'allow', 'deny' or 'always'", "ENUM", ob.FulfillOn)
+		}
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 func (a *API) registerRelationRoutes(router forge.Router) error {
-	g := router.Group("/v1", forge.WithGroupTags("relations"))
+	g := router.Group("/v1", forge.WithGroupTags("relations"), forge.WithGroupMiddleware(auditContext))
 
 	if err := g.POST("/relations", a.writeRelation,
 		forge.WithSummary("Write relation"),
@@ -3,6 +3,7 @@ package api
 import (
 	"time"
 
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/policy"
 )
 
@@ -19,6 +20,7 @@ type CheckRequest struct {
 	ResourceID   string         `json:"resource_id" description:"Resource identifier"`
 	Context      map[string]any `json:"context,omitempty" description:"Additional context attributes"`
 	TenantID     string         `json:"tenant_id,omitempty" description:"Optional tenant ID override (defaults to context-derived tenant)"`
+	ActiveRoles  []string       `json:"active_roles,omitempty" description:"Role slugs activated for this session; omit to activate every assigned role"`
 }
 
 // BatchCheckRequest contains multiple checks.
@@ -26,6 +28,17 @@ type BatchCheckRequest struct {
 	Checks []CheckRequest `json:"checks" description:"List of authorization checks"`
 }
 
+// SimulateRequest is the body for replaying checks against a proposed
+// change to the model.
+type SimulateRequest struct {
+	Source   string         `json:"source" description:"Proposed changes as .warden source, applied on top of the current model"`
+	Prune    bool           `json:"prune,omitempty" description:"Treat source as the tenant's complete model: entities it does not declare are removed"`
+	After    string         `json:"after,omitempty" description:"Replay checks recorded after this timestamp (RFC3339)"`
+	Before   string         `json:"before,omitempty" description:"Replay checks recorded before this timestamp (RFC3339)"`
+	Limit    int            `json:"limit,omitempty" description:"Maximum checks to replay (default: 10000)"`
+	Requests []CheckRequest `json:"requests,omitempty" description:"Checks to replay instead of the check log"`
+}
+
 // ──────────────────────────────────────────────────
 // Role requests
 // ──────────────────────────────────────────────────
@@ -203,44 +216,55 @@ type ListRelationsRequest struct {
 
 // CreatePolicyRequest is the body for creating an ABAC/PBAC policy.
 type CreatePolicyRequest struct {
-	Name        string                `json:"name" description:"Policy name"`
-	Description string                `json:"description,omitempty" description:"Human-readable description"`
-	Effect      string                `json:"effect" description:"Policy effect (allow or deny)"`
-	Priority    int                   `json:"priority,omitempty" description:"Policy priority"`
-	IsActive    bool                  `json:"is_active" description:"Whether the policy is active"`
-	NotBefore   *time.Time            `json:"not_before,omitempty" description:"PBAC: policy is inactive before this RFC3339 instant"`
-	NotAfter    *time.Time            `json:"not_after,omitempty" description:"PBAC: policy is inactive after this RFC3339 instant"`
-	Obligations []string              `json:"obligations,omitempty" description:"PBAC: named side-effect actions emitted on match"`
-	Subjects    []policy.SubjectMatch `json:"subjects,omitempty" description:"Subject matchers"`
-	Actions     []string              `json:"actions,omitempty" description:"Action patterns"`
-	Resources   []string              `json:"resources,omitempty" description:"Resource patterns"`
-	Conditions  []ConditionInput      `json:"conditions,omitempty" description:"Policy conditions"`
-	Metadata    map[string]any        `json:"metadata,omitempty" description:"Custom metadata"`
+	Name                  string                `json:"name" description:"Policy name"`
+	Description           string                `json:"description,omitempty" description:"Human-readable description"`
+	Effect                string                `json:"effect" description:"Policy effect (allow or deny)"`
+	Priority              int                   `json:"priority,omitempty" description:"Policy priority"`
+	IsActive              bool                  `json:"is_active" description:"Whether the policy is active"`
+	Mode                  string                `json:"mode,omitempty" description:"Rollout mode: enforce (default), shadow or disabled"`
+	OnError               string                `json:"on_error,omitempty" description:"When conditions fail to evaluate: deny (default, the check is indeterminate) or skip"`
+	NotBefore             *time.Time            `json:"not_before,omitempty" description:"PBAC: policy is inactive before this RFC3339 instant"`
+	NotAfter              *time.Time            `json:"not_after,omitempty" description:"PBAC: policy is inactive after this RFC3339 instant"`
+	Obligations           []string              `json:"obligations,omitempty" description:"PBAC: named side-effect actions emitted on match"`
+	StructuredObligations []policy.Obligation   `json:"structured_obligations,omitempty" description:"PBAC: obligations with parameters and a fulfill_on decision filter"`
+	Advice                []policy.Obligation   `json:"advice,omitempty" description:"PBAC: optional advice with parameters and a fulfill_on decision filter"`
+	Subjects              []policy.SubjectMatch `json:"subjects,omitempty" description:"Subject matchers"`
+	Actions               []string              `json:"actions,omitempty" description:"Action patterns"`
+	Resources             []string              `json:"resources,omitempty" description:"Resource patterns"`
+	Conditions            []ConditionInput      `json:"conditions,omitempty" description:"Policy conditions"`
+	Metadata              map[string]any        `json:"metadata,omitempty" description:"Custom metadata"`
 }
 
 // ConditionInput is the input format for a policy condition.
 type ConditionInput struct {
-	Field    string `json:"field" description:"Dot-separated field path (e.g. context.ip)"`
-	Operator string `json:"operator" description:"Comparison operator"`
-	Value    any    `json:"value" description:"Expected value"`
+	Field      string `json:"field" description:"Dot-separated field path (e.g. context.ip)"`
+	Operator   string `json:"operator" description:"Comparison operator"`
+	Value      any    `json:"value" description:"Expected value"`
+	ValueRef   string `json:"value_ref,omitempty" description:"Field path compared against instead of value (e.g. subject.id)"`
+	Expression string `json:"expression,omitempty" description:"Boolean expression used instead of field, operator and value (e.g. size(subject.groups) > 2)"`
+	TimeZone   string `json:"time_zone,omitempty" description:"IANA time zone for schedule operators (e.g. Europe/Berlin)"`
 }
 
 // UpdatePolicyRequest is the body for updating a policy.
 type UpdatePolicyRequest struct {
-	PolicyID    string                `path:"policyId" description:"Policy ID"`
-	Name        string                `json:"name,omitempty" description:"Policy name"`
-	Description string                `json:"description,omitempty" description:"Description"`
-	Effect      string                `json:"effect,omitempty" description:"Policy effect"`
-	Priority    *int                  `json:"priority,omitempty" description:"Priority"`
-	IsActive    *bool                 `json:"is_active,omitempty" description:"Active flag"`
-	NotBefore   *time.Time            `json:"not_before,omitempty" description:"PBAC: lower time bound (RFC3339)"`
-	NotAfter    *time.Time            `json:"not_after,omitempty" description:"PBAC: upper time bound (RFC3339)"`
-	Obligations []string              `json:"obligations,omitempty" description:"PBAC: named side-effect actions emitted on match"`
-	Subjects    []policy.SubjectMatch `json:"subjects,omitempty" description:"Subject matchers"`
-	Actions     []string              `json:"actions,omitempty" description:"Action patterns"`
-	Resources   []string              `json:"resources,omitempty" description:"Resource patterns"`
-	Conditions  []ConditionInput      `json:"conditions,omitempty" description:"Conditions"`
-	Metadata    map[string]any        `json:"metadata,omitempty" description:"Metadata"`
+	PolicyID              string                `path:"policyId" description:"Policy ID"`
+	Name                  string                `json:"name,omitempty" description:"Policy name"`
+	Description           string                `json:"description,omitempty" description:"Description"`
+	Effect                string                `json:"effect,omitempty" description:"Policy effect"`
+	Priority              *int                  `json:"priority,omitempty" description:"Priority"`
+	IsActive              *bool                 `json:"is_active,omitempty" description:"Active flag"`
+	Mode                  string                `json:"mode,omitempty" description:"Rollout mode: enforce, shadow or disabled"`
+	OnError               string                `json:"on_error,omitempty" description:"When conditions fail to evaluate: deny or skip"`
+	NotBefore             *time.Time            `json:"not_before,omitempty" description:"PBAC: lower time bound (RFC3339)"`
+	NotAfter              *time.Time            `json:"not_after,omitempty" description:"PBAC: upper time bound (RFC3339)"`
+	Obligations           []string              `json:"obligations,omitempty" description:"PBAC: named side-effect actions emitted on match"`
+	StructuredObligations []policy.Obligation   `json:"structured_obligations,omitempty" description:"PBAC: obligations with parameters and a fulfill_on decision filter"`
+	Advice                []policy.Obligation   `json:"advice,omitempty" description:"PBAC: optional advice with parameters and a fulfill_on decision filter"`
+	Subjects              []policy.SubjectMatch `json:"subjects,omitempty" description:"Subject matchers"`
+	Actions               []string              `json:"actions,omitempty" description:"Action patterns"`
+	Resources             []string              `json:"resources,omitempty" description:"Resource patterns"`
+	Conditions            []ConditionInput      `json:"conditions,omitempty" description:"Conditions"`
+	Metadata              map[string]any        `json:"metadata,omitempty" description:"Metadata"`
 }
 
 // GetPolicyRequest is the path parameter for getting a policy.
@@ -267,6 +291,7 @@ type CreateResourceTypeRequest struct {
 	Description string               `json:"description,omitempty" description:"Description"`
 	Relations   []RelationDefInput   `json:"relations,omitempty" description:"Relation definitions"`
 	Permissions []PermissionDefInput `json:"permissions,omitempty" description:"Permission definitions"`
+	Attributes  []AttributeDefInput  `json:"attributes,omitempty" description:"Attribute schema for resources of this type and subjects of this kind"`
 	Metadata    map[string]any       `json:"metadata,omitempty" description:"Custom metadata"`
 }
 
@@ -282,6 +307,14 @@ type PermissionDefInput struct {
 	Expression string `json:"expression" description:"Permission expression"`
 }
 
+// AttributeDefInput is the input for an attribute declaration.
+type AttributeDefInput struct {
+	Name     string   `json:"name" description:"Attribute name"`
+	Type     string   `json:"type" description:"Attribute type: string, int, float, bool, timestamp, duration, list or map"`
+	Required bool     `json:"required,omitempty" description:"Whether the attribute must be present"`
+	Enum     []string `json:"enum,omitempty" description:"Allowed values"`
+}
+
 // GetResourceTypeRequest is the path parameter.
 type GetResourceTypeRequest struct {
 	ResourceTypeID string `path:"resourceTypeId" description:"Resource type ID"`
@@ -300,13 +333,198 @@ type ListResourceTypesRequest struct {
 
 // ListCheckLogsRequest holds query parameters for querying check logs.
 type ListCheckLogsRequest struct {
-	SubjectKind  string `query:"subject_kind" description:"Filter by subject type"`
-	SubjectID    string `query:"subject_id" description:"Filter by subject ID"`
-	Action       string `query:"action" description:"Filter by action"`
-	ResourceType string `query:"resource_type" description:"Filter by resource type"`
-	Decision     string `query:"decision" description:"Filter by decision"`
-	After        string `query:"after" description:"After timestamp (RFC3339)"`
-	Before       string `query:"before" description:"Before timestamp (RFC3339)"`
-	Limit        int    `query:"limit" description:"Maximum results"`
-	Offset       int    `query:"offset" description:"Results to skip"`
+	SubjectKind     string `query:"subject_kind" description:"Filter by subject type"`
+	SubjectID       string `query:"subject_id" description:"Filter by subject ID"`
+	Action          string `query:"action" description:"Filter by action"`
+	ResourceType    string `query:"resource_type" description:"Filter by resource type"`
+	ResourceID      string `query:"resource_id" description:"Filter by resource ID"`
+	Decision        string `query:"decision" description:"Filter by decision"`
+	NamespacePath   string `query:"namespace_path" description:"Filter by exact namespace path"`
+	NamespacePrefix string `query:"namespace_prefix" description:"Filter by namespace path prefix"`
+	RequestIP       string `query:"request_ip" description:"Filter by caller IP"`
+	RequestID       string `query:"request_id" description:"Filter by request ID"`
+	MatchedSource   string `query:"matched_source" description:"Filter by matching evaluator (rbac, rebac, abac)"`
+	MatchedRuleID   string `query:"matched_rule_id" description:"Filter by matching role, relation or policy"`
+	Obligation      string `query:"obligation" description:"Filter by obligation"`
+	After           string `query:"after" description:"After timestamp (RFC3339)"`
+	Before          string `query:"before" description:"Before timestamp (RFC3339)"`
+	Limit           int    `query:"limit" description:"Maximum results"`
+	Offset          int    `query:"offset" description:"Results to skip"`
+}
+
+// CheckLogStatsRequest holds query parameters for aggregated check log
+// statistics. Filters match ListCheckLogsRequest.
+type CheckLogStatsRequest struct {
+	SubjectKind     string `query:"subject_kind" description:"Filter by subject type"`
+	SubjectID       string `query:"subject_id" description:"Filter by subject ID"`
+	Action          string `query:"action" description:"Filter by action"`
+	ResourceType    string `query:"resource_type" description:"Filter by resource type"`
+	ResourceID      string `query:"resource_id" description:"Filter by resource ID"`
+	Decision        string `query:"decision" description:"Filter by decision"`
+	NamespacePath   string `query:"namespace_path" description:"Filter by exact namespace path"`
+	NamespacePrefix string `query:"namespace_prefix" description:"Filter by namespace path prefix"`
+	RequestIP       string `query:"request_ip" description:"Filter by caller IP"`
+	RequestID       string `query:"request_id" description:"Filter by request ID"`
+	MatchedSource   string `query:"matched_source" description:"Filter by matching evaluator (rbac, rebac, abac)"`
+	MatchedRuleID   string `query:"matched_rule_id" description:"Filter by matching role, relation or policy"`
+	Obligation      string `query:"obligation" description:"Filter by obligation"`
+	After           string `query:"after" description:"After timestamp (RFC3339)"`
+	Before          string `query:"before" description:"Before timestamp (RFC3339)"`
+	GroupBy         string `query:"group_by" description:"Series dimension: decision, resource_type, action or subject"`
+	Bucket          string `query:"bucket" description:"Time bucket width as a duration, e.g. 1h (empty for a single bucket)"`
+	Top             int    `query:"top" description:"Number of top denied subjects and resources"`
+}
+
+func (r *CheckLogStatsRequest) listRequest() *ListCheckLogsRequest {
+	return &ListCheckLogsRequest{
+		SubjectKind:     r.SubjectKind,
+		SubjectID:       r.SubjectID,
+		Action:          r.Action,
+		ResourceType:    r.ResourceType,
+		ResourceID:      r.ResourceID,
+		Decision:        r.Decision,
+		NamespacePath:   r.NamespacePath,
+		NamespacePrefix: r.NamespacePrefix,
+		RequestIP:       r.RequestIP,
+		RequestID:       r.RequestID,
+		MatchedSource:   r.MatchedSource,
+		MatchedRuleID:   r.MatchedRuleID,
+		Obligation:      r.Obligation,
+		After:           r.After,
+		Before:          r.Before,
+	}
 }
+
+// ──────────────────────────────────────────────────
+// Audit requests
+// ──────────────────────────────────────────────────
+
+// ListAuditRequest holds query parameters for querying the audit trail.
+type ListAuditRequest struct {
+	EntityType string `query:"entity_type" description:"Filter by entity type (role, permission, role_permissions, assignment, relation, policy, resource_type, break_glass)"`
+	EntityID   string `query:"entity_id" description:"Filter by entity ID"`
+	Operation  string `query:"operation" description:"Filter by operation (create, update, delete)"`
+	Source     string `query:"source" description:"Filter by source (api, dsl, cli, sdk)"`
+	ActorID    string `query:"actor_id" description:"Filter by actor ID"`
+	After      string `query:"after" description:"After timestamp (RFC3339)"`
+	Before     string `query:"before" description:"Before timestamp (RFC3339)"`
+	Limit      int    `query:"limit" description:"Maximum results"`
+	Offset     int    `query:"offset" description:"Results to skip"`
+}
+
+// VerifyAuditRequest takes no parameters; the tenant comes from scope.
+type VerifyAuditRequest struct{}
+
+// ──────────────────────────────────────────────────
+// Break-glass requests
+// ──────────────────────────────────────────────────
+
+// BreakGlassRequest is the body for requesting emergency access. The
+// grant always goes to the authenticated user.
+type BreakGlassRequest struct {
+	Justification  string   `json:"justification" description:"Why emergency access is needed (required)"`
+	Duration       string   `json:"duration,omitempty" description:"Grant duration, e.g. 30m (defaults to the configured maximum)"`
+	Roles          []string `json:"roles,omitempty" description:"Slugs of eligible roles to grant"`
+	BypassPolicies []string `json:"bypass_policies,omitempty" description:"Names of eligible deny policies to bypass"`
+	NamespacePath  string   `json:"namespace_path,omitempty" description:"Namespace the grant applies to (and below); empty = tenant root"`
+}
+
+// GetBreakGlassRequest is the path parameter for a break-glass grant.
+type GetBreakGlassRequest struct {
+	GrantID string `path:"grantId" description:"Break-glass grant ID"`
+}
+
+// ListBreakGlassRequest holds query parameters for listing grants.
+type ListBreakGlassRequest struct {
+	SubjectKind string `query:"subject_kind" description:"Filter by subject type"`
+	SubjectID   string `query:"subject_id" description:"Filter by subject ID"`
+	Active      string `query:"active" description:"Only unexpired, unrevoked grants (true)"`
+	Limit       int    `query:"limit" description:"Maximum results"`
+	Offset      int    `query:"offset" description:"Results to skip"`
+}
+
+// ──────────────────────────────────────────────────
+// Calendar requests
+// ──────────────────────────────────────────────────
+
+// CreateCalendarRequest is the body for creating a calendar.
+type CreateCalendarRequest struct {
+	Name        string          `json:"name" description:"Calendar name, referenced by in_calendar conditions"`
+	Description string          `json:"description,omitempty" description:"Description"`
+	TimeZone    string          `json:"time_zone,omitempty" description:"IANA time zone the dates are observed in (default UTC)"`
+	Dates       []calendar.Date `json:"dates,omitempty" description:"Dates as YYYY-MM-DD, or MM-DD for every year"`
+	Metadata    map[string]any  `json:"metadata,omitempty" description:"Custom metadata"`
+}
+
+// UpdateCalendarRequest is the body for updating a calendar. Dates, when
+// given, replace the calendar's dates.
+type UpdateCalendarRequest struct {
+	CalendarID  string          `path:"calendarId" description:"Calendar ID"`
+	Name        string          `json:"name,omitempty" description:"Calendar name"`
+	Description string          `json:"description,omitempty" description:"Description"`
+	TimeZone    *string         `json:"time_zone,omitempty" description:"IANA time zone; empty string resets to UTC, omit to leave unchanged"`
+	Dates       []calendar.Date `json:"dates,omitempty" description:"Replacement dates"`
+	Metadata    map[string]any  `json:"metadata,omitempty" description:"Custom metadata"`
+}
+
+// GetCalendarRequest is the path parameter for a calendar.
+type GetCalendarRequest struct {
+	CalendarID string `path:"calendarId" description:"Calendar ID"`
+}
+
+// ListCalendarsRequest holds query parameters for listing calendars.
+type ListCalendarsRequest struct {
+	Search string `query:"search" description:"Search by name"`
+	Limit  int    `query:"limit" description:"Maximum results"`
+	Offset int    `query:"offset" description:"Results to skip"`
+}
+
+// ──────────────────────────────────────────────────
+// SoD constraint requests
+// ──────────────────────────────────────────────────
+
+// CreateSoDConstraintRequest is the body for creating a separation-of-duties
+// constraint.
+type CreateSoDConstraintRequest struct {
+	Name        string         `json:"name" description:"Constraint name, unique per tenant"`
+	Description string         `json:"description,omitempty" description:"Description"`
+	Kind        string         `json:"kind,omitempty" description:"static (enforced on assignment, default) or dynamic (enforced per session)"`
+	Roles       []string       `json:"roles" description:"Slugs of the mutually exclusive roles (at least two)"`
+	MaxRoles    int            `json:"max_roles,omitempty" description:"Roles of the set a subject may combine (default 1)"`
+	Metadata    map[string]any `json:"metadata,omitempty" description:"Custom metadata"`
+}
+
+// UpdateSoDConstraintRequest is the body for updating a separation-of-duties
+// constraint. Roles, when given, replace the constraint's roles.
+type UpdateSoDConstraintRequest struct {
+	SoDID       string         `path:"sodId" description:"Constraint ID"`
+	Name        string         `json:"name,omitempty" description:"Constraint name"`
+	Description string         `json:"description,omitempty" description:"Description"`
+	Kind        string         `json:"kind,omitempty" description:"static or dynamic"`
+	Roles       []string       `json:"roles,omitempty" description:"Replacement role slugs"`
+	MaxRoles    *int           `json:"max_roles,omitempty" description:"Roles of the set a subject may combine"`
+	Metadata    map[string]any `json:"metadata,omitempty" description:"Custom metadata"`
+}
+
+// GetSoDConstraintRequest is the path parameter for a separation-of-duties
+// constraint.
+type GetSoDConstraintRequest struct {
+	SoDID string `path:"sodId" description:"Constraint ID"`
+}
+
+// ListSoDConstraintsRequest holds query parameters for listing
+// separation-of-duties constraints.
+type ListSoDConstraintsRequest struct {
+	Kind   string `query:"kind" description:"Filter by kind (static, dynamic)"`
+	Role   string `query:"role" description:"Only constraints naming this role slug"`
+	Search string `query:"search" description:"Search by name"`
+	Limit  int    `query:"limit" description:"Maximum results"`
+	Offset int    `query:"offset" description:"Results to skip"`
+}
+
+// ──────────────────────────────────────────────────
+// Plugin requests
+// ──────────────────────────────────────────────────
+
+// PluginStatsRequest takes no parameters.
+type PluginStatsRequest struct{}
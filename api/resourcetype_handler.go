@@ -12,7 +12,7 @@ import (
 )
 
 func (a *API) registerResourceTypeRoutes(router forge.Router) error {
-	g := router.Group("/v1", forge.WithGroupTags("resource-types"))
+	g := router.Group("/v1", forge.WithGroupTags("resource-types"), forge.WithGroupMiddleware(auditContext))
 
 	if err := g.POST("/resource-types", a.createResourceType,
 		forge.WithSummary("Create resource type"),
@@ -58,6 +58,20 @@ func (a *API) createResourceType(ctx forge.Context, req *CreateResourceTypeReque
 		if req.Name == "" {
 			verr.AddWithCode("name", "name is required", "REQUIRED", nil)
 		}
+		seen := make(map[string]bool, len(req.Attributes))
+		for i, attr := range req.Attributes {
+			field := fmt.Sprintf("attributes[%d]", i)
+			switch {
+			case attr.Name == "":
+				verr.AddWithCode(field+".name", "name is required", "REQUIRED", nil)
+			case seen[attr.Name]:
+				verr.AddWithCode(field+".name", fmt.Sprintf("duplicate attribute %q", attr.Name), "INVALID", nil)
+			}
+			seen[attr.Name] = true
+			if !resourcetype.AttributeType(attr.Type).Valid() {
+				verr.AddWithCode(field+".type", fmt.Sprintf("unknown attribute type %q", attr.Type), "INVALID", nil)
+			}
+		}
 		if verr.HasErrors() {
 			return nil, verr
 		}
@@ -88,6 +102,14 @@ func (a *API) createResourceType(ctx forge.Context, req *CreateResourceTypeReque
 			Expression: p.Expression,
 		})
 	}
+	for _, attr := range req.Attributes {
+		rt.Attributes = append(rt.Attributes, resourcetype.AttributeDef{
+			Name:     attr.Name,
+			Type:     resourcetype.AttributeType(attr.Type),
+			Required: attr.Required,
+			Enum:     attr.Enum,
+		})
+	}
 
 	if err := a.eng.Store().CreateResourceType(ctx.Context(), rt); err != nil {
 		return nil, mapError(err)
@@ -1,5 +1,7 @@
 package api
 
+import "github.com/xraph/warden/audit"
+
 // CheckResponse is the response for an authorization check.
 type CheckResponse struct {
 	Allowed    bool        `json:"allowed" description:"Whether the request is allowed"`
@@ -11,9 +13,10 @@ type CheckResponse struct {
 
 // MatchInfo identifies a matched rule.
 type MatchInfo struct {
-	Source string `json:"source" description:"Source model (rbac, rebac, abac)"`
-	RuleID string `json:"rule_id,omitempty" description:"Rule identifier"`
-	Detail string `json:"detail,omitempty" description:"Match detail"`
+	Source     string `json:"source" description:"Source model (rbac, rebac, abac)"`
+	RuleID     string `json:"rule_id,omitempty" description:"Rule identifier"`
+	Detail     string `json:"detail,omitempty" description:"Match detail"`
+	Permission string `json:"permission,omitempty" description:"Granting resource:action permission (rbac)"`
 }
 
 // BatchCheckResponse contains results for multiple checks.
@@ -68,3 +71,50 @@ type ResourceTypeListResponse struct {
 type CheckLogListResponse struct {
 	Body any `json:"check_logs" body:"" description:"List of check logs"`
 }
+
+// CheckLogStatsResponse wraps aggregated check log statistics.
+type CheckLogStatsResponse struct {
+	Body any `json:"stats" body:"" description:"Check log statistics"`
+}
+
+// SimulationResponse wraps a simulation report.
+type SimulationResponse struct {
+	Body any `json:"simulation" body:"" description:"Decisions the proposed change would flip"`
+}
+
+// AuditEntryResponse is an audit entry with its field-level diff.
+type AuditEntryResponse struct {
+	*audit.Entry
+	Changes []audit.Change `json:"changes,omitempty" description:"Fields that differ between before and after"`
+}
+
+// AuditListResponse wraps a page of audit entries.
+type AuditListResponse struct {
+	Body any `json:"audit" body:"" description:"Page of audit entries"`
+}
+
+// AuditVerifyResponse wraps the result of verifying the audit hash chain.
+type AuditVerifyResponse struct {
+	Body any `json:"verification" body:"" description:"Chain verification result"`
+}
+
+// BreakGlassListResponse wraps a list of break-glass grants.
+type BreakGlassListResponse struct {
+	Body any `json:"grants" body:"" description:"List of break-glass grants"`
+}
+
+// CalendarListResponse wraps a list of calendars.
+type CalendarListResponse struct {
+	Body any `json:"calendars" body:"" description:"List of calendars"`
+}
+
+// SoDConstraintListResponse wraps a list of separation-of-duties
+// constraints.
+type SoDConstraintListResponse struct {
+	Body any `json:"constraints" body:"" description:"List of separation-of-duties constraints"`
+}
+
+// PluginStatsResponse wraps per-plugin hook statistics.
+type PluginStatsResponse struct {
+	Body any `json:"plugins" body:"" description:"Per-plugin hook statistics"`
+}
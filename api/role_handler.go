@@ -14,7 +14,7 @@ import (
 )
 
 func (a *API) registerRoleRoutes(router forge.Router) error {
-	g := router.Group("/v1", forge.WithGroupTags("roles"))
+	g := router.Group("/v1", forge.WithGroupTags("roles"), forge.WithGroupMiddleware(auditContext))
 
 	if err := g.POST("/roles", a.createRole,
 		forge.WithSummary("Create role"),
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xraph/forge"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/sod"
+)
+
+func (a *API) registerSoDRoutes(router forge.Router) error {
+	g := router.Group("/v1", forge.WithGroupTags("sod-constraints"), forge.WithGroupMiddleware(auditContext))
+
+	if err := g.POST("/sod-constraints", a.createSoDConstraint,
+		forge.WithSummary("Create separation-of-duties constraint"),
+		forge.WithDescription("Creates a set of roles no subject may combine. Static constraints are enforced when roles are assigned; dynamic constraints when a check activates roles together."),
+		forge.WithOperationID("wardenCreateSoDConstraint"),
+		forge.WithRequestSchema(CreateSoDConstraintRequest{}),
+		forge.WithCreatedResponse(&sod.Constraint{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	if err := g.GET("/sod-constraints/:sodId", a.getSoDConstraint,
+		forge.WithSummary("Get separation-of-duties constraint"),
+		forge.WithOperationID("wardenGetSoDConstraint"),
+		forge.WithRequestSchema(GetSoDConstraintRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Constraint details", &sod.Constraint{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	if err := g.PUT("/sod-constraints/:sodId", a.updateSoDConstraint,
+		forge.WithSummary("Update separation-of-duties constraint"),
+		forge.WithDescription("Updates a constraint. Existing assignments that break it are not removed; `warden audit sod` reports them."),
+		forge.WithOperationID("wardenUpdateSoDConstraint"),
+		forge.WithRequestSchema(UpdateSoDConstraintRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Updated constraint", &sod.Constraint{}),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	if err := g.DELETE("/sod-constraints/:sodId", a.deleteSoDConstraint,
+		forge.WithSummary("Delete separation-of-duties constraint"),
+		forge.WithOperationID("wardenDeleteSoDConstraint"),
+		forge.WithRequestSchema(GetSoDConstraintRequest{}),
+		forge.WithNoContentResponse(),
+		forge.WithErrorResponses(),
+	); err != nil {
+		return err
+	}
+
+	return g.GET("/sod-constraints", a.listSoDConstraints,
+		forge.WithSummary("List separation-of-duties constraints"),
+		forge.WithDescription("Lists the tenant's constraints by name."),
+		forge.WithOperationID("wardenListSoDConstraints"),
+		forge.WithRequestSchema(ListSoDConstraintsRequest{}),
+		forge.WithResponseSchema(http.StatusOK, "Constraint list", []*sod.Constraint{}),
+		forge.WithErrorResponses(),
+	)
+}
+
+func (a *API) createSoDConstraint(ctx forge.Context, req *CreateSoDConstraintRequest) (*sod.Constraint, error) {
+	appID, tenantID := scopeFromForgeContext(ctx)
+	now := time.Now()
+	c := &sod.Constraint{
+		ID:          id.NewSoDConstraintID(),
+		TenantID:    tenantID,
+		AppID:       appID,
+		Name:        req.Name,
+		Description: req.Description,
+		Kind:        sod.Kind(req.Kind),
+		Roles:       req.Roles,
+		MaxRoles:    req.MaxRoles,
+		Metadata:    req.Metadata,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if c.Kind == "" {
+		c.Kind = sod.KindStatic
+	}
+	if err := c.Validate(); err != nil {
+		return nil, forge.BadRequest(err.Error())
+	}
+	if err := a.eng.Store().CreateSoDConstraint(ctx.Context(), c); err != nil {
+		return nil, mapError(err)
+	}
+
+	return c, ctx.JSON(http.StatusCreated, c)
+}
+
+func (a *API) getSoDConstraint(ctx forge.Context, _ *GetSoDConstraintRequest) (*sod.Constraint, error) {
+	sodID, err := id.ParseSoDConstraintID(ctx.Param("sodId"))
+	if err != nil {
+		return nil, forge.BadRequest(fmt.Sprintf("invalid sod constraint ID: %v", err))
+	}
+
+	c, err := a.eng.Store().GetSoDConstraint(ctx.Context(), sodID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return c, ctx.JSON(http.StatusOK, c)
+}
+
+func (a *API) updateSoDConstraint(ctx forge.Context, req *UpdateSoDConstraintRequest) (*sod.Constraint, error) {
+	sodID, err := id.ParseSoDConstraintID(ctx.Param("sodId"))
+	if err != nil {
+		return nil, forge.BadRequest(fmt.Sprintf("invalid sod constraint ID: %v", err))
+	}
+
+	c, err := a.eng.Store().GetSoDConstraint(ctx.Context(), sodID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	if req.Name != "" {
+		c.Name = req.Name
+	}
+	if req.Description != "" {
+		c.Description = req.Description
+	}
+	if req.Kind != "" {
+		c.Kind = sod.Kind(req.Kind)
+	}
+	if req.Roles != nil {
+		c.Roles = req.Roles
+	}
+	if req.MaxRoles != nil {
+		c.MaxRoles = *req.MaxRoles
+	}
+	if req.Metadata != nil {
+		c.Metadata = req.Metadata
+	}
+	if err := c.Validate(); err != nil {
+		return nil, forge.BadRequest(err.Error())
+	}
+
+	if err := a.eng.Store().UpdateSoDConstraint(ctx.Context(), c); err != nil {
+		return nil, mapError(err)
+	}
+
+	return c, ctx.JSON(http.StatusOK, c)
+}
+
+func (a *API) deleteSoDConstraint(ctx forge.Context, _ *GetSoDConstraintRequest) (*struct{}, error) {
+	sodID, err := id.ParseSoDConstraintID(ctx.Param("sodId"))
+	if err != nil {
+		return nil, forge.BadRequest(fmt.Sprintf("invalid sod constraint ID: %v", err))
+	}
+
+	if err := a.eng.Store().DeleteSoDConstraint(ctx.Context(), sodID); err != nil {
+		return nil, mapError(err)
+	}
+
+	return nil, ctx.NoContent(http.StatusNoContent)
+}
+
+func (a *API) listSoDConstraints(ctx forge.Context, req *ListSoDConstraintsRequest) (*SoDConstraintListResponse, error) {
+	_, tenantID := scopeFromForgeContext(ctx)
+	filter := &sod.ListFilter{
+		TenantID: tenantID,
+		Kind:     sod.Kind(req.Kind),
+		Role:     req.Role,
+		Search:   req.Search,
+		Limit:    defaultLimit(req.Limit),
+		Offset:   req.Offset,
+	}
+
+	cs, err := a.eng.Store().ListSoDConstraints(ctx.Context(), filter)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &SoDConstraintListResponse{Body: cs}, nil
+}
@@ -0,0 +1,231 @@
+package warden
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xraph/warden/plugin"
+)
+
+// maxAttributeCacheEntries bounds the cross-check attribute cache. Expired
+// entries are swept once it is reached, and the whole cache is dropped if
+// that frees nothing.
+const maxAttributeCacheEntries = 10000
+
+// ResolveAttribute returns the value of a policy condition field such as
+// "subject.department" or "resource.owner_id". Values on the request win;
+// a subject, resource or context attribute the request did not carry is
+// fetched from the engine's AttributeProvider plugins when ctx belongs to a
+// Check, and resource.parent.<attr> and resource.ancestors follow the
+// resource's parent relation tuples. During ABAC evaluation, attributes
+// declared in a resource type's schema are coerced to their declared type.
+// A failed lookup resolves to nil; use LookupAttribute to tell it apart
+// from an absent attribute.
+func ResolveAttribute(ctx context.Context, field string, req *CheckRequest) any {
+	v, _ := LookupAttribute(ctx, field, req) //nolint:errcheck // a failed lookup reads as absent
+	return v
+}
+
+// LookupAttribute is ResolveAttribute that also returns the error of a
+// provider or relation lookup that failed. Custom Evaluators should use it
+// so they see provided attributes and can treat a failed lookup as an
+// evaluation error rather than a missing value.
+func LookupAttribute(ctx context.Context, field string, req *CheckRequest) (any, error) {
+	if s, ok := ctx.Value(ctxKeyAttributeSchemas).(*attributeSchemas); ok {
+		if v, ok, err := s.lookup(ctx, field, req); ok {
+			return v, err
+		}
+	}
+	return lookupAttribute(ctx, field, req)
+}
+
+// lookupAttribute is LookupAttribute without attribute schemas.
+func lookupAttribute(ctx context.Context, field string, req *CheckRequest) (any, error) {
+	v, missing := resolveField(field, req)
+	if !missing {
+		return v, nil
+	}
+	if h, ok := ctx.Value(ctxKeyHierarchy).(*hierarchySource); ok {
+		if v, ok, err := h.resolve(ctx, field, req); ok {
+			return v, err
+		}
+	}
+	if r, ok := ctx.Value(ctxKeyAttributeResolver).(*attributeResolver); ok {
+		return r.resolve(ctx, field, req)
+	}
+	return nil, nil
+}
+
+// attributeResolver fetches missing attributes from AttributeProvider
+// plugins for a single check, remembering each field so a provider is asked
+// at most once per check, and recording every lookup for the trace. A
+// failed lookup is remembered for the check but never enters the
+// cross-check cache.
+type attributeResolver struct {
+	plugins  *plugin.Registry
+	cache    *attributeCache // nil when Config.AttributeCacheTTL is zero
+	tenantID string
+
+	mu     sync.Mutex
+	values map[string]any
+	errs   map[string]error
+	trace  []AttributeLookup
+}
+
+// newAttributeResolver returns the resolver for one check, or nil when no
+// plugin provides attributes.
+func (e *Engine) newAttributeResolver(tenantID string) *attributeResolver {
+	if e.plugins == nil || !e.plugins.HasAttributeProviders() {
+		return nil
+	}
+	return &attributeResolver{
+		plugins:  e.plugins,
+		cache:    e.attrCache,
+		tenantID: tenantID,
+		values:   make(map[string]any),
+		errs:     make(map[string]error),
+	}
+}
+
+// withAttributeResolver attaches r to ctx so ResolveAttribute can reach it.
+func withAttributeResolver(ctx context.Context, r *attributeResolver) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKeyAttributeResolver, r)
+}
+
+func (r *attributeResolver) resolve(ctx context.Context, field string, req *CheckRequest) (any, error) {
+	return r.resolveAs(ctx, field, field, req)
+}
+
+// resolveAs asks the providers for field of req, remembering and tracing
+// the answer as key. The hierarchy uses it to fetch resource.<attr> of a
+// parent for the check's resource.parent.<attr>.
+func (r *attributeResolver) resolveAs(ctx context.Context, key, field string, req *CheckRequest) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.values[key]; ok {
+		return v, nil
+	}
+	if err, ok := r.errs[key]; ok {
+		return nil, err
+	}
+
+	start := time.Now()
+	lookup := AttributeLookup{Field: key}
+	cacheKey, cacheable := attributeCacheKey(r.tenantID, field, req)
+	var v any
+	var err error
+	if ent, ok := r.cache.get(cacheKey, cacheable); ok {
+		v, lookup.Provider, lookup.Found, lookup.Cached = ent.value, ent.provider, ent.found, true
+	} else {
+		v, lookup.Provider, lookup.Found, err = r.plugins.ProvideAttribute(ctx, req, field)
+		if cacheable && err == nil {
+			r.cache.set(cacheKey, attributeCacheEntry{value: v, provider: lookup.Provider, found: lookup.Found})
+		}
+	}
+	lookup.TimeNs = time.Since(start).Nanoseconds()
+
+	if err != nil {
+		lookup.Error = err.Error()
+		r.errs[key] = err
+	} else {
+		r.values[key] = v
+	}
+	r.trace = append(r.trace, lookup)
+	return v, err
+}
+
+// decisionTrace returns the lookups made so far, or nil if there were none.
+func (r *attributeResolver) decisionTrace() *DecisionTrace {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.trace) == 0 {
+		return nil
+	}
+	return &DecisionTrace{Attributes: append([]AttributeLookup(nil), r.trace...)}
+}
+
+// attributeCacheKey scopes a provided attribute to the entity it describes.
+// Context attributes and attributes of an unidentified resource belong to a
+// single request and are never shared across checks.
+func attributeCacheKey(tenantID, field string, req *CheckRequest) (string, bool) {
+	var entity string
+	switch {
+	case strings.HasPrefix(field, "subject."):
+		entity = string(req.Subject.Kind) + ":" + req.Subject.ID
+	case strings.HasPrefix(field, "resource.") && req.Resource.ID != "":
+		entity = req.Resource.Type + ":" + req.Resource.ID
+	default:
+		return "", false
+	}
+	return tenantID + "\x00" + entity + "\x00" + field, true
+}
+
+// attributeCache shares provided attributes across checks for
+// Config.AttributeCacheTTL.
+type attributeCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]attributeCacheEntry
+}
+
+type attributeCacheEntry struct {
+	value    any
+	provider string
+	found    bool
+	expires  time.Time
+}
+
+func newAttributeCache(ttl time.Duration) *attributeCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &attributeCache{ttl: ttl, now: time.Now, entries: make(map[string]attributeCacheEntry)}
+}
+
+func (c *attributeCache) get(key string, cacheable bool) (attributeCacheEntry, bool) {
+	if c == nil || !cacheable {
+		return attributeCacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ent, ok := c.entries[key]
+	if !ok {
+		return attributeCacheEntry{}, false
+	}
+	if !c.now().Before(ent.expires) {
+		delete(c.entries, key)
+		return attributeCacheEntry{}, false
+	}
+	return ent, true
+}
+
+func (c *attributeCache) set(key string, ent attributeCacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= maxAttributeCacheEntries {
+		for k, old := range c.entries {
+			if !now.Before(old.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxAttributeCacheEntries {
+			clear(c.entries)
+		}
+	}
+	ent.expires = now.Add(c.ttl)
+	c.entries[key] = ent
+}
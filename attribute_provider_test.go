@@ -0,0 +1,185 @@
+package warden
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/store/memory"
+)
+
+// departmentProvider supplies subject.department from a fixed directory and
+// counts how often it is asked.
+type departmentProvider struct {
+	dept  map[string]string
+	calls map[string]int
+	err   error // returned instead of a value when set
+}
+
+func (p *departmentProvider) Name() string { return "directory" }
+
+func (p *departmentProvider) ProvideAttribute(_ context.Context, req any, field string) (any, bool, error) {
+	p.calls[field]++
+	if p.err != nil {
+		return nil, false, p.err
+	}
+	r, ok := req.(*CheckRequest)
+	if !ok || field != "subject.department" {
+		return nil, false, nil
+	}
+	d, ok := p.dept[r.Subject.ID]
+	return d, ok, nil
+}
+
+func newProviderEngine(t *testing.T, ttl time.Duration) (*Engine, *departmentProvider) {
+	t.Helper()
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	prov := &departmentProvider{dept: map[string]string{"u1": "eng", "u2": "sales"}, calls: map[string]int{}}
+	cfg := DefaultConfig()
+	cfg.AttributeCacheTTL = ttl
+	eng, err := NewEngine(WithStore(s), WithConfig(cfg), WithPlugin(prov))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1",
+		Name:     "eng-only",
+		Effect:   policy.EffectAllow,
+		IsActive: true,
+		Actions:  []string{"deploy"},
+		Conditions: []policy.Condition{
+			{Field: "subject.department", Operator: policy.OpEquals, Value: "eng"},
+			{Field: "subject.department", Operator: policy.OpNotEquals, Value: "sales"},
+		},
+	})
+	return eng, prov
+}
+
+func deployCheck(subjectID string, attrs map[string]any) *CheckRequest {
+	return &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: subjectID, Attributes: attrs},
+		Action:   Action{Name: "deploy"},
+		Resource: Resource{Type: "service", ID: "api"},
+	}
+}
+
+func TestAttributeProvider_SuppliesMissingAttribute(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, prov := newProviderEngine(t, 0)
+
+	result, err := eng.Check(ctx, deployCheck("u1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("expected allow from provided department, got %s: %s", result.Decision, result.Reason)
+	}
+	if got := prov.calls["subject.department"]; got != 1 {
+		t.Fatalf("provider calls = %d, want 1 (cached within the check)", got)
+	}
+	if result.Trace == nil || len(result.Trace.Attributes) != 1 {
+		t.Fatalf("expected one traced lookup, got %+v", result.Trace)
+	}
+	lookup := result.Trace.Attributes[0]
+	if lookup.Field != "subject.department" || lookup.Provider != "directory" || !lookup.Found || lookup.Cached {
+		t.Fatalf("unexpected lookup %+v", lookup)
+	}
+
+	result, err = eng.Check(ctx, deployCheck("u2", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatal("expected deny for sales department")
+	}
+}
+
+func TestAttributeProvider_RequestAttributesWin(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, prov := newProviderEngine(t, 0)
+
+	result, err := eng.Check(ctx, deployCheck("u2", map[string]any{"department": "eng"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("expected allow from request attribute, got %s", result.Decision)
+	}
+	if len(prov.calls) != 0 {
+		t.Fatalf("provider should not be called, got %v", prov.calls)
+	}
+	if result.Trace != nil {
+		t.Fatalf("expected no trace, got %+v", result.Trace)
+	}
+}
+
+func TestAttributeProvider_TTLCache(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, prov := newProviderEngine(t, time.Minute)
+	now := time.Now()
+	eng.attrCache.now = func() time.Time { return now }
+
+	for range 2 {
+		if _, err := eng.Check(ctx, deployCheck("u1", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := prov.calls["subject.department"]; got != 1 {
+		t.Fatalf("provider calls = %d, want 1 within TTL", got)
+	}
+
+	result, err := eng.Check(ctx, deployCheck("u1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Trace == nil || !result.Trace.Attributes[0].Cached {
+		t.Fatalf("expected cached lookup in trace, got %+v", result.Trace)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := eng.Check(ctx, deployCheck("u1", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := prov.calls["subject.department"]; got != 2 {
+		t.Fatalf("provider calls = %d, want 2 after expiry", got)
+	}
+}
+
+// TestAttributeProvider_ErrorIsIndeterminate pins that a failing provider
+// makes the check indeterminate under on_error: deny instead of reading as
+// a missing attribute, and that the failure is not cached.
+func TestAttributeProvider_ErrorIsIndeterminate(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, prov := newProviderEngine(t, time.Minute)
+	prov.err = errors.New("directory unavailable")
+
+	result, err := eng.Check(ctx, deployCheck("u1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decision != DecisionIndeterminate {
+		t.Fatalf("decision = %s, want indeterminate: %s", result.Decision, result.Reason)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error, "directory unavailable") {
+		t.Fatalf("errors = %+v", result.Errors)
+	}
+	if result.Trace == nil || result.Trace.Attributes[0].Error == "" || result.Trace.Attributes[0].Found {
+		t.Fatalf("expected failed lookup in trace, got %+v", result.Trace)
+	}
+
+	prov.err = nil
+	result, err = eng.Check(ctx, deployCheck("u1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("expected allow once the provider recovers, got %s: %s", result.Decision, result.Reason)
+	}
+	if result.Trace.Attributes[0].Cached {
+		t.Fatal("failed lookup should not have been cached")
+	}
+}
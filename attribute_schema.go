@@ -0,0 +1,110 @@
+package warden
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/xraph/warden/resourcetype"
+)
+
+// attributeSchemas coerces the subject and resource attributes a check
+// reads to the types declared on the resource type named by
+// req.Resource.Type and on the resource type named after the subject kind.
+// Only attributes that a condition reads are coerced, when first read, so
+// an attribute that cannot be coerced, is outside its enum, or is required
+// but neither on the request nor supplied by an AttributeProvider is an
+// evaluation error of the policy that reads it.
+type attributeSchemas struct {
+	engine *Engine
+	scope  tenantScope
+
+	mu      sync.Mutex
+	schemas map[string]map[string]resourcetype.AttributeDef // by resource type name
+}
+
+// withAttributeSchemas attaches the attribute schemas of scope to ctx so
+// LookupAttribute coerces declared attributes.
+func (e *Engine) withAttributeSchemas(ctx context.Context, scope tenantScope) context.Context {
+	return context.WithValue(ctx, ctxKeyAttributeSchemas, &attributeSchemas{
+		engine:  e,
+		scope:   scope,
+		schemas: make(map[string]map[string]resourcetype.AttributeDef),
+	})
+}
+
+// lookup returns the coerced value of field. ok is false when field is not
+// a declared subject or resource attribute, so the caller looks it up
+// as is.
+func (s *attributeSchemas) lookup(ctx context.Context, field string, req *CheckRequest) (v any, ok bool, err error) {
+	root, name, _ := strings.Cut(field, ".")
+	var typeName string
+	switch root {
+	case "subject":
+		typeName = string(req.Subject.Kind)
+	case "resource":
+		typeName = req.Resource.Type
+	default:
+		return nil, false, nil
+	}
+	def, ok := s.schema(ctx, typeName)[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if v, err = lookupAttribute(ctx, field, req); err != nil {
+		return nil, true, fmt.Errorf("attribute %s: %w", field, err)
+	}
+	if v == nil {
+		if def.Required {
+			return nil, true, fmt.Errorf("required attribute %s is missing", field)
+		}
+		return nil, true, nil
+	}
+	if v, err = def.Coerce(v); err != nil {
+		return nil, true, fmt.Errorf("%s %w", root, err)
+	}
+	return v, true, nil
+}
+
+// schema returns the declared attributes of the named resource type by
+// name, looking each type up at most once per check.
+func (s *attributeSchemas) schema(ctx context.Context, typeName string) map[string]resourcetype.AttributeDef {
+	if typeName == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if defs, ok := s.schemas[typeName]; ok {
+		return defs
+	}
+	var defs map[string]resourcetype.AttributeDef
+	if attrs := s.engine.attributeSchema(ctx, s.scope, typeName); len(attrs) > 0 {
+		defs = make(map[string]resourcetype.AttributeDef, len(attrs))
+		for _, def := range attrs {
+			defs[def.Name] = def
+		}
+	}
+	s.schemas[typeName] = defs
+	return defs
+}
+
+// attributeSchema returns the attributes declared on the named resource
+// type, looked up from the request's namespace outwards. With a policy
+// index the answer is cached alongside the scope's policies.
+func (e *Engine) attributeSchema(ctx context.Context, scope tenantScope, name string) []resourcetype.AttributeDef {
+	load := func() []resourcetype.AttributeDef {
+		for _, ns := range AncestorNamespaces(scope.namespacePath) {
+			rt, err := e.store.GetResourceTypeByName(ctx, scope.tenantID, ns, name)
+			if err == nil && rt != nil {
+				return rt.Attributes
+			}
+		}
+		return nil
+	}
+	if e.policyIndex == nil {
+		return load()
+	}
+	return e.policyIndex.schema(scope.tenantID, scope.namespacePath, name, load)
+}
@@ -0,0 +1,196 @@
+// Package audit defines the tamper-evident trail of administrative changes
+// to the authorization model.
+//
+// Every create, update or delete of a role, permission, role grant,
+// assignment, relation tuple, policy or resource type made through the
+// engine's store is recorded as an Entry carrying the actor, the source of
+// the change (API, DSL apply, CLI or SDK) and before/after snapshots of the
+// entity. Entries form one hash chain per tenant: each entry's Hash covers
+// its own fields and the Hash of the previous entry, so editing or deleting
+// a stored entry breaks the chain and is reported by VerifyChain.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/xraph/warden/id"
+)
+
+// Operation is the kind of change an Entry records.
+type Operation string
+
+const (
+	// OpCreate records a new entity.
+	OpCreate Operation = "create"
+
+	// OpUpdate records a change to an existing entity.
+	OpUpdate Operation = "update"
+
+	// OpDelete records a removed entity, or a bulk delete when EntityID
+	// is empty.
+	OpDelete Operation = "delete"
+)
+
+// EntityType identifies the kind of entity an Entry records.
+type EntityType string
+
+const (
+	EntityRole            EntityType = "role"
+	EntityPermission      EntityType = "permission"
+	EntityRolePermissions EntityType = "role_permissions" // the permission grants of one role
+	EntityAssignment      EntityType = "assignment"
+	EntityRelation        EntityType = "relation"
+	EntityPolicy          EntityType = "policy"
+	EntityResourceType    EntityType = "resource_type"
+	EntityBreakGlass      EntityType = "break_glass"
+	EntityCalendar        EntityType = "calendar"
+	EntitySoDConstraint   EntityType = "sod_constraint"
+)
+
+// Source identifies how a change entered the system.
+type Source string
+
+const (
+	// SourceAPI changes came through the HTTP API.
+	SourceAPI Source = "api"
+
+	// SourceDSL changes came from applying .warden configuration.
+	SourceDSL Source = "dsl"
+
+	// SourceCLI changes came from the warden command-line tool.
+	SourceCLI Source = "cli"
+
+	// SourceSDK changes were made by calling the store directly from Go.
+	// It is the default when the context carries no source.
+	SourceSDK Source = "sdk"
+)
+
+// Actor identifies who made a change.
+type Actor struct {
+	Kind string `json:"kind,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// String returns "kind:id", just the ID when Kind is empty, or "" for the
+// zero Actor.
+func (a Actor) String() string {
+	if a.Kind == "" {
+		return a.ID
+	}
+	return a.Kind + ":" + a.ID
+}
+
+// Entry is a single administrative change.
+type Entry struct {
+	ID            id.AuditID      `json:"id" db:"id"`
+	TenantID      string          `json:"tenant_id" db:"tenant_id"`
+	NamespacePath string          `json:"namespace_path,omitempty" db:"namespace_path"`
+	AppID         string          `json:"app_id" db:"app_id"`
+	Seq           int64           `json:"seq" db:"seq"` // position in the tenant's chain, starting at 1
+	Actor         Actor           `json:"actor" db:"actor"`
+	Source        Source          `json:"source" db:"source"`
+	Operation     Operation       `json:"operation" db:"operation"`
+	EntityType    EntityType      `json:"entity_type" db:"entity_type"`
+	EntityID      string          `json:"entity_id,omitempty" db:"entity_id"`
+	EntityName    string          `json:"entity_name,omitempty" db:"entity_name"` // slug or name, for display
+	Before        json.RawMessage `json:"before,omitempty" db:"before"`
+	After         json.RawMessage `json:"after,omitempty" db:"after"`
+	RequestIP     string          `json:"request_ip,omitempty" db:"request_ip"`
+	RequestID     string          `json:"request_id,omitempty" db:"request_id"`
+	PrevHash      string          `json:"prev_hash,omitempty" db:"prev_hash"`
+	Hash          string          `json:"hash" db:"hash"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Change is one top-level field that differs between Before and After.
+type Change struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Changes diffs the Before and After snapshots field by field. Creates
+// list every field of After and deletes every field of Before. The
+// updated_at timestamp is left out since every update changes it.
+func (e *Entry) Changes() []Change {
+	before := snapshotFields(e.Before)
+	after := snapshotFields(e.After)
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	delete(keys, "updated_at")
+
+	var out []Change
+	for k := range keys {
+		b, a := before[k], after[k]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		out = append(out, Change{Field: k, Before: b, After: a})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
+
+// snapshotFields decodes a JSON object snapshot. Non-object snapshots
+// (such as a role's permission list) are reported under the "value" key.
+func snapshotFields(raw json.RawMessage) map[string]any {
+	if len(raw) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil || v == nil {
+		return nil
+	}
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+	return map[string]any{"value": v}
+}
+
+// QueryFilter contains filters for querying audit entries. TenantID always
+// selects a single chain — the empty string is the global scope, not "all
+// tenants". Results are ordered by Seq, newest first unless Ascending is set.
+type QueryFilter struct {
+	TenantID   string     `json:"tenant_id,omitempty"`
+	EntityType EntityType `json:"entity_type,omitempty"`
+	EntityID   string     `json:"entity_id,omitempty"`
+	Operation  Operation  `json:"operation,omitempty"`
+	Source     Source     `json:"source,omitempty"`
+	ActorID    string     `json:"actor_id,omitempty"`
+	AfterSeq   int64      `json:"after_seq,omitempty"` // entries with Seq greater than this
+	After      *time.Time `json:"after,omitempty"`
+	Before     *time.Time `json:"before,omitempty"`
+	Ascending  bool       `json:"ascending,omitempty"`
+	Limit      int        `json:"limit,omitempty"`
+	Offset     int        `json:"offset,omitempty"`
+}
+
+// Matches reports whether e passes the filter's field and time criteria.
+// Backends without a query language use it; Limit, Offset and ordering
+// are left to the caller.
+func (f *QueryFilter) Matches(e *Entry) bool {
+	if f == nil {
+		return true
+	}
+	switch {
+	case e.TenantID != f.TenantID,
+		f.EntityType != "" && e.EntityType != f.EntityType,
+		f.EntityID != "" && e.EntityID != f.EntityID,
+		f.Operation != "" && e.Operation != f.Operation,
+		f.Source != "" && e.Source != f.Source,
+		f.ActorID != "" && e.Actor.ID != f.ActorID,
+		f.AfterSeq > 0 && e.Seq <= f.AfterSeq,
+		f.After != nil && e.CreatedAt.Before(*f.After),
+		f.Before != nil && e.CreatedAt.After(*f.Before):
+		return false
+	}
+	return true
+}
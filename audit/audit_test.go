@@ -0,0 +1,139 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/store/memory"
+)
+
+func recordChain(t *testing.T, n int) (*memory.Store, []*audit.Entry) {
+	t.Helper()
+	s := memory.New()
+	rec := audit.NewRecorder(s)
+	for i := range n {
+		e := &audit.Entry{
+			TenantID:   "t1",
+			Actor:      audit.Actor{Kind: "user", ID: "alice"},
+			Operation:  audit.OpUpdate,
+			EntityType: audit.EntityRole,
+			EntityID:   "role_1",
+			After:      json.RawMessage(`{"max_members":` + strconv.Itoa(i) + `}`),
+		}
+		if err := rec.Record(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := s.ListAuditEntries(context.Background(), &audit.QueryFilter{TenantID: "t1", Ascending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, entries
+}
+
+func TestRecorder_LinksEntries(t *testing.T) {
+	_, entries := recordChain(t, 3)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, e := range entries {
+		if e.Seq != int64(i+1) {
+			t.Errorf("entry %d seq = %d", i, e.Seq)
+		}
+		if i > 0 && e.PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d not linked to its predecessor", i)
+		}
+	}
+	if entries[0].PrevHash != "" {
+		t.Errorf("first entry prev_hash = %q, want empty", entries[0].PrevHash)
+	}
+	if err := audit.Verify(nil, entries); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func([]*audit.Entry) []*audit.Entry
+		wantSeq int64
+	}{
+		{"edited actor", func(es []*audit.Entry) []*audit.Entry {
+			es[1].Actor.ID = "mallory"
+			return es
+		}, 2},
+		{"edited snapshot", func(es []*audit.Entry) []*audit.Entry {
+			es[2].After = json.RawMessage(`{"max_members":9}`)
+			return es
+		}, 3},
+		{"removed entry", func(es []*audit.Entry) []*audit.Entry {
+			return append(es[:1], es[2:]...)
+		}, 3},
+		{"rehashed after edit", func(es []*audit.Entry) []*audit.Entry {
+			es[1].Actor.ID = "mallory"
+			es[1].Hash = es[1].ComputeHash()
+			return es
+		}, 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, entries := recordChain(t, 4)
+			err := audit.Verify(nil, tc.tamper(entries))
+			var ce *audit.ChainError
+			if !errors.As(err, &ce) || !errors.Is(err, audit.ErrChainBroken) {
+				t.Fatalf("Verify = %v, want ChainError", err)
+			}
+			if ce.Seq != tc.wantSeq {
+				t.Errorf("broken at seq %d, want %d", ce.Seq, tc.wantSeq)
+			}
+		})
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	s, _ := recordChain(t, 5)
+	v, err := audit.VerifyChain(context.Background(), s, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Valid || v.Entries != 5 || v.HeadSeq != 5 {
+		t.Errorf("VerifyChain = %+v", v)
+	}
+
+	empty, err := audit.VerifyChain(context.Background(), s, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !empty.Valid || empty.Entries != 0 {
+		t.Errorf("VerifyChain(empty) = %+v", empty)
+	}
+}
+
+func TestEntry_Changes(t *testing.T) {
+	e := &audit.Entry{
+		Before: json.RawMessage(`{"name":"Editor","slug":"editor","updated_at":"2026-01-01T00:00:00Z"}`),
+		After:  json.RawMessage(`{"name":"Writer","slug":"editor","description":"x","updated_at":"2026-01-02T00:00:00Z"}`),
+	}
+	got := e.Changes()
+	if len(got) != 2 {
+		t.Fatalf("changes = %+v, want description and name", got)
+	}
+	if got[0].Field != "description" || got[0].Before != nil || got[0].After != "x" {
+		t.Errorf("changes[0] = %+v", got[0])
+	}
+	if got[1].Field != "name" || got[1].Before != "Editor" || got[1].After != "Writer" {
+		t.Errorf("changes[1] = %+v", got[1])
+	}
+
+	grants := (&audit.Entry{
+		Before: json.RawMessage(`["doc:read"]`),
+		After:  json.RawMessage(`["doc:read","doc:write"]`),
+	}).Changes()
+	if len(grants) != 1 || grants[0].Field != "value" {
+		t.Errorf("list changes = %+v", grants)
+	}
+}
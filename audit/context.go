@@ -0,0 +1,47 @@
+package audit
+
+import "context"
+
+type contextKey int
+
+const (
+	ctxKeyActor contextKey = iota
+	ctxKeySource
+)
+
+// WithActor returns a context recording actor as the author of any
+// changes made with it. The HTTP API sets it from the authenticated user;
+// plugin hooks receive the same context and can read it with
+// ActorFromContext.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, ctxKeyActor, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(ctxKeyActor).(Actor)
+	return a, ok
+}
+
+// WithSource returns a context recording how changes made with it entered
+// the system.
+func WithSource(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, ctxKeySource, source)
+}
+
+// SourceFromContext returns the source set by WithSource, or SourceSDK.
+func SourceFromContext(ctx context.Context) Source {
+	if s, ok := ctx.Value(ctxKeySource).(Source); ok && s != "" {
+		return s
+	}
+	return SourceSDK
+}
+
+// WithDefaultSource sets source unless the context already carries one,
+// so that an outer caller (the CLI running a DSL apply) keeps precedence.
+func WithDefaultSource(ctx context.Context, source Source) context.Context {
+	if _, ok := ctx.Value(ctxKeySource).(Source); ok {
+		return ctx
+	}
+	return WithSource(ctx, source)
+}
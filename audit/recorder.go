@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xraph/warden/id"
+)
+
+// maxAppendAttempts bounds retries when another process appends to the
+// same chain between reading its head and writing the next entry.
+const maxAppendAttempts = 5
+
+// Recorder appends entries to the audit trail, linking each one to the
+// head of its tenant's chain.
+type Recorder struct {
+	store Store
+	now   func() time.Time
+
+	// mu serializes appends from this process so they do not race for
+	// the same Seq; the store's uniqueness check covers other processes.
+	mu sync.Mutex
+}
+
+// NewRecorder returns a Recorder writing to s.
+func NewRecorder(s Store) *Recorder {
+	return &Recorder{store: s, now: time.Now}
+}
+
+// Record assigns e an ID and timestamp when unset, seals it onto the head
+// of its tenant's chain and appends it.
+func (r *Recorder) Record(ctx context.Context, e *Entry) error {
+	if e.ID.IsNil() {
+		e.ID = id.NewAuditID()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = r.now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for attempt := 1; ; attempt++ {
+		prev, err := r.store.LastAuditEntry(ctx, e.TenantID)
+		if err != nil {
+			return fmt.Errorf("warden: audit chain head: %w", err)
+		}
+		Seal(e, prev)
+		err = r.store.AppendAuditEntry(ctx, e)
+		if err == nil || !errors.Is(err, ErrSequenceConflict) || attempt == maxAppendAttempts {
+			return err
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSequenceConflict is returned by AppendAuditEntry when the tenant's
+// chain already has an entry at e.Seq — another writer appended first.
+var ErrSequenceConflict = errors.New("audit: sequence already taken")
+
+// Store defines persistence operations for the audit trail. Entries are
+// append-only; there is deliberately no update or delete.
+type Store interface {
+	// AppendAuditEntry persists a sealed entry. It returns an error
+	// wrapping ErrSequenceConflict when (TenantID, Seq) is already taken.
+	AppendAuditEntry(ctx context.Context, e *Entry) error
+
+	// LastAuditEntry returns the entry with the highest Seq in a tenant's
+	// chain, or nil when the chain is empty.
+	LastAuditEntry(ctx context.Context, tenantID string) (*Entry, error)
+
+	// ListAuditEntries returns entries matching the filter.
+	ListAuditEntries(ctx context.Context, filter *QueryFilter) ([]*Entry, error)
+
+	// CountAuditEntries returns the number of entries matching the filter.
+	CountAuditEntries(ctx context.Context, filter *QueryFilter) (int64, error)
+}
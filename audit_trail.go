@@ -0,0 +1,596 @@
+package warden
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/xraph/go-utils/log"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/relation"
+	"github.com/xraph/warden/resourcetype"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/store"
+)
+
+// auditedStore wraps the engine's store and records every mutation of the
+// authorization model in the audit trail. Reads pass straight through.
+//
+// Updates and deletes read the entity first so the entry carries a before
+// snapshot. A failure to record is logged rather than returned: the change
+// itself has already been committed.
+type auditedStore struct {
+	store.Store
+	recorder *audit.Recorder
+	logger   log.Logger
+}
+
+func newAuditedStore(s store.Store, logger log.Logger) *auditedStore {
+	return &auditedStore{Store: s, recorder: audit.NewRecorder(s), logger: logger}
+}
+
+// CheckLogStats forwards to the wrapped store so checklog.QueryStats keeps
+// using a backend's native aggregation.
+func (s *auditedStore) CheckLogStats(ctx context.Context, q *checklog.StatsQuery) (*checklog.Stats, error) {
+	return checklog.QueryStats(ctx, s.Store, q)
+}
+
+// scoped identifies the tenant scope of an audited entity.
+type scoped struct {
+	tenantID, namespacePath, appID string
+}
+
+// record appends one entry for a committed change. before and after are
+// snapshotted as JSON; a nil value is recorded as no snapshot.
+func (s *auditedStore) record(ctx context.Context, sc scoped, op audit.Operation, et audit.EntityType, entityID, name string, before, after any) {
+	if sc.tenantID == "" && sc.appID == "" {
+		ts := scopeFromContext(ctx)
+		sc = scoped{tenantID: ts.tenantID, namespacePath: ts.namespacePath, appID: ts.appID}
+	}
+	actor, _ := audit.ActorFromContext(ctx) //nolint:errcheck // absent → zero Actor
+	reqIP, reqID := requestInfoFromContext(ctx)
+	e := &audit.Entry{
+		TenantID:      sc.tenantID,
+		NamespacePath: sc.namespacePath,
+		AppID:         sc.appID,
+		Actor:         actor,
+		Source:        audit.SourceFromContext(ctx),
+		Operation:     op,
+		EntityType:    et,
+		EntityID:      entityID,
+		EntityName:    name,
+		Before:        snapshot(before),
+		After:         snapshot(after),
+		RequestIP:     reqIP,
+		RequestID:     reqID,
+	}
+	// The change is committed; don't let a cancelled request drop its record.
+	if err := s.recorder.Record(context.WithoutCancel(ctx), e); err != nil {
+		s.logger.Error("warden: failed to write audit entry",
+			log.String("entity_type", string(et)),
+			log.String("entity_id", entityID),
+			log.Error(err),
+		)
+	}
+}
+
+func snapshot(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil
+	}
+	return b
+}
+
+// ──────────────────────────────────────────────────
+// Roles
+// ──────────────────────────────────────────────────
+
+func roleScope(r *role.Role) scoped { return scoped{r.TenantID, r.NamespacePath, r.AppID} }
+
+func (s *auditedStore) CreateRole(ctx context.Context, r *role.Role) error {
+	if err := s.Store.CreateRole(ctx, r); err != nil {
+		return err
+	}
+	s.record(ctx, roleScope(r), audit.OpCreate, audit.EntityRole, r.ID.String(), r.Slug, nil, r)
+	return nil
+}
+
+func (s *auditedStore) UpdateRole(ctx context.Context, r *role.Role) error {
+	before, _ := s.Store.GetRole(ctx, r.ID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.UpdateRole(ctx, r); err != nil {
+		return err
+	}
+	s.record(ctx, roleScope(r), audit.OpUpdate, audit.EntityRole, r.ID.String(), r.Slug, optional(before), r)
+	return nil
+}
+
+func (s *auditedStore) DeleteRole(ctx context.Context, roleID id.RoleID) error {
+	before, _ := s.Store.GetRole(ctx, roleID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.DeleteRole(ctx, roleID); err != nil {
+		return err
+	}
+	sc, name := scoped{}, ""
+	if before != nil {
+		sc, name = roleScope(before), before.Slug
+	}
+	s.record(ctx, sc, audit.OpDelete, audit.EntityRole, roleID.String(), name, optional(before), nil)
+	return nil
+}
+
+func (s *auditedStore) DeleteRolesByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteRolesByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityRole, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// AttachPermission, DetachPermission and SetRolePermissions are recorded
+// as updates of the role's grant list, with the full list before and after.
+
+func (s *auditedStore) AttachPermission(ctx context.Context, roleID id.RoleID, ref permission.Ref) error {
+	return s.updateGrants(ctx, roleID, func() error { return s.Store.AttachPermission(ctx, roleID, ref) })
+}
+
+func (s *auditedStore) DetachPermission(ctx context.Context, roleID id.RoleID, ref permission.Ref) error {
+	return s.updateGrants(ctx, roleID, func() error { return s.Store.DetachPermission(ctx, roleID, ref) })
+}
+
+func (s *auditedStore) SetRolePermissions(ctx context.Context, roleID id.RoleID, refs []permission.Ref) error {
+	return s.updateGrants(ctx, roleID, func() error { return s.Store.SetRolePermissions(ctx, roleID, refs) })
+}
+
+func (s *auditedStore) updateGrants(ctx context.Context, roleID id.RoleID, apply func() error) error {
+	before := s.grants(ctx, roleID)
+	if err := apply(); err != nil {
+		return err
+	}
+	after := s.grants(ctx, roleID)
+	sc, name := scoped{}, ""
+	if r, err := s.Store.GetRole(ctx, roleID); err == nil {
+		sc, name = roleScope(r), r.Slug
+	}
+	s.record(ctx, sc, audit.OpUpdate, audit.EntityRolePermissions, roleID.String(), name, before, after)
+	return nil
+}
+
+// grants returns the role's permission names, sorted by the store.
+func (s *auditedStore) grants(ctx context.Context, roleID id.RoleID) []string {
+	perms, err := s.Store.ListRolePermissions(ctx, roleID)
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		out = append(out, p.Name)
+	}
+	return out
+}
+
+// ──────────────────────────────────────────────────
+// Permissions
+// ──────────────────────────────────────────────────
+
+func permScope(p *permission.Permission) scoped { return scoped{p.TenantID, p.NamespacePath, p.AppID} }
+
+func (s *auditedStore) CreatePermission(ctx context.Context, p *permission.Permission) error {
+	if err := s.Store.CreatePermission(ctx, p); err != nil {
+		return err
+	}
+	s.record(ctx, permScope(p), audit.OpCreate, audit.EntityPermission, p.ID.String(), p.Name, nil, p)
+	return nil
+}
+
+func (s *auditedStore) UpdatePermission(ctx context.Context, p *permission.Permission) error {
+	before, _ := s.Store.GetPermission(ctx, p.ID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.UpdatePermission(ctx, p); err != nil {
+		return err
+	}
+	s.record(ctx, permScope(p), audit.OpUpdate, audit.EntityPermission, p.ID.String(), p.Name, optional(before), p)
+	return nil
+}
+
+func (s *auditedStore) DeletePermission(ctx context.Context, permID id.PermissionID) error {
+	before, _ := s.Store.GetPermission(ctx, permID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.DeletePermission(ctx, permID); err != nil {
+		return err
+	}
+	sc, name := scoped{}, ""
+	if before != nil {
+		sc, name = permScope(before), before.Name
+	}
+	s.record(ctx, sc, audit.OpDelete, audit.EntityPermission, permID.String(), name, optional(before), nil)
+	return nil
+}
+
+func (s *auditedStore) DeletePermissionsByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeletePermissionsByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityPermission, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Assignments
+// ──────────────────────────────────────────────────
+
+func assignmentScope(a *assignment.Assignment) scoped {
+	return scoped{a.TenantID, a.NamespacePath, a.AppID}
+}
+
+func assignmentName(a *assignment.Assignment) string {
+	return a.SubjectKind + ":" + a.SubjectID
+}
+
+func (s *auditedStore) CreateAssignment(ctx context.Context, a *assignment.Assignment) error {
+	if err := s.Store.CreateAssignment(ctx, a); err != nil {
+		return err
+	}
+	s.record(ctx, assignmentScope(a), audit.OpCreate, audit.EntityAssignment, a.ID.String(), assignmentName(a), nil, a)
+	return nil
+}
+
+func (s *auditedStore) DeleteAssignment(ctx context.Context, assID id.AssignmentID) error {
+	before, _ := s.Store.GetAssignment(ctx, assID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.DeleteAssignment(ctx, assID); err != nil {
+		return err
+	}
+	sc, name := scoped{}, ""
+	if before != nil {
+		sc, name = assignmentScope(before), assignmentName(before)
+	}
+	s.record(ctx, sc, audit.OpDelete, audit.EntityAssignment, assID.String(), name, optional(before), nil)
+	return nil
+}
+
+func (s *auditedStore) DeleteExpiredAssignments(ctx context.Context, now time.Time) (int64, error) {
+	n, err := s.Store.DeleteExpiredAssignments(ctx, now)
+	if err != nil || n == 0 {
+		return n, err
+	}
+	s.recordBulkDelete(ctx, "", audit.EntityAssignment, map[string]any{"expired_before": now, "count": n})
+	return n, nil
+}
+
+func (s *auditedStore) DeleteAssignmentsBySubject(ctx context.Context, tenantID, subjectKind, subjectID string) error {
+	if err := s.Store.DeleteAssignmentsBySubject(ctx, tenantID, subjectKind, subjectID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityAssignment, map[string]string{
+		"tenant_id": tenantID, "subject_kind": subjectKind, "subject_id": subjectID,
+	})
+	return nil
+}
+
+func (s *auditedStore) DeleteAssignmentsByRole(ctx context.Context, roleID id.RoleID) error {
+	tenantID := ""
+	if r, err := s.Store.GetRole(ctx, roleID); err == nil {
+		tenantID = r.TenantID
+	}
+	if err := s.Store.DeleteAssignmentsByRole(ctx, roleID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityAssignment, map[string]string{"role_id": roleID.String()})
+	return nil
+}
+
+func (s *auditedStore) DeleteAssignmentsByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteAssignmentsByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityAssignment, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Relations
+// ──────────────────────────────────────────────────
+
+func tupleName(t *relation.Tuple) string {
+	return t.ObjectType + ":" + t.ObjectID + "#" + t.Relation + "@" + t.SubjectType + ":" + t.SubjectID
+}
+
+func (s *auditedStore) CreateRelation(ctx context.Context, t *relation.Tuple) error {
+	if err := s.Store.CreateRelation(ctx, t); err != nil {
+		return err
+	}
+	s.record(ctx, scoped{t.TenantID, t.NamespacePath, t.AppID}, audit.OpCreate, audit.EntityRelation, t.ID.String(), tupleName(t), nil, t)
+	return nil
+}
+
+// DeleteRelation has no before snapshot: the relation store cannot fetch a
+// tuple by ID.
+func (s *auditedStore) DeleteRelation(ctx context.Context, relID id.RelationID) error {
+	if err := s.Store.DeleteRelation(ctx, relID); err != nil {
+		return err
+	}
+	s.record(ctx, scoped{}, audit.OpDelete, audit.EntityRelation, relID.String(), "", nil, nil)
+	return nil
+}
+
+func (s *auditedStore) DeleteRelationTuple(ctx context.Context, tenantID, namespacePath, objectType, objectID, rel, subjectType, subjectID string) error {
+	if err := s.Store.DeleteRelationTuple(ctx, tenantID, namespacePath, objectType, objectID, rel, subjectType, subjectID); err != nil {
+		return err
+	}
+	t := &relation.Tuple{
+		TenantID: tenantID, NamespacePath: namespacePath,
+		ObjectType: objectType, ObjectID: objectID, Relation: rel,
+		SubjectType: subjectType, SubjectID: subjectID,
+	}
+	s.record(ctx, scoped{tenantID: tenantID, namespacePath: namespacePath}, audit.OpDelete, audit.EntityRelation, "", tupleName(t), t, nil)
+	return nil
+}
+
+func (s *auditedStore) DeleteRelationsByObject(ctx context.Context, tenantID, objectType, objectID string) error {
+	if err := s.Store.DeleteRelationsByObject(ctx, tenantID, objectType, objectID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityRelation, map[string]string{
+		"tenant_id": tenantID, "object_type": objectType, "object_id": objectID,
+	})
+	return nil
+}
+
+func (s *auditedStore) DeleteRelationsBySubject(ctx context.Context, tenantID, subjectType, subjectID string) error {
+	if err := s.Store.DeleteRelationsBySubject(ctx, tenantID, subjectType, subjectID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityRelation, map[string]string{
+		"tenant_id": tenantID, "subject_type": subjectType, "subject_id": subjectID,
+	})
+	return nil
+}
+
+func (s *auditedStore) DeleteRelationsByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteRelationsByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityRelation, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Policies
+// ──────────────────────────────────────────────────
+
+func policyScope(p *policy.Policy) scoped { return scoped{p.TenantID, p.NamespacePath, p.AppID} }
+
+func (s *auditedStore) CreatePolicy(ctx context.Context, p *policy.Policy) error {
+	if err := s.Store.CreatePolicy(ctx, p); err != nil {
+		return err
+	}
+	s.record(ctx, policyScope(p), audit.OpCreate, audit.EntityPolicy, p.ID.String(), p.Name, nil, p)
+	return nil
+}
+
+func (s *auditedStore) UpdatePolicy(ctx context.Context, p *policy.Policy) error {
+	before, _ := s.Store.GetPolicy(ctx, p.ID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.UpdatePolicy(ctx, p); err != nil {
+		return err
+	}
+	s.record(ctx, policyScope(p), audit.OpUpdate, audit.EntityPolicy, p.ID.String(), p.Name, optional(before), p)
+	return nil
+}
+
+func (s *auditedStore) SetPolicyVersion(ctx context.Context, polID id.PolicyID, version int) error {
+	before, _ := s.Store.GetPolicy(ctx, polID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.SetPolicyVersion(ctx, polID, version); err != nil {
+		return err
+	}
+	after, _ := s.Store.GetPolicy(ctx, polID) //nolint:errcheck // missing → no after snapshot
+	sc, name := scoped{}, ""
+	if after != nil {
+		sc, name = policyScope(after), after.Name
+	}
+	s.record(ctx, sc, audit.OpUpdate, audit.EntityPolicy, polID.String(), name, optional(before), optional(after))
+	return nil
+}
+
+func (s *auditedStore) DeletePolicy(ctx context.Context, polID id.PolicyID) error {
+	before, _ := s.Store.GetPolicy(ctx, polID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.DeletePolicy(ctx, polID); err != nil {
+		return err
+	}
+	sc, name := scoped{}, ""
+	if before != nil {
+		sc, name = policyScope(before), before.Name
+	}
+	s.record(ctx, sc, audit.OpDelete, audit.EntityPolicy, polID.String(), name, optional(before), nil)
+	return nil
+}
+
+func (s *auditedStore) DeletePoliciesByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeletePoliciesByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityPolicy, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Resource types
+// ──────────────────────────────────────────────────
+
+func resourceTypeScope(rt *resourcetype.ResourceType) scoped {
+	return scoped{rt.TenantID, rt.NamespacePath, rt.AppID}
+}
+
+func (s *auditedStore) CreateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error {
+	if err := s.Store.CreateResourceType(ctx, rt); err != nil {
+		return err
+	}
+	s.record(ctx, resourceTypeScope(rt), audit.OpCreate, audit.EntityResourceType, rt.ID.String(), rt.Name, nil, rt)
+	return nil
+}
+
+func (s *auditedStore) UpdateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error {
+	before, _ := s.Store.GetResourceType(ctx, rt.ID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.UpdateResourceType(ctx, rt); err != nil {
+		return err
+	}
+	s.record(ctx, resourceTypeScope(rt), audit.OpUpdate, audit.EntityResourceType, rt.ID.String(), rt.Name, optional(before), rt)
+	return nil
+}
+
+func (s *auditedStore) DeleteResourceType(ctx context.Context, rtID id.ResourceTypeID) error {
+	before, _ := s.Store.GetResourceType(ctx, rtID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.DeleteResourceType(ctx, rtID); err != nil {
+		return err
+	}
+	sc, name := scoped{}, ""
+	if before != nil {
+		sc, name = resourceTypeScope(before), before.Name
+	}
+	s.record(ctx, sc, audit.OpDelete, audit.EntityResourceType, rtID.String(), name, optional(before), nil)
+	return nil
+}
+
+func (s *auditedStore) DeleteResourceTypesByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteResourceTypesByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityResourceType, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass grants
+// ──────────────────────────────────────────────────
+
+func breakGlassScope(g *breakglass.Grant) scoped {
+	return scoped{g.TenantID, g.NamespacePath, g.AppID}
+}
+
+func (s *auditedStore) CreateBreakGlassGrant(ctx context.Context, g *breakglass.Grant) error {
+	if err := s.Store.CreateBreakGlassGrant(ctx, g); err != nil {
+		return err
+	}
+	s.record(ctx, breakGlassScope(g), audit.OpCreate, audit.EntityBreakGlass, g.ID.String(), g.SubjectKind+":"+g.SubjectID, nil, g)
+	return nil
+}
+
+func (s *auditedStore) UpdateBreakGlassGrant(ctx context.Context, g *breakglass.Grant) error {
+	before, _ := s.Store.GetBreakGlassGrant(ctx, g.ID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.UpdateBreakGlassGrant(ctx, g); err != nil {
+		return err
+	}
+	s.record(ctx, breakGlassScope(g), audit.OpUpdate, audit.EntityBreakGlass, g.ID.String(), g.SubjectKind+":"+g.SubjectID, optional(before), g)
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Calendars
+// ──────────────────────────────────────────────────
+
+func calendarScope(c *calendar.Calendar) scoped { return scoped{c.TenantID, "", c.AppID} }
+
+func (s *auditedStore) CreateCalendar(ctx context.Context, c *calendar.Calendar) error {
+	if err := s.Store.CreateCalendar(ctx, c); err != nil {
+		return err
+	}
+	s.record(ctx, calendarScope(c), audit.OpCreate, audit.EntityCalendar, c.ID.String(), c.Name, nil, c)
+	return nil
+}
+
+func (s *auditedStore) UpdateCalendar(ctx context.Context, c *calendar.Calendar) error {
+	before, _ := s.Store.GetCalendar(ctx, c.ID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.UpdateCalendar(ctx, c); err != nil {
+		return err
+	}
+	s.record(ctx, calendarScope(c), audit.OpUpdate, audit.EntityCalendar, c.ID.String(), c.Name, optional(before), c)
+	return nil
+}
+
+func (s *auditedStore) DeleteCalendar(ctx context.Context, calID id.CalendarID) error {
+	before, _ := s.Store.GetCalendar(ctx, calID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.DeleteCalendar(ctx, calID); err != nil {
+		return err
+	}
+	sc, name := scoped{}, ""
+	if before != nil {
+		sc, name = calendarScope(before), before.Name
+	}
+	s.record(ctx, sc, audit.OpDelete, audit.EntityCalendar, calID.String(), name, optional(before), nil)
+	return nil
+}
+
+func (s *auditedStore) DeleteCalendarsByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteCalendarsByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntityCalendar, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Separation-of-duties constraints
+// ──────────────────────────────────────────────────
+
+func sodScope(c *sod.Constraint) scoped { return scoped{c.TenantID, "", c.AppID} }
+
+func (s *auditedStore) CreateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	if err := s.Store.CreateSoDConstraint(ctx, c); err != nil {
+		return err
+	}
+	s.record(ctx, sodScope(c), audit.OpCreate, audit.EntitySoDConstraint, c.ID.String(), c.Name, nil, c)
+	return nil
+}
+
+func (s *auditedStore) UpdateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	before, _ := s.Store.GetSoDConstraint(ctx, c.ID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.UpdateSoDConstraint(ctx, c); err != nil {
+		return err
+	}
+	s.record(ctx, sodScope(c), audit.OpUpdate, audit.EntitySoDConstraint, c.ID.String(), c.Name, optional(before), c)
+	return nil
+}
+
+func (s *auditedStore) DeleteSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) error {
+	before, _ := s.Store.GetSoDConstraint(ctx, sodID) //nolint:errcheck // missing → no before snapshot
+	if err := s.Store.DeleteSoDConstraint(ctx, sodID); err != nil {
+		return err
+	}
+	sc, name := scoped{}, ""
+	if before != nil {
+		sc, name = sodScope(before), before.Name
+	}
+	s.record(ctx, sc, audit.OpDelete, audit.EntitySoDConstraint, sodID.String(), name, optional(before), nil)
+	return nil
+}
+
+func (s *auditedStore) DeleteSoDConstraintsByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteSoDConstraintsByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.recordBulkDelete(ctx, tenantID, audit.EntitySoDConstraint, map[string]string{"tenant_id": tenantID})
+	return nil
+}
+
+// recordBulkDelete records a delete of every entity matching criteria as a
+// single entry with no EntityID; the criteria are the before snapshot.
+func (s *auditedStore) recordBulkDelete(ctx context.Context, tenantID string, et audit.EntityType, criteria any) {
+	s.record(ctx, scoped{tenantID: tenantID}, audit.OpDelete, et, "", "", criteria, nil)
+}
+
+// optional converts a possibly-nil typed pointer into an untyped nil so
+// snapshot records no state for it.
+func optional[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,139 @@
+package warden
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/store/memory"
+)
+
+func TestAuditTrail_RecordsMutations(t *testing.T) {
+	eng, raw := newTestEngine(t)
+	s := eng.Store()
+	ctx := audit.WithActor(context.Background(), audit.Actor{Kind: "user", ID: "alice"})
+	ctx = audit.WithSource(ctx, audit.SourceAPI)
+	ctx = WithRequestInfo(ctx, "10.0.0.1", "req-1")
+
+	r := &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: "Editor", Slug: "editor"}
+	if err := s.CreateRole(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	r.Name = "Writer"
+	if err := s.UpdateRole(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "doc:read", Resource: "doc", Action: "read"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AttachPermission(ctx, r.ID, permission.Ref{Name: "doc:read"}); err != nil {
+		t.Fatal(err)
+	}
+	a := &assignment.Assignment{ID: id.NewAssignmentID(), TenantID: "t1", RoleID: r.ID, SubjectKind: "user", SubjectID: "u1"}
+	if err := s.CreateAssignment(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteAssignment(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteRole(ctx, r.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := raw.ListAuditEntries(context.Background(), &audit.QueryFilter{TenantID: "t1", Ascending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		op audit.Operation
+		et audit.EntityType
+	}{
+		{audit.OpCreate, audit.EntityRole},
+		{audit.OpUpdate, audit.EntityRole},
+		{audit.OpCreate, audit.EntityPermission},
+		{audit.OpUpdate, audit.EntityRolePermissions},
+		{audit.OpCreate, audit.EntityAssignment},
+		{audit.OpDelete, audit.EntityAssignment},
+		{audit.OpDelete, audit.EntityRole},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d audit entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Operation != w.op || e.EntityType != w.et {
+			t.Errorf("entry %d = %s %s, want %s %s", i, e.Operation, e.EntityType, w.op, w.et)
+		}
+		if e.Actor.ID != "alice" || e.Source != audit.SourceAPI || e.RequestID != "req-1" {
+			t.Errorf("entry %d provenance = %+v / %s / %s", i, e.Actor, e.Source, e.RequestID)
+		}
+	}
+
+	changes := entries[1].Changes()
+	if len(changes) != 1 || changes[0].Field != "name" || changes[0].Before != "Editor" || changes[0].After != "Writer" {
+		t.Errorf("update changes = %+v, want name Editor → Writer", changes)
+	}
+	grants := entries[3].Changes()
+	if len(grants) != 1 || grants[0].Field != "value" {
+		t.Errorf("grant changes = %+v", grants)
+	}
+	if entries[6].Before == nil || entries[6].EntityName != "editor" {
+		t.Errorf("delete entry lacks before snapshot: %+v", entries[6])
+	}
+
+	v, err := audit.VerifyChain(context.Background(), raw, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Valid || v.Entries != int64(len(want)) {
+		t.Errorf("VerifyChain = %+v", v)
+	}
+}
+
+func TestAuditTrail_FailedMutationNotRecorded(t *testing.T) {
+	eng, raw := newTestEngine(t)
+	ctx := context.Background()
+
+	p := &policy.Policy{ID: id.NewPolicyID(), TenantID: "t1", Name: "freeze", Effect: policy.EffectDeny}
+	if err := eng.Store().UpdatePolicy(ctx, p); err == nil {
+		t.Fatal("expected update of a missing policy to fail")
+	}
+	if err := eng.Store().CreatePolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := raw.ListAuditEntries(ctx, &audit.QueryFilter{TenantID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].EntityType != audit.EntityPolicy || entries[0].Source != audit.SourceSDK {
+		t.Fatalf("entries = %+v, want one sdk policy create", entries)
+	}
+}
+
+func TestAuditTrail_Disabled(t *testing.T) {
+	s := memory.New()
+	off := false
+	cfg := DefaultConfig()
+	cfg.EnableAudit = &off
+	eng, err := NewEngine(WithStore(s), WithConfig(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := eng.Store().CreateRole(ctx, &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: "Viewer", Slug: "viewer"}); err != nil {
+		t.Fatal(err)
+	}
+	n, err := s.CountAuditEntries(ctx, &audit.QueryFilter{TenantID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("recorded %d entries with audit disabled", n)
+	}
+}
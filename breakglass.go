@@ -0,0 +1,314 @@
+package warden
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/role"
+)
+
+// DefaultBreakGlassMaxDuration caps a break-glass grant when
+// BreakGlassConfig.MaxDuration is zero.
+const DefaultBreakGlassMaxDuration = 4 * time.Hour
+
+// ObligationBreakGlass is the obligation carried by every check result
+// served by a break-glass grant. Its parameters are the grant ID, the
+// justification and the expiry.
+const ObligationBreakGlass = "break_glass"
+
+// breakGlassSource is the MatchInfo.Source of a break-glass match.
+const breakGlassSource = "break_glass"
+
+// BreakGlassConfig controls who may break glass and what it grants.
+//
+// Patterns use the same glob syntax as CheckLogRules. Subject patterns
+// match "kind:id", e.g. "user:*" or "user:oncall-*".
+type BreakGlassConfig struct {
+	// Subjects may request break-glass access. Empty disables break-glass:
+	// requests are rejected and existing grants are ignored by Check.
+	Subjects []string `json:"subjects,omitempty"`
+
+	// Roles are the slugs of roles a grant may confer.
+	Roles []string `json:"roles,omitempty"`
+
+	// Policies are the names of deny policies a grant may bypass.
+	Policies []string `json:"policies,omitempty"`
+
+	// MaxDuration is the longest a grant may last. Defaults to
+	// DefaultBreakGlassMaxDuration.
+	MaxDuration time.Duration `json:"max_duration,omitempty"`
+}
+
+func (c BreakGlassConfig) enabled() bool { return len(c.Subjects) > 0 }
+
+func (c BreakGlassConfig) allows(s Subject) bool {
+	return matchesAny(c.Subjects, string(s.Kind)+":"+s.ID)
+}
+
+func (c BreakGlassConfig) maxDuration() time.Duration {
+	if c.MaxDuration > 0 {
+		return c.MaxDuration
+	}
+	return DefaultBreakGlassMaxDuration
+}
+
+// BreakGlassRequest asks for time-boxed emergency access.
+type BreakGlassRequest struct {
+	Subject Subject `json:"subject"`
+
+	// Justification explains the emergency. It is required and recorded
+	// on the grant, in the audit trail and on every check it serves.
+	Justification string `json:"justification"`
+
+	// Duration is how long the grant lasts. Zero requests the configured
+	// maximum.
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// Roles are slugs of eligible roles to confer for the duration.
+	Roles []string `json:"roles,omitempty"`
+
+	// BypassPolicies are names of eligible deny policies to skip.
+	BypassPolicies []string `json:"bypass_policies,omitempty"`
+
+	// TenantID and NamespacePath override the scope from ctx.
+	TenantID      string `json:"tenant_id,omitempty"`
+	NamespacePath string `json:"namespace_path,omitempty"`
+}
+
+// BreakGlass grants the subject emergency access: the requested roles and
+// deny-policy bypasses apply to its checks until the grant expires or is
+// revoked. At least one role or policy must be requested, and all of them
+// must be eligible under Config.BreakGlass.
+func (e *Engine) BreakGlass(ctx context.Context, req *BreakGlassRequest) (*breakglass.Grant, error) {
+	cfg := e.config.BreakGlass
+	if !cfg.enabled() {
+		return nil, fmt.Errorf("%w: break-glass is not enabled", ErrBreakGlassNotAllowed)
+	}
+	if req.Subject.ID == "" {
+		return nil, fmt.Errorf("warden: subject ID is required")
+	}
+	if strings.TrimSpace(req.Justification) == "" {
+		return nil, fmt.Errorf("%w: a justification is required", ErrBreakGlassNotAllowed)
+	}
+	if !cfg.allows(req.Subject) {
+		return nil, fmt.Errorf("%w: subject %s:%s may not break glass", ErrBreakGlassNotAllowed, req.Subject.Kind, req.Subject.ID)
+	}
+	if len(req.Roles) == 0 && len(req.BypassPolicies) == 0 {
+		return nil, fmt.Errorf("%w: request at least one role or policy bypass", ErrBreakGlassNotAllowed)
+	}
+	for _, slug := range req.Roles {
+		if !matchesAny(cfg.Roles, slug) {
+			return nil, fmt.Errorf("%w: role %q is not eligible", ErrBreakGlassNotAllowed, slug)
+		}
+	}
+	for _, name := range req.BypassPolicies {
+		if !matchesAny(cfg.Policies, name) {
+			return nil, fmt.Errorf("%w: policy %q may not be bypassed", ErrBreakGlassNotAllowed, name)
+		}
+	}
+	duration := req.Duration
+	if duration <= 0 {
+		duration = cfg.maxDuration()
+	}
+	if duration > cfg.maxDuration() {
+		return nil, fmt.Errorf("%w: duration %s exceeds the maximum of %s", ErrBreakGlassNotAllowed, duration, cfg.maxDuration())
+	}
+
+	scope := scopeFromContext(ctx)
+	if req.TenantID != "" {
+		scope.tenantID = req.TenantID
+	}
+	if req.NamespacePath != "" {
+		scope.namespacePath = req.NamespacePath
+	}
+
+	roleIDs := make([]id.RoleID, 0, len(req.Roles))
+	for _, slug := range req.Roles {
+		r, err := e.findRoleBySlug(ctx, scope, slug)
+		if err != nil {
+			return nil, err
+		}
+		roleIDs = append(roleIDs, r.ID)
+	}
+
+	now := time.Now().UTC()
+	g := &breakglass.Grant{
+		ID:             id.NewBreakGlassID(),
+		TenantID:       scope.tenantID,
+		NamespacePath:  scope.namespacePath,
+		AppID:          scope.appID,
+		SubjectKind:    string(req.Subject.Kind),
+		SubjectID:      req.Subject.ID,
+		Justification:  req.Justification,
+		RoleIDs:        roleIDs,
+		BypassPolicies: slices.Clone(req.BypassPolicies),
+		ExpiresAt:      now.Add(duration),
+		CreatedAt:      now,
+	}
+	if err := e.store.CreateBreakGlassGrant(ctx, g); err != nil {
+		return nil, fmt.Errorf("warden: break glass: %w", err)
+	}
+
+	// Denies cached before the grant would otherwise outlive it.
+	if e.cache != nil {
+		e.cache.InvalidateSubject(ctx, scope.tenantID, req.Subject.Kind, req.Subject.ID)
+	}
+	if e.plugins != nil {
+		e.plugins.EmitBreakGlassActivated(ctx, g)
+	}
+	return g, nil
+}
+
+// RevokeBreakGlass ends a grant before it expires. revokedBy identifies
+// who revoked it and is recorded on the grant. When ctx carries a tenant,
+// a grant of another tenant is reported as not found.
+func (e *Engine) RevokeBreakGlass(ctx context.Context, grantID id.BreakGlassID, revokedBy string) (*breakglass.Grant, error) {
+	g, err := e.store.GetBreakGlassGrant(ctx, grantID)
+	if err != nil {
+		return nil, fmt.Errorf("warden: revoke break glass: %w", err)
+	}
+	if tenantID := scopeFromContext(ctx).tenantID; tenantID != "" && g.TenantID != tenantID {
+		return nil, fmt.Errorf("warden: revoke break glass: grant %s: %w", grantID, ErrNotFound)
+	}
+	now := time.Now().UTC()
+	if !g.ActiveAt(now) {
+		return nil, fmt.Errorf("%w: grant %s", ErrBreakGlassInactive, grantID)
+	}
+	g.RevokedAt = &now
+	g.RevokedBy = revokedBy
+	if err := e.store.UpdateBreakGlassGrant(ctx, g); err != nil {
+		return nil, fmt.Errorf("warden: revoke break glass: %w", err)
+	}
+
+	// Results served by break-glass are never cached, so there is nothing
+	// to invalidate.
+	if e.plugins != nil {
+		e.plugins.EmitBreakGlassRevoked(ctx, g)
+	}
+	return g, nil
+}
+
+// findRoleBySlug resolves slug at the scope's namespace or its nearest
+// ancestor defining it.
+func (e *Engine) findRoleBySlug(ctx context.Context, scope tenantScope, slug string) (*role.Role, error) {
+	for _, ns := range AncestorNamespaces(scope.namespacePath) {
+		if r, err := e.store.GetRoleBySlug(ctx, scope.tenantID, ns, slug); err == nil && r != nil {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrRoleNotFound, slug)
+}
+
+// applyBreakGlass re-evaluates a denied check with each of the subject's
+// active grants in turn, newest first. It returns the result and grant of
+// the first grant that allows the check, or nil when none does.
+func (e *Engine) applyBreakGlass(ctx context.Context, scope tenantScope, req *CheckRequest, rbac, rebac, abac *CheckResult) (*CheckResult, *breakglass.Grant, error) {
+	if !e.config.BreakGlass.allows(req.Subject) {
+		return nil, nil, nil
+	}
+	now := time.Now()
+	grants, err := traceStore(ctx, "ListBreakGlassGrants", func(ctx context.Context) ([]*breakglass.Grant, error) {
+		return e.store.ListBreakGlassGrants(ctx, &breakglass.ListFilter{
+			TenantID:    scope.tenantID,
+			SubjectKind: string(req.Subject.Kind),
+			SubjectID:   req.Subject.ID,
+			ActiveAt:    &now,
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, g := range grants {
+		if !IsAncestorOrSelf(g.NamespacePath, scope.namespacePath) {
+			continue
+		}
+
+		rb := rbac
+		if len(g.RoleIDs) > 0 && e.config.rbacEnabled() {
+			permName := req.Resource.Type + ":" + req.Action.Name
+			if grants := e.matchRolePermissions(ctx, e.resolveInheritedRoles(ctx, g.RoleIDs), permName, true); len(grants) > 0 {
+				rb = &CheckResult{
+					Allowed:  true,
+					Decision: DecisionAllow,
+					MatchedBy: []MatchInfo{{
+						Source: breakGlassSource,
+						RuleID: g.ID.String(),
+						Detail: "role " + grants[0].roleID.String() + " grants " + grants[0].permission,
+					}},
+				}
+			}
+		}
+
+		ab, bypassed := abac, []string(nil)
+		if abac != nil && abac.Decision == DecisionDenyExplicit && len(g.BypassPolicies) > 0 {
+			ab, bypassed, err = e.evaluateABACBypassing(ctx, scope, req, g)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		out := e.mergeDecisions(req, rb, rebac, ab)
+		if !out.Allowed {
+			continue
+		}
+		return withBreakGlass(out, g, bypassed), g, nil
+	}
+	return nil, nil, nil
+}
+
+// evaluateABACBypassing evaluates the active policies minus the deny
+// policies g bypasses, and returns the names of those it skipped.
+func (e *Engine) evaluateABACBypassing(ctx context.Context, scope tenantScope, req *CheckRequest, g *breakglass.Grant) (*CheckResult, []string, error) {
+	policies, active, err := e.activePolicies(ctx, scope, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	var bypassed []string
+	kept := make([]*policy.Policy, 0, len(policies))
+	for _, pol := range policies {
+		if pol.Effect == policy.EffectDeny && g.Bypasses(pol.Name) {
+			bypassed = append(bypassed, pol.Name)
+			continue
+		}
+		kept = append(kept, pol)
+	}
+	res, err := e.evaluatePolicies(ctx, scope, kept, active, req)
+	return res, bypassed, err
+}
+
+// withBreakGlass marks result as served by g: a break-glass match (unless
+// a grant role already supplied one) and the break_glass obligation.
+func withBreakGlass(result *CheckResult, g *breakglass.Grant, bypassed []string) *CheckResult {
+	if !servedByBreakGlass(result) {
+		result.MatchedBy = append(result.MatchedBy, MatchInfo{
+			Source: breakGlassSource,
+			RuleID: g.ID.String(),
+			Detail: "bypassed policies " + strings.Join(bypassed, ", "),
+		})
+	}
+	result.StructuredObligations = append(result.StructuredObligations, Obligation{
+		Name: ObligationBreakGlass,
+		Params: map[string]any{
+			"grant_id":      g.ID.String(),
+			"justification": g.Justification,
+			"expires_at":    g.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+		FulfillOn: policy.FulfillOnAllow,
+	})
+	return result
+}
+
+// servedByBreakGlass reports whether a break-glass grant contributed to
+// result.
+func servedByBreakGlass(result *CheckResult) bool {
+	return slices.ContainsFunc(result.MatchedBy, func(m MatchInfo) bool {
+		return m.Source == breakGlassSource
+	})
+}
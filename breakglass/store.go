@@ -0,0 +1,25 @@
+package breakglass
+
+import (
+	"context"
+
+	"github.com/xraph/warden/id"
+)
+
+// Store defines persistence operations for break-glass grants. Grants are
+// never deleted; revoking one sets RevokedAt.
+type Store interface {
+	// CreateBreakGlassGrant persists a new grant.
+	CreateBreakGlassGrant(ctx context.Context, g *Grant) error
+
+	// GetBreakGlassGrant returns a grant by ID.
+	GetBreakGlassGrant(ctx context.Context, grantID id.BreakGlassID) (*Grant, error)
+
+	// UpdateBreakGlassGrant persists changes to a grant, e.g. its
+	// revocation.
+	UpdateBreakGlassGrant(ctx context.Context, g *Grant) error
+
+	// ListBreakGlassGrants returns grants matching the filter, newest
+	// first.
+	ListBreakGlassGrants(ctx context.Context, filter *ListFilter) ([]*Grant, error)
+}
@@ -0,0 +1,253 @@
+package warden
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/plugin"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/resourcetype"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/store/memory"
+)
+
+// breakGlassCapturePlugin records break-glass lifecycle events.
+type breakGlassCapturePlugin struct {
+	activated, used, revoked []*breakglass.Grant
+}
+
+func (p *breakGlassCapturePlugin) Name() string { return "break-glass-capture" }
+func (p *breakGlassCapturePlugin) OnBreakGlassActivated(_ context.Context, g *breakglass.Grant) error {
+	p.activated = append(p.activated, g)
+	return nil
+}
+func (p *breakGlassCapturePlugin) OnBreakGlassUsed(_ context.Context, g *breakglass.Grant, _, _ any) error {
+	p.used = append(p.used, g)
+	return nil
+}
+func (p *breakGlassCapturePlugin) OnBreakGlassRevoked(_ context.Context, g *breakglass.Grant) error {
+	p.revoked = append(p.revoked, g)
+	return nil
+}
+
+var (
+	_ plugin.BreakGlassActivated = (*breakGlassCapturePlugin)(nil)
+	_ plugin.BreakGlassUsed      = (*breakGlassCapturePlugin)(nil)
+	_ plugin.BreakGlassRevoked   = (*breakGlassCapturePlugin)(nil)
+)
+
+func newBreakGlassEngine(t *testing.T, ctx context.Context) (*Engine, *memory.Store, *breakGlassCapturePlugin) {
+	t.Helper()
+	s := memory.New()
+	r := &role.Role{TenantID: "t1", Name: "Responder", Slug: "responder"}
+	if err := s.CreateRole(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreatePermission(ctx, &permission.Permission{TenantID: "t1", Name: "database:restart", Resource: "database", Action: "restart"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AttachPermission(ctx, r.ID, permission.Ref{Name: "database:restart"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.BreakGlass = BreakGlassConfig{
+		Subjects:    []string{"user:oncall-*"},
+		Roles:       []string{"responder"},
+		Policies:    []string{"freeze-*"},
+		MaxDuration: time.Hour,
+	}
+	capPlugin := &breakGlassCapturePlugin{}
+	eng, err := NewEngine(WithStore(s), WithConfig(cfg), WithPlugin(capPlugin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return eng, s, capPlugin
+}
+
+func hasBreakGlassObligation(res *CheckResult) bool {
+	for _, ob := range res.StructuredObligations {
+		if ob.Name == ObligationBreakGlass {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEngine_BreakGlassRole(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, _, capPlugin := newBreakGlassEngine(t, ctx)
+	oncall := Subject{Kind: SubjectUser, ID: "oncall-ana"}
+	restart := &CheckRequest{Subject: oncall, Action: Action{Name: "restart"}, Resource: Resource{Type: "database", ID: "db1"}}
+
+	res, err := eng.Check(ctx, restart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed {
+		t.Fatal("expected deny before breaking glass")
+	}
+
+	g, err := eng.BreakGlass(ctx, &BreakGlassRequest{
+		Subject:       oncall,
+		Justification: "INC-42: primary database is wedged",
+		Duration:      30 * time.Minute,
+		Roles:         []string{"responder"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capPlugin.activated) != 1 {
+		t.Errorf("activated events = %d, want 1", len(capPlugin.activated))
+	}
+
+	res, err = eng.Check(ctx, restart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed {
+		t.Fatalf("expected allow with break-glass, got %s: %s", res.Decision, res.Reason)
+	}
+	if !hasBreakGlassObligation(res) || !servedByBreakGlass(res) {
+		t.Errorf("result not marked as break-glass: %+v", res)
+	}
+	if res.MatchedBy[0].RuleID != g.ID.String() {
+		t.Errorf("MatchedBy = %+v", res.MatchedBy)
+	}
+	if len(capPlugin.used) != 1 {
+		t.Errorf("used events = %d, want 1", len(capPlugin.used))
+	}
+
+	// Revocation takes effect on the next check.
+	if _, err := eng.RevokeBreakGlass(ctx, g.ID, "security"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.RevokeBreakGlass(ctx, g.ID, "security"); !errors.Is(err, ErrBreakGlassInactive) {
+		t.Errorf("second revoke err = %v, want ErrBreakGlassInactive", err)
+	}
+	res, err = eng.Check(ctx, restart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed {
+		t.Fatal("expected deny after revocation")
+	}
+	if len(capPlugin.revoked) != 1 {
+		t.Errorf("revoked events = %d, want 1", len(capPlugin.revoked))
+	}
+}
+
+func TestEngine_BreakGlassBypassPolicy(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s, _ := newBreakGlassEngine(t, ctx)
+	for _, p := range []*policy.Policy{
+		{TenantID: "t1", Name: "allow-deploy", Effect: policy.EffectAllow, IsActive: true, Actions: []string{"deploy"}},
+		{TenantID: "t1", Name: "freeze-deploys", Effect: policy.EffectDeny, IsActive: true, Actions: []string{"deploy"}},
+	} {
+		if err := s.CreatePolicy(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oncall := Subject{Kind: SubjectUser, ID: "oncall-bo"}
+	deploy := &CheckRequest{Subject: oncall, Action: Action{Name: "deploy"}, Resource: Resource{Type: "service", ID: "api"}}
+
+	res, err := eng.Check(ctx, deploy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Decision != DecisionDenyExplicit {
+		t.Fatalf("expected explicit deny during the freeze, got %s", res.Decision)
+	}
+
+	if _, err := eng.BreakGlass(ctx, &BreakGlassRequest{
+		Subject:        oncall,
+		Justification:  "hotfix for INC-43",
+		BypassPolicies: []string{"freeze-deploys"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	res, err = eng.Check(ctx, deploy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed || !hasBreakGlassObligation(res) || !servedByBreakGlass(res) {
+		t.Fatalf("expected break-glass allow, got %+v", res)
+	}
+}
+
+// The policies a grant does not bypass read attributes through the
+// resource type schemas, as they do without a grant.
+func TestEngine_BreakGlassBypassAttributeSchema(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s, _ := newBreakGlassEngine(t, ctx)
+	if err := s.CreateResourceType(ctx, &resourcetype.ResourceType{
+		ID: id.NewResourceTypeID(), TenantID: "t1", Name: "service",
+		Attributes: []resourcetype.AttributeDef{{Name: "env", Type: resourcetype.AttrString, Enum: []string{"prod", "staging"}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*policy.Policy{
+		{
+			TenantID: "t1", Name: "allow-staging-deploy", Effect: policy.EffectAllow, IsActive: true, Actions: []string{"deploy"},
+			Conditions: []policy.Condition{{Field: "resource.env", Operator: policy.OpNotEquals, Value: "prod"}},
+		},
+		{TenantID: "t1", Name: "freeze-deploys", Effect: policy.EffectDeny, IsActive: true, Actions: []string{"deploy"}},
+	} {
+		if err := s.CreatePolicy(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oncall := Subject{Kind: SubjectUser, ID: "oncall-bo"}
+	if _, err := eng.BreakGlass(ctx, &BreakGlassRequest{
+		Subject:        oncall,
+		Justification:  "hotfix for INC-44",
+		BypassPolicies: []string{"freeze-deploys"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An environment outside the declared enum cannot be evaluated.
+	res, err := eng.Check(ctx, &CheckRequest{
+		Subject: oncall, Action: Action{Name: "deploy"},
+		Resource: Resource{Type: "service", ID: "api", Attributes: map[string]any{"env": "qa"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || len(res.Errors) != 1 || res.Errors[0].PolicyName != "allow-staging-deploy" {
+		t.Fatalf("expected a deny with the schema error of allow-staging-deploy, got %s: %+v", res.Decision, res.Errors)
+	}
+}
+
+func TestEngine_BreakGlassRejected(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, _, capPlugin := newBreakGlassEngine(t, ctx)
+	oncall := Subject{Kind: SubjectUser, ID: "oncall-ana"}
+
+	tests := []struct {
+		name string
+		req  BreakGlassRequest
+	}{
+		{"no justification", BreakGlassRequest{Subject: oncall, Roles: []string{"responder"}}},
+		{"subject not allowed", BreakGlassRequest{Subject: Subject{Kind: SubjectUser, ID: "intern"}, Justification: "x", Roles: []string{"responder"}}},
+		{"role not eligible", BreakGlassRequest{Subject: oncall, Justification: "x", Roles: []string{"admin"}}},
+		{"policy not eligible", BreakGlassRequest{Subject: oncall, Justification: "x", BypassPolicies: []string{"deny-all"}}},
+		{"nothing requested", BreakGlassRequest{Subject: oncall, Justification: "x"}},
+		{"too long", BreakGlassRequest{Subject: oncall, Justification: "x", Roles: []string{"responder"}, Duration: 2 * time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := eng.BreakGlass(ctx, &tt.req); !errors.Is(err, ErrBreakGlassNotAllowed) {
+				t.Errorf("err = %v, want ErrBreakGlassNotAllowed", err)
+			}
+		})
+	}
+	if len(capPlugin.activated) != 0 {
+		t.Errorf("activated events = %d, want 0", len(capPlugin.activated))
+	}
+}
@@ -0,0 +1,115 @@
+// Package calendar defines named calendars: per-tenant sets of dates, such
+// as public holidays or change freezes, that policy conditions test with
+// the in_calendar and not_in_calendar operators.
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/schedule"
+)
+
+// Calendar is a named set of dates within a tenant. Names are unique per
+// tenant.
+//
+// TimeZone decides which day an instant falls on. A condition that sets
+// its own time zone overrides it; when neither does, UTC is used.
+type Calendar struct {
+	ID          id.CalendarID  `json:"id" db:"id"`
+	TenantID    string         `json:"tenant_id" db:"tenant_id"`
+	AppID       string         `json:"app_id" db:"app_id"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description,omitempty" db:"description"`
+	TimeZone    string         `json:"time_zone,omitempty" db:"time_zone"`
+	Dates       []Date         `json:"dates" db:"dates"`
+	Metadata    map[string]any `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Date is one day of a calendar: "2026-12-24" for a single date, or
+// "12-25" for a date that recurs every year.
+type Date struct {
+	Date string `json:"date"`
+	Name string `json:"name,omitempty"`
+}
+
+const (
+	dateLayout   = "2006-01-02"
+	annualLayout = "01-02"
+)
+
+// Validate reports a date that is not in either form.
+func (d Date) Validate() error {
+	if _, err := time.Parse(dateLayout, d.Date); err == nil {
+		return nil
+	}
+	// Parse annual dates in a leap year so "02-29" is accepted.
+	if _, err := time.Parse(dateLayout, "2000-"+d.Date); err == nil && len(d.Date) == len(annualLayout) {
+		return nil
+	}
+	return fmt.Errorf("invalid date %q: want YYYY-MM-DD or MM-DD", d.Date)
+}
+
+// Validate reports a calendar without a name, with an unknown time zone or
+// with a malformed date.
+func (c *Calendar) Validate() error {
+	if c.Name == "" {
+		return errors.New("calendar name is required")
+	}
+	if _, err := schedule.LoadLocation(c.TimeZone); err != nil {
+		return err
+	}
+	for _, d := range c.Dates {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Contains reports whether t falls on one of the calendar's dates in loc.
+// A nil loc uses the calendar's own time zone.
+func (c *Calendar) Contains(t time.Time, loc *time.Location) (bool, error) {
+	if loc == nil {
+		var err error
+		if loc, err = schedule.LoadLocation(c.TimeZone); err != nil {
+			return false, err
+		}
+	}
+	t = t.In(loc)
+	day, annual := t.Format(dateLayout), t.Format(annualLayout)
+	for _, d := range c.Dates {
+		if d.Date == day || d.Date == annual {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListFilter contains filters for listing calendars.
+type ListFilter struct {
+	TenantID string `json:"tenant_id,omitempty"`
+	Search   string `json:"search,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+}
+
+// Matches reports whether c passes the filter's field criteria. Backends
+// without a query language use it; Limit and Offset are left to the
+// caller.
+func (f *ListFilter) Matches(c *Calendar) bool {
+	if f == nil {
+		return true
+	}
+	switch {
+	case f.TenantID != "" && c.TenantID != f.TenantID,
+		f.Search != "" && !strings.Contains(strings.ToLower(c.Name), strings.ToLower(f.Search)):
+		return false
+	}
+	return true
+}
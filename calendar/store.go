@@ -0,0 +1,31 @@
+package calendar
+
+import (
+	"context"
+
+	"github.com/xraph/warden/id"
+)
+
+// Store defines persistence operations for named calendars.
+type Store interface {
+	// CreateCalendar persists a new calendar.
+	CreateCalendar(ctx context.Context, c *Calendar) error
+
+	// GetCalendar retrieves a calendar by ID.
+	GetCalendar(ctx context.Context, calID id.CalendarID) (*Calendar, error)
+
+	// GetCalendarByName retrieves a calendar by tenant and name.
+	GetCalendarByName(ctx context.Context, tenantID, name string) (*Calendar, error)
+
+	// UpdateCalendar persists changes to a calendar.
+	UpdateCalendar(ctx context.Context, c *Calendar) error
+
+	// DeleteCalendar removes a calendar by ID.
+	DeleteCalendar(ctx context.Context, calID id.CalendarID) error
+
+	// ListCalendars returns calendars matching the filter, ordered by name.
+	ListCalendars(ctx context.Context, filter *ListFilter) ([]*Calendar, error)
+
+	// DeleteCalendarsByTenant removes all calendars for a tenant.
+	DeleteCalendarsByTenant(ctx context.Context, tenantID string) error
+}
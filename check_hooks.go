@@ -0,0 +1,263 @@
+package warden
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	log "github.com/xraph/go-utils/log"
+
+	"github.com/xraph/warden/plugin"
+)
+
+// BeforeCheckHook is a veto-capable plugin hook that runs before a check is
+// evaluated, ahead of the result cache. Returning a HookDeny or HookAllow
+// verdict decides the check without evaluating RBAC, ReBAC or ABAC — an
+// emergency kill switch or tenant suspension. A nil verdict or HookContinue
+// lets evaluation proceed, keeping any obligations and annotations.
+//
+// Unlike plugin.BeforeCheck, which only observes, its errors are handled
+// according to the plugin's plugin.FailMode.
+type BeforeCheckHook interface {
+	plugin.Plugin
+	BeforeCheck(ctx context.Context, req *CheckRequest) (*HookVerdict, error)
+}
+
+// AfterCheckHook is a plugin hook that runs after a check is decided, on
+// cached results too. It may deny an allowed result, add obligations, or
+// annotate the result. It cannot turn a deny into an allow: a HookAllow
+// verdict only contributes its obligations and annotations.
+type AfterCheckHook interface {
+	plugin.Plugin
+	AfterCheck(ctx context.Context, req *CheckRequest, result *CheckResult) (*HookVerdict, error)
+}
+
+// HookAction is what a check hook wants done with the check.
+type HookAction int
+
+const (
+	// HookContinue leaves the decision to the engine.
+	HookContinue HookAction = iota
+
+	// HookDeny denies the check.
+	HookDeny
+
+	// HookAllow allows the check. Only honored from BeforeCheckHook.
+	HookAllow
+)
+
+// HookVerdict is a check hook's response.
+type HookVerdict struct {
+	Action HookAction
+
+	// Reason explains a HookDeny or HookAllow; it becomes
+	// CheckResult.Reason.
+	Reason string
+
+	// Obligations are appended to CheckResult.Obligations.
+	Obligations []string
+
+	// Annotations are merged into CheckResult.Annotations. When hooks set
+	// the same key, the one with higher precedence wins.
+	Annotations map[string]string
+}
+
+// checkHook is a typed check hook with its plugin's settings.
+type checkHook[H any] struct {
+	name     string
+	hook     H
+	settings plugin.Settings
+}
+
+// checkHooks holds the typed check hooks in precedence order.
+type checkHooks struct {
+	before []checkHook[BeforeCheckHook]
+	after  []checkHook[AfterCheckHook]
+}
+
+func newCheckHooks(reg *plugin.Registry) checkHooks {
+	var hs checkHooks
+	if reg == nil {
+		return hs
+	}
+	for _, p := range reg.Plugins() {
+		st := reg.Settings(p.Name())
+		if h, ok := p.(BeforeCheckHook); ok {
+			hs.before = append(hs.before, checkHook[BeforeCheckHook]{p.Name(), h, st})
+		}
+		if h, ok := p.(AfterCheckHook); ok {
+			hs.after = append(hs.after, checkHook[AfterCheckHook]{p.Name(), h, st})
+		}
+	}
+	slices.SortStableFunc(hs.before, func(a, b checkHook[BeforeCheckHook]) int {
+		return cmp.Compare(a.settings.Priority, b.settings.Priority)
+	})
+	slices.SortStableFunc(hs.after, func(a, b checkHook[AfterCheckHook]) int {
+		return cmp.Compare(a.settings.Priority, b.settings.Priority)
+	})
+	return hs
+}
+
+// hookEffects accumulates the non-deciding parts of hook verdicts.
+type hookEffects struct {
+	obligations []string
+	annotations map[string]string
+}
+
+func (fx *hookEffects) add(v *HookVerdict) {
+	fx.obligations = append(fx.obligations, v.Obligations...)
+	for k, val := range v.Annotations {
+		if _, ok := fx.annotations[k]; ok {
+			continue
+		}
+		if fx.annotations == nil {
+			fx.annotations = make(map[string]string)
+		}
+		fx.annotations[k] = val
+	}
+}
+
+// runBeforeCheckHooks runs BeforeCheckHooks in precedence order. It returns
+// a result when a hook decided the check, and the effects of the hooks that
+// let it continue.
+func (e *Engine) runBeforeCheckHooks(ctx context.Context, req *CheckRequest) (*CheckResult, hookEffects) {
+	var fx hookEffects
+	for _, h := range e.checkHooks.before {
+		var v *HookVerdict
+		err := e.plugins.Invoke(ctx, h.name, func(ctx context.Context) error {
+			var err error
+			v, err = h.hook.BeforeCheck(ctx, req)
+			return err
+		})
+		if err != nil {
+			if denied := e.hookFailed(h.name, h.settings, "BeforeCheck", err); denied != nil {
+				return denied, fx
+			}
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		fx.add(v)
+		switch v.Action {
+		case HookDeny:
+			return hookResult(h.name, false, v.Reason), fx
+		case HookAllow:
+			return hookResult(h.name, true, v.Reason), fx
+		}
+	}
+	return nil, fx
+}
+
+// runAfterCheckHooks runs AfterCheckHooks in precedence order and returns
+// result with fx and every hook's verdict applied. result itself is never
+// modified, so cached results stay intact.
+func (e *Engine) runAfterCheckHooks(ctx context.Context, req *CheckRequest, result *CheckResult, fx hookEffects) *CheckResult {
+	if len(e.checkHooks.after) == 0 && len(fx.obligations) == 0 && len(fx.annotations) == 0 {
+		return result
+	}
+	out := result.clone()
+	fx.applyTo(out)
+
+	for _, h := range e.checkHooks.after {
+		var v *HookVerdict
+		snapshot := out.clone() // a timed-out hook may still be reading its copy
+		err := e.plugins.Invoke(ctx, h.name, func(ctx context.Context) error {
+			var err error
+			v, err = h.hook.AfterCheck(ctx, req, snapshot)
+			return err
+		})
+		if err != nil {
+			if denied := e.hookFailed(h.name, h.settings, "AfterCheck", err); denied != nil && out.Allowed {
+				out.veto(denied)
+			}
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		var vfx hookEffects
+		vfx.add(v)
+		vfx.applyTo(out)
+		if v.Action == HookDeny && out.Allowed {
+			out.veto(hookResult(h.name, false, v.Reason))
+		}
+	}
+	return out
+}
+
+// clone returns a deep copy of res that shares no maps or slices with it.
+func (res *CheckResult) clone() *CheckResult {
+	out := *res
+	out.MatchedBy = slices.Clone(res.MatchedBy)
+	out.Obligations = slices.Clone(res.Obligations)
+	out.Annotations = maps.Clone(res.Annotations)
+	out.StructuredObligations = cloneObligations(res.StructuredObligations)
+	out.Advice = cloneObligations(res.Advice)
+	out.Shadow = slices.Clone(res.Shadow)
+	out.Errors = slices.Clone(res.Errors)
+	if res.Trace != nil {
+		out.Trace = &DecisionTrace{Attributes: slices.Clone(res.Trace.Attributes)}
+	}
+	return &out
+}
+
+// applyTo merges the effects into res. Keys res already has keep their
+// value, since they came from higher-precedence hooks.
+func (fx hookEffects) applyTo(res *CheckResult) {
+	if len(fx.obligations) > 0 {
+		res.Obligations = dedupeStrings(append(res.Obligations, fx.obligations...))
+	}
+	for k, v := range fx.annotations {
+		if _, ok := res.Annotations[k]; ok {
+			continue
+		}
+		if res.Annotations == nil {
+			res.Annotations = make(map[string]string)
+		}
+		res.Annotations[k] = v
+	}
+}
+
+// veto replaces the decision of res with the plugin deny in by, keeping
+// its obligations and annotations.
+func (res *CheckResult) veto(by *CheckResult) {
+	res.Allowed = by.Allowed
+	res.Decision = by.Decision
+	res.Reason = by.Reason
+	res.MatchedBy = by.MatchedBy
+}
+
+// hookFailed logs a check hook error (including a timeout or panic) and,
+// for fail-closed plugins, returns the deny result it causes.
+func (e *Engine) hookFailed(name string, st plugin.Settings, hook string, err error) *CheckResult {
+	e.logger.Warn("warden: check hook error",
+		log.String("hook", hook),
+		log.String("plugin", name),
+		log.String("fail_mode", st.FailMode.String()),
+		log.String("error", err.Error()),
+	)
+	if st.FailMode != plugin.FailClosed {
+		return nil
+	}
+	return hookResult(name, false, fmt.Sprintf("plugin %q failed closed: %v", name, err))
+}
+
+// hookResult is the result of a check decided by the named plugin.
+func hookResult(name string, allowed bool, reason string) *CheckResult {
+	res := &CheckResult{
+		Allowed:   allowed,
+		Decision:  DecisionAllow,
+		Reason:    reason,
+		MatchedBy: []MatchInfo{{Source: "plugin", RuleID: name, Detail: reason}},
+	}
+	if !allowed {
+		res.Decision = DecisionDenyPlugin
+		if res.Reason == "" {
+			res.Reason = fmt.Sprintf("denied by plugin %q", name)
+		}
+	}
+	return res
+}
@@ -0,0 +1,220 @@
+package warden
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/plugin"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/store/memory"
+)
+
+// verdictHook returns fixed verdicts (or errors) from both check hooks and
+// records the order it ran in.
+type verdictHook struct {
+	name   string
+	before *HookVerdict
+	after  *HookVerdict
+	err    error
+	order  *[]string
+}
+
+func (h *verdictHook) Name() string { return h.name }
+
+func (h *verdictHook) BeforeCheck(_ context.Context, _ *CheckRequest) (*HookVerdict, error) {
+	*h.order = append(*h.order, "before:"+h.name)
+	return h.before, h.err
+}
+
+func (h *verdictHook) AfterCheck(_ context.Context, _ *CheckRequest, _ *CheckResult) (*HookVerdict, error) {
+	*h.order = append(*h.order, "after:"+h.name)
+	return h.after, h.err
+}
+
+// newHookEngine returns an engine in which u1 may read documents.
+func newHookEngine(t *testing.T, opts ...Option) *Engine {
+	t.Helper()
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	eng, err := NewEngine(append([]Option{WithStore(s)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roleID := id.NewRoleID()
+	_ = s.CreateRole(ctx, &role.Role{ID: roleID, TenantID: "t1", Name: "reader", Slug: "reader"})
+	_ = s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "document:read", Resource: "document", Action: "read"})
+	_ = s.AttachPermission(ctx, roleID, permission.Ref{Name: "document:read"})
+	_ = s.CreateAssignment(ctx, &assignment.Assignment{TenantID: "t1", RoleID: roleID, SubjectKind: "user", SubjectID: "u1"})
+	return eng
+}
+
+func readCheck(subjectID string) *CheckRequest {
+	return &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: subjectID},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "document", ID: "doc1"},
+	}
+}
+
+func TestCheckHooks_BeforeDenyShortCircuits(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	var order []string
+	eng := newHookEngine(t,
+		WithPlugin(&verdictHook{name: "late", before: &HookVerdict{Action: HookAllow}, order: &order}, plugin.WithPriority(10)),
+		WithPlugin(&verdictHook{name: "kill-switch", before: &HookVerdict{Action: HookDeny, Reason: "tenant suspended"}, order: &order}, plugin.WithPriority(-10)),
+	)
+
+	result, err := eng.Check(ctx, readCheck("u1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Decision != DecisionDenyPlugin || result.Reason != "tenant suspended" {
+		t.Fatalf("expected plugin deny, got %+v", result)
+	}
+	if len(result.MatchedBy) != 1 || result.MatchedBy[0].Source != "plugin" || result.MatchedBy[0].RuleID != "kill-switch" {
+		t.Fatalf("unexpected matches %+v", result.MatchedBy)
+	}
+	want := []string{"before:kill-switch", "after:kill-switch", "after:late"}
+	if len(order) != len(want) {
+		t.Fatalf("hook order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("hook order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestCheckHooks_BeforeAllowWithObligations(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	var order []string
+	eng := newHookEngine(t, WithPlugin(&verdictHook{
+		name:   "break-glass",
+		before: &HookVerdict{Action: HookAllow, Reason: "emergency", Obligations: []string{"notify-security"}},
+		order:  &order,
+	}))
+
+	result, err := eng.Check(ctx, readCheck("u2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || len(result.Obligations) != 1 || result.Obligations[0] != "notify-security" {
+		t.Fatalf("expected allow with obligation, got %+v", result)
+	}
+}
+
+func TestCheckHooks_AfterDenyAndAnnotate(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	var order []string
+	eng := newHookEngine(t,
+		WithPlugin(&verdictHook{name: "tagger", after: &HookVerdict{Annotations: map[string]string{"risk": "low"}}, order: &order}),
+		WithPlugin(&verdictHook{name: "risk", after: &HookVerdict{Action: HookDeny, Annotations: map[string]string{"risk": "high"}}, order: &order}, plugin.WithPriority(-1)),
+	)
+
+	result, err := eng.Check(ctx, readCheck("u1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Decision != DecisionDenyPlugin {
+		t.Fatalf("expected after hook to deny, got %+v", result)
+	}
+	if result.Annotations["risk"] != "high" {
+		t.Fatalf("higher-precedence annotation should win, got %v", result.Annotations)
+	}
+}
+
+func TestCheckHooks_AfterAllowCannotOverturnDeny(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	var order []string
+	eng := newHookEngine(t, WithPlugin(&verdictHook{name: "lenient", after: &HookVerdict{Action: HookAllow}, order: &order}))
+
+	result, err := eng.Check(ctx, readCheck("u2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatal("after hook must not allow a denied check")
+	}
+}
+
+func TestCheckHooks_FailMode(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	boom := errors.New("directory unavailable")
+
+	var order []string
+	open := newHookEngine(t, WithPlugin(&verdictHook{name: "flaky", err: boom, order: &order}))
+	result, err := open.Check(ctx, readCheck("u1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("fail-open hook error should not deny, got %+v", result)
+	}
+
+	closed := newHookEngine(t, WithPlugin(&verdictHook{name: "flaky", err: boom, order: &order}, plugin.WithFailMode(plugin.FailClosed)))
+	result, err = closed.Check(ctx, readCheck("u1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Decision != DecisionDenyPlugin {
+		t.Fatalf("fail-closed hook error should deny, got %+v", result)
+	}
+}
+
+// annotateHook annotates every check after it.
+type annotateHook struct {
+	name string
+	key  string
+}
+
+func (h *annotateHook) Name() string { return h.name }
+
+func (h *annotateHook) AfterCheck(_ context.Context, _ *CheckRequest, _ *CheckResult) (*HookVerdict, error) {
+	return &HookVerdict{Annotations: map[string]string{h.key: h.name}}, nil
+}
+
+// slowHook keeps reading its result after its timeout has passed.
+type slowHook struct {
+	done chan struct{}
+}
+
+func (h *slowHook) Name() string { return "slow" }
+
+func (h *slowHook) AfterCheck(ctx context.Context, _ *CheckRequest, result *CheckResult) (*HookVerdict, error) {
+	defer close(h.done)
+	<-ctx.Done()
+	for range 1000 {
+		for k, v := range result.Annotations {
+			_, _ = k, v
+		}
+		_ = len(result.Obligations)
+	}
+	return nil, nil
+}
+
+// TestCheckHooks_TimedOutHookOwnsItsSnapshot runs under -race: a hook that
+// outlives its timeout must not share maps with the result later hooks
+// annotate.
+func TestCheckHooks_TimedOutHookOwnsItsSnapshot(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	slow := &slowHook{done: make(chan struct{})}
+	eng := newHookEngine(t,
+		WithPlugin(&annotateHook{name: "first", key: "first"}, plugin.WithPriority(-10)),
+		WithPlugin(slow, plugin.WithTimeout(time.Millisecond)),
+		WithPlugin(&annotateHook{name: "last", key: "last"}, plugin.WithPriority(10)),
+	)
+
+	result, err := eng.Check(ctx, readCheck("u1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-slow.done
+	if result.Annotations["first"] != "first" || result.Annotations["last"] != "last" {
+		t.Fatalf("annotations = %v", result.Annotations)
+	}
+}
@@ -0,0 +1,125 @@
+package checklog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArchiveExt is the file extension of check log archives: gzip-compressed
+// JSON lines, one Entry per line.
+const ArchiveExt = ".jsonl.gz"
+
+// ArchiveWriter streams check log entries as gzip-compressed JSON lines.
+type ArchiveWriter struct {
+	gz    *gzip.Writer
+	enc   *json.Encoder
+	file  *os.File // non-nil when the writer owns the file
+	path  string
+	count int64
+}
+
+// NewArchiveWriter returns a writer that compresses entries onto w. The
+// caller owns w; Close flushes the gzip stream but does not close w.
+func NewArchiveWriter(w io.Writer) *ArchiveWriter {
+	gz := gzip.NewWriter(w)
+	return &ArchiveWriter{gz: gz, enc: json.NewEncoder(gz)}
+}
+
+// CreateArchive creates a new archive file in dir named
+// "<prefix>-<UTC timestamp><ArchiveExt>". The directory is created if it
+// does not exist.
+func CreateArchive(dir, prefix string, now time.Time) (*ArchiveWriter, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("warden: create archive dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s%s", prefix, now.UTC().Format("20060102T150405.000000000Z"), ArchiveExt)
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640) //nolint:gosec // path built from configured dir
+	if err != nil {
+		return nil, fmt.Errorf("warden: create archive: %w", err)
+	}
+	w := NewArchiveWriter(f)
+	w.file = f
+	w.path = path
+	return w, nil
+}
+
+// Path returns the archive file path, or "" for writers created with
+// NewArchiveWriter.
+func (w *ArchiveWriter) Path() string { return w.path }
+
+// Count returns the number of entries written so far.
+func (w *ArchiveWriter) Count() int64 { return w.count }
+
+// Write appends entries to the archive.
+func (w *ArchiveWriter) Write(entries ...*Entry) error {
+	for _, e := range entries {
+		if err := w.enc.Encode(e); err != nil {
+			return fmt.Errorf("warden: write archive: %w", err)
+		}
+		w.count++
+	}
+	return nil
+}
+
+// Flush writes buffered entries through to the underlying writer and, for
+// file-backed archives, syncs the file so they survive a crash.
+func (w *ArchiveWriter) Flush() error {
+	if err := w.gz.Flush(); err != nil {
+		return fmt.Errorf("warden: flush archive: %w", err)
+	}
+	if w.file != nil {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("warden: sync archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close finishes the gzip stream. For file-backed archives it also syncs
+// and closes the file.
+func (w *ArchiveWriter) Close() error {
+	err := w.gz.Close()
+	if w.file != nil {
+		if serr := w.file.Sync(); err == nil {
+			err = serr
+		}
+		if cerr := w.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("warden: close archive: %w", err)
+	}
+	return nil
+}
+
+// ReadArchive decodes a gzip-compressed JSON lines archive, calling fn for
+// each entry in order.
+func ReadArchive(r io.Reader, fn func(*Entry) error) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("warden: read archive: %w", err)
+	}
+	defer gz.Close()
+	dec := json.NewDecoder(bufio.NewReader(gz))
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("warden: decode archive: %w", err)
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+}
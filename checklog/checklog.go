@@ -22,10 +22,35 @@ type Entry struct {
 	Reason        string         `json:"reason,omitempty" db:"reason"`
 	EvalTimeNs    int64          `json:"eval_time_ns" db:"eval_time_ns"`
 	RequestIP     string         `json:"request_ip,omitempty" db:"request_ip"`
+	RequestID     string         `json:"request_id,omitempty" db:"request_id"`
+	MatchedBy     []Match        `json:"matched_by,omitempty" db:"matched_by"`
+	Obligations   []string       `json:"obligations,omitempty" db:"obligations"`
 	Metadata      map[string]any `json:"metadata,omitempty" db:"metadata"`
 	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
 }
 
+// Match records one rule that contributed to a decision — the role,
+// relation, or policy that granted or denied access. Mirrors
+// warden.MatchInfo without importing the root package.
+type Match struct {
+	Source     string `json:"source"`
+	RuleID     string `json:"rule_id"`
+	Detail     string `json:"detail,omitempty"`
+	Permission string `json:"permission,omitempty"` // rbac: the granting "resource:action"
+}
+
+// Metadata keys holding the attribute snapshot and session roles
+// captured at check time, the would-be decisions of shadow-mode policies,
+// and the policies that failed to evaluate.
+const (
+	MetadataSubjectAttributes  = "subject_attributes"
+	MetadataResourceAttributes = "resource_attributes"
+	MetadataContext            = "context"
+	MetadataActiveRoles        = "active_roles"
+	MetadataShadow             = "shadow"
+	MetadataErrors             = "errors"
+)
+
 // QueryFilter contains filters for querying check logs.
 type QueryFilter struct {
 	TenantID        string     `json:"tenant_id,omitempty"`
@@ -37,8 +62,37 @@ type QueryFilter struct {
 	ResourceType    string     `json:"resource_type,omitempty"`
 	ResourceID      string     `json:"resource_id,omitempty"`
 	Decision        string     `json:"decision,omitempty"`
+	RequestIP       string     `json:"request_ip,omitempty"`
+	RequestID       string     `json:"request_id,omitempty"`
+	MatchedSource   string     `json:"matched_source,omitempty"` // entries with a MatchedBy entry of this source
+	MatchedRuleID   string     `json:"matched_rule_id,omitempty"`
+	Obligation      string     `json:"obligation,omitempty"` // entries whose Obligations contain this value
 	After           *time.Time `json:"after,omitempty"`
 	Before          *time.Time `json:"before,omitempty"`
+	Cursor          *Cursor    `json:"cursor,omitempty"` // entries listed after this one
 	Limit           int        `json:"limit,omitempty"`
 	Offset          int        `json:"offset,omitempty"`
 }
+
+// Cursor is a position in a check log listing, which is ordered newest
+// first by CreatedAt and then ID. Paging with the cursor of the last entry
+// read, instead of Offset, neither skips nor repeats entries when new ones
+// are written between pages.
+type Cursor struct {
+	CreatedAt time.Time     `json:"created_at"`
+	ID        id.CheckLogID `json:"id"`
+}
+
+// CursorAfter returns the cursor that lists the entries after e.
+func CursorAfter(e *Entry) *Cursor {
+	return &Cursor{CreatedAt: e.CreatedAt, ID: e.ID}
+}
+
+// Before reports whether e is listed after the cursor: older, or as old
+// with a smaller ID.
+func (c *Cursor) Before(e *Entry) bool {
+	if !e.CreatedAt.Equal(c.CreatedAt) {
+		return e.CreatedAt.Before(c.CreatedAt)
+	}
+	return e.ID.String() < c.ID.String()
+}
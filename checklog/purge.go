@@ -0,0 +1,151 @@
+package checklog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xraph/warden/id"
+)
+
+// DefaultPurgeBatchSize is the number of entries deleted per batch when
+// PurgeOptions.BatchSize is zero.
+const DefaultPurgeBatchSize = 1000
+
+// PurgeOptions controls a batched purge.
+type PurgeOptions struct {
+	// TenantID limits the purge to one tenant. Empty purges all tenants.
+	TenantID string
+
+	// Before purges entries created at or before this time.
+	Before time.Time
+
+	// ExcludeTenants keeps entries belonging to these tenants. Used when a
+	// default retention pass must skip tenants with their own retention.
+	ExcludeTenants []string
+
+	// BatchSize is the number of entries listed, archived and deleted per
+	// round trip. Small batches keep each delete short so the table is
+	// never locked for long. Defaults to DefaultPurgeBatchSize.
+	BatchSize int
+
+	// ArchiveDir, when set, writes every purged entry to a compressed
+	// archive in this directory before deleting it.
+	ArchiveDir string
+
+	// ArchivePrefix names the archive file. Defaults to "checklog".
+	ArchivePrefix string
+}
+
+// PurgeResult summarises a purge.
+type PurgeResult struct {
+	// Purged is the number of entries deleted.
+	Purged int64 `json:"purged"`
+
+	// Archives lists the archive files written.
+	Archives []string `json:"archives,omitempty"`
+}
+
+// Add folds another result into r.
+func (r *PurgeResult) Add(o *PurgeResult) {
+	r.Purged += o.Purged
+	r.Archives = append(r.Archives, o.Archives...)
+}
+
+// Purge deletes check log entries matching opts in batches, archiving each
+// batch first when opts.ArchiveDir is set. Unlike Store.PurgeCheckLogs it
+// never issues a single unbounded delete.
+func Purge(ctx context.Context, s Store, opts PurgeOptions) (*PurgeResult, error) {
+	size := opts.BatchSize
+	if size <= 0 {
+		size = DefaultPurgeBatchSize
+	}
+	prefix := opts.ArchivePrefix
+	if prefix == "" {
+		prefix = "checklog"
+	}
+	excluded := make(map[string]bool, len(opts.ExcludeTenants))
+	for _, t := range opts.ExcludeTenants {
+		excluded[t] = true
+	}
+
+	res := &PurgeResult{}
+	var archive *ArchiveWriter
+	closeArchive := func() error {
+		if archive == nil {
+			return nil
+		}
+		err := archive.Close()
+		res.Archives = append(res.Archives, archive.Path())
+		archive = nil
+		return err
+	}
+	defer closeArchive() //nolint:errcheck // closed explicitly on the success path
+
+	// Kept (excluded) entries stay at the head of the newest-first listing,
+	// so skipping past them with Offset is stable across batches.
+	before := opts.Before
+	kept := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		batch, err := s.ListCheckLogs(ctx, &QueryFilter{
+			TenantID: opts.TenantID,
+			Before:   &before,
+			Limit:    size,
+			Offset:   kept,
+		})
+		if err != nil {
+			return res, fmt.Errorf("warden: purge check logs: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		doomed := make([]*Entry, 0, len(batch))
+		for _, e := range batch {
+			if excluded[e.TenantID] {
+				kept++
+				continue
+			}
+			doomed = append(doomed, e)
+		}
+
+		if len(doomed) > 0 {
+			if opts.ArchiveDir != "" {
+				if archive == nil {
+					archive, err = CreateArchive(opts.ArchiveDir, prefix, time.Now())
+					if err != nil {
+						return res, err
+					}
+				}
+				if err := archive.Write(doomed...); err != nil {
+					return res, err
+				}
+				// Entries must be durable in the archive before they
+				// leave the store.
+				if err := archive.Flush(); err != nil {
+					return res, err
+				}
+			}
+			ids := make([]id.CheckLogID, len(doomed))
+			for i, e := range doomed {
+				ids[i] = e.ID
+			}
+			n, err := s.DeleteCheckLogs(ctx, ids)
+			if err != nil {
+				return res, fmt.Errorf("warden: purge check logs: %w", err)
+			}
+			res.Purged += n
+		}
+
+		if len(batch) < size {
+			break
+		}
+	}
+	if err := closeArchive(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package checklog_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/store/memory"
+)
+
+func seedAged(t *testing.T, s *memory.Store, tenantID string, n int, age time.Duration) {
+	t.Helper()
+	for range n {
+		err := s.CreateCheckLog(context.Background(), &checklog.Entry{
+			TenantID: tenantID, SubjectKind: "user", SubjectID: "u1",
+			Action: "read", ResourceType: "document", Decision: "allow",
+			CreatedAt: time.Now().Add(-age),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPurge_BatchesAndArchives(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	seedAged(t, s, "t1", 7, 48*time.Hour)
+	seedAged(t, s, "t1", 2, time.Minute)
+	dir := t.TempDir()
+
+	res, err := checklog.Purge(ctx, s, checklog.PurgeOptions{
+		Before:     time.Now().Add(-24 * time.Hour),
+		BatchSize:  3,
+		ArchiveDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Purged != 7 {
+		t.Fatalf("purged %d, want 7", res.Purged)
+	}
+	if n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{}); n != 2 {
+		t.Fatalf("remaining %d, want 2", n)
+	}
+	if len(res.Archives) != 1 {
+		t.Fatalf("archives = %v", res.Archives)
+	}
+
+	f, err := os.Open(res.Archives[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var archived int
+	if err := checklog.ReadArchive(f, func(e *checklog.Entry) error {
+		if e.TenantID != "t1" || e.ID.IsNil() {
+			t.Errorf("bad archived entry %+v", e)
+		}
+		archived++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if archived != 7 {
+		t.Fatalf("archived %d, want 7", archived)
+	}
+}
+
+func TestPurge_ExcludeTenants(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	seedAged(t, s, "keep", 4, 48*time.Hour)
+	seedAged(t, s, "drop", 5, 48*time.Hour)
+
+	res, err := checklog.Purge(ctx, s, checklog.PurgeOptions{
+		Before:         time.Now().Add(-time.Hour),
+		ExcludeTenants: []string{"keep"},
+		BatchSize:      2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Purged != 5 {
+		t.Fatalf("purged %d, want 5", res.Purged)
+	}
+	if n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{TenantID: "keep"}); n != 4 {
+		t.Fatalf("excluded tenant lost entries: %d left", n)
+	}
+}
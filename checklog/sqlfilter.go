@@ -0,0 +1,102 @@
+package checklog
+
+import (
+	"strings"
+	"time"
+)
+
+// SQLCondition is one WHERE condition with ? placeholders and its args.
+type SQLCondition struct {
+	Query string
+	Args  []any
+}
+
+// SQLDialect supplies the conditions whose syntax differs between SQL
+// backends: the JSON array columns and time arguments.
+type SQLDialect struct {
+	// MatchedBy selects entries with a matched_by element carrying source
+	// and/or ruleID; either may be empty but not both.
+	MatchedBy func(source, ruleID string) SQLCondition
+
+	// Obligation selects entries whose obligations contain name.
+	Obligation func(name string) SQLCondition
+
+	// Time converts a time argument to the form created_at is stored in.
+	// Nil passes times through unchanged.
+	Time func(time.Time) any
+}
+
+func (d SQLDialect) timeArg(t time.Time) any {
+	if d.Time == nil {
+		return t
+	}
+	return d.Time(t)
+}
+
+// SQLConditions renders f as the AND-ed WHERE conditions of the SQL
+// stores' list, count and stats queries, one per set field. Limit and
+// Offset are left to the caller, and listings must be ordered by
+// created_at DESC, id DESC for Cursor to page correctly.
+func (f *QueryFilter) SQLConditions(d SQLDialect) []SQLCondition {
+	var conds []SQLCondition
+	add := func(query string, args ...any) {
+		conds = append(conds, SQLCondition{Query: query, Args: args})
+	}
+	if f.TenantID != "" {
+		add("tenant_id = ?", f.TenantID)
+	}
+	if f.SubjectKind != "" {
+		add("subject_kind = ?", f.SubjectKind)
+	}
+	if f.SubjectID != "" {
+		add("subject_id = ?", f.SubjectID)
+	}
+	if f.Action != "" {
+		add("action = ?", f.Action)
+	}
+	if f.ResourceType != "" {
+		add("resource_type = ?", f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		add("resource_id = ?", f.ResourceID)
+	}
+	if f.Decision != "" {
+		add("decision = ?", f.Decision)
+	}
+	if f.NamespacePath != nil {
+		add("namespace_path = ?", *f.NamespacePath)
+	}
+	if f.NamespacePrefix != "" {
+		add(`namespace_path LIKE ? ESCAPE '\'`, LikePrefix(f.NamespacePrefix))
+	}
+	if f.RequestIP != "" {
+		add("request_ip = ?", f.RequestIP)
+	}
+	if f.RequestID != "" {
+		add("request_id = ?", f.RequestID)
+	}
+	if f.MatchedSource != "" || f.MatchedRuleID != "" {
+		conds = append(conds, d.MatchedBy(f.MatchedSource, f.MatchedRuleID))
+	}
+	if f.Obligation != "" {
+		conds = append(conds, d.Obligation(f.Obligation))
+	}
+	if f.After != nil {
+		add("created_at >= ?", d.timeArg(*f.After))
+	}
+	if f.Before != nil {
+		add("created_at <= ?", d.timeArg(*f.Before))
+	}
+	if c := f.Cursor; c != nil {
+		at := d.timeArg(c.CreatedAt)
+		add("(created_at < ? OR (created_at = ? AND id < ?))", at, at, c.ID.String())
+	}
+	return conds
+}
+
+// LikePrefix renders a LIKE operand matching values that start with
+// prefix, escaping the LIKE wildcards with a backslash. Use it with
+// `ESCAPE '\'`.
+func LikePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+}
@@ -0,0 +1,52 @@
+package checklog_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/checklog"
+)
+
+func TestQueryFilter_SQLConditions(t *testing.T) {
+	d := checklog.SQLDialect{
+		MatchedBy: func(source, ruleID string) checklog.SQLCondition {
+			return checklog.SQLCondition{Query: "matched(?, ?)", Args: []any{source, ruleID}}
+		},
+		Obligation: func(name string) checklog.SQLCondition {
+			return checklog.SQLCondition{Query: "obligation(?)", Args: []any{name}}
+		},
+	}
+	if conds := (&checklog.QueryFilter{Limit: 10, Offset: 5}).SQLConditions(d); len(conds) != 0 {
+		t.Fatalf("empty filter rendered %v", conds)
+	}
+
+	ns := "eng"
+	after := time.Unix(100, 0)
+	f := &checklog.QueryFilter{
+		TenantID:        "t1",
+		NamespacePath:   &ns,
+		NamespacePrefix: "a_b%",
+		MatchedRuleID:   "r1",
+		Obligation:      "audit-log",
+		After:           &after,
+	}
+	got := f.SQLConditions(d)
+	want := []checklog.SQLCondition{
+		{Query: "tenant_id = ?", Args: []any{"t1"}},
+		{Query: "namespace_path = ?", Args: []any{"eng"}},
+		{Query: `namespace_path LIKE ? ESCAPE '\'`, Args: []any{`a\_b\%%`}},
+		{Query: "matched(?, ?)", Args: []any{"", "r1"}},
+		{Query: "obligation(?)", Args: []any{"audit-log"}},
+		{Query: "created_at >= ?", Args: []any{after}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SQLConditions =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestLikePrefix(t *testing.T) {
+	if got := checklog.LikePrefix(`x\y`); got != `x\\y%` {
+		t.Errorf("checklog.LikePrefix = %q", got)
+	}
+}
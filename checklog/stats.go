@@ -0,0 +1,255 @@
+package checklog
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Dimension is a check log field that statistics can be grouped by.
+type Dimension string
+
+// Supported grouping dimensions.
+const (
+	DimensionDecision     Dimension = "decision"
+	DimensionResourceType Dimension = "resource_type"
+	DimensionAction       Dimension = "action"
+	DimensionSubject      Dimension = "subject" // "kind:id"
+)
+
+// Valid reports whether d is a supported dimension.
+func (d Dimension) Valid() bool {
+	switch d {
+	case DimensionDecision, DimensionResourceType, DimensionAction, DimensionSubject:
+		return true
+	}
+	return false
+}
+
+// DefaultStatsTopN is the number of top denied subjects and resources
+// returned when StatsQuery.TopN is zero.
+const DefaultStatsTopN = 10
+
+// StatsQuery describes an aggregation over check log entries.
+type StatsQuery struct {
+	// Filter scopes the entries aggregated. Limit and Offset are ignored.
+	Filter QueryFilter `json:"filter"`
+
+	// GroupBy is the dimension series counts are keyed by. Defaults to
+	// DimensionDecision.
+	GroupBy Dimension `json:"group_by,omitempty"`
+
+	// Bucket is the width of each time bucket, aligned to the Unix epoch.
+	// Zero aggregates the whole range into a single bucket.
+	Bucket time.Duration `json:"bucket,omitempty"`
+
+	// TopN limits the top denied subjects/resources. Defaults to
+	// DefaultStatsTopN.
+	TopN int `json:"top_n,omitempty"`
+}
+
+// Normalize fills defaults and validates the query.
+func (q *StatsQuery) Normalize() error {
+	if q.GroupBy == "" {
+		q.GroupBy = DimensionDecision
+	}
+	if !q.GroupBy.Valid() {
+		return fmt.Errorf("warden: unsupported stats dimension %q", q.GroupBy)
+	}
+	if q.Bucket < 0 {
+		return fmt.Errorf("warden: stats bucket must not be negative")
+	}
+	if q.Bucket > 0 && q.Bucket%time.Second != 0 {
+		return fmt.Errorf("warden: stats bucket must be a whole number of seconds")
+	}
+	if q.TopN <= 0 {
+		q.TopN = DefaultStatsTopN
+	}
+	return nil
+}
+
+// Stats is the result of a StatsQuery.
+type Stats struct {
+	// Total is the number of entries matched.
+	Total int64 `json:"total"`
+
+	// Series holds one point per (bucket, key), ordered by bucket then key.
+	// Bucket is the zero time when the query has no Bucket width.
+	Series []StatsPoint `json:"series"`
+
+	// Latency holds evaluation-time percentiles in nanoseconds.
+	Latency LatencyPercentiles `json:"latency"`
+
+	// TopDeniedSubjects ranks "kind:id" subjects by denied checks.
+	TopDeniedSubjects []KeyCount `json:"top_denied_subjects"`
+
+	// TopDeniedResources ranks "type:id" resources by denied checks.
+	TopDeniedResources []KeyCount `json:"top_denied_resources"`
+}
+
+// StatsPoint is the count of entries with a given key in a time bucket.
+type StatsPoint struct {
+	Bucket time.Time `json:"bucket"`
+	Key    string    `json:"key"`
+	Count  int64     `json:"count"`
+}
+
+// KeyCount is a ranked count.
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// LatencyPercentiles are nearest-rank percentiles of EvalTimeNs.
+type LatencyPercentiles struct {
+	P50 int64 `json:"p50_ns"`
+	P95 int64 `json:"p95_ns"`
+	P99 int64 `json:"p99_ns"`
+}
+
+// StatsStore is implemented by stores that aggregate check logs natively.
+type StatsStore interface {
+	// CheckLogStats runs the aggregation in the backend.
+	CheckLogStats(ctx context.Context, q *StatsQuery) (*Stats, error)
+}
+
+// QueryStats aggregates check log entries, using the store's native
+// aggregation when it implements StatsStore and otherwise paging through
+// ListCheckLogs and aggregating in memory.
+func QueryStats(ctx context.Context, s Store, q *StatsQuery) (*Stats, error) {
+	qq := *q
+	if err := qq.Normalize(); err != nil {
+		return nil, err
+	}
+	if ss, ok := s.(StatsStore); ok {
+		return ss.CheckLogStats(ctx, &qq)
+	}
+
+	const page = 1000
+	var entries []*Entry
+	f := qq.Filter
+	f.Limit = page
+	for f.Offset = 0; ; f.Offset += page {
+		batch, err := s.ListCheckLogs(ctx, &f)
+		if err != nil {
+			return nil, fmt.Errorf("warden: check log stats: %w", err)
+		}
+		entries = append(entries, batch...)
+		if len(batch) < page {
+			break
+		}
+	}
+	return ComputeStats(entries, &qq), nil
+}
+
+// ComputeStats aggregates entries in memory. Entries are assumed to match
+// q.Filter already; q must be normalized.
+func ComputeStats(entries []*Entry, q *StatsQuery) *Stats {
+	type seriesKey struct {
+		bucket int64
+		key    string
+	}
+	series := make(map[seriesKey]int64)
+	subjects := make(map[string]int64)
+	resources := make(map[string]int64)
+	latencies := make([]int64, 0, len(entries))
+	bucket := int64(q.Bucket / time.Second)
+
+	for _, e := range entries {
+		var b int64
+		if bucket > 0 {
+			b = BucketStart(e.CreatedAt.Unix(), bucket)
+		}
+		series[seriesKey{b, DimensionValue(e, q.GroupBy)}]++
+		latencies = append(latencies, e.EvalTimeNs)
+		if e.Decision != "allow" {
+			subjects[e.SubjectKind+":"+e.SubjectID]++
+			resources[e.ResourceType+":"+e.ResourceID]++
+		}
+	}
+
+	st := &Stats{
+		Total:  int64(len(entries)),
+		Series: make([]StatsPoint, 0, len(series)),
+	}
+	for k, n := range series {
+		p := StatsPoint{Key: k.key, Count: n}
+		if bucket > 0 {
+			p.Bucket = time.Unix(k.bucket, 0).UTC()
+		}
+		st.Series = append(st.Series, p)
+	}
+	SortSeries(st.Series)
+
+	if n := int64(len(latencies)); n > 0 {
+		slices.Sort(latencies)
+		st.Latency = LatencyPercentiles{
+			P50: latencies[RankIndex(n, 0.50)],
+			P95: latencies[RankIndex(n, 0.95)],
+			P99: latencies[RankIndex(n, 0.99)],
+		}
+	}
+
+	st.TopDeniedSubjects = TopCounts(subjects, q.TopN)
+	st.TopDeniedResources = TopCounts(resources, q.TopN)
+	return st
+}
+
+// DimensionValue returns the key an entry is grouped under.
+func DimensionValue(e *Entry, d Dimension) string {
+	switch d {
+	case DimensionResourceType:
+		return e.ResourceType
+	case DimensionAction:
+		return e.Action
+	case DimensionSubject:
+		return e.SubjectKind + ":" + e.SubjectID
+	default:
+		return e.Decision
+	}
+}
+
+// BucketStart floors a Unix timestamp to the start of its epoch-aligned
+// bucket of the given width in seconds.
+func BucketStart(unix, width int64) int64 {
+	return unix - ((unix%width)+width)%width
+}
+
+// RankIndex returns the zero-based nearest-rank index of percentile p
+// (0 < p ≤ 1) in a sorted sample of size n > 0. Backends use it so native
+// and in-memory percentiles agree.
+func RankIndex(n int64, p float64) int64 {
+	i := int64(math.Ceil(p*float64(n))) - 1
+	return max(0, min(i, n-1))
+}
+
+// SortSeries orders points by bucket, then key.
+func SortSeries(points []StatsPoint) {
+	slices.SortFunc(points, func(a, b StatsPoint) int {
+		if c := a.Bucket.Compare(b.Bucket); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+}
+
+// TopCounts ranks m by count descending, then key, keeping at most n.
+func TopCounts(m map[string]int64, n int) []KeyCount {
+	out := make([]KeyCount, 0, len(m))
+	for k, c := range m {
+		out = append(out, KeyCount{Key: k, Count: c})
+	}
+	slices.SortFunc(out, func(a, b KeyCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
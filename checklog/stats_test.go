@@ -0,0 +1,89 @@
+package checklog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/store/memory"
+)
+
+// listOnly hides the memory store's native aggregation so QueryStats takes
+// the paging fallback.
+type listOnly struct{ checklog.Store }
+
+func TestComputeStats(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []*checklog.Entry{
+		{SubjectKind: "user", SubjectID: "u1", ResourceType: "doc", ResourceID: "d1", Decision: "allow", EvalTimeNs: 10, CreatedAt: base.Add(5 * time.Minute)},
+		{SubjectKind: "user", SubjectID: "u2", ResourceType: "doc", ResourceID: "d1", Decision: "deny_default", EvalTimeNs: 20, CreatedAt: base.Add(10 * time.Minute)},
+		{SubjectKind: "user", SubjectID: "u2", ResourceType: "doc", ResourceID: "d2", Decision: "deny_explicit", EvalTimeNs: 30, CreatedAt: base.Add(70 * time.Minute)},
+		{SubjectKind: "user", SubjectID: "u1", ResourceType: "doc", ResourceID: "d1", Decision: "allow", EvalTimeNs: 40, CreatedAt: base.Add(80 * time.Minute)},
+	}
+	q := &checklog.StatsQuery{Bucket: time.Hour}
+	if err := q.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	st := checklog.ComputeStats(entries, q)
+
+	if st.Total != 4 {
+		t.Fatalf("total = %d", st.Total)
+	}
+	want := []checklog.StatsPoint{
+		{Bucket: base, Key: "allow", Count: 1},
+		{Bucket: base, Key: "deny_default", Count: 1},
+		{Bucket: base.Add(time.Hour), Key: "allow", Count: 1},
+		{Bucket: base.Add(time.Hour), Key: "deny_explicit", Count: 1},
+	}
+	if len(st.Series) != len(want) {
+		t.Fatalf("series = %+v", st.Series)
+	}
+	for i, p := range st.Series {
+		if !p.Bucket.Equal(want[i].Bucket) || p.Key != want[i].Key || p.Count != want[i].Count {
+			t.Errorf("series[%d] = %+v, want %+v", i, p, want[i])
+		}
+	}
+	if st.Latency != (checklog.LatencyPercentiles{P50: 20, P95: 40, P99: 40}) {
+		t.Errorf("latency = %+v", st.Latency)
+	}
+	if len(st.TopDeniedSubjects) != 1 || st.TopDeniedSubjects[0] != (checklog.KeyCount{Key: "user:u2", Count: 2}) {
+		t.Errorf("top subjects = %+v", st.TopDeniedSubjects)
+	}
+	if len(st.TopDeniedResources) != 2 || st.TopDeniedResources[0].Key != "doc:d1" {
+		t.Errorf("top resources = %+v", st.TopDeniedResources)
+	}
+}
+
+func TestQueryStats_Fallback(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	seedAged(t, s, "t1", 3, time.Minute)
+	seedAged(t, s, "t2", 2, time.Minute)
+
+	st, err := checklog.QueryStats(ctx, listOnly{s}, &checklog.StatsQuery{
+		Filter:  checklog.QueryFilter{TenantID: "t1"},
+		GroupBy: checklog.DimensionAction,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Total != 3 || len(st.Series) != 1 || st.Series[0].Key != "read" || st.Series[0].Count != 3 {
+		t.Fatalf("stats = %+v", st)
+	}
+	if !st.Series[0].Bucket.IsZero() {
+		t.Errorf("unbucketed query returned bucket %v", st.Series[0].Bucket)
+	}
+}
+
+func TestStatsQuery_Normalize(t *testing.T) {
+	for _, q := range []checklog.StatsQuery{
+		{GroupBy: "tenant"},
+		{Bucket: -time.Hour},
+		{Bucket: 1500 * time.Millisecond},
+	} {
+		if err := q.Normalize(); err == nil {
+			t.Errorf("Normalize(%+v) succeeded, want error", q)
+		}
+	}
+}
@@ -24,6 +24,10 @@ type Store interface {
 	// PurgeCheckLogs removes check log entries older than the given time.
 	PurgeCheckLogs(ctx context.Context, before time.Time) (int64, error)
 
+	// DeleteCheckLogs removes the check log entries with the given IDs and
+	// returns how many were deleted. Retention purges call it in batches.
+	DeleteCheckLogs(ctx context.Context, ids []id.CheckLogID) (int64, error)
+
 	// DeleteCheckLogsByTenant removes all check logs for a tenant.
 	DeleteCheckLogsByTenant(ctx context.Context, tenantID string) error
 }
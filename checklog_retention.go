@@ -0,0 +1,140 @@
+package warden
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	log "github.com/xraph/go-utils/log"
+
+	"github.com/xraph/warden/checklog"
+)
+
+// CheckLogRetention controls how long check log entries are kept. The zero
+// value keeps entries forever.
+type CheckLogRetention struct {
+	// MaxAge is the default retention. Entries older than this are purged.
+	// Zero keeps entries forever.
+	MaxAge time.Duration `json:"max_age,omitempty"`
+
+	// Tenants overrides MaxAge per tenant. A zero duration keeps that
+	// tenant's entries forever.
+	Tenants map[string]time.Duration `json:"tenants,omitempty"`
+
+	// Interval is how often the engine enforces retention once started.
+	// Zero disables the scheduler; ApplyCheckLogRetention can still be
+	// called directly.
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// BatchSize is the number of entries deleted per round trip.
+	// Defaults to checklog.DefaultPurgeBatchSize.
+	BatchSize int `json:"batch_size,omitempty"`
+
+	// ArchiveDir, when set, writes purged entries to gzip-compressed JSON
+	// lines files in this directory before deleting them.
+	ArchiveDir string `json:"archive_dir,omitempty"`
+}
+
+func (r CheckLogRetention) enabled() bool {
+	return r.MaxAge > 0 || len(r.Tenants) > 0
+}
+
+// ApplyCheckLogRetention purges check log entries that have outlived the
+// configured retention. Tenants with an override are purged on their own
+// schedule; every other tenant uses MaxAge.
+func (e *Engine) ApplyCheckLogRetention(ctx context.Context) (*checklog.PurgeResult, error) {
+	ret := e.Config().CheckLogRetention
+	now := time.Now()
+	total := &checklog.PurgeResult{}
+
+	overridden := make([]string, 0, len(ret.Tenants))
+	for tenantID := range ret.Tenants {
+		overridden = append(overridden, tenantID)
+	}
+	slices.Sort(overridden)
+
+	for _, tenantID := range overridden {
+		maxAge := ret.Tenants[tenantID]
+		if maxAge <= 0 {
+			continue
+		}
+		res, err := checklog.Purge(ctx, e.store, checklog.PurgeOptions{
+			TenantID:      tenantID,
+			Before:        now.Add(-maxAge),
+			BatchSize:     ret.BatchSize,
+			ArchiveDir:    ret.ArchiveDir,
+			ArchivePrefix: "checklog-" + tenantID,
+		})
+		total.Add(res)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	if ret.MaxAge > 0 {
+		res, err := checklog.Purge(ctx, e.store, checklog.PurgeOptions{
+			Before:         now.Add(-ret.MaxAge),
+			ExcludeTenants: overridden,
+			BatchSize:      ret.BatchSize,
+			ArchiveDir:     ret.ArchiveDir,
+			ArchivePrefix:  "checklog-all",
+		})
+		total.Add(res)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// startRetention launches the retention scheduler when configured.
+func (e *Engine) startRetention() {
+	ret := e.Config().CheckLogRetention
+	if ret.Interval <= 0 || !ret.enabled() {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	e.retentionStop = func() {
+		cancel()
+		<-done
+	}
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ret.Interval)
+		defer ticker.Stop()
+		for {
+			e.runRetention(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (e *Engine) runRetention(ctx context.Context) {
+	res, err := e.ApplyCheckLogRetention(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.logger.Error("warden: check log retention failed", log.Error(err))
+		}
+		return
+	}
+	if res.Purged > 0 {
+		e.logger.Info("warden: purged check logs",
+			log.Int64("purged", res.Purged),
+			log.Int("archives", len(res.Archives)),
+		)
+	}
+}
+
+// stopRetention stops the retention scheduler and waits for an in-flight
+// purge to return.
+func (e *Engine) stopRetention() {
+	if e.retentionStop != nil {
+		e.retentionStop()
+		e.retentionStop = nil
+	}
+}
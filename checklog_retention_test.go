@@ -0,0 +1,80 @@
+package warden
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/store/memory"
+)
+
+func TestEngine_ApplyCheckLogRetention(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	eng, err := NewEngine(WithStore(s), WithConfig(Config{
+		CheckLogRetention: CheckLogRetention{
+			MaxAge:  24 * time.Hour,
+			Tenants: map[string]time.Duration{"short": time.Hour, "forever": 0},
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed := func(tenantID string, age time.Duration) {
+		t.Helper()
+		if err := s.CreateCheckLog(ctx, &checklog.Entry{
+			TenantID: tenantID, SubjectKind: "user", SubjectID: "u1",
+			Action: "read", ResourceType: "document", Decision: "allow",
+			CreatedAt: time.Now().Add(-age),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seed("default", 2*time.Hour)  // kept: within MaxAge
+	seed("default", 48*time.Hour) // purged
+	seed("short", 2*time.Hour)    // purged: tenant override
+	seed("forever", 48*time.Hour) // kept: zero override
+
+	res, err := eng.ApplyCheckLogRetention(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Purged != 2 {
+		t.Fatalf("purged %d, want 2", res.Purged)
+	}
+	for tenantID, want := range map[string]int64{"default": 1, "short": 0, "forever": 1} {
+		n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{TenantID: tenantID}) //nolint:errcheck // memory store
+		if n != want {
+			t.Errorf("tenant %s: %d entries, want %d", tenantID, n, want)
+		}
+	}
+}
+
+func TestEngine_RetentionScheduler(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	eng, err := NewEngine(WithStore(s), WithConfig(Config{
+		CheckLogRetention: CheckLogRetention{MaxAge: time.Hour, Interval: time.Hour},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.CreateCheckLog(ctx, &checklog.Entry{TenantID: "t1", CreatedAt: time.Now().Add(-2 * time.Hour)})
+
+	// The scheduler runs once immediately on Start.
+	if err := eng.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Stop(ctx) //nolint:errcheck // test cleanup
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{}); n == 0 { //nolint:errcheck // polled
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("expected scheduler to purge expired entry")
+}
@@ -0,0 +1,170 @@
+package warden
+
+import (
+	"maps"
+	"math/rand/v2"
+)
+
+// CheckLogRules decides which check results are written to the check log.
+// The zero value logs every check.
+//
+// Denies bypass the include/exclude filters and sampling unless
+// AlwaysLogDenies is explicitly false. Allows are filtered first, then
+// sampled at AllowSampleRate percent. Checks where a shadow-mode policy
+// would have changed the decision, checks where a policy failed to
+// evaluate, and checks served by break-glass access, are always logged.
+//
+// Patterns use the same glob syntax as policy actions/resources
+// ("document", "doc*", "*"). Subject patterns match "kind:id", e.g.
+// "user:*" or "service:ci-bot".
+type CheckLogRules struct {
+	// AlwaysLogDenies logs every deny regardless of filters and sampling.
+	// Defaults to true.
+	AlwaysLogDenies *bool `json:"always_log_denies,omitempty"`
+
+	// AllowSampleRate is the percentage (0–100) of allowed checks to log.
+	// Nil means 100.
+	AllowSampleRate *float64 `json:"allow_sample_rate,omitempty"`
+
+	// IncludeResourceTypes, when non-empty, restricts logging to matching
+	// resource types.
+	IncludeResourceTypes []string `json:"include_resource_types,omitempty"`
+
+	// ExcludeResourceTypes skips matching resource types.
+	ExcludeResourceTypes []string `json:"exclude_resource_types,omitempty"`
+
+	// IncludeActions, when non-empty, restricts logging to matching actions.
+	IncludeActions []string `json:"include_actions,omitempty"`
+
+	// ExcludeActions skips matching actions.
+	ExcludeActions []string `json:"exclude_actions,omitempty"`
+
+	// IncludeSubjects, when non-empty, restricts logging to matching
+	// "kind:id" subjects.
+	IncludeSubjects []string `json:"include_subjects,omitempty"`
+
+	// ExcludeSubjects skips matching "kind:id" subjects.
+	ExcludeSubjects []string `json:"exclude_subjects,omitempty"`
+
+	// Tenants holds per-tenant overrides. Fields set on an override replace
+	// the base value; unset fields inherit it. Nested Tenants are ignored.
+	Tenants map[string]CheckLogRules `json:"tenants,omitempty"`
+}
+
+// ForTenant returns the effective rules for a tenant: the base rules with
+// the tenant override (if any) layered on top.
+func (r CheckLogRules) ForTenant(tenantID string) CheckLogRules {
+	out := r
+	out.Tenants = nil
+	o, ok := r.Tenants[tenantID]
+	if !ok {
+		return out
+	}
+	if o.AlwaysLogDenies != nil {
+		out.AlwaysLogDenies = o.AlwaysLogDenies
+	}
+	if o.AllowSampleRate != nil {
+		out.AllowSampleRate = o.AllowSampleRate
+	}
+	if o.IncludeResourceTypes != nil {
+		out.IncludeResourceTypes = o.IncludeResourceTypes
+	}
+	if o.ExcludeResourceTypes != nil {
+		out.ExcludeResourceTypes = o.ExcludeResourceTypes
+	}
+	if o.IncludeActions != nil {
+		out.IncludeActions = o.IncludeActions
+	}
+	if o.ExcludeActions != nil {
+		out.ExcludeActions = o.ExcludeActions
+	}
+	if o.IncludeSubjects != nil {
+		out.IncludeSubjects = o.IncludeSubjects
+	}
+	if o.ExcludeSubjects != nil {
+		out.ExcludeSubjects = o.ExcludeSubjects
+	}
+	return out
+}
+
+// ShouldLog reports whether a check result should be written. sample is a
+// uniform random value in [0, 100) used for allow sampling. Results with
+// shadow decisions are always logged so rollouts can be observed, results
+// with policy errors so broken policies are noticed, and break-glass
+// results so emergency access is never missing from the log.
+func (r CheckLogRules) ShouldLog(req *CheckRequest, result *CheckResult, sample float64) bool {
+	if len(result.Shadow) > 0 || len(result.Errors) > 0 || servedByBreakGlass(result) {
+		return true
+	}
+	if !result.Allowed && (r.AlwaysLogDenies == nil || *r.AlwaysLogDenies) {
+		return true
+	}
+	subject := string(req.Subject.Kind) + ":" + req.Subject.ID
+	if !includes(r.IncludeResourceTypes, req.Resource.Type) ||
+		!includes(r.IncludeActions, req.Action.Name) ||
+		!includes(r.IncludeSubjects, subject) {
+		return false
+	}
+	if matchesAny(r.ExcludeResourceTypes, req.Resource.Type) ||
+		matchesAny(r.ExcludeActions, req.Action.Name) ||
+		matchesAny(r.ExcludeSubjects, subject) {
+		return false
+	}
+	if result.Allowed && r.AllowSampleRate != nil {
+		return sample < *r.AllowSampleRate
+	}
+	return true
+}
+
+// includes reports whether value passes an include list. An empty list
+// includes everything.
+func includes(patterns []string, value string) bool {
+	return len(patterns) == 0 || matchesAny(patterns, value)
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckLogRules returns the engine's current check-log rules.
+func (e *Engine) CheckLogRules() CheckLogRules {
+	e.checkLogMu.RLock()
+	defer e.checkLogMu.RUnlock()
+	return e.config.CheckLog
+}
+
+// SetCheckLogRules replaces check-log rules at runtime. An empty tenantID
+// replaces the base rules and merges rules.Tenants into the existing
+// overrides; otherwise it sets the override for that tenant.
+func (e *Engine) SetCheckLogRules(tenantID string, rules CheckLogRules) {
+	e.checkLogMu.Lock()
+	defer e.checkLogMu.Unlock()
+	cur := e.config.CheckLog
+	tenants := maps.Clone(cur.Tenants)
+	if tenantID == "" {
+		cur = rules
+		if len(rules.Tenants) > 0 && tenants == nil {
+			tenants = make(map[string]CheckLogRules, len(rules.Tenants))
+		}
+		maps.Copy(tenants, rules.Tenants)
+	} else {
+		if tenants == nil {
+			tenants = make(map[string]CheckLogRules)
+		}
+		rules.Tenants = nil
+		tenants[tenantID] = rules
+	}
+	cur.Tenants = tenants
+	e.config.CheckLog = cur
+}
+
+// shouldWriteCheckLog applies the check-log rules for the scope's tenant.
+func (e *Engine) shouldWriteCheckLog(scope tenantScope, req *CheckRequest, result *CheckResult) bool {
+	rules := e.CheckLogRules().ForTenant(scope.tenantID)
+	return rules.ShouldLog(req, result, rand.Float64()*100) //nolint:gosec // sampling, not security
+}
@@ -0,0 +1,114 @@
+package warden
+
+import "testing"
+
+func TestCheckLogRules_ZeroValueLogsEverything(t *testing.T) {
+	var r CheckLogRules
+	req := &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "document"},
+	}
+	if !r.ShouldLog(req, &CheckResult{Allowed: true}, 99.9) {
+		t.Fatal("expected allow to be logged")
+	}
+	if !r.ShouldLog(req, &CheckResult{Allowed: false}, 99.9) {
+		t.Fatal("expected deny to be logged")
+	}
+}
+
+func TestCheckLogRules_SamplesAllowsButAlwaysLogsDenies(t *testing.T) {
+	rate := 10.0
+	r := CheckLogRules{AllowSampleRate: &rate, ExcludeResourceTypes: []string{"document"}}
+	req := &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "folder"},
+	}
+	if !r.ShouldLog(req, &CheckResult{Allowed: true}, 5) {
+		t.Fatal("expected sample below rate to be logged")
+	}
+	if r.ShouldLog(req, &CheckResult{Allowed: true}, 50) {
+		t.Fatal("expected sample above rate to be dropped")
+	}
+
+	req.Resource.Type = "document"
+	if r.ShouldLog(req, &CheckResult{Allowed: true}, 0) {
+		t.Fatal("expected excluded resource type to be dropped")
+	}
+	if !r.ShouldLog(req, &CheckResult{Allowed: false}, 99) {
+		t.Fatal("expected deny to bypass filters")
+	}
+
+	f := false
+	r.AlwaysLogDenies = &f
+	if r.ShouldLog(req, &CheckResult{Allowed: false}, 0) {
+		t.Fatal("expected deny to honour filters when always_log_denies is false")
+	}
+}
+
+func TestCheckLogRules_IncludeFilters(t *testing.T) {
+	r := CheckLogRules{
+		IncludeActions:  []string{"delete", "write"},
+		IncludeSubjects: []string{"service:*"},
+	}
+	req := &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "delete"},
+		Resource: Resource{Type: "document"},
+	}
+	if r.ShouldLog(req, &CheckResult{Allowed: true}, 0) {
+		t.Fatal("expected non-matching subject to be dropped")
+	}
+	req.Subject = Subject{Kind: "service", ID: "ci-bot"}
+	if !r.ShouldLog(req, &CheckResult{Allowed: true}, 0) {
+		t.Fatal("expected matching subject and action to be logged")
+	}
+	req.Action.Name = "read"
+	if r.ShouldLog(req, &CheckResult{Allowed: true}, 0) {
+		t.Fatal("expected non-matching action to be dropped")
+	}
+}
+
+func TestCheckLogRules_TenantOverride(t *testing.T) {
+	base, override := 100.0, 0.0
+	r := CheckLogRules{
+		AllowSampleRate: &base,
+		ExcludeActions:  []string{"list"},
+		Tenants: map[string]CheckLogRules{
+			"t1": {AllowSampleRate: &override},
+		},
+	}
+	eff := r.ForTenant("t1")
+	if eff.AllowSampleRate == nil || *eff.AllowSampleRate != 0 {
+		t.Fatalf("expected tenant sample rate 0, got %v", eff.AllowSampleRate)
+	}
+	if len(eff.ExcludeActions) != 1 {
+		t.Fatal("expected unset override fields to inherit base")
+	}
+	if eff.Tenants != nil {
+		t.Fatal("expected effective rules to drop tenant map")
+	}
+	if got := r.ForTenant("t2"); *got.AllowSampleRate != 100 {
+		t.Fatal("expected other tenants to use base rules")
+	}
+}
+
+func TestEngine_SetCheckLogRules(t *testing.T) {
+	eng, _ := newTestEngine(t)
+	zero := 0.0
+
+	eng.SetCheckLogRules("t1", CheckLogRules{AllowSampleRate: &zero})
+	eng.SetCheckLogRules("", CheckLogRules{ExcludeActions: []string{"read"}})
+
+	rules := eng.CheckLogRules()
+	if len(rules.ExcludeActions) != 1 {
+		t.Fatal("expected base rules to be replaced")
+	}
+	if _, ok := rules.Tenants["t1"]; !ok {
+		t.Fatal("expected tenant override to survive base replacement")
+	}
+	if eng.Config().CheckLog.Tenants["t1"].AllowSampleRate == nil {
+		t.Fatal("expected Config to reflect runtime rules")
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/cmd/internal/cli"
+	"github.com/xraph/warden/store"
+)
+
+// runCheckLog implements `warden checklog <purge|export>`.
+func runCheckLog(args []string) int {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "usage: warden checklog <purge|export> [flags]")
+		return 2
+	}
+	switch args[0] {
+	case "purge":
+		return runCheckLogPurge(args[1:])
+	case "export":
+		return runCheckLogExport(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "warden checklog: unknown subcommand %q (purge|export)\n", args[0])
+		return 2
+	}
+}
+
+// runCheckLogPurge deletes check log entries older than a cutoff in
+// batches, optionally archiving them first.
+func runCheckLogPurge(args []string) int {
+	fs := flag.NewFlagSet("warden checklog purge", flag.ExitOnError)
+	var (
+		storeDSN    = fs.String("store", "", "store DSN (required)")
+		tenantID    = fs.String("tenant", "", "limit the purge to one tenant")
+		olderThan   = fs.String("older-than", "", "purge entries older than this age, e.g. 720h or 30d")
+		beforeStr   = fs.String("before", "", "purge entries created before this RFC3339 timestamp")
+		batchSize   = fs.Int("batch-size", checklog.DefaultPurgeBatchSize, "entries deleted per batch")
+		archiveDir  = fs.String("archive-dir", "", "archive purged entries as .jsonl.gz files in this directory")
+		dryRun      = fs.Bool("dry-run", false, "count matching entries without deleting")
+		skipMigrate = fs.Bool("skip-migrate", false, "skip running store migrations on connect")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *storeDSN == "" {
+		fmt.Fprintln(os.Stderr, "warden checklog purge: --store is required")
+		return 2
+	}
+	before, err := purgeCutoff(*olderThan, *beforeStr, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warden checklog purge: %v\n", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	s, closeStore, code := openCheckLogStore(ctx, "warden checklog purge", *storeDSN, *skipMigrate)
+	if s == nil {
+		return code
+	}
+	defer closeStore()
+
+	if *dryRun {
+		n, err := s.CountCheckLogs(ctx, &checklog.QueryFilter{TenantID: *tenantID, Before: &before})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warden checklog purge: %v\n", err)
+			return 3
+		}
+		fmt.Printf("warden: would purge %d check log entries created before %s\n", n, before.Format(time.RFC3339))
+		return 0
+	}
+
+	prefix := "checklog-all"
+	if *tenantID != "" {
+		prefix = "checklog-" + *tenantID
+	}
+	res, err := checklog.Purge(ctx, s, checklog.PurgeOptions{
+		TenantID:      *tenantID,
+		Before:        before,
+		BatchSize:     *batchSize,
+		ArchiveDir:    *archiveDir,
+		ArchivePrefix: prefix,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warden checklog purge: %v (purged %d before failing)\n", err, res.Purged)
+		return 3
+	}
+	fmt.Printf("warden: purged %d check log entries created before %s\n", res.Purged, before.Format(time.RFC3339))
+	for _, path := range res.Archives {
+		fmt.Printf("archived to %s\n", path)
+	}
+	return 0
+}
+
+// runCheckLogExport writes check log entries as JSON lines. Output ending
+// in .gz is gzip-compressed in the same format retention archives use.
+func runCheckLogExport(args []string) int {
+	fs := flag.NewFlagSet("warden checklog export", flag.ExitOnError)
+	var (
+		storeDSN    = fs.String("store", "", "store DSN (required)")
+		tenantID    = fs.String("tenant", "", "limit the export to one tenant")
+		afterStr    = fs.String("after", "", "only entries created after this RFC3339 timestamp")
+		beforeStr   = fs.String("before", "", "only entries created before this RFC3339 timestamp")
+		decision    = fs.String("decision", "", "only entries with this decision")
+		outPath     = fs.String("o", "-", "output file (- for stdout; .gz suffix compresses)")
+		batchSize   = fs.Int("batch-size", checklog.DefaultPurgeBatchSize, "entries read per batch")
+		skipMigrate = fs.Bool("skip-migrate", true, "skip running store migrations on connect (export is read-only)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *storeDSN == "" {
+		fmt.Fprintln(os.Stderr, "warden checklog export: --store is required")
+		return 2
+	}
+	if *batchSize <= 0 {
+		*batchSize = checklog.DefaultPurgeBatchSize
+	}
+	filter := &checklog.QueryFilter{TenantID: *tenantID, Decision: *decision}
+	for _, f := range []struct {
+		raw string
+		dst **time.Time
+	}{{*afterStr, &filter.After}, {*beforeStr, &filter.Before}} {
+		if f.raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, f.raw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warden checklog export: invalid timestamp %q\n", f.raw)
+			return 2
+		}
+		*f.dst = &t
+	}
+
+	ctx := context.Background()
+	s, closeStore, code := openCheckLogStore(ctx, "warden checklog export", *storeDSN, *skipMigrate)
+	if s == nil {
+		return code
+	}
+	defer closeStore()
+
+	var out io.Writer = os.Stdout
+	if *outPath != "-" {
+		f, err := os.Create(*outPath) //nolint:gosec // user-chosen output path
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warden checklog export: %v\n", err)
+			return 3
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var (
+		write func(...*checklog.Entry) error
+		flush func() error
+	)
+	if strings.HasSuffix(*outPath, ".gz") {
+		aw := checklog.NewArchiveWriter(out)
+		write, flush = aw.Write, aw.Close
+	} else {
+		bw := bufio.NewWriter(out)
+		enc := json.NewEncoder(bw)
+		write = func(entries ...*checklog.Entry) error {
+			for _, e := range entries {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		flush = bw.Flush
+	}
+
+	var total int
+	for offset := 0; ; offset += *batchSize {
+		filter.Limit, filter.Offset = *batchSize, offset
+		batch, err := s.ListCheckLogs(ctx, filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warden checklog export: %v\n", err)
+			return 3
+		}
+		if err := write(batch...); err != nil {
+			fmt.Fprintf(os.Stderr, "warden checklog export: %v\n", err)
+			return 3
+		}
+		total += len(batch)
+		if len(batch) < *batchSize {
+			break
+		}
+	}
+	if err := flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "warden checklog export: %v\n", err)
+		return 3
+	}
+	fmt.Fprintf(os.Stderr, "warden: exported %d check log entries\n", total)
+	return 0
+}
+
+// openCheckLogStore opens and optionally migrates the store. On failure it
+// prints the error and returns a nil store with the exit code.
+func openCheckLogStore(ctx context.Context, cmd, dsn string, skipMigrate bool) (store.Store, func(), int) {
+	s, closeStore, err := cli.OpenStore(ctx, dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+		return nil, nil, 3
+	}
+	closeFn := func() {
+		if cerr := closeStore(); cerr != nil {
+			fmt.Fprintf(os.Stderr, "%s: close store: %v\n", cmd, cerr)
+		}
+	}
+	if mErr := cli.MaybeMigrate(ctx, s, skipMigrate); mErr != nil {
+		fmt.Fprintf(os.Stderr, "%s: migrate: %v\n", cmd, mErr)
+		closeFn()
+		return nil, nil, 3
+	}
+	return s, closeFn, 0
+}
+
+// purgeCutoff resolves --older-than / --before into a cutoff time. Exactly
+// one must be set. Ages accept Go durations plus a "d" (days) suffix.
+func purgeCutoff(olderThan, before string, now time.Time) (time.Time, error) {
+	switch {
+	case olderThan != "" && before != "":
+		return time.Time{}, fmt.Errorf("--older-than and --before are mutually exclusive")
+	case before != "":
+		t, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --before timestamp %q", before)
+		}
+		return t, nil
+	case olderThan != "":
+		age, err := parseAge(olderThan)
+		if err != nil || age <= 0 {
+			return time.Time{}, fmt.Errorf("invalid --older-than %q (e.g. 720h or 30d)", olderThan)
+		}
+		return now.Add(-age), nil
+	default:
+		return time.Time{}, fmt.Errorf("one of --older-than or --before is required")
+	}
+}
+
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -5,6 +5,11 @@
 //	warden lint <path>                   — static checks; no DB required
 //	warden apply -f <path> --store <DSN> — apply config to a tenant
 //	warden diff  -f <path> --store <DSN> — alias for `apply --dry-run`
+//	warden checklog purge|export         — check-log retention and export
+//	warden report --tenant ID --store DSN — unused-permission report
+//	warden simulate -f <path> --store DSN — decisions a change would flip
+//	warden audit verify --store DSN      — check the audit hash chain
+//	warden audit sod --store DSN         — report existing SoD violations
 //
 // Path may be a single .warden file, a directory (walked recursively for
 // .warden files), or a glob pattern. Hidden directories are skipped;
@@ -33,6 +38,7 @@ import (
 	"strings"
 
 	"github.com/xraph/warden"
+	"github.com/xraph/warden/audit"
 	"github.com/xraph/warden/cmd/internal/cli"
 	"github.com/xraph/warden/dsl"
 	"github.com/xraph/warden/lsp"
@@ -54,6 +60,14 @@ func main() {
 		os.Exit(runFmt(os.Args[2:]))
 	case "export":
 		os.Exit(runExport(os.Args[2:]))
+	case "checklog":
+		os.Exit(runCheckLog(os.Args[2:]))
+	case "report":
+		os.Exit(runReport(os.Args[2:]))
+	case "simulate":
+		os.Exit(runSimulate(os.Args[2:]))
+	case "audit":
+		os.Exit(runAudit(os.Args[2:]))
 	case "lsp":
 		os.Exit(runLSP(os.Args[2:]))
 	case "version", "--version", "-v":
@@ -80,6 +94,18 @@ USAGE:
   warden fmt   -d <path>               Print canonical-form diff
   warden fmt   --check <path>          Exit 1 if reformatting needed
   warden export --tenant ID --store DSN -o <dir>  Dump tenant state to .warden
+  warden checklog purge --store DSN --older-than 30d [--archive-dir DIR]
+                                       Delete old check logs in batches
+  warden checklog export --store DSN [-o file.jsonl.gz]
+                                       Dump check logs as JSON lines
+  warden report --tenant ID --store DSN [--window 90d] [--diff] [--json]
+                                       Unused permissions and over-privileged roles
+  warden simulate -f <path> --store DSN [--tenant ID] [--since 7d] [--requests file.jsonl] [--json]
+                                       Replay checks against proposed changes; list flipped decisions
+  warden audit verify --store DSN [--tenant ID]
+                                       Check the audit trail's hash chain
+  warden audit sod --store DSN [--tenant ID] [--json]
+                                       Report SoD and max_members violations
   warden lsp                           Start the language server (stdio)
 
 PATH formats:
@@ -98,6 +124,7 @@ FLAGS:
   --var KEY=VALUE     Expand ${KEY} placeholders (repeatable; overrides WARDEN_VAR_*)
   --dry-run           Plan without writing
   --prune             Delete tenant entries not in config (apply only)
+  --actor NAME        Author recorded in the audit trail (default $USER)
   --skip-migrate      Don't run store migrations on connect
 
 ENVIRONMENT:
@@ -181,6 +208,7 @@ func runApply(args []string, dryRunDefault bool) int {
 		dryRun      = fs.Bool("dry-run", dryRunDefault, "plan without writing")
 		prune       = fs.Bool("prune", false, "delete tenant entries not in config")
 		skipMigrate = fs.Bool("skip-migrate", false, "skip running store migrations on connect")
+		actor       = fs.String("actor", os.Getenv("USER"), "author recorded in the audit trail")
 	)
 	fs.Var(cliVars, "var", "set DSL template variable, e.g. --var TENANT=acme (repeatable; overrides WARDEN_VAR_*)")
 	if err := fs.Parse(args); err != nil {
@@ -233,6 +261,10 @@ func runApply(args []string, dryRunDefault bool) int {
 		return 3
 	}
 
+	ctx = audit.WithSource(ctx, audit.SourceCLI)
+	if *actor != "" {
+		ctx = audit.WithActor(ctx, audit.Actor{Kind: "user", ID: *actor})
+	}
 	result, err := dsl.Apply(ctx, eng, prog, dsl.ApplyOptions{
 		TenantID: *tenantID,
 		AppID:    *appID,
@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestCLI_Lint runs the binary as a subprocess against the bundled
@@ -103,6 +104,64 @@ func TestCLI_LintInvalidExitsNonZero(t *testing.T) {
 	}
 }
 
+func TestCLI_CheckLogPurgeDryRun(t *testing.T) {
+	bin := buildBin(t)
+	cmd := exec.CommandContext(context.Background(), bin, "checklog", "purge", "--store", "memory:", "--older-than", "30d", "--dry-run")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("checklog purge exited %v\nstderr: %s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "would purge 0") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestCLI_ReportEmptyTenant(t *testing.T) {
+	bin := buildBin(t)
+	cmd := exec.CommandContext(context.Background(), bin, "report", "--store", "memory:", "--tenant", "t1", "--diff")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("report exited %v\nstderr: %s", err, stderr.String())
+	}
+	for _, want := range []string{"privilege report for tenant t1", "UNUSED PERMISSIONS (0)", "no .warden changes suggested"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestCLI_AuditSoDEmptyTenant(t *testing.T) {
+	bin := buildBin(t)
+	cmd := exec.CommandContext(context.Background(), bin, "audit", "sod", "--store", "memory:", "--tenant", "t1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("audit sod exited %v\nstderr: %s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "no sod violations") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestPurgeCutoff(t *testing.T) {
+	now := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	got, err := purgeCutoff("30d", "", now)
+	if err != nil || !got.Equal(now.AddDate(0, 0, -30)) {
+		t.Fatalf("30d = %v, %v", got, err)
+	}
+	if _, err := purgeCutoff("", "", now); err == nil {
+		t.Fatal("expected error when no cutoff given")
+	}
+	if _, err := purgeCutoff("1h", "2026-01-01T00:00:00Z", now); err == nil {
+		t.Fatal("expected error when both cutoffs given")
+	}
+}
+
 func buildBin(t *testing.T) string {
 	t.Helper()
 	if runtime.GOOS == "windows" {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/dsl"
+)
+
+// runReport implements `warden report`: the unused-permission and
+// over-privilege report built from check history.
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("warden report", flag.ExitOnError)
+	var (
+		storeDSN    = fs.String("store", "", "store DSN (required)")
+		tenantID    = fs.String("tenant", "", "tenant ID (required)")
+		window      = fs.String("window", "90d", "check history to examine, e.g. 720h or 30d")
+		asJSON      = fs.Bool("json", false, "print the report as JSON")
+		diff        = fs.Bool("diff", false, "print a suggested .warden diff removing unused grants")
+		skipMigrate = fs.Bool("skip-migrate", true, "skip running store migrations on connect (report is read-only)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *storeDSN == "" || *tenantID == "" {
+		fmt.Fprintln(os.Stderr, "warden report: --store and --tenant are required")
+		return 2
+	}
+	age, err := parseAge(*window)
+	if err != nil || age <= 0 {
+		fmt.Fprintf(os.Stderr, "warden report: invalid --window %q (e.g. 720h or 30d)\n", *window)
+		return 2
+	}
+
+	ctx := context.Background()
+	s, closeStore, code := openCheckLogStore(ctx, "warden report", *storeDSN, *skipMigrate)
+	if s == nil {
+		return code
+	}
+	defer closeStore()
+
+	eng, err := warden.NewEngine(warden.WithStore(s))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warden report: %v\n", err)
+		return 3
+	}
+	rep, err := eng.PrivilegeReport(ctx, warden.PrivilegeReportOptions{TenantID: *tenantID, Window: age})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warden report: %v\n", err)
+		return 3
+	}
+
+	var suggested string
+	if *diff {
+		suggested, err = dsl.PrivilegeDiff(ctx, eng, rep)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warden report: %v\n", err)
+			return 3
+		}
+	}
+
+	if *asJSON {
+		out := struct {
+			*warden.PrivilegeReport
+			Diff string `json:"diff,omitempty"`
+		}{rep, suggested}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintf(os.Stderr, "warden report: %v\n", err)
+			return 3
+		}
+		return 0
+	}
+
+	printReport(os.Stdout, rep)
+	if *diff {
+		fmt.Println()
+		if suggested == "" {
+			fmt.Println("# no .warden changes suggested")
+		} else {
+			fmt.Print(suggested)
+		}
+	}
+	return 0
+}
+
+func printReport(w io.Writer, rep *warden.PrivilegeReport) {
+	fmt.Fprintf(w, "warden: privilege report for tenant %s, %s to %s (%d allowed checks)\n",
+		rep.TenantID, rep.Since.Format(time.RFC3339), rep.Until.Format(time.RFC3339), rep.Checks)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nOVER-PRIVILEGED ROLES (%d)\n", len(rep.OverPrivilegedRoles))
+	if len(rep.OverPrivilegedRoles) > 0 {
+		fmt.Fprintln(tw, "ROLE\tMEMBERS\tUSED\tUNUSED")
+		for _, r := range rep.OverPrivilegedRoles {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", roleLabel(r.RoleSlug, r.NamespacePath), r.Members,
+				strings.Join(r.Used, ","), strings.Join(r.Unused, ","))
+		}
+	}
+	fmt.Fprintf(tw, "\nUNUSED PERMISSIONS (%d)\n", len(rep.UnusedPermissions))
+	if len(rep.UnusedPermissions) > 0 {
+		fmt.Fprintln(tw, "ROLE\tPERMISSION")
+		for _, p := range rep.UnusedPermissions {
+			fmt.Fprintf(tw, "%s\t%s\n", roleLabel(p.RoleSlug, p.NamespacePath), p.Permission)
+		}
+	}
+	fmt.Fprintf(tw, "\nUNUSED ASSIGNMENTS (%d)\n", len(rep.UnusedAssignments))
+	if len(rep.UnusedAssignments) > 0 {
+		fmt.Fprintln(tw, "SUBJECT\tROLE\tASSIGNMENT")
+		for _, a := range rep.UnusedAssignments {
+			fmt.Fprintf(tw, "%s:%s\t%s\t%s\n", a.SubjectKind, a.SubjectID, roleLabel(a.RoleSlug, a.NamespacePath), a.AssignmentID)
+		}
+	}
+	_ = tw.Flush() //nolint:errcheck // best-effort terminal output
+}
+
+func roleLabel(slug, namespacePath string) string {
+	if namespacePath == "" {
+		return slug
+	}
+	return namespacePath + "/" + slug
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/dsl"
+)
+
+// runSimulate implements `warden simulate`: replay recorded checks against
+// the model with a .warden change applied and list the decisions that
+// would flip. Nothing is written to the store.
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("warden simulate", flag.ExitOnError)
+	cliVars := &varList{}
+	var (
+		path        = fs.String("f", "", "path to the proposed .warden changes (required)")
+		storeDSN    = fs.String("store", "", "store DSN (required)")
+		tenantID    = fs.String("tenant", "", "tenant to simulate (defaults to 'tenant' in source)")
+		since       = fs.String("since", "7d", "replay checks recorded in this window, e.g. 24h or 7d")
+		limit       = fs.Int("limit", warden.DefaultSimulationLimit, "maximum checks to replay")
+		requests    = fs.String("requests", "", "replay checks from a JSON lines file instead of the check log")
+		prune       = fs.Bool("prune", false, "treat the changes as the tenant's complete model")
+		asJSON      = fs.Bool("json", false, "print the report as JSON")
+		skipMigrate = fs.Bool("skip-migrate", true, "skip running store migrations on connect (simulate is read-only)")
+	)
+	fs.Var(cliVars, "var", "set DSL template variable, e.g. --var TENANT=acme (repeatable; overrides WARDEN_VAR_*)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" || *storeDSN == "" {
+		fmt.Fprintln(os.Stderr, "warden simulate: -f and --store are required")
+		return 2
+	}
+	age, err := parseAge(*since)
+	if err != nil || age <= 0 {
+		fmt.Fprintf(os.Stderr, "warden simulate: invalid --since %q (e.g. 24h or 7d)\n", *since)
+		return 2
+	}
+
+	prog, errs, err := dsl.Load(*path, dsl.WithVariables(resolveVariables(cliVars)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warden simulate: %v\n", err)
+		return 3
+	}
+	allErrs := append([]*dsl.Diagnostic{}, errs...)
+	allErrs = append(allErrs, dsl.Resolve(prog)...)
+	if len(allErrs) > 0 {
+		for _, d := range allErrs {
+			fmt.Fprintln(os.Stderr, d.String())
+		}
+		return 1
+	}
+	tenant := *tenantID
+	if tenant == "" {
+		tenant = prog.Tenant
+	}
+
+	var reqs []*warden.CheckRequest
+	if *requests != "" {
+		f, err := os.Open(*requests)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warden simulate: %v\n", err)
+			return 3
+		}
+		reqs, err = warden.ReadCheckRequests(f)
+		_ = f.Close() //nolint:errcheck // read-only
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warden simulate: %s: %v\n", *requests, err)
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	s, closeStore, code := openCheckLogStore(ctx, "warden simulate", *storeDSN, *skipMigrate)
+	if s == nil {
+		return code
+	}
+	defer closeStore()
+
+	ev := dsl.NewEngineEvaluator(s)
+	eng, err := warden.NewEngine(warden.WithStore(s), warden.WithExpressionEvaluator(ev))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warden simulate: %v\n", err)
+		return 3
+	}
+
+	ctx = warden.WithTenant(ctx, prog.App, tenant)
+	changes := dsl.SimulationChanges(prog, dsl.ApplyOptions{TenantID: tenant, Prune: *prune})
+	var rep *warden.SimulationReport
+	if reqs != nil {
+		rep, err = eng.SimulateRequests(ctx, changes, reqs)
+	} else {
+		after := time.Now().Add(-age)
+		rep, err = eng.Simulate(ctx, changes, &checklog.QueryFilter{TenantID: tenant, After: &after, Limit: *limit})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warden simulate: %v\n", err)
+		return 3
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			fmt.Fprintf(os.Stderr, "warden simulate: %v\n", err)
+			return 3
+		}
+		return 0
+	}
+	printSimulation(os.Stdout, *path, rep)
+	return 0
+}
+
+func printSimulation(w io.Writer, path string, rep *warden.SimulationReport) {
+	fmt.Fprintf(w, "warden: simulated %s against %d checks (%d skipped)\n", path, rep.Checks, rep.Skipped)
+	fmt.Fprintf(w, "%d newly allowed, %d newly denied\n", rep.NewlyAllowed, rep.NewlyDenied)
+	if len(rep.Flips) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nCHANGE\tSUBJECT\tACTION\tRESOURCE\tBEFORE\tAFTER")
+	for _, f := range rep.Flips {
+		change := "- deny"
+		if f.After.Allowed {
+			change = "+ allow"
+		}
+		r := f.Request
+		fmt.Fprintf(tw, "%s\t%s:%s\t%s\t%s:%s\t%s\t%s\n", change,
+			r.Subject.Kind, r.Subject.ID, r.Action.Name, r.Resource.Type, r.Resource.ID,
+			decisionLabel(f.Before), decisionLabel(f.After))
+	}
+	_ = tw.Flush() //nolint:errcheck // best-effort terminal output
+}
+
+// decisionLabel renders a decision with the rules that produced it, e.g.
+// "allow (rbac:role_…)".
+func decisionLabel(r *warden.CheckResult) string {
+	if len(r.MatchedBy) == 0 {
+		if r.Reason != "" {
+			return string(r.Decision) + " (" + r.Reason + ")"
+		}
+		return string(r.Decision)
+	}
+	rules := make([]string, len(r.MatchedBy))
+	for i, m := range r.MatchedBy {
+		rules[i] = m.Source + ":" + m.RuleID
+	}
+	return string(r.Decision) + " (" + strings.Join(rules, ", ") + ")"
+}
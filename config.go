@@ -12,6 +12,23 @@ type Config struct {
 	// Zero means no caching.
 	CacheTTL time.Duration `json:"cache_ttl,omitempty"`
 
+	// AttributeCacheTTL is how long values returned by AttributeProvider
+	// plugins are reused across checks for the same subject or resource.
+	// Zero caches them for the duration of a single check only.
+	AttributeCacheTTL time.Duration `json:"attribute_cache_ttl,omitempty"`
+
+	// PolicyIndexTTL is how long the compiled ABAC policies of a tenant
+	// scope are reused before they are reloaded from the store. Changes
+	// made through Engine.Store, or reported by a store that implements
+	// policy.ChangeNotifier, take effect immediately; the TTL bounds how
+	// long other changes, e.g. by another process sharing the database,
+	// go unseen. Zero means 30 seconds for a store that implements
+	// policy.ChangeNotifier and disables the index for any other store;
+	// set a positive value to enable it there and accept that window. A
+	// negative value always disables the index, and a disabled index
+	// reads policies from the store on every check.
+	PolicyIndexTTL time.Duration `json:"policy_index_ttl,omitempty"`
+
 	// EnableRBAC enables role-based access control evaluation.
 	// Defaults to true.
 	EnableRBAC *bool `json:"enable_rbac,omitempty"`
@@ -27,6 +44,37 @@ type Config struct {
 	// EnableCheckLog enables writing authorization check results to the
 	// check log store. Defaults to true.
 	EnableCheckLog *bool `json:"enable_check_log,omitempty"`
+
+	// CheckLog controls which checks are logged when the check log is
+	// enabled. The zero value logs every check.
+	CheckLog CheckLogRules `json:"check_log,omitempty"`
+
+	// CheckLogRetention controls how long check log entries are kept and
+	// where purged entries are archived. The zero value keeps everything.
+	CheckLogRetention CheckLogRetention `json:"check_log_retention,omitempty"`
+
+	// EnableAudit records every change to roles, permissions, assignments,
+	// relations, policies, resource types and break-glass grants made
+	// through Engine.Store in the tamper-evident audit trail. Defaults to
+	// true.
+	EnableAudit *bool `json:"enable_audit,omitempty"`
+
+	// BreakGlass controls who may request emergency access and what it
+	// may grant. The zero value disables break-glass.
+	BreakGlass BreakGlassConfig `json:"break_glass,omitempty"`
+
+	// PersistRateCounters keeps the counters behind rate conditions in the
+	// store, so they survive restarts and are shared by every engine using
+	// it. By default each engine counts in memory.
+	PersistRateCounters bool `json:"persist_rate_counters,omitempty"`
+
+	// DynamicSoDActiveRolesOnly applies dynamic separation-of-duties
+	// constraints only to checks that pass Subject.ActiveRoles. By default
+	// a check without them treats every assigned role as active, so a
+	// subject holding roles a dynamic constraint keeps apart is denied
+	// until the caller activates a subset. Set it for callers that never
+	// pass an active set.
+	DynamicSoDActiveRolesOnly bool `json:"dynamic_sod_active_roles_only,omitempty"`
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -38,6 +86,7 @@ func DefaultConfig() Config {
 		EnableABAC:     &t,
 		EnableReBAC:    &t,
 		EnableCheckLog: &t,
+		EnableAudit:    &t,
 	}
 }
 
@@ -45,3 +94,4 @@ func (c Config) rbacEnabled() bool     { return c.EnableRBAC == nil || *c.Enable
 func (c Config) abacEnabled() bool     { return c.EnableABAC == nil || *c.EnableABAC }
 func (c Config) rebacEnabled() bool    { return c.EnableReBAC == nil || *c.EnableReBAC }
 func (c Config) checkLogEnabled() bool { return c.EnableCheckLog == nil || *c.EnableCheckLog }
+func (c Config) auditEnabled() bool    { return c.EnableAudit == nil || *c.EnableAudit }
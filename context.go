@@ -8,6 +8,16 @@ const (
 	ctxKeyAppID contextKey = iota
 	ctxKeyTenantID
 	ctxKeyNamespacePath
+	ctxKeyRequestIP
+	ctxKeyRequestID
+	ctxKeyAttributeResolver
+	ctxKeyCalendars
+	ctxKeyRates
+	ctxKeyHierarchy
+	ctxKeyTracers
+	ctxKeySpan
+	ctxKeyAttributeSchemas
+	ctxKeyObligationRefs
 )
 
 // WithTenant returns a context with the given app and tenant IDs.
@@ -29,6 +39,22 @@ func WithNamespace(ctx context.Context, namespacePath string) context.Context {
 	return context.WithValue(ctx, ctxKeyNamespacePath, namespacePath)
 }
 
+// WithRequestInfo returns a context carrying the caller's IP address and
+// request ID. The engine copies both onto check log entries. The HTTP
+// middleware sets this automatically; standalone callers may set it
+// themselves.
+func WithRequestInfo(ctx context.Context, requestIP, requestID string) context.Context {
+	ctx = context.WithValue(ctx, ctxKeyRequestIP, requestIP)
+	ctx = context.WithValue(ctx, ctxKeyRequestID, requestID)
+	return ctx
+}
+
+func requestInfoFromContext(ctx context.Context) (requestIP, requestID string) {
+	requestIP, _ = ctx.Value(ctxKeyRequestIP).(string) //nolint:errcheck // absent → ""
+	requestID, _ = ctx.Value(ctxKeyRequestID).(string) //nolint:errcheck // absent → ""
+	return requestIP, requestID
+}
+
 func appIDFromContext(ctx context.Context) string {
 	v, ok := ctx.Value(ctxKeyAppID).(string)
 	if !ok {
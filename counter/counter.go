@@ -0,0 +1,49 @@
+// Package counter defines sliding-window event counters. The engine counts
+// what subjects do, e.g. how many exports a user ran, and rate conditions
+// compare the count over a trailing window with a threshold.
+//
+// Events are recorded in buckets of Resolution width. A window covers the
+// buckets that start within it, so counts are exact to the second and the
+// same for every Store implementation.
+package counter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resolution is the width of a counter bucket.
+const Resolution = time.Second
+
+// MaxWindow is the longest window a count may cover. Buckets older than
+// this are purged.
+const MaxWindow = 24 * time.Hour
+
+// Key identifies one counter: the events of a subject for an action within
+// a tenant.
+type Key struct {
+	TenantID    string `json:"tenant_id"`
+	SubjectKind string `json:"subject_kind"`
+	SubjectID   string `json:"subject_id"`
+	Action      string `json:"action"`
+}
+
+// Bucket returns the start of the bucket an event at t is recorded in.
+func Bucket(t time.Time) time.Time {
+	return t.UTC().Truncate(Resolution)
+}
+
+// Since returns the start of the oldest bucket in the window of the given
+// length ending at now.
+func Since(now time.Time, window time.Duration) time.Time {
+	return Bucket(now).Add(-window + Resolution)
+}
+
+// ValidateWindow reports a window that is not a whole number of
+// Resolution units between Resolution and MaxWindow.
+func ValidateWindow(window time.Duration) error {
+	if window < Resolution || window > MaxWindow || window%Resolution != 0 {
+		return fmt.Errorf("window must be a whole number of seconds between %s and %s, got %s", Resolution, MaxWindow, window)
+	}
+	return nil
+}
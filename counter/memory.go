@@ -0,0 +1,81 @@
+package counter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Compile-time interface check.
+var _ Store = (*Memory)(nil)
+
+// Memory is an in-process Store. It is what the engine counts in unless
+// counters are persisted in the engine's store.
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[Key]map[int64]int64 // bucket start in Unix seconds → events
+}
+
+// NewMemory returns an empty in-memory counter store.
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[Key]map[int64]int64)}
+}
+
+// IncrementCounter adds n events to a bucket.
+func (m *Memory) IncrementCounter(_ context.Context, key Key, bucket time.Time, n int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = make(map[int64]int64)
+		m.buckets[key] = b
+	}
+	b[bucket.Unix()] += n
+	return nil
+}
+
+// SumCounter returns the events in the buckets starting at or after since.
+func (m *Memory) SumCounter(_ context.Context, key Key, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total int64
+	from := since.Unix()
+	for start, n := range m.buckets[key] {
+		if start >= from {
+			total += n
+		}
+	}
+	return total, nil
+}
+
+// PurgeCounters removes the buckets starting before before.
+func (m *Memory) PurgeCounters(_ context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var purged int64
+	cutoff := before.Unix()
+	for key, b := range m.buckets {
+		for start := range b {
+			if start < cutoff {
+				delete(b, start)
+				purged++
+			}
+		}
+		if len(b) == 0 {
+			delete(m.buckets, key)
+		}
+	}
+	return purged, nil
+}
+
+// DeleteCountersByTenant removes all counters for a tenant.
+func (m *Memory) DeleteCountersByTenant(_ context.Context, tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.buckets {
+		if key.TenantID == tenantID {
+			delete(m.buckets, key)
+		}
+	}
+	return nil
+}
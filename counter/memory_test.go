@@ -0,0 +1,69 @@
+package counter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory_SlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	key := Key{TenantID: "t1", SubjectKind: "user", SubjectID: "u1", Action: "export"}
+	t0 := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{t0, t0.Add(30 * time.Minute), t0.Add(time.Hour - time.Second)} {
+		if err := m.IncrementCounter(ctx, key, Bucket(at), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := key
+	other.Action = "read"
+	if err := m.IncrementCounter(ctx, other, Bucket(t0), 5); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int64
+	}{
+		{"window still holds the first event", t0.Add(time.Hour - time.Millisecond), 3},
+		{"first event slid out", t0.Add(time.Hour), 2},
+		{"all slid out", t0.Add(2 * time.Hour), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.SumCounter(ctx, key, Since(tt.now, time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("SumCounter = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	purged, err := m.PurgeCounters(ctx, t0.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Errorf("purged %d buckets, want 2", purged)
+	}
+	if n, _ := m.SumCounter(ctx, other, time.Time{}); n != 0 { //nolint:errcheck // memory never fails
+		t.Errorf("purged counter still has %d events", n)
+	}
+}
+
+func TestValidateWindow(t *testing.T) {
+	for _, w := range []time.Duration{time.Second, time.Hour, MaxWindow} {
+		if err := ValidateWindow(w); err != nil {
+			t.Errorf("ValidateWindow(%s) = %v", w, err)
+		}
+	}
+	for _, w := range []time.Duration{0, 500 * time.Millisecond, 1500 * time.Millisecond, MaxWindow + time.Second} {
+		if err := ValidateWindow(w); err == nil {
+			t.Errorf("ValidateWindow(%s) succeeded", w)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package counter
+
+import (
+	"context"
+	"time"
+)
+
+// Store defines persistence operations for counter buckets. Callers pass
+// bucket starts computed with Bucket and Since, so every implementation
+// counts the same events.
+type Store interface {
+	// IncrementCounter adds n events to the bucket of key starting at
+	// bucket, creating it if needed.
+	IncrementCounter(ctx context.Context, key Key, bucket time.Time, n int64) error
+
+	// SumCounter returns the number of events of key in the buckets
+	// starting at or after since.
+	SumCounter(ctx context.Context, key Key, since time.Time) (int64, error)
+
+	// PurgeCounters removes the buckets starting before before and returns
+	// how many were removed.
+	PurgeCounters(ctx context.Context, before time.Time) (int64, error)
+
+	// DeleteCountersByTenant removes all counters for a tenant.
+	DeleteCountersByTenant(ctx context.Context, tenantID string) error
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/a-h/templ"
 
 	"github.com/xraph/forge/extensions/dashboard/contributor"
 
 	"github.com/xraph/warden"
+	"github.com/xraph/warden/audit"
 	"github.com/xraph/warden/dashboard/components"
 	"github.com/xraph/warden/dashboard/pages"
 	"github.com/xraph/warden/dashboard/settings"
 	"github.com/xraph/warden/dashboard/widgets"
+	"github.com/xraph/warden/dsl"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/plugin"
 	"github.com/xraph/warden/store"
@@ -111,8 +114,12 @@ func (c *Contributor) renderPageRoute(ctx context.Context, pageRoute string, s s
 		return c.renderResourceTypeForm(ctx, s)
 	case "/check-logs":
 		return c.renderCheckLogs(ctx, s, params)
+	case "/audit":
+		return c.renderAudit(ctx, s, params)
 	case "/playground":
 		return c.renderPlayground(ctx)
+	case "/privilege-report":
+		return c.renderPrivilegeReport(ctx, params)
 	default:
 		return nil, contributor.ErrPageNotFound
 	}
@@ -166,6 +173,11 @@ func (c *Contributor) renderOverview(ctx context.Context, s store.Store) (templ.
 		logs = nil
 	}
 
+	checkStats, err := fetchCheckLogStats(ctx, s, "", 24*time.Hour, time.Hour)
+	if err != nil {
+		checkStats = nil
+	}
+
 	cfg := c.engine.Config()
 
 	// Fetch roles for the create dialogs on the overview page
@@ -175,7 +187,7 @@ func (c *Contributor) renderOverview(ctx context.Context, s store.Store) (templ.
 
 	return templ.ComponentFunc(func(tCtx context.Context, w io.Writer) error {
 		childCtx := templ.WithChildren(tCtx, components.PluginSections(pluginSections))
-		return pages.OverviewPage(counts.Roles, counts.Permissions, counts.Assignments, counts.Relations, counts.Policies, counts.ResourceTypes, logs, cfg, allRoles).Render(childCtx, w)
+		return pages.OverviewPage(counts.Roles, counts.Permissions, counts.Assignments, counts.Relations, counts.Policies, counts.ResourceTypes, logs, checkStats, cfg, allRoles).Render(childCtx, w)
 	}), nil
 }
 
@@ -395,10 +407,46 @@ func (c *Contributor) renderCheckLogs(ctx context.Context, s store.Store, params
 	return pages.CheckLogsPage(items, params.QueryParams, pg), nil
 }
 
+func (c *Contributor) renderAudit(ctx context.Context, s store.Store, params contributor.Params) (templ.Component, error) {
+	limit := parseIntParam(params.QueryParams, "limit", 50)
+	offset := parseIntParam(params.QueryParams, "offset", 0)
+	tenantID := params.QueryParams["tenant"]
+
+	items, total, err := fetchAuditPaginated(ctx, s, tenantID, params.QueryParams, limit, offset)
+	if err != nil {
+		items = nil
+		total = 0
+	}
+	v, err := audit.VerifyChain(ctx, s, tenantID)
+	if err != nil {
+		v = nil
+	}
+	pg := components.NewPaginationMeta(total, limit, offset)
+	return pages.AuditPage(items, v, params.QueryParams, pg), nil
+}
+
 func (c *Contributor) renderPlayground(_ context.Context) (templ.Component, error) {
 	return pages.PlaygroundPage(), nil
 }
 
+func (c *Contributor) renderPrivilegeReport(ctx context.Context, params contributor.Params) (templ.Component, error) {
+	days := parseIntParam(params.QueryParams, "days", 90)
+	if days <= 0 {
+		days = 90
+	}
+	rep, err := c.engine.PrivilegeReport(ctx, warden.PrivilegeReportOptions{
+		Window: time.Duration(days) * 24 * time.Hour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("warden dashboard: privilege report: %w", err)
+	}
+	diff, err := dsl.PrivilegeDiff(ctx, c.engine, rep)
+	if err != nil {
+		diff = ""
+	}
+	return pages.PrivilegeReportPage(rep, days, diff), nil
+}
+
 // ─── Widget Render Helpers ───────────────────────────────────────────────────
 
 func (c *Contributor) renderStatsWidget(ctx context.Context, s store.Store) (templ.Component, error) {
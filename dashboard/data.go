@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
 	"github.com/xraph/warden/checklog"
 	"github.com/xraph/warden/dashboard/pages"
 	"github.com/xraph/warden/id"
@@ -225,6 +226,29 @@ func fetchCheckLogsPaginated(ctx context.Context, s store.Store, tenantID string
 	return entries, total, nil
 }
 
+// fetchAuditPaginated returns a page of one tenant's audit trail, newest
+// first, with the total count.
+func fetchAuditPaginated(ctx context.Context, s store.Store, tenantID string, params map[string]string, limit, offset int) ([]*audit.Entry, int64, error) {
+	filter := &audit.QueryFilter{
+		TenantID:   tenantID,
+		EntityType: audit.EntityType(params["entity_type"]),
+		EntityID:   params["entity_id"],
+		Operation:  audit.Operation(params["operation"]),
+		Source:     audit.Source(params["source"]),
+		ActorID:    params["actor_id"],
+		After:      parseTimeParam(params, "after"),
+		Before:     parseTimeParam(params, "before"),
+	}
+	total, _ := s.CountAuditEntries(ctx, filter) //nolint:errcheck // pagination count
+	filter.Limit = limit
+	filter.Offset = offset
+	entries, err := s.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("dashboard: fetch audit entries: %w", err)
+	}
+	return entries, total, nil
+}
+
 // ─── Non-Paginated Fetch Functions ───────────────────────────────────────────
 
 // fetchRoles returns all roles for the given tenant.
@@ -257,6 +281,20 @@ func fetchCheckLogs(ctx context.Context, s store.Store, tenantID string, limit i
 	return entries, nil
 }
 
+// fetchCheckLogStats aggregates the tenant's check logs over the trailing
+// window into buckets of the given width.
+func fetchCheckLogStats(ctx context.Context, s store.Store, tenantID string, window, bucket time.Duration) (*checklog.Stats, error) {
+	after := time.Now().Add(-window)
+	stats, err := checklog.QueryStats(ctx, s, &checklog.StatsQuery{
+		Filter: checklog.QueryFilter{TenantID: tenantID, After: &after},
+		Bucket: bucket,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dashboard: fetch check log stats: %w", err)
+	}
+	return stats, nil
+}
+
 // fetchRoleWithPermissions returns a role and its attached permissions.
 func fetchRoleWithPermissions(ctx context.Context, s store.Store, roleID id.RoleID) (*role.Role, []*permission.Permission, error) {
 	r, err := s.GetRole(ctx, roleID)
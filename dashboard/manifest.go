@@ -94,6 +94,8 @@ func baseNav() []contributor.NavItem {
 
 		// Monitoring — audit and logs
 		{Label: "Check Logs", Path: "/check-logs", Icon: "scroll-text", Group: "Monitoring", Priority: 0},
+		{Label: "Privilege Report", Path: "/privilege-report", Icon: "shield-alert", Group: "Monitoring", Priority: 1},
+		{Label: "Audit Trail", Path: "/audit", Icon: "history", Group: "Monitoring", Priority: 2},
 	}
 }
 
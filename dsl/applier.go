@@ -1,19 +1,25 @@
 package dsl
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/xraph/warden"
+	"github.com/xraph/warden/audit"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
 )
 
 // ApplyOptions configures the DSL applier.
@@ -52,30 +58,33 @@ type ApplyResult struct {
 // apps should always pass a tenant explicitly to avoid accidentally
 // landing entities in the global bucket.
 func Apply(ctx context.Context, eng *warden.Engine, prog *Program, opts ApplyOptions) (*ApplyResult, error) {
-	tenantID := firstNonEmpty(opts.TenantID, prog.Tenant)
+	ctx = audit.WithDefaultSource(ctx, audit.SourceDSL)
 	if errs := Resolve(prog); len(errs) > 0 {
 		return nil, &DiagnosticError{Diags: errs}
 	}
+	a := newApplier(ctx, eng.Store(), prog, opts)
+	a.eng = eng
+	if err := a.run(prog); err != nil {
+		return nil, err
+	}
+	return a.result, nil
+}
 
+func newApplier(ctx context.Context, s applierStore, prog *Program, opts ApplyOptions) *applier {
 	now := opts.Now
 	if now.IsZero() {
 		now = time.Now().UTC()
 	}
-	a := &applier{
+	return &applier{
 		ctx:      ctx,
-		eng:      eng,
-		store:    eng.Store(),
-		tenantID: tenantID,
+		store:    s,
+		tenantID: firstNonEmpty(opts.TenantID, prog.Tenant),
 		appID:    firstNonEmpty(opts.AppID, prog.App),
 		now:      now,
 		prune:    opts.Prune,
 		dryRun:   opts.DryRun,
 		result:   &ApplyResult{},
 	}
-	if err := a.run(prog); err != nil {
-		return nil, err
-	}
-	return a.result, nil
 }
 
 func firstNonEmpty(a, b string) string {
@@ -85,39 +94,47 @@ func firstNonEmpty(a, b string) string {
 	return b
 }
 
+// applierStore is the store surface the applier writes through.
+type applierStore interface {
+	// Roles
+	CreateRole(ctx context.Context, r *role.Role) error
+	GetRoleBySlug(ctx context.Context, tenantID, namespacePath, slug string) (*role.Role, error)
+	UpdateRole(ctx context.Context, r *role.Role) error
+	DeleteRole(ctx context.Context, roleID id.RoleID) error
+	ListRoles(ctx context.Context, filter *role.ListFilter) ([]*role.Role, error)
+	// Permissions
+	CreatePermission(ctx context.Context, p *permission.Permission) error
+	GetPermissionByName(ctx context.Context, tenantID, namespacePath, name string) (*permission.Permission, error)
+	UpdatePermission(ctx context.Context, p *permission.Permission) error
+	DeletePermission(ctx context.Context, permID id.PermissionID) error
+	ListPermissions(ctx context.Context, filter *permission.ListFilter) ([]*permission.Permission, error)
+	SetRolePermissions(ctx context.Context, roleID id.RoleID, refs []permission.Ref) error
+	// Policies
+	CreatePolicy(ctx context.Context, p *policy.Policy) error
+	GetPolicyByName(ctx context.Context, tenantID, namespacePath, name string) (*policy.Policy, error)
+	UpdatePolicy(ctx context.Context, p *policy.Policy) error
+	DeletePolicy(ctx context.Context, polID id.PolicyID) error
+	ListPolicies(ctx context.Context, filter *policy.ListFilter) ([]*policy.Policy, error)
+	// Resource types
+	CreateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error
+	GetResourceTypeByName(ctx context.Context, tenantID, namespacePath, name string) (*resourcetype.ResourceType, error)
+	UpdateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error
+	DeleteResourceType(ctx context.Context, rtID id.ResourceTypeID) error
+	ListResourceTypes(ctx context.Context, filter *resourcetype.ListFilter) ([]*resourcetype.ResourceType, error)
+	// Relations
+	CreateRelation(ctx context.Context, t *relation.Tuple) error
+	ListRelations(ctx context.Context, filter *relation.ListFilter) ([]*relation.Tuple, error)
+	// Separation-of-duties constraints
+	CreateSoDConstraint(ctx context.Context, c *sod.Constraint) error
+	UpdateSoDConstraint(ctx context.Context, c *sod.Constraint) error
+	DeleteSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) error
+	ListSoDConstraints(ctx context.Context, filter *sod.ListFilter) ([]*sod.Constraint, error)
+}
+
 type applier struct {
 	ctx   context.Context
 	eng   *warden.Engine
-	store interface {
-		// Roles
-		CreateRole(ctx context.Context, r *role.Role) error
-		GetRoleBySlug(ctx context.Context, tenantID, namespacePath, slug string) (*role.Role, error)
-		UpdateRole(ctx context.Context, r *role.Role) error
-		DeleteRole(ctx context.Context, roleID id.RoleID) error
-		ListRoles(ctx context.Context, filter *role.ListFilter) ([]*role.Role, error)
-		// Permissions
-		CreatePermission(ctx context.Context, p *permission.Permission) error
-		GetPermissionByName(ctx context.Context, tenantID, namespacePath, name string) (*permission.Permission, error)
-		UpdatePermission(ctx context.Context, p *permission.Permission) error
-		DeletePermission(ctx context.Context, permID id.PermissionID) error
-		ListPermissions(ctx context.Context, filter *permission.ListFilter) ([]*permission.Permission, error)
-		SetRolePermissions(ctx context.Context, roleID id.RoleID, refs []permission.Ref) error
-		// Policies
-		CreatePolicy(ctx context.Context, p *policy.Policy) error
-		GetPolicyByName(ctx context.Context, tenantID, namespacePath, name string) (*policy.Policy, error)
-		UpdatePolicy(ctx context.Context, p *policy.Policy) error
-		DeletePolicy(ctx context.Context, polID id.PolicyID) error
-		ListPolicies(ctx context.Context, filter *policy.ListFilter) ([]*policy.Policy, error)
-		// Resource types
-		CreateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error
-		GetResourceTypeByName(ctx context.Context, tenantID, namespacePath, name string) (*resourcetype.ResourceType, error)
-		UpdateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error
-		DeleteResourceType(ctx context.Context, rtID id.ResourceTypeID) error
-		ListResourceTypes(ctx context.Context, filter *resourcetype.ListFilter) ([]*resourcetype.ResourceType, error)
-		// Relations
-		CreateRelation(ctx context.Context, t *relation.Tuple) error
-		ListRelations(ctx context.Context, filter *relation.ListFilter) ([]*relation.Tuple, error)
-	}
+	store applierStore
 
 	tenantID string
 	appID    string
@@ -144,7 +161,14 @@ func (a *applier) run(prog *Program) error {
 	if err := a.applyPolicies(prog); err != nil {
 		return err
 	}
-	return a.applyRelations(prog)
+	if err := a.applyRelations(prog); err != nil {
+		return err
+	}
+	if err := a.applySoD(prog); err != nil {
+		return err
+	}
+	a.applyCheckLog(prog)
+	return nil
 }
 
 // ─────────────────────────────────────────────────────────────────────────
@@ -163,6 +187,7 @@ func (a *applier) applyResourceTypes(prog *Program) error {
 			Description:   rt.Description,
 			Relations:     rtRelations(rt),
 			Permissions:   rtPermissions(rt),
+			Attributes:    rtAttributes(rt),
 			CreatedAt:     a.now,
 			UpdatedAt:     a.now,
 		}
@@ -225,6 +250,17 @@ func rtPermissions(rt *ResourceDecl) []resourcetype.PermissionDef {
 	return out
 }
 
+func rtAttributes(rt *ResourceDecl) []resourcetype.AttributeDef {
+	if len(rt.Attributes) == 0 {
+		return nil
+	}
+	out := make([]resourcetype.AttributeDef, 0, len(rt.Attributes))
+	for _, attr := range rt.Attributes {
+		out = append(out, attr.attributeDef())
+	}
+	return out
+}
+
 func rtEquivalent(a, b *resourcetype.ResourceType) bool {
 	if a.Description != b.Description {
 		return false
@@ -245,7 +281,9 @@ func rtEquivalent(a, b *resourcetype.ResourceType) bool {
 			return false
 		}
 	}
-	return true
+	return slices.EqualFunc(a.Attributes, b.Attributes, func(x, y resourcetype.AttributeDef) bool {
+		return x.Name == y.Name && x.Type == y.Type && x.Required == y.Required && slices.Equal(x.Enum, y.Enum)
+	})
 }
 
 func (a *applier) pruneResourceTypes(declared map[string]struct{}) error {
@@ -513,23 +551,27 @@ func (a *applier) applyPolicies(prog *Program) error {
 	for _, p := range prog.Policies {
 		declared[keyOf(p.NamespacePath, p.Name)] = struct{}{}
 		desired := &policy.Policy{
-			TenantID:      a.tenantID,
-			NamespacePath: p.NamespacePath,
-			AppID:         a.appID,
-			Name:          p.Name,
-			Description:   p.Description,
-			Effect:        policy.Effect(p.Effect),
-			Priority:      p.Priority,
-			IsActive:      p.Active,
-			NotBefore:     p.NotBefore,
-			NotAfter:      p.NotAfter,
-			Obligations:   p.Obligations,
-			Version:       1,
-			Actions:       p.Actions,
-			Resources:     p.Resources,
-			Conditions:    flattenConditions(p.Conditions),
-			CreatedAt:     a.now,
-			UpdatedAt:     a.now,
+			TenantID:              a.tenantID,
+			NamespacePath:         p.NamespacePath,
+			AppID:                 a.appID,
+			Name:                  p.Name,
+			Description:           p.Description,
+			Effect:                policy.Effect(p.Effect),
+			Priority:              p.Priority,
+			IsActive:              p.Active,
+			Mode:                  policy.Mode(p.Mode),
+			OnError:               policy.OnError(p.OnError),
+			NotBefore:             p.NotBefore,
+			NotAfter:              p.NotAfter,
+			Obligations:           p.Obligations,
+			StructuredObligations: obligationsFromDecls(p.StructuredObligations),
+			Advice:                obligationsFromDecls(p.Advice),
+			Version:               1,
+			Actions:               p.Actions,
+			Resources:             p.Resources,
+			Conditions:            flattenConditions(p.Conditions),
+			CreatedAt:             a.now,
+			UpdatedAt:             a.now,
 		}
 		existing, _ := a.store.GetPolicyByName(a.ctx, a.tenantID, p.NamespacePath, p.Name) //nolint:errcheck // missing → create
 		if existing == nil {
@@ -581,7 +623,7 @@ func policyEquivalent(a, b *policy.Policy) bool {
 		return false
 	}
 	if a.Description != b.Description || a.Effect != b.Effect ||
-		a.Priority != b.Priority || a.IsActive != b.IsActive {
+		a.Priority != b.Priority || a.IsActive != b.IsActive || a.Mode != b.Mode || a.OnError != b.OnError {
 		return false
 	}
 	if !timePtrEqual(a.NotBefore, b.NotBefore) || !timePtrEqual(a.NotAfter, b.NotAfter) {
@@ -590,6 +632,9 @@ func policyEquivalent(a, b *policy.Policy) bool {
 	if strings.Join(a.Obligations, ",") != strings.Join(b.Obligations, ",") {
 		return false
 	}
+	if !obligationsEqual(a.StructuredObligations, b.StructuredObligations) || !obligationsEqual(a.Advice, b.Advice) {
+		return false
+	}
 	if strings.Join(a.Actions, ",") != strings.Join(b.Actions, ",") {
 		return false
 	}
@@ -601,7 +646,10 @@ func policyEquivalent(a, b *policy.Policy) bool {
 	}
 	for i := range a.Conditions {
 		if a.Conditions[i].Field != b.Conditions[i].Field ||
-			a.Conditions[i].Operator != b.Conditions[i].Operator {
+			a.Conditions[i].Operator != b.Conditions[i].Operator ||
+			a.Conditions[i].ValueRef != b.Conditions[i].ValueRef ||
+			a.Conditions[i].Expression != b.Conditions[i].Expression ||
+			a.Conditions[i].TimeZone != b.Conditions[i].TimeZone {
 			return false
 		}
 		// Value comparison via fmt round-trip — covers most literal types
@@ -613,6 +661,35 @@ func policyEquivalent(a, b *policy.Policy) bool {
 	return true
 }
 
+// obligationsEqual compares through JSON so a stored policy whose params
+// came back as float64 still matches the ints the parser produced.
+func obligationsEqual(a, b []policy.Obligation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+func obligationsFromDecls(decls []*ObligationDecl) []policy.Obligation {
+	if len(decls) == 0 {
+		return nil
+	}
+	out := make([]policy.Obligation, len(decls))
+	for i, d := range decls {
+		out[i] = policy.Obligation{
+			Name:      d.Name,
+			Params:    d.Params,
+			FulfillOn: policy.FulfillOn(d.FulfillOn),
+		}
+	}
+	return out
+}
+
 func timePtrEqual(a, b *time.Time) bool {
 	if a == nil && b == nil {
 		return true
@@ -628,17 +705,20 @@ func flattenConditions(in []*Condition) []policy.Condition {
 	for _, c := range in {
 		flatten := func(c *Condition) {
 			out = append(out, policy.Condition{
-				ID:       id.NewConditionID(),
-				Field:    c.Field,
-				Operator: policy.Operator(c.Operator),
-				Value:    c.Value,
+				ID:         id.NewConditionID(),
+				Field:      c.Field,
+				Operator:   policy.Operator(c.Operator),
+				Value:      c.Value,
+				ValueRef:   c.ValueRef,
+				Expression: c.Expression,
+				TimeZone:   c.TimeZone,
 			})
 		}
 		switch {
 		case len(c.AllOf) > 0:
 			// AllOf: append each as separate AND-merged condition.
 			for _, inner := range c.AllOf {
-				if inner.Field != "" {
+				if inner.isAtom() {
 					flatten(inner)
 				}
 			}
@@ -647,12 +727,12 @@ func flattenConditions(in []*Condition) []policy.Condition {
 			// record only the first sub-condition to avoid silent drops.
 			// Future work: extend evaluator to support OR groups.
 			for _, inner := range c.AnyOf {
-				if inner.Field != "" {
+				if inner.isAtom() {
 					flatten(inner)
 					break
 				}
 			}
-		case c.Field != "":
+		case c.isAtom():
 			flatten(c)
 		}
 	}
@@ -713,6 +793,132 @@ func (a *applier) applyRelations(prog *Program) error {
 	return nil
 }
 
+// ─────────────────────────────────────────────────────────────────────────
+// Separation-of-duties constraints.
+// ─────────────────────────────────────────────────────────────────────────
+
+// applySoD upserts the program's sod constraints by name. Constraints are
+// tenant-wide, so prune removes every undeclared constraint of the
+// tenant. Assignments that already break a new or tightened static
+// constraint are left in place for `warden audit sod` to report.
+func (a *applier) applySoD(prog *Program) error {
+	existing, err := a.store.ListSoDConstraints(a.ctx, &sod.ListFilter{TenantID: a.tenantID})
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*sod.Constraint, len(existing))
+	for _, c := range existing {
+		byName[c.Name] = c
+	}
+	declared := make(map[string]struct{}, len(prog.SoDConstraints))
+	for _, d := range prog.SoDConstraints {
+		declared[d.Name] = struct{}{}
+		desired := d.constraint()
+		desired.TenantID = a.tenantID
+		desired.AppID = a.appID
+		desired.CreatedAt = a.now
+		desired.UpdatedAt = a.now
+		cur := byName[d.Name]
+		if cur == nil {
+			// ID is auto-assigned by the store on CreateSoDConstraint.
+			a.result.Created = append(a.result.Created, "+ sod/"+d.Name)
+			if !a.dryRun {
+				if err := a.store.CreateSoDConstraint(a.ctx, desired); err != nil && !errors.Is(err, warden.ErrAlreadyExists) {
+					return fmt.Errorf("create sod constraint %s: %w", d.Name, err)
+				}
+			}
+			continue
+		}
+		desired.ID = cur.ID
+		desired.CreatedAt = cur.CreatedAt
+		desired.Metadata = cur.Metadata
+		if cur.Description == desired.Description &&
+			cur.Kind == desired.Kind &&
+			slices.Equal(cur.Roles, desired.Roles) &&
+			cur.MaxRoles == desired.MaxRoles {
+			a.result.NoOps++
+			continue
+		}
+		a.result.Updated = append(a.result.Updated, "~ sod/"+d.Name)
+		if !a.dryRun {
+			if err := a.store.UpdateSoDConstraint(a.ctx, desired); err != nil {
+				return fmt.Errorf("update sod constraint %s: %w", d.Name, err)
+			}
+		}
+	}
+	if a.prune {
+		for _, c := range existing {
+			if _, ok := declared[c.Name]; ok {
+				continue
+			}
+			a.result.Deleted = append(a.result.Deleted, "- sod/"+c.Name)
+			if !a.dryRun {
+				if err := a.store.DeleteSoDConstraint(a.ctx, c.ID); err != nil {
+					return fmt.Errorf("delete sod constraint %s: %w", c.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────
+// Check-log rules.
+// ─────────────────────────────────────────────────────────────────────────
+
+// applyCheckLog installs the program's checklog block on the engine. The
+// rules live in engine memory rather than the store, so they are re-applied
+// on every start. When the apply is tenant-scoped, the top-level rules
+// become that tenant's override. Simulations have no engine to install them
+// on and skip the block.
+func (a *applier) applyCheckLog(prog *Program) {
+	if prog.CheckLog == nil || a.eng == nil {
+		return
+	}
+	desired := make(map[string]warden.CheckLogRules, len(prog.CheckLog.Tenants)+1)
+	desired[a.tenantID] = checkLogRules(prog.CheckLog)
+	for _, t := range prog.CheckLog.Tenants {
+		desired[t.Tenant] = checkLogRules(t)
+	}
+	current := a.eng.CheckLogRules()
+	for tenantID, rules := range desired {
+		existing := current.Tenants[tenantID]
+		if tenantID == "" {
+			existing = current
+			existing.Tenants = nil
+		}
+		label := tenantID
+		if label == "" {
+			label = "*"
+		}
+		if reflect.DeepEqual(existing, rules) {
+			a.result.NoOps++
+			continue
+		}
+		a.result.Updated = append(a.result.Updated, fmt.Sprintf("~ checklog/%s", label))
+		if !a.dryRun {
+			a.eng.SetCheckLogRules(tenantID, rules)
+		}
+	}
+}
+
+func checkLogRules(d *CheckLogDecl) warden.CheckLogRules {
+	r := warden.CheckLogRules{
+		AlwaysLogDenies:      d.AlwaysLogDenies,
+		IncludeResourceTypes: d.IncludeResourceTypes,
+		ExcludeResourceTypes: d.ExcludeResourceTypes,
+		IncludeActions:       d.IncludeActions,
+		ExcludeActions:       d.ExcludeActions,
+		IncludeSubjects:      d.IncludeSubjects,
+		ExcludeSubjects:      d.ExcludeSubjects,
+	}
+	if d.AllowSampleRate != nil {
+		rate := float64(*d.AllowSampleRate)
+		r.AllowSampleRate = &rate
+	}
+	return r
+}
+
 // ─────────────────────────────────────────────────────────────────────────
 // Helpers.
 // ─────────────────────────────────────────────────────────────────────────
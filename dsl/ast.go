@@ -25,6 +25,12 @@ type Program struct {
 	Imports       []*ImportDecl
 	Namespaces    []*NamespaceDecl
 
+	// SoDConstraints are the tenant-wide `sod "<name>" { ... }` blocks.
+	SoDConstraints []*SoDDecl
+
+	// CheckLog holds the optional top-level `checklog { ... }` block.
+	CheckLog *CheckLogDecl
+
 	// HeaderPos is the position of the `warden config N` line.
 	HeaderPos Pos
 }
@@ -64,6 +70,7 @@ type ResourceDecl struct {
 	Description   string
 	Relations     []*RelationDef
 	Permissions   []*ResourcePermissionDecl
+	Attributes    []*AttributeDecl
 	Pos           Pos
 }
 
@@ -90,6 +97,17 @@ type ResourcePermissionDecl struct {
 	Pos  Pos
 }
 
+// AttributeDecl is an `attribute <name>: <type> [required] [enum [...]]`
+// line inside a resource block. It declares an attribute of resources of
+// the type, and of subjects whose kind is the type's name.
+type AttributeDecl struct {
+	Name     string
+	Type     string
+	Required bool
+	Enum     []string
+	Pos      Pos
+}
+
 // PermissionDecl is a top-level `permission "<name>" (...)` or `{...}` block.
 type PermissionDecl struct {
 	Name          string // the literal "resource:action" string
@@ -123,6 +141,13 @@ type RoleDecl struct {
 //     policy is effective. Either may be nil ("no bound on that side").
 //   - Obligations: list of named side-effect actions emitted when the
 //     policy matches (e.g. "audit-log", "require-mfa").
+//   - Mode: `mode = shadow` evaluates the policy without enforcing it;
+//     `mode = disabled` skips it. Empty means enforce.
+//   - OnError: `on_error = skip` ignores the policy when its conditions
+//     fail to evaluate; `deny` (the default) makes the check indeterminate.
+//   - StructuredObligations / Advice: the block forms
+//     `obligations { require_mfa { max_age = 300 } }` and `advice { ... }`,
+//     carrying parameters and a fulfill_on decision filter.
 type PolicyDecl struct {
 	Name          string
 	NamespacePath string
@@ -130,6 +155,8 @@ type PolicyDecl struct {
 	Effect        string // "allow" | "deny"
 	Priority      int
 	Active        bool
+	Mode          string // "" | "enforce" | "shadow" | "disabled"
+	OnError       string // "" | "deny" | "skip"
 	NotBefore     *time.Time
 	NotAfter      *time.Time
 	Obligations   []string
@@ -137,18 +164,47 @@ type PolicyDecl struct {
 	Resources     []string
 	Conditions    []*Condition
 	Pos           Pos
+
+	StructuredObligations []*ObligationDecl
+	Advice                []*ObligationDecl
+}
+
+// ObligationDecl is one entry of a policy's `obligations { ... }` or
+// `advice { ... }` block:
+//
+//	require_mfa {
+//	    max_age    = 300
+//	    fulfill_on = allow
+//	}
+//
+// Params values are strings, ints, bools, string lists, or nested
+// map[string]any blocks. FulfillOn is "", "always", "allow" or "deny".
+type ObligationDecl struct {
+	Name      string
+	Params    map[string]any
+	FulfillOn string
+	Pos       Pos
 }
 
 // Condition is a single ABAC predicate or boolean group.
 //
-// Exactly one of {Field+Operator+Value, AllOf, AnyOf} is populated.
+// Exactly one of {Field+Operator+Value, Expression, AllOf, AnyOf} is
+// populated. An atomic predicate whose right-hand side is a field path
+// (`resource.owner_id == subject.id`) sets ValueRef instead of Value.
 type Condition struct {
 	// Atomic predicate.
 	Field    string
 	Operator string
 	Value    any
+	ValueRef string
 	Negate   bool
 
+	// TimeZone is the `in "<zone>"` suffix of a schedule condition.
+	TimeZone string
+
+	// Expression is the source of an `expr "..."` condition.
+	Expression string
+
 	// Boolean groups.
 	AllOf []*Condition
 	AnyOf []*Condition
@@ -156,6 +212,48 @@ type Condition struct {
 	Pos Pos
 }
 
+// isAtom reports whether c is a predicate rather than a boolean group.
+func (c *Condition) isAtom() bool { return c.Field != "" || c.Expression != "" }
+
+// CheckLogDecl is a top-level `checklog { ... }` block configuring which
+// authorization checks are written to the check log. Nested
+// `tenant "<id>" { ... }` blocks become per-tenant overrides; on those,
+// Tenant is set and Tenants is empty.
+type CheckLogDecl struct {
+	Tenant               string
+	AlwaysLogDenies      *bool
+	AllowSampleRate      *int // percent, 0–100
+	IncludeResourceTypes []string
+	ExcludeResourceTypes []string
+	IncludeActions       []string
+	ExcludeActions       []string
+	IncludeSubjects      []string
+	ExcludeSubjects      []string
+	Tenants              []*CheckLogDecl
+	Pos                  Pos
+}
+
+// SoDDecl is a top-level `sod "<name>" { ... }` block declaring roles no
+// subject may combine:
+//
+//	sod "request-approve" {
+//	    kind      = static
+//	    roles     = ["requester", "approver"]
+//	    max_roles = 1
+//	}
+//
+// Kind is "static" (enforced on assignment; the default when empty) or
+// "dynamic" (enforced on the roles a session activates). Roles are slugs
+// and match roles of that slug in every namespace.
+type SoDDecl struct {
+	Name        string
+	Description string
+	Kind        string
+	Roles       []string
+	MaxRoles    int
+	Pos         Pos
+}
+
 // RelationDecl is a top-level `relation <obj_type>:<obj_id> <rel> = <subj_type>:<subj_id>[#<subj_rel>]`.
 type RelationDecl struct {
 	NamespacePath   string
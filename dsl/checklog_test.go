@@ -0,0 +1,106 @@
+package dsl
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const checkLogSrc = `warden config 1
+
+checklog {
+    always_log_denies = true
+    allow_sample_rate = 10
+    exclude_resource_types = ["health"]
+    include_subjects = ["user:*"]
+    tenant "acme" {
+        allow_sample_rate = 100
+    }
+}
+`
+
+func TestParser_CheckLog(t *testing.T) {
+	prog := mustParse(t, checkLogSrc)
+	cl := prog.CheckLog
+	if cl == nil {
+		t.Fatal("expected checklog block")
+	}
+	if cl.AlwaysLogDenies == nil || !*cl.AlwaysLogDenies {
+		t.Error("always_log_denies not parsed")
+	}
+	if cl.AllowSampleRate == nil || *cl.AllowSampleRate != 10 {
+		t.Errorf("allow_sample_rate = %v", cl.AllowSampleRate)
+	}
+	if strings.Join(cl.ExcludeResourceTypes, ",") != "health" {
+		t.Errorf("exclude_resource_types = %v", cl.ExcludeResourceTypes)
+	}
+	if len(cl.Tenants) != 1 || cl.Tenants[0].Tenant != "acme" {
+		t.Fatalf("tenants = %+v", cl.Tenants)
+	}
+	if *cl.Tenants[0].AllowSampleRate != 100 {
+		t.Error("tenant override sample rate not parsed")
+	}
+}
+
+func TestParser_CheckLogErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":  "warden config 1\nchecklog {\n    sample = 10\n}\n",
+		"rate too big": "warden config 1\nchecklog {\n    allow_sample_rate = 150\n}\n",
+		"duplicate":    "warden config 1\nchecklog {\n}\nchecklog {\n}\n",
+		"nested":       "warden config 1\nchecklog {\n    tenant a {\n        tenant b {\n        }\n    }\n}\n",
+		"in namespace": "warden config 1\nnamespace ops {\n    checklog {\n    }\n}\n",
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, errs := Parse("test.warden", []byte(src)); len(errs) == 0 {
+				t.Fatal("expected parse error")
+			}
+		})
+	}
+}
+
+func TestFormat_CheckLogRoundTrip(t *testing.T) {
+	first := Format(mustParse(t, checkLogSrc))
+	second := Format(mustParse(t, first))
+	if first != second {
+		t.Fatalf("format not stable:\n%s\n---\n%s", first, second)
+	}
+	if !strings.Contains(first, "tenant \"acme\" {") {
+		t.Errorf("tenant override missing from output:\n%s", first)
+	}
+}
+
+func TestApply_CheckLog(t *testing.T) {
+	ctx := context.Background()
+	eng, _ := newTestEngine(t)
+	prog := mustParse(t, checkLogSrc)
+
+	res, err := Apply(ctx, eng, prog, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(res.Updated) != 2 {
+		t.Fatalf("expected 2 checklog updates, got %v", res.Updated)
+	}
+
+	rules := eng.CheckLogRules()
+	if rules.AllowSampleRate == nil || *rules.AllowSampleRate != 10 {
+		t.Errorf("base sample rate = %v", rules.AllowSampleRate)
+	}
+	acme := rules.ForTenant("acme")
+	if *acme.AllowSampleRate != 100 {
+		t.Errorf("acme sample rate = %v", *acme.AllowSampleRate)
+	}
+	if len(acme.ExcludeResourceTypes) != 1 {
+		t.Error("acme override should inherit base exclusions")
+	}
+
+	// Re-applying is a no-op.
+	res, err = Apply(ctx, eng, prog, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("re-apply: %v", err)
+	}
+	if len(res.Updated) != 0 {
+		t.Errorf("expected no updates on re-apply, got %v", res.Updated)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/xraph/warden"
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
+	"github.com/xraph/warden/store"
 )
 
 // engineStore is the minimum surface NewEngineEvaluator needs.
@@ -37,6 +38,13 @@ func NewEngineEvaluator(s engineStore) *EngineEvaluator {
 	return ee
 }
 
+// ForStore returns an evaluator that reads resource types and relations
+// from s instead. Engine.Simulate uses it to evaluate expressions against
+// its overlay store.
+func (e *EngineEvaluator) ForStore(s store.Store) warden.ExpressionEvaluator {
+	return NewEngineEvaluator(s)
+}
+
 // resolvePerm implements dsl.PermResolver — looks up the compiled
 // expression for a permission on a resource type, walking the namespace
 // ancestor chain.
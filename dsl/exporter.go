@@ -14,6 +14,7 @@ import (
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
 )
 
 // Layout controls how `warden export` distributes a tenant's state across
@@ -25,7 +26,7 @@ const (
 	FlatLayout Layout = iota
 	// SectionalLayout splits by entity kind: 00-resource-types.warden,
 	// 10-permissions.warden, 20-roles.warden, 30-policies.warden,
-	// 40-relations.warden.
+	// 40-relations.warden, 50-sod.warden.
 	SectionalLayout
 	// DomainLayout groups by namespace path. Each top-level segment becomes
 	// a directory; entities at the tenant root go to a `_root/` directory.
@@ -168,6 +169,18 @@ func BuildProgram(ctx context.Context, eng *warden.Engine, opts ExportOptions) (
 		prog.Relations = append(prog.Relations, tupleToDecl(t))
 	}
 
+	// SoD constraints are tenant-wide and reference roles by slug across
+	// namespaces, so a namespace-scoped export leaves them out.
+	if prefix == "" {
+		constraints, err := store.ListSoDConstraints(ctx, &sod.ListFilter{TenantID: opts.TenantID})
+		if err != nil {
+			return nil, fmt.Errorf("list sod constraints: %w", err)
+		}
+		for _, c := range constraints {
+			prog.SoDConstraints = append(prog.SoDConstraints, sodToDecl(c))
+		}
+	}
+
 	return prog, nil
 }
 
@@ -204,12 +217,15 @@ func writeSectional(dir string, prog *Program) (int, error) {
 		{"40-relations.warden", func(p *Program) *Program {
 			return &Program{Version: p.Version, Tenant: p.Tenant, Relations: p.Relations}
 		}},
+		{"50-sod.warden", func(p *Program) *Program {
+			return &Program{Version: p.Version, Tenant: p.Tenant, SoDConstraints: p.SoDConstraints}
+		}},
 	}
 	count := 0
 	for _, f := range files {
 		section := f.mod(prog)
 		// Skip empty sections.
-		if len(section.ResourceTypes)+len(section.Permissions)+len(section.Roles)+len(section.Policies)+len(section.Relations) == 0 {
+		if len(section.ResourceTypes)+len(section.Permissions)+len(section.Roles)+len(section.Policies)+len(section.Relations)+len(section.SoDConstraints) == 0 {
 			continue
 		}
 		src := Format(section)
@@ -248,6 +264,9 @@ func writeDomain(dir string, prog *Program) (int, error) {
 	for _, t := range prog.Relations {
 		groupFor(t.NamespacePath).Relations = append(groupFor(t.NamespacePath).Relations, t)
 	}
+	for _, c := range prog.SoDConstraints {
+		groupFor("").SoDConstraints = append(groupFor("").SoDConstraints, c)
+	}
 
 	keys := make([]string, 0, len(groups))
 	for k := range groups {
@@ -312,6 +331,14 @@ func resourceTypeToDecl(rt *resourcetype.ResourceType) *ResourceDecl {
 		}
 		d.Permissions = append(d.Permissions, &ResourcePermissionDecl{Name: p.Name, Expr: expr})
 	}
+	for _, attr := range rt.Attributes {
+		d.Attributes = append(d.Attributes, &AttributeDecl{
+			Name:     attr.Name,
+			Type:     string(attr.Type),
+			Required: attr.Required,
+			Enum:     attr.Enum,
+		})
+	}
 	return d
 }
 
@@ -347,22 +374,52 @@ func policyToDecl(p *policy.Policy) *PolicyDecl {
 		Effect:        string(p.Effect),
 		Priority:      p.Priority,
 		Active:        p.IsActive,
+		Mode:          string(p.Mode),
+		OnError:       string(p.OnError),
 		NotBefore:     p.NotBefore,
 		NotAfter:      p.NotAfter,
 		Obligations:   append([]string{}, p.Obligations...),
 		Actions:       append([]string{}, p.Actions...),
 		Resources:     append([]string{}, p.Resources...),
+
+		StructuredObligations: obligationDecls(p.StructuredObligations),
+		Advice:                obligationDecls(p.Advice),
 	}
 	for _, c := range p.Conditions {
 		d.Conditions = append(d.Conditions, &Condition{
-			Field:    c.Field,
-			Operator: string(c.Operator),
-			Value:    c.Value,
+			Field:      c.Field,
+			Operator:   string(c.Operator),
+			Value:      c.Value,
+			ValueRef:   c.ValueRef,
+			Expression: c.Expression,
+			TimeZone:   c.TimeZone,
 		})
 	}
 	return d
 }
 
+func obligationDecls(obs []policy.Obligation) []*ObligationDecl {
+	out := make([]*ObligationDecl, 0, len(obs))
+	for _, ob := range obs {
+		out = append(out, &ObligationDecl{
+			Name:      ob.Name,
+			Params:    ob.Params,
+			FulfillOn: string(ob.FulfillOn),
+		})
+	}
+	return out
+}
+
+func sodToDecl(c *sod.Constraint) *SoDDecl {
+	return &SoDDecl{
+		Name:        c.Name,
+		Description: c.Description,
+		Kind:        string(c.Kind),
+		Roles:       append([]string(nil), c.Roles...),
+		MaxRoles:    c.MaxRoles,
+	}
+}
+
 func tupleToDecl(t *relation.Tuple) *RelationDecl {
 	return &RelationDecl{
 		NamespacePath:   t.NamespacePath,
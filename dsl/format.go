@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/xraph/warden/policy"
 )
 
 // Format renders a Program back to canonical .warden source. The output is
@@ -89,6 +91,8 @@ func (f *formatter) program(prog *Program) {
 		{"roles", func() { f.roles(prog.Roles) }, len(prog.Roles)},
 		{"policies", func() { f.policies(prog.Policies) }, len(prog.Policies)},
 		{"relations", func() { f.relations(prog.Relations) }, len(prog.Relations)},
+		{"sod", func() { f.sodConstraints(prog.SoDConstraints) }, len(prog.SoDConstraints)},
+		{"checklog", func() { f.checkLog(prog.CheckLog) }, checkLogCount(prog.CheckLog)},
 	}
 	first := true
 	for _, sec := range sections {
@@ -129,6 +133,14 @@ func (f *formatter) resourceType(rt *ResourceDecl) {
 	if rt.Description != "" {
 		f.writef("description = %s\n", strconv.Quote(rt.Description))
 	}
+	attrs := append([]*AttributeDecl{}, rt.Attributes...)
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	for _, attr := range attrs {
+		f.attributeDecl(attr)
+	}
+	if len(attrs) > 0 && len(rt.Relations)+len(rt.Permissions) > 0 {
+		f.blank()
+	}
 	relations := append([]*RelationDef{}, rt.Relations...)
 	sort.Slice(relations, func(i, j int) bool { return relations[i].Name < relations[j].Name })
 	for _, rel := range relations {
@@ -146,6 +158,17 @@ func (f *formatter) resourceType(rt *ResourceDecl) {
 	f.writeln("}")
 }
 
+func (f *formatter) attributeDecl(attr *AttributeDecl) {
+	line := fmt.Sprintf("attribute %s: %s", attr.Name, attr.Type)
+	if attr.Required {
+		line += " required"
+	}
+	if len(attr.Enum) > 0 {
+		line += " enum " + formatStringList(attr.Enum)
+	}
+	f.writeln(line)
+}
+
 func (f *formatter) relationDef(rel *RelationDef) {
 	subjects := make([]string, 0, len(rel.AllowedSubjects))
 	for _, s := range rel.AllowedSubjects {
@@ -263,6 +286,12 @@ func (f *formatter) policy(p *PolicyDecl) {
 		f.writef("priority = %d\n", p.Priority)
 	}
 	f.writef("active = %t\n", p.Active)
+	if p.Mode != "" {
+		f.writef("mode = %s\n", p.Mode)
+	}
+	if p.OnError != "" {
+		f.writef("on_error = %s\n", p.OnError)
+	}
 	if p.NotBefore != nil {
 		f.writef("not_before = %s\n", strconv.Quote(p.NotBefore.UTC().Format(time.RFC3339Nano)))
 	}
@@ -272,6 +301,8 @@ func (f *formatter) policy(p *PolicyDecl) {
 	if len(p.Obligations) > 0 {
 		f.writef("obligations = %s\n", formatStringList(p.Obligations))
 	}
+	f.obligationBlock("obligations", p.StructuredObligations)
+	f.obligationBlock("advice", p.Advice)
 	if len(p.Actions) > 0 {
 		f.writef("actions = %s\n", formatStringList(p.Actions))
 	}
@@ -291,6 +322,51 @@ func (f *formatter) policy(p *PolicyDecl) {
 	f.writeln("}")
 }
 
+func (f *formatter) obligationBlock(kind string, obs []*ObligationDecl) {
+	if len(obs) == 0 {
+		return
+	}
+	f.writef("%s {\n", kind)
+	f.indent++
+	for _, ob := range obs {
+		if len(ob.Params) == 0 && ob.FulfillOn == "" {
+			f.writeln(formatWord(ob.Name))
+			continue
+		}
+		f.writef("%s {\n", formatWord(ob.Name))
+		f.indent++
+		f.params(ob.Params)
+		if ob.FulfillOn != "" {
+			f.writef("fulfill_on = %s\n", ob.FulfillOn)
+		}
+		f.indent--
+		f.writeln("}")
+	}
+	f.indent--
+	f.writeln("}")
+}
+
+// params writes obligation parameters in key order, nesting map values as
+// blocks.
+func (f *formatter) params(params map[string]any) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if nested, ok := params[k].(map[string]any); ok {
+			f.writef("%s {\n", formatWord(k))
+			f.indent++
+			f.params(nested)
+			f.indent--
+			f.writeln("}")
+			continue
+		}
+		f.writef("%s = %s\n", formatWord(k), formatLiteral(params[k]))
+	}
+}
+
 func (f *formatter) condition(c *Condition) {
 	if len(c.AllOf) > 0 {
 		f.writeln("all_of {")
@@ -312,13 +388,37 @@ func (f *formatter) condition(c *Condition) {
 		f.writeln("}")
 		return
 	}
+	if c.Expression != "" {
+		f.writef("expr %s\n", strconv.Quote(c.Expression))
+		return
+	}
 	op := canonicalOp(c.Operator)
-	val := formatLiteral(c.Value)
+	val := c.ValueRef
+	if val == "" {
+		val = formatLiteral(c.Value)
+	}
 	suffix := ""
+	if c.TimeZone != "" {
+		suffix = " in " + strconv.Quote(c.TimeZone)
+	}
 	if c.Negate {
-		suffix = " negate"
+		suffix += " negate"
 	}
-	f.writef("%s %s %s%s\n", c.Field, op, val, suffix)
+	f.writef("%s %s %s%s\n", formatField(c.Field), op, val, suffix)
+}
+
+// formatField writes a rate(subject, action, window) field with its
+// arguments quoted as the parser reads them. Other fields are unchanged.
+func formatField(field string) string {
+	ref, ok, err := policy.ParseRate(field)
+	if !ok || err != nil {
+		return field
+	}
+	action := "action"
+	if ref.Action != "" {
+		action = strconv.Quote(ref.Action)
+	}
+	return fmt.Sprintf("rate(subject, %s, %q)", action, policy.FormatWindow(ref.Window))
 }
 
 // canonicalOp returns the source-form keyword spelling for a policy operator.
@@ -342,6 +442,8 @@ func canonicalOp(op string) string {
 		return "not in"
 	case "not_exists":
 		return "not exists"
+	case "not_in_calendar":
+		return "not in_calendar"
 	default:
 		return op
 	}
@@ -371,6 +473,87 @@ func (f *formatter) relations(relations []*RelationDecl) {
 	}
 }
 
+// ─────────────────────────────────────────────────────────────────────────
+// Separation-of-duties constraints.
+// ─────────────────────────────────────────────────────────────────────────
+
+func (f *formatter) sodConstraints(cs []*SoDDecl) {
+	sorted := append([]*SoDDecl{}, cs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for i, c := range sorted {
+		if i > 0 {
+			f.blank()
+		}
+		f.writef("sod %s {\n", strconv.Quote(c.Name))
+		f.indent++
+		if c.Description != "" {
+			f.writef("description = %s\n", strconv.Quote(c.Description))
+		}
+		if c.Kind != "" {
+			f.writef("kind = %s\n", c.Kind)
+		}
+		f.writef("roles = %s\n", formatStringList(c.Roles))
+		if c.MaxRoles != 0 {
+			f.writef("max_roles = %d\n", c.MaxRoles)
+		}
+		f.indent--
+		f.writeln("}")
+	}
+}
+
+// ─────────────────────────────────────────────────────────────────────────
+// Check-log rules.
+// ─────────────────────────────────────────────────────────────────────────
+
+func checkLogCount(d *CheckLogDecl) int {
+	if d == nil {
+		return 0
+	}
+	return 1
+}
+
+func (f *formatter) checkLog(d *CheckLogDecl) {
+	f.writeln("checklog {")
+	f.indent++
+	f.checkLogFields(d)
+	tenants := append([]*CheckLogDecl{}, d.Tenants...)
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Tenant < tenants[j].Tenant })
+	for _, t := range tenants {
+		f.writef("tenant %s {\n", strconv.Quote(t.Tenant))
+		f.indent++
+		f.checkLogFields(t)
+		f.indent--
+		f.writeln("}")
+	}
+	f.indent--
+	f.writeln("}")
+}
+
+func (f *formatter) checkLogFields(d *CheckLogDecl) {
+	if d.AlwaysLogDenies != nil {
+		f.writef("always_log_denies = %t\n", *d.AlwaysLogDenies)
+	}
+	if d.AllowSampleRate != nil {
+		f.writef("allow_sample_rate = %d\n", *d.AllowSampleRate)
+	}
+	lists := []struct {
+		key   string
+		items []string
+	}{
+		{"include_resource_types", d.IncludeResourceTypes},
+		{"exclude_resource_types", d.ExcludeResourceTypes},
+		{"include_actions", d.IncludeActions},
+		{"exclude_actions", d.ExcludeActions},
+		{"include_subjects", d.IncludeSubjects},
+		{"exclude_subjects", d.ExcludeSubjects},
+	}
+	for _, l := range lists {
+		if len(l.items) > 0 {
+			f.writef("%s = %s\n", l.key, formatStringList(l.items))
+		}
+	}
+}
+
 // ─────────────────────────────────────────────────────────────────────────
 // Helpers.
 // ─────────────────────────────────────────────────────────────────────────
@@ -399,6 +582,23 @@ func formatStringList(items []string) string {
 	return b.String()
 }
 
+// formatWord renders an obligation or parameter name bare when it lexes
+// as a plain identifier, and quoted otherwise.
+func formatWord(s string) string {
+	if s == "" || !isIdentStart(s[0]) {
+		return strconv.Quote(s)
+	}
+	for i := 1; i < len(s); i++ {
+		if !isIdentPart(s[i]) {
+			return strconv.Quote(s)
+		}
+	}
+	if _, kw := keywords[s]; kw {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
 // formatLiteral renders a condition value. Falls back to fmt.Sprint for
 // types we don't special-case.
 func formatLiteral(v any) string {
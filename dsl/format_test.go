@@ -45,6 +45,19 @@ resource document {
     relation viewer: user | group#member
     permission read = viewer or owner
 }
+`,
+		},
+		{
+			name: "resource with attributes",
+			src: `warden config 1
+tenant t1
+
+resource document {
+    attribute size: int required
+    attribute tier: string enum ["free", "pro"]
+
+    relation owner: user
+}
 `,
 		},
 		{
@@ -62,6 +75,87 @@ policy "biz-hours" {
         subject.attributes.dept == "engineering"
     }
 }
+`,
+		},
+		{
+			name: "policy with attribute reference",
+			src: `warden config 1
+tenant t1
+
+policy "owner-only" {
+    effect = allow
+    when {
+        resource.owner_id == subject.id
+        subject.dept != "resource.dept"
+    }
+}
+`,
+		},
+		{
+			name: "policy with schedule",
+			src: `warden config 1
+tenant t1
+
+policy "office-hours" {
+    effect = allow
+    when {
+        now day_of_week "mon-fri" in "Europe/Berlin"
+        context.time time_of_day "09:00-17:00" in "Europe/Berlin"
+        now schedule "*/30 * * * *"
+        now not in_calendar "holidays"
+    }
+}
+`,
+		},
+		{
+			name: "policy with collection operators",
+			src: `warden config 1
+tenant t1
+
+policy "shared-groups" {
+    effect = allow
+    when {
+        subject.groups any_in ["eng", "ops"]
+        subject.scopes all_in resource.allowed_scopes
+        resource.tags contains_element "public"
+        subject.groups size_gt 0
+        subject.groups size_lt 10
+    }
+}
+`,
+		},
+		{
+			name: "policy with rate conditions",
+			src: `warden config 1
+tenant t1
+
+policy "export-velocity" {
+    effect = deny
+    actions = ["export"]
+    when {
+        rate(subject, "export", "1h") > 50
+        rate(subject, action, "90s") >= 10
+    }
+    obligations {
+        increment_rate { action = "export" }
+    }
+}
+`,
+		},
+		{
+			name: "policy with expression",
+			src: `warden config 1
+tenant t1
+
+policy "recent" {
+    effect = allow
+    when {
+        expr "now - timestamp(resource.created_at) < duration(\"24h\")"
+        any_of {
+            expr "matches(subject.email, '.*@example\\.com$')"
+        }
+    }
+}
 `,
 		},
 		{
@@ -230,4 +230,14 @@ func mergeInto(prog1, prog2 *Program, path string, errs *[]*Diagnostic) {
 	prog1.Relations = append(prog1.Relations, prog2.Relations...)
 	prog1.Imports = append(prog1.Imports, prog2.Imports...)
 	prog1.Namespaces = append(prog1.Namespaces, prog2.Namespaces...)
+	prog1.SoDConstraints = append(prog1.SoDConstraints, prog2.SoDConstraints...)
+
+	if prog1.CheckLog == nil {
+		prog1.CheckLog = prog2.CheckLog
+	} else if prog2.CheckLog != nil {
+		*errs = append(*errs, &Diagnostic{
+			Pos: prog2.CheckLog.Pos,
+			Msg: fmt.Sprintf("checklog block in %s conflicts with checklog already loaded at %s", path, prog1.CheckLog.Pos),
+		})
+	}
 }
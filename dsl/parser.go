@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/xraph/warden/policy"
 )
 
 // parseRFC3339 parses a timestamp literal as used in PBAC time-bound
@@ -150,6 +152,13 @@ func (p *parser) parseTopLevel(
 	policies *[]*PolicyDecl,
 	relations *[]*RelationDecl,
 ) bool {
+	// `sod` is not reserved so existing identifiers keep working.
+	if p.cur.Kind == IDENT && p.cur.Value == "sod" {
+		if decl := p.parseSoD(); decl != nil {
+			prog.SoDConstraints = append(prog.SoDConstraints, decl)
+		}
+		return true
+	}
 	switch p.cur.Kind {
 	case ILLEGAL:
 		p.errf(p.cur.Pos, "lexer error: %s", p.cur.Value)
@@ -197,6 +206,16 @@ func (p *parser) parseTopLevel(
 			*relations = append(*relations, decl)
 		}
 		return true
+	case CHECKLOG:
+		decl := p.parseCheckLog()
+		if decl != nil {
+			if prog.CheckLog != nil {
+				p.errf(decl.Pos, "checklog block already declared at %s", prog.CheckLog.Pos)
+			} else {
+				prog.CheckLog = decl
+			}
+		}
+		return true
 	case EOF:
 		return false
 	default:
@@ -260,6 +279,11 @@ func (p *parser) parseNamespace() *NamespaceDecl {
 				d.Relations = append(d.Relations, child)
 			}
 		default:
+			if p.cur.Kind == IDENT && p.cur.Value == "sod" {
+				p.errf(p.cur.Pos, "sod constraints apply to the whole tenant; declare them at top level")
+				p.parseSoD()
+				continue
+			}
 			p.errf(p.cur.Pos, "unexpected token %s %q inside namespace", p.cur.Kind, p.cur.Value)
 			p.advance()
 		}
@@ -302,6 +326,15 @@ func (p *parser) parseResource() *ResourceDecl {
 			} else {
 				p.errf(p.cur.Pos, "expected string after description =")
 			}
+		case IDENT:
+			if p.cur.Value != "attribute" {
+				p.errf(p.cur.Pos, "unexpected identifier %q inside resource block", p.cur.Value)
+				p.advance()
+				continue
+			}
+			if attr := p.parseAttributeDecl(); attr != nil {
+				d.Attributes = append(d.Attributes, attr)
+			}
 		default:
 			p.errf(p.cur.Pos, "unexpected token %s %q inside resource block", p.cur.Kind, p.cur.Value)
 			p.advance()
@@ -347,6 +380,42 @@ func (p *parser) parseRelationDef() *RelationDef {
 	return def
 }
 
+// parseAttributeDecl parses `attribute <name>: <type> [required] [enum [...]]`.
+func (p *parser) parseAttributeDecl() *AttributeDecl {
+	pos := p.cur.Pos
+	p.advance() // consume `attribute`
+	name, ok := p.word()
+	if !ok {
+		p.errf(p.cur.Pos, "expected attribute name")
+		return nil
+	}
+	d := &AttributeDecl{Name: name, Pos: pos}
+	p.advance()
+	if !p.accept(COLON) {
+		p.errf(p.cur.Pos, "expected `:` after attribute name")
+		return nil
+	}
+	if p.cur.Kind != IDENT {
+		p.errf(p.cur.Pos, "expected attribute type")
+		return nil
+	}
+	d.Type = p.cur.Value
+	p.advance()
+	for p.cur.Kind == IDENT {
+		switch p.cur.Value {
+		case "required":
+			d.Required = true
+			p.advance()
+		case "enum":
+			p.advance()
+			d.Enum = p.parseStringList()
+		default:
+			return d
+		}
+	}
+	return d
+}
+
 func (p *parser) parseResourcePermission() *ResourcePermissionDecl {
 	pos := p.cur.Pos
 	p.advance() // consume `permission`
@@ -625,6 +694,30 @@ func (p *parser) parsePolicy() *PolicyDecl {
 				d.Active = p.cur.Value == "true"
 				p.advance()
 			}
+		case IDENT:
+			// `mode` and `on_error` are not reserved so existing
+			// identifiers keep working.
+			key := p.cur.Value
+			if key != "mode" && key != "on_error" {
+				p.errf(p.cur.Pos, "unexpected token in policy block: %s %q", p.cur.Kind, p.cur.Value)
+				p.advance()
+				continue
+			}
+			p.advance()
+			if !p.accept(ASSIGN) {
+				p.errf(p.cur.Pos, "expected `=` after %s", key)
+			}
+			switch v := p.cur.Value; {
+			case key == "mode" && (v == "enforce" || v == "shadow" || v == "disabled"):
+				d.Mode = v
+			case key == "mode":
+				p.errf(p.cur.Pos, "mode must be `enforce`, `shadow` or `disabled`, got %q", v)
+			case v == "deny" || v == "skip":
+				d.OnError = v
+			default:
+				p.errf(p.cur.Pos, "on_error must be `deny` or `skip`, got %q", v)
+			}
+			p.advance()
 		case ACTIONS:
 			p.advance()
 			if !p.accept(ASSIGN) {
@@ -678,10 +771,17 @@ func (p *parser) parsePolicy() *PolicyDecl {
 			}
 		case OBLIGATIONS:
 			p.advance()
+			if p.cur.Kind == LBRACE {
+				d.StructuredObligations = append(d.StructuredObligations, p.parseObligationBlock("obligations")...)
+				continue
+			}
 			if !p.accept(ASSIGN) {
-				p.errf(p.cur.Pos, "expected `=` after obligations")
+				p.errf(p.cur.Pos, "expected `=` or `{` after obligations")
 			}
 			d.Obligations = append(d.Obligations, p.parseStringList()...)
+		case ADVICE:
+			p.advance()
+			d.Advice = append(d.Advice, p.parseObligationBlock("advice")...)
 		case WHEN:
 			p.advance()
 			if !p.accept(LBRACE) {
@@ -703,6 +803,273 @@ func (p *parser) parsePolicy() *PolicyDecl {
 	return d
 }
 
+// parseObligationBlock parses the `{ ... }` body of a policy's
+// obligations or advice block. Each entry is a name, optionally followed
+// by a `{ key = value ... }` parameter block:
+//
+//	obligations {
+//	    audit_log
+//	    require_mfa { max_age = 300 }
+//	    redact {
+//	        fields     = ["ssn", "dob"]
+//	        fulfill_on = allow
+//	    }
+//	}
+func (p *parser) parseObligationBlock(kind string) []*ObligationDecl {
+	if !p.accept(LBRACE) {
+		p.errf(p.cur.Pos, "expected `{` to open %s block", kind)
+		return nil
+	}
+	var out []*ObligationDecl
+	for p.cur.Kind != RBRACE && p.cur.Kind != EOF {
+		name, ok := p.word()
+		if !ok {
+			p.errf(p.cur.Pos, "expected %s name, got %s %q", kind, p.cur.Kind, p.cur.Value)
+			p.advance()
+			continue
+		}
+		ob := &ObligationDecl{Name: name, Pos: p.cur.Pos}
+		p.advance()
+		if p.cur.Kind == LBRACE {
+			ob.Params = p.parseParamBlock(ob)
+		}
+		out = append(out, ob)
+	}
+	p.expect(RBRACE)
+	return out
+}
+
+// parseParamBlock parses `{ key = value ... }`. Values may be literals or
+// nested blocks. When ob is non-nil, a top-level `fulfill_on` key sets
+// ob.FulfillOn instead of becoming a parameter.
+func (p *parser) parseParamBlock(ob *ObligationDecl) map[string]any {
+	p.advance() // consume `{`
+	params := make(map[string]any)
+	for p.cur.Kind != RBRACE && p.cur.Kind != EOF {
+		key, ok := p.word()
+		if !ok {
+			p.errf(p.cur.Pos, "expected parameter name, got %s %q", p.cur.Kind, p.cur.Value)
+			p.advance()
+			continue
+		}
+		keyPos := p.cur.Pos
+		p.advance()
+		if ob != nil && key == "fulfill_on" {
+			if !p.accept(ASSIGN) {
+				p.errf(p.cur.Pos, "expected `=` after fulfill_on")
+			}
+			switch v := p.cur.Value; v {
+			case "allow", "deny", "always":
+				ob.FulfillOn = v
+			default:
+				p.errf(p.cur.Pos, "fulfill_on must be `allow`, `deny` or `always`, got %q", v)
+			}
+			p.advance()
+			continue
+		}
+		if _, dup := params[key]; dup {
+			p.errf(keyPos, "duplicate parameter %q", key)
+		}
+		if p.cur.Kind == LBRACE {
+			params[key] = p.parseParamBlock(nil)
+			continue
+		}
+		if !p.accept(ASSIGN) {
+			p.errf(p.cur.Pos, "expected `=` or `{` after parameter %q", key)
+			continue
+		}
+		if v, ok := p.parseLiteralValue(); ok {
+			params[key] = v
+		} else {
+			p.advance()
+		}
+	}
+	p.expect(RBRACE)
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// word returns the current token as a bare name: a string literal, an
+// identifier, or a keyword spelled as one (so `name` or `deny` work as
+// parameter names). It does not advance.
+func (p *parser) word() (string, bool) {
+	switch {
+	case p.cur.Kind == STRING, p.cur.Kind == IDENT:
+		return p.cur.Value, true
+	case p.cur.Kind != BOOL && p.cur.Value != "" && isIdentStart(p.cur.Value[0]):
+		return p.cur.Value, true
+	}
+	return "", false
+}
+
+// parseCheckLog parses a `checklog { ... }` block:
+//
+//	checklog {
+//	    always_log_denies = true
+//	    allow_sample_rate = 10
+//	    exclude_actions   = ["list"]
+//	    tenant "acme" {
+//	        allow_sample_rate = 100
+//	    }
+//	}
+func (p *parser) parseCheckLog() *CheckLogDecl {
+	d := &CheckLogDecl{Pos: p.cur.Pos}
+	p.advance() // consume `checklog`
+	p.parseCheckLogBody(d, true)
+	return d
+}
+
+// parseCheckLogBody parses the `{ ... }` body of a checklog block or of a
+// nested tenant override. Tenant overrides may not nest further.
+func (p *parser) parseCheckLogBody(d *CheckLogDecl, allowTenants bool) {
+	if !p.accept(LBRACE) {
+		p.errf(p.cur.Pos, "expected `{` to open checklog block")
+		return
+	}
+	for p.cur.Kind != RBRACE && p.cur.Kind != EOF {
+		if p.cur.Kind == TENANT {
+			pos := p.cur.Pos
+			p.advance()
+			if p.cur.Kind != STRING && p.cur.Kind != IDENT {
+				p.errf(p.cur.Pos, "expected tenant identifier after `tenant`")
+				p.advance()
+				continue
+			}
+			override := &CheckLogDecl{Tenant: p.cur.Value, Pos: pos}
+			p.advance()
+			if !allowTenants {
+				p.errf(pos, "tenant overrides cannot be nested")
+			}
+			p.parseCheckLogBody(override, false)
+			if allowTenants {
+				d.Tenants = append(d.Tenants, override)
+			}
+			continue
+		}
+		if p.cur.Kind != IDENT {
+			p.errf(p.cur.Pos, "unexpected token in checklog block: %s %q", p.cur.Kind, p.cur.Value)
+			p.advance()
+			continue
+		}
+		key := p.advance()
+		if !p.accept(ASSIGN) {
+			p.errf(p.cur.Pos, "expected `=` after %s", key.Value)
+		}
+		switch key.Value {
+		case "always_log_denies":
+			if p.cur.Kind != BOOL {
+				p.errf(p.cur.Pos, "always_log_denies must be true or false")
+				p.advance()
+				continue
+			}
+			v := p.cur.Value == "true"
+			d.AlwaysLogDenies = &v
+			p.advance()
+		case "allow_sample_rate":
+			if p.cur.Kind != INT {
+				p.errf(p.cur.Pos, "allow_sample_rate must be an integer percentage")
+				p.advance()
+				continue
+			}
+			v, err := strconv.Atoi(p.cur.Value)
+			if err != nil || v > 100 {
+				p.errf(p.cur.Pos, "allow_sample_rate must be between 0 and 100, got %s", p.cur.Value)
+			}
+			d.AllowSampleRate = &v
+			p.advance()
+		case "include_resource_types":
+			d.IncludeResourceTypes = append(d.IncludeResourceTypes, p.parseStringList()...)
+		case "exclude_resource_types":
+			d.ExcludeResourceTypes = append(d.ExcludeResourceTypes, p.parseStringList()...)
+		case "include_actions":
+			d.IncludeActions = append(d.IncludeActions, p.parseStringList()...)
+		case "exclude_actions":
+			d.ExcludeActions = append(d.ExcludeActions, p.parseStringList()...)
+		case "include_subjects":
+			d.IncludeSubjects = append(d.IncludeSubjects, p.parseStringList()...)
+		case "exclude_subjects":
+			d.ExcludeSubjects = append(d.ExcludeSubjects, p.parseStringList()...)
+		default:
+			p.errf(key.Pos, "unknown checklog setting %q", key.Value)
+			p.advance()
+		}
+	}
+	p.expect(RBRACE)
+}
+
+// parseSoD parses a `sod "<name>" { ... }` block:
+//
+//	sod "request-approve" {
+//	    description = "Requesters cannot approve"
+//	    kind        = static
+//	    roles       = ["requester", "approver"]
+//	    max_roles   = 1
+//	}
+func (p *parser) parseSoD() *SoDDecl {
+	pos := p.cur.Pos
+	p.advance() // consume `sod`
+	if p.cur.Kind != STRING {
+		p.errf(p.cur.Pos, "expected sod constraint name as string literal")
+		return nil
+	}
+	d := &SoDDecl{Name: p.cur.Value, Pos: pos}
+	p.advance()
+	if !p.accept(LBRACE) {
+		p.errf(p.cur.Pos, "expected `{` to open sod block")
+		return d
+	}
+	for p.cur.Kind != RBRACE && p.cur.Kind != EOF {
+		if p.cur.Kind == DESCRIPTION {
+			p.advance()
+			if !p.accept(ASSIGN) {
+				p.errf(p.cur.Pos, "expected `=` after description")
+			}
+			if p.cur.Kind == STRING {
+				d.Description = p.cur.Value
+				p.advance()
+			}
+			continue
+		}
+		if p.cur.Kind != IDENT {
+			p.errf(p.cur.Pos, "unexpected token in sod block: %s %q", p.cur.Kind, p.cur.Value)
+			p.advance()
+			continue
+		}
+		key := p.advance()
+		if !p.accept(ASSIGN) {
+			p.errf(p.cur.Pos, "expected `=` after %s", key.Value)
+		}
+		switch key.Value {
+		case "kind":
+			if p.cur.Kind != IDENT || (p.cur.Value != "static" && p.cur.Value != "dynamic") {
+				p.errf(p.cur.Pos, "kind must be static or dynamic, got %q", p.cur.Value)
+			} else {
+				d.Kind = p.cur.Value
+			}
+			p.advance()
+		case "roles":
+			d.Roles = append(d.Roles, p.parseStringList()...)
+		case "max_roles":
+			if p.cur.Kind != INT {
+				p.errf(p.cur.Pos, "max_roles must be an integer")
+				p.advance()
+				continue
+			}
+			if v, err := strconv.Atoi(p.cur.Value); err == nil {
+				d.MaxRoles = v
+			}
+			p.advance()
+		default:
+			p.errf(key.Pos, "unknown sod setting %q", key.Value)
+			p.advance()
+		}
+	}
+	p.expect(RBRACE)
+	return d
+}
+
 // parseCondition parses one ABAC predicate: either an atomic
 // `<field> <op> <value> [negate]` form or a `all_of { ... }` / `any_of { ... }` group.
 func (p *parser) parseCondition() *Condition {
@@ -736,6 +1103,15 @@ func (p *parser) parseCondition() *Condition {
 		}
 		p.expect(RBRACE)
 		return c
+	case EXPR:
+		p.advance()
+		if p.cur.Kind != STRING {
+			p.errf(p.cur.Pos, "expected expression string after expr, got %s %q", p.cur.Kind, p.cur.Value)
+			return nil
+		}
+		c := &Condition{Expression: p.cur.Value, Pos: pos}
+		p.advance()
+		return c
 	}
 
 	// Atomic predicate: field-path operator value [negate].
@@ -750,12 +1126,28 @@ func (p *parser) parseCondition() *Condition {
 		return nil
 	}
 
-	value, ok := p.parseLiteralValue()
-	if !ok {
-		return nil
+	c := &Condition{Field: field, Operator: op, Pos: pos}
+	if isFieldPathStart(p.cur.Kind) {
+		// Attribute reference: `resource.owner_id == subject.id`.
+		if c.ValueRef = p.parseFieldPath(); c.ValueRef == "" {
+			return nil
+		}
+	} else {
+		value, ok := p.parseLiteralValue()
+		if !ok {
+			return nil
+		}
+		c.Value = value
+	}
+	if policy.Operator(op).IsSchedule() && p.accept(IN) {
+		// Time zone suffix: `context.time time_of_day "09:00-17:00" in "Europe/Berlin"`.
+		if p.cur.Kind != STRING {
+			p.errf(p.cur.Pos, "expected time zone string after `in`, got %s %q", p.cur.Kind, p.cur.Value)
+			return nil
+		}
+		c.TimeZone = p.cur.Value
+		p.advance()
 	}
-
-	c := &Condition{Field: field, Operator: op, Value: value, Pos: pos}
 	if p.accept(NEGATE) {
 		c.Negate = true
 	}
@@ -763,13 +1155,21 @@ func (p *parser) parseCondition() *Condition {
 }
 
 func (p *parser) parseFieldPath() string {
-	if p.cur.Kind != IDENT && p.cur.Kind != SUBJECTS && p.cur.Kind != ACTIONS && p.cur.Kind != RESOURCES {
+	if !isFieldPathStart(p.cur.Kind) {
 		p.errf(p.cur.Pos, "expected field path identifier, got %s %q", p.cur.Kind, p.cur.Value)
 		return ""
 	}
+	first := p.advance()
+	if first.Kind == IDENT && first.Value == "rate" && p.cur.Kind == LPAREN {
+		return p.parseRateArgs()
+	}
+	return first.Value + p.parseFieldPathRest()
+}
+
+// parseFieldPathRest reads the `.segment` and `.["key"]` parts of a field
+// path after its first identifier.
+func (p *parser) parseFieldPathRest() string {
 	var sb strings.Builder
-	sb.WriteString(p.cur.Value)
-	p.advance()
 	for p.accept(DOT) {
 		switch p.cur.Kind {
 		case IDENT:
@@ -794,8 +1194,58 @@ func (p *parser) parseFieldPath() string {
 	return sb.String()
 }
 
+// parseRateArgs reads the arguments of `rate(subject, "export", "1h")`
+// after the name and returns the field in canonical form. The action is a
+// string, or the bare word action for the action being checked.
+func (p *parser) parseRateArgs() string {
+	p.advance() // consume `(`
+	if p.cur.Kind != IDENT || p.cur.Value != "subject" {
+		p.errf(p.cur.Pos, "expected subject as the first argument of rate")
+		return ""
+	}
+	p.advance()
+	p.expect(COMMA)
+	action := ""
+	switch {
+	case p.cur.Kind == STRING:
+		action = p.cur.Value
+	case p.cur.Kind == IDENT && p.cur.Value == "action":
+	default:
+		p.errf(p.cur.Pos, "expected an action string or action in rate, got %s %q", p.cur.Kind, p.cur.Value)
+		return ""
+	}
+	p.advance()
+	p.expect(COMMA)
+	if p.cur.Kind != STRING {
+		p.errf(p.cur.Pos, "expected a window string such as \"1h\" in rate, got %s %q", p.cur.Kind, p.cur.Value)
+		return ""
+	}
+	window := p.cur.Value
+	p.advance()
+	p.expect(RPAREN)
+	field := fmt.Sprintf("rate(subject, action, %s)", strconv.Quote(window))
+	if action != "" {
+		field = fmt.Sprintf("rate(subject, %s, %s)", strconv.Quote(action), strconv.Quote(window))
+	}
+	// A malformed window is kept as written for the resolver to report.
+	if ref, ok, err := policy.ParseRate(field); ok && err == nil {
+		return ref.String()
+	}
+	return field
+}
+
 // parseOperator reads one of the keyword/operator-spelled comparison ops.
 // Returns the canonical policy.Operator string.
+// isFieldPathStart reports whether a token of kind k can begin a field
+// path. `resource` is a keyword but also the root of resource attributes.
+func isFieldPathStart(k TokenKind) bool {
+	switch k {
+	case IDENT, SUBJECTS, ACTIONS, RESOURCES, RESOURCE:
+		return true
+	}
+	return false
+}
+
 func (p *parser) parseOperator() (string, bool) {
 	pos := p.cur.Pos
 	switch p.cur.Kind {
@@ -818,8 +1268,13 @@ func (p *parser) parseOperator() (string, bool) {
 		case EXISTS:
 			p.advance()
 			return "not_exists", true
+		case IDENT:
+			if p.cur.Value == "in_calendar" {
+				p.advance()
+				return "not_in_calendar", true
+			}
 		}
-		p.errf(p.cur.Pos, "expected `in` or `exists` after `not`")
+		p.errf(p.cur.Pos, "expected `in`, `exists` or `in_calendar` after `not`")
 		return "", false
 	case CONTAINS:
 		p.advance()
@@ -857,6 +1312,16 @@ func (p *parser) parseOperator() (string, bool) {
 	case REGEX:
 		p.advance()
 		return "regex", true
+	case IDENT:
+		// Schedule and collection operators are contextual so they stay
+		// usable as attribute names.
+		switch p.cur.Value {
+		case "day_of_week", "time_of_day", "schedule", "in_calendar",
+			"any_in", "all_in", "contains_element", "size_gt", "size_lt":
+			op := p.cur.Value
+			p.advance()
+			return op, true
+		}
 	}
 	p.errf(pos, "expected condition operator, got %s %q", p.cur.Kind, p.cur.Value)
 	return "", false
@@ -205,6 +205,176 @@ policy "business-hours" {
 	}
 }
 
+func TestParser_ConditionAttributeReference(t *testing.T) {
+	src := `
+warden config 1
+
+policy "owner-only" {
+    effect = allow
+    when {
+        resource.owner_id == subject.id
+        subject.department == "subject.department"
+    }
+}
+`
+	prog := mustParse(t, src)
+	conds := prog.Policies[0].Conditions
+	if len(conds) != 2 {
+		t.Fatalf("expected 2 conditions, got %d", len(conds))
+	}
+	if conds[0].ValueRef != "subject.id" || conds[0].Value != nil {
+		t.Errorf("condition[0] = %+v, want reference to subject.id", conds[0])
+	}
+	if conds[1].ValueRef != "" || conds[1].Value != "subject.department" {
+		t.Errorf("condition[1] = %+v, want string literal", conds[1])
+	}
+}
+
+func TestParser_ConditionExpression(t *testing.T) {
+	src := `
+warden config 1
+
+policy "gold-tier" {
+    effect = allow
+    when {
+        expr "context.request.headers[\"x-tier\"] in ['gold']"
+        subject.level > 2
+    }
+}
+`
+	prog := mustParse(t, src)
+	conds := prog.Policies[0].Conditions
+	if len(conds) != 2 {
+		t.Fatalf("expected 2 conditions, got %d", len(conds))
+	}
+	if want := `context.request.headers["x-tier"] in ['gold']`; conds[0].Expression != want {
+		t.Errorf("expression = %q, want %q", conds[0].Expression, want)
+	}
+	if conds[1].Field != "subject.level" || conds[1].Expression != "" {
+		t.Errorf("condition[1] = %+v", conds[1])
+	}
+}
+
+func TestParser_ScheduleConditions(t *testing.T) {
+	src := `
+warden config 1
+
+policy "office-hours" {
+    effect = allow
+    when {
+        context.time day_of_week ["mon-fri", "sat"] in "Europe/Berlin"
+        now time_of_day "09:00-17:00"
+        now not in_calendar "holidays"
+        context.schedule == "on-call"
+    }
+}
+`
+	prog := mustParse(t, src)
+	conds := prog.Policies[0].Conditions
+	if len(conds) != 4 {
+		t.Fatalf("expected 4 conditions, got %d", len(conds))
+	}
+	if conds[0].Operator != "day_of_week" || conds[0].TimeZone != "Europe/Berlin" {
+		t.Errorf("condition[0] = %+v", conds[0])
+	}
+	if conds[1].Field != "now" || conds[1].TimeZone != "" {
+		t.Errorf("condition[1] = %+v", conds[1])
+	}
+	if conds[2].Operator != "not_in_calendar" || conds[2].Value != "holidays" {
+		t.Errorf("condition[2] = %+v", conds[2])
+	}
+	// Operator names stay usable as attribute names.
+	if conds[3].Field != "context.schedule" || conds[3].Operator != "eq" {
+		t.Errorf("condition[3] = %+v", conds[3])
+	}
+}
+
+func TestParser_CollectionConditions(t *testing.T) {
+	src := `
+warden config 1
+
+policy "shared-groups" {
+    effect = allow
+    when {
+        subject.groups any_in ["eng", "ops"]
+        subject.scopes all_in resource.allowed_scopes
+        resource.tags contains_element "public"
+        subject.groups size_lt 10
+        context.any_in == "x"
+    }
+}
+`
+	prog := mustParse(t, src)
+	conds := prog.Policies[0].Conditions
+	if len(conds) != 5 {
+		t.Fatalf("expected 5 conditions, got %d", len(conds))
+	}
+	for i, op := range []string{"any_in", "all_in", "contains_element", "size_lt", "eq"} {
+		if conds[i].Operator != op {
+			t.Errorf("condition[%d].Operator = %q, want %q", i, conds[i].Operator, op)
+		}
+	}
+	if conds[1].ValueRef != "resource.allowed_scopes" {
+		t.Errorf("condition[1] = %+v", conds[1])
+	}
+	if conds[3].Value != 10 {
+		t.Errorf("condition[3].Value = %#v", conds[3].Value)
+	}
+}
+
+func TestParser_RateConditions(t *testing.T) {
+	src := `
+warden config 1
+
+policy "export-velocity" {
+    effect = deny
+    actions = ["export"]
+    when {
+        rate(subject, "export", "1h") > 50
+        rate(subject, action, "90s") >= 10
+        context.rate == "high"
+    }
+}
+`
+	prog := mustParse(t, src)
+	conds := prog.Policies[0].Conditions
+	if len(conds) != 3 {
+		t.Fatalf("expected 3 conditions, got %d", len(conds))
+	}
+	for i, want := range []string{"rate(subject, export, 1h)", "rate(subject, action, 90s)", "context.rate"} {
+		if conds[i].Field != want {
+			t.Errorf("condition[%d].Field = %q, want %q", i, conds[i].Field, want)
+		}
+	}
+}
+
+func TestParser_ResourceAttributes(t *testing.T) {
+	src := `
+warden config 1
+
+resource document {
+    attribute size: int required
+    attribute tier: string enum ["free", "pro"]
+    attribute labels: list
+    relation owner: user
+}
+`
+	prog := mustParse(t, src)
+	attrs := prog.ResourceTypes[0].Attributes
+	if len(attrs) != 3 {
+		t.Fatalf("expected 3 attributes, got %d", len(attrs))
+	}
+	if a := attrs[0]; a.Name != "size" || a.Type != "int" || !a.Required {
+		t.Errorf("attribute[0] = %+v", a)
+	}
+	if a := attrs[1]; a.Name != "tier" || a.Required || len(a.Enum) != 2 || a.Enum[1] != "pro" {
+		t.Errorf("attribute[1] = %+v", a)
+	}
+	if len(prog.ResourceTypes[0].Relations) != 1 {
+		t.Error("expected the relation after the attributes to parse")
+	}
+}
+
 func TestParser_NestedNamespaces(t *testing.T) {
 	src := `
 warden config 1
@@ -152,3 +152,190 @@ policy "scheduled-grant" {
 		t.Errorf("formatter is not idempotent\nfirst:\n%s\nsecond:\n%s", out, out2)
 	}
 }
+
+// TestParser_StructuredObligations asserts the block forms of obligations
+// and advice parse names, typed parameters, nested blocks and fulfill_on.
+func TestParser_StructuredObligations(t *testing.T) {
+	src := `
+warden config 1
+
+policy "sensitive-read" {
+    effect  = allow
+    actions = ["read"]
+    obligations {
+        audit_log
+        require_mfa { max_age = 300 }
+        redact {
+            fields     = ["ssn", "dob"]
+            fulfill_on = allow
+        }
+        "notify-security" {
+            channel { name = "#sec" }
+            fulfill_on = deny
+        }
+    }
+    advice {
+        show_banner { text = "Access by ${subject.id} is logged" }
+    }
+}
+`
+	prog := mustParse(t, src)
+	d := prog.Policies[0]
+	if len(d.StructuredObligations) != 4 {
+		t.Fatalf("got %d obligations, want 4", len(d.StructuredObligations))
+	}
+	audit, mfa, redact, notify := d.StructuredObligations[0], d.StructuredObligations[1], d.StructuredObligations[2], d.StructuredObligations[3]
+	if audit.Name != "audit_log" || audit.Params != nil || audit.FulfillOn != "" {
+		t.Errorf("audit_log = %+v", audit)
+	}
+	if mfa.Params["max_age"] != 300 {
+		t.Errorf("require_mfa params = %v", mfa.Params)
+	}
+	if got, _ := redact.Params["fields"].([]string); strings.Join(got, ",") != "ssn,dob" || redact.FulfillOn != "allow" {
+		t.Errorf("redact = %+v", redact)
+	}
+	if _, ok := redact.Params["fulfill_on"]; ok {
+		t.Error("fulfill_on leaked into params")
+	}
+	channel, _ := notify.Params["channel"].(map[string]any)
+	if notify.Name != "notify-security" || channel["name"] != "#sec" || notify.FulfillOn != "deny" {
+		t.Errorf("notify-security = %+v", notify)
+	}
+	if len(d.Advice) != 1 || d.Advice[0].Params["text"] != "Access by ${subject.id} is logged" {
+		t.Errorf("advice = %+v", d.Advice)
+	}
+}
+
+func TestParser_StructuredObligations_InvalidFulfillOn(t *testing.T) {
+	src := `
+warden config 1
+
+policy "p" {
+    effect = allow
+    obligations {
+        notify { fulfill_on = sometimes }
+    }
+}
+`
+	_, diags := Parse("test.warden", []byte(src))
+	if len(diags) == 0 {
+		t.Fatal("expected a diagnostic for an unknown fulfill_on")
+	}
+	if !strings.Contains(diags[0].Msg, "fulfill_on") {
+		t.Errorf("diagnostic = %v", diags[0])
+	}
+}
+
+// TestFormat_StructuredObligations asserts the formatter writes the block
+// forms with sorted parameters and re-parses to the same output.
+func TestFormat_StructuredObligations(t *testing.T) {
+	src := `warden config 1
+
+policy "sensitive-read" {
+    effect = allow
+    obligations {
+        require_mfa { max_age = 300  method = "totp" }
+        "audit-log"
+        redact { fields = ["ssn"]  fulfill_on = allow }
+    }
+    advice { show_banner }
+}
+`
+	out := Format(mustParse(t, src))
+	for _, want := range []string{
+		"obligations {\n",
+		"        require_mfa {\n            max_age = 300\n            method = \"totp\"\n        }\n",
+		"        audit-log\n",
+		"            fields = [\"ssn\"]\n            fulfill_on = allow\n",
+		"    advice {\n        show_banner\n    }\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatted output missing %q\n----\n%s", want, out)
+		}
+	}
+
+	prog2, diags := Parse("fmt.warden", []byte(out))
+	if len(diags) > 0 {
+		t.Fatalf("re-parse diags: %v", diags)
+	}
+	if out2 := Format(prog2); out != out2 {
+		t.Errorf("formatter is not idempotent\nfirst:\n%s\nsecond:\n%s", out, out2)
+	}
+}
+
+// TestParser_PolicyMode asserts `mode` parses, formats and round-trips,
+// and that unknown modes produce a diagnostic.
+func TestParser_PolicyMode(t *testing.T) {
+	src := `warden config 1
+
+policy "deny-contractors" {
+    effect = deny
+    mode   = shadow
+}
+`
+	prog := mustParse(t, src)
+	if got := prog.Policies[0].Mode; got != "shadow" {
+		t.Fatalf("Mode = %q, want shadow", got)
+	}
+	out := Format(prog)
+	if !strings.Contains(out, "    mode = shadow\n") {
+		t.Errorf("formatted output missing mode\n----\n%s", out)
+	}
+	if out2 := Format(mustParse(t, out)); out != out2 {
+		t.Errorf("formatter is not idempotent\nfirst:\n%s\nsecond:\n%s", out, out2)
+	}
+
+	_, diags := Parse("test.warden", []byte(`warden config 1
+policy "p" { mode = dry_run }
+`))
+	if len(diags) == 0 || !strings.Contains(diags[0].Msg, "mode") {
+		t.Errorf("diags = %v, want a diagnostic about mode", diags)
+	}
+}
+
+// TestParser_PolicyOnError asserts `on_error` parses and round-trips, and
+// that conditions which could never evaluate are rejected at lint time.
+func TestParser_PolicyOnError(t *testing.T) {
+	src := `warden config 1
+
+policy "office-network" {
+    effect   = deny
+    on_error = skip
+}
+`
+	prog := mustParse(t, src)
+	if got := prog.Policies[0].OnError; got != "skip" {
+		t.Fatalf("OnError = %q, want skip", got)
+	}
+	out := Format(prog)
+	if !strings.Contains(out, "    on_error = skip\n") {
+		t.Errorf("formatted output missing on_error\n----\n%s", out)
+	}
+
+	_, diags := Parse("test.warden", []byte(`warden config 1
+policy "p" { on_error = ignore }
+`))
+	if len(diags) == 0 || !strings.Contains(diags[0].Msg, "on_error") {
+		t.Errorf("diags = %v, want a diagnostic about on_error", diags)
+	}
+
+	errs := resolveSrc(t, `warden config 1
+tenant t1
+
+policy "bad" {
+    effect = deny
+    when {
+        subject.email =~ "(["
+        context.ip ip_in_cidr ["10.0.0.0/8", "10.0.0.300/24"]
+        now day_of_week "mon-fry"
+        now schedule "0 25 * * *"
+        now time_of_day "09:00-17:00" in "Mars/Olympus"
+    }
+}
+`)
+	wantDiagContaining(t, errs, "invalid regex")
+	wantDiagContaining(t, errs, `invalid CIDR "10.0.0.300/24"`)
+	wantDiagContaining(t, errs, `unknown day "fry"`)
+	wantDiagContaining(t, errs, "hour")
+	wantDiagContaining(t, errs, "Mars/Olympus")
+}
@@ -0,0 +1,96 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xraph/warden"
+)
+
+// PrivilegeDiff renders the role changes suggested by a privilege report as
+// a unified diff of canonical .warden source: every unused permission is
+// dropped from its role's grants. Assignments are not declared in .warden
+// and are left to the report itself. Returns "" when nothing would change.
+func PrivilegeDiff(ctx context.Context, eng *warden.Engine, rep *warden.PrivilegeReport) (string, error) {
+	prog, err := BuildProgram(ctx, eng, ExportOptions{TenantID: rep.TenantID})
+	if err != nil {
+		return "", fmt.Errorf("warden report: %w", err)
+	}
+
+	drop := make(map[string][]string) // "ns\x00slug" → permission names
+	for _, u := range rep.UnusedPermissions {
+		key := u.NamespacePath + "\x00" + u.RoleSlug
+		drop[key] = append(drop[key], u.Permission)
+	}
+
+	var b strings.Builder
+	for _, r := range sortedRoles(prog.Roles) {
+		unused := drop[r.NamespacePath+"\x00"+r.Slug]
+		if len(unused) == 0 {
+			continue
+		}
+		after := *r
+		after.Grants = slices.DeleteFunc(slices.Clone(r.Grants), func(g string) bool {
+			return slices.Contains(unused, g)
+		})
+		if len(after.Grants) == len(r.Grants) {
+			continue
+		}
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- tenant %s (current)\n+++ tenant %s (least privilege)\n", rep.TenantID, rep.TenantID)
+		}
+		header := "role " + r.Slug
+		if r.NamespacePath != "" {
+			header += " (namespace " + r.NamespacePath + ")"
+		}
+		fmt.Fprintf(&b, "@@ %s @@\n", header)
+		writeLineDiff(&b, renderRole(r), renderRole(&after))
+	}
+	return b.String(), nil
+}
+
+func sortedRoles(roles []*RoleDecl) []*RoleDecl {
+	sorted := slices.Clone(roles)
+	slices.SortFunc(sorted, func(a, b *RoleDecl) int {
+		if c := strings.Compare(a.NamespacePath, b.NamespacePath); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Slug, b.Slug)
+	})
+	return sorted
+}
+
+func renderRole(r *RoleDecl) []string {
+	f := &formatter{}
+	f.role(r)
+	return strings.Split(strings.TrimSuffix(f.buf.String(), "\n"), "\n")
+}
+
+// writeLineDiff writes a single-hunk diff of before→after. Role blocks only
+// ever differ in one contiguous region (the grants line), so the common
+// prefix and suffix are context and the middle is the change.
+func writeLineDiff(b *strings.Builder, before, after []string) {
+	pre := 0
+	for pre < len(before) && pre < len(after) && before[pre] == after[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(before)-pre && suf < len(after)-pre &&
+		before[len(before)-1-suf] == after[len(after)-1-suf] {
+		suf++
+	}
+	for _, l := range before[:pre] {
+		b.WriteString(" " + l + "\n")
+	}
+	for _, l := range before[pre : len(before)-suf] {
+		b.WriteString("-" + l + "\n")
+	}
+	for _, l := range after[pre : len(after)-suf] {
+		b.WriteString("+" + l + "\n")
+	}
+	for _, l := range before[len(before)-suf:] {
+		b.WriteString(" " + l + "\n")
+	}
+}
@@ -0,0 +1,69 @@
+package dsl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/store/memory"
+)
+
+func TestPrivilegeDiff(t *testing.T) {
+	src := `warden config 1
+tenant t1
+
+permission "doc:read"   (doc : read)
+permission "doc:write"  (doc : write)
+permission "doc:delete" (doc : delete)
+
+role editor {
+    name = "Editor"
+    grants = ["doc:delete", "doc:read", "doc:write"]
+}
+
+role viewer {
+    grants = ["doc:read"]
+}
+`
+	prog, errs := Parse("test", []byte(src))
+	if len(errs) > 0 {
+		t.Fatalf("parse: %v", errs)
+	}
+	ctx := context.Background()
+	eng, err := warden.NewEngine(warden.WithStore(memory.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Apply(ctx, eng, prog, ApplyOptions{}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	rep := &warden.PrivilegeReport{
+		TenantID: "t1",
+		UnusedPermissions: []warden.UnusedPermission{
+			{RoleSlug: "editor", Permission: "doc:delete"},
+			{RoleSlug: "editor", Permission: "doc:write"},
+		},
+	}
+	got, err := PrivilegeDiff(ctx, eng, rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- tenant t1 (current)
++++ tenant t1 (least privilege)
+@@ role editor @@
+ role editor {
+     name = "Editor"
+-    grants = ["doc:delete", "doc:read", "doc:write"]
++    grants = ["doc:read"]
+ }
+`
+	if got != want {
+		t.Fatalf("diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = PrivilegeDiff(ctx, eng, &warden.PrivilegeReport{TenantID: "t1"})
+	if err != nil || got != "" {
+		t.Fatalf("empty report diff = %q, %v", got, err)
+	}
+}
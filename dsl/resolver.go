@@ -4,6 +4,9 @@ import (
 	"strings"
 
 	"github.com/xraph/warden"
+	"github.com/xraph/warden/expr"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/sod"
 )
 
 // Resolve performs name resolution and type checking against a parsed
@@ -17,6 +20,10 @@ import (
 //   - resource-type permission expressions reference declared relations
 //   - traversal expressions hop through declared relation targets
 //   - condition operators are valid
+//   - attribute declarations have known types and unique names
+//   - condition regexes and CIDRs compile
+//   - sod constraints are well formed and name declared roles
+//   - `expr` and atomic conditions type-check against declared attributes
 //   - identifier conventions (slug regex, name regex, namespace path)
 func Resolve(prog *Program) []*Diagnostic {
 	r := &resolver{
@@ -31,6 +38,9 @@ func Resolve(prog *Program) []*Diagnostic {
 	r.checkRoleParents()
 	r.checkCycles()
 	r.checkExpressions()
+	r.checkAttributeDecls()
+	r.checkConditionExpressions()
+	r.checkSoD()
 	return r.errs
 }
 
@@ -235,6 +245,79 @@ func (r *resolver) checkExpressions() {
 	}
 }
 
+// checkConditionExpressions compiles every `expr` condition, validates
+// atomic conditions (regexes, CIDRs) and checks them against the declared
+// attribute schemas, so syntax and type errors surface at lint and apply
+// time rather than on the first check.
+func (r *resolver) checkConditionExpressions() {
+	var walk func(pol *PolicyDecl, conds []*Condition, schema attributeSchema)
+	walk = func(pol *PolicyDecl, conds []*Condition, schema attributeSchema) {
+		for _, c := range conds {
+			switch {
+			case c.Expression != "":
+				if _, err := expr.Compile(c.Expression, schema); err != nil {
+					r.errf(c.Pos, "policy %q: %v", pol.Name, err)
+				}
+			case c.Field != "":
+				cond := policy.Condition{Field: c.Field, Operator: policy.Operator(c.Operator), Value: c.Value, ValueRef: c.ValueRef, TimeZone: c.TimeZone}
+				if err := cond.Validate(); err != nil {
+					r.errf(c.Pos, "policy %q: %v", pol.Name, err)
+					continue
+				}
+				r.checkConditionTypes(pol, c, schema)
+			}
+			walk(pol, c.AllOf, schema)
+			walk(pol, c.AnyOf, schema)
+		}
+	}
+	for _, pol := range r.prog.Policies {
+		walk(pol, pol.Conditions, r.schemaFor(pol))
+	}
+}
+
+// checkSoD validates each sod constraint and reports roles that no
+// namespace of the program declares. Names are unique per tenant.
+func (r *resolver) checkSoD() {
+	slugs := make(map[string]bool, len(r.prog.Roles))
+	for _, role := range r.prog.Roles {
+		slugs[role.Slug] = true
+	}
+	seen := make(map[string]*SoDDecl, len(r.prog.SoDConstraints))
+	for _, d := range r.prog.SoDConstraints {
+		if existing, ok := seen[d.Name]; ok {
+			r.errf(d.Pos, "sod constraint %q already declared at %s", d.Name, existing.Pos)
+			continue
+		}
+		seen[d.Name] = d
+		if !slugRegex.MatchString(d.Name) {
+			r.errf(d.Pos, "sod constraint name %q must match %s", d.Name, slugRegex.String())
+		}
+		if err := d.constraint().Validate(); err != nil {
+			r.errf(d.Pos, "sod constraint %q: %v", d.Name, err)
+		}
+		for _, slug := range d.Roles {
+			if slug != "" && !slugs[slug] {
+				r.errf(d.Pos, "sod constraint %q references undeclared role %q", d.Name, slug)
+			}
+		}
+	}
+}
+
+// constraint returns the sod.Constraint d declares, without tenant scope.
+func (d *SoDDecl) constraint() *sod.Constraint {
+	kind := sod.Kind(d.Kind)
+	if kind == "" {
+		kind = sod.KindStatic
+	}
+	return &sod.Constraint{
+		Name:        d.Name,
+		Description: d.Description,
+		Kind:        kind,
+		Roles:       d.Roles,
+		MaxRoles:    d.MaxRoles,
+	}
+}
+
 func (r *resolver) checkExprNames(rt *ResourceDecl, e Expr, targets map[string]string) {
 	switch v := e.(type) {
 	case *RefExpr:
@@ -196,3 +196,164 @@ namespace "Eng" {
 		t.Fatal("expected diagnostic on uppercase namespace segment")
 	}
 }
+
+func TestResolve_ConditionExpression(t *testing.T) {
+	src := `
+warden config 1
+tenant t1
+
+policy "big-teams" {
+    effect = allow
+    when {
+        expr "size(subject.groups) > 2 && resource.owner_id == subject.id"
+        any_of {
+            expr "subject.id > 3"
+        }
+    }
+}
+`
+	errs := resolveSrc(t, src)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", errs)
+	}
+	wantDiagContaining(t, errs, "operator > not defined on string and int")
+}
+
+func TestResolve_AttributeSchema(t *testing.T) {
+	src := `
+warden config 1
+tenant t1
+
+resource user {
+    attribute level: int
+}
+
+resource document {
+    attribute size: int required
+    attribute tier: string enum ["free", "pro"]
+    attribute rank: float enum ["high"]
+    attribute size: string
+}
+
+resource folder {
+    attribute color: colour
+}
+
+policy "large" {
+    effect = allow
+    resources = ["document:*"]
+    when {
+        resource.size > 100
+        resource.tier > 1
+        resource.tier == "gold"
+        subject.level >= "senior"
+        resource.size == subject.level
+        resource.tier == subject.level
+        expr "resource.tier + 1 == 2"
+    }
+}
+`
+	errs := resolveSrc(t, src)
+	for _, want := range []string{
+		`attribute "size" declared twice on resource "document"`,
+		`enum value "high" is not of type float`,
+		`attribute "color" has unknown type "colour"`,
+		"operator gt needs a numeric attribute, resource.tier is string",
+		`resource.tier: attribute "tier": gold is not one of [free pro]`,
+		`subject.level: attribute "level": senior (string) is not of type int`,
+		"cannot compare resource.tier (string) with subject.level (int)",
+		"operator + not defined on string and int",
+	} {
+		wantDiagContaining(t, errs, want)
+	}
+	if len(errs) != 8 {
+		t.Errorf("expected 8 diagnostics, got %v", errs)
+	}
+}
+
+func TestResolve_CollectionOperators(t *testing.T) {
+	src := `
+warden config 1
+tenant t1
+
+resource document {
+    attribute tags: list
+    attribute labels: map
+    attribute owner: string
+}
+
+policy "tagged" {
+    effect = allow
+    resources = ["document:*"]
+    when {
+        resource.tags any_in ["public", "shared"]
+        resource.labels size_gt 2
+        resource.owner contains_element "alice"
+        resource.owner size_lt 3
+        resource.tags size_gt "many"
+    }
+}
+`
+	errs := resolveSrc(t, src)
+	for _, want := range []string{
+		"operator contains_element needs a list attribute, resource.owner is string",
+		"operator size_lt needs a list or map attribute, resource.owner is string",
+		"size must be a non-negative integer, got many",
+	} {
+		wantDiagContaining(t, errs, want)
+	}
+	if len(errs) != 3 {
+		t.Errorf("expected 3 diagnostics, got %v", errs)
+	}
+}
+
+func TestResolve_RateWindow(t *testing.T) {
+	src := `
+warden config 1
+tenant t1
+
+policy "export-velocity" {
+    effect = deny
+    when {
+        rate(subject, "export", "25h") > 50
+    }
+}
+`
+	errs := resolveSrc(t, src)
+	wantDiagContaining(t, errs, "window must be a whole number of seconds")
+}
+
+func TestResolve_ParentAttributes(t *testing.T) {
+	src := `
+warden config 1
+tenant t1
+
+resource project {
+    attribute classification: string enum ["public", "secret"]
+    attribute budget: int
+}
+
+resource document {
+    relation parent: project
+    attribute title: string
+}
+
+policy "no-secret-parents" {
+    effect = deny
+    resources = ["document:*"]
+    when {
+        resource.parent.classification == "secret"
+        resource.parent.classification == "internal"
+        resource.parent.budget > "lots"
+        resource.parent.title == "untyped"
+        resource.ancestors contains_element "project:p1"
+    }
+}
+`
+	errs := resolveSrc(t, src)
+	wantDiagContaining(t, errs, `resource.parent.classification: attribute "classification": internal is not one of [public secret]`)
+	wantDiagContaining(t, errs, `resource.parent.budget: attribute "budget": lots (string) is not of type int`)
+	if len(errs) != 2 {
+		t.Errorf("expected 2 diagnostics, got %v", errs)
+	}
+}
@@ -0,0 +1,202 @@
+package dsl
+
+import (
+	"path"
+	"strings"
+
+	"github.com/xraph/warden/expr"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/resourcetype"
+)
+
+// attributeDef converts a DSL attribute declaration to its stored form.
+func (d *AttributeDecl) attributeDef() resourcetype.AttributeDef {
+	return resourcetype.AttributeDef{
+		Name:     d.Name,
+		Type:     resourcetype.AttributeType(d.Type),
+		Required: d.Required,
+		Enum:     d.Enum,
+	}
+}
+
+// checkAttributeDecls validates attribute declarations: known types, unique
+// names and enum values of the declared type.
+func (r *resolver) checkAttributeDecls() {
+	for _, rt := range r.prog.ResourceTypes {
+		seen := make(map[string]bool, len(rt.Attributes))
+		for _, attr := range rt.Attributes {
+			if seen[attr.Name] {
+				r.errf(attr.Pos, "attribute %q declared twice on resource %q", attr.Name, rt.Name)
+			}
+			seen[attr.Name] = true
+			def := attr.attributeDef()
+			if !def.Type.Valid() {
+				r.errf(attr.Pos, "attribute %q has unknown type %q (want string, int, float, bool, timestamp, duration, list or map)", attr.Name, attr.Type)
+				continue
+			}
+			def.Enum = nil
+			for _, v := range attr.Enum {
+				if _, err := def.Coerce(v); err != nil {
+					r.errf(attr.Pos, "enum value %q is not of type %s", v, attr.Type)
+				}
+			}
+		}
+	}
+}
+
+// attributeSchema maps attribute paths ("subject.level", "resource.size")
+// to their declarations for one policy. Resource attributes come from the
+// resource types the policy's resources can match, and parent attributes
+// ("resource.parent.level") from the types their parent relation allows;
+// subject attributes from every resource type, since any of them may name
+// a subject kind. A path declared with different types is left out and
+// stays untyped.
+type attributeSchema map[string]resourcetype.AttributeDef
+
+// FieldType implements expr.Env.
+func (s attributeSchema) FieldType(p string) (expr.Type, bool) {
+	def, ok := s[p]
+	if !ok {
+		return expr.Dyn, false
+	}
+	return expr.ParseType(string(def.Type))
+}
+
+func (r *resolver) schemaFor(pol *PolicyDecl) attributeSchema {
+	schema := make(attributeSchema)
+	conflicts := make(map[string]bool)
+	add := func(root string, rt *ResourceDecl) {
+		for _, attr := range rt.Attributes {
+			def := attr.attributeDef()
+			if !def.Type.Valid() {
+				continue
+			}
+			p := root + "." + attr.Name
+			if prev, ok := schema[p]; ok && prev.Type != def.Type {
+				conflicts[p] = true
+			}
+			schema[p] = def
+		}
+	}
+	for _, rt := range r.prog.ResourceTypes {
+		add("subject", rt)
+		if policyMatchesResourceType(pol, rt.Name) {
+			add("resource", rt)
+			for _, parent := range r.parentTypes(rt) {
+				add("resource.parent", parent)
+			}
+		}
+	}
+	for p := range conflicts {
+		delete(schema, p)
+	}
+	return schema
+}
+
+// parentTypes returns the resource types rt's parent relation allows, so
+// resource.parent.<attr> is typed like the parent's own attributes.
+func (r *resolver) parentTypes(rt *ResourceDecl) []*ResourceDecl {
+	var out []*ResourceDecl
+	for _, rel := range rt.Relations {
+		if rel.Name != resourcetype.ParentRelation {
+			continue
+		}
+		for _, st := range rel.AllowedSubjects {
+			if st.Relation != "" {
+				continue
+			}
+			for _, cand := range r.prog.ResourceTypes {
+				if cand.Name == st.Type {
+					out = append(out, cand)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// policyMatchesResourceType reports whether one of the policy's resource
+// patterns ("document", "document:*", "doc*:123") can match resources of
+// the named type.
+func policyMatchesResourceType(pol *PolicyDecl, name string) bool {
+	if len(pol.Resources) == 0 {
+		return true
+	}
+	for _, pattern := range pol.Resources {
+		typ, _, _ := strings.Cut(pattern, ":")
+		if ok, _ := path.Match(typ, name); ok || typ == "*" { //nolint:errcheck // a bad pattern matches nothing
+			return true
+		}
+	}
+	return false
+}
+
+// checkConditionTypes checks an atomic condition against the declared
+// attribute types.
+func (r *resolver) checkConditionTypes(pol *PolicyDecl, c *Condition, schema attributeSchema) {
+	def, ok := schema[c.Field]
+	if !ok {
+		return
+	}
+	if policy.Operator(c.Operator).IsSchedule() {
+		if def.Type != resourcetype.AttrTimestamp {
+			r.errf(c.Pos, "policy %q: operator %s needs a timestamp attribute, %s is %s", pol.Name, c.Operator, c.Field, def.Type)
+		}
+		return
+	}
+	switch c.Operator {
+	case "any_in", "all_in", "contains_element":
+		if def.Type != resourcetype.AttrList {
+			r.errf(c.Pos, "policy %q: operator %s needs a list attribute, %s is %s", pol.Name, c.Operator, c.Field, def.Type)
+		}
+		return
+	case "size_gt", "size_lt":
+		if def.Type != resourcetype.AttrList && def.Type != resourcetype.AttrMap {
+			r.errf(c.Pos, "policy %q: operator %s needs a list or map attribute, %s is %s", pol.Name, c.Operator, c.Field, def.Type)
+		}
+		return
+	}
+	if c.ValueRef != "" {
+		ref, ok := schema[c.ValueRef]
+		if ok && !comparableTypes(def.Type, ref.Type) {
+			r.errf(c.Pos, "policy %q: cannot compare %s (%s) with %s (%s)", pol.Name, c.Field, def.Type, c.ValueRef, ref.Type)
+		}
+		return
+	}
+	switch c.Operator {
+	case "gt", "lt", "gte", "lte":
+		if def.Type != resourcetype.AttrInt && def.Type != resourcetype.AttrFloat {
+			r.errf(c.Pos, "policy %q: operator %s needs a numeric attribute, %s is %s", pol.Name, c.Operator, c.Field, def.Type)
+			return
+		}
+		r.checkConditionValue(pol, c, def, c.Value)
+	case "time_after", "time_before":
+		if def.Type != resourcetype.AttrTimestamp {
+			r.errf(c.Pos, "policy %q: operator %s needs a timestamp attribute, %s is %s", pol.Name, c.Operator, c.Field, def.Type)
+		}
+	case "eq", "neq":
+		r.checkConditionValue(pol, c, def, c.Value)
+	case "in", "not_in":
+		if vals, ok := c.Value.([]any); ok {
+			for _, v := range vals {
+				r.checkConditionValue(pol, c, def, v)
+			}
+		}
+	}
+}
+
+func (r *resolver) checkConditionValue(pol *PolicyDecl, c *Condition, def resourcetype.AttributeDef, v any) {
+	if v == nil || def.Type == resourcetype.AttrList || def.Type == resourcetype.AttrMap {
+		return
+	}
+	if _, err := def.Coerce(v); err != nil {
+		r.errf(c.Pos, "policy %q: %s: %v", pol.Name, c.Field, err)
+	}
+}
+
+func comparableTypes(a, b resourcetype.AttributeType) bool {
+	numeric := func(t resourcetype.AttributeType) bool {
+		return t == resourcetype.AttrInt || t == resourcetype.AttrFloat
+	}
+	return a == b || (numeric(a) && numeric(b))
+}
@@ -0,0 +1,26 @@
+package dsl
+
+import (
+	"context"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/store"
+)
+
+// SimulationChanges wraps a program as a proposed change for
+// Engine.Simulate. The program is applied to the simulation's overlay
+// store exactly as Apply would apply it to the real one, so opts.Prune
+// simulates replacing the tenant's model with the program rather than
+// merging it in. Checklog blocks are ignored.
+func SimulationChanges(prog *Program, opts ApplyOptions) *warden.SimulationChanges {
+	return &warden.SimulationChanges{
+		Apply: func(ctx context.Context, s store.Store) error {
+			if errs := Resolve(prog); len(errs) > 0 {
+				return &DiagnosticError{Diags: errs}
+			}
+			a := newApplier(ctx, s, prog, opts)
+			a.dryRun = false
+			return a.run(prog)
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package dsl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/id"
+)
+
+func TestSimulationChanges(t *testing.T) {
+	base := `
+warden config 1
+tenant t1
+
+permission "document:read"   (document : read)
+permission "document:write"  (document : write)
+
+role viewer {
+    name = "Viewer"
+    grants = ["document:read"]
+}
+`
+	prog, _ := Parse("base.warden", []byte(base))
+	eng, s := newTestEngine(t)
+	ctx := warden.WithTenant(context.Background(), "", "t1")
+	if _, err := Apply(ctx, eng, prog, ApplyOptions{}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	viewer, err := s.GetRoleBySlug(ctx, "t1", "", "viewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateAssignment(ctx, &assignment.Assignment{
+		ID: id.NewAssignmentID(), TenantID: "t1", RoleID: viewer.ID, SubjectKind: "user", SubjectID: "u1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	proposed := `
+warden config 1
+tenant t1
+
+role viewer {
+    name = "Viewer"
+    grants = ["document:read", "document:write"]
+}
+`
+	changes, _ := Parse("proposed.warden", []byte(proposed))
+	reqs := []*warden.CheckRequest{
+		{Subject: warden.Subject{Kind: warden.SubjectUser, ID: "u1"}, Action: warden.Action{Name: "read"}, Resource: warden.Resource{Type: "document", ID: "d1"}},
+		{Subject: warden.Subject{Kind: warden.SubjectUser, ID: "u1"}, Action: warden.Action{Name: "write"}, Resource: warden.Resource{Type: "document", ID: "d1"}},
+	}
+	rep, err := eng.SimulateRequests(ctx, SimulationChanges(changes, ApplyOptions{}), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Checks != 2 || rep.NewlyAllowed != 1 || len(rep.Flips) != 1 || rep.Flips[0].Request.Action.Name != "write" {
+		t.Fatalf("report = %+v", rep)
+	}
+
+	// With Prune the program is the whole model, so dropping the role
+	// revokes everything it granted.
+	pruned, _ := Parse("pruned.warden", []byte(`
+warden config 1
+tenant t1
+
+permission "document:read"   (document : read)
+permission "document:write"  (document : write)
+`))
+	rep, err = eng.SimulateRequests(ctx, SimulationChanges(pruned, ApplyOptions{Prune: true}), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.NewlyDenied != 1 || rep.NewlyAllowed != 0 {
+		t.Errorf("pruned report = %+v", rep)
+	}
+
+	// The real store still has the original grants.
+	perms, err := s.ListRolePermissions(ctx, viewer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 1 {
+		t.Errorf("viewer has %d permissions after simulating, want 1", len(perms))
+	}
+}
@@ -0,0 +1,198 @@
+package dsl
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xraph/warden/sod"
+)
+
+const sodSrc = `warden config 1
+tenant t1
+
+role requester {
+    name = "Requester"
+}
+
+role approver {
+    name = "Approver"
+}
+
+role auditor {
+    name = "Auditor"
+}
+
+sod "payments" {
+    description = "Nobody approves their own payments."
+    roles = ["requester", "approver"]
+}
+
+sod "session-duties" {
+    kind = dynamic
+    roles = ["requester", "approver", "auditor"]
+    max_roles = 2
+}
+`
+
+func TestParser_SoD(t *testing.T) {
+	prog := mustParse(t, sodSrc)
+	if len(prog.SoDConstraints) != 2 {
+		t.Fatalf("expected 2 sod constraints, got %d", len(prog.SoDConstraints))
+	}
+	p := prog.SoDConstraints[0]
+	if p.Name != "payments" || p.Kind != "" || strings.Join(p.Roles, ",") != "requester,approver" {
+		t.Errorf("payments = %+v", p)
+	}
+	if p.Description == "" {
+		t.Error("description not parsed")
+	}
+	d := prog.SoDConstraints[1]
+	if d.Kind != "dynamic" || d.MaxRoles != 2 || len(d.Roles) != 3 {
+		t.Errorf("session-duties = %+v", d)
+	}
+}
+
+func TestParser_SoDErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":  "warden config 1\nsod \"x\" {\n    limit = 1\n}\n",
+		"bad kind":     "warden config 1\nsod \"x\" {\n    kind = sometimes\n}\n",
+		"missing name": "warden config 1\nsod {\n}\n",
+		"in namespace": "warden config 1\nnamespace ops {\n    sod \"x\" {\n    }\n}\n",
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, errs := Parse("test.warden", []byte(src)); len(errs) == 0 {
+				t.Fatal("expected parse error")
+			}
+		})
+	}
+}
+
+// TestParser_SoDNotReserved pins that `sod` stays usable as an ordinary
+// identifier outside top-level position.
+func TestParser_SoDNotReserved(t *testing.T) {
+	mustParse(t, "warden config 1\nrole sod {\n    name = \"SoD officer\"\n}\n")
+}
+
+func TestFormat_SoDRoundTrip(t *testing.T) {
+	first := Format(mustParse(t, sodSrc))
+	second := Format(mustParse(t, first))
+	if first != second {
+		t.Fatalf("format not stable:\n%s\n---\n%s", first, second)
+	}
+	if !strings.Contains(first, "sod \"session-duties\" {") || !strings.Contains(first, "max_roles = 2") {
+		t.Errorf("sod block missing from output:\n%s", first)
+	}
+}
+
+func TestResolve_SoD(t *testing.T) {
+	const roles = "warden config 1\nrole a {\n}\nrole b {\n}\n"
+	cases := map[string]struct {
+		src  string
+		want string
+	}{
+		"undeclared role": {
+			src:  roles + "sod \"x\" {\n    roles = [\"a\", \"ghost\"]\n}\n",
+			want: `undeclared role "ghost"`,
+		},
+		"duplicate": {
+			src:  roles + "sod \"x\" {\n    roles = [\"a\", \"b\"]\n}\nsod \"x\" {\n    roles = [\"a\", \"b\"]\n}\n",
+			want: "already declared",
+		},
+		"max_roles too high": {
+			src:  roles + "sod \"x\" {\n    roles = [\"a\", \"b\"]\n    max_roles = 2\n}\n",
+			want: "max_roles 2",
+		},
+		"single role": {
+			src:  roles + "sod \"x\" {\n    roles = [\"a\"]\n}\n",
+			want: `sod constraint "x"`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wantDiagContaining(t, resolveSrc(t, tc.src), tc.want)
+		})
+	}
+	if errs := resolveSrc(t, sodSrc); len(errs) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", errs)
+	}
+}
+
+func TestApply_SoD(t *testing.T) {
+	ctx := context.Background()
+	eng, s := newTestEngine(t)
+
+	res, err := Apply(ctx, eng, mustParse(t, sodSrc), ApplyOptions{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if !slices.Contains(res.Created, "+ sod/payments") || !slices.Contains(res.Created, "+ sod/session-duties") {
+		t.Fatalf("created = %v", res.Created)
+	}
+	cs, err := s.ListSoDConstraints(ctx, &sod.ListFilter{TenantID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 {
+		t.Fatalf("expected 2 stored constraints, got %d", len(cs))
+	}
+
+	// Re-applying is a no-op.
+	res, err = Apply(ctx, eng, mustParse(t, sodSrc), ApplyOptions{})
+	if err != nil {
+		t.Fatalf("re-apply: %v", err)
+	}
+	if len(res.Created)+len(res.Updated) != 0 {
+		t.Errorf("expected no changes on re-apply, got created=%v updated=%v", res.Created, res.Updated)
+	}
+
+	// Tightening one constraint and dropping the other, with prune.
+	changed := strings.Replace(sodSrc, "max_roles = 2", "max_roles = 1", 1)
+	changed = changed[:strings.Index(changed, `sod "payments"`)] + changed[strings.Index(changed, `sod "session-duties"`):]
+	res, err = Apply(ctx, eng, mustParse(t, changed), ApplyOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("apply changed: %v", err)
+	}
+	if !slices.Contains(res.Updated, "~ sod/session-duties") || !slices.Contains(res.Deleted, "- sod/payments") {
+		t.Fatalf("updated = %v, deleted = %v", res.Updated, res.Deleted)
+	}
+	cs, err = s.ListSoDConstraints(ctx, &sod.ListFilter{TenantID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 || cs[0].MaxRoles != 1 {
+		t.Fatalf("after prune: %+v", cs)
+	}
+}
+
+func TestExport_SoDRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	eng, _ := newTestEngine(t)
+	if _, err := Apply(ctx, eng, mustParse(t, sodSrc), ApplyOptions{}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	prog, err := BuildProgram(ctx, eng, ExportOptions{TenantID: "t1"})
+	if err != nil {
+		t.Fatalf("BuildProgram: %v", err)
+	}
+	if len(prog.SoDConstraints) != 2 {
+		t.Fatalf("exported %d sod constraints, want 2", len(prog.SoDConstraints))
+	}
+	res, err := Apply(ctx, eng, mustParse(t, Format(prog)), ApplyOptions{})
+	if err != nil {
+		t.Fatalf("re-apply export: %v", err)
+	}
+	if len(res.Created)+len(res.Updated) != 0 {
+		t.Errorf("export should round-trip, got created=%v updated=%v", res.Created, res.Updated)
+	}
+
+	scoped, err := BuildProgram(ctx, eng, ExportOptions{TenantID: "t1", NamespacePrefix: "ops"})
+	if err != nil {
+		t.Fatalf("BuildProgram scoped: %v", err)
+	}
+	if len(scoped.SoDConstraints) != 0 {
+		t.Error("namespace-scoped export should leave tenant-wide sod constraints out")
+	}
+}
@@ -134,6 +134,9 @@ const (
 	NOT_BEFORE  //nolint:revive // matches DSL keyword spelling
 	NOT_AFTER   //nolint:revive // matches DSL keyword spelling
 	OBLIGATIONS // obligations (PBAC: named side-effect actions)
+	ADVICE      // advice (PBAC: optional structured hints)
+	CHECKLOG    // checklog (check-log sampling and filtering rules)
+	EXPR        // expr (expression condition inside `when { ... }`)
 )
 
 // keywords maps keyword spellings to their TokenKind.
@@ -182,6 +185,9 @@ var keywords = map[string]TokenKind{
 	"not_before":  NOT_BEFORE,
 	"not_after":   NOT_AFTER,
 	"obligations": OBLIGATIONS,
+	"advice":      ADVICE,
+	"checklog":    CHECKLOG,
+	"expr":        EXPR,
 	"true":        BOOL,
 	"false":       BOOL,
 }
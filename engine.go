@@ -4,13 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/xraph/go-utils/log"
 
+	"github.com/xraph/warden/breakglass"
 	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/counter"
 	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/plugin"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/role"
 	"github.com/xraph/warden/store"
 )
 
@@ -23,8 +32,28 @@ type Engine struct {
 	exprEval    ExpressionEvaluator
 	cache       Cache
 	plugins     *plugin.Registry
+	attrCache   *attributeCache
+	policyIndex *policyIndex
+	counters    counter.Store
+	checkHooks  checkHooks
+	tracers     []Tracer
 	logger      log.Logger
 	config      Config
+
+	// checkLogMu guards config.CheckLog, which may be replaced at runtime
+	// via SetCheckLogRules.
+	checkLogMu sync.RWMutex
+
+	// retentionStop stops the check-log retention scheduler, if running.
+	retentionStop func()
+
+	// policyNotifyStop unsubscribes the policy index from store change
+	// notifications, if subscribed.
+	policyNotifyStop func()
+
+	// countersPurgedAt is when expired rate counter buckets were last
+	// purged, in Unix nanoseconds.
+	countersPurgedAt atomic.Int64
 }
 
 // ExpressionEvaluator is an optional engine hook that evaluates resource-type
@@ -56,10 +85,30 @@ func NewEngine(opts ...Option) (*Engine, error) {
 	if e.config.MaxGraphDepth > 0 {
 		e.graphWalker = DefaultGraphWalker(e.config.MaxGraphDepth)
 	}
+	// Subscribe before wrapping: the wrappers do not forward notifications.
+	notifier, notified := e.store.(policy.ChangeNotifier)
+	e.policyIndex = newPolicyIndex(e.config.PolicyIndexTTL, notified)
+	if notified && e.policyIndex != nil {
+		e.policyNotifyStop = notifier.NotifyPolicyChanges(e.policyIndex.invalidate)
+	}
+	if e.config.auditEnabled() {
+		e.store = newAuditedStore(e.store, e.logger)
+	}
+	if e.policyIndex != nil {
+		e.store = &policyIndexStore{Store: e.store, index: e.policyIndex}
+	}
+	e.counters = counter.NewMemory()
+	if e.config.PersistRateCounters {
+		e.counters = e.store
+	}
+	e.attrCache = newAttributeCache(e.config.AttributeCacheTTL)
+	e.checkHooks = newCheckHooks(e.plugins)
+	e.tracers = newTracers(e.plugins)
 	return e, nil
 }
 
-// Store returns the underlying composite store.
+// Store returns the engine's composite store. Unless Config.EnableAudit is
+// false, mutations made through it are recorded in the audit trail.
 func (e *Engine) Store() store.Store { return e.store }
 
 // SetExpressionEvaluator overrides the resource-type expression evaluator
@@ -68,7 +117,11 @@ func (e *Engine) Store() store.Store { return e.store }
 func (e *Engine) SetExpressionEvaluator(ev ExpressionEvaluator) { e.exprEval = ev }
 
 // Config returns the engine configuration.
-func (e *Engine) Config() Config { return e.config }
+func (e *Engine) Config() Config {
+	e.checkLogMu.RLock()
+	defer e.checkLogMu.RUnlock()
+	return e.config
+}
 
 // Plugins returns the plugin registry (may be nil).
 func (e *Engine) Plugins() *plugin.Registry { return e.plugins }
@@ -78,15 +131,50 @@ func (e *Engine) Health(ctx context.Context) error {
 	return e.store.Ping(ctx)
 }
 
-// Start performs any startup initialization.
-func (e *Engine) Start(_ context.Context) error { return nil }
+// Start performs any startup initialization, including the check-log
+// retention scheduler when Config.CheckLogRetention.Interval is set.
+func (e *Engine) Start(_ context.Context) error {
+	e.startRetention()
+	return nil
+}
 
-// Stop performs graceful shutdown.
-func (e *Engine) Stop(_ context.Context) error { return nil }
+// Stop performs graceful shutdown, delivering events still queued for
+// async plugins until ctx is done and then running Shutdown hooks.
+func (e *Engine) Stop(ctx context.Context) error {
+	e.stopRetention()
+	if e.policyNotifyStop != nil {
+		e.policyNotifyStop()
+		e.policyNotifyStop = nil
+	}
+	if e.plugins == nil {
+		return nil
+	}
+	err := e.plugins.Close(ctx)
+	e.plugins.EmitShutdown(ctx)
+	return err
+}
 
 // Check performs an authorization check. This is the hot path.
 // Optional CallOption values override scope for this single call.
 func (e *Engine) Check(ctx context.Context, req *CheckRequest, opts ...CallOption) (*CheckResult, error) {
+	if len(e.tracers) == 0 {
+		return e.check(ctx, req, opts)
+	}
+	ctx, span := startSpan(withTracers(ctx, e.tracers), SpanCheck)
+	span.SetAttributes(
+		Attr(AttrSubjectKind, string(req.Subject.Kind)),
+		Attr(AttrAction, req.Action.Name),
+		Attr(AttrResourceType, req.Resource.Type),
+	)
+	result, err := e.check(ctx, req, opts)
+	if result != nil {
+		span.SetAttributes(Attr(AttrSource, matchSource(result)))
+	}
+	endStageSpan(span, result, err)
+	return result, err
+}
+
+func (e *Engine) check(ctx context.Context, req *CheckRequest, opts []CallOption) (*CheckResult, error) {
 	start := time.Now()
 
 	// Validate required fields.
@@ -119,6 +207,8 @@ func (e *Engine) Check(ctx context.Context, req *CheckRequest, opts ...CallOptio
 	if co.namespacePathSet {
 		scope.namespacePath = co.namespacePath
 	}
+	span := SpanFromContext(ctx)
+	span.SetAttributes(Attr(AttrTenantID, scope.tenantID))
 
 	e.logger.Debug("warden: check",
 		log.String("subject_kind", string(req.Subject.Kind)),
@@ -129,9 +219,23 @@ func (e *Engine) Check(ctx context.Context, req *CheckRequest, opts ...CallOptio
 		log.String("scope_tenant_id", scope.tenantID),
 	)
 
-	// 1. Cache hit?
-	if e.cache != nil {
-		if cached, ok := e.cache.Get(ctx, scope.tenantID, req); ok {
+	// 1. Veto-capable check hooks run ahead of the cache so a kill switch
+	// takes effect immediately.
+	decided, fx := e.runBeforeCheckHooks(ctx, req)
+	if decided != nil {
+		result := fulfillObligations(e.runAfterCheckHooks(ctx, req, decided, fx))
+		result.EvalTimeNs = time.Since(start).Nanoseconds()
+		e.finishCheck(ctx, scope, req, result)
+		return result, nil
+	}
+
+	// 1a. Cache hit? Checks that activate a subset of roles are never
+	// cached: the cache key does not carry the active set.
+	if e.cache != nil && len(req.Subject.ActiveRoles) == 0 {
+		cached, ok := e.cache.Get(ctx, scope.tenantID, req)
+		span.SetAttributes(Attr(AttrCacheHit, ok))
+		if ok {
+			cached = fulfillObligations(e.runAfterCheckHooks(ctx, req, cached, fx))
 			cached.EvalTimeNs = time.Since(start).Nanoseconds()
 			return cached, nil
 		}
@@ -147,7 +251,9 @@ func (e *Engine) Check(ctx context.Context, req *CheckRequest, opts ...CallOptio
 
 	// 2. RBAC: resolve roles → check permissions.
 	if e.config.rbacEnabled() {
-		rbacResult, err = e.evaluateRBAC(ctx, scope, req)
+		sctx, stage := startSpan(ctx, SpanRBAC)
+		rbacResult, err = e.evaluateRBAC(sctx, scope, req)
+		endStageSpan(stage, rbacResult, err)
 		if err != nil {
 			return nil, fmt.Errorf("warden rbac: %w", err)
 		}
@@ -155,15 +261,25 @@ func (e *Engine) Check(ctx context.Context, req *CheckRequest, opts ...CallOptio
 
 	// 3. ReBAC: check relation tuples → walk graph.
 	if e.config.rebacEnabled() {
-		rebacResult, err = e.evaluateReBAC(ctx, scope, req)
+		sctx, stage := startSpan(ctx, SpanReBAC)
+		rebacResult, err = e.evaluateReBAC(sctx, scope, req)
+		endStageSpan(stage, rebacResult, err)
 		if err != nil {
 			return nil, fmt.Errorf("warden rebac: %w", err)
 		}
 	}
 
-	// 4. ABAC: evaluate active policies with conditions.
+	// 4. ABAC: evaluate active policies with conditions. Attributes the
+	// request lacks are fetched lazily from AttributeProvider plugins and,
+	// for the resource's parents, from parent relation tuples.
+	attrs := e.newAttributeResolver(scope.tenantID)
+	abacCtx, schedules := e.withCalendars(withAttributeResolver(ctx, attrs), scope.tenantID)
+	abacCtx, rates := e.withRates(e.withHierarchy(abacCtx, scope), scope.tenantID)
+	abacCtx, obligationRefs := withObligationRefs(abacCtx)
 	if e.config.abacEnabled() {
-		abacResult, err = e.evaluateABAC(ctx, scope, req)
+		sctx, stage := startSpan(abacCtx, SpanABAC)
+		abacResult, err = e.evaluateABAC(sctx, scope, req)
+		endStageSpan(stage, abacResult, err)
 		if err != nil {
 			return nil, fmt.Errorf("warden abac: %w", err)
 		}
@@ -171,27 +287,71 @@ func (e *Engine) Check(ctx context.Context, req *CheckRequest, opts ...CallOptio
 
 	// 5. Merge: explicit deny > allow > default deny.
 	result := e.mergeDecisions(req, rbacResult, rebacResult, abacResult)
+
+	// 5a. Break-glass: a deny is re-evaluated with the subject's active
+	// emergency grants.
+	var grant *breakglass.Grant
+	if !result.Allowed && e.config.BreakGlass.enabled() {
+		bg, g, bgErr := e.applyBreakGlass(abacCtx, scope, req, rbacResult, rebacResult, abacResult)
+		if bgErr != nil {
+			return nil, fmt.Errorf("warden break-glass: %w", bgErr)
+		}
+		if bg != nil {
+			result, grant = bg, g
+		}
+	}
+	result.Trace = attrs.decisionTrace()
 	result.EvalTimeNs = time.Since(start).Nanoseconds()
 
-	// 6. Cache the result.
-	if e.cache != nil {
+	// 6. Cache the result. Hook effects are applied afterwards so they are
+	// recomputed on every hit, and structured obligations are filtered
+	// against the decision the hooks leave behind. Break-glass results are
+	// not cached so expiry and revocation take effect immediately, nor are
+	// results that read or increment a rate counter, evaluate a schedule or
+	// calendar, activate a subset of roles, or carry policy errors, which
+	// may be transient. Results with shadow diffs are not cached either: a
+	// hit skips finishCheck, so the diffs would go unreported. Nor are
+	// results with obligation parameters interpolated from the request,
+	// whose context and attributes the cache key leaves out.
+	if e.cache != nil && grant == nil && !rates.used() && !schedules.used() && !obligationRefs.used() && !incrementsRate(result) &&
+		len(req.Subject.ActiveRoles) == 0 && len(result.Errors) == 0 && len(result.Shadow) == 0 {
 		e.cache.Set(ctx, scope.tenantID, req, result)
 	}
+	result = fulfillObligations(e.runAfterCheckHooks(ctx, req, result, fx))
+	if grant != nil && result.Allowed && e.plugins != nil {
+		e.plugins.EmitBreakGlassUsed(ctx, grant, req, result)
+	}
 
-	// 7. Extension hooks: per-obligation, then after check.
+	e.finishCheck(ctx, scope, req, result)
+	return result, nil
+}
+
+// finishCheck fires the post-decision plugin events and writes the check
+// log entry.
+func (e *Engine) finishCheck(ctx context.Context, scope tenantScope, req *CheckRequest, result *CheckResult) {
+	// 7. Rate counters count the check when a policy asks for it.
+	e.incrementRates(ctx, scope, req, result)
+
+	// 7a. Extension hooks: per-obligation, then after check.
 	if e.plugins != nil {
 		for _, ob := range result.Obligations {
 			e.plugins.EmitPolicyObligationFired(ctx, policyIDFromMatched(result.MatchedBy), ob, req, result)
 		}
+		for i := range result.Shadow {
+			sd := &result.Shadow[i]
+			polID, _ := id.ParsePolicyID(sd.PolicyID) //nolint:errcheck // zero ID on malformed input
+			e.plugins.EmitPolicyShadowDecision(ctx, polID, sd, req, result)
+		}
 		e.plugins.EmitAfterCheck(ctx, req, result)
 	}
 
-	// 8. Write check log entry (fire-and-forget).
-	if e.config.checkLogEnabled() {
-		go e.writeCheckLog(ctx, scope, req, result)
+	// 8. Write check log entry (fire-and-forget), subject to the
+	// configured sampling and filtering rules.
+	if e.config.checkLogEnabled() && e.shouldWriteCheckLog(scope, req, result) {
+		// The entry is built synchronously so the attribute snapshot cannot
+		// race with callers reusing the request after Check returns.
+		go e.writeCheckLog(ctx, newCheckLogEntry(ctx, scope, req, result))
 	}
-
-	return result, nil
 }
 
 // Enforce returns an error if the authorization check is denied.
@@ -221,37 +381,107 @@ func (e *Engine) CanI(ctx context.Context, subjectKind SubjectKind, subjectID, a
 	return result.Allowed, nil
 }
 
-func (e *Engine) writeCheckLog(ctx context.Context, scope tenantScope, req *CheckRequest, result *CheckResult) {
-	entry := &checklog.Entry{
-		ID:           id.NewCheckLogID(),
-		TenantID:     scope.tenantID,
-		AppID:        scope.appID,
-		SubjectKind:  string(req.Subject.Kind),
-		SubjectID:    req.Subject.ID,
-		Action:       req.Action.Name,
-		ResourceType: req.Resource.Type,
-		ResourceID:   req.Resource.ID,
-		Decision:     string(result.Decision),
-		Reason:       result.Reason,
-		EvalTimeNs:   result.EvalTimeNs,
-		CreatedAt:    time.Now(),
-	}
+func (e *Engine) writeCheckLog(ctx context.Context, entry *checklog.Entry) {
 	if err := e.store.CreateCheckLog(context.WithoutCancel(ctx), entry); err != nil {
 		e.logger.Error("warden: failed to write check log", log.Error(err))
 	}
 }
 
+// newCheckLogEntry records the full decision provenance of a check: scope,
+// caller IP and request ID, matched rules, obligations, and the attributes
+// the decision was evaluated against.
+func newCheckLogEntry(ctx context.Context, scope tenantScope, req *CheckRequest, result *CheckResult) *checklog.Entry {
+	requestIP, requestID := requestInfoFromContext(ctx)
+	return &checklog.Entry{
+		ID:            id.NewCheckLogID(),
+		TenantID:      scope.tenantID,
+		NamespacePath: scope.namespacePath,
+		AppID:         scope.appID,
+		SubjectKind:   string(req.Subject.Kind),
+		SubjectID:     req.Subject.ID,
+		Action:        req.Action.Name,
+		ResourceType:  req.Resource.Type,
+		ResourceID:    req.Resource.ID,
+		Decision:      string(result.Decision),
+		Reason:        result.Reason,
+		EvalTimeNs:    result.EvalTimeNs,
+		RequestIP:     requestIP,
+		RequestID:     requestID,
+		MatchedBy:     checkLogMatches(result.MatchedBy),
+		Obligations:   slices.Clone(result.Obligations),
+		Metadata:      checkLogMetadata(req, result),
+		CreatedAt:     time.Now(),
+	}
+}
+
+func checkLogMatches(matched []MatchInfo) []checklog.Match {
+	if len(matched) == 0 {
+		return nil
+	}
+	out := make([]checklog.Match, len(matched))
+	for i, m := range matched {
+		out[i] = checklog.Match{Source: m.Source, RuleID: m.RuleID, Detail: m.Detail, Permission: m.Permission}
+	}
+	return out
+}
+
+// checkLogMetadata is the attribute snapshot of req plus the would-be
+// decisions of shadow-mode policies and the policies that failed to
+// evaluate, if any.
+func checkLogMetadata(req *CheckRequest, result *CheckResult) map[string]any {
+	md := attributeSnapshot(req)
+	if len(result.Shadow) > 0 || len(result.Errors) > 0 {
+		if md == nil {
+			md = make(map[string]any, 2)
+		}
+	}
+	if len(result.Shadow) > 0 {
+		md[checklog.MetadataShadow] = slices.Clone(result.Shadow)
+	}
+	if len(result.Errors) > 0 {
+		md[checklog.MetadataErrors] = slices.Clone(result.Errors)
+	}
+	return md
+}
+
+// attributeSnapshot captures the attributes and session roles a check was
+// evaluated against so the log shows why an ABAC policy did or did not
+// match.
+func attributeSnapshot(req *CheckRequest) map[string]any {
+	md := make(map[string]any, 3)
+	if len(req.Subject.Attributes) > 0 {
+		md[checklog.MetadataSubjectAttributes] = maps.Clone(req.Subject.Attributes)
+	}
+	if len(req.Resource.Attributes) > 0 {
+		md[checklog.MetadataResourceAttributes] = maps.Clone(req.Resource.Attributes)
+	}
+	if len(req.Context) > 0 {
+		md[checklog.MetadataContext] = maps.Clone(req.Context)
+	}
+	if len(req.Subject.ActiveRoles) > 0 {
+		md[checklog.MetadataActiveRoles] = slices.Clone(req.Subject.ActiveRoles)
+	}
+	if len(md) == 0 {
+		return nil
+	}
+	return md
+}
+
 func (e *Engine) evaluateRBAC(ctx context.Context, scope tenantScope, req *CheckRequest) (*CheckResult, error) {
 	// Cascading scope: resolve roles assigned at the request's namespace and
 	// every ancestor up to the tenant root.
 	namespaces := AncestorNamespaces(scope.namespacePath)
 
 	// 1. Get roles assigned to subject (global + resource-scoped).
-	globalRoles, err := e.store.ListRolesForSubject(ctx, scope.tenantID, namespaces, string(req.Subject.Kind), req.Subject.ID)
+	globalRoles, err := traceStore(ctx, "ListRolesForSubject", func(ctx context.Context) ([]id.RoleID, error) {
+		return e.store.ListRolesForSubject(ctx, scope.tenantID, namespaces, string(req.Subject.Kind), req.Subject.ID)
+	})
 	if err != nil {
 		return nil, err
 	}
-	resourceRoles, err := e.store.ListRolesForSubjectOnResource(ctx, scope.tenantID, namespaces, string(req.Subject.Kind), req.Subject.ID, req.Resource.Type, req.Resource.ID)
+	resourceRoles, err := traceStore(ctx, "ListRolesForSubjectOnResource", func(ctx context.Context) ([]id.RoleID, error) {
+		return e.store.ListRolesForSubjectOnResource(ctx, scope.tenantID, namespaces, string(req.Subject.Kind), req.Subject.ID, req.Resource.Type, req.Resource.ID)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -268,8 +498,12 @@ func (e *Engine) evaluateRBAC(ctx context.Context, scope tenantScope, req *Check
 		return &CheckResult{Decision: DecisionDenyNoRoles, Reason: fmt.Sprintf("subject %s:%s has no assigned roles in tenant %q", req.Subject.Kind, req.Subject.ID, scope.tenantID)}, nil
 	}
 
-	// 2. Walk parent chain for inherited roles.
-	allRoles = e.resolveInheritedRoles(ctx, allRoles)
+	// 2. Narrow to the session's active roles, walk their parent chains
+	// for inherited roles, and enforce dynamic separation of duties.
+	allRoles, denied, err := e.activateRoles(ctx, scope, req, allRoles)
+	if err != nil || denied != nil {
+		return denied, err
+	}
 
 	// 3. Check if any role grants "resource:action" permission (glob matching).
 	permName := req.Resource.Type + ":" + req.Action.Name
@@ -280,8 +514,38 @@ func (e *Engine) evaluateRBAC(ctx context.Context, scope tenantScope, req *Check
 		log.String("tenant_id", scope.tenantID),
 	)
 
-	for _, roleID := range allRoles {
-		perms, err := e.store.ListRolePermissions(ctx, roleID)
+	// Every granting role is recorded, not just the first, so usage
+	// reports credit each of them.
+	if grants := e.matchRolePermissions(ctx, allRoles, permName, false); len(grants) > 0 {
+		matched := make([]MatchInfo, len(grants))
+		for i, g := range grants {
+			matched[i] = MatchInfo{
+				Source:     "rbac",
+				RuleID:     g.roleID.String(),
+				Detail:     "role grants " + g.permission,
+				Permission: g.permission,
+			}
+		}
+		return &CheckResult{Allowed: true, Decision: DecisionAllow, MatchedBy: matched}, nil
+	}
+
+	return &CheckResult{Decision: DecisionDenyNoPerms, Reason: fmt.Sprintf("no role grants permission %q for subject %s:%s", permName, req.Subject.Kind, req.Subject.ID)}, nil
+}
+
+// rbacGrant is a role permission that matched a check.
+type rbacGrant struct {
+	roleID     id.RoleID
+	permission string // the stored "resource:action"
+}
+
+// matchRolePermissions returns the permissions of roles that grant
+// permName, in role order. With first set it stops at the first grant.
+func (e *Engine) matchRolePermissions(ctx context.Context, roles []id.RoleID, permName string, first bool) []rbacGrant {
+	var grants []rbacGrant
+	for _, roleID := range roles {
+		perms, err := traceStore(ctx, "ListRolePermissions", func(ctx context.Context) ([]*permission.Permission, error) {
+			return e.store.ListRolePermissions(ctx, roleID)
+		})
 		if err != nil {
 			e.logger.Warn("warden: rbac ListRolePermissions error",
 				log.String("role_id", roleID.String()),
@@ -311,33 +575,61 @@ func (e *Engine) evaluateRBAC(ctx context.Context, scope tenantScope, req *Check
 			)
 
 			if matched {
-				return &CheckResult{
-					Allowed:  true,
-					Decision: DecisionAllow,
-					MatchedBy: []MatchInfo{{
-						Source: "rbac",
-						RuleID: roleID.String(),
-						Detail: "role grants " + storedPerm,
-					}},
-				}, nil
+				grants = append(grants, rbacGrant{roleID: roleID, permission: storedPerm})
+				if first {
+					return grants
+				}
 			}
 		}
 	}
-
-	return &CheckResult{Decision: DecisionDenyNoPerms, Reason: fmt.Sprintf("no role grants permission %q for subject %s:%s", permName, req.Subject.Kind, req.Subject.ID)}, nil
+	return grants
 }
 
 func (e *Engine) resolveInheritedRoles(ctx context.Context, roleIDs []id.RoleID) []id.RoleID {
+	return newRoleLoader(e).inherited(ctx, roleIDs)
+}
+
+// roleLoader reads each role of a check from the store at most once.
+type roleLoader struct {
+	e     *Engine
+	roles map[string]roleLoad // by role ID
+}
+
+type roleLoad struct {
+	r   *role.Role
+	err error
+}
+
+func newRoleLoader(e *Engine) *roleLoader {
+	return &roleLoader{e: e, roles: make(map[string]roleLoad)}
+}
+
+// get returns the role, loading it on first use.
+func (l *roleLoader) get(ctx context.Context, roleID id.RoleID) (*role.Role, error) {
+	key := roleID.String()
+	if ld, ok := l.roles[key]; ok {
+		return ld.r, ld.err
+	}
+	r, err := traceStore(ctx, "GetRole", func(ctx context.Context) (*role.Role, error) {
+		return l.e.store.GetRole(ctx, roleID)
+	})
+	l.roles[key] = roleLoad{r: r, err: err}
+	return r, err
+}
+
+// inherited returns roleIDs followed by the roles they inherit through
+// ParentSlug, each once.
+func (l *roleLoader) inherited(ctx context.Context, roleIDs []id.RoleID) []id.RoleID {
 	seen := make(map[string]struct{}, len(roleIDs))
 	result := make([]id.RoleID, 0, len(roleIDs)*2)
 
 	for _, rid := range roleIDs {
-		e.walkRoleParents(ctx, rid, seen, &result, 0)
+		l.walkParents(ctx, rid, seen, &result, 0)
 	}
 	return result
 }
 
-func (e *Engine) walkRoleParents(ctx context.Context, roleID id.RoleID, seen map[string]struct{}, result *[]id.RoleID, depth int) {
+func (l *roleLoader) walkParents(ctx context.Context, roleID id.RoleID, seen map[string]struct{}, result *[]id.RoleID, depth int) {
 	key := roleID.String()
 	if _, ok := seen[key]; ok {
 		return
@@ -348,21 +640,28 @@ func (e *Engine) walkRoleParents(ctx context.Context, roleID id.RoleID, seen map
 	seen[key] = struct{}{}
 	*result = append(*result, roleID)
 
-	r, err := e.store.GetRole(ctx, roleID)
+	r, err := l.get(ctx, roleID)
 	if err != nil || r == nil || r.ParentSlug == "" {
 		return
 	}
-	parent, err := e.store.GetRoleBySlug(ctx, r.TenantID, r.NamespacePath, r.ParentSlug)
+	parent, err := traceStore(ctx, "GetRoleBySlug", func(ctx context.Context) (*role.Role, error) {
+		return l.e.store.GetRoleBySlug(ctx, r.TenantID, r.NamespacePath, r.ParentSlug)
+	})
 	if err != nil || parent == nil {
 		return
 	}
-	e.walkRoleParents(ctx, parent.ID, seen, result, depth+1)
+	if _, ok := l.roles[parent.ID.String()]; !ok {
+		l.roles[parent.ID.String()] = roleLoad{r: parent}
+	}
+	l.walkParents(ctx, parent.ID, seen, result, depth+1)
 }
 
 func (e *Engine) evaluateReBAC(ctx context.Context, scope tenantScope, req *CheckRequest) (*CheckResult, error) {
 	// Direct relation check. Relations cascade like roles/policies: a tuple at
 	// an ancestor namespace is in scope for a check at a descendant namespace.
-	direct, err := e.store.CheckDirectRelation(ctx, scope.tenantID, AncestorNamespaces(scope.namespacePath), req.Resource.Type, req.Resource.ID, req.Action.Name, string(req.Subject.Kind), req.Subject.ID)
+	direct, err := traceStore(ctx, "CheckDirectRelation", func(ctx context.Context) (bool, error) {
+		return e.store.CheckDirectRelation(ctx, scope.tenantID, AncestorNamespaces(scope.namespacePath), req.Resource.Type, req.Resource.ID, req.Action.Name, string(req.Subject.Kind), req.Subject.ID)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -412,12 +711,22 @@ func (e *Engine) evaluateReBAC(ctx context.Context, scope tenantScope, req *Chec
 
 func (e *Engine) evaluateABAC(ctx context.Context, scope tenantScope, req *CheckRequest) (*CheckResult, error) {
 	// Policies cascade: include policies at the request's namespace and every ancestor.
-	namespaces := AncestorNamespaces(scope.namespacePath)
-	policies, err := e.store.ListActivePolicies(ctx, scope.tenantID, namespaces)
+	policies, active, err := e.activePolicies(ctx, scope, req)
 	if err != nil {
 		return nil, err
 	}
-	return e.evaluator.Evaluate(ctx, policies, req)
+	SpanFromContext(ctx).SetAttributes(Attr(AttrPolicyCount, len(policies)))
+	return e.evaluatePolicies(ctx, scope, policies, active, req)
+}
+
+// evaluatePolicies evaluates policies against req, reading attributes
+// through the resource type schemas of scope unless the scope has no
+// active policies.
+func (e *Engine) evaluatePolicies(ctx context.Context, scope tenantScope, policies []*policy.Policy, active int, req *CheckRequest) (*CheckResult, error) {
+	if active == 0 {
+		return e.evaluator.Evaluate(ctx, policies, req)
+	}
+	return e.evaluator.Evaluate(e.withAttributeSchemas(ctx, scope), policies, req)
 }
 
 func (e *Engine) mergeDecisions(req *CheckRequest, rbac, rebac, abac *CheckResult) *CheckResult {
@@ -425,11 +734,32 @@ func (e *Engine) mergeDecisions(req *CheckRequest, rbac, rebac, abac *CheckResul
 	// the final result regardless of which decision wins. Side-effect
 	// signals are independent of the allow/deny outcome.
 	obligations := mergeObligations(rbac, rebac, abac)
+	structured, advice := mergeStructuredObligations(rbac, rebac, abac)
+
+	out := pickDecision(req, rbac, rebac, abac)
+	out.Obligations = obligations
+	out.StructuredObligations, out.Advice = structured, advice
+	if abac != nil {
+		out.Shadow = divergentShadow(out, abac.Shadow)
+		out.Errors = abac.Errors
+	}
+	return out
+}
 
-	// Explicit deny (from ABAC) always wins.
-	if abac != nil && abac.Decision == DecisionDenyExplicit {
+// pickDecision returns a copy of the winning result: explicit deny,
+// indeterminate or a separation-of-duties deny > allow > the most
+// informative default deny.
+func pickDecision(req *CheckRequest, rbac, rebac, abac *CheckResult) *CheckResult {
+	// Explicit deny (from ABAC) always wins. So does an ABAC stage that
+	// could not evaluate, since a policy it skipped may have denied.
+	if abac != nil && (abac.Decision == DecisionDenyExplicit || abac.Decision == DecisionIndeterminate) {
 		out := *abac
-		out.Obligations = obligations
+		return &out
+	}
+	// A session that violates dynamic separation of duties is denied
+	// outright, whatever ReBAC or ABAC would allow.
+	if rbac != nil && rbac.Decision == DecisionDenySoD {
+		out := *rbac
 		return &out
 	}
 
@@ -437,7 +767,6 @@ func (e *Engine) mergeDecisions(req *CheckRequest, rbac, rebac, abac *CheckResul
 	for _, r := range []*CheckResult{rbac, rebac, abac} {
 		if r != nil && r.Allowed {
 			out := *r
-			out.Obligations = obligations
 			return &out
 		}
 	}
@@ -446,15 +775,13 @@ func (e *Engine) mergeDecisions(req *CheckRequest, rbac, rebac, abac *CheckResul
 	for _, r := range []*CheckResult{rbac, rebac, abac} {
 		if r != nil && r.Reason != "" {
 			out := *r
-			out.Obligations = obligations
 			return &out
 		}
 	}
 
 	return &CheckResult{
-		Decision:    DecisionDenyDefault,
-		Reason:      fmt.Sprintf("no rule allows %s:%s to %s on %s:%s", req.Subject.Kind, req.Subject.ID, req.Action.Name, req.Resource.Type, req.Resource.ID),
-		Obligations: obligations,
+		Decision: DecisionDenyDefault,
+		Reason:   fmt.Sprintf("no rule allows %s:%s to %s on %s:%s", req.Subject.Kind, req.Subject.ID, req.Action.Name, req.Resource.Type, req.Resource.ID),
 	}
 }
 
@@ -469,6 +796,17 @@ func mergeObligations(results ...*CheckResult) []string {
 	return dedupeStrings(all)
 }
 
+func mergeStructuredObligations(results ...*CheckResult) (obligations, advice []Obligation) {
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		obligations = append(obligations, r.StructuredObligations...)
+		advice = append(advice, r.Advice...)
+	}
+	return obligations, advice
+}
+
 // policyIDFromMatched returns the first ABAC policy ID in matchedBy, or
 // the zero value if none is present. Used to attach provenance to
 // PolicyObligationFired events; consumers needing full provenance can
@@ -8,6 +8,7 @@ import (
 	"github.com/xraph/warden/assignment"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/role"
 	"github.com/xraph/warden/store/memory"
 )
@@ -182,3 +183,62 @@ func BenchmarkRoleInheritance(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkABACPolicyCount measures an ABAC Check in a tenant with N active
+// policies, each on its own resource type and carrying a regex and a CIDR
+// condition. With the policy index only the matching policy is evaluated,
+// so time should stay flat in N; with the index disabled every check lists
+// and scans all N policies.
+func BenchmarkABACPolicyCount(b *testing.B) {
+	for _, indexed := range []bool{true, false} {
+		for _, n := range []int{10, 100, 1000, 10000} {
+			name := "index/N=" + strconv.Itoa(n)
+			cfg := Config{EnableCheckLog: new(bool)}
+			if !indexed {
+				name = "store/N=" + strconv.Itoa(n)
+				cfg.PolicyIndexTTL = -1
+			}
+			b.Run(name, func(b *testing.B) {
+				ctx := WithTenant(context.Background(), "app1", "t1")
+				s := memory.New()
+				eng, err := NewEngine(WithStore(s), WithConfig(cfg))
+				if err != nil {
+					b.Fatal(err)
+				}
+				for i := 0; i < n; i++ {
+					_ = s.CreatePolicy(ctx, &policy.Policy{
+						ID: id.NewPolicyID(), TenantID: "t1", Name: "p" + strconv.Itoa(i),
+						Effect: policy.EffectAllow, IsActive: true,
+						Actions: []string{"read"}, Resources: []string{"type" + strconv.Itoa(i) + ":*"},
+						Conditions: []policy.Condition{
+							{Field: "subject.email", Operator: policy.OpRegex, Value: `@example\.com$`},
+							{Field: "context.ip", Operator: policy.OpIPInCIDR, Value: "10.0.0.0/8"},
+						},
+					})
+				}
+				req := &CheckRequest{
+					Subject:  Subject{Kind: SubjectUser, ID: "u1", Attributes: map[string]any{"email": "u1@example.com"}},
+					Action:   Action{Name: "read"},
+					Resource: Resource{Type: "type" + strconv.Itoa(n/2), ID: "r1"},
+					Context:  map[string]any{"ip": "10.1.2.3"},
+				}
+				// Warm the index so its one-off build is not measured.
+				if _, err := eng.Check(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					result, err := eng.Check(ctx, req)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if !result.Allowed {
+						b.Fatalf("expected allow at N=%d, got %s", n, result.Decision)
+					}
+				}
+			})
+		}
+	}
+}
@@ -3,15 +3,19 @@ package warden
 import (
 	"context"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/checklog"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/relation"
+	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/store"
 	"github.com/xraph/warden/store/memory"
 )
 
@@ -587,6 +591,364 @@ func TestABACTimeCondition(t *testing.T) {
 	}
 }
 
+func TestABACAttributeReference(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "owner-and-department",
+		Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"*"},
+		Conditions: []policy.Condition{
+			{Field: "resource.owner_id", Operator: policy.OpEquals, ValueRef: "subject.id"},
+			{Field: "subject.department", Operator: policy.OpEquals, ValueRef: "resource.department"},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		subject   Subject
+		resAttrs  map[string]any
+		wantAllow bool
+	}{
+		{"owner in same department", Subject{Kind: SubjectUser, ID: "u1", Attributes: map[string]any{"department": "eng"}}, map[string]any{"owner_id": "u1", "department": "eng"}, true},
+		{"not the owner", Subject{Kind: SubjectUser, ID: "u2", Attributes: map[string]any{"department": "eng"}}, map[string]any{"owner_id": "u1", "department": "eng"}, false},
+		{"other department", Subject{Kind: SubjectUser, ID: "u1", Attributes: map[string]any{"department": "sales"}}, map[string]any{"owner_id": "u1", "department": "eng"}, false},
+		{"both departments missing", Subject{Kind: SubjectUser, ID: "u1"}, map[string]any{"owner_id": "u1"}, false},
+		{"literal field name is not a match", Subject{Kind: SubjectUser, ID: "subject.id", Attributes: map[string]any{"department": "eng"}}, map[string]any{"owner_id": "subject.id", "department": "eng"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eng.Check(ctx, &CheckRequest{
+				Subject:  tt.subject,
+				Action:   Action{Name: "read"},
+				Resource: Resource{Type: "doc", ID: "d1", Attributes: tt.resAttrs},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Allowed != tt.wantAllow {
+				t.Fatalf("allowed = %v, want %v (%s: %s)", result.Allowed, tt.wantAllow, result.Decision, result.Reason)
+			}
+		})
+	}
+}
+
+func TestABACExpressionCondition(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "senior-gold",
+		Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"*"},
+		Conditions: []policy.Condition{
+			{Expression: `size(subject.groups) >= 2 && context.request.headers["x-tier"] in ["gold", "platinum"]`},
+		},
+	})
+
+	check := func(subjectAttrs, reqCtx map[string]any) bool {
+		t.Helper()
+		result, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1", Attributes: subjectAttrs},
+			Action:   Action{Name: "read"},
+			Resource: Resource{Type: "doc", ID: "d1"},
+			Context:  reqCtx,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Allowed
+	}
+	gold := map[string]any{"request": map[string]any{"headers": map[string]string{"x-tier": "gold"}}}
+	if !check(map[string]any{"groups": []string{"a", "b"}}, gold) {
+		t.Error("expected allow for two groups on the gold tier")
+	}
+	if check(map[string]any{"groups": []string{"a"}}, gold) {
+		t.Error("expected deny for one group")
+	}
+	if check(map[string]any{"groups": []string{"a", "b"}}, nil) {
+		t.Error("expected deny when the header is absent")
+	}
+
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "broken",
+		Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"write"},
+		Conditions: []policy.Condition{
+			{Expression: `subject.id >`},
+		},
+	})
+	result, err := eng.Check(ctx, &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "write"},
+		Resource: Resource{Type: "doc", ID: "d1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decision != DecisionIndeterminate || len(result.Errors) != 1 {
+		t.Errorf("got %s with errors %v, want indeterminate with one error", result.Decision, result.Errors)
+	}
+}
+
+func TestABACExpressionRuntimeError(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "allow-read",
+		Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"},
+	})
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "deny-low-level",
+		Effect: policy.EffectDeny, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Expression: `subject.level < 3`},
+		},
+	})
+
+	check := func(attrs map[string]any) *CheckResult {
+		t.Helper()
+		result, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1", Attributes: attrs},
+			Action:   Action{Name: "read"},
+			Resource: Resource{Type: "doc", ID: "d1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// An absent attribute is not an error: the deny does not hold.
+	if r := check(nil); !r.Allowed || len(r.Errors) != 0 {
+		t.Errorf("absent attribute: got %s with errors %v, want allow", r.Decision, r.Errors)
+	}
+	// A type mismatch must not silently drop the deny.
+	r := check(map[string]any{"level": "three"})
+	if r.Decision != DecisionIndeterminate || len(r.Errors) != 1 || r.Errors[0].PolicyName != "deny-low-level" {
+		t.Errorf("type mismatch: got %s with errors %v, want indeterminate", r.Decision, r.Errors)
+	}
+}
+
+func TestABACPolicyErrorIsolation(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "allow-read",
+		Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"},
+	})
+	// Stored without write-time validation, as by an older version.
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "bad-regex",
+		Effect: policy.EffectDeny, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Field: "subject.id", Operator: policy.OpRegex, Value: "("},
+		},
+	})
+
+	check := func() *CheckResult {
+		t.Helper()
+		result, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: "read"},
+			Resource: Resource{Type: "doc", ID: "d1"},
+		})
+		if err != nil {
+			t.Fatalf("a broken policy must not fail the check: %v", err)
+		}
+		return result
+	}
+
+	r := check()
+	if r.Allowed || r.Decision != DecisionIndeterminate {
+		t.Errorf("on_error=deny: got %s (allowed=%v), want indeterminate", r.Decision, r.Allowed)
+	}
+	if len(r.Errors) != 1 || r.Errors[0].PolicyName != "bad-regex" || r.Errors[0].OnError != policy.OnErrorDeny {
+		t.Errorf("errors = %+v", r.Errors)
+	}
+
+	pols, _ := s.ListPolicies(ctx, &policy.ListFilter{TenantID: "t1"})
+	for _, p := range pols {
+		if p.Name == "bad-regex" {
+			p.OnError = policy.OnErrorSkip
+			_ = s.UpdatePolicy(ctx, p)
+		}
+	}
+	r = check()
+	if !r.Allowed {
+		t.Errorf("on_error=skip: got %s, want allow", r.Decision)
+	}
+	if len(r.Errors) != 1 || r.Errors[0].OnError != policy.OnErrorSkip {
+		t.Errorf("errors = %+v, want the skipped policy reported", r.Errors)
+	}
+
+	// Check logs are written asynchronously.
+	var entries []*checklog.Entry
+	for range 100 {
+		entries, _ = s.ListCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"}) //nolint:errcheck // polled
+		if len(entries) == 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for _, e := range entries {
+		if logged, ok := e.Metadata[checklog.MetadataErrors].([]PolicyError); !ok || len(logged) != 1 {
+			t.Errorf("check log metadata = %+v", e.Metadata)
+		}
+	}
+	if len(entries) != 2 {
+		t.Errorf("check logs = %d, want 2", len(entries))
+	}
+}
+
+func TestABACAttributeSchema(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	_ = s.CreateResourceType(ctx, &resourcetype.ResourceType{
+		ID: id.NewResourceTypeID(), TenantID: "t1", Name: "doc",
+		Attributes: []resourcetype.AttributeDef{
+			{Name: "size", Type: resourcetype.AttrInt, Required: true},
+			{Name: "tier", Type: resourcetype.AttrString, Enum: []string{"free", "pro"}},
+			{Name: "owner", Type: resourcetype.AttrString, Required: true},
+		},
+	})
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "large-docs",
+		Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Field: "resource.size", Operator: policy.OpGreaterThan, Value: 100},
+		},
+	})
+	_ = s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "no-free-tier",
+		Effect: policy.EffectDeny, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Expression: `exists(resource.tier) && resource.tier == "free"`},
+		},
+	})
+
+	check := func(attrs map[string]any) *CheckResult {
+		t.Helper()
+		result, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: "read"},
+			Resource: Resource{Type: "doc", ID: "d1", Attributes: attrs},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// A numeric string is coerced to the declared int, and the required
+	// owner is not needed because no policy reads it.
+	if r := check(map[string]any{"size": "250"}); !r.Allowed {
+		t.Errorf("expected allow for a coercible size, got %s: %s", r.Decision, r.Reason)
+	}
+	tests := []struct {
+		name   string
+		attrs  map[string]any
+		policy string
+	}{
+		{"wrong type", map[string]any{"size": "large"}, "large-docs"},
+		{"missing required", map[string]any{"tier": "pro"}, "large-docs"},
+		{"outside enum", map[string]any{"size": 250, "tier": "gold"}, "no-free-tier"},
+	}
+	for _, tt := range tests {
+		r := check(tt.attrs)
+		if r.Allowed || r.Decision != DecisionIndeterminate {
+			t.Errorf("%s: got %s (allowed=%v), want indeterminate", tt.name, r.Decision, r.Allowed)
+		}
+		if len(r.Errors) != 1 || r.Errors[0].PolicyName != tt.policy {
+			t.Errorf("%s: errors = %+v, want one from %s", tt.name, r.Errors, tt.policy)
+		}
+	}
+
+	// A coercion error follows the on_error of the policy that reads it.
+	pols, _ := s.ListPolicies(ctx, &policy.ListFilter{TenantID: "t1"})
+	for _, p := range pols {
+		if p.Name == "no-free-tier" {
+			p.OnError = policy.OnErrorSkip
+			_ = eng.Store().UpdatePolicy(ctx, p)
+		}
+	}
+	if r := check(map[string]any{"size": 250, "tier": "gold"}); !r.Allowed {
+		t.Errorf("on_error=skip: got %s, want allow", r.Decision)
+	}
+}
+
+func TestABACAttributeSchemaCached(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := &countingResourceTypeStore{Store: memory.New()}
+	eng, err := NewEngine(WithStore(s), WithConfig(Config{PolicyIndexTTL: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = eng.Store().CreateResourceType(ctx, &resourcetype.ResourceType{
+		ID: id.NewResourceTypeID(), TenantID: "t1", Name: "doc",
+		Attributes: []resourcetype.AttributeDef{{Name: "size", Type: resourcetype.AttrInt}},
+	})
+	_ = eng.Store().CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "large-docs",
+		Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Field: "resource.size", Operator: policy.OpGreaterThan, Value: 100},
+		},
+	})
+	check := func(size any) bool {
+		t.Helper()
+		result, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: "read"},
+			Resource: Resource{Type: "doc", ID: "d1", Attributes: map[string]any{"size": size}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Allowed
+	}
+
+	for range 3 {
+		if !check("250") {
+			t.Fatal("expected allow for a coercible size")
+		}
+	}
+	if n := s.lookups.Load(); n != 1 {
+		t.Errorf("resource type lookups = %d, want 1", n)
+	}
+
+	// Changing a resource type through the engine drops the cached schema.
+	rt, _ := eng.Store().GetResourceTypeByName(ctx, "t1", "", "doc")
+	rt.Attributes[0].Type = resourcetype.AttrBool
+	_ = eng.Store().UpdateResourceType(ctx, rt)
+	if check("250") {
+		t.Error("expected the updated schema to reject an int size")
+	}
+}
+
+// countingResourceTypeStore counts resource type lookups by name.
+type countingResourceTypeStore struct {
+	store.Store
+	lookups atomic.Int64
+}
+
+func (s *countingResourceTypeStore) GetResourceTypeByName(ctx context.Context, tenantID, namespacePath, name string) (*resourcetype.ResourceType, error) {
+	s.lookups.Add(1)
+	return s.Store.GetResourceTypeByName(ctx, tenantID, namespacePath, name)
+}
+
 func TestCheckWithTenantOverride(t *testing.T) {
 	// Context has tenant "t1", but CheckRequest overrides to "t2".
 	ctx := WithTenant(context.Background(), "app1", "t1")
@@ -735,3 +1097,48 @@ func TestCheck_DenyNoRolesReasonIncludesSubject(t *testing.T) {
 		t.Fatal("expected non-empty reason")
 	}
 }
+
+func TestCheckLog_RecordsProvenance(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	ctx = WithRequestInfo(ctx, "203.0.113.7", "req-42")
+	eng, s := newTestEngine(t)
+
+	roleID := id.NewRoleID()
+	_ = s.CreateRole(ctx, &role.Role{ID: roleID, TenantID: "t1", Name: "editor", Slug: "editor"})
+	_ = s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "document:read", Resource: "document", Action: "read"})
+	_ = s.AttachPermission(ctx, roleID, permission.Ref{Name: "document:read"})
+	_ = s.CreateAssignment(ctx, &assignment.Assignment{TenantID: "t1", RoleID: roleID, SubjectKind: "user", SubjectID: "u1"})
+
+	_, err := eng.Check(ctx, &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1", Attributes: map[string]any{"dept": "eng"}},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "document", ID: "doc1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check logs are written asynchronously.
+	var entries []*checklog.Entry
+	for range 100 {
+		entries, _ = s.ListCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1", RequestID: "req-42"}) //nolint:errcheck // polled
+		if len(entries) > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 check log entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.RequestIP != "203.0.113.7" {
+		t.Errorf("RequestIP = %q", e.RequestIP)
+	}
+	if len(e.MatchedBy) != 1 || e.MatchedBy[0].Source != "rbac" || e.MatchedBy[0].RuleID != roleID.String() {
+		t.Errorf("MatchedBy = %+v", e.MatchedBy)
+	}
+	attrs, ok := e.Metadata[checklog.MetadataSubjectAttributes].(map[string]any)
+	if !ok || attrs["dept"] != "eng" {
+		t.Errorf("Metadata = %+v", e.Metadata)
+	}
+}
@@ -28,6 +28,13 @@ var (
 	// ErrResourceTypeNotFound is returned when a resource type cannot be found.
 	ErrResourceTypeNotFound = errors.New("warden: resource type not found")
 
+	// ErrCalendarNotFound is returned when a calendar cannot be found.
+	ErrCalendarNotFound = errors.New("warden: calendar not found")
+
+	// ErrSoDConstraintNotFound is returned when a separation-of-duties
+	// constraint cannot be found.
+	ErrSoDConstraintNotFound = errors.New("warden: separation-of-duties constraint not found")
+
 	// ErrSystemRoleImmutable is returned when trying to modify a system role.
 	ErrSystemRoleImmutable = errors.New("warden: system role cannot be modified")
 
@@ -66,15 +73,40 @@ var (
 	// Wraps ErrAlreadyExists.
 	ErrDuplicateRelation = wardenerr.ErrDuplicateRelation
 
+	// ErrDuplicateCalendar is returned when a calendar would violate the
+	// (tenant_id, name) uniqueness constraint.
+	ErrDuplicateCalendar = wardenerr.ErrDuplicateCalendar
+
+	// ErrDuplicateSoDConstraint is returned when a separation-of-duties
+	// constraint would violate the (tenant_id, name) uniqueness constraint.
+	ErrDuplicateSoDConstraint = wardenerr.ErrDuplicateSoDConstraint
+
 	// ErrCyclicRoleInheritance is returned when role inheritance would create a cycle.
 	ErrCyclicRoleInheritance = errors.New("warden: cyclic role inheritance detected")
 
-	// ErrMaxMembersExceeded is returned when a role's member limit is reached.
-	ErrMaxMembersExceeded = errors.New("warden: role max members exceeded")
+	// ErrNotFound is wrapped by the errors the bundled stores return for
+	// an entity that does not exist.
+	ErrNotFound = wardenerr.ErrNotFound
+
+	// ErrMaxMembersExceeded is returned when an assignment would give a
+	// role more members than its MaxMembers.
+	ErrMaxMembersExceeded = wardenerr.ErrMaxMembersExceeded
+
+	// ErrSoDViolation is returned when an assignment would give a subject
+	// more roles of a static separation-of-duties constraint than it allows.
+	ErrSoDViolation = wardenerr.ErrSoDViolation
 
 	// ErrInvalidCondition is returned when a policy condition is malformed.
 	ErrInvalidCondition = errors.New("warden: invalid policy condition")
 
 	// ErrGraphDepthExceeded is returned when the relation graph walk exceeds max depth.
 	ErrGraphDepthExceeded = errors.New("warden: relation graph depth exceeded")
+
+	// ErrBreakGlassNotAllowed is returned when a break-glass request is
+	// rejected by the break-glass configuration.
+	ErrBreakGlassNotAllowed = errors.New("warden: break-glass not allowed")
+
+	// ErrBreakGlassInactive is returned when revoking a break-glass grant
+	// that has already expired or been revoked.
+	ErrBreakGlassInactive = errors.New("warden: break-glass grant is not active")
 )
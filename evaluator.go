@@ -2,12 +2,17 @@ package warden
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/xraph/warden/expr"
 	"github.com/xraph/warden/policy"
 )
 
@@ -27,14 +32,15 @@ func NewConditionEvaluator(now func() time.Time) Evaluator {
 	if now == nil {
 		now = time.Now
 	}
-	return &conditionEvaluator{now: now}
+	return &conditionEvaluator{now: now, exprs: expr.NewCache(nil)}
 }
 
 type conditionEvaluator struct {
-	now func() time.Time
+	now   func() time.Time
+	exprs *expr.Cache
 }
 
-func (e *conditionEvaluator) Evaluate(_ context.Context, policies []*policy.Policy, req *CheckRequest) (*CheckResult, error) {
+func (e *conditionEvaluator) Evaluate(ctx context.Context, policies []*policy.Policy, req *CheckRequest) (*CheckResult, error) {
 	if len(policies) == 0 {
 		return nil, nil
 	}
@@ -46,7 +52,11 @@ func (e *conditionEvaluator) Evaluate(_ context.Context, policies []*policy.Poli
 
 	var bestDeny *CheckResult
 	var bestAllow *CheckResult
+	var indeterminate *CheckResult
 	var allObligations []string
+	var structured, advice []Obligation
+	var shadow []ShadowDecision
+	var errs []PolicyError
 
 	for _, pol := range policies {
 		if !pol.EffectiveAt(now) {
@@ -63,17 +73,43 @@ func (e *conditionEvaluator) Evaluate(_ context.Context, policies []*policy.Poli
 			continue
 		}
 
-		conditionsMet, err := e.evaluateConditions(pol.Conditions, req)
+		conditionsMet, err := e.evaluateConditions(ctx, pol.Conditions, req, now)
 		if err != nil {
-			return nil, fmt.Errorf("evaluate conditions for policy %s: %w", pol.Name, err)
+			// A policy that cannot be evaluated is isolated: it is
+			// reported and, unless it opts to be skipped, makes the
+			// check indeterminate. Shadow policies never affect the
+			// decision.
+			pe := newPolicyError(pol, err)
+			errs = append(errs, pe)
+			if pe.OnError == policy.OnErrorDeny && pol.Mode != policy.ModeShadow && indeterminate == nil {
+				indeterminate = &CheckResult{
+					Decision: DecisionIndeterminate,
+					Reason:   fmt.Sprintf("indeterminate: policy %q could not be evaluated: %v", pol.Name, err),
+					MatchedBy: []MatchInfo{{
+						Source: "abac",
+						RuleID: pol.ID.String(),
+						Detail: fmt.Sprintf("policy %q (error)", pol.Name),
+					}},
+				}
+			}
+			continue
 		}
 		if !conditionsMet {
 			continue
 		}
 
+		// Shadow policies are evaluated in full but only report what they
+		// would have done; they never contribute effects or obligations.
+		if pol.Mode == policy.ModeShadow {
+			shadow = append(shadow, newShadowDecision(pol))
+			continue
+		}
+
 		// Obligations fire on every matched policy, regardless of effect.
 		// They are side-effect signals; the calling system decides what to do.
 		allObligations = append(allObligations, pol.Obligations...)
+		structured = append(structured, resolveObligations(ctx, pol, pol.StructuredObligations, req)...)
+		advice = append(advice, resolveObligations(ctx, pol, pol.Advice, req)...)
 
 		info := MatchInfo{
 			Source: "abac",
@@ -103,20 +139,43 @@ func (e *conditionEvaluator) Evaluate(_ context.Context, policies []*policy.Poli
 		}
 	}
 
-	// Explicit deny always wins over allow. Obligations from every matched
-	// policy (allow OR deny) flow through to the caller.
-	if bestDeny != nil {
-		bestDeny.Obligations = dedupeStrings(allObligations)
-		return bestDeny, nil
+	// Explicit deny always wins, then indeterminate, then allow.
+	// Obligations from every matched policy (allow OR deny) flow through
+	// to the caller.
+	for _, result := range []*CheckResult{bestDeny, indeterminate, bestAllow} {
+		if result != nil {
+			result.Obligations = dedupeStrings(allObligations)
+			result.StructuredObligations, result.Advice = structured, advice
+			result.Shadow = shadow
+			result.Errors = errs
+			return result, nil
+		}
 	}
-	if bestAllow != nil {
-		bestAllow.Obligations = dedupeStrings(allObligations)
-		return bestAllow, nil
+	if len(shadow) > 0 || len(errs) > 0 {
+		// Only shadow or skipped policies matched: the enforced outcome
+		// is "no opinion", which the engine merges as a default deny.
+		return &CheckResult{Decision: DecisionDenyDefault, Shadow: shadow, Errors: errs}, nil
 	}
 
 	return nil, nil
 }
 
+// PolicyError records a policy that could not be evaluated during a check.
+type PolicyError struct {
+	PolicyID   string         `json:"policy_id"`
+	PolicyName string         `json:"policy_name"`
+	Error      string         `json:"error"`
+	OnError    policy.OnError `json:"on_error"`
+}
+
+func newPolicyError(pol *policy.Policy, err error) PolicyError {
+	onErr := pol.OnError
+	if onErr == "" {
+		onErr = policy.OnErrorDeny
+	}
+	return PolicyError{PolicyID: pol.ID.String(), PolicyName: pol.Name, Error: err.Error(), OnError: onErr}
+}
+
 // dedupeStrings returns a new slice preserving first-occurrence order. Used
 // for merging obligations from many matched policies; obligations are
 // idempotent by name (the consumer dedupes the action it triggers).
@@ -181,10 +240,56 @@ func (e *conditionEvaluator) matchesResource(pol *policy.Policy, req *CheckReque
 	return false
 }
 
-func (e *conditionEvaluator) evaluateConditions(conditions []policy.Condition, req *CheckRequest) (bool, error) {
+func (e *conditionEvaluator) evaluateConditions(ctx context.Context, conditions []policy.Condition, req *CheckRequest, now time.Time) (bool, error) {
 	for _, c := range conditions {
-		val := resolveField(c.Field, req)
-		ok, err := evaluateCondition(c.Operator, val, c.Value)
+		if c.Expression != "" {
+			ok, err := e.evaluateExpression(ctx, c.Expression, req, now)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				return false, nil
+			}
+			continue
+		}
+		if c.Operator.IsSchedule() {
+			ok, err := evaluateSchedule(ctx, c, req, now)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				return false, nil
+			}
+			continue
+		}
+		ref, isRate, err := policy.ParseRate(c.Field)
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrInvalidCondition, err)
+		}
+		var val any
+		if isRate {
+			n, err := evaluateRate(ctx, ref, req, now)
+			if err != nil {
+				return false, err
+			}
+			val = n
+		} else if val, err = LookupAttribute(ctx, c.Field, req); err != nil {
+			return false, err
+		}
+		expected := c.Value
+		if c.ValueRef != "" {
+			if expected, err = LookupAttribute(ctx, c.ValueRef, req); err != nil {
+				return false, err
+			}
+			// Two missing attributes must not compare equal.
+			if expected == nil || val == nil {
+				return false, nil
+			}
+		}
+		ok, compiled := evaluateCompiled(c, val)
+		if !compiled {
+			ok, err = evaluateCondition(c.Operator, val, expected)
+		}
 		if err != nil {
 			return false, err
 		}
@@ -195,42 +300,115 @@ func (e *conditionEvaluator) evaluateConditions(conditions []policy.Condition, r
 	return true, nil
 }
 
-func resolveField(field string, req *CheckRequest) any {
+// evaluateExpression evaluates an expression condition. An expression
+// that reads an absent attribute does not hold. A malformed expression is
+// an ErrInvalidCondition, and any other failure at evaluation, such as a
+// type mismatch or a failed attribute lookup, is returned so the policy's
+// on_error applies.
+func (e *conditionEvaluator) evaluateExpression(ctx context.Context, src string, req *CheckRequest, now time.Time) (bool, error) {
+	prog, err := e.exprs.Compile(src)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidCondition, err)
+	}
+	var lookupErr error
+	ok, err := prog.Eval(requestActivation{ctx: ctx, req: req, now: now, err: &lookupErr})
+	switch {
+	case lookupErr != nil:
+		return false, lookupErr
+	case errors.Is(err, expr.ErrNoSuchAttribute):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("expression %q: %w", src, err)
+	}
+	return ok, nil
+}
+
+// requestActivation exposes a check request to expressions, including
+// attributes supplied by AttributeProvider plugins.
+type requestActivation struct {
+	ctx context.Context
+	req *CheckRequest
+	now time.Time
+	err *error // first failed attribute lookup
+}
+
+func (a requestActivation) Lookup(name string) (any, bool) {
+	if name == "now" {
+		return a.now, true
+	}
+	v, err := LookupAttribute(a.ctx, name, a.req)
+	if err != nil && *a.err == nil {
+		*a.err = err
+	}
+	return v, v != nil
+}
+
+// resolveField looks field up on the request. missing reports a subject,
+// resource or context attribute the request did not carry, which an
+// AttributeProvider plugin may still supply.
+func resolveField(field string, req *CheckRequest) (value any, missing bool) {
 	parts := strings.SplitN(field, ".", 2)
 	if len(parts) < 2 {
-		return nil
+		return nil, false
 	}
+	var attrs map[string]any
 	switch parts[0] {
 	case "subject":
 		if parts[1] == "kind" {
-			return string(req.Subject.Kind)
+			return string(req.Subject.Kind), false
 		}
 		if parts[1] == "id" {
-			return req.Subject.ID
-		}
-		if req.Subject.Attributes != nil {
-			return req.Subject.Attributes[parts[1]]
+			return req.Subject.ID, false
 		}
+		attrs = req.Subject.Attributes
 	case "resource":
 		if parts[1] == "type" {
-			return req.Resource.Type
+			return req.Resource.Type, false
 		}
 		if parts[1] == "id" {
-			return req.Resource.ID
-		}
-		if req.Resource.Attributes != nil {
-			return req.Resource.Attributes[parts[1]]
+			return req.Resource.ID, false
 		}
+		attrs = req.Resource.Attributes
 	case "action":
 		if parts[1] == "name" {
-			return req.Action.Name
+			return req.Action.Name, false
 		}
+		return nil, false
 	case "context":
-		if req.Context != nil {
-			return req.Context[parts[1]]
+		attrs = req.Context
+	default:
+		return nil, false
+	}
+	v, ok := attrs[parts[1]]
+	return v, !ok
+}
+
+// evaluateCompiled evaluates a condition whose value the policy index has
+// pre-parsed. compiled is false when c was not compiled.
+func evaluateCompiled(c policy.Condition, actual any) (ok, compiled bool) {
+	switch c.Operator {
+	case policy.OpRegex:
+		if re := c.Regexp(); re != nil {
+			return re.MatchString(fmt.Sprint(actual)), true
+		}
+	case policy.OpIPInCIDR:
+		if networks := c.Networks(); networks != nil {
+			ip := net.ParseIP(fmt.Sprint(actual))
+			return ip != nil && slices.ContainsFunc(networks, func(n *net.IPNet) bool { return n.Contains(ip) }), true
+		}
+	case policy.OpTimeAfter, policy.OpTimeBefore:
+		if et, ok := c.Time(); ok {
+			at, ok := parseTime(actual)
+			if !ok {
+				return false, true
+			}
+			if c.Operator == policy.OpTimeAfter {
+				return at.After(et), true
+			}
+			return at.Before(et), true
 		}
 	}
-	return nil
+	return false, false
 }
 
 func evaluateCondition(op policy.Operator, actual, expected any) (bool, error) {
@@ -273,6 +451,28 @@ func evaluateCondition(op policy.Operator, actual, expected any) (bool, error) {
 			return false, fmt.Errorf("%w: invalid regex %q: %w", ErrInvalidCondition, expected, err)
 		}
 		return re.MatchString(fmt.Sprint(actual)), nil
+	case policy.OpAnyIn, policy.OpAllIn:
+		items, ok := collectionOf(actual)
+		set, okSet := collectionOf(expected)
+		if !ok || !okSet {
+			return false, nil
+		}
+		if op == policy.OpAnyIn {
+			return slices.ContainsFunc(items, func(s string) bool { return slices.Contains(set, s) }), nil
+		}
+		return !slices.ContainsFunc(items, func(s string) bool { return !slices.Contains(set, s) }), nil
+	case policy.OpContainsElement:
+		items, ok := collectionOf(actual)
+		return ok && expected != nil && slices.Contains(items, fmt.Sprint(expected)), nil
+	case policy.OpSizeGT, policy.OpSizeLT:
+		n, ok := collectionSize(actual)
+		if !ok {
+			return false, nil
+		}
+		if op == policy.OpSizeGT {
+			return float64(n) > toFloat64(expected), nil
+		}
+		return float64(n) < toFloat64(expected), nil
 	default:
 		return false, fmt.Errorf("%w: unknown operator %q", ErrInvalidCondition, op)
 	}
@@ -297,6 +497,63 @@ func inSlice(actual, expected any) bool {
 	return false
 }
 
+// collectionOf returns the elements of a multi-valued attribute, compared
+// by their string form like OpIn does. Slices and arrays of any element
+// type and JSON arrays are collections; any other value is a collection of
+// one. A missing attribute or a map is not a collection.
+func collectionOf(v any) ([]string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return nil, false
+	case []string:
+		return x, true
+	case []any:
+		out := make([]string, len(x))
+		for i, item := range x {
+			out[i] = fmt.Sprint(item)
+		}
+		return out, true
+	case json.RawMessage:
+		var decoded any
+		if err := json.Unmarshal(x, &decoded); err != nil {
+			return nil, false
+		}
+		return collectionOf(decoded)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		out := make([]string, rv.Len())
+		for i := range out {
+			out[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return out, true
+	case reflect.Map:
+		return nil, false
+	}
+	return []string{fmt.Sprint(v)}, true
+}
+
+// collectionSize returns the number of elements of a collection, or of
+// entries of a map.
+func collectionSize(v any) (int, bool) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map {
+		return rv.Len(), true
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		var decoded any
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return 0, false
+		}
+		v = decoded
+	}
+	if m, ok := v.(map[string]any); ok {
+		return len(m), true
+	}
+	items, ok := collectionOf(v)
+	return len(items), ok
+}
+
 func compareNumbers(a, b any) int {
 	fa := toFloat64(a)
 	fb := toFloat64(b)
@@ -0,0 +1,299 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checker infers the static type of each node and records the first type
+// error. Dyn operands satisfy every rule; they are checked at evaluation.
+type checker struct {
+	env Env
+	err error
+}
+
+func (c *checker) errf(n node, format string, args ...any) Type {
+	if c.err == nil {
+		c.err = &Error{Col: n.pos() + 1, Msg: fmt.Sprintf(format, args...)}
+	}
+	return Dyn
+}
+
+// fieldType is the declared type of an attribute path, or Dyn.
+func (c *checker) fieldType(path string) Type {
+	if t, ok := builtinFields[path]; ok {
+		return t
+	}
+	if c.env != nil {
+		if t, ok := c.env.FieldType(path); ok {
+			return t
+		}
+	}
+	return Dyn
+}
+
+func isRoot(n node) bool {
+	id, ok := n.(*identNode)
+	return ok && slices.Contains(Roots, id.name)
+}
+
+// attrPath renders a select/index chain over a root identifier with
+// constant string keys as a dotted path ("context.request.headers.x-tier").
+func attrPath(n node) (string, bool) {
+	switch n := n.(type) {
+	case *identNode:
+		if isRoot(n) {
+			return n.name, true
+		}
+	case *selectNode:
+		if base, ok := attrPath(n.operand); ok {
+			return base + "." + n.field, true
+		}
+	case *indexNode:
+		lit, ok := n.index.(*literalNode)
+		if !ok {
+			return "", false
+		}
+		key, ok := lit.val.(string)
+		if !ok {
+			return "", false
+		}
+		if base, ok := attrPath(n.operand); ok {
+			return base + "." + key, true
+		}
+	}
+	return "", false
+}
+
+func isNum(t Type) bool { return t == Int || t == Float }
+
+func (c *checker) check(n node) Type {
+	switch n := n.(type) {
+	case *literalNode:
+		return typeOf(n.val)
+	case *identNode:
+		if n.name == "now" {
+			return Timestamp
+		}
+		if isRoot(n) {
+			return c.errf(n, "%s must be followed by a field, e.g. %s.id", n.name, n.name)
+		}
+		return c.errf(n, "undeclared identifier %q", n.name)
+	case *listNode:
+		for _, e := range n.elems {
+			c.check(e)
+		}
+		return List
+	case *selectNode:
+		if !isRoot(n.operand) {
+			if t := c.check(n.operand); t != Dyn && t != Map {
+				return c.errf(n, "cannot select field %q on %s", n.field, t)
+			}
+		}
+		if path, ok := attrPath(n); ok {
+			return c.fieldType(path)
+		}
+		return Dyn
+	case *indexNode:
+		return c.checkIndex(n)
+	case *unaryNode:
+		t := c.check(n.x)
+		switch {
+		case n.op == "!" && (t == Bool || t == Dyn):
+			return Bool
+		case n.op == "-" && (isNum(t) || t == Duration || t == Dyn):
+			return t
+		}
+		return c.errf(n, "operator %s not defined on %s", n.op, t)
+	case *binaryNode:
+		return c.checkBinary(n)
+	case *callNode:
+		return c.checkCall(n)
+	}
+	return c.errf(n, "unsupported expression")
+}
+
+func (c *checker) checkIndex(n *indexNode) Type {
+	if isRoot(n.operand) {
+		if path, ok := attrPath(n); ok {
+			return c.fieldType(path)
+		}
+		return c.errf(n, "attributes of %s must be selected by a constant string", n.operand.(*identNode).name)
+	}
+	ot := c.check(n.operand)
+	it := c.check(n.index)
+	switch {
+	case ot == List && it != Int && it != Dyn:
+		return c.errf(n.index, "list index must be int, got %s", it)
+	case ot == Map && it != String && it != Dyn:
+		return c.errf(n.index, "map key must be string, got %s", it)
+	case ot != List && ot != Map && ot != Dyn:
+		return c.errf(n, "cannot index %s", ot)
+	}
+	if path, ok := attrPath(n); ok {
+		return c.fieldType(path)
+	}
+	return Dyn
+}
+
+func (c *checker) checkBinary(n *binaryNode) Type {
+	l, r := c.check(n.l), c.check(n.r)
+	if c.err != nil {
+		return Dyn
+	}
+	dyn := l == Dyn || r == Dyn
+	switch n.op {
+	case "&&", "||":
+		if (l == Bool || l == Dyn) && (r == Bool || r == Dyn) {
+			return Bool
+		}
+	case "==", "!=":
+		if dyn || l == Null || r == Null || l == r || (isNum(l) && isNum(r)) {
+			return Bool
+		}
+	case "<", "<=", ">", ">=":
+		orderable := func(t Type) bool { return isNum(t) || t == String || t == Duration || t == Timestamp || t == Dyn }
+		if orderable(l) && orderable(r) && (dyn || l == r || (isNum(l) && isNum(r))) {
+			return Bool
+		}
+	case "in":
+		if r == List || r == Map || r == Dyn {
+			return Bool
+		}
+	case "+":
+		switch {
+		case dyn:
+			return Dyn
+		case isNum(l) && isNum(r):
+			return numResult(l, r)
+		case l == r && (l == String || l == List || l == Duration):
+			return l
+		case (l == Timestamp && r == Duration) || (l == Duration && r == Timestamp):
+			return Timestamp
+		}
+	case "-":
+		switch {
+		case dyn:
+			return Dyn
+		case isNum(l) && isNum(r):
+			return numResult(l, r)
+		case l == Duration && r == Duration, l == Timestamp && r == Timestamp:
+			return Duration
+		case l == Timestamp && r == Duration:
+			return Timestamp
+		}
+	case "*", "/":
+		switch {
+		case (isNum(l) || l == Dyn) && (isNum(r) || r == Dyn):
+			if dyn {
+				return Dyn
+			}
+			return numResult(l, r)
+		}
+	case "%":
+		if (l == Int || l == Dyn) && (r == Int || r == Dyn) {
+			return Int
+		}
+	}
+	return c.errf(n, "operator %s not defined on %s and %s", n.op, l, r)
+}
+
+func numResult(l, r Type) Type {
+	if l == Int && r == Int {
+		return Int
+	}
+	return Float
+}
+
+// arities lists the built-in functions and their argument counts.
+var arities = map[string]int{"size": 1, "exists": 1, "matches": 2, "duration": 1, "timestamp": 1}
+
+func (c *checker) checkCall(n *callNode) Type {
+	want, ok := arities[n.fn]
+	if !ok {
+		return c.errf(n, "unknown function %q", n.fn)
+	}
+	if len(n.args) != want {
+		return c.errf(n, "%s() takes %d argument(s), got %d", n.fn, want, len(n.args))
+	}
+
+	if n.fn == "exists" {
+		switch n.args[0].(type) {
+		case *selectNode, *indexNode:
+			c.check(n.args[0])
+			return Bool
+		}
+		return c.errf(n.args[0], "exists() takes an attribute path")
+	}
+
+	types := make([]Type, len(n.args))
+	for i, a := range n.args {
+		types[i] = c.check(a)
+	}
+	if c.err != nil {
+		return Dyn
+	}
+	stringArg := func(i int) (string, bool) {
+		lit, ok := n.args[i].(*literalNode)
+		if !ok {
+			return "", false
+		}
+		s, ok := lit.val.(string)
+		return s, ok
+	}
+	requireString := func(i int) bool {
+		if types[i] != String && types[i] != Dyn {
+			c.errf(n.args[i], "%s() argument %d must be string, got %s", n.fn, i+1, types[i])
+			return false
+		}
+		return true
+	}
+
+	switch n.fn {
+	case "size":
+		if t := types[0]; t != String && t != List && t != Map && t != Dyn {
+			return c.errf(n.args[0], "size() not defined on %s", t)
+		}
+		return Int
+	case "matches":
+		if !requireString(0) || !requireString(1) {
+			return Dyn
+		}
+		if pattern, ok := stringArg(1); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return c.errf(n.args[1], "invalid regex %q: %s", pattern, strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+			}
+			n.re = re
+		}
+		return Bool
+	case "duration":
+		if !requireString(0) {
+			return Dyn
+		}
+		if s, ok := stringArg(0); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return c.errf(n.args[0], "invalid duration %q", s)
+			}
+			n.konst = d
+		}
+		return Duration
+	case "timestamp":
+		if !requireString(0) {
+			return Dyn
+		}
+		if s, ok := stringArg(0); ok {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return c.errf(n.args[0], "invalid RFC3339 timestamp %q", s)
+			}
+			n.konst = t
+		}
+		return Timestamp
+	}
+	return Dyn
+}
@@ -0,0 +1,543 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNoSuchAttribute is returned by Program.Eval when the expression reads
+// an attribute, map key or list index that is absent. exists() reports
+// such paths as false instead.
+var ErrNoSuchAttribute = errors.New("expr: no such attribute")
+
+type evaluator struct {
+	act Activation
+}
+
+func (e *evaluator) lookup(name string) (any, error) {
+	if e.act != nil {
+		if v, ok := e.act.Lookup(name); ok && v != nil {
+			return normalize(v), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoSuchAttribute, name)
+}
+
+func (e *evaluator) eval(n node) (any, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.val, nil
+	case *identNode:
+		if n.name == "now" {
+			if e.act != nil {
+				if v, ok := e.act.Lookup("now"); ok {
+					if t, ok := v.(time.Time); ok {
+						return t, nil
+					}
+				}
+			}
+			return time.Now(), nil
+		}
+		return nil, fmt.Errorf("expr: undeclared identifier %q", n.name)
+	case *listNode:
+		out := make([]any, len(n.elems))
+		for i, el := range n.elems {
+			v, err := e.eval(el)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	case *selectNode:
+		if isRoot(n.operand) {
+			return e.lookup(n.operand.(*identNode).name + "." + n.field)
+		}
+		v, err := e.eval(n.operand)
+		if err != nil {
+			return nil, err
+		}
+		return e.key(v, n.field)
+	case *indexNode:
+		return e.evalIndex(n)
+	case *unaryNode:
+		return e.evalUnary(n)
+	case *binaryNode:
+		return e.evalBinary(n)
+	case *callNode:
+		return e.evalCall(n)
+	}
+	return nil, fmt.Errorf("expr: unsupported expression")
+}
+
+func (e *evaluator) key(v any, k string) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expr: cannot select field %q on %s", k, typeOf(v))
+	}
+	val, ok := m[k]
+	if !ok || val == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchAttribute, k)
+	}
+	return normalize(val), nil
+}
+
+func (e *evaluator) evalIndex(n *indexNode) (any, error) {
+	if isRoot(n.operand) {
+		path, _ := attrPath(n)
+		return e.lookup(path)
+	}
+	v, err := e.eval(n.operand)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := e.eval(n.index)
+	if err != nil {
+		return nil, err
+	}
+	switch c := v.(type) {
+	case []any:
+		i, ok := idx.(int64)
+		if !ok {
+			return nil, fmt.Errorf("expr: list index must be int, got %s", typeOf(idx))
+		}
+		if i < 0 || i >= int64(len(c)) {
+			return nil, fmt.Errorf("%w: index %d out of range", ErrNoSuchAttribute, i)
+		}
+		return normalize(c[i]), nil
+	case map[string]any:
+		k, ok := idx.(string)
+		if !ok {
+			return nil, fmt.Errorf("expr: map key must be string, got %s", typeOf(idx))
+		}
+		return e.key(c, k)
+	}
+	return nil, fmt.Errorf("expr: cannot index %s", typeOf(v))
+}
+
+func (e *evaluator) evalUnary(n *unaryNode) (any, error) {
+	v, err := e.eval(n.x)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case bool:
+		if n.op == "!" {
+			return !x, nil
+		}
+	case int64:
+		if n.op == "-" {
+			return -x, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -x, nil
+		}
+	case time.Duration:
+		if n.op == "-" {
+			return -x, nil
+		}
+	}
+	return nil, fmt.Errorf("expr: operator %s not defined on %s", n.op, typeOf(v))
+}
+
+func (e *evaluator) evalBinary(n *binaryNode) (any, error) {
+	l, err := e.eval(n.l)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit, so the right side may guard on
+	// the left: `exists(subject.level) && subject.level > 2`.
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := l.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expr: operator %s not defined on %s", n.op, typeOf(l))
+		}
+		if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+			return lb, nil
+		}
+		r, err := e.eval(n.r)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := r.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expr: operator %s not defined on %s", n.op, typeOf(r))
+		}
+		return rb, nil
+	}
+
+	r, err := e.eval(n.r)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	case "<", "<=", ">", ">=":
+		c, err := compare(l, r)
+		if err != nil {
+			return nil, fmt.Errorf("expr: operator %s: %w", n.op, err)
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "in":
+		switch c := r.(type) {
+		case []any:
+			for _, item := range c {
+				if equal(l, normalize(item)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]any:
+			k, ok := l.(string)
+			if !ok {
+				return false, nil
+			}
+			_, found := c[k]
+			return found, nil
+		}
+		return nil, fmt.Errorf("expr: operator in not defined on %s", typeOf(r))
+	}
+	return arith(n.op, l, r)
+}
+
+func (e *evaluator) evalCall(n *callNode) (any, error) {
+	if n.fn == "exists" {
+		_, err := e.eval(n.args[0])
+		if errors.Is(err, ErrNoSuchAttribute) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	if n.konst != nil {
+		return n.konst, nil
+	}
+
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		v, err := e.eval(a)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	switch n.fn {
+	case "size":
+		switch v := args[0].(type) {
+		case string:
+			return int64(len([]rune(v))), nil
+		case []any:
+			return int64(len(v)), nil
+		case map[string]any:
+			return int64(len(v)), nil
+		}
+		return nil, fmt.Errorf("expr: size() not defined on %s", typeOf(args[0]))
+	case "matches":
+		s, ok1 := args[0].(string)
+		pattern, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("expr: matches() takes strings")
+		}
+		re := n.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("expr: invalid regex %q: %w", pattern, err)
+			}
+		}
+		return re.MatchString(s), nil
+	case "duration":
+		switch v := args[0].(type) {
+		case time.Duration:
+			return v, nil
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("expr: invalid duration %q", v)
+			}
+			return d, nil
+		}
+		return nil, fmt.Errorf("expr: duration() takes a string")
+	case "timestamp":
+		if t, ok := toTime(args[0]); ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("expr: invalid RFC3339 timestamp %v", args[0])
+	}
+	return nil, fmt.Errorf("expr: unknown function %q", n.fn)
+}
+
+func arith(op string, l, r any) (any, error) {
+	switch lv := l.(type) {
+	case int64:
+		if rv, ok := r.(int64); ok {
+			switch op {
+			case "+":
+				return lv + rv, nil
+			case "-":
+				return lv - rv, nil
+			case "*":
+				return lv * rv, nil
+			case "/", "%":
+				if rv == 0 {
+					return nil, fmt.Errorf("expr: division by zero")
+				}
+				if op == "/" {
+					return lv / rv, nil
+				}
+				return lv % rv, nil
+			}
+		}
+	case string:
+		if rv, ok := r.(string); ok && op == "+" {
+			return lv + rv, nil
+		}
+	case []any:
+		if rv, ok := r.([]any); ok && op == "+" {
+			return append(append([]any{}, lv...), rv...), nil
+		}
+	case time.Duration:
+		switch rv := r.(type) {
+		case time.Duration:
+			if op == "+" {
+				return lv + rv, nil
+			}
+			if op == "-" {
+				return lv - rv, nil
+			}
+		case time.Time:
+			if op == "+" {
+				return rv.Add(lv), nil
+			}
+		}
+	case time.Time:
+		switch rv := r.(type) {
+		case time.Duration:
+			if op == "+" {
+				return lv.Add(rv), nil
+			}
+			if op == "-" {
+				return lv.Add(-rv), nil
+			}
+		case time.Time:
+			if op == "-" {
+				return lv.Sub(rv), nil
+			}
+		}
+	}
+
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if lok && rok && op != "%" {
+		switch op {
+		case "+":
+			return lf + rf, nil
+		case "-":
+			return lf - rf, nil
+		case "*":
+			return lf * rf, nil
+		case "/":
+			if rf == 0 {
+				return nil, fmt.Errorf("expr: division by zero")
+			}
+			return lf / rf, nil
+		}
+	}
+	return nil, fmt.Errorf("expr: operator %s not defined on %s and %s", op, typeOf(l), typeOf(r))
+}
+
+// equal compares values of any type. Numbers compare by value, and a
+// string compares equal to a timestamp it parses to.
+func equal(l, r any) bool {
+	if c, err := compare(l, r); err == nil {
+		return c == 0
+	}
+	switch lv := l.(type) {
+	case nil:
+		return r == nil
+	case bool:
+		rv, ok := r.(bool)
+		return ok && lv == rv
+	case []any:
+		rv, ok := r.([]any)
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		for i := range lv {
+			if !equal(normalize(lv[i]), normalize(rv[i])) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		return reflect.DeepEqual(l, r)
+	}
+	return false
+}
+
+// compare orders two numbers, strings, durations or timestamps. Strings
+// are parsed when compared against durations or timestamps.
+func compare(l, r any) (int, error) {
+	if lf, ok := toFloat(l); ok {
+		if rf, ok := toFloat(r); ok {
+			return cmp3(lf < rf, lf > rf), nil
+		}
+	}
+	_, lt := l.(time.Time)
+	_, rt := r.(time.Time)
+	if lt || rt {
+		a, ok1 := toTime(l)
+		b, ok2 := toTime(r)
+		if ok1 && ok2 {
+			return cmp3(a.Before(b), a.After(b)), nil
+		}
+	}
+	_, ld := l.(time.Duration)
+	_, rd := r.(time.Duration)
+	if ld || rd {
+		a, ok1 := toDuration(l)
+		b, ok2 := toDuration(r)
+		if ok1 && ok2 {
+			return cmp3(a < b, a > b), nil
+		}
+	}
+	if ls, ok := l.(string); ok {
+		if rs, ok := r.(string); ok {
+			return strings.Compare(ls, rs), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", typeOf(l), typeOf(r))
+}
+
+func cmp3(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+func toDuration(v any) (time.Duration, bool) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, true
+	case string:
+		parsed, err := time.ParseDuration(d)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+// normalize maps Go values onto the language's value set: integers to
+// int64, floats to float64, slices to []any and string-keyed maps to
+// map[string]any. Other values pass through.
+func normalize(v any) any {
+	switch x := v.(type) {
+	case nil, bool, string, int64, float64, time.Time, time.Duration, []any, map[string]any:
+		return v
+	case int:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case float32:
+		return float64(x)
+	case []string:
+		out := make([]any, len(x))
+		for i, s := range x {
+			out[i] = s
+		}
+		return out
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice, reflect.Array:
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = rv.Index(i).Interface()
+		}
+		return out
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = iter.Value().Interface()
+		}
+		return out
+	}
+	return v
+}
+
+// typeOf is the Type of a normalized value.
+func typeOf(v any) Type {
+	switch v.(type) {
+	case nil:
+		return Null
+	case bool:
+		return Bool
+	case int64:
+		return Int
+	case float64:
+		return Float
+	case string:
+		return String
+	case time.Duration:
+		return Duration
+	case time.Time:
+		return Timestamp
+	case []any:
+		return List
+	case map[string]any:
+		return Map
+	}
+	return Dyn
+}
@@ -0,0 +1,224 @@
+// Package expr implements the small expression language used by
+// expression conditions in ABAC policies.
+//
+// Expressions are side-effect free and evaluate to a bool:
+//
+//	size(subject.groups) > 2 && resource.owner_id == subject.id
+//	context.request.headers["x-tier"] in ["gold", "platinum"]
+//	now - timestamp(resource.created_at) < duration("720h")
+//	exists(subject.mfa_at) && matches(subject.email, ".*@example\\.com$")
+//
+// The language has bool, int, float, string, null, list, map, duration
+// and timestamp values; the usual arithmetic, comparison and logical
+// operators; `in` for list and map membership; and the functions size,
+// exists, matches, duration and timestamp. There are no loops, no
+// assignment and no access to anything but the attributes the caller
+// supplies, so an expression always terminates.
+//
+// Compile parses and type-checks an expression once; the resulting
+// Program is safe for concurrent use. Cache memoizes Compile by source.
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Type is the static type of an expression or attribute.
+type Type int
+
+const (
+	// Dyn is the type of a value not known until evaluation, such as an
+	// undeclared attribute. It is compatible with every other type.
+	Dyn Type = iota
+
+	// Null is the type of the null literal.
+	Null
+
+	// Bool is true or false.
+	Bool
+
+	// Int is a 64-bit signed integer.
+	Int
+
+	// Float is a 64-bit floating point number.
+	Float
+
+	// String is a UTF-8 string.
+	String
+
+	// Duration is a time.Duration.
+	Duration
+
+	// Timestamp is a time.Time.
+	Timestamp
+
+	// List is an ordered list of values.
+	List
+
+	// Map is a map with string keys.
+	Map
+)
+
+var typeNames = [...]string{"dyn", "null", "bool", "int", "float", "string", "duration", "timestamp", "list", "map"}
+
+// String returns the type's name as used in diagnostics.
+func (t Type) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// ParseType returns the Type named s, as printed by Type.String.
+func ParseType(s string) (Type, bool) {
+	for i, name := range typeNames {
+		if name == s {
+			return Type(i), true
+		}
+	}
+	return Dyn, false
+}
+
+// Roots are the identifiers an expression may select attributes from.
+var Roots = []string{"subject", "resource", "action", "context"}
+
+// builtinFields are always present on a check request.
+var builtinFields = map[string]Type{
+	"subject.id":    String,
+	"subject.kind":  String,
+	"resource.id":   String,
+	"resource.type": String,
+	"action.name":   String,
+}
+
+// Env describes the attributes available to an expression at compile
+// time. Attributes it does not declare are typed Dyn.
+type Env interface {
+	// FieldType returns the declared type of a dotted attribute path such
+	// as "subject.department".
+	FieldType(path string) (Type, bool)
+}
+
+// Activation supplies attribute values at evaluation time.
+type Activation interface {
+	// Lookup returns the value of a root attribute such as
+	// "subject.department" or "context.request", or of "now". It reports
+	// false when the attribute is absent.
+	Lookup(name string) (any, bool)
+}
+
+// Vars is an Activation backed by nested maps, keyed by root:
+//
+//	expr.Vars{"subject": map[string]any{"id": "u1"}, "now": time.Now()}
+type Vars map[string]any
+
+// Lookup implements Activation.
+func (v Vars) Lookup(name string) (any, bool) {
+	root, field, ok := strings.Cut(name, ".")
+	if !ok {
+		val, found := v[name]
+		return val, found
+	}
+	m, ok := v[root].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	val, found := m[field]
+	return val, found
+}
+
+// Error is a syntax or type error in an expression. Col is the 1-based
+// byte offset in the source.
+type Error struct {
+	Col int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("expr: col %d: %s", e.Col, e.Msg)
+}
+
+// Program is a compiled expression.
+type Program struct {
+	src  string
+	root node
+}
+
+// Source returns the expression the program was compiled from.
+func (p *Program) Source() string { return p.src }
+
+// Eval evaluates the program against act. Absent attributes, runtime type
+// mismatches and invalid arguments are reported as errors; callers
+// typically treat an error as "condition not met".
+func (p *Program) Eval(act Activation) (bool, error) {
+	v, err := (&evaluator{act: act}).eval(p.root)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expr: result is %s, not bool", typeOf(v))
+	}
+	return b, nil
+}
+
+// Compile parses and type-checks src. A nil env declares only the
+// built-in request fields (subject.id, subject.kind, resource.id,
+// resource.type, action.name).
+func Compile(src string, env Env) (*Program, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	c := &checker{env: env}
+	t := c.check(root)
+	if c.err != nil {
+		return nil, c.err
+	}
+	if t != Bool && t != Dyn {
+		return nil, &Error{Col: root.pos() + 1, Msg: fmt.Sprintf("expression must be bool, got %s", t)}
+	}
+	return &Program{src: src, root: root}, nil
+}
+
+// maxCacheEntries bounds a Cache; it is cleared when full.
+const maxCacheEntries = 4096
+
+// Cache compiles each distinct source once. It is safe for concurrent use.
+type Cache struct {
+	env Env
+
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	prog *Program
+	err  error
+}
+
+// NewCache returns a Cache that compiles against env.
+func NewCache(env Env) *Cache {
+	return &Cache{env: env, entries: make(map[string]cacheEntry)}
+}
+
+// Compile returns the cached result of Compile(src, env).
+func (c *Cache) Compile(src string) (*Program, error) {
+	c.mu.RLock()
+	e, ok := c.entries[src]
+	c.mu.RUnlock()
+	if ok {
+		return e.prog, e.err
+	}
+
+	prog, err := Compile(src, c.env)
+	c.mu.Lock()
+	if len(c.entries) >= maxCacheEntries {
+		c.entries = make(map[string]cacheEntry)
+	}
+	c.entries[src] = cacheEntry{prog: prog, err: err}
+	c.mu.Unlock()
+	return prog, err
+}
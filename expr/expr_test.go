@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testVars() Vars {
+	return Vars{
+		"subject": map[string]any{
+			"id":         "u1",
+			"kind":       "user",
+			"department": "eng",
+			"groups":     []string{"admins", "oncall"},
+			"level":      3,
+			"email":      "ana@example.com",
+		},
+		"resource": map[string]any{
+			"type":       "document",
+			"owner_id":   "u1",
+			"department": "eng",
+			"size":       1.5,
+			"created_at": "2026-05-20T12:00:00Z",
+		},
+		"action": map[string]any{"name": "read"},
+		"context": map[string]any{
+			"request": map[string]any{
+				"headers": map[string]string{"x-tier": "gold"},
+			},
+		},
+		"now": testNow,
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`resource.owner_id == subject.id`, true},
+		{`subject.department == resource.department && action.name == "read"`, true},
+		{`subject.level > 2 && subject.level * 2 == 6`, true},
+		{`subject.level + resource.size > 4`, true},
+		{`10 % 3 == 1 && -subject.level < 0`, true},
+		{`"oncall" in subject.groups`, true},
+		{`"root" in subject.groups`, false},
+		{`"headers" in context.request`, true},
+		{`context.request.headers["x-tier"] in ["gold", "platinum"]`, true},
+		{`size(subject.groups) == 2 && size("héllo") == 5`, true},
+		{`exists(subject.level) && !exists(subject.manager)`, true},
+		{`exists(context.request.headers["x-missing"])`, false},
+		{`matches(subject.email, '.*@example\.com$')`, true},
+		{`now - timestamp(resource.created_at) < duration("720h")`, true},
+		{`timestamp(resource.created_at) + duration("24h") < now`, true},
+		{`resource.created_at < now`, true},
+		{`!(subject.kind == "service") || false`, true},
+		{`exists(subject.manager) && subject.manager == "x"`, false},
+		{`subject.groups[0] == "admins"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := Compile(tt.src, nil)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			got, err := prog.Eval(testVars())
+			if err != nil {
+				t.Fatalf("eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEval_MissingAttribute(t *testing.T) {
+	prog, err := Compile(`subject.manager == "x"`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prog.Eval(testVars()); !errors.Is(err, ErrNoSuchAttribute) {
+		t.Errorf("err = %v, want ErrNoSuchAttribute", err)
+	}
+}
+
+type mapEnv map[string]Type
+
+func (m mapEnv) FieldType(path string) (Type, bool) {
+	t, ok := m[path]
+	return t, ok
+}
+
+func TestCompile_Errors(t *testing.T) {
+	env := mapEnv{"subject.level": Int, "resource.tags": List}
+	tests := []struct {
+		src, want string
+	}{
+		{``, "empty expression"},
+		{`subject.id ==`, "unexpected end"},
+		{`subject.id = "x"`, "unexpected character"},
+		{`(subject.id == "x"`, "unexpected end"},
+		{`"unterminated`, "unterminated string"},
+		{`user.id == "x"`, `undeclared identifier "user"`},
+		{`subject == "x"`, "must be followed by a field"},
+		{`subject.id > 3`, "operator > not defined on string and int"},
+		{`subject.level == "high"`, "operator == not defined on int and string"},
+		{`subject.id.first == "a"`, `cannot select field "first" on string`},
+		{`resource.tags["a"] == "b"`, "list index must be int"},
+		{`subject.id`, "expression must be bool, got string"},
+		{`size(subject.level) > 1`, "size() not defined on int"},
+		{`exists("x")`, "exists() takes an attribute path"},
+		{`matches(subject.id, "[")`, "invalid regex"},
+		{`duration("soon") > duration("1h")`, `invalid duration "soon"`},
+		{`timestamp("yesterday") < now`, "invalid RFC3339 timestamp"},
+		{`now > "2026-01-01T00:00:00Z"`, "operator > not defined on timestamp and string"},
+		{`lower(subject.id) == "a"`, `unknown function "lower"`},
+		{`size(subject.id, 1) == 1`, "size() takes 1 argument(s), got 2"},
+		{`!subject.level`, "operator ! not defined on int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src, env)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := NewCache(nil)
+	p1, err := c.Compile(`subject.id == "u1"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, _ := c.Compile(`subject.id == "u1"`)
+	if p1 != p2 {
+		t.Error("expected the cached program to be reused")
+	}
+	if _, err := c.Compile(`subject.id ==`); err == nil {
+		t.Error("expected compile error")
+	}
+	if _, err := c.Compile(`subject.id ==`); err == nil {
+		t.Error("expected cached compile error")
+	}
+}
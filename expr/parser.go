@@ -0,0 +1,466 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// node is an expression AST node. pos is the 0-based source offset.
+type node interface{ pos() int }
+
+type (
+	literalNode struct {
+		at  int
+		val any // nil, bool, int64, float64 or string
+	}
+	identNode struct {
+		at   int
+		name string
+	}
+	selectNode struct {
+		at      int
+		operand node
+		field   string
+	}
+	indexNode struct {
+		at      int
+		operand node
+		index   node
+	}
+	unaryNode struct {
+		at int
+		op string
+		x  node
+	}
+	binaryNode struct {
+		at   int
+		op   string
+		l, r node
+	}
+	callNode struct {
+		at   int
+		fn   string
+		args []node
+
+		// Set by the checker when an argument is a literal, so the work
+		// is done once at compile time.
+		re    *regexp.Regexp
+		konst any
+	}
+	listNode struct {
+		at    int
+		elems []node
+	}
+)
+
+func (n *literalNode) pos() int { return n.at }
+func (n *identNode) pos() int   { return n.at }
+func (n *selectNode) pos() int  { return n.at }
+func (n *indexNode) pos() int   { return n.at }
+func (n *unaryNode) pos() int   { return n.at }
+func (n *binaryNode) pos() int  { return n.at }
+func (n *callNode) pos() int    { return n.at }
+func (n *listNode) pos() int    { return n.at }
+
+type tokKind int
+
+const (
+	tEOF tokKind = iota
+	tIdent
+	tInt
+	tFloat
+	tString
+	tOp // punctuation and operators; text holds the spelling
+)
+
+type token struct {
+	kind tokKind
+	text string
+	val  any
+	at   int
+}
+
+// twoCharOps are matched before single-character operators.
+var twoCharOps = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+const oneCharOps = "()[],.!-+*/%<>"
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tIdent, text: src[start:i], at: start})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			isFloat := false
+			if i+1 < len(src) && src[i] == '.' && src[i+1] >= '0' && src[i+1] <= '9' {
+				isFloat = true
+				i++
+				for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+					i++
+				}
+			}
+			text := src[start:i]
+			if isFloat {
+				f, err := strconv.ParseFloat(text, 64)
+				if err != nil {
+					return nil, &Error{Col: start + 1, Msg: fmt.Sprintf("invalid number %q", text)}
+				}
+				toks = append(toks, token{kind: tFloat, text: text, val: f, at: start})
+			} else {
+				n, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return nil, &Error{Col: start + 1, Msg: fmt.Sprintf("invalid number %q", text)}
+				}
+				toks = append(toks, token{kind: tInt, text: text, val: n, at: start})
+			}
+		case c == '"' || c == '\'':
+			s, n, err := readString(src[i:])
+			if err != nil {
+				return nil, &Error{Col: i + 1, Msg: err.Error()}
+			}
+			toks = append(toks, token{kind: tString, text: src[i : i+n], val: s, at: i})
+			i += n
+		default:
+			matched := false
+			for _, op := range twoCharOps {
+				if strings.HasPrefix(src[i:], op) {
+					toks = append(toks, token{kind: tOp, text: op, at: i})
+					i += 2
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			if strings.IndexByte(oneCharOps, c) >= 0 {
+				toks = append(toks, token{kind: tOp, text: string(c), at: i})
+				i++
+				continue
+			}
+			return nil, &Error{Col: i + 1, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return append(toks, token{kind: tEOF, at: len(src)}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool { return isIdentStart(c) || (c >= '0' && c <= '9') }
+
+// readString reads a single- or double-quoted string at the start of s,
+// returning its unescaped value and its length in the source.
+func readString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\':
+			i++
+			if i >= len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '"', '\'':
+				b.WriteByte(s[i])
+			default:
+				// Keep unknown escapes so regex classes like \d survive.
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// parser is a precedence-climbing parser over the token stream.
+//
+//	or      = and { "||" and }
+//	and     = rel { "&&" rel }
+//	rel     = add [ ("==" | "!=" | "<" | "<=" | ">" | ">=" | "in") add ]
+//	add     = mul { ("+" | "-") mul }
+//	mul     = unary { ("*" | "/" | "%") unary }
+//	unary   = ("!" | "-") unary | postfix
+//	postfix = primary { "." ident | "[" or "]" }
+//	primary = literal | ident [ "(" args ")" ] | "(" or ")" | "[" args "]"
+type parser struct {
+	toks []token
+	i    int
+}
+
+func parse(src string) (node, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 1 {
+		return nil, &Error{Col: 1, Msg: "empty expression"}
+	}
+	p := &parser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tEOF {
+		return nil, p.unexpected(t)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) isOp(text string) bool {
+	t := p.peek()
+	return t.kind == tOp && t.text == text
+}
+
+func (p *parser) expectOp(text string) error {
+	if !p.isOp(text) {
+		return p.unexpected(p.peek())
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tEOF {
+		return &Error{Col: t.at + 1, Msg: "unexpected end of expression"}
+	}
+	return &Error{Col: t.at + 1, Msg: fmt.Sprintf("unexpected %q", t.text)}
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		t := p.next()
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{at: t.at, op: "||", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseRel()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		t := p.next()
+		r, err := p.parseRel()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{at: t.at, op: "&&", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseRel() (node, error) {
+	l, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	op := ""
+	switch {
+	case t.kind == tOp && (t.text == "==" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">="):
+		op = t.text
+	case t.kind == tIdent && t.text == "in":
+		op = "in"
+	default:
+		return l, nil
+	}
+	p.next()
+	r, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{at: t.at, op: op, l: l, r: r}, nil
+}
+
+func (p *parser) parseAdd() (node, error) {
+	l, err := p.parseMul()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		t := p.next()
+		r, err := p.parseMul()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{at: t.at, op: t.text, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseMul() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*") || p.isOp("/") || p.isOp("%") {
+		t := p.next()
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{at: t.at, op: t.text, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("!") || p.isOp("-") {
+		t := p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{at: t.at, op: t.text, x: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			t := p.next()
+			f := p.next()
+			if f.kind != tIdent {
+				return nil, p.unexpected(f)
+			}
+			n = &selectNode{at: t.at, operand: n, field: f.text}
+		case p.isOp("["):
+			t := p.next()
+			idx, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp("]"); err != nil {
+				return nil, err
+			}
+			n = &indexNode{at: t.at, operand: n, index: idx}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tInt, tFloat, tString:
+		return &literalNode{at: t.at, val: t.val}, nil
+	case tIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{at: t.at, val: true}, nil
+		case "false":
+			return &literalNode{at: t.at, val: false}, nil
+		case "null":
+			return &literalNode{at: t.at, val: nil}, nil
+		case "in":
+			return nil, p.unexpected(t)
+		}
+		if p.isOp("(") {
+			p.next()
+			args, err := p.parseArgs(")")
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{at: t.at, fn: t.text, args: args}, nil
+		}
+		return &identNode{at: t.at, name: t.text}, nil
+	case tOp:
+		switch t.text {
+		case "(":
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "[":
+			elems, err := p.parseArgs("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{at: t.at, elems: elems}, nil
+		}
+	}
+	return nil, p.unexpected(t)
+}
+
+// parseArgs parses a comma-separated list up to and including closer.
+func (p *parser) parseArgs(closer string) ([]node, error) {
+	var out []node
+	if p.isOp(closer) {
+		p.next()
+		return out, nil
+	}
+	for {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		if err := p.expectOp(closer); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+}
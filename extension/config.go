@@ -1,5 +1,12 @@
 package extension
 
+import (
+	"time"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/webhook"
+)
+
 // Config holds the Warden extension configuration.
 // Fields can be set programmatically via Option functions or loaded from
 // YAML configuration files (under "extensions.warden" or "warden" keys).
@@ -10,6 +17,10 @@ type Config struct {
 	// DisableMigrate prevents auto-migration on start.
 	DisableMigrate bool `json:"disable_migrate" mapstructure:"disable_migrate" yaml:"disable_migrate"`
 
+	// DisableAudit stops recording changes to the authorization model in
+	// the audit trail.
+	DisableAudit bool `json:"disable_audit" mapstructure:"disable_audit" yaml:"disable_audit"`
+
 	// BasePath is the URL prefix for warden routes (default: "/warden").
 	BasePath string `json:"base_path" mapstructure:"base_path" yaml:"base_path"`
 
@@ -58,6 +69,160 @@ type Config struct {
 	// Useful for multi-tenant deployments where the same source is applied
 	// to many tenants.
 	DeclarativeTenantID string `json:"declarative_tenant_id" mapstructure:"declarative_tenant_id" yaml:"declarative_tenant_id"`
+
+	// CheckLog configures check-log sampling and filtering. When nil,
+	// every check is logged.
+	CheckLog *CheckLogConfig `json:"check_log" mapstructure:"check_log" yaml:"check_log"`
+
+	// CheckLogRetention configures scheduled check-log purging. When nil,
+	// entries are kept forever.
+	CheckLogRetention *CheckLogRetentionConfig `json:"check_log_retention" mapstructure:"check_log_retention" yaml:"check_log_retention"`
+
+	// BreakGlass configures who may request emergency access. When nil,
+	// break-glass is disabled.
+	BreakGlass *BreakGlassConfig `json:"break_glass" mapstructure:"break_glass" yaml:"break_glass"`
+
+	// Webhooks configures the outbound webhook plugin. When nil or without
+	// endpoints, no webhooks are sent. See webhook.Config for the fields.
+	Webhooks *webhook.Config `json:"webhooks" mapstructure:"webhooks" yaml:"webhooks"`
+
+	// TrustedProxies lists the reverse proxies (IPs or CIDRs) whose
+	// X-Forwarded-For and X-Real-IP headers are believed when recording
+	// the caller's IP. When empty, the connection's address is recorded.
+	TrustedProxies []string `json:"trusted_proxies" mapstructure:"trusted_proxies" yaml:"trusted_proxies"`
+}
+
+// CheckLogConfig is the YAML form of warden.CheckLogRules.
+//
+//	extensions:
+//	  warden:
+//	    check_log:
+//	      allow_sample_rate: 5
+//	      exclude_actions: ["list"]
+//	      tenants:
+//	        acme:
+//	          allow_sample_rate: 100
+type CheckLogConfig struct {
+	// AlwaysLogDenies logs every deny regardless of filters. Defaults to true.
+	AlwaysLogDenies *bool `json:"always_log_denies" mapstructure:"always_log_denies" yaml:"always_log_denies"`
+
+	// AllowSampleRate is the percentage (0–100) of allowed checks to log.
+	AllowSampleRate *float64 `json:"allow_sample_rate" mapstructure:"allow_sample_rate" yaml:"allow_sample_rate"`
+
+	IncludeResourceTypes []string `json:"include_resource_types" mapstructure:"include_resource_types" yaml:"include_resource_types"`
+	ExcludeResourceTypes []string `json:"exclude_resource_types" mapstructure:"exclude_resource_types" yaml:"exclude_resource_types"`
+	IncludeActions       []string `json:"include_actions" mapstructure:"include_actions" yaml:"include_actions"`
+	ExcludeActions       []string `json:"exclude_actions" mapstructure:"exclude_actions" yaml:"exclude_actions"`
+	IncludeSubjects      []string `json:"include_subjects" mapstructure:"include_subjects" yaml:"include_subjects"`
+	ExcludeSubjects      []string `json:"exclude_subjects" mapstructure:"exclude_subjects" yaml:"exclude_subjects"`
+
+	// Tenants holds per-tenant overrides keyed by tenant ID.
+	Tenants map[string]CheckLogConfig `json:"tenants" mapstructure:"tenants" yaml:"tenants"`
+}
+
+// Rules converts the YAML config into engine check-log rules.
+func (c *CheckLogConfig) Rules() warden.CheckLogRules {
+	if c == nil {
+		return warden.CheckLogRules{}
+	}
+	r := warden.CheckLogRules{
+		AlwaysLogDenies:      c.AlwaysLogDenies,
+		AllowSampleRate:      c.AllowSampleRate,
+		IncludeResourceTypes: c.IncludeResourceTypes,
+		ExcludeResourceTypes: c.ExcludeResourceTypes,
+		IncludeActions:       c.IncludeActions,
+		ExcludeActions:       c.ExcludeActions,
+		IncludeSubjects:      c.IncludeSubjects,
+		ExcludeSubjects:      c.ExcludeSubjects,
+	}
+	if len(c.Tenants) > 0 {
+		r.Tenants = make(map[string]warden.CheckLogRules, len(c.Tenants))
+		for tenantID, o := range c.Tenants {
+			r.Tenants[tenantID] = o.Rules()
+		}
+	}
+	return r
+}
+
+// CheckLogRetentionConfig is the YAML form of warden.CheckLogRetention.
+//
+//	extensions:
+//	  warden:
+//	    check_log_retention:
+//	      max_age: 720h
+//	      interval: 1h
+//	      archive_dir: /var/lib/warden/archive
+//	      tenants:
+//	        acme: 2160h
+type CheckLogRetentionConfig struct {
+	// MaxAge is the default retention. Zero keeps entries forever.
+	MaxAge time.Duration `json:"max_age" mapstructure:"max_age" yaml:"max_age"`
+
+	// Tenants overrides MaxAge per tenant; zero keeps that tenant forever.
+	Tenants map[string]time.Duration `json:"tenants" mapstructure:"tenants" yaml:"tenants"`
+
+	// Interval is how often retention runs (default: 1h).
+	Interval time.Duration `json:"interval" mapstructure:"interval" yaml:"interval"`
+
+	// BatchSize is the number of entries deleted per round trip.
+	BatchSize int `json:"batch_size" mapstructure:"batch_size" yaml:"batch_size"`
+
+	// ArchiveDir, when set, archives purged entries as .jsonl.gz files.
+	ArchiveDir string `json:"archive_dir" mapstructure:"archive_dir" yaml:"archive_dir"`
+}
+
+// Retention converts the YAML config into engine check-log retention.
+func (c *CheckLogRetentionConfig) Retention() warden.CheckLogRetention {
+	if c == nil {
+		return warden.CheckLogRetention{}
+	}
+	interval := c.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return warden.CheckLogRetention{
+		MaxAge:     c.MaxAge,
+		Tenants:    c.Tenants,
+		Interval:   interval,
+		BatchSize:  c.BatchSize,
+		ArchiveDir: c.ArchiveDir,
+	}
+}
+
+// BreakGlassConfig is the YAML form of warden.BreakGlassConfig.
+//
+//	extensions:
+//	  warden:
+//	    break_glass:
+//	      subjects: ["user:oncall-*"]
+//	      roles: ["incident-responder"]
+//	      policies: ["deny-prod-writes"]
+//	      max_duration: 2h
+type BreakGlassConfig struct {
+	// Subjects are "kind:id" patterns of who may break glass.
+	Subjects []string `json:"subjects" mapstructure:"subjects" yaml:"subjects"`
+
+	// Roles are the slugs of roles a grant may confer.
+	Roles []string `json:"roles" mapstructure:"roles" yaml:"roles"`
+
+	// Policies are the names of deny policies a grant may bypass.
+	Policies []string `json:"policies" mapstructure:"policies" yaml:"policies"`
+
+	// MaxDuration caps grant duration (default: 4h).
+	MaxDuration time.Duration `json:"max_duration" mapstructure:"max_duration" yaml:"max_duration"`
+}
+
+// Settings converts the YAML config into engine break-glass settings.
+func (c *BreakGlassConfig) Settings() warden.BreakGlassConfig {
+	if c == nil {
+		return warden.BreakGlassConfig{}
+	}
+	return warden.BreakGlassConfig{
+		Subjects:    c.Subjects,
+		Roles:       c.Roles,
+		Policies:    c.Policies,
+		MaxDuration: c.MaxDuration,
+	}
 }
 
 // DefaultConfig returns a Config with sensible defaults.
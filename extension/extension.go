@@ -24,11 +24,13 @@ import (
 	"github.com/xraph/warden/api"
 	wardendash "github.com/xraph/warden/dashboard"
 	"github.com/xraph/warden/dsl"
+	"github.com/xraph/warden/middleware"
 	"github.com/xraph/warden/plugin"
 	"github.com/xraph/warden/store"
 	mongostore "github.com/xraph/warden/store/mongo"
 	pgstore "github.com/xraph/warden/store/postgres"
 	sqlitestore "github.com/xraph/warden/store/sqlite"
+	"github.com/xraph/warden/webhook"
 )
 
 // ExtensionName is the name registered with Forge.
@@ -55,6 +57,8 @@ type Extension struct {
 	apiHandler *api.API
 	wardenOpts []warden.Option
 	plugins    []plugin.Plugin
+	pluginOpts [][]plugin.RegisterOption // parallel to plugins
+	webhooks   *webhook.Plugin
 	useGrove   bool
 }
 
@@ -137,15 +141,36 @@ func (e *Extension) init(fapp forge.App) error {
 	opts = append(opts, e.wardenOpts...)
 
 	// Register extension hooks.
-	for _, x := range e.plugins {
-		opts = append(opts, warden.WithPlugin(x))
+	for i, x := range e.plugins {
+		opts = append(opts, warden.WithPlugin(x, e.pluginOpts[i]...))
+	}
+
+	// Apply max graph depth, check-log rules/retention, break-glass and
+	// the audit toggle from config if set.
+	if e.config.MaxGraphDepth > 0 || e.config.CheckLog != nil || e.config.CheckLogRetention != nil || e.config.BreakGlass != nil || e.config.DisableAudit {
+		cfg := warden.Config{
+			MaxGraphDepth:     e.config.MaxGraphDepth,
+			CheckLog:          e.config.CheckLog.Rules(),
+			CheckLogRetention: e.config.CheckLogRetention.Retention(),
+			BreakGlass:        e.config.BreakGlass.Settings(),
+		}
+		if e.config.DisableAudit {
+			off := false
+			cfg.EnableAudit = &off
+		}
+		opts = append(opts, warden.WithConfig(cfg))
+	}
+
+	if err := middleware.SetTrustedProxies(e.config.TrustedProxies...); err != nil {
+		return err
 	}
 
-	// Apply max graph depth from config if set.
-	if e.config.MaxGraphDepth > 0 {
-		opts = append(opts, warden.WithConfig(warden.Config{
-			MaxGraphDepth: e.config.MaxGraphDepth,
-		}))
+	// Outbound webhooks from YAML.
+	if cfg := e.config.Webhooks; cfg != nil && len(cfg.Endpoints) > 0 {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("warden: webhooks: %w", err)
+		}
+		opts = append(opts, e.webhookOption(*cfg))
 	}
 
 	eng, err := warden.NewEngine(opts...)
@@ -181,6 +206,23 @@ func (e *Extension) init(fapp forge.App) error {
 	return nil
 }
 
+// webhookOption registers the webhook plugin once the engine's store is
+// resolved, since the plugin keeps its outbox in that store.
+func (e *Extension) webhookOption(cfg webhook.Config) warden.Option {
+	return func(eng *warden.Engine) {
+		s := eng.Store()
+		if s == nil {
+			return // NewEngine reports the missing store
+		}
+		wh, err := webhook.New(s, cfg, webhook.WithLogger(e.Logger()))
+		if err != nil {
+			return // cfg was validated by init
+		}
+		e.webhooks = wh
+		warden.WithPlugin(wh)(eng)
+	}
+}
+
 // Start begins the warden engine and runs migrations if enabled.
 func (e *Extension) Start(ctx context.Context) error {
 	if e.eng == nil {
@@ -215,6 +257,12 @@ func (e *Extension) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Deliver webhooks left in the outbox by a previous run, then new
+	// events as they occur. Engine.Stop stops the dispatcher.
+	if e.webhooks != nil {
+		e.webhooks.Start()
+	}
+
 	e.MarkStarted()
 	return nil
 }
@@ -350,6 +398,9 @@ func (e *Extension) mergeConfigurations(yamlConfig, programmaticConfig Config) C
 	if programmaticConfig.DisableMigrate {
 		yamlConfig.DisableMigrate = true
 	}
+	if programmaticConfig.DisableAudit {
+		yamlConfig.DisableAudit = true
+	}
 
 	// String fields: YAML takes precedence.
 	if yamlConfig.BasePath == "" && programmaticConfig.BasePath != "" {
@@ -359,6 +410,20 @@ func (e *Extension) mergeConfigurations(yamlConfig, programmaticConfig Config) C
 		yamlConfig.GroveDatabase = programmaticConfig.GroveDatabase
 	}
 
+	// Check-log rules, retention and webhooks: YAML takes precedence.
+	if yamlConfig.CheckLog == nil && programmaticConfig.CheckLog != nil {
+		yamlConfig.CheckLog = programmaticConfig.CheckLog
+	}
+	if yamlConfig.CheckLogRetention == nil && programmaticConfig.CheckLogRetention != nil {
+		yamlConfig.CheckLogRetention = programmaticConfig.CheckLogRetention
+	}
+	if yamlConfig.Webhooks == nil && programmaticConfig.Webhooks != nil {
+		yamlConfig.Webhooks = programmaticConfig.Webhooks
+	}
+	if len(yamlConfig.TrustedProxies) == 0 {
+		yamlConfig.TrustedProxies = programmaticConfig.TrustedProxies
+	}
+
 	// Int fields: YAML takes precedence, programmatic fills gaps.
 	if yamlConfig.MaxGraphDepth == 0 && programmaticConfig.MaxGraphDepth != 0 {
 		yamlConfig.MaxGraphDepth = programmaticConfig.MaxGraphDepth
@@ -452,6 +517,9 @@ func mergeDeclProgram(dst, src *dsl.Program) {
 	dst.Roles = append(dst.Roles, src.Roles...)
 	dst.Policies = append(dst.Policies, src.Policies...)
 	dst.Relations = append(dst.Relations, src.Relations...)
+	if dst.CheckLog == nil {
+		dst.CheckLog = src.CheckLog
+	}
 }
 
 func (e *Extension) buildStoreFromGroveDB(db *grove.DB) (store.Store, error) {
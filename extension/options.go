@@ -4,6 +4,7 @@ import (
 	"github.com/xraph/warden"
 	"github.com/xraph/warden/plugin"
 	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/webhook"
 )
 
 // Option configures the Warden Forge extension.
@@ -30,10 +31,12 @@ func WithEngineOptions(opts ...warden.Option) Option {
 	}
 }
 
-// WithPlugin registers a lifecycle hook plugin.
-func WithPlugin(x plugin.Plugin) Option {
+// WithPlugin registers a lifecycle hook plugin. opts set the precedence
+// and failure mode of its check hooks.
+func WithPlugin(x plugin.Plugin, opts ...plugin.RegisterOption) Option {
 	return func(e *Extension) {
 		e.plugins = append(e.plugins, x)
+		e.pluginOpts = append(e.pluginOpts, opts)
 	}
 }
 
@@ -51,6 +54,13 @@ func WithDisableMigrate() Option {
 	}
 }
 
+// WithDisableAudit stops recording model changes in the audit trail.
+func WithDisableAudit() Option {
+	return func(e *Extension) {
+		e.config.DisableAudit = true
+	}
+}
+
 // WithBasePath sets the URL prefix for warden routes.
 func WithBasePath(path string) Option {
 	return func(e *Extension) {
@@ -75,3 +85,24 @@ func WithGroveDatabase(name string) Option {
 		e.useGrove = true
 	}
 }
+
+// WithCheckLog sets check-log sampling and filtering rules.
+func WithCheckLog(cfg CheckLogConfig) Option {
+	return func(e *Extension) {
+		e.config.CheckLog = &cfg
+	}
+}
+
+// WithCheckLogRetention enables scheduled check-log purging.
+func WithCheckLogRetention(cfg CheckLogRetentionConfig) Option {
+	return func(e *Extension) {
+		e.config.CheckLogRetention = &cfg
+	}
+}
+
+// WithWebhooks enables the outbound webhook plugin.
+func WithWebhooks(cfg webhook.Config) Option {
+	return func(e *Extension) {
+		e.config.Webhooks = &cfg
+	}
+}
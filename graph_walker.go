@@ -57,9 +57,14 @@ func (w *bfsGraphWalker) Walk(ctx context.Context, relStore relation.Store, tena
 
 	visited := make(map[string]struct{})
 
+	// Report how deep the walk went on the ReBAC span, if traced.
+	reached := 0
+	defer func() { SpanFromContext(ctx).SetAttributes(Attr(AttrGraphDepth, reached)) }()
+
 	for len(queue) > 0 {
 		node := queue[0]
 		queue = queue[1:]
+		reached = max(reached, node.depth)
 
 		if node.depth > w.maxDepth {
 			return false, "", ErrGraphDepthExceeded
@@ -71,7 +76,9 @@ func (w *bfsGraphWalker) Walk(ctx context.Context, relStore relation.Store, tena
 		}
 		visited[visitKey] = struct{}{}
 
-		tuples, err := relStore.ListRelationSubjects(ctx, tenantID, namespaces, node.objectType, node.objectID, node.relation)
+		tuples, err := traceStore(ctx, "ListRelationSubjects", func(ctx context.Context) ([]*relation.Tuple, error) {
+			return relStore.ListRelationSubjects(ctx, tenantID, namespaces, node.objectType, node.objectID, node.relation)
+		})
 		if err != nil {
 			return false, "", fmt.Errorf("list subjects for %s: %w", visitKey, err)
 		}
@@ -0,0 +1,150 @@
+package warden
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/xraph/warden/relation"
+	"github.com/xraph/warden/resourcetype"
+)
+
+// hierarchySource resolves resource.parent.<attr> and resource.ancestors
+// from the parent relation tuples of one tenant for a single check,
+// reading the parent of each resource from the store at most once. A
+// failed read is not remembered, and its error fails the lookup.
+type hierarchySource struct {
+	store      relation.Store
+	tenantID   string
+	namespaces []string
+	maxDepth   int // 0 = bounded by cycle detection only
+
+	mu      sync.Mutex
+	parents map[string]*relation.Tuple // "type:id" → parent tuple, nil when none
+}
+
+// withHierarchy attaches the resource hierarchy of scope to ctx so
+// ResolveAttribute can follow parent relations.
+func (e *Engine) withHierarchy(ctx context.Context, scope tenantScope) context.Context {
+	return context.WithValue(ctx, ctxKeyHierarchy, &hierarchySource{
+		store:      e.store,
+		tenantID:   scope.tenantID,
+		namespaces: AncestorNamespaces(scope.namespacePath),
+		maxDepth:   e.config.MaxGraphDepth,
+		parents:    make(map[string]*relation.Tuple),
+	})
+}
+
+// resolve returns the value of a hierarchical field. ok is false when field
+// is not one, so the caller falls back to attribute providers.
+//
+// resource.parent is the parent as "type:id", resource.parent.type and
+// resource.parent.id its parts, and resource.parent.<attr> an attribute of
+// the parent, read from the parent tuple's metadata or else from the
+// AttributeProvider plugins as resource.<attr> of the parent. Hops chain:
+// resource.parent.parent.<attr> reads the grandparent.
+func (h *hierarchySource) resolve(ctx context.Context, field string, req *CheckRequest) (any, bool, error) {
+	rest, ok := strings.CutPrefix(field, "resource.")
+	if !ok {
+		return nil, false, nil
+	}
+	if rest == "ancestors" {
+		ancestors, err := h.ancestors(ctx, req.Resource)
+		return ancestors, true, err
+	}
+	hops := 0
+	for rest == resourcetype.ParentRelation || strings.HasPrefix(rest, resourcetype.ParentRelation+".") {
+		hops++
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, resourcetype.ParentRelation), ".")
+	}
+	if hops == 0 {
+		return nil, false, nil
+	}
+
+	var t *relation.Tuple
+	typ, id := req.Resource.Type, req.Resource.ID
+	for range hops {
+		var err error
+		if t, err = h.parent(ctx, typ, id); err != nil || t == nil {
+			return nil, true, err
+		}
+		typ, id = t.SubjectType, t.SubjectID
+	}
+
+	switch rest {
+	case "":
+		return typ + ":" + id, true, nil
+	case "type":
+		return typ, true, nil
+	case "id":
+		return id, true, nil
+	}
+	if v, ok := t.Metadata[rest]; ok {
+		return v, true, nil
+	}
+	if r, ok := ctx.Value(ctxKeyAttributeResolver).(*attributeResolver); ok {
+		parent := *req
+		parent.Resource = Resource{Type: typ, ID: id}
+		v, err := r.resolveAs(ctx, field, "resource."+rest, &parent)
+		return v, true, err
+	}
+	return nil, true, nil
+}
+
+// ancestors returns the resource's ancestors as "type:id", nearest first.
+// The walk stops at a resource without a parent, at a cycle, or after
+// Config.MaxGraphDepth hops.
+func (h *hierarchySource) ancestors(ctx context.Context, res Resource) ([]string, error) {
+	out := []string{}
+	seen := map[string]bool{res.Type + ":" + res.ID: true}
+	typ, id := res.Type, res.ID
+	for h.maxDepth <= 0 || len(out) < h.maxDepth {
+		t, err := h.parent(ctx, typ, id)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			break
+		}
+		typ, id = t.SubjectType, t.SubjectID
+		ref := typ + ":" + id
+		if seen[ref] {
+			break
+		}
+		seen[ref] = true
+		out = append(out, ref)
+	}
+	return out, nil
+}
+
+// parent returns the tuple naming the parent of typ:id, or nil when it has
+// none. A resource with several parents uses the first in (type, id)
+// order; userset subjects such as group:eng#member are not parents.
+func (h *hierarchySource) parent(ctx context.Context, typ, id string) (*relation.Tuple, error) {
+	if typ == "" || id == "" {
+		return nil, nil
+	}
+	key := typ + ":" + id
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if t, ok := h.parents[key]; ok {
+		return t, nil
+	}
+	tuples, err := h.store.ListRelationSubjects(ctx, h.tenantID, h.namespaces, typ, id, resourcetype.ParentRelation)
+	if err != nil {
+		return nil, fmt.Errorf("parent of %s: %w", key, err)
+	}
+	var parent *relation.Tuple
+	for _, t := range tuples {
+		if t.SubjectRelation != "" {
+			continue
+		}
+		if parent == nil || cmp.Or(cmp.Compare(t.SubjectType, parent.SubjectType), cmp.Compare(t.SubjectID, parent.SubjectID)) < 0 {
+			parent = t
+		}
+	}
+	h.parents[key] = parent
+	return parent, nil
+}
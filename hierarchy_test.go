@@ -0,0 +1,214 @@
+package warden
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/relation"
+	"github.com/xraph/warden/resourcetype"
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/store/memory"
+)
+
+// regionProvider supplies resource.region for orgs.
+type regionProvider struct {
+	calls map[string]int
+}
+
+func (p *regionProvider) Name() string { return "regions" }
+
+func (p *regionProvider) ProvideAttribute(_ context.Context, req any, field string) (any, bool, error) {
+	r, ok := req.(*CheckRequest)
+	if !ok || field != "resource.region" || r.Resource.Type != "org" {
+		return nil, false, nil
+	}
+	p.calls[r.Resource.ID]++
+	return "eu-" + r.Resource.ID, true, nil
+}
+
+func newHierarchyEngine(t *testing.T, maxDepth int) (*Engine, *memory.Store, *regionProvider) {
+	t.Helper()
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	prov := &regionProvider{calls: map[string]int{}}
+	cfg := DefaultConfig()
+	cfg.MaxGraphDepth = maxDepth
+	eng, err := NewEngine(WithStore(s), WithConfig(cfg), WithPlugin(prov))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := func(objType, objID, subjType, subjID string, meta map[string]any) {
+		t.Helper()
+		if err := s.CreateRelation(ctx, &relation.Tuple{
+			TenantID:   "t1",
+			ObjectType: objType, ObjectID: objID, Relation: "parent",
+			SubjectType: subjType, SubjectID: subjID,
+			Metadata: meta,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parent("document", "d1", "project", "p1", map[string]any{"classification": "secret"})
+	parent("document", "d2", "project", "p2", map[string]any{"classification": "public"})
+	parent("project", "p1", "org", "o1", nil)
+	parent("project", "p2", "org", "o1", nil)
+	parent("folder", "a", "folder", "b", nil)
+	parent("folder", "b", "folder", "a", nil)
+	return eng, s, prov
+}
+
+func TestResolveAttribute_Hierarchy(t *testing.T) {
+	eng, _, prov := newHierarchyEngine(t, 10)
+	attrs := eng.newAttributeResolver("t1")
+	ctx := eng.withHierarchy(withAttributeResolver(context.Background(), attrs), tenantScope{tenantID: "t1"})
+	doc := &CheckRequest{Resource: Resource{Type: "document", ID: "d1", Attributes: map[string]any{"ancestors": "given"}}}
+	folder := &CheckRequest{Resource: Resource{Type: "folder", ID: "a"}}
+
+	tests := []struct {
+		field string
+		req   *CheckRequest
+		want  any
+	}{
+		{"resource.parent", doc, "project:p1"},
+		{"resource.parent.type", doc, "project"},
+		{"resource.parent.id", doc, "p1"},
+		{"resource.parent.classification", doc, "secret"},
+		{"resource.parent.parent", doc, "org:o1"},
+		{"resource.parent.parent.region", doc, "eu-o1"},
+		{"resource.parent.parent.parent", doc, nil},
+		{"resource.parental", doc, nil},
+		{"resource.ancestors", doc, "given"}, // request attributes win
+		{"resource.ancestors", &CheckRequest{Resource: Resource{Type: "document", ID: "d2"}}, []string{"project:p2", "org:o1"}},
+		{"resource.ancestors", folder, []string{"folder:b"}}, // cycle
+		{"resource.ancestors", &CheckRequest{Resource: Resource{Type: "document"}}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := ResolveAttribute(ctx, tt.field, tt.req); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s of %s:%s = %#v, want %#v", tt.field, tt.req.Resource.Type, tt.req.Resource.ID, got, tt.want)
+		}
+	}
+
+	ResolveAttribute(ctx, "resource.parent.parent.region", doc)
+	if prov.calls["o1"] != 1 {
+		t.Errorf("provider asked %d times for o1, want 1", prov.calls["o1"])
+	}
+	trace := attrs.decisionTrace()
+	if trace == nil || trace.Attributes[0].Field != "resource.parent.parent.region" || trace.Attributes[0].Provider != "regions" {
+		t.Errorf("trace = %+v, want the parent lookup traced under the check's field", trace)
+	}
+}
+
+func TestResolveAttribute_HierarchyDepth(t *testing.T) {
+	eng, _, _ := newHierarchyEngine(t, 1)
+	ctx := eng.withHierarchy(context.Background(), tenantScope{tenantID: "t1"})
+	got := ResolveAttribute(ctx, "resource.ancestors", &CheckRequest{Resource: Resource{Type: "document", ID: "d1"}})
+	if want := []string{"project:p1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ancestors = %v, want %v", got, want)
+	}
+}
+
+func TestEngine_ParentAttributeConditions(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s, _ := newHierarchyEngine(t, 10)
+	for _, p := range []*policy.Policy{{
+		TenantID: "t1", Name: "in-eu-orgs", Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Field: "resource.ancestors", Operator: policy.OpContainsElement, Value: "org:o1"},
+			{Field: "resource.parent.parent.region", Operator: policy.OpEquals, Value: "eu-o1"},
+		},
+	}, {
+		TenantID: "t1", Name: "no-secret-projects", Effect: policy.EffectDeny, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Field: "resource.parent.classification", Operator: policy.OpEquals, Value: "secret"},
+		},
+	}} {
+		if err := s.CreatePolicy(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for doc, want := range map[string]bool{"d1": false, "d2": true, "d3": false} {
+		result, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: "read"},
+			Resource: Resource{Type: "document", ID: doc},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != want {
+			t.Errorf("read document:%s allowed = %v, want %v (%s: %s)", doc, result.Allowed, want, result.Decision, result.Reason)
+		}
+	}
+}
+
+// flakyRelationStore fails parent lookups while fail is set.
+type flakyRelationStore struct {
+	store.Store
+	fail atomic.Bool
+}
+
+func (s *flakyRelationStore) ListRelationSubjects(ctx context.Context, tenantID string, namespaces []string, objType, objID, rel string) ([]*relation.Tuple, error) {
+	if rel == resourcetype.ParentRelation && s.fail.Load() {
+		return nil, errors.New("relation store unavailable")
+	}
+	return s.Store.ListRelationSubjects(ctx, tenantID, namespaces, objType, objID, rel)
+}
+
+func TestEngine_ParentLookupErrorIsIndeterminate(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	_, mem, _ := newHierarchyEngine(t, 10)
+	s := &flakyRelationStore{Store: mem}
+	eng, err := NewEngine(WithStore(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "allow-read", Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "no-secret-projects", Effect: policy.EffectDeny, IsActive: true,
+		Actions: []string{"read"},
+		Conditions: []policy.Condition{
+			{Field: "resource.parent.classification", Operator: policy.OpEquals, Value: "secret"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	req := &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "document", ID: "d1"},
+	}
+
+	// A failed parent lookup must not read as "no parent" and skip the deny.
+	s.fail.Store(true)
+	result, err := eng.Check(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decision != DecisionIndeterminate || len(result.Errors) != 1 || result.Errors[0].PolicyName != "no-secret-projects" {
+		t.Fatalf("got %s with errors %+v, want indeterminate from no-secret-projects", result.Decision, result.Errors)
+	}
+
+	// The failure is not remembered: a later lookup in the same check sees
+	// the parent once the store recovers.
+	hctx := eng.withHierarchy(context.Background(), tenantScope{tenantID: "t1"})
+	if _, err := LookupAttribute(hctx, "resource.ancestors", req); err == nil {
+		t.Fatal("expected the parent lookup error")
+	}
+	s.fail.Store(false)
+	got, err := LookupAttribute(hctx, "resource.ancestors", req)
+	if want := []string{"project:p1", "org:o1"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ancestors = %v, %v, want %v", got, err, want)
+	}
+}
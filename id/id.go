@@ -24,14 +24,19 @@ type Prefix string
 
 // Prefix constants for all Warden entity types.
 const (
-	PrefixRole         Prefix = "role"
-	PrefixPermission   Prefix = "perm"
-	PrefixAssignment   Prefix = "asgn"
-	PrefixPolicy       Prefix = "wpol"
-	PrefixRelation     Prefix = "rel"
-	PrefixCheckLog     Prefix = "chklog"
-	PrefixResourceType Prefix = "rtype"
-	PrefixCondition    Prefix = "cond"
+	PrefixRole            Prefix = "role"
+	PrefixPermission      Prefix = "perm"
+	PrefixAssignment      Prefix = "asgn"
+	PrefixPolicy          Prefix = "wpol"
+	PrefixRelation        Prefix = "rel"
+	PrefixCheckLog        Prefix = "chklog"
+	PrefixResourceType    Prefix = "rtype"
+	PrefixCondition       Prefix = "cond"
+	PrefixWebhookDelivery Prefix = "whdlv"
+	PrefixAudit           Prefix = "audit"
+	PrefixBreakGlass      Prefix = "bglass"
+	PrefixCalendar        Prefix = "cal"
+	PrefixSoDConstraint   Prefix = "sod"
 )
 
 // ID is the primary identifier type for all Warden entities.
@@ -136,6 +141,22 @@ type ResourceTypeID = ID
 // ConditionID is a type-safe identifier for policy conditions (prefix: "cond").
 type ConditionID = ID
 
+// WebhookDeliveryID is a type-safe identifier for webhook outbox deliveries (prefix: "whdlv").
+type WebhookDeliveryID = ID
+
+// AuditID is a type-safe identifier for audit trail entries (prefix: "audit").
+type AuditID = ID
+
+// BreakGlassID is a type-safe identifier for break-glass grants (prefix: "bglass").
+type BreakGlassID = ID
+
+// CalendarID is a type-safe identifier for named calendars (prefix: "cal").
+type CalendarID = ID
+
+// SoDConstraintID is a type-safe identifier for separation-of-duties
+// constraints (prefix: "sod").
+type SoDConstraintID = ID
+
 // AnyID is a type alias that accepts any valid prefix.
 type AnyID = ID
 
@@ -167,6 +188,21 @@ func NewResourceTypeID() ID { return New(PrefixResourceType) }
 // NewConditionID generates a new unique condition ID.
 func NewConditionID() ID { return New(PrefixCondition) }
 
+// NewWebhookDeliveryID generates a new unique webhook delivery ID.
+func NewWebhookDeliveryID() ID { return New(PrefixWebhookDelivery) }
+
+// NewAuditID generates a new unique audit entry ID.
+func NewAuditID() ID { return New(PrefixAudit) }
+
+// NewBreakGlassID generates a new unique break-glass grant ID.
+func NewBreakGlassID() ID { return New(PrefixBreakGlass) }
+
+// NewCalendarID generates a new unique calendar ID.
+func NewCalendarID() ID { return New(PrefixCalendar) }
+
+// NewSoDConstraintID generates a new unique separation-of-duties constraint ID.
+func NewSoDConstraintID() ID { return New(PrefixSoDConstraint) }
+
 // ──────────────────────────────────────────────────
 // Convenience parsers
 // ──────────────────────────────────────────────────
@@ -195,6 +231,21 @@ func ParseResourceTypeID(s string) (ID, error) { return ParseWithPrefix(s, Prefi
 // ParseConditionID parses a string and validates the "cond" prefix.
 func ParseConditionID(s string) (ID, error) { return ParseWithPrefix(s, PrefixCondition) }
 
+// ParseWebhookDeliveryID parses a string and validates the "whdlv" prefix.
+func ParseWebhookDeliveryID(s string) (ID, error) { return ParseWithPrefix(s, PrefixWebhookDelivery) }
+
+// ParseAuditID parses a string and validates the "audit" prefix.
+func ParseAuditID(s string) (ID, error) { return ParseWithPrefix(s, PrefixAudit) }
+
+// ParseBreakGlassID parses a string and validates the "bglass" prefix.
+func ParseBreakGlassID(s string) (ID, error) { return ParseWithPrefix(s, PrefixBreakGlass) }
+
+// ParseCalendarID parses a string and validates the "cal" prefix.
+func ParseCalendarID(s string) (ID, error) { return ParseWithPrefix(s, PrefixCalendar) }
+
+// ParseSoDConstraintID parses a string and validates the "sod" prefix.
+func ParseSoDConstraintID(s string) (ID, error) { return ParseWithPrefix(s, PrefixSoDConstraint) }
+
 // ParseAny parses a string into an ID without type checking the prefix.
 func ParseAny(s string) (ID, error) { return Parse(s) }
 
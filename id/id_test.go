@@ -21,6 +21,11 @@ func TestConstructors(t *testing.T) {
 		{"CheckLogID", id.NewCheckLogID, "chklog_"},
 		{"ResourceTypeID", id.NewResourceTypeID, "rtype_"},
 		{"ConditionID", id.NewConditionID, "cond_"},
+		{"WebhookDeliveryID", id.NewWebhookDeliveryID, "whdlv_"},
+		{"AuditID", id.NewAuditID, "audit_"},
+		{"BreakGlassID", id.NewBreakGlassID, "bglass_"},
+		{"CalendarID", id.NewCalendarID, "cal_"},
+		{"SoDConstraintID", id.NewSoDConstraintID, "sod_"},
 	}
 
 	for _, tt := range tests {
@@ -57,6 +62,11 @@ func TestParseRoundTrip(t *testing.T) {
 		{"CheckLogID", id.NewCheckLogID, id.ParseCheckLogID},
 		{"ResourceTypeID", id.NewResourceTypeID, id.ParseResourceTypeID},
 		{"ConditionID", id.NewConditionID, id.ParseConditionID},
+		{"WebhookDeliveryID", id.NewWebhookDeliveryID, id.ParseWebhookDeliveryID},
+		{"AuditID", id.NewAuditID, id.ParseAuditID},
+		{"BreakGlassID", id.NewBreakGlassID, id.ParseBreakGlassID},
+		{"CalendarID", id.NewCalendarID, id.ParseCalendarID},
+		{"SoDConstraintID", id.NewSoDConstraintID, id.ParseSoDConstraintID},
 	}
 
 	for _, tt := range tests {
@@ -87,6 +97,11 @@ func TestCrossTypeRejection(t *testing.T) {
 		{"ParseCheckLogID rejects rtype_", id.NewResourceTypeID().String(), id.ParseCheckLogID},
 		{"ParseResourceTypeID rejects cond_", id.NewConditionID().String(), id.ParseResourceTypeID},
 		{"ParseConditionID rejects role_", id.NewRoleID().String(), id.ParseConditionID},
+		{"ParseWebhookDeliveryID rejects chklog_", id.NewCheckLogID().String(), id.ParseWebhookDeliveryID},
+		{"ParseAuditID rejects whdlv_", id.NewWebhookDeliveryID().String(), id.ParseAuditID},
+		{"ParseBreakGlassID rejects asgn_", id.NewAssignmentID().String(), id.ParseBreakGlassID},
+		{"ParseCalendarID rejects bglass_", id.NewBreakGlassID().String(), id.ParseCalendarID},
+		{"ParseSoDConstraintID rejects cal_", id.NewCalendarID().String(), id.ParseSoDConstraintID},
 	}
 
 	for _, tt := range tests {
@@ -109,6 +124,11 @@ func TestParseAny(t *testing.T) {
 		id.NewCheckLogID(),
 		id.NewResourceTypeID(),
 		id.NewConditionID(),
+		id.NewWebhookDeliveryID(),
+		id.NewAuditID(),
+		id.NewBreakGlassID(),
+		id.NewCalendarID(),
+		id.NewSoDConstraintID(),
 	}
 
 	for _, i := range ids {
@@ -202,6 +202,8 @@ func topLevelKeywordItems() []completionItem {
 		{"policy", "Declare an ABAC/PBAC policy with effect, conditions, and obligations."},
 		{"relation", "Declare a relation tuple (initial state)."},
 		{"import", `Import another .warden file — import "shared/policies.warden".`},
+		{"checklog", "Configure check-log sampling and filtering (top level only)."},
+		{"sod", "Declare roles no subject may combine (top level only)."},
 	}
 	out := make([]completionItem, 0, len(keywords))
 	for _, k := range keywords {
@@ -323,6 +325,8 @@ func policyFieldItems() []completionItem {
 		{"effect", "allow | deny — the policy effect."},
 		{"priority", "Integer; lower priority wins when multiple policies match."},
 		{"active", "Whether the policy is active. PBAC: see also not_before / not_after."},
+		{"mode", "enforce | shadow | disabled — shadow policies are evaluated and reported but never change the decision."},
+		{"on_error", "deny | skip — deny (default) makes the check indeterminate when conditions fail to evaluate; skip ignores the policy."},
 		{"not_before", `PBAC: RFC3339 instant — policy is inactive before this time. e.g. "2026-06-01T00:00:00Z".`},
 		{"not_after", `PBAC: RFC3339 instant — policy is inactive after this time.`},
 		{"obligations", `PBAC: list of named side-effect actions emitted on match. e.g. ["audit-log","require-mfa"].`},
@@ -383,6 +387,15 @@ func conditionOperatorItems() []completionItem {
 		{"ip_in_cidr", "IP address falls within a CIDR range."},
 		{"time_after", "Time is after a threshold."},
 		{"time_before", "Time is before a threshold."},
+		{"day_of_week", "Time falls on one of the given days, e.g. \"mon-fri\"."},
+		{"time_of_day", "Time falls within a daily range, e.g. \"09:00-17:00\"."},
+		{"schedule", "Time matches a five-field cron expression."},
+		{"in_calendar", "Time falls on a date of a named calendar."},
+		{"any_in", "Collection shares at least one element with a list."},
+		{"all_in", "Every element of the collection is in a list."},
+		{"contains_element", "Collection contains the given element."},
+		{"size_gt", "Collection has more than N elements."},
+		{"size_lt", "Collection has fewer than N elements."},
 	}
 	out := make([]completionItem, 0, len(ops))
 	for _, o := range ops {
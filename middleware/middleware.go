@@ -2,7 +2,13 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync/atomic"
 
 	"github.com/xraph/forge"
 
@@ -18,7 +24,7 @@ func Require(eng *warden.Engine, action, resourceType string) forge.Middleware {
 			subject := resolveSubject(ctx)
 			resourceID := ctx.Param("id")
 
-			err := eng.Enforce(ctx.Context(), &warden.CheckRequest{
+			err := eng.Enforce(RequestContext(ctx), &warden.CheckRequest{
 				Subject:  subject,
 				Action:   warden.Action{Name: action},
 				Resource: warden.Resource{Type: resourceType, ID: resourceID},
@@ -36,10 +42,11 @@ func RequireAny(eng *warden.Engine, checks ...warden.CheckRequest) forge.Middlew
 	return func(next forge.Handler) forge.Handler {
 		return func(ctx forge.Context) error {
 			subject := resolveSubject(ctx)
+			reqCtx := RequestContext(ctx)
 			for i := range checks {
 				c := checks[i]
 				c.Subject = subject
-				result, err := eng.Check(ctx.Context(), &c)
+				result, err := eng.Check(reqCtx, &c)
 				if err == nil && result.Allowed {
 					return next(ctx)
 				}
@@ -54,10 +61,11 @@ func RequireAll(eng *warden.Engine, checks ...warden.CheckRequest) forge.Middlew
 	return func(next forge.Handler) forge.Handler {
 		return func(ctx forge.Context) error {
 			subject := resolveSubject(ctx)
+			reqCtx := RequestContext(ctx)
 			for i := range checks {
 				c := checks[i]
 				c.Subject = subject
-				err := eng.Enforce(ctx.Context(), &c)
+				err := eng.Enforce(reqCtx, &c)
 				if err != nil {
 					return denyResponse(ctx)
 				}
@@ -76,6 +84,105 @@ func resolveSubject(ctx forge.Context) warden.Subject {
 	return warden.Subject{Kind: "unknown", ID: "anonymous"}
 }
 
+// trustedProxies holds the prefixes set by SetTrustedProxies.
+var trustedProxies atomic.Pointer[[]netip.Prefix]
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-For and X-Real-IP
+// headers RequestContext believes. Each entry is an IP address or a CIDR.
+// With none set, the default, proxy headers are ignored and the
+// connection's remote address is recorded, since any client can send them.
+func SetTrustedProxies(proxies ...string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if pfx, err := netip.ParsePrefix(p); err == nil {
+			prefixes = append(prefixes, pfx.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(p)
+		if err != nil {
+			return fmt.Errorf("warden: trusted proxy %q is not an IP or CIDR", p)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	trustedProxies.Store(&prefixes)
+	return nil
+}
+
+// RequestContext returns the request's context annotated with the caller's
+// IP and request ID so they are recorded on check log entries.
+// The IP is the connection's remote address. When that address is a
+// trusted proxy (see SetTrustedProxies), it is instead the last
+// X-Forwarded-For hop that is not a trusted proxy, or X-Real-IP. The
+// request ID comes from Forge's request logger, falling back to the
+// X-Request-ID header.
+func RequestContext(ctx forge.Context) context.Context {
+	c := ctx.Context()
+	requestID := forge.RequestIDFromContext(c)
+	if requestID == "" {
+		requestID = ctx.Header("X-Request-ID")
+	}
+	return warden.WithRequestInfo(c, clientIP(ctx), requestID)
+}
+
+// clientIP resolves the caller's IP address.
+func clientIP(ctx forge.Context) string {
+	r := ctx.Request()
+	if r == nil {
+		return ""
+	}
+	var trusted []netip.Prefix
+	if p := trustedProxies.Load(); p != nil {
+		trusted = *p
+	}
+	return resolveClientIP(r.RemoteAddr, ctx.Header("X-Forwarded-For"), ctx.Header("X-Real-IP"), trusted)
+}
+
+// resolveClientIP picks the client address from the connection's remote
+// address and the proxy headers. The headers are only read when the
+// remote address is trusted, and X-Forwarded-For is walked from the
+// nearest hop back, so a client cannot prepend a forged address.
+func resolveClientIP(remoteAddr, xff, realIP string, trusted []netip.Prefix) string {
+	remote := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remote = host
+	}
+	if !isTrustedProxy(remote, trusted) {
+		return remote
+	}
+	if xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && !isTrustedProxy(hop, trusted) {
+				return hop
+			}
+		}
+		if first := strings.TrimSpace(hops[0]); first != "" {
+			return first // every hop is a trusted proxy
+		}
+	}
+	if ip := strings.TrimSpace(realIP); ip != "" {
+		return ip
+	}
+	return remote
+}
+
+func isTrustedProxy(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 func denyResponse(ctx forge.Context) error {
 	ctx.SetHeader("Content-Type", "application/json")
 	ctx.Response().WriteHeader(403)
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	cases := []struct {
+		name, remote, xff, realIP string
+		trusted                   []netip.Prefix
+		want                      string
+	}{
+		{"no trusted proxies ignores headers", "203.0.113.7:5000", "1.2.3.4", "5.6.7.8", nil, "203.0.113.7"},
+		{"untrusted remote ignores headers", "203.0.113.7:5000", "1.2.3.4", "", trusted, "203.0.113.7"},
+		{"trusted remote uses forwarded hop", "10.0.0.2:5000", "198.51.100.9", "", trusted, "198.51.100.9"},
+		{"forged leftmost hop skipped", "10.0.0.2:5000", "1.2.3.4, 198.51.100.9, 10.0.0.5", "", trusted, "198.51.100.9"},
+		{"all hops trusted", "10.0.0.2:5000", "10.0.0.9, 10.0.0.5", "", trusted, "10.0.0.9"},
+		{"trusted remote uses X-Real-IP", "10.0.0.2:5000", "", "198.51.100.9", trusted, "198.51.100.9"},
+		{"trusted remote without headers", "10.0.0.2:5000", "", "", trusted, "10.0.0.2"},
+		{"remote without port", "203.0.113.7", "", "", nil, "203.0.113.7"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveClientIP(tc.remote, tc.xff, tc.realIP, tc.trusted); got != tc.want {
+				t.Errorf("resolveClientIP = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxies(t *testing.T) {
+	t.Cleanup(func() { _ = SetTrustedProxies() })
+	if err := SetTrustedProxies("10.0.0.0/8", "192.168.1.1", "::1"); err != nil {
+		t.Fatal(err)
+	}
+	got := *trustedProxies.Load()
+	if len(got) != 3 || got[1].Bits() != 32 || got[2].Bits() != 128 {
+		t.Errorf("prefixes = %v", got)
+	}
+	if err := SetTrustedProxies("not-an-ip"); err == nil {
+		t.Error("expected error for an invalid entry")
+	}
+}
@@ -0,0 +1,217 @@
+package warden
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/xraph/warden/policy"
+)
+
+// Obligation is a structured obligation or advice emitted by a matched
+// policy, with its parameters resolved against the check request.
+//
+// Obligations must be honoured by the caller before acting on the
+// decision (for example "require_mfa" with max_age=300); advice is
+// informational and may be ignored.
+type Obligation struct {
+	Name      string           `json:"name"`
+	Params    map[string]any   `json:"params,omitempty"`
+	FulfillOn policy.FulfillOn `json:"fulfill_on,omitempty"`
+	PolicyID  string           `json:"policy_id,omitempty"`
+}
+
+// resolveObligations interpolates the policy's obligation templates
+// against req. The result keeps FulfillOn so the engine can drop the ones
+// that don't apply once the final decision is known.
+func resolveObligations(ctx context.Context, pol *policy.Policy, templates []policy.Obligation, req *CheckRequest) []Obligation {
+	if len(templates) == 0 {
+		return nil
+	}
+	out := make([]Obligation, 0, len(templates))
+	for _, ob := range templates {
+		resolved := Obligation{
+			Name:      ob.Name,
+			FulfillOn: ob.FulfillOn,
+			PolicyID:  pol.ID.String(),
+		}
+		if len(ob.Params) > 0 {
+			resolved.Params = make(map[string]any, len(ob.Params))
+			for k, v := range ob.Params {
+				resolved.Params[k] = interpolateParam(ctx, v, req)
+			}
+		}
+		out = append(out, resolved)
+	}
+	return out
+}
+
+// cloneObligations returns a deep copy of obs, params included.
+func cloneObligations(obs []Obligation) []Obligation {
+	if obs == nil {
+		return nil
+	}
+	out := make([]Obligation, len(obs))
+	for i, ob := range obs {
+		out[i] = ob
+		if ob.Params != nil {
+			out[i].Params = make(map[string]any, len(ob.Params))
+			for k, v := range ob.Params {
+				out[i].Params[k] = cloneParam(v)
+			}
+		}
+	}
+	return out
+}
+
+// cloneParam deep-copies the maps and lists of an obligation parameter.
+func cloneParam(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = cloneParam(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = cloneParam(item)
+		}
+		return out
+	case []string:
+		return slices.Clone(val)
+	default:
+		return v
+	}
+}
+
+// obligationRefs records whether a check interpolated an obligation or
+// advice parameter, so a result whose obligations depend on the request's
+// context or attributes, which the cache key leaves out, is not cached.
+type obligationRefs struct {
+	found atomic.Bool
+}
+
+// withObligationRefs attaches a fresh obligationRefs to ctx.
+func withObligationRefs(ctx context.Context) (context.Context, *obligationRefs) {
+	refs := &obligationRefs{}
+	return context.WithValue(ctx, ctxKeyObligationRefs, refs), refs
+}
+
+// used reports whether any parameter was interpolated.
+func (r *obligationRefs) used() bool {
+	return r != nil && r.found.Load()
+}
+
+func markObligationRef(ctx context.Context) {
+	if r, ok := ctx.Value(ctxKeyObligationRefs).(*obligationRefs); ok {
+		r.found.Store(true)
+	}
+}
+
+// interpolateParam replaces "${field}" references in v with request
+// attributes. A string that is exactly one reference takes the
+// attribute's value unchanged, so numbers and lists keep their type;
+// references embedded in longer strings are formatted with %v. Missing
+// attributes resolve to nil (or "" when embedded). Maps and lists are
+// interpolated recursively.
+func interpolateParam(ctx context.Context, v any, req *CheckRequest) any {
+	switch val := v.(type) {
+	case string:
+		if !strings.Contains(val, "${") {
+			return val
+		}
+		markObligationRef(ctx)
+		if field, ok := wholeReference(val); ok {
+			return ResolveAttribute(ctx, field, req)
+		}
+		return expandReferences(ctx, val, req)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = interpolateParam(ctx, item, req)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = interpolateParam(ctx, item, req)
+		}
+		return out
+	case []string:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = interpolateParam(ctx, item, req)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func wholeReference(s string) (string, bool) {
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return "", false
+	}
+	field := s[2 : len(s)-1]
+	if field == "" || strings.ContainsAny(field, "${}") {
+		return "", false
+	}
+	return field, true
+}
+
+func expandReferences(ctx context.Context, s string, req *CheckRequest) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		if v := ResolveAttribute(ctx, s[start+2:start+end], req); v != nil {
+			fmt.Fprint(&b, v)
+		}
+		s = s[start+end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// fulfillObligations drops structured obligations and advice whose
+// FulfillOn doesn't match the final decision, and adds the names of the
+// remaining obligations to Obligations so check logs, webhooks and the
+// PolicyObligationFired hook see them. result itself is never modified,
+// so cached results stay intact.
+func fulfillObligations(result *CheckResult) *CheckResult {
+	if len(result.StructuredObligations) == 0 && len(result.Advice) == 0 {
+		return result
+	}
+	out := *result
+	out.StructuredObligations = filterObligations(result.StructuredObligations, result.Allowed)
+	out.Advice = filterObligations(result.Advice, result.Allowed)
+	if len(out.StructuredObligations) > 0 {
+		names := append([]string(nil), result.Obligations...)
+		for _, ob := range out.StructuredObligations {
+			names = append(names, ob.Name)
+		}
+		out.Obligations = dedupeStrings(names)
+	}
+	return &out
+}
+
+func filterObligations(obs []Obligation, allowed bool) []Obligation {
+	var out []Obligation
+	for _, ob := range obs {
+		if ob.FulfillOn.Applies(allowed) {
+			out = append(out, ob)
+		}
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package warden
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/store/memory"
+)
+
+func TestInterpolateParam(t *testing.T) {
+	req := &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1", Attributes: map[string]any{"email": "u1@example.com", "level": 3}},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "document", ID: "doc1"},
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{"plain string", "static", "static"},
+		{"whole reference keeps type", "${subject.level}", 3},
+		{"embedded reference", "${subject.id} read ${resource.type}/${resource.id}", "u1 read document/doc1"},
+		{"missing whole reference", "${context.nope}", nil},
+		{"missing embedded reference", "x=${context.nope}", "x="},
+		{"unterminated", "${subject.id", "${subject.id"},
+		{"int", 300, 300},
+		{"list", []string{"${subject.email}", "ops@example.com"}, []any{"u1@example.com", "ops@example.com"}},
+		{"nested map", map[string]any{"to": "${subject.email}"}, map[string]any{"to": "u1@example.com"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := interpolateParam(ctx, tc.in, req); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("interpolateParam(%v) = %#v, want %#v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestEngine_StructuredObligations covers parameter interpolation and
+// fulfill_on filtering through a full check, for both decisions.
+func TestEngine_StructuredObligations(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	store := memory.New()
+
+	obligations := []policy.Obligation{
+		{Name: "require_mfa", Params: map[string]any{"max_age": 300}, FulfillOn: policy.FulfillOnAllow},
+		{Name: "notify", Params: map[string]any{"who": "${subject.id}"}, FulfillOn: policy.FulfillOnDeny},
+		{Name: "audit_log"},
+	}
+	advice := []policy.Obligation{
+		{Name: "show_banner", Params: map[string]any{"text": "viewing ${resource.id}"}, FulfillOn: policy.FulfillOnAllow},
+	}
+	for _, p := range []*policy.Policy{
+		{
+			TenantID: "t1", Name: "read-docs", Effect: policy.EffectAllow, IsActive: true,
+			Actions: []string{"read"}, Resources: []string{"document"},
+			StructuredObligations: obligations, Advice: advice,
+		},
+		{
+			TenantID: "t1", Name: "no-deletes", Effect: policy.EffectDeny, IsActive: true,
+			Actions: []string{"delete"}, Resources: []string{"document"},
+			Obligations:           []string{"legacy"},
+			StructuredObligations: obligations, Advice: advice,
+		},
+	} {
+		if err := store.CreatePolicy(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	eng, err := NewEngine(WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(action string) *CheckResult {
+		t.Helper()
+		res, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: action},
+			Resource: Resource{Type: "document", ID: "doc1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	names := func(obs []Obligation) []string {
+		out := make([]string, len(obs))
+		for i, ob := range obs {
+			out[i] = ob.Name
+		}
+		return out
+	}
+
+	allowed := check("read")
+	if !allowed.Allowed {
+		t.Fatalf("expected allow, got %+v", allowed)
+	}
+	if got := names(allowed.StructuredObligations); !slices.Equal(got, []string{"require_mfa", "audit_log"}) {
+		t.Errorf("allow obligations = %v", got)
+	}
+	if got := allowed.StructuredObligations[0].Params["max_age"]; got != 300 {
+		t.Errorf("max_age = %v, want 300", got)
+	}
+	if allowed.StructuredObligations[0].PolicyID == "" {
+		t.Error("PolicyID not set")
+	}
+	if len(allowed.Advice) != 1 || allowed.Advice[0].Params["text"] != "viewing doc1" {
+		t.Errorf("allow advice = %+v", allowed.Advice)
+	}
+	if !slices.Equal(allowed.Obligations, []string{"require_mfa", "audit_log"}) {
+		t.Errorf("Obligations = %v, want structured names", allowed.Obligations)
+	}
+
+	denied := check("delete")
+	if denied.Allowed {
+		t.Fatalf("expected deny, got %+v", denied)
+	}
+	if got := names(denied.StructuredObligations); !slices.Equal(got, []string{"notify", "audit_log"}) {
+		t.Errorf("deny obligations = %v", got)
+	}
+	if got := denied.StructuredObligations[0].Params["who"]; got != "u1" {
+		t.Errorf("who = %v, want u1", got)
+	}
+	if len(denied.Advice) != 0 {
+		t.Errorf("deny advice = %+v, want none", denied.Advice)
+	}
+	if !slices.Equal(denied.Obligations, []string{"legacy", "notify", "audit_log"}) {
+		t.Errorf("Obligations = %v", denied.Obligations)
+	}
+}
+
+// Results whose obligation or advice parameters are interpolated from the
+// request are not cached, since the cache key leaves out the request's
+// context and attributes.
+func TestEngine_InterpolatedObligationsNotCached(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	store := memory.New()
+	for _, p := range []*policy.Policy{
+		{
+			TenantID: "t1", Name: "read-docs", Effect: policy.EffectAllow, IsActive: true,
+			Actions: []string{"read"}, Resources: []string{"document"},
+			StructuredObligations: []policy.Obligation{{Name: "require_mfa", Params: map[string]any{"max_age": 300}}},
+		},
+		{
+			TenantID: "t1", Name: "edit-docs", Effect: policy.EffectAllow, IsActive: true,
+			Actions: []string{"edit"}, Resources: []string{"document"},
+			Advice: []policy.Obligation{{Name: "show_banner", Params: map[string]any{"text": "editing from ${context.ip}"}}},
+		},
+	} {
+		if err := store.CreatePolicy(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &recordingCache{}
+	eng, err := NewEngine(WithStore(store), WithCache(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(action, ip string) *CheckResult {
+		t.Helper()
+		res, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: action},
+			Resource: Resource{Type: "document", ID: "doc1"},
+			Context:  map[string]any{"ip": ip},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	check("read", "10.0.0.1")
+	if c.sets != 1 {
+		t.Fatalf("expected a result with literal params to be cached, got %d sets", c.sets)
+	}
+	if res := check("edit", "10.0.0.2"); len(res.Advice) != 1 || res.Advice[0].Params["text"] != "editing from 10.0.0.2" {
+		t.Fatalf("advice = %+v", res.Advice)
+	}
+	if c.sets != 1 {
+		t.Fatalf("expected a result with interpolated params not to be cached, got %d sets", c.sets)
+	}
+}
@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SpanData is a finished span as handed to exporters.
+type SpanData struct {
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Name         string         `json:"name"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+}
+
+// Duration is the span's wall-clock duration.
+func (d SpanData) Duration() time.Duration { return d.End.Sub(d.Start) }
+
+// SpanExporter receives every finished span. ExportSpan is called on the
+// check path, so implementations that do I/O should buffer or hand off.
+type SpanExporter interface {
+	ExportSpan(span SpanData)
+}
+
+// StdoutExporter writes each span as one JSON line.
+type StdoutExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewStdoutExporter returns an exporter writing to w, or to os.Stdout when
+// w is nil.
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &StdoutExporter{enc: json.NewEncoder(w)}
+}
+
+// ExportSpan implements SpanExporter.
+func (x *StdoutExporter) ExportSpan(span SpanData) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	_ = x.enc.Encode(span) //nolint:errcheck // best-effort diagnostics output
+}
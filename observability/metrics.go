@@ -0,0 +1,198 @@
+package observability
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// durationBuckets are the latency histogram bounds in seconds, from 50µs
+// to 1s.
+var durationBuckets = []float64{0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Handler serves the plugin's metrics in the Prometheus text exposition
+// format, for scraping at e.g. /metrics.
+func (p *Plugin) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = p.WriteMetrics(w) //nolint:errcheck // client went away
+	})
+}
+
+// WriteMetrics writes every metric in the Prometheus text exposition
+// format.
+func (p *Plugin) WriteMetrics(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	p.checks.write(bw)
+	p.checkDuration.write(bw)
+	p.cacheLookups.write(bw)
+	p.writeCacheHitRatio(bw)
+	p.stageDuration.write(bw)
+	p.storeDuration.write(bw)
+	p.graphDepth.write(bw)
+	p.policyCount.write(bw)
+	p.mutations.write(bw)
+	return bw.Flush()
+}
+
+// writeCacheHitRatio derives the hit ratio gauge from the lookup counter.
+func (p *Plugin) writeCacheHitRatio(w io.Writer) {
+	hits, misses := p.cacheLookups.value("hit"), p.cacheLookups.value("miss")
+	if hits+misses == 0 {
+		return
+	}
+	fmt.Fprintln(w, "# HELP warden_check_cache_hit_ratio Share of check cache lookups that hit.")
+	fmt.Fprintln(w, "# TYPE warden_check_cache_hit_ratio gauge")
+	fmt.Fprintf(w, "warden_check_cache_hit_ratio %s\n", formatFloat(hits/(hits+misses)))
+}
+
+// counter is a labelled Prometheus counter.
+type counter struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	n      float64
+}
+
+func newCounter(name, help string, labels ...string) *counter {
+	return &counter{name: name, help: help, labels: labels, series: make(map[string]*counterSeries)}
+}
+
+func (c *counter) add(n float64, values ...string) {
+	key := strings.Join(values, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{values: values}
+		c.series[key] = s
+	}
+	s.n += n
+}
+
+func (c *counter) value(values ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.series[strings.Join(values, "\xff")]; ok {
+		return s.n
+	}
+	return 0
+}
+
+func (c *counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.series) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelSet(c.labels, s.values, "", ""), formatFloat(s.n))
+	}
+}
+
+// histogram is a labelled Prometheus histogram.
+type histogram struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *histogram {
+	return &histogram{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+func (h *histogram) observe(v float64, values ...string) {
+	key := strings.Join(values, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{values: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.series) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cum uint64
+		for i, le := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelSet(h.labels, s.values, "le", formatFloat(le)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelSet(h.labels, s.values, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelSet(h.labels, s.values, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelSet(h.labels, s.values, "", ""), s.count)
+	}
+}
+
+// labelSet renders {a="x",b="y"}, with an optional extra label appended.
+func labelSet(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, n, labelEscaper.Replace(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extraName, extraValue)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelEscaper applies the text format's label value escaping.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package observability
+
+import (
+	"context"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/plugin"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/relation"
+	"github.com/xraph/warden/role"
+)
+
+// Lifecycle hooks feed warden_mutations_total.
+var (
+	_ plugin.RoleCreated        = (*Plugin)(nil)
+	_ plugin.RoleUpdated        = (*Plugin)(nil)
+	_ plugin.RoleDeleted        = (*Plugin)(nil)
+	_ plugin.PermissionCreated  = (*Plugin)(nil)
+	_ plugin.PermissionDeleted  = (*Plugin)(nil)
+	_ plugin.PermissionAttached = (*Plugin)(nil)
+	_ plugin.PermissionDetached = (*Plugin)(nil)
+	_ plugin.RoleAssigned       = (*Plugin)(nil)
+	_ plugin.RoleUnassigned     = (*Plugin)(nil)
+	_ plugin.RelationWritten    = (*Plugin)(nil)
+	_ plugin.RelationDeleted    = (*Plugin)(nil)
+	_ plugin.PolicyCreated      = (*Plugin)(nil)
+	_ plugin.PolicyUpdated      = (*Plugin)(nil)
+	_ plugin.PolicyDeleted      = (*Plugin)(nil)
+)
+
+func (p *Plugin) mutated(entity, op string) error {
+	p.mutations.add(1, entity, op)
+	return nil
+}
+
+// OnRoleCreated implements plugin.RoleCreated.
+func (p *Plugin) OnRoleCreated(context.Context, *role.Role) error { return p.mutated("role", "create") }
+
+// OnRoleUpdated implements plugin.RoleUpdated.
+func (p *Plugin) OnRoleUpdated(context.Context, *role.Role) error { return p.mutated("role", "update") }
+
+// OnRoleDeleted implements plugin.RoleDeleted.
+func (p *Plugin) OnRoleDeleted(context.Context, id.RoleID) error { return p.mutated("role", "delete") }
+
+// OnPermissionCreated implements plugin.PermissionCreated.
+func (p *Plugin) OnPermissionCreated(context.Context, *permission.Permission) error {
+	return p.mutated("permission", "create")
+}
+
+// OnPermissionDeleted implements plugin.PermissionDeleted.
+func (p *Plugin) OnPermissionDeleted(context.Context, id.PermissionID) error {
+	return p.mutated("permission", "delete")
+}
+
+// OnPermissionAttached implements plugin.PermissionAttached.
+func (p *Plugin) OnPermissionAttached(context.Context, id.RoleID, id.PermissionID) error {
+	return p.mutated("role_permission", "attach")
+}
+
+// OnPermissionDetached implements plugin.PermissionDetached.
+func (p *Plugin) OnPermissionDetached(context.Context, id.RoleID, id.PermissionID) error {
+	return p.mutated("role_permission", "detach")
+}
+
+// OnRoleAssigned implements plugin.RoleAssigned.
+func (p *Plugin) OnRoleAssigned(context.Context, *assignment.Assignment) error {
+	return p.mutated("assignment", "create")
+}
+
+// OnRoleUnassigned implements plugin.RoleUnassigned.
+func (p *Plugin) OnRoleUnassigned(context.Context, *assignment.Assignment) error {
+	return p.mutated("assignment", "delete")
+}
+
+// OnRelationWritten implements plugin.RelationWritten.
+func (p *Plugin) OnRelationWritten(context.Context, *relation.Tuple) error {
+	return p.mutated("relation", "create")
+}
+
+// OnRelationDeleted implements plugin.RelationDeleted.
+func (p *Plugin) OnRelationDeleted(context.Context, id.RelationID) error {
+	return p.mutated("relation", "delete")
+}
+
+// OnPolicyCreated implements plugin.PolicyCreated.
+func (p *Plugin) OnPolicyCreated(context.Context, *policy.Policy) error {
+	return p.mutated("policy", "create")
+}
+
+// OnPolicyUpdated implements plugin.PolicyUpdated.
+func (p *Plugin) OnPolicyUpdated(context.Context, *policy.Policy) error {
+	return p.mutated("policy", "update")
+}
+
+// OnPolicyDeleted implements plugin.PolicyDeleted.
+func (p *Plugin) OnPolicyDeleted(context.Context, id.PolicyID) error {
+	return p.mutated("policy", "delete")
+}
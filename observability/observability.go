@@ -0,0 +1,183 @@
+// Package observability is a first-party Warden plugin that traces and
+// measures authorization checks without any external service.
+//
+// It implements warden.Tracer, so the engine gives it a span per Check
+// with child spans for RBAC, ReBAC, ABAC and every store call, and it
+// derives Prometheus metrics from those spans: evaluation time by decision
+// and source, cache hits, ReBAC graph depth and ABAC policy counts. Metrics
+// are served in the Prometheus text format by Handler; finished spans go to
+// any number of SpanExporters, such as the StdoutExporter.
+//
+//	obs := observability.New(observability.WithExporter(observability.NewStdoutExporter(nil)))
+//	eng, _ := warden.NewEngine(warden.WithStore(s), warden.WithPlugin(obs))
+//	http.Handle("/metrics", obs.Handler())
+package observability
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xraph/warden"
+)
+
+// Plugin traces checks and records metrics. Create it with New.
+type Plugin struct {
+	exporters []SpanExporter
+	now       func() time.Time
+
+	checks        *counter
+	checkDuration *histogram
+	cacheLookups  *counter
+	stageDuration *histogram
+	storeDuration *histogram
+	graphDepth    *histogram
+	policyCount   *histogram
+	mutations     *counter
+}
+
+// Option configures the Plugin.
+type Option func(*Plugin)
+
+// WithExporter adds a destination for finished spans.
+func WithExporter(x SpanExporter) Option {
+	return func(p *Plugin) { p.exporters = append(p.exporters, x) }
+}
+
+// New returns an observability plugin.
+func New(opts ...Option) *Plugin {
+	p := &Plugin{
+		now: time.Now,
+		checks: newCounter("warden_checks_total",
+			"Authorization checks by decision.", "decision"),
+		checkDuration: newHistogram("warden_check_duration_seconds",
+			"Check evaluation time by decision and deciding source.", durationBuckets, "decision", "source"),
+		cacheLookups: newCounter("warden_check_cache_total",
+			"Check result cache lookups by result (hit or miss).", "result"),
+		stageDuration: newHistogram("warden_stage_duration_seconds",
+			"Evaluation time of each check stage (rbac, rebac, abac).", durationBuckets, "stage"),
+		storeDuration: newHistogram("warden_store_call_duration_seconds",
+			"Store call latency on the check path by operation.", durationBuckets, "op"),
+		graphDepth: newHistogram("warden_graph_depth",
+			"Deepest ReBAC graph level visited per check.", []float64{0, 1, 2, 3, 4, 5, 6, 8, 10, 15, 20}),
+		policyCount: newHistogram("warden_abac_policies_evaluated",
+			"ABAC policies evaluated per check.", []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500}),
+		mutations: newCounter("warden_mutations_total",
+			"Entity mutations by entity and operation.", "entity", "op"),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Name implements plugin.Plugin.
+func (p *Plugin) Name() string { return "observability" }
+
+type spanKey struct{}
+
+// Start implements warden.Tracer.
+func (p *Plugin) Start(ctx context.Context, spanName string) (context.Context, warden.Span) {
+	s := &span{p: p, data: SpanData{
+		SpanID:     newID(1),
+		Name:       spanName,
+		Start:      p.now(),
+		Attributes: make(map[string]any),
+	}}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.data.TraceID = parent.data.TraceID
+		s.data.ParentSpanID = parent.data.SpanID
+	} else {
+		s.data.TraceID = newID(2)
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// record derives metrics from a finished span.
+func (p *Plugin) record(d *SpanData) {
+	secs := d.End.Sub(d.Start).Seconds()
+	switch {
+	case d.Name == warden.SpanCheck:
+		decision := attrString(d.Attributes, warden.AttrDecision)
+		if _, failed := d.Attributes[warden.AttrError]; failed {
+			decision = "error"
+		}
+		p.checks.add(1, decision)
+		p.checkDuration.observe(secs, decision, attrString(d.Attributes, warden.AttrSource))
+		if hit, ok := d.Attributes[warden.AttrCacheHit].(bool); ok {
+			result := "miss"
+			if hit {
+				result = "hit"
+			}
+			p.cacheLookups.add(1, result)
+		}
+	case d.Name == warden.SpanRBAC || d.Name == warden.SpanReBAC || d.Name == warden.SpanABAC:
+		p.stageDuration.observe(secs, strings.TrimPrefix(d.Name, "warden."))
+		if depth, ok := d.Attributes[warden.AttrGraphDepth].(int); ok {
+			p.graphDepth.observe(float64(depth))
+		}
+		if n, ok := d.Attributes[warden.AttrPolicyCount].(int); ok {
+			p.policyCount.observe(float64(n))
+		}
+	case strings.HasPrefix(d.Name, warden.SpanStorePrefix):
+		p.storeDuration.observe(secs, strings.TrimPrefix(d.Name, warden.SpanStorePrefix))
+	}
+}
+
+// span is the Plugin's warden.Span.
+type span struct {
+	p *Plugin
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *span) SetAttributes(attrs ...warden.Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	for _, a := range attrs {
+		s.data.Attributes[a.Key] = a.Value
+	}
+}
+
+func (s *span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.p.now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.p.record(&data)
+	for _, x := range s.p.exporters {
+		x.ExportSpan(data)
+	}
+}
+
+func attrString(attrs map[string]any, key string) string {
+	v, ok := attrs[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// newID returns n random 64-bit words as hex: 1 for a span ID, 2 for a
+// trace ID, matching the W3C trace-context sizes.
+func newID(n int) string {
+	var b strings.Builder
+	for range n {
+		fmt.Fprintf(&b, "%016x", rand.Uint64()) //nolint:gosec // identifiers, not secrets
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/cache"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/store/memory"
+)
+
+// capture collects exported spans.
+type capture struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (c *capture) ExportSpan(s SpanData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = append(c.spans, s)
+}
+
+func (c *capture) byName(name string) []SpanData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []SpanData
+	for _, s := range c.spans {
+		if s.Name == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func newEngine(t *testing.T, obs *Plugin, opts ...warden.Option) (context.Context, *warden.Engine) {
+	t.Helper()
+	ctx := warden.WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	eng, err := warden.NewEngine(append([]warden.Option{warden.WithStore(s), warden.WithPlugin(obs)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roleID := id.NewRoleID()
+	_ = s.CreateRole(ctx, &role.Role{ID: roleID, TenantID: "t1", Name: "reader", Slug: "reader"})
+	_ = s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "document:read", Resource: "document", Action: "read"})
+	_ = s.AttachPermission(ctx, roleID, permission.Ref{Name: "document:read"})
+	_ = s.CreateAssignment(ctx, &assignment.Assignment{TenantID: "t1", RoleID: roleID, SubjectKind: "user", SubjectID: "u1"})
+	return ctx, eng
+}
+
+func readCheck(subjectID string) *warden.CheckRequest {
+	return &warden.CheckRequest{
+		Subject:  warden.Subject{Kind: warden.SubjectUser, ID: subjectID},
+		Action:   warden.Action{Name: "read"},
+		Resource: warden.Resource{Type: "document", ID: "doc1"},
+	}
+}
+
+func TestPlugin_SpansFormOneTrace(t *testing.T) {
+	spans := &capture{}
+	obs := New(WithExporter(spans))
+	ctx, eng := newEngine(t, obs)
+
+	result, err := eng.Check(ctx, readCheck("u1"))
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected allow, got %+v, %v", result, err)
+	}
+
+	checks := spans.byName(warden.SpanCheck)
+	if len(checks) != 1 {
+		t.Fatalf("expected one check span, got %d", len(checks))
+	}
+	root := checks[0]
+	if root.ParentSpanID != "" || len(root.TraceID) != 32 {
+		t.Fatalf("unexpected root span %+v", root)
+	}
+	if root.Attributes[warden.AttrDecision] != string(warden.DecisionAllow) || root.Attributes[warden.AttrSource] != "rbac" {
+		t.Fatalf("unexpected root attributes %v", root.Attributes)
+	}
+
+	rbac := spans.byName(warden.SpanRBAC)
+	if len(rbac) != 1 || rbac[0].ParentSpanID != root.SpanID || rbac[0].TraceID != root.TraceID {
+		t.Fatalf("rbac span not a child of the check: %+v", rbac)
+	}
+	store := spans.byName(warden.SpanStorePrefix + "ListRolesForSubject")
+	if len(store) == 0 || store[0].ParentSpanID != rbac[0].SpanID || store[0].TraceID != root.TraceID {
+		t.Fatalf("store span not a child of the rbac stage: %+v", store)
+	}
+}
+
+func TestPlugin_Metrics(t *testing.T) {
+	obs := New()
+	ctx, eng := newEngine(t, obs, warden.WithCache(cache.NewMemory()))
+
+	for _, subject := range []string{"u1", "u1", "u2"} {
+		if _, err := eng.Check(ctx, readCheck(subject)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eng.Plugins().EmitRoleCreated(ctx, &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: "editor", Slug: "editor"})
+
+	var buf bytes.Buffer
+	if err := obs.WriteMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`warden_checks_total{decision="allow"} 2`,
+		`warden_checks_total{decision="deny_no_roles"} 1`,
+		`warden_check_duration_seconds_bucket{decision="allow",source="rbac",le="+Inf"} 2`,
+		`warden_check_cache_total{result="hit"} 1`,
+		`warden_check_cache_hit_ratio 0.3333333333333333`,
+		`warden_stage_duration_seconds_count{stage="rbac"}`,
+		`warden_abac_policies_evaluated_count`,
+		`warden_store_call_duration_seconds_count{op="ListRolesForSubject"}`,
+		`warden_mutations_total{entity="role",op="create"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q\n%s", want, out)
+		}
+	}
+}
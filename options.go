@@ -35,12 +35,13 @@ func WithLogger(l log.Logger) Option { return func(e *Engine) { e.logger = l } }
 // WithConfig sets the engine configuration.
 func WithConfig(c Config) Option { return func(e *Engine) { e.config = c } }
 
-// WithPlugin registers a plugin with the engine.
-func WithPlugin(x plugin.Plugin) Option {
+// WithPlugin registers a plugin with the engine. opts set the precedence
+// and failure mode of its BeforeCheckHook and AfterCheckHook, if any.
+func WithPlugin(x plugin.Plugin, opts ...plugin.RegisterOption) Option {
 	return func(e *Engine) {
 		if e.plugins == nil {
 			e.plugins = plugin.NewRegistry(e.logger)
 		}
-		e.plugins.Register(x)
+		e.plugins.Register(x, opts...)
 	}
 }
@@ -2,9 +2,11 @@ package warden
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/xraph/warden/checklog"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/plugin"
 	"github.com/xraph/warden/policy"
@@ -33,6 +35,8 @@ func TestPolicy_EffectiveAt(t *testing.T) {
 		{"only upper, in", policy.Policy{IsActive: true, NotAfter: &t3}, t2, true},
 		{"only upper, out", policy.Policy{IsActive: true, NotAfter: &t1}, t2, false},
 		{"unbounded", policy.Policy{IsActive: true}, t2, true},
+		{"disabled mode", policy.Policy{IsActive: true, Mode: policy.ModeDisabled}, t2, false},
+		{"shadow mode", policy.Policy{IsActive: true, Mode: policy.ModeShadow}, t2, true},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -238,3 +242,165 @@ func TestEngine_PolicyObligationFired(t *testing.T) {
 		t.Fatalf("expected 2 obligation events, got %d: %v", len(capPlugin.captured), capPlugin.captured)
 	}
 }
+
+// shadowCapturePlugin records every shadow decision the engine reports.
+type shadowCapturePlugin struct {
+	captured []*ShadowDecision
+}
+
+func (p *shadowCapturePlugin) Name() string { return "shadow-capture" }
+func (p *shadowCapturePlugin) OnPolicyShadowDecision(_ context.Context, _ id.PolicyID, shadow, _ any, _ any) error {
+	p.captured = append(p.captured, shadow.(*ShadowDecision))
+	return nil
+}
+
+var _ plugin.PolicyShadowDecision = (*shadowCapturePlugin)(nil)
+
+// TestEngine_ShadowPolicy verifies shadow policies never change the
+// decision but report the would-be difference on the result, through the
+// plugin hook and in the check log.
+func TestEngine_ShadowPolicy(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+
+	for _, p := range []*policy.Policy{
+		{TenantID: "t1", Name: "allow-read", Effect: policy.EffectAllow, IsActive: true, Actions: []string{"read"}},
+		{TenantID: "t1", Name: "deny-contractors", Effect: policy.EffectDeny, IsActive: true, Mode: policy.ModeShadow,
+			Actions:     []string{"read", "write"},
+			Obligations: []string{"notify-security"},
+			Conditions:  []policy.Condition{{Field: "subject.type", Operator: policy.OpEquals, Value: "contractor"}}},
+		{TenantID: "t1", Name: "allow-write", Effect: policy.EffectAllow, IsActive: true, Mode: policy.ModeShadow, Actions: []string{"write"}},
+		{TenantID: "t1", Name: "deny-all-disabled", Effect: policy.EffectDeny, IsActive: true, Mode: policy.ModeDisabled},
+	} {
+		if err := s.CreatePolicy(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	capPlugin := &shadowCapturePlugin{}
+	eng, err := NewEngine(WithStore(s), WithPlugin(capPlugin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractor := Subject{Kind: SubjectUser, ID: "u1", Attributes: map[string]any{"type": "contractor"}}
+
+	// The shadow deny would have flipped an allow.
+	res, err := eng.Check(ctx, &CheckRequest{Subject: contractor, Action: Action{Name: "read"}, Resource: Resource{Type: "document", ID: "d1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed || len(res.Obligations) != 0 {
+		t.Fatalf("shadow policy changed the result: %+v", res)
+	}
+	if len(res.Shadow) != 1 || res.Shadow[0].PolicyName != "deny-contractors" || res.Shadow[0].Decision != DecisionDenyExplicit {
+		t.Fatalf("Shadow = %+v", res.Shadow)
+	}
+	if len(capPlugin.captured) != 1 || capPlugin.captured[0].PolicyName != "deny-contractors" {
+		t.Errorf("shadow events = %+v", capPlugin.captured)
+	}
+
+	// Check logs are written asynchronously.
+	var entries []*checklog.Entry
+	for range 100 {
+		entries, _ = s.ListCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"}) //nolint:errcheck // polled
+		if len(entries) == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("check logs = %d, want 1", len(entries))
+	}
+	if logged, ok := entries[0].Metadata[checklog.MetadataShadow].([]ShadowDecision); !ok || len(logged) != 1 {
+		t.Errorf("check log metadata = %+v", entries[0].Metadata)
+	}
+
+	// Both shadow policies match a contractor's write, but only the allow
+	// would have changed the default deny.
+	res, err = eng.Check(ctx, &CheckRequest{Subject: contractor, Action: Action{Name: "write"}, Resource: Resource{Type: "document", ID: "d1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed {
+		t.Fatalf("shadow allow changed the result: %+v", res)
+	}
+	if len(res.Shadow) != 1 || res.Shadow[0].PolicyName != "allow-write" || !res.Shadow[0].Allowed {
+		t.Errorf("Shadow = %+v", res.Shadow)
+	}
+
+	// Shadow decisions that agree with the result are not reported.
+	res, err = eng.Check(ctx, &CheckRequest{Subject: Subject{Kind: SubjectUser, ID: "u2"}, Action: Action{Name: "read"}, Resource: Resource{Type: "document", ID: "d1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed || len(res.Shadow) != 0 {
+		t.Errorf("result = %+v", res)
+	}
+
+	// A result with shadow diffs is never cached, so every repeat of the
+	// check reports them.
+	c := &recordingCache{}
+	cached, err := NewEngine(WithStore(s), WithCache(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cached.Check(ctx, &CheckRequest{Subject: contractor, Action: Action{Name: "read"}, Resource: Resource{Type: "document", ID: "d1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if c.sets != 0 {
+		t.Errorf("result with shadow diffs was cached")
+	}
+	if _, err := cached.Check(ctx, &CheckRequest{Subject: Subject{Kind: SubjectUser, ID: "u2"}, Action: Action{Name: "read"}, Resource: Resource{Type: "document", ID: "d1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if c.sets != 1 {
+		t.Errorf("result without shadow diffs should be cached, sets = %d", c.sets)
+	}
+}
+
+// TestEvaluator_CollectionOperators covers the collection operators over
+// the shapes a multi-valued attribute arrives in.
+func TestEvaluator_CollectionOperators(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	ev := &conditionEvaluator{now: func() time.Time { return now }}
+
+	tests := []struct {
+		name   string
+		groups any
+		op     policy.Operator
+		value  any
+		want   bool
+	}{
+		{"any_in []string", []string{"eng", "ops"}, policy.OpAnyIn, []any{"ops", "sales"}, true},
+		{"any_in disjoint", []string{"eng"}, policy.OpAnyIn, []any{"ops", "sales"}, false},
+		{"any_in scalar", "ops", policy.OpAnyIn, []any{"ops"}, true},
+		{"all_in subset", []any{"eng", "ops"}, policy.OpAllIn, []any{"eng", "ops", "sales"}, true},
+		{"all_in not subset", []any{"eng", "hr"}, policy.OpAllIn, []any{"eng", "ops"}, false},
+		{"all_in empty", []string{}, policy.OpAllIn, []any{"eng"}, true},
+		{"contains_element JSON array", json.RawMessage(`["eng","ops"]`), policy.OpContainsElement, "ops", true},
+		{"contains_element ints", []int{1, 2}, policy.OpContainsElement, 2, true},
+		{"contains_element missing", []string{"eng"}, policy.OpContainsElement, "ops", false},
+		{"size_gt", []string{"a", "b", "c"}, policy.OpSizeGT, 2, true},
+		{"size_gt equal", []string{"a", "b"}, policy.OpSizeGT, 2, false},
+		{"size_lt map", map[string]any{"a": 1}, policy.OpSizeLT, 2, true},
+		{"size_lt JSON float", []any{"a", "b"}, policy.OpSizeLT, float64(2), false},
+		{"absent attribute", nil, policy.OpSizeLT, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CheckRequest{Subject: Subject{Kind: SubjectUser, ID: "u1"}}
+			if tt.groups != nil {
+				req.Subject.Attributes = map[string]any{"groups": tt.groups}
+			}
+			cond := policy.Condition{Field: "subject.groups", Operator: tt.op, Value: tt.value}
+			got, err := ev.evaluateConditions(ctx, []policy.Condition{cond}, req, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
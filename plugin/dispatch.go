@@ -0,0 +1,250 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultQueueSize is the async queue capacity used when WithAsync is given
+// a non-positive size.
+const DefaultQueueSize = 1024
+
+var (
+	// ErrHookTimeout is reported when a hook outlives its plugin's timeout.
+	ErrHookTimeout = errors.New("plugin: hook timed out")
+
+	// ErrHookPanic wraps the value recovered from a panicking hook.
+	ErrHookPanic = errors.New("plugin: hook panicked")
+)
+
+// Invoke runs fn, one call of the named plugin's hook, on the calling
+// goroutine's behalf: with the plugin's timeout, with panics recovered into
+// ErrHookPanic, and with latency and errors recorded in Stats. The engine
+// uses it for hooks whose result it needs, which are therefore always
+// synchronous regardless of the plugin's DispatchMode.
+//
+// When the timeout fires Invoke returns ErrHookTimeout without waiting for
+// fn; fn's context is cancelled and fn must not touch caller state after
+// that.
+func (r *Registry) Invoke(ctx context.Context, pluginName string, fn func(context.Context) error) error {
+	start := time.Now()
+	err := invoke(ctx, r.settings[pluginName].Timeout, fn)
+	if st := r.stats[pluginName]; st != nil {
+		st.record(time.Since(start), err)
+	}
+	return err
+}
+
+// emit delivers one lifecycle notification according to the plugin's
+// DispatchMode. Errors are logged, never returned.
+func (r *Registry) emit(ctx context.Context, hook, pluginName string, fn func(context.Context) error) {
+	w := r.workers[pluginName]
+	if w == nil {
+		r.run(ctx, hook, pluginName, fn)
+		return
+	}
+	// The request may finish before the worker gets to the event.
+	ctx = context.WithoutCancel(ctx)
+	if !w.enqueue(func() { r.run(ctx, hook, pluginName, fn) }) {
+		r.stats[pluginName].dropped.Add(1)
+		r.logHookError(hook, pluginName, errors.New("async queue full, event dropped"))
+	}
+}
+
+func (r *Registry) run(ctx context.Context, hook, pluginName string, fn func(context.Context) error) {
+	if err := r.Invoke(ctx, pluginName, fn); err != nil {
+		r.logHookError(hook, pluginName, err)
+	}
+}
+
+// Close stops the async workers after they deliver the events already
+// queued, or when ctx is done. Events emitted to async plugins after Close
+// are dropped.
+func (r *Registry) Close(ctx context.Context) error {
+	var errs []error
+	for _, p := range r.plugins {
+		if w := r.workers[p.Name()]; w != nil {
+			if err := w.close(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: %w", p.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func invoke(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	if timeout <= 0 {
+		return safeCall(ctx, fn)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- safeCall(ctx, fn) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrHookTimeout
+		}
+		return ctx.Err()
+	}
+}
+
+func safeCall(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%w: %v", ErrHookPanic, rec)
+		}
+	}()
+	return fn(ctx)
+}
+
+// worker delivers an async plugin's events in order from a bounded queue.
+type worker struct {
+	mu     sync.RWMutex
+	closed bool
+	queue  chan func()
+	done   chan struct{}
+}
+
+func newWorker(size int) *worker {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	w := &worker{queue: make(chan func(), size), done: make(chan struct{})}
+	go w.loop()
+	return w
+}
+
+func (w *worker) loop() {
+	defer close(w.done)
+	for task := range w.queue {
+		task()
+	}
+}
+
+// enqueue adds task without blocking; it reports false when the queue is
+// full or closed.
+func (w *worker) enqueue(task func()) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.queue <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *worker) close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats are the hook metrics of one plugin since registration.
+type Stats struct {
+	Plugin   string `json:"plugin"`
+	Dispatch string `json:"dispatch"`
+
+	// Calls counts hook invocations, including failed ones.
+	Calls int64 `json:"calls"`
+
+	// Errors counts invocations that returned an error, timed out or
+	// panicked.
+	Errors   int64 `json:"errors"`
+	Timeouts int64 `json:"timeouts"`
+	Panics   int64 `json:"panics"`
+
+	// Dropped counts async events discarded because the queue was full.
+	Dropped int64 `json:"dropped"`
+
+	// Queued is the number of async events waiting to be delivered.
+	Queued int `json:"queued"`
+
+	TotalLatency time.Duration `json:"total_latency_ns"`
+	MaxLatency   time.Duration `json:"max_latency_ns"`
+}
+
+// AvgLatency is the mean hook latency.
+func (s Stats) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls)
+}
+
+// Stats returns hook metrics for every registered plugin, in registration
+// order.
+func (r *Registry) Stats() []Stats {
+	out := make([]Stats, 0, len(r.plugins))
+	for _, p := range r.plugins {
+		name := p.Name()
+		st := r.stats[name]
+		s := Stats{
+			Plugin:       name,
+			Dispatch:     r.settings[name].Dispatch.String(),
+			Calls:        st.calls.Load(),
+			Errors:       st.errors.Load(),
+			Timeouts:     st.timeouts.Load(),
+			Panics:       st.panics.Load(),
+			Dropped:      st.dropped.Load(),
+			TotalLatency: time.Duration(st.totalNs.Load()),
+			MaxLatency:   time.Duration(st.maxNs.Load()),
+		}
+		if w := r.workers[name]; w != nil {
+			s.Queued = len(w.queue)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// pluginStats are the live counters behind Stats.
+type pluginStats struct {
+	calls    atomic.Int64
+	errors   atomic.Int64
+	timeouts atomic.Int64
+	panics   atomic.Int64
+	dropped  atomic.Int64
+	totalNs  atomic.Int64
+	maxNs    atomic.Int64
+}
+
+func (s *pluginStats) record(d time.Duration, err error) {
+	s.calls.Add(1)
+	s.totalNs.Add(int64(d))
+	for {
+		prev := s.maxNs.Load()
+		if int64(d) <= prev || s.maxNs.CompareAndSwap(prev, int64(d)) {
+			break
+		}
+	}
+	if err == nil {
+		return
+	}
+	s.errors.Add(1)
+	switch {
+	case errors.Is(err, ErrHookTimeout):
+		s.timeouts.Add(1)
+	case errors.Is(err, ErrHookPanic):
+		s.panics.Add(1)
+	}
+}
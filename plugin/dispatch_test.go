@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/xraph/go-utils/log"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/role"
+)
+
+// hookPlugin runs fn for every RoleCreated event.
+type hookPlugin struct {
+	name string
+	fn   func(ctx context.Context, r *role.Role) error
+}
+
+func (h *hookPlugin) Name() string { return h.name }
+
+func (h *hookPlugin) OnRoleCreated(ctx context.Context, r *role.Role) error { return h.fn(ctx, r) }
+
+func statsFor(t *testing.T, reg *Registry, name string) Stats {
+	t.Helper()
+	for _, s := range reg.Stats() {
+		if s.Plugin == name {
+			return s
+		}
+	}
+	t.Fatalf("no stats for plugin %q", name)
+	return Stats{}
+}
+
+func TestDispatch_AsyncDeliversInOrder(t *testing.T) {
+	reg := NewRegistry(log.NewNoopLogger())
+	var mu sync.Mutex
+	var got []string
+	release := make(chan struct{})
+	reg.Register(&hookPlugin{name: "audit", fn: func(_ context.Context, r *role.Role) error {
+		<-release
+		mu.Lock()
+		got = append(got, r.Name)
+		mu.Unlock()
+		return nil
+	}}, WithAsync(16))
+
+	// The hook blocks until released, so emitting returns only because
+	// delivery is async.
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, name := range []string{"a", "b", "c"} {
+		reg.EmitRoleCreated(ctx, &role.Role{ID: id.NewRoleID(), Name: name})
+	}
+	cancel() // async delivery must not depend on the request context
+	close(release)
+
+	if err := reg.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Fatalf("delivered %v, want [a b c]", got)
+	}
+	if s := statsFor(t, reg, "audit"); s.Calls != 3 || s.Dispatch != "async" || s.Queued != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestDispatch_AsyncQueueFullDrops(t *testing.T) {
+	reg := NewRegistry(log.NewNoopLogger())
+	release := make(chan struct{})
+	reg.Register(&hookPlugin{name: "slow", fn: func(context.Context, *role.Role) error {
+		<-release
+		return nil
+	}}, WithAsync(1))
+
+	ctx := context.Background()
+	for range 5 {
+		reg.EmitRoleCreated(ctx, &role.Role{ID: id.NewRoleID()})
+	}
+	close(release)
+	if err := reg.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	s := statsFor(t, reg, "slow")
+	if s.Dropped == 0 || s.Calls+s.Dropped != 5 {
+		t.Fatalf("expected some of 5 events dropped, got %+v", s)
+	}
+}
+
+func TestDispatch_TimeoutAndPanic(t *testing.T) {
+	reg := NewRegistry(log.NewNoopLogger())
+	reg.Register(&hookPlugin{name: "hang", fn: func(ctx context.Context, _ *role.Role) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}, WithTimeout(5*time.Millisecond))
+	reg.Register(&hookPlugin{name: "boom", fn: func(context.Context, *role.Role) error {
+		panic("nil map")
+	}})
+
+	// Neither the hang nor the panic may escape the emit.
+	reg.EmitRoleCreated(context.Background(), &role.Role{ID: id.NewRoleID()})
+
+	if s := statsFor(t, reg, "hang"); s.Timeouts != 1 || s.Errors != 1 || s.MaxLatency < 5*time.Millisecond {
+		t.Fatalf("unexpected hang stats %+v", s)
+	}
+	if s := statsFor(t, reg, "boom"); s.Panics != 1 || s.Errors != 1 {
+		t.Fatalf("unexpected boom stats %+v", s)
+	}
+
+	err := reg.Invoke(context.Background(), "boom", func(context.Context) error { panic("again") })
+	if !errors.Is(err, ErrHookPanic) {
+		t.Fatalf("Invoke error = %v, want ErrHookPanic", err)
+	}
+}
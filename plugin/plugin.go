@@ -10,6 +10,7 @@ import (
 	"context"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/breakglass"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
@@ -39,6 +40,22 @@ type AfterCheck interface {
 	OnAfterCheck(ctx context.Context, req, result any) error
 }
 
+// AttributeProvider supplies ABAC attributes the caller did not put on the
+// request (a policy information point). The engine calls it lazily, only
+// when a policy condition references a subject.*, resource.* or context.*
+// attribute missing from the request, so providers can look up values such
+// as subject.department or resource.owner_id from local data.
+//
+// The req parameter is *warden.CheckRequest; field is the full condition
+// field, e.g. "subject.department". Return found=false to let the next
+// provider try. Results are cached for the rest of the check, and across
+// checks for Config.AttributeCacheTTL. An error is never cached: it makes
+// every policy that reads the field fail with a PolicyError, so the
+// policy's on_error setting decides the check.
+type AttributeProvider interface {
+	ProvideAttribute(ctx context.Context, req any, field string) (value any, found bool, err error)
+}
+
 // ──────────────────────────────────────────────────
 // Role lifecycle hooks
 // ──────────────────────────────────────────────────
@@ -143,6 +160,37 @@ type PolicyObligationFired interface {
 	OnPolicyObligationFired(ctx context.Context, polID id.PolicyID, obligation string, req, result any) error
 }
 
+// PolicyShadowDecision is called when a policy in shadow mode would have
+// changed the outcome of a Check had it been enforced. shadow is the
+// *warden.ShadowDecision describing the would-be decision; result is the
+// real, enforced *warden.CheckResult.
+type PolicyShadowDecision interface {
+	OnPolicyShadowDecision(ctx context.Context, polID id.PolicyID, shadow, req, result any) error
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass hooks
+// ──────────────────────────────────────────────────
+
+// BreakGlassActivated is called after a subject breaks glass and is
+// granted emergency access. Security tooling should alert on it.
+type BreakGlassActivated interface {
+	OnBreakGlassActivated(ctx context.Context, g *breakglass.Grant) error
+}
+
+// BreakGlassUsed is called for every Check that was allowed only because
+// of a break-glass grant. req is the *warden.CheckRequest; result is the
+// *warden.CheckResult carrying the "break_glass" obligation.
+type BreakGlassUsed interface {
+	OnBreakGlassUsed(ctx context.Context, g *breakglass.Grant, req, result any) error
+}
+
+// BreakGlassRevoked is called after a break-glass grant is revoked before
+// it expired.
+type BreakGlassRevoked interface {
+	OnBreakGlassRevoked(ctx context.Context, g *breakglass.Grant) error
+}
+
 // ──────────────────────────────────────────────────
 // Shutdown hook
 // ──────────────────────────────────────────────────
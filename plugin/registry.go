@@ -2,10 +2,12 @@ package plugin
 
 import (
 	"context"
+	"fmt"
 
 	log "github.com/xraph/go-utils/log"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/breakglass"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
@@ -23,6 +25,10 @@ type afterCheckEntry struct {
 	name string
 	hook AfterCheck
 }
+type attributeProviderEntry struct {
+	name string
+	hook AttributeProvider
+}
 type roleCreatedEntry struct {
 	name string
 	hook RoleCreated
@@ -83,6 +89,22 @@ type policyObligationFiredEntry struct {
 	name string
 	hook PolicyObligationFired
 }
+type policyShadowDecisionEntry struct {
+	name string
+	hook PolicyShadowDecision
+}
+type breakGlassActivatedEntry struct {
+	name string
+	hook BreakGlassActivated
+}
+type breakGlassUsedEntry struct {
+	name string
+	hook BreakGlassUsed
+}
+type breakGlassRevokedEntry struct {
+	name string
+	hook BreakGlassRevoked
+}
 type shutdownEntry struct {
 	name string
 	hook Shutdown
@@ -92,11 +114,15 @@ type shutdownEntry struct {
 // It type-caches plugins at registration time so emit calls iterate
 // only over plugins implementing the relevant hook.
 type Registry struct {
-	plugins []Plugin
-	logger  log.Logger
+	plugins  []Plugin
+	settings map[string]Settings
+	stats    map[string]*pluginStats
+	workers  map[string]*worker // async plugins only
+	logger   log.Logger
 
 	beforeCheck        []beforeCheckEntry
 	afterCheck         []afterCheckEntry
+	attributeProvider  []attributeProviderEntry
 	roleCreated        []roleCreatedEntry
 	roleUpdated        []roleUpdatedEntry
 	roleDeleted        []roleDeletedEntry
@@ -112,26 +138,50 @@ type Registry struct {
 	policyUpdated      []policyUpdatedEntry
 	policyDeleted      []policyDeletedEntry
 	policyObligation   []policyObligationFiredEntry
+	policyShadow       []policyShadowDecisionEntry
+	breakGlassOn       []breakGlassActivatedEntry
+	breakGlassUsed     []breakGlassUsedEntry
+	breakGlassOff      []breakGlassRevokedEntry
 	shutdown           []shutdownEntry
 }
 
 // NewRegistry creates a plugin registry with the given logger.
 func NewRegistry(logger log.Logger) *Registry {
-	return &Registry{logger: logger}
+	return &Registry{
+		logger:   logger,
+		settings: make(map[string]Settings),
+		stats:    make(map[string]*pluginStats),
+		workers:  make(map[string]*worker),
+	}
 }
 
 // Register adds a plugin and type-asserts it into all applicable
-// hook caches. Plugins are notified in registration order.
-func (r *Registry) Register(p Plugin) {
+// hook caches. Plugins are notified in registration order; opts set the
+// precedence and failure mode of veto-capable check hooks, and how the
+// plugin's hooks are dispatched.
+func (r *Registry) Register(p Plugin, opts ...RegisterOption) {
 	r.plugins = append(r.plugins, p)
 	name := p.Name()
 
+	var st Settings
+	for _, opt := range opts {
+		opt(&st)
+	}
+	r.settings[name] = st
+	r.stats[name] = &pluginStats{}
+	if st.Dispatch == DispatchAsync {
+		r.workers[name] = newWorker(st.QueueSize)
+	}
+
 	if h, ok := p.(BeforeCheck); ok {
 		r.beforeCheck = append(r.beforeCheck, beforeCheckEntry{name, h})
 	}
 	if h, ok := p.(AfterCheck); ok {
 		r.afterCheck = append(r.afterCheck, afterCheckEntry{name, h})
 	}
+	if h, ok := p.(AttributeProvider); ok {
+		r.attributeProvider = append(r.attributeProvider, attributeProviderEntry{name, h})
+	}
 	if h, ok := p.(RoleCreated); ok {
 		r.roleCreated = append(r.roleCreated, roleCreatedEntry{name, h})
 	}
@@ -177,6 +227,18 @@ func (r *Registry) Register(p Plugin) {
 	if h, ok := p.(PolicyObligationFired); ok {
 		r.policyObligation = append(r.policyObligation, policyObligationFiredEntry{name, h})
 	}
+	if h, ok := p.(PolicyShadowDecision); ok {
+		r.policyShadow = append(r.policyShadow, policyShadowDecisionEntry{name, h})
+	}
+	if h, ok := p.(BreakGlassActivated); ok {
+		r.breakGlassOn = append(r.breakGlassOn, breakGlassActivatedEntry{name, h})
+	}
+	if h, ok := p.(BreakGlassUsed); ok {
+		r.breakGlassUsed = append(r.breakGlassUsed, breakGlassUsedEntry{name, h})
+	}
+	if h, ok := p.(BreakGlassRevoked); ok {
+		r.breakGlassOff = append(r.breakGlassOff, breakGlassRevokedEntry{name, h})
+	}
 	if h, ok := p.(Shutdown); ok {
 		r.shutdown = append(r.shutdown, shutdownEntry{name, h})
 	}
@@ -185,6 +247,9 @@ func (r *Registry) Register(p Plugin) {
 // Plugins returns all registered plugins.
 func (r *Registry) Plugins() []Plugin { return r.plugins }
 
+// Settings returns the registration settings of the named plugin.
+func (r *Registry) Settings(name string) Settings { return r.settings[name] }
+
 // ──────────────────────────────────────────────────
 // Check event emitters
 // ──────────────────────────────────────────────────
@@ -192,19 +257,44 @@ func (r *Registry) Plugins() []Plugin { return r.plugins }
 // EmitBeforeCheck notifies all plugins that implement BeforeCheck.
 func (r *Registry) EmitBeforeCheck(ctx context.Context, req any) {
 	for _, e := range r.beforeCheck {
-		if err := e.hook.OnBeforeCheck(ctx, req); err != nil {
-			r.logHookError("OnBeforeCheck", e.name, err)
-		}
+		r.emit(ctx, "OnBeforeCheck", e.name, func(ctx context.Context) error { return e.hook.OnBeforeCheck(ctx, req) })
 	}
 }
 
 // EmitAfterCheck notifies all plugins that implement AfterCheck.
 func (r *Registry) EmitAfterCheck(ctx context.Context, req, result any) {
 	for _, e := range r.afterCheck {
-		if err := e.hook.OnAfterCheck(ctx, req, result); err != nil {
-			r.logHookError("OnAfterCheck", e.name, err)
+		r.emit(ctx, "OnAfterCheck", e.name, func(ctx context.Context) error { return e.hook.OnAfterCheck(ctx, req, result) })
+	}
+}
+
+// HasAttributeProviders reports whether any plugin implements
+// AttributeProvider.
+func (r *Registry) HasAttributeProviders() bool { return len(r.attributeProvider) > 0 }
+
+// ProvideAttribute asks each AttributeProvider in registration order for
+// field and returns the first value found, with the name of the plugin that
+// supplied it. A provider error or timeout stops the lookup and is
+// returned, since a later provider's answer could differ from the one the
+// failed provider would have given.
+func (r *Registry) ProvideAttribute(ctx context.Context, req any, field string) (value any, provider string, found bool, err error) {
+	for _, e := range r.attributeProvider {
+		var v any
+		var ok bool
+		err := r.Invoke(ctx, e.name, func(ctx context.Context) error {
+			var err error
+			v, ok, err = e.hook.ProvideAttribute(ctx, req, field)
+			return err
+		})
+		if err != nil {
+			r.logHookError("ProvideAttribute", e.name, err)
+			return nil, e.name, false, fmt.Errorf("attribute provider %q: %w", e.name, err)
+		}
+		if ok {
+			return v, e.name, true, nil
 		}
 	}
+	return nil, "", false, nil
 }
 
 // ──────────────────────────────────────────────────
@@ -214,27 +304,21 @@ func (r *Registry) EmitAfterCheck(ctx context.Context, req, result any) {
 // EmitRoleCreated notifies all plugins that implement RoleCreated.
 func (r *Registry) EmitRoleCreated(ctx context.Context, rl *role.Role) {
 	for _, e := range r.roleCreated {
-		if err := e.hook.OnRoleCreated(ctx, rl); err != nil {
-			r.logHookError("OnRoleCreated", e.name, err)
-		}
+		r.emit(ctx, "OnRoleCreated", e.name, func(ctx context.Context) error { return e.hook.OnRoleCreated(ctx, rl) })
 	}
 }
 
 // EmitRoleUpdated notifies all plugins that implement RoleUpdated.
 func (r *Registry) EmitRoleUpdated(ctx context.Context, rl *role.Role) {
 	for _, e := range r.roleUpdated {
-		if err := e.hook.OnRoleUpdated(ctx, rl); err != nil {
-			r.logHookError("OnRoleUpdated", e.name, err)
-		}
+		r.emit(ctx, "OnRoleUpdated", e.name, func(ctx context.Context) error { return e.hook.OnRoleUpdated(ctx, rl) })
 	}
 }
 
 // EmitRoleDeleted notifies all plugins that implement RoleDeleted.
 func (r *Registry) EmitRoleDeleted(ctx context.Context, roleID id.RoleID) {
 	for _, e := range r.roleDeleted {
-		if err := e.hook.OnRoleDeleted(ctx, roleID); err != nil {
-			r.logHookError("OnRoleDeleted", e.name, err)
-		}
+		r.emit(ctx, "OnRoleDeleted", e.name, func(ctx context.Context) error { return e.hook.OnRoleDeleted(ctx, roleID) })
 	}
 }
 
@@ -245,36 +329,28 @@ func (r *Registry) EmitRoleDeleted(ctx context.Context, roleID id.RoleID) {
 // EmitPermissionCreated notifies all plugins that implement PermissionCreated.
 func (r *Registry) EmitPermissionCreated(ctx context.Context, p *permission.Permission) {
 	for _, e := range r.permissionCreated {
-		if err := e.hook.OnPermissionCreated(ctx, p); err != nil {
-			r.logHookError("OnPermissionCreated", e.name, err)
-		}
+		r.emit(ctx, "OnPermissionCreated", e.name, func(ctx context.Context) error { return e.hook.OnPermissionCreated(ctx, p) })
 	}
 }
 
 // EmitPermissionDeleted notifies all plugins that implement PermissionDeleted.
 func (r *Registry) EmitPermissionDeleted(ctx context.Context, permID id.PermissionID) {
 	for _, e := range r.permissionDeleted {
-		if err := e.hook.OnPermissionDeleted(ctx, permID); err != nil {
-			r.logHookError("OnPermissionDeleted", e.name, err)
-		}
+		r.emit(ctx, "OnPermissionDeleted", e.name, func(ctx context.Context) error { return e.hook.OnPermissionDeleted(ctx, permID) })
 	}
 }
 
 // EmitPermissionAttached notifies all plugins that implement PermissionAttached.
 func (r *Registry) EmitPermissionAttached(ctx context.Context, roleID id.RoleID, permID id.PermissionID) {
 	for _, e := range r.permissionAttached {
-		if err := e.hook.OnPermissionAttached(ctx, roleID, permID); err != nil {
-			r.logHookError("OnPermissionAttached", e.name, err)
-		}
+		r.emit(ctx, "OnPermissionAttached", e.name, func(ctx context.Context) error { return e.hook.OnPermissionAttached(ctx, roleID, permID) })
 	}
 }
 
 // EmitPermissionDetached notifies all plugins that implement PermissionDetached.
 func (r *Registry) EmitPermissionDetached(ctx context.Context, roleID id.RoleID, permID id.PermissionID) {
 	for _, e := range r.permissionDetached {
-		if err := e.hook.OnPermissionDetached(ctx, roleID, permID); err != nil {
-			r.logHookError("OnPermissionDetached", e.name, err)
-		}
+		r.emit(ctx, "OnPermissionDetached", e.name, func(ctx context.Context) error { return e.hook.OnPermissionDetached(ctx, roleID, permID) })
 	}
 }
 
@@ -285,18 +361,14 @@ func (r *Registry) EmitPermissionDetached(ctx context.Context, roleID id.RoleID,
 // EmitRoleAssigned notifies all plugins that implement RoleAssigned.
 func (r *Registry) EmitRoleAssigned(ctx context.Context, a *assignment.Assignment) {
 	for _, e := range r.roleAssigned {
-		if err := e.hook.OnRoleAssigned(ctx, a); err != nil {
-			r.logHookError("OnRoleAssigned", e.name, err)
-		}
+		r.emit(ctx, "OnRoleAssigned", e.name, func(ctx context.Context) error { return e.hook.OnRoleAssigned(ctx, a) })
 	}
 }
 
 // EmitRoleUnassigned notifies all plugins that implement RoleUnassigned.
 func (r *Registry) EmitRoleUnassigned(ctx context.Context, a *assignment.Assignment) {
 	for _, e := range r.roleUnassigned {
-		if err := e.hook.OnRoleUnassigned(ctx, a); err != nil {
-			r.logHookError("OnRoleUnassigned", e.name, err)
-		}
+		r.emit(ctx, "OnRoleUnassigned", e.name, func(ctx context.Context) error { return e.hook.OnRoleUnassigned(ctx, a) })
 	}
 }
 
@@ -307,18 +379,14 @@ func (r *Registry) EmitRoleUnassigned(ctx context.Context, a *assignment.Assignm
 // EmitRelationWritten notifies all plugins that implement RelationWritten.
 func (r *Registry) EmitRelationWritten(ctx context.Context, t *relation.Tuple) {
 	for _, e := range r.relationWritten {
-		if err := e.hook.OnRelationWritten(ctx, t); err != nil {
-			r.logHookError("OnRelationWritten", e.name, err)
-		}
+		r.emit(ctx, "OnRelationWritten", e.name, func(ctx context.Context) error { return e.hook.OnRelationWritten(ctx, t) })
 	}
 }
 
 // EmitRelationDeleted notifies all plugins that implement RelationDeleted.
 func (r *Registry) EmitRelationDeleted(ctx context.Context, relID id.RelationID) {
 	for _, e := range r.relationDeleted {
-		if err := e.hook.OnRelationDeleted(ctx, relID); err != nil {
-			r.logHookError("OnRelationDeleted", e.name, err)
-		}
+		r.emit(ctx, "OnRelationDeleted", e.name, func(ctx context.Context) error { return e.hook.OnRelationDeleted(ctx, relID) })
 	}
 }
 
@@ -329,27 +397,21 @@ func (r *Registry) EmitRelationDeleted(ctx context.Context, relID id.RelationID)
 // EmitPolicyCreated notifies all plugins that implement PolicyCreated.
 func (r *Registry) EmitPolicyCreated(ctx context.Context, p *policy.Policy) {
 	for _, e := range r.policyCreated {
-		if err := e.hook.OnPolicyCreated(ctx, p); err != nil {
-			r.logHookError("OnPolicyCreated", e.name, err)
-		}
+		r.emit(ctx, "OnPolicyCreated", e.name, func(ctx context.Context) error { return e.hook.OnPolicyCreated(ctx, p) })
 	}
 }
 
 // EmitPolicyUpdated notifies all plugins that implement PolicyUpdated.
 func (r *Registry) EmitPolicyUpdated(ctx context.Context, p *policy.Policy) {
 	for _, e := range r.policyUpdated {
-		if err := e.hook.OnPolicyUpdated(ctx, p); err != nil {
-			r.logHookError("OnPolicyUpdated", e.name, err)
-		}
+		r.emit(ctx, "OnPolicyUpdated", e.name, func(ctx context.Context) error { return e.hook.OnPolicyUpdated(ctx, p) })
 	}
 }
 
 // EmitPolicyDeleted notifies all plugins that implement PolicyDeleted.
 func (r *Registry) EmitPolicyDeleted(ctx context.Context, polID id.PolicyID) {
 	for _, e := range r.policyDeleted {
-		if err := e.hook.OnPolicyDeleted(ctx, polID); err != nil {
-			r.logHookError("OnPolicyDeleted", e.name, err)
-		}
+		r.emit(ctx, "OnPolicyDeleted", e.name, func(ctx context.Context) error { return e.hook.OnPolicyDeleted(ctx, polID) })
 	}
 }
 
@@ -358,9 +420,52 @@ func (r *Registry) EmitPolicyDeleted(ctx context.Context, polID id.PolicyID) {
 // engine after merging RBAC / ReBAC / ABAC results.
 func (r *Registry) EmitPolicyObligationFired(ctx context.Context, polID id.PolicyID, obligation string, req, result any) {
 	for _, e := range r.policyObligation {
-		if err := e.hook.OnPolicyObligationFired(ctx, polID, obligation, req, result); err != nil {
-			r.logHookError("OnPolicyObligationFired", e.name, err)
-		}
+		r.emit(ctx, "OnPolicyObligationFired", e.name, func(ctx context.Context) error {
+			return e.hook.OnPolicyObligationFired(ctx, polID, obligation, req, result)
+		})
+	}
+}
+
+// EmitPolicyShadowDecision notifies all plugins that implement
+// PolicyShadowDecision. Called once per shadow-mode policy whose would-be
+// decision differs from the enforced one.
+func (r *Registry) EmitPolicyShadowDecision(ctx context.Context, polID id.PolicyID, shadow, req, result any) {
+	for _, e := range r.policyShadow {
+		r.emit(ctx, "OnPolicyShadowDecision", e.name, func(ctx context.Context) error {
+			return e.hook.OnPolicyShadowDecision(ctx, polID, shadow, req, result)
+		})
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass emitters
+// ──────────────────────────────────────────────────
+
+// EmitBreakGlassActivated notifies all plugins that implement BreakGlassActivated.
+func (r *Registry) EmitBreakGlassActivated(ctx context.Context, g *breakglass.Grant) {
+	for _, e := range r.breakGlassOn {
+		r.emit(ctx, "OnBreakGlassActivated", e.name, func(ctx context.Context) error {
+			return e.hook.OnBreakGlassActivated(ctx, g)
+		})
+	}
+}
+
+// EmitBreakGlassUsed notifies all plugins that implement BreakGlassUsed.
+// Called once per Check served by a break-glass grant.
+func (r *Registry) EmitBreakGlassUsed(ctx context.Context, g *breakglass.Grant, req, result any) {
+	for _, e := range r.breakGlassUsed {
+		r.emit(ctx, "OnBreakGlassUsed", e.name, func(ctx context.Context) error {
+			return e.hook.OnBreakGlassUsed(ctx, g, req, result)
+		})
+	}
+}
+
+// EmitBreakGlassRevoked notifies all plugins that implement BreakGlassRevoked.
+func (r *Registry) EmitBreakGlassRevoked(ctx context.Context, g *breakglass.Grant) {
+	for _, e := range r.breakGlassOff {
+		r.emit(ctx, "OnBreakGlassRevoked", e.name, func(ctx context.Context) error {
+			return e.hook.OnBreakGlassRevoked(ctx, g)
+		})
 	}
 }
 
@@ -368,17 +473,17 @@ func (r *Registry) EmitPolicyObligationFired(ctx context.Context, polID id.Polic
 // Shutdown emitter
 // ──────────────────────────────────────────────────
 
-// EmitShutdown notifies all plugins that implement Shutdown.
+// EmitShutdown notifies all plugins that implement Shutdown. Shutdown
+// hooks always run synchronously, even for async plugins.
 func (r *Registry) EmitShutdown(ctx context.Context) {
 	for _, e := range r.shutdown {
-		if err := e.hook.OnShutdown(ctx); err != nil {
-			r.logHookError("OnShutdown", e.name, err)
-		}
+		r.run(ctx, "OnShutdown", e.name, func(ctx context.Context) error { return e.hook.OnShutdown(ctx) })
 	}
 }
 
-// logHookError logs a warning when a lifecycle hook returns an error.
-// Errors from hooks are never propagated — they must not block the pipeline.
+// logHookError logs a warning when a lifecycle hook returns an error, times
+// out or panics. Errors from hooks are never propagated — they must not
+// block the pipeline.
 func (r *Registry) logHookError(hook, pluginName string, err error) {
 	r.logger.Warn("plugin hook error",
 		log.String("hook", hook),
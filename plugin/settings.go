@@ -0,0 +1,98 @@
+package plugin
+
+import "time"
+
+// FailMode decides what happens to a check when a veto-capable check hook
+// returns an error.
+type FailMode int
+
+const (
+	// FailOpen logs the error and lets the check continue as if the hook
+	// had not run. This is the default.
+	FailOpen FailMode = iota
+
+	// FailClosed denies the check when the hook errors. Use it for hooks
+	// whose absence must not grant access, such as tenant suspension.
+	FailClosed
+)
+
+// String returns "open" or "closed".
+func (m FailMode) String() string {
+	if m == FailClosed {
+		return "closed"
+	}
+	return "open"
+}
+
+// DispatchMode selects how a plugin's lifecycle notifications (the Emit*
+// events) are delivered.
+type DispatchMode int
+
+const (
+	// DispatchSync runs hooks on the caller's goroutine. This is the
+	// default.
+	DispatchSync DispatchMode = iota
+
+	// DispatchAsync queues events for a per-plugin worker so a slow plugin
+	// does not add latency to the request. Events are delivered in order;
+	// they are dropped (and counted) when the queue is full.
+	DispatchAsync
+)
+
+// String returns "sync" or "async".
+func (m DispatchMode) String() string {
+	if m == DispatchAsync {
+		return "async"
+	}
+	return "sync"
+}
+
+// Settings control how the engine runs a plugin's hooks.
+type Settings struct {
+	// Priority orders veto-capable check hooks: lower values run first,
+	// and plugins with equal priority run in registration order. The
+	// first hook to decide a check wins.
+	Priority int
+
+	// FailMode applies when one of the plugin's check hooks errors.
+	FailMode FailMode
+
+	// Dispatch selects sync or async delivery of lifecycle notifications.
+	// Hooks whose result the engine needs (check hooks, attribute
+	// providers) always run synchronously.
+	Dispatch DispatchMode
+
+	// QueueSize bounds the async queue. Defaults to DefaultQueueSize.
+	QueueSize int
+
+	// Timeout bounds each hook call; zero means no limit. A timed-out
+	// call is abandoned and reported as ErrHookTimeout.
+	Timeout time.Duration
+}
+
+// RegisterOption configures the Settings of a plugin at registration.
+type RegisterOption func(*Settings)
+
+// WithPriority sets the plugin's check-hook precedence. Lower runs first.
+func WithPriority(priority int) RegisterOption {
+	return func(s *Settings) { s.Priority = priority }
+}
+
+// WithFailMode sets what a check-hook error means for the check.
+func WithFailMode(mode FailMode) RegisterOption {
+	return func(s *Settings) { s.FailMode = mode }
+}
+
+// WithAsync delivers the plugin's lifecycle notifications from a bounded
+// queue of queueSize events (DefaultQueueSize when not positive).
+func WithAsync(queueSize int) RegisterOption {
+	return func(s *Settings) {
+		s.Dispatch = DispatchAsync
+		s.QueueSize = queueSize
+	}
+}
+
+// WithTimeout bounds each of the plugin's hook calls.
+func WithTimeout(d time.Duration) RegisterOption {
+	return func(s *Settings) { s.Timeout = d }
+}
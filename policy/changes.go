@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultChangePollInterval is how often a ChangeFeed polls for policy
+// changes made by other processes.
+const DefaultChangePollInterval = 2 * time.Second
+
+// changeWindow is how far back each poll looks past the previous one, so a
+// change committed late, or stamped by a writer whose clock lags, is still
+// seen.
+const changeWindow = time.Minute
+
+// Change is the policy version a store records for a tenant. Stores bump
+// Version and set ChangedAt on every write a ChangeNotifier reports.
+type Change struct {
+	TenantID  string
+	Version   int64
+	ChangedAt time.Time
+}
+
+// ChangeFeed implements ChangeNotifier for a store that records a Change
+// per tenant. Watchers are told of writes made through the store when the
+// store calls Notify, and of writes made by other processes sharing the
+// database when a poll finds a recorded version they have not seen.
+// The zero value is not usable; create one with NewChangeFeed.
+type ChangeFeed struct {
+	poll     func(ctx context.Context, since time.Time) ([]Change, error)
+	interval time.Duration
+
+	mu       sync.Mutex
+	watchers map[int]func(tenantID string)
+	next     int
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewChangeFeed returns a feed that calls poll every interval while it has
+// watchers. poll returns the versions recorded at or after since. A nil
+// poll only reports Notify calls; a non-positive interval uses
+// DefaultChangePollInterval.
+func NewChangeFeed(poll func(ctx context.Context, since time.Time) ([]Change, error), interval time.Duration) *ChangeFeed {
+	if interval <= 0 {
+		interval = DefaultChangePollInterval
+	}
+	return &ChangeFeed{
+		poll:     poll,
+		interval: interval,
+		watchers: make(map[int]func(string)),
+	}
+}
+
+// NotifyPolicyChanges implements ChangeNotifier. Polling starts with the
+// first watcher and stops when the last one is unregistered.
+func (f *ChangeFeed) NotifyPolicyChanges(fn func(tenantID string)) (stop func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := f.next
+	f.next++
+	f.watchers[key] = fn
+	if f.poll != nil && f.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		f.cancel, f.done = cancel, make(chan struct{})
+		go f.run(ctx, f.done)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			f.mu.Lock()
+			delete(f.watchers, key)
+			var wait func()
+			if len(f.watchers) == 0 {
+				wait = f.stopLocked()
+			}
+			f.mu.Unlock()
+			if wait != nil {
+				wait()
+			}
+		})
+	}
+}
+
+// Notify tells every watcher that a policy of tenantID changed.
+func (f *ChangeFeed) Notify(tenantID string) {
+	for _, fn := range f.snapshot() {
+		fn(tenantID)
+	}
+}
+
+// Close stops polling and waits for an in-flight poll to finish. Watchers
+// stay registered; polling resumes when another one is added.
+func (f *ChangeFeed) Close() {
+	f.mu.Lock()
+	wait := f.stopLocked()
+	f.mu.Unlock()
+	wait()
+}
+
+// stopLocked cancels polling and returns a func that waits for it to end.
+// The caller holds f.mu and calls the func after releasing it.
+func (f *ChangeFeed) stopLocked() (wait func()) {
+	cancel, done := f.cancel, f.done
+	f.cancel, f.done = nil, nil
+	if cancel == nil {
+		return func() {}
+	}
+	cancel()
+	return func() { <-done }
+}
+
+func (f *ChangeFeed) snapshot() []func(string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fns := make([]func(string), 0, len(f.watchers))
+	for _, fn := range f.watchers {
+		fns = append(fns, fn)
+	}
+	return fns
+}
+
+// run polls until ctx is done. Each poll reports the tenants whose version
+// differs from the previous poll; a failed poll is retried from the same
+// point, so changes are late rather than lost.
+func (f *ChangeFeed) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	since := time.Now().Add(-changeWindow)
+	seen := make(map[string]int64)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		started := time.Now()
+		changes, err := f.poll(ctx, since)
+		if err != nil {
+			continue
+		}
+		next := make(map[string]int64, len(changes))
+		for _, c := range changes {
+			next[c.TenantID] = c.Version
+			if v, ok := seen[c.TenantID]; !ok || v != c.Version {
+				f.Notify(c.TenantID)
+			}
+		}
+		seen = next
+		since = started.Add(-changeWindow)
+	}
+}
@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChangeFeed(t *testing.T) {
+	var mu sync.Mutex
+	versions := map[string]int64{}
+	polls := 0
+	feed := NewChangeFeed(func(_ context.Context, _ time.Time) ([]Change, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+		var out []Change
+		for tenant, v := range versions {
+			out = append(out, Change{TenantID: tenant, Version: v, ChangedAt: time.Now()})
+		}
+		return out, nil
+	}, time.Millisecond)
+
+	got := make(chan string, 16)
+	stop := feed.NotifyPolicyChanges(func(tenantID string) { got <- tenantID })
+
+	feed.Notify("local")
+	if tenant := <-got; tenant != "local" {
+		t.Fatalf("expected the local write to be reported, got %q", tenant)
+	}
+
+	// A version written by another process is reported once.
+	mu.Lock()
+	versions["t1"] = 1
+	mu.Unlock()
+	select {
+	case tenant := <-got:
+		if tenant != "t1" {
+			t.Fatalf("expected t1, got %q", tenant)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the polled change to be reported")
+	}
+	waitPolls := func() {
+		mu.Lock()
+		n := polls
+		mu.Unlock()
+		for {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			done := polls > n+1
+			mu.Unlock()
+			if done {
+				return
+			}
+		}
+	}
+	waitPolls()
+	select {
+	case tenant := <-got:
+		t.Fatalf("an unchanged version must not be reported again, got %q", tenant)
+	default:
+	}
+
+	// Polling stops with the last watcher.
+	stop()
+	mu.Lock()
+	n := polls
+	versions["t1"] = 2
+	mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if polls != n {
+		t.Fatalf("expected no polls after the last watcher stopped, got %d", polls-n)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no notifications after stop, got %q", <-got)
+	}
+}
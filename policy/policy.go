@@ -13,7 +13,15 @@
 //     records every obligation that fired in CheckResult.Obligations and
 //     emits a plugin hook (PolicyObligationFired) so audit / Chronicle /
 //     notification systems can react. Examples: "audit-log", "require-mfa",
-//     "notify-security", "step-up-auth".
+//     "notify-security", "step-up-auth". StructuredObligations and Advice
+//     carry parameters ("require-mfa with max_age=300") and say which
+//     decision they apply to.
+//   - Modes: a shadow policy is evaluated on every check but never changes
+//     the decision; the engine reports where it would have. Use it to
+//     observe a new deny policy before enforcing it.
+//   - Error handling: a policy whose conditions fail to evaluate is
+//     isolated. OnError decides whether it makes the check indeterminate
+//     or is skipped.
 package policy
 
 import (
@@ -33,6 +41,55 @@ const (
 	EffectDeny Effect = "deny"
 )
 
+// Mode controls whether a policy's matches are enforced.
+type Mode string
+
+const (
+	// ModeEnforce applies the policy's effect. It is the default when Mode
+	// is empty.
+	ModeEnforce Mode = "enforce"
+
+	// ModeShadow evaluates the policy on every check without letting it
+	// affect the decision. Checks whose outcome it would have changed
+	// are reported in CheckResult.Shadow, the check log and the
+	// PolicyShadowDecision plugin hook.
+	ModeShadow Mode = "shadow"
+
+	// ModeDisabled skips the policy entirely, like IsActive=false.
+	ModeDisabled Mode = "disabled"
+)
+
+// Valid reports whether m is empty or one of the known modes.
+func (m Mode) Valid() bool {
+	switch m {
+	case "", ModeEnforce, ModeShadow, ModeDisabled:
+		return true
+	}
+	return false
+}
+
+// OnError selects how a policy whose conditions fail to evaluate, for
+// example on an invalid regex, affects the check.
+type OnError string
+
+const (
+	// OnErrorDeny makes the check indeterminate, which is never allowed.
+	// It is the default when OnError is empty.
+	OnErrorDeny OnError = "deny"
+
+	// OnErrorSkip ignores the policy as if it had not matched.
+	OnErrorSkip OnError = "skip"
+)
+
+// Valid reports whether o is empty or one of the known settings.
+func (o OnError) Valid() bool {
+	switch o {
+	case "", OnErrorDeny, OnErrorSkip:
+		return true
+	}
+	return false
+}
+
 // Policy defines an attribute-based / policy-based access-control rule.
 //
 // NamespacePath locates the policy within the tenant's namespace tree. A
@@ -49,34 +106,50 @@ const (
 // the PolicyObligationFired plugin hook for each. Obligations don't
 // change the allow/deny decision; they're side-effect signals consumed by
 // audit, notification, and step-up-auth systems.
+//
+// StructuredObligations and Advice are the parameterised form. An
+// obligation must be honoured by the caller; advice may be ignored. Both
+// are only returned when the final decision matches their FulfillOn.
+//
+// Mode selects enforce (the default), shadow or disabled. Shadow policies
+// emit no obligations.
+//
+// OnError selects deny (the default) or skip for a policy whose
+// conditions fail to evaluate. Either way the failure is reported in
+// CheckResult.Errors rather than failing the check.
 type Policy struct {
-	ID            id.PolicyID    `json:"id" db:"id"`
-	TenantID      string         `json:"tenant_id" db:"tenant_id"`
-	NamespacePath string         `json:"namespace_path,omitempty" db:"namespace_path"`
-	AppID         string         `json:"app_id" db:"app_id"`
-	Name          string         `json:"name" db:"name"`
-	Description   string         `json:"description,omitempty" db:"description"`
-	Effect        Effect         `json:"effect" db:"effect"`
-	Priority      int            `json:"priority" db:"priority"`
-	IsActive      bool           `json:"is_active" db:"is_active"`
-	NotBefore     *time.Time     `json:"not_before,omitempty" db:"not_before"`
-	NotAfter      *time.Time     `json:"not_after,omitempty" db:"not_after"`
-	Obligations   []string       `json:"obligations,omitempty" db:"-"`
-	Version       int            `json:"version" db:"version"`
-	Subjects      []SubjectMatch `json:"subjects" db:"-"`
-	Actions       []string       `json:"actions" db:"-"`
-	Resources     []string       `json:"resources" db:"-"`
-	Conditions    []Condition    `json:"conditions,omitempty" db:"-"`
-	Metadata      map[string]any `json:"metadata,omitempty" db:"metadata"`
-	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+	ID                    id.PolicyID    `json:"id" db:"id"`
+	TenantID              string         `json:"tenant_id" db:"tenant_id"`
+	NamespacePath         string         `json:"namespace_path,omitempty" db:"namespace_path"`
+	AppID                 string         `json:"app_id" db:"app_id"`
+	Name                  string         `json:"name" db:"name"`
+	Description           string         `json:"description,omitempty" db:"description"`
+	Effect                Effect         `json:"effect" db:"effect"`
+	Priority              int            `json:"priority" db:"priority"`
+	IsActive              bool           `json:"is_active" db:"is_active"`
+	Mode                  Mode           `json:"mode,omitempty" db:"mode"`
+	OnError               OnError        `json:"on_error,omitempty" db:"on_error"`
+	NotBefore             *time.Time     `json:"not_before,omitempty" db:"not_before"`
+	NotAfter              *time.Time     `json:"not_after,omitempty" db:"not_after"`
+	Obligations           []string       `json:"obligations,omitempty" db:"-"`
+	StructuredObligations []Obligation   `json:"structured_obligations,omitempty" db:"-"`
+	Advice                []Obligation   `json:"advice,omitempty" db:"-"`
+	Version               int            `json:"version" db:"version"`
+	Subjects              []SubjectMatch `json:"subjects" db:"-"`
+	Actions               []string       `json:"actions" db:"-"`
+	Resources             []string       `json:"resources" db:"-"`
+	Conditions            []Condition    `json:"conditions,omitempty" db:"-"`
+	Metadata              map[string]any `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt             time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at" db:"updated_at"`
 }
 
 // EffectiveAt reports whether the policy is active at instant t. Returns
-// false if IsActive is false, NotBefore is in the future, or NotAfter is
-// in the past. Inputs may be the zero time (treated as "no bound").
+// false if IsActive is false, the policy is disabled, NotBefore is in the
+// future, or NotAfter is in the past. Inputs may be the zero time (treated
+// as "no bound").
 func (p *Policy) EffectiveAt(t time.Time) bool {
-	if !p.IsActive {
+	if !p.IsActive || p.Mode == ModeDisabled {
 		return false
 	}
 	if p.NotBefore != nil && t.Before(*p.NotBefore) {
@@ -88,6 +161,54 @@ func (p *Policy) EffectiveAt(t time.Time) bool {
 	return true
 }
 
+// FulfillOn selects the final decision an obligation or advice applies to.
+type FulfillOn string
+
+const (
+	// FulfillOnAlways returns the obligation whatever the decision. It is
+	// the default when FulfillOn is empty.
+	FulfillOnAlways FulfillOn = "always"
+
+	// FulfillOnAllow returns the obligation only when access is allowed.
+	FulfillOnAllow FulfillOn = "allow"
+
+	// FulfillOnDeny returns the obligation only when access is denied.
+	FulfillOnDeny FulfillOn = "deny"
+)
+
+// Valid reports whether f is empty or one of the known values.
+func (f FulfillOn) Valid() bool {
+	switch f {
+	case "", FulfillOnAlways, FulfillOnAllow, FulfillOnDeny:
+		return true
+	}
+	return false
+}
+
+// Applies reports whether an obligation with this FulfillOn is returned
+// for a decision that allowed (or denied) access.
+func (f FulfillOn) Applies(allowed bool) bool {
+	switch f {
+	case FulfillOnAllow:
+		return allowed
+	case FulfillOnDeny:
+		return !allowed
+	default:
+		return true
+	}
+}
+
+// Obligation is a named, parameterised obligation or advice attached to a
+// policy. String parameters may reference request attributes with
+// "${field}" using the same field paths as conditions, for example
+// "${subject.email}". A parameter that is exactly one
+// reference takes the attribute's value with its type intact.
+type Obligation struct {
+	Name      string         `json:"name"`
+	Params    map[string]any `json:"params,omitempty"`
+	FulfillOn FulfillOn      `json:"fulfill_on,omitempty"`
+}
+
 // SubjectMatch defines which subjects a policy applies to.
 // Kind and ID are plain strings to avoid import cycles with the root warden package.
 type SubjectMatch struct {
@@ -97,11 +218,29 @@ type SubjectMatch struct {
 }
 
 // Condition is a single attribute predicate within a policy.
+//
+// The right-hand side is either the literal Value or, when ValueRef is
+// set, another request attribute ("subject.id", "resource.department")
+// resolved at check time. ValueRef takes precedence over Value.
+//
+// An expression condition sets Expression instead of Field, Operator and
+// Value: a boolean expression in the language of package expr, such as
+// `size(subject.groups) > 2 && resource.owner_id == subject.id`.
 type Condition struct {
-	ID       id.ConditionID `json:"id" db:"id"`
-	Field    string         `json:"field"`
-	Operator Operator       `json:"operator"`
-	Value    any            `json:"value"`
+	ID         id.ConditionID `json:"id" db:"id"`
+	Field      string         `json:"field"`
+	Operator   Operator       `json:"operator"`
+	Value      any            `json:"value"`
+	ValueRef   string         `json:"value_ref,omitempty"`
+	Expression string         `json:"expression,omitempty"`
+
+	// TimeZone is the IANA zone a schedule condition is evaluated in. It
+	// defaults to UTC, or to the calendar's zone for calendar operators.
+	TimeZone string `json:"time_zone,omitempty"`
+
+	// compiled is set by Compile; it is shared by copies of the condition
+	// and never mutated afterwards.
+	compiled *compiledValue
 }
 
 // Operator is a comparison operator for conditions.
@@ -158,6 +297,38 @@ const (
 
 	// OpRegex checks if a value matches a regular expression.
 	OpRegex Operator = "regex"
+
+	// OpAnyIn checks if a collection shares at least one element with a set.
+	OpAnyIn Operator = "any_in"
+
+	// OpAllIn checks if every element of a collection is in a set.
+	OpAllIn Operator = "all_in"
+
+	// OpContainsElement checks if a collection contains a value.
+	OpContainsElement Operator = "contains_element"
+
+	// OpSizeGT checks if a collection has more elements than a number.
+	OpSizeGT Operator = "size_gt"
+
+	// OpSizeLT checks if a collection has fewer elements than a number.
+	OpSizeLT Operator = "size_lt"
+
+	// OpDayOfWeek checks if a time falls on one of the given days, e.g.
+	// "mon-fri".
+	OpDayOfWeek Operator = "day_of_week"
+
+	// OpTimeOfDay checks if a time falls within one of the given daily
+	// ranges, e.g. "09:00-17:00".
+	OpTimeOfDay Operator = "time_of_day"
+
+	// OpSchedule checks if a time matches a five-field cron expression.
+	OpSchedule Operator = "schedule"
+
+	// OpInCalendar checks if a time falls on a date of a named calendar.
+	OpInCalendar Operator = "in_calendar"
+
+	// OpNotInCalendar checks if a time falls on no date of a named calendar.
+	OpNotInCalendar Operator = "not_in_calendar"
 )
 
 // ListFilter contains filters for listing policies.
@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xraph/warden/counter"
+)
+
+// RateRef is a rate(subject, action, window) condition field: the number of
+// times the check's subject performed an action within the trailing
+// window. Counts are kept by the engine and incremented by the
+// ObligationIncrementRate obligation or Engine.IncrementRate.
+type RateRef struct {
+	// Action is the counted action. Empty counts the action being checked,
+	// written as the bare word action.
+	Action string
+
+	// Window is the length of the trailing window.
+	Window time.Duration
+}
+
+// ObligationIncrementRate is the obligation the engine fulfils itself by
+// counting one event for the check's subject. The optional "action" param
+// names the counted action, which defaults to the action being checked;
+// the optional "count" param is the number of events to add.
+const ObligationIncrementRate = "increment_rate"
+
+// ParseRate parses a rate(subject, action, window) condition field, such as
+// `rate(subject, export, 1h)`. Arguments may be double-quoted. ok is false
+// when field is not a rate call; err reports a malformed one.
+func ParseRate(field string) (ref RateRef, ok bool, err error) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(field), "rate(")
+	if !found {
+		return RateRef{}, false, nil
+	}
+	args, found := strings.CutSuffix(rest, ")")
+	if !found {
+		return RateRef{}, true, fmt.Errorf("rate %q: missing closing parenthesis", field)
+	}
+	parts := strings.Split(args, ",")
+	if len(parts) != 3 {
+		return RateRef{}, true, fmt.Errorf("rate %q: want rate(subject, action, window)", field)
+	}
+	var vals [3]string
+	var quoted [3]bool
+	for i, p := range parts {
+		vals[i], quoted[i], err = rateArg(p)
+		if err != nil {
+			return RateRef{}, true, fmt.Errorf("rate %q: %w", field, err)
+		}
+	}
+	if vals[0] != "subject" || quoted[0] {
+		return RateRef{}, true, fmt.Errorf("rate %q: the first argument must be subject", field)
+	}
+	switch {
+	case vals[1] == "":
+		return RateRef{}, true, fmt.Errorf("rate %q: action is required", field)
+	case vals[1] != "action" || quoted[1]:
+		// A quoted "action" names an action called action.
+		ref.Action = vals[1]
+	}
+	window, err := time.ParseDuration(vals[2])
+	if err != nil {
+		return RateRef{}, true, fmt.Errorf("rate %q: invalid window %q", field, vals[2])
+	}
+	if err := counter.ValidateWindow(window); err != nil {
+		return RateRef{}, true, fmt.Errorf("rate %q: %w", field, err)
+	}
+	ref.Window = window
+	return ref, true, nil
+}
+
+// rateArg trims one argument and removes its quotes, if any.
+func rateArg(s string) (string, bool, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, `"`) {
+		return s, false, nil
+	}
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", false, fmt.Errorf("malformed string %s", s)
+	}
+	return v, true, nil
+}
+
+// String returns the canonical field form of r.
+func (r RateRef) String() string {
+	action := "action"
+	if r.Action != "" {
+		action = r.Action
+		if action == "action" || strings.ContainsAny(action, " \t") {
+			action = strconv.Quote(action)
+		}
+	}
+	return fmt.Sprintf("rate(subject, %s, %s)", action, FormatWindow(r.Window))
+}
+
+// FormatWindow writes d in the largest whole unit, e.g. "1h" or "90s".
+func FormatWindow(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return d.String()
+}
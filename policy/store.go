@@ -42,3 +42,13 @@ type Store interface {
 	// DeletePoliciesByTenant removes all policies for a tenant.
 	DeletePoliciesByTenant(ctx context.Context, tenantID string) error
 }
+
+// ChangeNotifier is implemented by stores that can report policy changes as
+// they are committed. The engine uses it to keep its compiled policy index
+// current; stores that do not implement it are re-read periodically.
+type ChangeNotifier interface {
+	// NotifyPolicyChanges registers fn to be called after a policy or
+	// separation-of-duties constraint of tenantID is created, updated or
+	// deleted. The returned func unregisters fn.
+	NotifyPolicyChanges(fn func(tenantID string)) (stop func())
+}
@@ -0,0 +1,276 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/xraph/warden/expr"
+	"github.com/xraph/warden/schedule"
+)
+
+// Valid reports whether o is a known operator.
+func (o Operator) Valid() bool {
+	switch o {
+	case OpEquals, OpNotEquals, OpIn, OpNotIn, OpContains, OpStartsWith, OpEndsWith,
+		OpGreaterThan, OpLessThan, OpGTE, OpLTE, OpExists, OpNotExists,
+		OpIPInCIDR, OpTimeAfter, OpTimeBefore, OpRegex,
+		OpAnyIn, OpAllIn, OpContainsElement, OpSizeGT, OpSizeLT,
+		OpDayOfWeek, OpTimeOfDay, OpSchedule, OpInCalendar, OpNotInCalendar:
+		return true
+	}
+	return false
+}
+
+// IsSchedule reports whether o matches a point in time against a recurring
+// schedule or a calendar. The condition's field names the time attribute,
+// or is "now" for the evaluation clock.
+func (o Operator) IsSchedule() bool {
+	switch o {
+	case OpDayOfWeek, OpTimeOfDay, OpSchedule, OpInCalendar, OpNotInCalendar:
+		return true
+	}
+	return false
+}
+
+// Validate reports a condition that can never evaluate: an unknown
+// operator, an expression that does not compile, a malformed rate field,
+// or a literal regex or CIDR that does not parse. Call it when a policy is written so a bad
+// condition is rejected up front instead of failing checks.
+func (c Condition) Validate() error {
+	if c.Expression != "" {
+		_, err := expr.Compile(c.Expression, nil)
+		return err
+	}
+	if c.Field == "" {
+		return errors.New("field is required")
+	}
+	if !c.Operator.Valid() {
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	if _, _, err := ParseRate(c.Field); err != nil {
+		return err
+	}
+	if c.TimeZone != "" {
+		if !c.Operator.IsSchedule() {
+			return fmt.Errorf("time zone is not supported by operator %q", c.Operator)
+		}
+		if _, err := schedule.LoadLocation(c.TimeZone); err != nil {
+			return err
+		}
+	}
+	if c.ValueRef != "" {
+		return nil
+	}
+	_, err := c.compile()
+	return err
+}
+
+// compiledValue is a condition's literal value pre-parsed for its operator.
+type compiledValue struct {
+	re       *regexp.Regexp
+	networks []*net.IPNet
+	at       time.Time
+	hasTime  bool
+	schedule schedule.Matcher
+	loc      *time.Location
+}
+
+// compile parses the literal value of a regex, CIDR, time or schedule
+// condition and checks the value of a collection condition. It returns nil
+// for operators whose value needs no parsing.
+func (c Condition) compile() (*compiledValue, error) {
+	switch c.Operator {
+	case OpRegex:
+		pattern, ok := c.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("regex pattern must be a string, got %T", c.Value)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		return &compiledValue{re: re}, nil
+	case OpIPInCIDR:
+		cidrs, ok := stringValues(c.Value)
+		if !ok {
+			return nil, fmt.Errorf("CIDR must be a string or a list of strings, got %T", c.Value)
+		}
+		cv := &compiledValue{networks: make([]*net.IPNet, 0, len(cidrs))}
+		for _, cidr := range cidrs {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q", cidr)
+			}
+			cv.networks = append(cv.networks, network)
+		}
+		return cv, nil
+	case OpTimeAfter, OpTimeBefore:
+		switch v := c.Value.(type) {
+		case time.Time:
+			return &compiledValue{at: v, hasTime: true}, nil
+		case string:
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return &compiledValue{at: t, hasTime: true}, nil
+			}
+		}
+	case OpAnyIn, OpAllIn, OpContainsElement:
+		if c.Value == nil {
+			return nil, fmt.Errorf("operator %q needs a value", c.Operator)
+		}
+	case OpSizeGT, OpSizeLT:
+		if n, ok := sizeValue(c.Value); !ok || n < 0 {
+			return nil, fmt.Errorf("size must be a non-negative integer, got %v", c.Value)
+		}
+	case OpDayOfWeek, OpTimeOfDay, OpSchedule, OpInCalendar, OpNotInCalendar:
+		m, loc, err := c.parseSchedule()
+		if err != nil {
+			return nil, err
+		}
+		return &compiledValue{schedule: m, loc: loc}, nil
+	}
+	return nil, nil
+}
+
+// parseSchedule parses the value and time zone of a schedule condition.
+// Calendar operators have no matcher and a nil location unless TimeZone is
+// set, so the calendar's own zone applies.
+func (c Condition) parseSchedule() (schedule.Matcher, *time.Location, error) {
+	var loc *time.Location
+	if c.TimeZone != "" || !c.isCalendar() {
+		var err error
+		if loc, err = schedule.LoadLocation(c.TimeZone); err != nil {
+			return nil, nil, err
+		}
+	}
+	switch c.Operator {
+	case OpDayOfWeek:
+		days, err := schedule.ParseWeekdays(c.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		return days, loc, nil
+	case OpTimeOfDay:
+		ranges, err := schedule.ParseTimeRanges(c.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ranges, loc, nil
+	case OpSchedule:
+		spec, ok := c.Value.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("schedule must be a cron string, got %T", c.Value)
+		}
+		cron, err := schedule.ParseCron(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cron, loc, nil
+	}
+	if name, ok := c.Value.(string); !ok || name == "" {
+		return nil, nil, fmt.Errorf("calendar name must be a non-empty string, got %v", c.Value)
+	}
+	return nil, loc, nil
+}
+
+func (c Condition) isCalendar() bool {
+	return c.Operator == OpInCalendar || c.Operator == OpNotInCalendar
+}
+
+// Compile pre-parses the condition's literal value so evaluation does not
+// repeat the work on every check. Conditions read from a store are not
+// compiled; the engine compiles the ones it holds in its policy index. A
+// condition that fails to compile is left as is.
+func (c *Condition) Compile() error {
+	if c.Expression != "" || c.ValueRef != "" {
+		return nil
+	}
+	cv, err := c.compile()
+	if err != nil {
+		return err
+	}
+	c.compiled = cv
+	return nil
+}
+
+// Regexp returns the compiled pattern of a compiled regex condition.
+func (c Condition) Regexp() *regexp.Regexp {
+	if c.compiled == nil {
+		return nil
+	}
+	return c.compiled.re
+}
+
+// Networks returns the parsed networks of a compiled ip_in_cidr condition.
+func (c Condition) Networks() []*net.IPNet {
+	if c.compiled == nil {
+		return nil
+	}
+	return c.compiled.networks
+}
+
+// Time returns the parsed value of a compiled time_after or time_before
+// condition.
+func (c Condition) Time() (time.Time, bool) {
+	if c.compiled == nil {
+		return time.Time{}, false
+	}
+	return c.compiled.at, c.compiled.hasTime
+}
+
+// Schedule returns the matcher and time zone of a day_of_week, time_of_day
+// or schedule condition, parsing them unless the condition is compiled.
+// For calendar operators the matcher is nil and the location is nil
+// unless TimeZone is set.
+func (c Condition) Schedule() (schedule.Matcher, *time.Location, error) {
+	if c.compiled != nil && c.Operator.IsSchedule() {
+		return c.compiled.schedule, c.compiled.loc, nil
+	}
+	return c.parseSchedule()
+}
+
+// sizeValue returns the integer a size_gt or size_lt condition compares
+// against. Whole floats, as decoded from JSON, are accepted.
+func sizeValue(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		if n == math.Trunc(n) {
+			return int(n), true
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return int(i), true
+		}
+	}
+	return 0, false
+}
+
+func stringValues(v any) ([]string, bool) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
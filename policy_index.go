@@ -0,0 +1,481 @@
+package warden
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/resourcetype"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/store"
+)
+
+// defaultPolicyIndexTTL is used when Config.PolicyIndexTTL is zero and the
+// store implements policy.ChangeNotifier.
+const defaultPolicyIndexTTL = 30 * time.Second
+
+// maxPolicyIndexSchemas bounds the attribute schemas cached by a policy
+// index. Expired schemas are swept once it is reached, and new ones are not
+// cached while that frees nothing.
+const maxPolicyIndexSchemas = 10000
+
+// policyIndex holds the active policies of each tenant scope, compiled and
+// bucketed so a check only evaluates the policies that can apply to its
+// action and resource type, the attribute schemas of the resource types
+// its checks name, and each tenant's dynamic separation-of-duties
+// constraints.
+//
+// Entries are dropped when a policy, resource type or separation-of-duties
+// constraint of their tenant changes through Engine.Store, or a change is
+// reported by a policy.ChangeNotifier store, and are reloaded after the TTL
+// to pick up changes made elsewhere.
+type policyIndex struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.RWMutex
+	entries map[policyIndexKey]*policySet
+	schemas map[schemaIndexKey]schemaIndexEntry
+	sod     map[string]sodIndexEntry // by tenant
+	// epochs counts invalidations per tenant so a load that raced with a
+	// change is not stored.
+	epochs map[string]uint64
+}
+
+type policyIndexKey struct {
+	tenantID, namespacePath string
+}
+
+type schemaIndexKey struct {
+	tenantID, namespacePath, name string
+}
+
+type schemaIndexEntry struct {
+	attrs   []resourcetype.AttributeDef
+	expires time.Time
+}
+
+type sodIndexEntry struct {
+	constraints []*sod.Constraint
+	expires     time.Time
+}
+
+// newPolicyIndex returns the index for a store, or nil when it is
+// disabled. A zero ttl enables it only when the store reports policy
+// changes, since otherwise changes made by other processes would go unseen
+// for up to the TTL.
+func newPolicyIndex(ttl time.Duration, notified bool) *policyIndex {
+	if ttl < 0 || ttl == 0 && !notified {
+		return nil
+	}
+	if ttl == 0 {
+		ttl = defaultPolicyIndexTTL
+	}
+	return &policyIndex{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[policyIndexKey]*policySet),
+		schemas: make(map[schemaIndexKey]schemaIndexEntry),
+		sod:     make(map[string]sodIndexEntry),
+		epochs:  make(map[string]uint64),
+	}
+}
+
+// get returns the policy set of a tenant scope, calling load on a miss.
+func (x *policyIndex) get(tenantID, namespacePath string, load func() ([]*policy.Policy, error)) (*policySet, error) {
+	key := policyIndexKey{tenantID, namespacePath}
+	now := x.now()
+	x.mu.RLock()
+	set, ok := x.entries[key]
+	epoch := x.epochs[tenantID]
+	x.mu.RUnlock()
+	if ok && now.Before(set.expires) {
+		return set, nil
+	}
+
+	policies, err := load()
+	if err != nil {
+		return nil, err
+	}
+	set = newPolicySet(policies, now.Add(x.ttl))
+
+	x.mu.Lock()
+	if x.epochs[tenantID] == epoch {
+		x.entries[key] = set
+	}
+	x.mu.Unlock()
+	return set, nil
+}
+
+// schema returns the attributes declared on the resource type name as seen
+// from a tenant scope, calling load on a miss.
+func (x *policyIndex) schema(tenantID, namespacePath, name string, load func() []resourcetype.AttributeDef) []resourcetype.AttributeDef {
+	key := schemaIndexKey{tenantID, namespacePath, name}
+	now := x.now()
+	x.mu.RLock()
+	ent, ok := x.schemas[key]
+	epoch := x.epochs[tenantID]
+	x.mu.RUnlock()
+	if ok && now.Before(ent.expires) {
+		return ent.attrs
+	}
+
+	attrs := load()
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.epochs[tenantID] != epoch {
+		return attrs
+	}
+	if len(x.schemas) >= maxPolicyIndexSchemas {
+		for k, ent := range x.schemas {
+			if !now.Before(ent.expires) {
+				delete(x.schemas, k)
+			}
+		}
+		if len(x.schemas) >= maxPolicyIndexSchemas {
+			return attrs
+		}
+	}
+	x.schemas[key] = schemaIndexEntry{attrs: attrs, expires: now.Add(x.ttl)}
+	return attrs
+}
+
+// dynamicSoD returns the dynamic separation-of-duties constraints of a
+// tenant, calling load on a miss. The constraints are shared by concurrent
+// checks and must not be modified.
+func (x *policyIndex) dynamicSoD(tenantID string, load func() ([]*sod.Constraint, error)) ([]*sod.Constraint, error) {
+	now := x.now()
+	x.mu.RLock()
+	ent, ok := x.sod[tenantID]
+	epoch := x.epochs[tenantID]
+	x.mu.RUnlock()
+	if ok && now.Before(ent.expires) {
+		return ent.constraints, nil
+	}
+
+	constraints, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	x.mu.Lock()
+	if x.epochs[tenantID] == epoch {
+		x.sod[tenantID] = sodIndexEntry{constraints: constraints, expires: now.Add(x.ttl)}
+	}
+	x.mu.Unlock()
+	return constraints, nil
+}
+
+// invalidate drops every entry of tenantID.
+func (x *policyIndex) invalidate(tenantID string) {
+	if x == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.epochs[tenantID]++
+	for key := range x.entries {
+		if key.tenantID == tenantID {
+			delete(x.entries, key)
+		}
+	}
+	for key := range x.schemas {
+		if key.tenantID == tenantID {
+			delete(x.schemas, key)
+		}
+	}
+	delete(x.sod, tenantID)
+}
+
+// policySet is the compiled index of one tenant scope. It is immutable once
+// built and shared by concurrent checks.
+type policySet struct {
+	policies []*policy.Policy
+	expires  time.Time
+
+	// Policy positions, in load order, keyed by the exact action or
+	// resource type they name. Policies that name none, or only match
+	// through a glob, are listed under anyAction / anyType.
+	byAction  map[string][]int
+	anyAction []int
+	byType    map[string][]int
+	anyType   []int
+}
+
+func newPolicySet(policies []*policy.Policy, expires time.Time) *policySet {
+	s := &policySet{
+		policies: make([]*policy.Policy, len(policies)),
+		expires:  expires,
+		byAction: make(map[string][]int),
+		byType:   make(map[string][]int),
+	}
+	for i, pol := range policies {
+		s.policies[i] = compilePolicy(pol)
+
+		if len(pol.Actions) == 0 {
+			s.anyAction = append(s.anyAction, i)
+		}
+		for _, a := range pol.Actions {
+			if strings.Contains(a, "*") {
+				s.anyAction = appendPosition(s.anyAction, i)
+			} else {
+				s.byAction[a] = appendPosition(s.byAction[a], i)
+			}
+		}
+
+		if len(pol.Resources) == 0 {
+			s.anyType = append(s.anyType, i)
+		}
+		for _, r := range pol.Resources {
+			if t, ok := resourcePatternType(r); ok {
+				s.byType[t] = appendPosition(s.byType[t], i)
+			} else {
+				s.anyType = appendPosition(s.anyType, i)
+			}
+		}
+	}
+	return s
+}
+
+// compilePolicy returns a copy of pol with its conditions compiled. The
+// store's copy is left untouched.
+func compilePolicy(pol *policy.Policy) *policy.Policy {
+	cp := *pol
+	cp.Conditions = make([]policy.Condition, len(pol.Conditions))
+	for i, c := range pol.Conditions {
+		_ = c.Compile() //nolint:errcheck // left uncompiled; the evaluator reports the error
+		cp.Conditions[i] = c
+	}
+	return &cp
+}
+
+// resourcePatternType returns the single resource type a policy resource
+// pattern can match, or false when it may match several.
+func resourcePatternType(pattern string) (string, bool) {
+	t := pattern
+	if i := strings.IndexByte(pattern, ':'); i >= 0 {
+		t = pattern[:i]
+	}
+	if t == "" || strings.Contains(t, "*") {
+		return "", false
+	}
+	return t, true
+}
+
+// appendPosition appends i unless it is already the last position, which
+// happens when a policy names the same bucket twice.
+func appendPosition(positions []int, i int) []int {
+	if n := len(positions); n > 0 && positions[n-1] == i {
+		return positions
+	}
+	return append(positions, i)
+}
+
+// len returns the number of active policies in the scope.
+func (s *policySet) len() int { return len(s.policies) }
+
+// candidates returns, in load order, the policies that can apply to action
+// on resourceType. The evaluator still matches each one in full.
+func (s *policySet) candidates(action, resourceType string) []*policy.Policy {
+	if strings.Contains(resourceType, ":") {
+		// Resource patterns are split on ':'; such a type cannot be bucketed.
+		return s.policies
+	}
+	actions := mergePositions(s.byAction[action], s.anyAction)
+	types := mergePositions(s.byType[resourceType], s.anyType)
+	var out []*policy.Policy
+	for i, j := 0, 0; i < len(actions) && j < len(types); {
+		switch {
+		case actions[i] < types[j]:
+			i++
+		case actions[i] > types[j]:
+			j++
+		default:
+			out = append(out, s.policies[actions[i]])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// mergePositions returns the sorted union of two sorted position lists.
+func mergePositions(a, b []int) []int {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+	out := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+// activePolicies returns the active policies of scope that can apply to
+// req, and the number of active policies in the scope. Without an index
+// every active policy is returned.
+func (e *Engine) activePolicies(ctx context.Context, scope tenantScope, req *CheckRequest) ([]*policy.Policy, int, error) {
+	load := func() ([]*policy.Policy, error) {
+		return traceStore(ctx, "ListActivePolicies", func(ctx context.Context) ([]*policy.Policy, error) {
+			return e.store.ListActivePolicies(ctx, scope.tenantID, AncestorNamespaces(scope.namespacePath))
+		})
+	}
+	if e.policyIndex == nil {
+		policies, err := load()
+		return policies, len(policies), err
+	}
+	set, err := e.policyIndex.get(scope.tenantID, scope.namespacePath, load)
+	if err != nil {
+		return nil, 0, err
+	}
+	return set.candidates(req.Action.Name, req.Resource.Type), set.len(), nil
+}
+
+// InvalidatePolicies drops the compiled policies of tenantID so the next
+// check reloads them from the store. Changes made through Engine.Store do
+// this automatically; call it when policies are changed by other means,
+// e.g. by another process sharing the database.
+func (e *Engine) InvalidatePolicies(tenantID string) {
+	e.policyIndex.invalidate(tenantID)
+}
+
+// policyIndexStore wraps the engine's store and drops a tenant's compiled
+// policies, attribute schemas and separation-of-duties constraints
+// whenever one of its policies, resource types or constraints is written
+// through it.
+type policyIndexStore struct {
+	store.Store
+	index *policyIndex
+}
+
+// CheckLogStats forwards to the wrapped store so checklog.QueryStats keeps
+// using a backend's native aggregation.
+func (s *policyIndexStore) CheckLogStats(ctx context.Context, q *checklog.StatsQuery) (*checklog.Stats, error) {
+	return checklog.QueryStats(ctx, s.Store, q)
+}
+
+func (s *policyIndexStore) CreatePolicy(ctx context.Context, p *policy.Policy) error {
+	if err := s.Store.CreatePolicy(ctx, p); err != nil {
+		return err
+	}
+	s.index.invalidate(p.TenantID)
+	return nil
+}
+
+func (s *policyIndexStore) UpdatePolicy(ctx context.Context, p *policy.Policy) error {
+	if err := s.Store.UpdatePolicy(ctx, p); err != nil {
+		return err
+	}
+	s.index.invalidate(p.TenantID)
+	return nil
+}
+
+func (s *policyIndexStore) DeletePolicy(ctx context.Context, polID id.PolicyID) error {
+	before, _ := s.Store.GetPolicy(ctx, polID) //nolint:errcheck // missing → nothing indexed
+	if err := s.Store.DeletePolicy(ctx, polID); err != nil {
+		return err
+	}
+	if before != nil {
+		s.index.invalidate(before.TenantID)
+	}
+	return nil
+}
+
+func (s *policyIndexStore) DeletePoliciesByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeletePoliciesByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.index.invalidate(tenantID)
+	return nil
+}
+
+func (s *policyIndexStore) CreateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error {
+	if err := s.Store.CreateResourceType(ctx, rt); err != nil {
+		return err
+	}
+	s.index.invalidate(rt.TenantID)
+	return nil
+}
+
+func (s *policyIndexStore) UpdateResourceType(ctx context.Context, rt *resourcetype.ResourceType) error {
+	if err := s.Store.UpdateResourceType(ctx, rt); err != nil {
+		return err
+	}
+	s.index.invalidate(rt.TenantID)
+	return nil
+}
+
+func (s *policyIndexStore) DeleteResourceType(ctx context.Context, rtID id.ResourceTypeID) error {
+	before, _ := s.Store.GetResourceType(ctx, rtID) //nolint:errcheck // missing → nothing indexed
+	if err := s.Store.DeleteResourceType(ctx, rtID); err != nil {
+		return err
+	}
+	if before != nil {
+		s.index.invalidate(before.TenantID)
+	}
+	return nil
+}
+
+func (s *policyIndexStore) DeleteResourceTypesByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteResourceTypesByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.index.invalidate(tenantID)
+	return nil
+}
+
+func (s *policyIndexStore) CreateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	if err := s.Store.CreateSoDConstraint(ctx, c); err != nil {
+		return err
+	}
+	s.index.invalidate(c.TenantID)
+	return nil
+}
+
+func (s *policyIndexStore) UpdateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	if err := s.Store.UpdateSoDConstraint(ctx, c); err != nil {
+		return err
+	}
+	s.index.invalidate(c.TenantID)
+	return nil
+}
+
+func (s *policyIndexStore) DeleteSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) error {
+	before, _ := s.Store.GetSoDConstraint(ctx, sodID) //nolint:errcheck // missing → nothing indexed
+	if err := s.Store.DeleteSoDConstraint(ctx, sodID); err != nil {
+		return err
+	}
+	if before != nil {
+		s.index.invalidate(before.TenantID)
+	}
+	return nil
+}
+
+func (s *policyIndexStore) DeleteSoDConstraintsByTenant(ctx context.Context, tenantID string) error {
+	if err := s.Store.DeleteSoDConstraintsByTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	s.index.invalidate(tenantID)
+	return nil
+}
@@ -0,0 +1,154 @@
+package warden
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/store/memory"
+)
+
+func TestPolicySetCandidates(t *testing.T) {
+	pols := []*policy.Policy{
+		{Name: "doc-read", Actions: []string{"read"}, Resources: []string{"document:*"}},
+		{Name: "any-action", Resources: []string{"document"}},
+		{Name: "glob-action", Actions: []string{"re*"}, Resources: []string{"document:d1", "document:d2"}},
+		{Name: "any-resource", Actions: []string{"read", "write"}},
+		{Name: "glob-resource", Actions: []string{"read"}, Resources: []string{"doc*"}},
+		{Name: "report-read", Actions: []string{"read"}, Resources: []string{"report:*"}},
+		{Name: "doc-write", Actions: []string{"write"}, Resources: []string{"document:*"}},
+	}
+	set := newPolicySet(pols, time.Time{})
+
+	tests := []struct {
+		action, resourceType string
+		want                 []string
+	}{
+		{"read", "document", []string{"doc-read", "any-action", "glob-action", "any-resource", "glob-resource"}},
+		// Glob actions are kept as candidates; the evaluator rejects them.
+		{"write", "document", []string{"any-action", "glob-action", "any-resource", "doc-write"}},
+		{"read", "report", []string{"any-resource", "glob-resource", "report-read"}},
+		{"delete", "folder", nil},
+		{"read", "a:b", []string{"doc-read", "any-action", "glob-action", "any-resource", "glob-resource", "report-read", "doc-write"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action+" "+tt.resourceType, func(t *testing.T) {
+			var got []string
+			for _, pol := range set.candidates(tt.action, tt.resourceType) {
+				got = append(got, pol.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("candidates = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("candidates = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicySetCompilesConditions(t *testing.T) {
+	stored := &policy.Policy{Name: "p", Conditions: []policy.Condition{
+		{Field: "subject.email", Operator: policy.OpRegex, Value: `@example\.com$`},
+		{Field: "context.ip", Operator: policy.OpIPInCIDR, Value: []any{"10.0.0.0/8"}},
+		{Field: "context.time", Operator: policy.OpTimeAfter, Value: "2026-01-01T00:00:00Z"},
+		{Field: "context.ip", Operator: policy.OpIPInCIDR, Value: "bogus"},
+	}}
+	set := newPolicySet([]*policy.Policy{stored}, time.Time{})
+	conds := set.policies[0].Conditions
+
+	if conds[0].Regexp() == nil || len(conds[1].Networks()) != 1 {
+		t.Error("expected the regex and CIDR values to be compiled")
+	}
+	if at, ok := conds[2].Time(); !ok || at.Year() != 2026 {
+		t.Errorf("Time() = %v, %v", at, ok)
+	}
+	if conds[3].Networks() != nil {
+		t.Error("an invalid CIDR must stay uncompiled")
+	}
+	if stored.Conditions[0].Regexp() != nil {
+		t.Error("the stored policy must not be modified")
+	}
+}
+
+// TestPolicyIndexInvalidation verifies policy changes reach the index:
+// through Engine.Store, through a notifying store, and via
+// InvalidatePolicies.
+func TestPolicyIndexInvalidation(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	eng, err := NewEngine(WithStore(s), WithConfig(Config{PolicyIndexTTL: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "document", ID: "d1"},
+	}
+	check := func(want bool) {
+		t.Helper()
+		res, err := eng.Check(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed != want {
+			t.Fatalf("allowed = %v, want %v (%s)", res.Allowed, want, res.Reason)
+		}
+	}
+	check(false)
+
+	allow := &policy.Policy{
+		ID: id.NewPolicyID(), TenantID: "t1", Name: "allow-read", Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"}, Resources: []string{"document:*"},
+	}
+	if err := eng.Store().CreatePolicy(ctx, allow); err != nil {
+		t.Fatal(err)
+	}
+	check(true)
+
+	// Written to the store directly: the memory store notifies the engine.
+	allow.Actions = []string{"write"}
+	if err := s.UpdatePolicy(ctx, allow); err != nil {
+		t.Fatal(err)
+	}
+	check(false)
+
+	// Without notifications the index serves the compiled policies until
+	// it is told to reload.
+	eng2, err := NewEngine(WithStore(struct{ store.Store }{s}), WithConfig(Config{PolicyIndexTTL: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eng = eng2
+	check(false)
+	allow.Actions = []string{"read"}
+	if err := s.UpdatePolicy(ctx, allow); err != nil {
+		t.Fatal(err)
+	}
+	check(false)
+	eng.InvalidatePolicies("t1")
+	check(true)
+
+	// Without notifications the index is off unless a TTL is set, so
+	// every check reads the store.
+	eng3, err := NewEngine(WithStore(struct{ store.Store }{s}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eng3.policyIndex != nil {
+		t.Fatal("expected no policy index for a store without change notifications")
+	}
+	eng = eng3
+	check(true)
+	allow.Actions = []string{"write"}
+	if err := s.UpdatePolicy(ctx, allow); err != nil {
+		t.Fatal(err)
+	}
+	check(false)
+}
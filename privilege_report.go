@@ -0,0 +1,258 @@
+package warden
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/role"
+)
+
+// DefaultPrivilegeReportWindow is the check history examined when
+// PrivilegeReportOptions.Window is zero.
+const DefaultPrivilegeReportWindow = 90 * 24 * time.Hour
+
+// PrivilegeReportOptions configures Engine.PrivilegeReport.
+type PrivilegeReportOptions struct {
+	// TenantID is the tenant to report on. Defaults to the tenant in ctx.
+	TenantID string `json:"tenant_id,omitempty"`
+
+	// Window is how far back check history is examined. Defaults to
+	// DefaultPrivilegeReportWindow.
+	Window time.Duration `json:"window,omitempty"`
+
+	// Now is the end of the window. Defaults to time.Now().
+	Now time.Time `json:"now,omitempty"`
+}
+
+// PrivilegeReport lists grants that check history shows are not needed.
+//
+// Usage is derived from RBAC matches in the check log, so the report is only
+// as complete as the log: sampled or filtered checks (see CheckLogRules) and
+// purged entries count as unused.
+type PrivilegeReport struct {
+	TenantID string    `json:"tenant_id"`
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+
+	// Checks is the number of check log entries examined.
+	Checks int64 `json:"checks"`
+
+	// UnusedPermissions are role permissions that granted no check.
+	UnusedPermissions []UnusedPermission `json:"unused_permissions"`
+
+	// UnusedAssignments are assignments whose subject never exercised the
+	// role or any role it inherits from.
+	UnusedAssignments []UnusedAssignment `json:"unused_assignments"`
+
+	// OverPrivilegedRoles are assigned roles whose grants are a strict
+	// superset of what their members used.
+	OverPrivilegedRoles []OverPrivilegedRole `json:"over_privileged_roles"`
+}
+
+// UnusedPermission is a permission attached to a role that granted nothing
+// during the report window.
+type UnusedPermission struct {
+	RoleID        id.RoleID `json:"role_id"`
+	RoleSlug      string    `json:"role_slug"`
+	NamespacePath string    `json:"namespace_path,omitempty"`
+	Permission    string    `json:"permission"`
+}
+
+// UnusedAssignment is an assignment whose subject never exercised it during
+// the report window.
+type UnusedAssignment struct {
+	AssignmentID  id.AssignmentID `json:"assignment_id"`
+	RoleID        id.RoleID       `json:"role_id"`
+	RoleSlug      string          `json:"role_slug"`
+	NamespacePath string          `json:"namespace_path,omitempty"`
+	SubjectKind   string          `json:"subject_kind"`
+	SubjectID     string          `json:"subject_id"`
+}
+
+// OverPrivilegedRole is a role whose members use only part of its grants.
+type OverPrivilegedRole struct {
+	RoleID        id.RoleID `json:"role_id"`
+	RoleSlug      string    `json:"role_slug"`
+	NamespacePath string    `json:"namespace_path,omitempty"`
+	Members       int       `json:"members"`
+	Granted       []string  `json:"granted"`
+	Used          []string  `json:"used"`
+	Unused        []string  `json:"unused"`
+}
+
+// PrivilegeReport combines check history with role, permission and
+// assignment data to find permissions, assignments and roles that can be
+// removed or narrowed without affecting any recorded check.
+func (e *Engine) PrivilegeReport(ctx context.Context, opts PrivilegeReportOptions) (*PrivilegeReport, error) {
+	if opts.TenantID == "" {
+		opts.TenantID = tenantIDFromContext(ctx)
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultPrivilegeReportWindow
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	rep := &PrivilegeReport{
+		TenantID:            opts.TenantID,
+		Since:               opts.Now.Add(-opts.Window),
+		Until:               opts.Now,
+		UnusedPermissions:   []UnusedPermission{},
+		UnusedAssignments:   []UnusedAssignment{},
+		OverPrivilegedRoles: []OverPrivilegedRole{},
+	}
+
+	// 1. Collect RBAC usage: which stored permissions each role granted,
+	// and which roles each subject exercised. Every granting role of a
+	// check is credited. Pages are read by cursor so checks logged while
+	// the report runs don't shift the window.
+	permUse := make(map[string]map[string]struct{})    // role ID → "resource:action"
+	subjectUse := make(map[string]map[string]struct{}) // "kind:id" → role ID
+	const page = 1000
+	filter := &checklog.QueryFilter{
+		TenantID: opts.TenantID,
+		Decision: string(DecisionAllow),
+		After:    &rep.Since,
+		Before:   &rep.Until,
+		Limit:    page,
+	}
+	for {
+		batch, err := e.store.ListCheckLogs(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("warden: privilege report: list check logs: %w", err)
+		}
+		for _, entry := range batch {
+			for _, m := range entry.MatchedBy {
+				if m.Source != "rbac" || m.Permission == "" {
+					continue
+				}
+				addUse(permUse, m.RuleID, m.Permission)
+				addUse(subjectUse, entry.SubjectKind+":"+entry.SubjectID, m.RuleID)
+			}
+		}
+		rep.Checks += int64(len(batch))
+		if len(batch) < page {
+			break
+		}
+		filter.Cursor = checklog.CursorAfter(batch[len(batch)-1])
+	}
+
+	// 2. Unused permissions per role.
+	roles, err := e.store.ListRoles(ctx, &role.ListFilter{TenantID: opts.TenantID})
+	if err != nil {
+		return nil, fmt.Errorf("warden: privilege report: list roles: %w", err)
+	}
+	slices.SortFunc(roles, func(a, b *role.Role) int {
+		return cmp.Or(cmp.Compare(a.NamespacePath, b.NamespacePath), cmp.Compare(a.Slug, b.Slug))
+	})
+	byID := make(map[string]*role.Role, len(roles))
+	granted := make(map[string][]string, len(roles)) // role ID → permission names
+	unused := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		key := r.ID.String()
+		byID[key] = r
+		perms, err := e.store.ListRolePermissions(ctx, r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("warden: privilege report: list grants for role %s: %w", r.Slug, err)
+		}
+		slices.SortFunc(perms, func(a, b *permission.Permission) int { return cmp.Compare(a.Name, b.Name) })
+		for _, p := range perms {
+			granted[key] = append(granted[key], p.Name)
+			if _, ok := permUse[key][p.Resource+":"+p.Action]; ok {
+				continue
+			}
+			unused[key] = append(unused[key], p.Name)
+			rep.UnusedPermissions = append(rep.UnusedPermissions, UnusedPermission{
+				RoleID:        r.ID,
+				RoleSlug:      r.Slug,
+				NamespacePath: r.NamespacePath,
+				Permission:    p.Name,
+			})
+		}
+	}
+
+	// 3. Unused assignments. A subject exercises an assignment when any
+	// role it resolves to (the role or an ancestor) granted one of its
+	// checks.
+	assignments, err := e.store.ListAssignments(ctx, &assignment.ListFilter{TenantID: opts.TenantID})
+	if err != nil {
+		return nil, fmt.Errorf("warden: privilege report: list assignments: %w", err)
+	}
+	slices.SortFunc(assignments, func(a, b *assignment.Assignment) int {
+		return cmp.Or(
+			cmp.Compare(a.SubjectKind, b.SubjectKind),
+			cmp.Compare(a.SubjectID, b.SubjectID),
+			cmp.Compare(a.RoleID.String(), b.RoleID.String()),
+		)
+	})
+	members := make(map[string]int)
+	for _, a := range assignments {
+		if a.ExpiresAt != nil && a.ExpiresAt.Before(opts.Now) {
+			continue
+		}
+		members[a.RoleID.String()]++
+		used := subjectUse[a.SubjectKind+":"+a.SubjectID]
+		exercised := false
+		for _, rid := range e.resolveInheritedRoles(ctx, []id.RoleID{a.RoleID}) {
+			if _, ok := used[rid.String()]; ok {
+				exercised = true
+				break
+			}
+		}
+		if exercised {
+			continue
+		}
+		ua := UnusedAssignment{
+			AssignmentID:  a.ID,
+			RoleID:        a.RoleID,
+			NamespacePath: a.NamespacePath,
+			SubjectKind:   a.SubjectKind,
+			SubjectID:     a.SubjectID,
+		}
+		if r := byID[a.RoleID.String()]; r != nil {
+			ua.RoleSlug = r.Slug
+		}
+		rep.UnusedAssignments = append(rep.UnusedAssignments, ua)
+	}
+
+	// 4. Assigned roles whose grants exceed what their members used.
+	for _, r := range roles {
+		key := r.ID.String()
+		if members[key] == 0 || len(unused[key]) == 0 {
+			continue
+		}
+		used := make([]string, 0, len(granted[key])-len(unused[key]))
+		for _, name := range granted[key] {
+			if !slices.Contains(unused[key], name) {
+				used = append(used, name)
+			}
+		}
+		rep.OverPrivilegedRoles = append(rep.OverPrivilegedRoles, OverPrivilegedRole{
+			RoleID:        r.ID,
+			RoleSlug:      r.Slug,
+			NamespacePath: r.NamespacePath,
+			Members:       members[key],
+			Granted:       granted[key],
+			Used:          used,
+			Unused:        unused[key],
+		})
+	}
+
+	return rep, nil
+}
+
+func addUse(m map[string]map[string]struct{}, key, val string) {
+	set, ok := m[key]
+	if !ok {
+		set = make(map[string]struct{})
+		m[key] = set
+	}
+	set[val] = struct{}{}
+}
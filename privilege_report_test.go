@@ -0,0 +1,120 @@
+package warden
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/role"
+)
+
+func TestPrivilegeReport(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	viewerID, editorID := id.NewRoleID(), id.NewRoleID()
+	_ = s.CreateRole(ctx, &role.Role{ID: viewerID, TenantID: "t1", Name: "viewer", Slug: "viewer"})
+	_ = s.CreateRole(ctx, &role.Role{ID: editorID, TenantID: "t1", Name: "editor", Slug: "editor", ParentSlug: "viewer"})
+	for _, action := range []string{"read", "write", "delete"} {
+		_ = s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "doc:" + action, Resource: "doc", Action: action})
+	}
+	_ = s.AttachPermission(ctx, viewerID, permission.Ref{Name: "doc:read"})
+	_ = s.AttachPermission(ctx, editorID, permission.Ref{Name: "doc:write"})
+	_ = s.AttachPermission(ctx, editorID, permission.Ref{Name: "doc:delete"})
+	_ = s.CreateAssignment(ctx, &assignment.Assignment{TenantID: "t1", RoleID: editorID, SubjectKind: "user", SubjectID: "u1"})
+	_ = s.CreateAssignment(ctx, &assignment.Assignment{TenantID: "t1", RoleID: viewerID, SubjectKind: "user", SubjectID: "u2"})
+
+	// u1 reads (granted through the inherited viewer role) and writes.
+	for _, action := range []string{"read", "write"} {
+		if _, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: action},
+			Resource: Resource{Type: "doc", ID: "d1"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Check logs are written asynchronously.
+	for range 100 {
+		if n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"}); n == 2 { //nolint:errcheck // polled
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rep, err := eng.PrivilegeReport(ctx, PrivilegeReportOptions{Window: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.TenantID != "t1" || rep.Checks != 2 {
+		t.Fatalf("tenant/checks = %q/%d", rep.TenantID, rep.Checks)
+	}
+	if len(rep.UnusedPermissions) != 1 || rep.UnusedPermissions[0].RoleSlug != "editor" || rep.UnusedPermissions[0].Permission != "doc:delete" {
+		t.Errorf("UnusedPermissions = %+v", rep.UnusedPermissions)
+	}
+	if len(rep.UnusedAssignments) != 1 || rep.UnusedAssignments[0].SubjectID != "u2" || rep.UnusedAssignments[0].RoleSlug != "viewer" {
+		t.Errorf("UnusedAssignments = %+v", rep.UnusedAssignments)
+	}
+	if len(rep.OverPrivilegedRoles) != 1 {
+		t.Fatalf("OverPrivilegedRoles = %+v", rep.OverPrivilegedRoles)
+	}
+	op := rep.OverPrivilegedRoles[0]
+	if op.RoleSlug != "editor" || op.Members != 1 ||
+		!slices.Equal(op.Used, []string{"doc:write"}) || !slices.Equal(op.Unused, []string{"doc:delete"}) {
+		t.Errorf("OverPrivilegedRoles[0] = %+v", op)
+	}
+
+	// Outside the window nothing counts as used.
+	rep, err = eng.PrivilegeReport(ctx, PrivilegeReportOptions{Window: time.Hour, Now: time.Now().Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Checks != 0 || len(rep.UnusedPermissions) != 3 || len(rep.UnusedAssignments) != 2 {
+		t.Errorf("stale report = %+v", rep)
+	}
+}
+
+// TestPrivilegeReport_CreditsEveryGrantingRole pins that a check granted
+// by two of a subject's roles counts as usage of both.
+func TestPrivilegeReport_CreditsEveryGrantingRole(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	_ = s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "doc:read", Resource: "doc", Action: "read"})
+	for _, slug := range []string{"reader", "auditor"} {
+		rid := id.NewRoleID()
+		_ = s.CreateRole(ctx, &role.Role{ID: rid, TenantID: "t1", Name: slug, Slug: slug})
+		_ = s.AttachPermission(ctx, rid, permission.Ref{Name: "doc:read"})
+		_ = s.CreateAssignment(ctx, &assignment.Assignment{TenantID: "t1", RoleID: rid, SubjectKind: "user", SubjectID: "u1"})
+	}
+	res, err := eng.Check(ctx, &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "read"},
+		Resource: Resource{Type: "doc", ID: "d1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.MatchedBy) != 2 || res.MatchedBy[0].Permission != "doc:read" {
+		t.Fatalf("MatchedBy = %+v", res.MatchedBy)
+	}
+	for range 100 {
+		if n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"}); n == 1 { //nolint:errcheck // polled
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rep, err := eng.PrivilegeReport(ctx, PrivilegeReportOptions{Window: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.UnusedPermissions) != 0 || len(rep.UnusedAssignments) != 0 {
+		t.Errorf("both roles should count as used: %+v", rep)
+	}
+}
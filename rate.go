@@ -0,0 +1,174 @@
+package warden
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/xraph/go-utils/log"
+
+	"github.com/xraph/warden/counter"
+	"github.com/xraph/warden/policy"
+)
+
+// counterPurgeInterval is how often increments purge buckets that have
+// fallen out of counter.MaxWindow.
+const counterPurgeInterval = time.Minute
+
+// IncrementRate counts n events of action by subject in tenantID. Rate
+// conditions see them on the next check.
+func (e *Engine) IncrementRate(ctx context.Context, tenantID string, subject Subject, action string, n int64) error {
+	if n <= 0 {
+		return nil
+	}
+	now := time.Now()
+	key := rateKey(tenantID, subject, action)
+	if err := e.counters.IncrementCounter(ctx, key, counter.Bucket(now), n); err != nil {
+		return fmt.Errorf("warden: increment rate: %w", err)
+	}
+	e.purgeCounters(ctx, now)
+	return nil
+}
+
+// Rate returns the number of events of action by subject in tenantID
+// within the trailing window.
+func (e *Engine) Rate(ctx context.Context, tenantID string, subject Subject, action string, window time.Duration) (int64, error) {
+	if err := counter.ValidateWindow(window); err != nil {
+		return 0, fmt.Errorf("warden: rate: %w", err)
+	}
+	return e.counters.SumCounter(ctx, rateKey(tenantID, subject, action), counter.Since(time.Now(), window))
+}
+
+func rateKey(tenantID string, subject Subject, action string) counter.Key {
+	return counter.Key{
+		TenantID:    tenantID,
+		SubjectKind: string(subject.Kind),
+		SubjectID:   subject.ID,
+		Action:      action,
+	}
+}
+
+// purgeCounters drops expired buckets at most once per
+// counterPurgeInterval.
+func (e *Engine) purgeCounters(ctx context.Context, now time.Time) {
+	last := e.countersPurgedAt.Load()
+	if now.UnixNano()-last < int64(counterPurgeInterval) || !e.countersPurgedAt.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	if _, err := e.counters.PurgeCounters(ctx, now.Add(-counter.MaxWindow)); err != nil {
+		e.logger.Error("warden: failed to purge rate counters", log.Error(err))
+	}
+}
+
+// incrementRates fulfils the increment_rate obligations of a final result.
+// An obligation's "action" param overrides the counted action and its
+// "count" param the number of events.
+func (e *Engine) incrementRates(ctx context.Context, scope tenantScope, req *CheckRequest, result *CheckResult) {
+	for _, ob := range result.StructuredObligations {
+		if ob.Name != policy.ObligationIncrementRate {
+			continue
+		}
+		action := req.Action.Name
+		if a, ok := ob.Params["action"].(string); ok && a != "" {
+			action = a
+		}
+		n := int64(1)
+		if c, ok := ob.Params["count"]; ok {
+			n = int64(toFloat64(c))
+		}
+		if err := e.IncrementRate(ctx, scope.tenantID, req.Subject, action, n); err != nil {
+			e.logger.Error("warden: failed to fulfil increment_rate",
+				log.String("policy_id", ob.PolicyID),
+				log.Error(err),
+			)
+		}
+	}
+}
+
+// incrementsRate reports whether result carries an increment_rate
+// obligation, which must be fulfilled on every check and so must not be
+// served from the cache.
+func incrementsRate(result *CheckResult) bool {
+	for _, ob := range result.StructuredObligations {
+		if ob.Name == policy.ObligationIncrementRate {
+			return true
+		}
+	}
+	return false
+}
+
+// rateSource reads the counters of one tenant for a single check. It
+// remembers every count it read, both so a rate is read from the store at
+// most once per check and so a result that depends on a counter is not
+// cached.
+type rateSource struct {
+	counters counter.Store
+	tenantID string
+
+	mu     sync.Mutex
+	counts map[counter.Key]map[time.Duration]int64
+}
+
+// withRates attaches the counters of tenantID to ctx so rate conditions
+// can reach them.
+func (e *Engine) withRates(ctx context.Context, tenantID string) (context.Context, *rateSource) {
+	if e.counters == nil {
+		return ctx, nil
+	}
+	src := &rateSource{
+		counters: e.counters,
+		tenantID: tenantID,
+		counts:   make(map[counter.Key]map[time.Duration]int64),
+	}
+	return context.WithValue(ctx, ctxKeyRates, src), src
+}
+
+// used reports whether any rate was read.
+func (s *rateSource) used() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.counts) > 0
+}
+
+// evaluateRate returns the value of a rate(subject, action, window) field
+// at now.
+func evaluateRate(ctx context.Context, ref policy.RateRef, req *CheckRequest, now time.Time) (int64, error) {
+	s, ok := ctx.Value(ctxKeyRates).(*rateSource)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s: no rate counters", ErrInvalidCondition, ref)
+	}
+	action := ref.Action
+	if action == "" {
+		action = req.Action.Name
+	}
+	key := rateKey(s.tenantID, req.Subject, action)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n, ok := s.counts[key][ref.Window]; ok {
+		return n, nil
+	}
+	n, err := s.counters.SumCounter(ctx, key, counter.Since(now, ref.Window))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", ErrInvalidCondition, ref, err)
+	}
+	if s.counts[key] == nil {
+		s.counts[key] = make(map[time.Duration]int64)
+	}
+	s.counts[key][ref.Window] = n
+	return n, nil
+}
+
+// readOnlyCounters reads through to a counter store and drops writes, so a
+// simulation sees live counts without recording events.
+type readOnlyCounters struct{ counter.Store }
+
+func (readOnlyCounters) IncrementCounter(context.Context, counter.Key, time.Time, int64) error {
+	return nil
+}
+
+func (readOnlyCounters) PurgeCounters(context.Context, time.Time) (int64, error) { return 0, nil }
@@ -0,0 +1,148 @@
+package warden
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/store/memory"
+)
+
+// recordingCache is a Cache that counts the results it is asked to store.
+type recordingCache struct {
+	mu   sync.Mutex
+	sets int
+}
+
+func (c *recordingCache) Get(context.Context, string, *CheckRequest) (*CheckResult, bool) {
+	return nil, false
+}
+
+func (c *recordingCache) Set(context.Context, string, *CheckRequest, *CheckResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sets++
+}
+
+func (c *recordingCache) InvalidateTenant(context.Context, string) {}
+
+func (c *recordingCache) InvalidateSubject(context.Context, string, SubjectKind, string) {}
+
+// TestEngine_RateConditions counts allowed exports with an increment_rate
+// obligation and denies once the subject reaches the limit.
+func TestEngine_RateConditions(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	for _, pol := range []*policy.Policy{
+		{
+			TenantID: "t1", Name: "allow-export", Effect: policy.EffectAllow, IsActive: true,
+			Actions: []string{"export"}, Resources: []string{"report:*"},
+			StructuredObligations: []policy.Obligation{
+				{Name: policy.ObligationIncrementRate, FulfillOn: policy.FulfillOnAllow},
+			},
+		},
+		{
+			TenantID: "t1", Name: "export-velocity", Effect: policy.EffectDeny, IsActive: true,
+			Actions: []string{"export"}, Resources: []string{"report:*"},
+			Conditions: []policy.Condition{
+				{Field: "rate(subject, export, 1h)", Operator: policy.OpGTE, Value: 3},
+			},
+		},
+	} {
+		if err := s.CreatePolicy(ctx, pol); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &recordingCache{}
+	eng, err := NewEngine(WithStore(s), WithCache(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	export := func(subjectID string) bool {
+		t.Helper()
+		res, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: subjectID},
+			Action:   Action{Name: "export"},
+			Resource: Resource{Type: "report", ID: "r1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Allowed
+	}
+	for i, want := range []bool{true, true, true, false, false} {
+		if got := export("u1"); got != want {
+			t.Fatalf("export %d: allowed = %v, want %v", i+1, got, want)
+		}
+	}
+	if !export("u2") {
+		t.Error("another subject must have its own counter")
+	}
+
+	// Denied exports are not counted: the obligation is allow-only.
+	n, err := eng.Rate(ctx, "t1", Subject{Kind: SubjectUser, ID: "u1"}, "export", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("Rate = %d, want 3", n)
+	}
+	if c.sets != 0 {
+		t.Errorf("%d results depending on a rate counter were cached", c.sets)
+	}
+}
+
+// TestEngine_RateWithoutIncrements checks rate(subject, action, ...) against
+// events recorded with IncrementRate, and that unrelated results are still
+// cached.
+func TestEngine_RateWithoutIncrements(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	if err := s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "step-up", Effect: policy.EffectDeny, IsActive: true,
+		Actions: []string{"read", "write"}, Resources: []string{"document:*"},
+		Conditions: []policy.Condition{
+			{Field: "rate(subject, action, 1m)", Operator: policy.OpGreaterThan, Value: 10},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	c := &recordingCache{}
+	eng, err := NewEngine(WithStore(s), WithCache(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject := Subject{Kind: SubjectUser, ID: "u1"}
+	if err := eng.IncrementRate(ctx, "t1", subject, "write", 11); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(action string) *CheckResult {
+		t.Helper()
+		res, err := eng.Check(ctx, &CheckRequest{
+			Subject:  subject,
+			Action:   Action{Name: action},
+			Resource: Resource{Type: "document", ID: "d1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	if res := check("write"); res.Decision != DecisionDenyExplicit {
+		t.Errorf("write decision = %s, want %s", res.Decision, DecisionDenyExplicit)
+	}
+	if res := check("read"); res.Decision == DecisionDenyExplicit {
+		t.Error("read is counted separately and must not be denied")
+	}
+	if c.sets != 0 {
+		t.Errorf("%d results depending on a rate counter were cached", c.sets)
+	}
+	check("delete")
+	if c.sets != 1 {
+		t.Errorf("a result that reads no counter must be cached, got %d sets", c.sets)
+	}
+}
@@ -0,0 +1,142 @@
+package resourcetype
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// AttributeType is the declared type of an attribute.
+type AttributeType string
+
+const (
+	// AttrString is a string.
+	AttrString AttributeType = "string"
+
+	// AttrInt is a whole number.
+	AttrInt AttributeType = "int"
+
+	// AttrFloat is a number.
+	AttrFloat AttributeType = "float"
+
+	// AttrBool is true or false.
+	AttrBool AttributeType = "bool"
+
+	// AttrTimestamp is an RFC3339 instant.
+	AttrTimestamp AttributeType = "timestamp"
+
+	// AttrDuration is a Go duration such as "90m".
+	AttrDuration AttributeType = "duration"
+
+	// AttrList is a list of values.
+	AttrList AttributeType = "list"
+
+	// AttrMap is a map with string keys.
+	AttrMap AttributeType = "map"
+)
+
+// Valid reports whether t is a known attribute type.
+func (t AttributeType) Valid() bool {
+	switch t {
+	case AttrString, AttrInt, AttrFloat, AttrBool, AttrTimestamp, AttrDuration, AttrList, AttrMap:
+		return true
+	}
+	return false
+}
+
+// AttributeDef declares one attribute in a resource type's schema.
+type AttributeDef struct {
+	Name     string        `json:"name"`
+	Type     AttributeType `json:"type"`
+	Required bool          `json:"required,omitempty"`
+
+	// Enum, when set, lists the allowed values, compared as strings.
+	Enum []string `json:"enum,omitempty"`
+}
+
+// Coerce converts v to the declared type, returning an error when it
+// cannot. Numeric, boolean, timestamp and duration values arriving as
+// strings are parsed; an int accepts a whole float such as a JSON number.
+func (d AttributeDef) Coerce(v any) (any, error) {
+	out, ok := coerce(d.Type, v)
+	if !ok {
+		return nil, fmt.Errorf("attribute %q: %v (%T) is not of type %s", d.Name, v, v, d.Type)
+	}
+	if len(d.Enum) > 0 && !slices.Contains(d.Enum, fmt.Sprint(out)) {
+		return nil, fmt.Errorf("attribute %q: %v is not one of %v", d.Name, out, d.Enum)
+	}
+	return out, nil
+}
+
+func coerce(t AttributeType, v any) (any, bool) {
+	rv := reflect.ValueOf(v)
+	switch t {
+	case AttrString:
+		s, ok := v.(string)
+		return s, ok
+	case AttrInt:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return rv.Int(), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int64(rv.Uint()), true
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			if f != math.Trunc(f) {
+				return nil, false
+			}
+			return int64(f), true
+		case reflect.String:
+			n, err := strconv.ParseInt(rv.String(), 10, 64)
+			return n, err == nil
+		}
+	case AttrFloat:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(rv.Int()), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(rv.Uint()), true
+		case reflect.Float32, reflect.Float64:
+			return rv.Float(), true
+		case reflect.String:
+			f, err := strconv.ParseFloat(rv.String(), 64)
+			return f, err == nil
+		}
+	case AttrBool:
+		switch x := v.(type) {
+		case bool:
+			return x, true
+		case string:
+			b, err := strconv.ParseBool(x)
+			return b, err == nil
+		}
+	case AttrTimestamp:
+		switch x := v.(type) {
+		case time.Time:
+			return x, true
+		case string:
+			ts, err := time.Parse(time.RFC3339, x)
+			return ts, err == nil
+		}
+	case AttrDuration:
+		switch x := v.(type) {
+		case time.Duration:
+			return x, true
+		case string:
+			d, err := time.ParseDuration(x)
+			return d, err == nil
+		}
+	case AttrList:
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			return v, true
+		}
+	case AttrMap:
+		if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+			return v, true
+		}
+	}
+	return nil, false
+}
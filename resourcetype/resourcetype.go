@@ -13,6 +13,10 @@ import (
 // NamespacePath locates the resource type within the tenant's namespace
 // tree. Resource types defined at an ancestor namespace are visible from
 // descendants.
+//
+// Attributes declares the ABAC attribute schema of resources of this type
+// and of subjects whose kind equals Name, so a `user` resource type also
+// describes `user` subjects.
 type ResourceType struct {
 	ID            id.ResourceTypeID `json:"id" db:"id"`
 	TenantID      string            `json:"tenant_id" db:"tenant_id"`
@@ -22,11 +26,18 @@ type ResourceType struct {
 	Description   string            `json:"description,omitempty" db:"description"`
 	Relations     []RelationDef     `json:"relations" db:"-"`
 	Permissions   []PermissionDef   `json:"permissions" db:"-"`
+	Attributes    []AttributeDef    `json:"attributes,omitempty" db:"-"`
 	Metadata      map[string]any    `json:"metadata,omitempty" db:"metadata"`
 	CreatedAt     time.Time         `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time         `json:"updated_at" db:"updated_at"`
 }
 
+// ParentRelation is the relation that places a resource in a hierarchy:
+// the tuple document:d1#parent@project:p1 makes project p1 the parent of
+// document d1. ABAC conditions read the parent's attributes as
+// resource.parent.<attr> and the whole chain as resource.ancestors.
+const ParentRelation = "parent"
+
 // RelationDef defines a valid relation for a resource type.
 type RelationDef struct {
 	Name            string   `json:"name"`
@@ -39,6 +50,16 @@ type PermissionDef struct {
 	Expression string `json:"expression"` // e.g., "viewer or editor or owner"
 }
 
+// Attribute returns the declared attribute named name.
+func (rt *ResourceType) Attribute(name string) (AttributeDef, bool) {
+	for _, a := range rt.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return AttributeDef{}, false
+}
+
 // ListFilter contains filters for listing resource types.
 type ListFilter struct {
 	TenantID        string  `json:"tenant_id,omitempty"`
@@ -0,0 +1,125 @@
+package warden
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xraph/warden/calendar"
+	"github.com/xraph/warden/policy"
+)
+
+// evaluateSchedule evaluates a day_of_week, time_of_day, schedule or
+// calendar condition. The time is the attribute named by the condition's
+// field, or the evaluation clock when the field is "now" or the attribute
+// is absent. A time that does not parse does not match.
+func evaluateSchedule(ctx context.Context, c policy.Condition, req *CheckRequest, now time.Time) (bool, error) {
+	cals, _ := ctx.Value(ctxKeyCalendars).(*calendarSource) //nolint:errcheck // absent → nil
+	cals.markUsed()
+	at := now
+	if c.Field != "now" {
+		v, err := LookupAttribute(ctx, c.Field, req)
+		if err != nil {
+			return false, err
+		}
+		if v != nil {
+			t, ok := parseTime(v)
+			if !ok {
+				return false, nil
+			}
+			at = t
+		}
+	}
+	if c.ValueRef != "" {
+		v, err := LookupAttribute(ctx, c.ValueRef, req)
+		if err != nil {
+			return false, err
+		}
+		if c.Value = v; c.Value == nil {
+			return false, nil
+		}
+		c.ValueRef = ""
+	}
+
+	m, loc, err := c.Schedule()
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidCondition, err)
+	}
+	if m != nil {
+		return m.Matches(at.In(loc)), nil
+	}
+
+	name, _ := c.Value.(string) //nolint:errcheck // checked by Schedule
+	if cals == nil {
+		return false, fmt.Errorf("%w: calendar %q: no calendar store", ErrInvalidCondition, name)
+	}
+	cal, err := cals.get(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	in, err := cal.Contains(at, loc)
+	if err != nil {
+		return false, fmt.Errorf("%w: calendar %q: %w", ErrInvalidCondition, name, err)
+	}
+	return in == (c.Operator == policy.OpInCalendar), nil
+}
+
+// calendarSource looks up the named calendars of one tenant for a single
+// check, reading each calendar from the store at most once. It also
+// records whether any schedule or calendar condition was evaluated, so a
+// result that depends on the time is not cached.
+type calendarSource struct {
+	store    calendar.Store
+	tenantID string
+
+	mu        sync.Mutex
+	calendars map[string]*calendar.Calendar
+	evaluated bool
+}
+
+// withCalendars attaches the calendars of tenantID to ctx so calendar
+// conditions can reach them.
+func (e *Engine) withCalendars(ctx context.Context, tenantID string) (context.Context, *calendarSource) {
+	src := &calendarSource{
+		store:     e.store,
+		tenantID:  tenantID,
+		calendars: make(map[string]*calendar.Calendar),
+	}
+	return context.WithValue(ctx, ctxKeyCalendars, src), src
+}
+
+func (s *calendarSource) markUsed() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.evaluated = true
+	s.mu.Unlock()
+}
+
+// used reports whether any schedule or calendar condition was evaluated.
+func (s *calendarSource) used() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evaluated
+}
+
+func (s *calendarSource) get(ctx context.Context, name string) (*calendar.Calendar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cal, ok := s.calendars[name]; ok {
+		return cal, nil
+	}
+	cal, err := s.store.GetCalendarByName(ctx, s.tenantID, name)
+	if err != nil {
+		// An unknown calendar makes the condition unevaluable, so the
+		// policy's on_error behaviour applies.
+		return nil, fmt.Errorf("%w: calendar %q: %w", ErrInvalidCondition, name, err)
+	}
+	s.calendars[name] = cal
+	return cal, nil
+}
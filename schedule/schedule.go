@@ -0,0 +1,297 @@
+// Package schedule parses and matches the recurring time specifications
+// used by policy schedule conditions: days of the week, time-of-day ranges
+// and five-field cron expressions.
+//
+// Matching is done on wall-clock time: callers convert an instant to the
+// schedule's time zone (see LoadLocation) before calling Matches.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Matcher reports whether a wall-clock time falls within a schedule.
+type Matcher interface {
+	Matches(t time.Time) bool
+}
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// Weekdays is a set of days of the week.
+type Weekdays uint8
+
+// ParseWeekdays parses a day list such as "mon-fri", "sat,sun" or a list
+// of such strings. Days are spelled by their three-letter English names;
+// a range may wrap around the week ("fri-mon").
+func ParseWeekdays(v any) (Weekdays, error) {
+	items, err := stringItems(v)
+	if err != nil {
+		return 0, fmt.Errorf("days: %w", err)
+	}
+	var w Weekdays
+	for _, item := range items {
+		for _, part := range strings.Split(item, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			from, to, isRange := strings.Cut(part, "-")
+			lo, ok := dayNames[from]
+			if !ok {
+				return 0, fmt.Errorf("unknown day %q", from)
+			}
+			hi := lo
+			if isRange {
+				if hi, ok = dayNames[to]; !ok {
+					return 0, fmt.Errorf("unknown day %q", to)
+				}
+			}
+			for d := lo; ; d = (d + 1) % 7 {
+				w |= 1 << d
+				if d == hi {
+					break
+				}
+			}
+		}
+	}
+	if w == 0 {
+		return 0, errors.New("days: no days given")
+	}
+	return w, nil
+}
+
+// Matches reports whether t falls on one of the days.
+func (w Weekdays) Matches(t time.Time) bool { return w&(1<<t.Weekday()) != 0 }
+
+// TimeRanges is a set of daily time-of-day ranges.
+type TimeRanges []timeRange
+
+// timeRange spans [from, to) in minutes since midnight. A range whose end
+// is not after its start wraps past midnight.
+type timeRange struct{ from, to int }
+
+// ParseTimeRanges parses "HH:MM-HH:MM" or a list of such ranges. The end
+// is exclusive; "24:00" ends a range at midnight and "22:00-06:00" spans
+// midnight.
+func ParseTimeRanges(v any) (TimeRanges, error) {
+	items, err := stringItems(v)
+	if err != nil {
+		return nil, fmt.Errorf("time range: %w", err)
+	}
+	out := make(TimeRanges, 0, len(items))
+	for _, item := range items {
+		from, to, ok := strings.Cut(strings.TrimSpace(item), "-")
+		if !ok {
+			return nil, fmt.Errorf("time range %q: want HH:MM-HH:MM", item)
+		}
+		lo, err := parseClock(from)
+		if err != nil {
+			return nil, fmt.Errorf("time range %q: %w", item, err)
+		}
+		hi, err := parseClock(to)
+		if err != nil {
+			return nil, fmt.Errorf("time range %q: %w", item, err)
+		}
+		if lo == 24*60 {
+			return nil, fmt.Errorf("time range %q: a range cannot start at 24:00", item)
+		}
+		out = append(out, timeRange{lo, hi})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("time range: no ranges given")
+	}
+	return out, nil
+}
+
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	h, errH := strconv.Atoi(hh)
+	m, errM := strconv.Atoi(mm)
+	if !ok || errH != nil || errM != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// Matches reports whether the time of day of t is within any range.
+func (r TimeRanges) Matches(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	for _, tr := range r {
+		if tr.from < tr.to {
+			if m >= tr.from && m < tr.to {
+				return true
+			}
+		} else if m >= tr.from || m < tr.to {
+			return true
+		}
+	}
+	return false
+}
+
+// Cron is a five-field cron expression: minute, hour, day of month, month
+// and day of week. It matches the minutes the expression would fire in.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field. As in cron, when both day
+	// fields are restricted a time matches if either does.
+	domAny, dowAny bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, monthNames},
+	{"day of week", 0, 7, weekdayNumbers()},
+}
+
+func weekdayNumbers() map[string]int {
+	out := make(map[string]int, len(dayNames))
+	for name, d := range dayNames {
+		out[name] = int(d)
+	}
+	return out
+}
+
+// ParseCron parses a five-field cron expression such as "*/15 9-16 * *
+// mon-fri". Fields accept "*", numbers, names (jan-dec, sun-sat), ranges,
+// lists and "/step"; day of week 7 is Sunday.
+func ParseCron(s string) (*Cron, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields, got %d", s, len(fields))
+	}
+	var c Cron
+	sets := [5]*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, f := range fields {
+		set, err := cronFields[i].parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", s, err)
+		}
+		*sets[i] = set
+	}
+	c.domAny, c.dowAny = fields[2] == "*", fields[4] == "*"
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is an alias for Sunday
+	}
+	return &c, nil
+}
+
+func (f cronField) parse(s string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepStr)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(from); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(to); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("%s: range %q is backwards", f.name, rng)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	return v, nil
+}
+
+// Matches reports whether the expression fires in the minute of t.
+func (c *Cron) Matches(t time.Time) bool {
+	if c.minute&(1<<t.Minute()) == 0 || c.hour&(1<<t.Hour()) == 0 || c.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+var locations sync.Map // name → *time.Location
+
+// LoadLocation returns the IANA time zone with the given name, or UTC for
+// an empty name. Zones are loaded once and cached.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// stringItems accepts a string or a list of strings, as decoded from JSON
+// or the DSL.
+func stringItems(v any) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("want strings, got %T", item)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("want a string or a list of strings, got %T", v)
+}
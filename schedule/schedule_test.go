@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// 2026-06-01 is a Monday.
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 6, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestWeekdays(t *testing.T) {
+	tests := []struct {
+		spec any
+		in   []int // June 2026 days that match
+		out  []int
+	}{
+		{"mon-fri", []int{1, 2, 5}, []int{6, 7}},
+		{"sat,sun", []int{6, 7}, []int{1, 5}},
+		{"fri-mon", []int{5, 6, 7, 1}, []int{2, 4}},
+		{[]any{"mon", "wed"}, []int{1, 3}, []int{2}},
+	}
+	for _, tt := range tests {
+		w, err := ParseWeekdays(tt.spec)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.spec, err)
+		}
+		for _, d := range tt.in {
+			if !w.Matches(at(d, 12, 0)) {
+				t.Errorf("%v: June %d should match", tt.spec, d)
+			}
+		}
+		for _, d := range tt.out {
+			if w.Matches(at(d, 12, 0)) {
+				t.Errorf("%v: June %d should not match", tt.spec, d)
+			}
+		}
+	}
+	for _, bad := range []any{"", "monday", "mon-xyz", 3} {
+		if _, err := ParseWeekdays(bad); err == nil {
+			t.Errorf("ParseWeekdays(%v): expected error", bad)
+		}
+	}
+}
+
+func TestTimeRanges(t *testing.T) {
+	tests := []struct {
+		spec    any
+		h, m    int
+		matches bool
+	}{
+		{"09:00-17:00", 9, 0, true},
+		{"09:00-17:00", 16, 59, true},
+		{"09:00-17:00", 17, 0, false},
+		{"09:00-17:00", 8, 59, false},
+		{"22:00-06:00", 23, 30, true},
+		{"22:00-06:00", 5, 59, true},
+		{"22:00-06:00", 12, 0, false},
+		{"18:00-24:00", 23, 59, true},
+		{[]string{"08:00-12:00", "13:00-17:00"}, 12, 30, false},
+		{[]string{"08:00-12:00", "13:00-17:00"}, 13, 30, true},
+	}
+	for _, tt := range tests {
+		r, err := ParseTimeRanges(tt.spec)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.spec, err)
+		}
+		if got := r.Matches(at(1, tt.h, tt.m)); got != tt.matches {
+			t.Errorf("%v at %02d:%02d = %v, want %v", tt.spec, tt.h, tt.m, got, tt.matches)
+		}
+	}
+	for _, bad := range []string{"9-17", "09:00", "25:00-26:00", "09:60-10:00", "24:00-01:00"} {
+		if _, err := ParseTimeRanges(bad); err == nil {
+			t.Errorf("ParseTimeRanges(%q): expected error", bad)
+		}
+	}
+}
+
+func TestCron(t *testing.T) {
+	tests := []struct {
+		spec    string
+		t       time.Time
+		matches bool
+	}{
+		{"* 9-16 * * mon-fri", at(1, 9, 30), true},
+		{"* 9-16 * * mon-fri", at(1, 17, 0), false},
+		{"* 9-16 * * mon-fri", at(6, 10, 0), false},
+		{"*/15 * * * *", at(1, 10, 45), true},
+		{"*/15 * * * *", at(1, 10, 46), false},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"* * * * 7", at(7, 0, 0), true},
+		// Both day fields restricted: either matches.
+		{"* * 15 * mon", at(1, 0, 0), true},
+		{"* * 15 * mon", at(15, 0, 0), true},
+		{"* * 15 * mon", at(16, 0, 0), false},
+		// Only day of month restricted.
+		{"* * 15 * *", at(1, 0, 0), false},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.spec, err)
+		}
+		if got := c.Matches(tt.t); got != tt.matches {
+			t.Errorf("%q at %s = %v, want %v", tt.spec, tt.t, got, tt.matches)
+		}
+	}
+	for _, tc := range []struct{ spec, want string }{
+		{"* * * *", "want 5 fields"},
+		{"60 * * * *", "minute: invalid value"},
+		{"* 17-9 * * *", "backwards"},
+		{"*/0 * * * *", "invalid step"},
+		{"* * * foo *", "month: invalid value"},
+	} {
+		_, err := ParseCron(tc.spec)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseCron(%q) = %v, want %q", tc.spec, err, tc.want)
+		}
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc, err := LoadLocation(""); err != nil || loc != time.UTC {
+		t.Errorf("LoadLocation(\"\") = %v, %v", loc, err)
+	}
+	if _, err := LoadLocation("Europe/Berlin"); err != nil {
+		t.Error(err)
+	}
+	if _, err := LoadLocation("Mars/Olympus"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,180 @@
+package warden
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/calendar"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/store/memory"
+)
+
+// TestEngine_ScheduleConditions checks schedule operators against the
+// evaluator clock and against context.time, in the condition's zone.
+func TestEngine_ScheduleConditions(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	if err := s.CreateCalendar(ctx, &calendar.Calendar{
+		TenantID: "t1", Name: "holidays", TimeZone: "Europe/Berlin",
+		Dates: []calendar.Date{{Date: "12-25", Name: "Christmas"}, {Date: "2026-04-03"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "office-hours", Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"read"}, Resources: []string{"document:*"},
+		Conditions: []policy.Condition{
+			{Field: "context.time", Operator: policy.OpDayOfWeek, Value: "mon-fri", TimeZone: "Europe/Berlin"},
+			{Field: "context.time", Operator: policy.OpTimeOfDay, Value: "09:00-17:00", TimeZone: "Europe/Berlin"},
+			{Field: "context.time", Operator: policy.OpNotInCalendar, Value: "holidays"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Monday 2026-03-02, 08:30 UTC = 09:30 in Berlin.
+	clock := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+	eng, err := NewEngine(WithStore(s), WithEvaluator(NewConditionEvaluator(func() time.Time { return clock })))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		at   any
+		want bool
+	}{
+		{"evaluator clock", nil, true},
+		{"before opening in Berlin", "2026-03-02T07:30:00Z", false},
+		{"closing time is exclusive", "2026-03-02T16:00:00Z", false},
+		{"saturday", "2026-03-07T10:00:00Z", false},
+		{"annual holiday", "2025-12-25T10:00:00Z", false},
+		{"one-off holiday", "2026-04-03T10:00:00Z", false},
+		{"time value", time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC), true},
+		{"unparseable time", "tuesday", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CheckRequest{
+				Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+				Action:   Action{Name: "read"},
+				Resource: Resource{Type: "document", ID: "d1"},
+			}
+			if tt.at != nil {
+				req.Context = map[string]any{"time": tt.at}
+			}
+			res, err := eng.Check(ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Allowed != tt.want {
+				t.Fatalf("allowed = %v, want %v (%s)", res.Allowed, tt.want, res.Reason)
+			}
+		})
+	}
+}
+
+// TestEngine_ScheduleResultsNotCached verifies that a decision that
+// evaluated a schedule or calendar condition is not cached, since it
+// changes when a window opens or closes.
+func TestEngine_ScheduleResultsNotCached(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	for _, pol := range []*policy.Policy{
+		{
+			TenantID: "t1", Name: "office-hours", Effect: policy.EffectAllow, IsActive: true,
+			Actions: []string{"read"},
+			Conditions: []policy.Condition{
+				{Field: "now", Operator: policy.OpTimeOfDay, Value: "09:00-17:00"},
+			},
+		},
+		{
+			TenantID: "t1", Name: "no-holiday-writes", Effect: policy.EffectDeny, IsActive: true,
+			Actions: []string{"write"},
+			Conditions: []policy.Condition{
+				{Field: "now", Operator: policy.OpInCalendar, Value: "holidays"},
+			},
+		},
+		{
+			TenantID: "t1", Name: "allow-list", Effect: policy.EffectAllow, IsActive: true,
+			Actions: []string{"list"},
+		},
+	} {
+		if err := s.CreatePolicy(ctx, pol); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.CreateCalendar(ctx, &calendar.Calendar{
+		TenantID: "t1", Name: "holidays", Dates: []calendar.Date{{Date: "12-25"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cache := &recordingCache{}
+	eng, err := NewEngine(WithStore(s), WithCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range []string{"read", "write"} {
+		if _, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: action},
+			Resource: Resource{Type: "document", ID: "d1"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cache.sets != 0 {
+		t.Errorf("cache sets = %d, want 0 for schedule and calendar results", cache.sets)
+	}
+
+	if _, err := eng.Check(ctx, &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+		Action:   Action{Name: "list"},
+		Resource: Resource{Type: "document", ID: "d1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if cache.sets != 1 {
+		t.Errorf("cache sets = %d, want 1 for a result without schedules", cache.sets)
+	}
+}
+
+func TestEvaluator_ScheduleOperators(t *testing.T) {
+	ctx := context.Background()
+	// Wednesday 2026-03-04 23:30 UTC is Thursday 00:30 in Berlin.
+	now := time.Date(2026, 3, 4, 23, 30, 0, 0, time.UTC)
+	ev := &conditionEvaluator{now: func() time.Time { return now }}
+	req := &CheckRequest{}
+
+	tests := []struct {
+		name string
+		cond policy.Condition
+		want bool
+	}{
+		{"day in UTC", policy.Condition{Field: "now", Operator: policy.OpDayOfWeek, Value: "wed"}, true},
+		{"day in zone", policy.Condition{Field: "now", Operator: policy.OpDayOfWeek, Value: []any{"thu"}, TimeZone: "Europe/Berlin"}, true},
+		{"overnight range", policy.Condition{Field: "now", Operator: policy.OpTimeOfDay, Value: "22:00-06:00"}, true},
+		{"cron", policy.Condition{Field: "now", Operator: policy.OpSchedule, Value: "*/15 23 * * wed"}, true},
+		{"cron in zone", policy.Condition{Field: "now", Operator: policy.OpSchedule, Value: "* 0 * * thu", TimeZone: "Europe/Berlin"}, true},
+		{"cron miss", policy.Condition{Field: "now", Operator: policy.OpSchedule, Value: "0 9 * * 1-5"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ev.evaluateConditions(ctx, []policy.Condition{tt.cond}, req, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// A calendar condition without a calendar store cannot be evaluated.
+	cond := policy.Condition{Field: "now", Operator: policy.OpInCalendar, Value: "holidays"}
+	if _, err := ev.evaluateConditions(ctx, []policy.Condition{cond}, req, now); err == nil {
+		t.Error("expected an error for an unreachable calendar")
+	}
+}
@@ -0,0 +1,51 @@
+package warden
+
+import (
+	"github.com/xraph/warden/policy"
+)
+
+// ShadowDecision records what a policy in shadow mode would have done to a
+// check had it been enforced. Only decisions that differ from the real
+// outcome are reported on CheckResult.Shadow.
+type ShadowDecision struct {
+	PolicyID   string        `json:"policy_id"`
+	PolicyName string        `json:"policy_name"`
+	Effect     policy.Effect `json:"effect"`
+
+	// Allowed and Decision are the would-be outcome of the check.
+	Allowed  bool     `json:"allowed"`
+	Decision Decision `json:"decision"`
+}
+
+// newShadowDecision returns the would-be decision of a matched shadow
+// policy, before it is compared with the real outcome.
+func newShadowDecision(pol *policy.Policy) ShadowDecision {
+	sd := ShadowDecision{
+		PolicyID:   pol.ID.String(),
+		PolicyName: pol.Name,
+		Effect:     pol.Effect,
+		Decision:   DecisionDenyExplicit,
+	}
+	if pol.Effect != policy.EffectDeny {
+		sd.Allowed, sd.Decision = true, DecisionAllow
+	}
+	return sd
+}
+
+// divergentShadow keeps the shadow decisions that would have changed the
+// final result: a shadow deny diverges from an allow, and a shadow allow
+// diverges from a deny that no enforced policy denied explicitly.
+func divergentShadow(final *CheckResult, candidates []ShadowDecision) []ShadowDecision {
+	var out []ShadowDecision
+	for _, sd := range candidates {
+		if sd.Allowed {
+			if final.Allowed || final.Decision == DecisionDenyExplicit {
+				continue
+			}
+		} else if !final.Allowed {
+			continue
+		}
+		out = append(out, sd)
+	}
+	return out
+}
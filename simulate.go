@@ -0,0 +1,514 @@
+package warden
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/calendar"
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/relation"
+	"github.com/xraph/warden/resourcetype"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/store/memory"
+)
+
+// DefaultSimulationLimit caps the number of recorded checks Simulate
+// replays when the query sets no Limit.
+const DefaultSimulationLimit = 10000
+
+// SimulationChanges is a proposed change to the authorization model.
+// Entities in the upsert lists replace the stored entity with the same ID
+// and are created otherwise. Deletions run first, then upserts, grants and
+// revokes, then Apply.
+type SimulationChanges struct {
+	Roles             []*role.Role                 `json:"roles,omitempty"`
+	DeleteRoles       []id.RoleID                  `json:"delete_roles,omitempty"`
+	Permissions       []*permission.Permission     `json:"permissions,omitempty"`
+	Grants            []RoleGrant                  `json:"grants,omitempty"`
+	Revokes           []RoleGrant                  `json:"revokes,omitempty"`
+	Assignments       []*assignment.Assignment     `json:"assignments,omitempty"`
+	DeleteAssignments []id.AssignmentID            `json:"delete_assignments,omitempty"`
+	Policies          []*policy.Policy             `json:"policies,omitempty"`
+	DeletePolicies    []id.PolicyID                `json:"delete_policies,omitempty"`
+	ResourceTypes     []*resourcetype.ResourceType `json:"resource_types,omitempty"`
+	Relations         []*relation.Tuple            `json:"relations,omitempty"`
+	DeleteRelations   []*relation.Tuple            `json:"delete_relations,omitempty"`
+
+	// Apply makes further changes directly against the overlay store. The
+	// DSL package uses it to apply a .warden program (dsl.SimulationChanges).
+	Apply func(ctx context.Context, s store.Store) error `json:"-"`
+}
+
+// RoleGrant attaches (or, in SimulationChanges.Revokes, detaches) a
+// permission to a role.
+type RoleGrant struct {
+	RoleID     id.RoleID      `json:"role_id"`
+	Permission permission.Ref `json:"permission"`
+}
+
+// SimulationReport is the outcome of replaying checks against a proposed
+// change.
+//
+// Before is the decision under the current model and After the decision
+// with the changes applied. Both are re-evaluated rather than read from the
+// log, so a check whose recorded decision is stale is not reported unless
+// the change itself flips it.
+type SimulationReport struct {
+	// Checks is the number of checks replayed.
+	Checks int `json:"checks"`
+
+	// Skipped counts checks that could not be replayed, for example
+	// because the recorded request is incomplete.
+	Skipped int `json:"skipped"`
+
+	// NewlyAllowed and NewlyDenied count the flips in each direction.
+	NewlyAllowed int `json:"newly_allowed"`
+	NewlyDenied  int `json:"newly_denied"`
+
+	// Flips lists every check whose allow/deny outcome would change, in
+	// replay order.
+	Flips []DecisionFlip `json:"flips"`
+}
+
+// DecisionFlip is one replayed check whose outcome the change flips.
+type DecisionFlip struct {
+	// CheckLogID identifies the replayed entry; empty for checks that did
+	// not come from the check log.
+	CheckLogID string        `json:"check_log_id,omitempty"`
+	RecordedAt time.Time     `json:"recorded_at,omitzero"`
+	Request    *CheckRequest `json:"request"`
+	Before     *CheckResult  `json:"before"`
+	After      *CheckResult  `json:"after"`
+}
+
+// replayedCheck is a check queued for replay with its provenance.
+type replayedCheck struct {
+	logID      string
+	appID      string
+	recordedAt time.Time
+	req        *CheckRequest
+}
+
+// Simulate replays the checks recorded in the check log that match query
+// against the current model and against the model with changes applied,
+// and reports every check whose outcome would flip.
+//
+// Changes are applied to an in-memory overlay holding a copy of each
+// replayed tenant's roles, permissions, assignments, policies, resource
+// types and relations; the store itself is never written. Replays use the
+// attributes recorded with each check. Check hooks, attribute providers,
+// the cache and the check log are not involved, so nothing is emitted or
+// recorded while simulating.
+//
+// query.TenantID defaults to the tenant in ctx. query.Limit caps the
+// number of checks replayed and defaults to DefaultSimulationLimit.
+func (e *Engine) Simulate(ctx context.Context, changes *SimulationChanges, query *checklog.QueryFilter) (*SimulationReport, error) {
+	filter := checklog.QueryFilter{}
+	if query != nil {
+		filter = *query
+	}
+	if filter.TenantID == "" {
+		filter.TenantID = tenantIDFromContext(ctx)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultSimulationLimit
+	}
+
+	const page = 1000
+	var checks []replayedCheck
+	for len(checks) < limit {
+		filter.Limit = min(page, limit-len(checks))
+		batch, err := e.store.ListCheckLogs(ctx, &filter)
+		if err != nil {
+			return nil, fmt.Errorf("warden: simulate: list check logs: %w", err)
+		}
+		for _, entry := range batch {
+			checks = append(checks, replayedCheck{
+				logID:      entry.ID.String(),
+				appID:      entry.AppID,
+				recordedAt: entry.CreatedAt,
+				req:        CheckRequestFromLog(entry),
+			})
+		}
+		if len(batch) < filter.Limit {
+			break
+		}
+		filter.Offset += len(batch)
+	}
+	return e.simulate(ctx, changes, checks)
+}
+
+// SimulateRequests is Simulate for checks supplied by the caller, for
+// example read from a JSON lines file with ReadCheckRequests. Requests
+// without a TenantID run in the tenant from ctx.
+func (e *Engine) SimulateRequests(ctx context.Context, changes *SimulationChanges, reqs []*CheckRequest) (*SimulationReport, error) {
+	scope := scopeFromContext(ctx)
+	checks := make([]replayedCheck, 0, len(reqs))
+	for _, req := range reqs {
+		r := *req
+		if r.TenantID == "" {
+			r.TenantID = scope.tenantID
+		}
+		checks = append(checks, replayedCheck{appID: scope.appID, req: &r})
+	}
+	return e.simulate(ctx, changes, checks)
+}
+
+func (e *Engine) simulate(ctx context.Context, changes *SimulationChanges, checks []replayedCheck) (*SimulationReport, error) {
+	var tenants []string
+	for _, c := range checks {
+		if !slices.Contains(tenants, c.req.TenantID) {
+			tenants = append(tenants, c.req.TenantID)
+		}
+	}
+	overlay := memory.New()
+	for _, tenantID := range tenants {
+		if err := copyTenantModel(ctx, e.store, overlay, tenantID); err != nil {
+			return nil, fmt.Errorf("warden: simulate: copy tenant %q: %w", tenantID, err)
+		}
+	}
+	if changes != nil {
+		if err := changes.applyTo(ctx, overlay); err != nil {
+			return nil, fmt.Errorf("warden: simulate: apply changes: %w", err)
+		}
+	}
+
+	current, candidate := e.replayEngine(e.store), e.replayEngine(overlay)
+	rep := &SimulationReport{Flips: []DecisionFlip{}}
+	for _, c := range checks {
+		cctx := WithTenant(ctx, c.appID, c.req.TenantID)
+		before, err := current.Check(cctx, c.req)
+		if err != nil {
+			rep.Skipped++
+			continue
+		}
+		after, err := candidate.Check(cctx, c.req)
+		if err != nil {
+			rep.Skipped++
+			continue
+		}
+		rep.Checks++
+		if before.Allowed == after.Allowed {
+			continue
+		}
+		if after.Allowed {
+			rep.NewlyAllowed++
+		} else {
+			rep.NewlyDenied++
+		}
+		rep.Flips = append(rep.Flips, DecisionFlip{
+			CheckLogID: c.logID,
+			RecordedAt: c.recordedAt,
+			Request:    c.req,
+			Before:     before,
+			After:      after,
+		})
+	}
+	return rep, nil
+}
+
+// storeBinder is implemented by ExpressionEvaluators that read the model
+// from a store, so a simulation can point them at its overlay.
+type storeBinder interface {
+	ForStore(s store.Store) ExpressionEvaluator
+}
+
+// replayEngine returns a copy of e that evaluates against s with no
+// plugins, cache or check log. Rate conditions read the live counters but
+// checks are not counted.
+func (e *Engine) replayEngine(s store.Store) *Engine {
+	cfg := e.Config()
+	off := false
+	cfg.EnableCheckLog = &off
+	cfg.EnableAudit = &off
+	exprEval := e.exprEval
+	if b, ok := exprEval.(storeBinder); ok {
+		exprEval = b.ForStore(s)
+	}
+	return &Engine{
+		store:       s,
+		evaluator:   e.evaluator,
+		graphWalker: e.graphWalker,
+		exprEval:    exprEval,
+		attrCache:   newAttributeCache(0),
+		counters:    readOnlyCounters{e.counters},
+		logger:      e.logger,
+		config:      cfg,
+	}
+}
+
+// copyTenantModel copies one tenant's authorization model from src to dst,
+// keeping IDs so changes can refer to existing entities.
+func copyTenantModel(ctx context.Context, src, dst store.Store, tenantID string) error {
+	rts, err := src.ListResourceTypes(ctx, &resourcetype.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, rt := range rts {
+		if rt.TenantID != tenantID {
+			continue
+		}
+		if err := dst.CreateResourceType(ctx, rt); err != nil {
+			return err
+		}
+	}
+	perms, err := src.ListPermissions(ctx, &permission.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, p := range perms {
+		if p.TenantID != tenantID {
+			continue
+		}
+		if err := dst.CreatePermission(ctx, p); err != nil {
+			return err
+		}
+	}
+	// Assignments are copied before their roles and constraints so the
+	// overlay's member caps and separation-of-duties checks don't reject
+	// assignments that already break them.
+	assignments, err := src.ListAssignments(ctx, &assignment.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		if a.TenantID != tenantID {
+			continue
+		}
+		if err := dst.CreateAssignment(ctx, a); err != nil {
+			return err
+		}
+	}
+	roles, err := src.ListRoles(ctx, &role.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if r.TenantID != tenantID {
+			continue
+		}
+		if err := dst.CreateRole(ctx, r); err != nil {
+			return err
+		}
+		granted, err := src.ListRolePermissions(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+		refs := make([]permission.Ref, len(granted))
+		for i, p := range granted {
+			refs[i] = permission.Ref{NamespacePath: p.NamespacePath, Name: p.Name}
+		}
+		if err := dst.SetRolePermissions(ctx, r.ID, refs); err != nil {
+			return err
+		}
+	}
+	policies, err := src.ListPolicies(ctx, &policy.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, p := range policies {
+		if p.TenantID != tenantID {
+			continue
+		}
+		if err := dst.CreatePolicy(ctx, p); err != nil {
+			return err
+		}
+	}
+	tuples, err := src.ListRelations(ctx, &relation.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, t := range tuples {
+		if t.TenantID != tenantID {
+			continue
+		}
+		if err := dst.CreateRelation(ctx, t); err != nil {
+			return err
+		}
+	}
+	calendars, err := src.ListCalendars(ctx, &calendar.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, c := range calendars {
+		if err := dst.CreateCalendar(ctx, c); err != nil {
+			return err
+		}
+	}
+	constraints, err := src.ListSoDConstraints(ctx, &sod.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	for _, c := range constraints {
+		if err := dst.CreateSoDConstraint(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *SimulationChanges) applyTo(ctx context.Context, s store.Store) error {
+	for _, rid := range c.DeleteRoles {
+		if err := s.DeleteRole(ctx, rid); err != nil {
+			return fmt.Errorf("delete role %s: %w", rid, err)
+		}
+	}
+	for _, aid := range c.DeleteAssignments {
+		if err := s.DeleteAssignment(ctx, aid); err != nil {
+			return fmt.Errorf("delete assignment %s: %w", aid, err)
+		}
+	}
+	for _, pid := range c.DeletePolicies {
+		if err := s.DeletePolicy(ctx, pid); err != nil {
+			return fmt.Errorf("delete policy %s: %w", pid, err)
+		}
+	}
+	for _, t := range c.DeleteRelations {
+		if err := s.DeleteRelationTuple(ctx, t.TenantID, t.NamespacePath, t.ObjectType, t.ObjectID, t.Relation, t.SubjectType, t.SubjectID); err != nil {
+			return fmt.Errorf("delete relation %s:%s#%s: %w", t.ObjectType, t.ObjectID, t.Relation, err)
+		}
+	}
+	for _, rt := range c.ResourceTypes {
+		_, err := s.GetResourceType(ctx, rt.ID)
+		if err := upsert(err == nil, func() error { return s.UpdateResourceType(ctx, rt) }, func() error { return s.CreateResourceType(ctx, rt) }); err != nil {
+			return fmt.Errorf("resource type %s: %w", rt.Name, err)
+		}
+	}
+	for _, p := range c.Permissions {
+		_, err := s.GetPermission(ctx, p.ID)
+		if err := upsert(err == nil, func() error { return s.UpdatePermission(ctx, p) }, func() error { return s.CreatePermission(ctx, p) }); err != nil {
+			return fmt.Errorf("permission %s: %w", p.Name, err)
+		}
+	}
+	for _, r := range c.Roles {
+		_, err := s.GetRole(ctx, r.ID)
+		if err := upsert(err == nil, func() error { return s.UpdateRole(ctx, r) }, func() error { return s.CreateRole(ctx, r) }); err != nil {
+			return fmt.Errorf("role %s: %w", r.Slug, err)
+		}
+	}
+	for _, g := range c.Grants {
+		if err := s.AttachPermission(ctx, g.RoleID, g.Permission); err != nil {
+			return fmt.Errorf("grant %s to %s: %w", g.Permission.Name, g.RoleID, err)
+		}
+	}
+	for _, g := range c.Revokes {
+		if err := s.DetachPermission(ctx, g.RoleID, g.Permission); err != nil {
+			return fmt.Errorf("revoke %s from %s: %w", g.Permission.Name, g.RoleID, err)
+		}
+	}
+	for _, a := range c.Assignments {
+		if err := s.CreateAssignment(ctx, a); err != nil {
+			return fmt.Errorf("assignment %s:%s: %w", a.SubjectKind, a.SubjectID, err)
+		}
+	}
+	for _, p := range c.Policies {
+		_, err := s.GetPolicy(ctx, p.ID)
+		if err := upsert(err == nil, func() error { return s.UpdatePolicy(ctx, p) }, func() error { return s.CreatePolicy(ctx, p) }); err != nil {
+			return fmt.Errorf("policy %s: %w", p.Name, err)
+		}
+	}
+	for _, t := range c.Relations {
+		if err := s.CreateRelation(ctx, t); err != nil {
+			return fmt.Errorf("relation %s:%s#%s: %w", t.ObjectType, t.ObjectID, t.Relation, err)
+		}
+	}
+	if c.Apply != nil {
+		return c.Apply(ctx, s)
+	}
+	return nil
+}
+
+// upsert updates an entity the overlay already holds and creates it
+// otherwise.
+func upsert(exists bool, update, create func() error) error {
+	if exists {
+		return update()
+	}
+	return create()
+}
+
+// CheckRequestFromLog rebuilds the request a check log entry recorded,
+// including the subject, resource and context attribute snapshot and the
+// session's active roles.
+func CheckRequestFromLog(entry *checklog.Entry) *CheckRequest {
+	req := &CheckRequest{
+		Subject:       Subject{Kind: SubjectKind(entry.SubjectKind), ID: entry.SubjectID},
+		Action:        Action{Name: entry.Action},
+		Resource:      Resource{Type: entry.ResourceType, ID: entry.ResourceID},
+		TenantID:      entry.TenantID,
+		NamespacePath: entry.NamespacePath,
+	}
+	if m, ok := entry.Metadata[checklog.MetadataSubjectAttributes].(map[string]any); ok {
+		req.Subject.Attributes = m
+	}
+	if m, ok := entry.Metadata[checklog.MetadataResourceAttributes].(map[string]any); ok {
+		req.Resource.Attributes = m
+	}
+	if m, ok := entry.Metadata[checklog.MetadataContext].(map[string]any); ok {
+		req.Context = m
+	}
+	switch roles := entry.Metadata[checklog.MetadataActiveRoles].(type) {
+	case []string:
+		req.Subject.ActiveRoles = roles
+	case []any: // decoded from JSON
+		for _, r := range roles {
+			if slug, ok := r.(string); ok {
+				req.Subject.ActiveRoles = append(req.Subject.ActiveRoles, slug)
+			}
+		}
+	}
+	return req
+}
+
+// ReadCheckRequests reads checks to replay from JSON lines. Each line is
+// either a CheckRequest or a check log entry as written by
+// `warden checklog export`. Blank lines are skipped.
+func ReadCheckRequests(r io.Reader) ([]*CheckRequest, error) {
+	var out []*CheckRequest
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		raw := bytes.TrimSpace(sc.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var probe struct {
+			SubjectKind *string `json:"subject_kind"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil {
+			return nil, fmt.Errorf("warden: line %d: %w", line, err)
+		}
+		if probe.SubjectKind != nil {
+			var entry checklog.Entry
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return nil, fmt.Errorf("warden: line %d: %w", line, err)
+			}
+			out = append(out, CheckRequestFromLog(&entry))
+			continue
+		}
+		var req CheckRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, fmt.Errorf("warden: line %d: %w", line, err)
+		}
+		out = append(out, &req)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("warden: read check requests: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,122 @@
+package warden
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/role"
+)
+
+func TestSimulate(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	viewerID := id.NewRoleID()
+	_ = s.CreateRole(ctx, &role.Role{ID: viewerID, TenantID: "t1", Name: "viewer", Slug: "viewer"})
+	for _, action := range []string{"read", "write"} {
+		_ = s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "doc:" + action, Resource: "doc", Action: action})
+	}
+	_ = s.AttachPermission(ctx, viewerID, permission.Ref{Name: "doc:read"})
+	_ = s.CreateAssignment(ctx, &assignment.Assignment{TenantID: "t1", RoleID: viewerID, SubjectKind: "user", SubjectID: "u1"})
+
+	for _, action := range []string{"read", "write"} {
+		if _, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1"},
+			Action:   Action{Name: action},
+			Resource: Resource{Type: "doc", ID: "d1"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Check logs are written asynchronously.
+	for range 100 {
+		if n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"}); n == 2 { //nolint:errcheck // polled
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	changes := &SimulationChanges{
+		Grants:  []RoleGrant{{RoleID: viewerID, Permission: permission.Ref{Name: "doc:write"}}},
+		Revokes: []RoleGrant{{RoleID: viewerID, Permission: permission.Ref{Name: "doc:read"}}},
+	}
+	rep, err := eng.Simulate(ctx, changes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Checks != 2 || rep.NewlyAllowed != 1 || rep.NewlyDenied != 1 || len(rep.Flips) != 2 {
+		t.Fatalf("report = %+v", rep)
+	}
+	for _, f := range rep.Flips {
+		if f.CheckLogID == "" || f.Before == nil || f.After == nil {
+			t.Errorf("flip missing provenance or decisions: %+v", f)
+		}
+		if want := f.Request.Action.Name == "write"; f.After.Allowed != want {
+			t.Errorf("%s: after allowed = %v, want %v", f.Request.Action.Name, f.After.Allowed, want)
+		}
+	}
+
+	// The simulation must not touch the real model or the check log.
+	perms, err := s.ListRolePermissions(ctx, viewerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 1 || perms[0].Name != "doc:read" {
+		t.Errorf("role permissions changed by simulation: %+v", perms)
+	}
+	if n, _ := s.CountCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"}); n != 2 { //nolint:errcheck // asserted
+		t.Errorf("check logs = %d after simulating, want 2", n)
+	}
+
+	// An empty change flips nothing; the query narrows what is replayed.
+	rep, err = eng.Simulate(ctx, &SimulationChanges{}, &checklog.QueryFilter{Action: "write"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Checks != 1 || len(rep.Flips) != 0 {
+		t.Errorf("no-op report = %+v", rep)
+	}
+}
+
+func TestSimulateRequests(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	eng, s := newTestEngine(t)
+
+	viewerID := id.NewRoleID()
+	_ = s.CreateRole(ctx, &role.Role{ID: viewerID, TenantID: "t1", Name: "viewer", Slug: "viewer"})
+	_ = s.CreatePermission(ctx, &permission.Permission{ID: id.NewPermissionID(), TenantID: "t1", Name: "doc:read", Resource: "doc", Action: "read"})
+	_ = s.AttachPermission(ctx, viewerID, permission.Ref{Name: "doc:read"})
+
+	input := strings.Join([]string{
+		`{"subject":{"kind":"user","id":"u1"},"action":{"name":"read"},"resource":{"type":"doc","id":"d1"}}`,
+		``,
+		`{"id":"chk_01h2xcejqtf2nbrexx3vqjhp41","tenant_id":"t1","subject_kind":"user","subject_id":"u2","action":"read","resource_type":"doc","resource_id":"d2","decision":"deny"}`,
+	}, "\n")
+	reqs, err := ReadCheckRequests(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 2 || reqs[1].Subject.ID != "u2" || reqs[1].TenantID != "t1" {
+		t.Fatalf("ReadCheckRequests = %+v", reqs)
+	}
+
+	changes := &SimulationChanges{
+		Assignments: []*assignment.Assignment{{TenantID: "t1", RoleID: viewerID, SubjectKind: "user", SubjectID: "u1"}},
+	}
+	rep, err := eng.SimulateRequests(ctx, changes, reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Checks != 2 || rep.NewlyAllowed != 1 || len(rep.Flips) != 1 || rep.Flips[0].Request.Subject.ID != "u1" {
+		t.Fatalf("report = %+v", rep)
+	}
+	if rep.Flips[0].Before.Allowed || !rep.Flips[0].After.Allowed {
+		t.Errorf("flip = %+v -> %+v", rep.Flips[0].Before, rep.Flips[0].After)
+	}
+}
@@ -0,0 +1,101 @@
+package warden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/xraph/go-utils/log"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/sod"
+)
+
+// activateRoles narrows roles, the subject's assigned roles, to those
+// whose slug the session activated in Subject.ActiveRoles, and returns
+// them followed by the roles they inherit. The result is checked against
+// the tenant's dynamic separation-of-duties constraints; a non-nil
+// CheckResult denies the check. Without ActiveRoles every assigned role is
+// active, so a subject that holds roles a dynamic constraint keeps apart
+// must choose among them, unless Config.DynamicSoDActiveRolesOnly is set.
+func (e *Engine) activateRoles(ctx context.Context, scope tenantScope, req *CheckRequest, roles []id.RoleID) ([]id.RoleID, *CheckResult, error) {
+	loader := newRoleLoader(e)
+	active := roles
+	if len(req.Subject.ActiveRoles) > 0 {
+		active = make([]id.RoleID, 0, len(roles))
+		for _, roleID := range roles {
+			r, err := loader.get(ctx, roleID)
+			if err != nil {
+				e.logger.Warn("warden: rbac GetRole error",
+					log.String("role_id", roleID.String()),
+					log.Error(err),
+				)
+				continue
+			}
+			if slices.Contains(req.Subject.ActiveRoles, r.Slug) {
+				active = append(active, roleID)
+			}
+		}
+		if len(active) == 0 {
+			return nil, &CheckResult{Decision: DecisionDenyNoRoles, Reason: fmt.Sprintf("none of the active roles %s is assigned to subject %s:%s in tenant %q",
+				strings.Join(req.Subject.ActiveRoles, ", "), req.Subject.Kind, req.Subject.ID, scope.tenantID)}, nil
+		}
+	}
+	inherited := loader.inherited(ctx, active)
+	if len(req.Subject.ActiveRoles) == 0 && e.config.DynamicSoDActiveRolesOnly {
+		return inherited, nil, nil
+	}
+
+	constraints, err := e.dynamicSoDConstraints(ctx, scope.tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(constraints) == 0 {
+		return inherited, nil, nil
+	}
+	var slugs []string
+	for _, roleID := range inherited {
+		r, err := loader.get(ctx, roleID)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("get role %s: %w", roleID, err)
+		}
+		if !slices.Contains(slugs, r.Slug) {
+			slugs = append(slugs, r.Slug)
+		}
+	}
+	for _, c := range constraints {
+		if held := c.Exceeded(slugs); held != nil {
+			reason := fmt.Sprintf("session activates %s, constraint %q allows %d at a time",
+				strings.Join(held, ", "), c.Name, c.Limit())
+			if len(req.Subject.ActiveRoles) == 0 {
+				reason = fmt.Sprintf("subject holds %s, constraint %q allows %d at a time; activate a subset with Subject.ActiveRoles",
+					strings.Join(held, ", "), c.Name, c.Limit())
+			}
+			return nil, &CheckResult{
+				Decision:  DecisionDenySoD,
+				Reason:    reason,
+				MatchedBy: []MatchInfo{{Source: "rbac", RuleID: c.ID.String(), Detail: "sod: " + c.Name}},
+			}, nil
+		}
+	}
+	return inherited, nil, nil
+}
+
+// dynamicSoDConstraints returns the dynamic separation-of-duties
+// constraints of a tenant, from the policy index when there is one.
+func (e *Engine) dynamicSoDConstraints(ctx context.Context, tenantID string) ([]*sod.Constraint, error) {
+	load := func() ([]*sod.Constraint, error) {
+		return traceStore(ctx, "ListSoDConstraints", func(ctx context.Context) ([]*sod.Constraint, error) {
+			return e.store.ListSoDConstraints(ctx, &sod.ListFilter{TenantID: tenantID, Kind: sod.KindDynamic})
+		})
+	}
+	if e.policyIndex == nil {
+		return load()
+	}
+	return e.policyIndex.dynamicSoD(tenantID, load)
+}
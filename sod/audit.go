@@ -0,0 +1,141 @@
+package sod
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/role"
+)
+
+// ViolationType names the rule a Violation breaks.
+type ViolationType string
+
+const (
+	// ViolationStatic is a subject holding more roles of a static
+	// constraint than it allows.
+	ViolationStatic ViolationType = "static"
+
+	// ViolationMaxMembers is a role with more members than its MaxMembers.
+	ViolationMaxMembers ViolationType = "max_members"
+)
+
+// Violation is one broken rule found by Audit.
+type Violation struct {
+	Type       ViolationType `json:"type"`
+	Constraint string        `json:"constraint,omitempty"` // static: the constraint's name
+	Subject    string        `json:"subject,omitempty"`    // static: "kind:id"
+	Roles      []string      `json:"roles,omitempty"`      // static: the constraint's roles the subject holds
+	Role       string        `json:"role,omitempty"`       // max_members: the role's slug
+	Namespace  string        `json:"namespace,omitempty"`  // max_members: the role's namespace
+	Members    int           `json:"members,omitempty"`    // max_members: current member count
+	MaxMembers int           `json:"max_members,omitempty"`
+}
+
+func (v Violation) String() string {
+	if v.Type == ViolationMaxMembers {
+		return fmt.Sprintf("role %q has %d members, max %d", v.Role, v.Members, v.MaxMembers)
+	}
+	return fmt.Sprintf("%s holds %s, constraint %q", v.Subject, strings.Join(v.Roles, ", "), v.Constraint)
+}
+
+// Report is the result of Audit.
+type Report struct {
+	TenantID    string      `json:"tenant_id"`
+	Constraints int         `json:"constraints"` // static constraints checked
+	Assignments int         `json:"assignments"` // unexpired assignments checked
+	Violations  []Violation `json:"violations"`
+}
+
+// Audit reports the existing assignments of a tenant that break a static
+// constraint or a role's MaxMembers, for example because they were created
+// before the constraint or the cap.
+// Dynamic constraints are not reported, since holding their roles is
+// allowed. A subject holds the roles it is assigned and the roles they
+// inherit through ParentSlug. Violations are ordered by type, then
+// constraint or role, then subject.
+func Audit(ctx context.Context, r Reader, tenantID string, now time.Time) (*Report, error) {
+	constraints, err := r.ListSoDConstraints(ctx, &ListFilter{TenantID: tenantID, Kind: KindStatic})
+	if err != nil {
+		return nil, fmt.Errorf("warden: list sod constraints: %w", err)
+	}
+	roles, err := r.ListRoles(ctx, &role.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return nil, fmt.Errorf("warden: list roles: %w", err)
+	}
+	as, err := r.ListAssignments(ctx, &assignment.ListFilter{TenantID: tenantID})
+	if err != nil {
+		return nil, fmt.Errorf("warden: list assignments: %w", err)
+	}
+
+	byID := make(map[string]*role.Role, len(roles))
+	bySlug := make(map[[2]string]*role.Role, len(roles)) // [namespace, slug]
+	for _, rl := range roles {
+		byID[rl.ID.String()] = rl
+		bySlug[[2]string{rl.NamespacePath, rl.Slug}] = rl
+	}
+	rep := &Report{TenantID: tenantID, Constraints: len(constraints), Violations: []Violation{}}
+	held := make(map[string][]string)           // subject → role slugs
+	members := make(map[string]map[string]bool) // role ID → subjects
+	for _, a := range as {
+		if a.ExpiresAt != nil && !a.ExpiresAt.After(now) {
+			continue
+		}
+		rep.Assignments++
+		rl, ok := byID[a.RoleID.String()]
+		if !ok {
+			continue
+		}
+		subject := a.SubjectKind + ":" + a.SubjectID
+		for _, slug := range inheritedSlugsOf(rl, bySlug) {
+			if !slices.Contains(held[subject], slug) {
+				held[subject] = append(held[subject], slug)
+			}
+		}
+		if members[a.RoleID.String()] == nil {
+			members[a.RoleID.String()] = make(map[string]bool)
+		}
+		members[a.RoleID.String()][subject] = true
+	}
+
+	for _, c := range constraints {
+		for subject, slugs := range held {
+			if exceeded := c.Exceeded(slugs); exceeded != nil {
+				rep.Violations = append(rep.Violations, Violation{
+					Type: ViolationStatic, Constraint: c.Name, Subject: subject, Roles: exceeded,
+				})
+			}
+		}
+	}
+	for _, rl := range roles {
+		if n := len(members[rl.ID.String()]); rl.MaxMembers > 0 && n > rl.MaxMembers {
+			rep.Violations = append(rep.Violations, Violation{
+				Type: ViolationMaxMembers, Role: rl.Slug, Namespace: rl.NamespacePath, Members: n, MaxMembers: rl.MaxMembers,
+			})
+		}
+	}
+	slices.SortFunc(rep.Violations, func(a, b Violation) int {
+		return strings.Compare(
+			strings.Join([]string{string(a.Type), a.Constraint, a.Namespace, a.Role, a.Subject}, "\x00"),
+			strings.Join([]string{string(b.Type), b.Constraint, b.Namespace, b.Role, b.Subject}, "\x00"))
+	})
+	return rep, nil
+}
+
+// inheritedSlugsOf is inheritedSlugs over roles already loaded, keyed by
+// namespace and slug.
+func inheritedSlugsOf(rl *role.Role, bySlug map[[2]string]*role.Role) []string {
+	out := []string{rl.Slug}
+	for cur := rl; cur.ParentSlug != "" && !slices.Contains(out, cur.ParentSlug) && len(out) <= maxInheritanceDepth; {
+		parent, ok := bySlug[[2]string{cur.NamespacePath, cur.ParentSlug}]
+		if !ok {
+			break
+		}
+		out = append(out, parent.Slug)
+		cur = parent
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+package sod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/wardenerr"
+)
+
+// maxInheritanceDepth bounds the ParentSlug chain followed for a role, as
+// the engine does when it resolves inherited permissions.
+const maxInheritanceDepth = 20
+
+// AssignmentReader is the part of a store that CheckAssignment reads.
+type AssignmentReader interface {
+	GetRole(ctx context.Context, roleID id.RoleID) (*role.Role, error)
+	GetRoleBySlug(ctx context.Context, tenantID, namespacePath, slug string) (*role.Role, error)
+	ListAssignments(ctx context.Context, filter *assignment.ListFilter) ([]*assignment.Assignment, error)
+	ListSoDConstraints(ctx context.Context, filter *ListFilter) ([]*Constraint, error)
+}
+
+// Reader is the part of a store that Audit reads.
+type Reader interface {
+	AssignmentReader
+	ListRoles(ctx context.Context, filter *role.ListFilter) ([]*role.Role, error)
+}
+
+// CheckAssignment reports whether creating a would give its role more
+// members than the role's MaxMembers (wardenerr.ErrMaxMembersExceeded) or
+// give its subject more roles of a static constraint than the constraint
+// allows (wardenerr.ErrSoDViolation). Members and held roles count every
+// unexpired assignment in the tenant, in any namespace and on any
+// resource, and a held role also holds the roles it inherits through
+// ParentSlug. An assignment to a role that does not exist is left to the
+// store's own checks; any other failed read is returned.
+//
+// Stores call it from CreateAssignment, so every path that assigns roles
+// is covered. They must serialise it with the insert, so that concurrent
+// assignments see each other.
+func CheckAssignment(ctx context.Context, r AssignmentReader, a *assignment.Assignment, now time.Time) error {
+	rl, err := r.GetRole(ctx, a.RoleID)
+	if errors.Is(err, wardenerr.ErrNotFound) || (err == nil && rl == nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("warden: get role: %w", err)
+	}
+	subject := a.SubjectKind + ":" + a.SubjectID
+
+	if rl.MaxMembers > 0 {
+		roleID := a.RoleID
+		current, err := r.ListAssignments(ctx, &assignment.ListFilter{TenantID: a.TenantID, RoleID: &roleID})
+		if err != nil {
+			return fmt.Errorf("warden: check role members: %w", err)
+		}
+		members := subjects(current, now)
+		if !members[subject] && len(members) >= rl.MaxMembers {
+			return fmt.Errorf("role %q has %d of %d members: %w", rl.Slug, len(members), rl.MaxMembers, wardenerr.ErrMaxMembersExceeded)
+		}
+	}
+
+	granted, err := inheritedSlugs(ctx, r, rl)
+	if err != nil {
+		return err
+	}
+	var constraints []*Constraint
+	for _, slug := range granted {
+		cs, err := r.ListSoDConstraints(ctx, &ListFilter{TenantID: a.TenantID, Kind: KindStatic, Role: slug})
+		if err != nil {
+			return fmt.Errorf("warden: list sod constraints: %w", err)
+		}
+		for _, c := range cs {
+			if !slices.ContainsFunc(constraints, func(o *Constraint) bool { return o.ID == c.ID }) {
+				constraints = append(constraints, c)
+			}
+		}
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+	held, err := r.ListAssignments(ctx, &assignment.ListFilter{TenantID: a.TenantID, SubjectKind: a.SubjectKind, SubjectID: a.SubjectID})
+	if err != nil {
+		return fmt.Errorf("warden: list subject assignments: %w", err)
+	}
+	slugs, err := roleSlugs(ctx, r, held, now)
+	if err != nil {
+		return err
+	}
+	for _, slug := range granted {
+		if !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	for _, c := range constraints {
+		if roles := c.Exceeded(slugs); roles != nil {
+			return fmt.Errorf("%s would hold %s, constraint %q allows %d: %w",
+				subject, strings.Join(roles, ", "), c.Name, c.Limit(), wardenerr.ErrSoDViolation)
+		}
+	}
+	return nil
+}
+
+// subjects returns the "kind:id" of every subject with an unexpired
+// assignment in as.
+func subjects(as []*assignment.Assignment, now time.Time) map[string]bool {
+	out := make(map[string]bool, len(as))
+	for _, a := range as {
+		if a.ExpiresAt == nil || a.ExpiresAt.After(now) {
+			out[a.SubjectKind+":"+a.SubjectID] = true
+		}
+	}
+	return out
+}
+
+// roleSlugs returns the slugs of the roles of the unexpired assignments in
+// as, and of the roles they inherit. Roles that no longer exist are
+// skipped.
+func roleSlugs(ctx context.Context, r AssignmentReader, as []*assignment.Assignment, now time.Time) ([]string, error) {
+	var out []string
+	seen := make(map[id.RoleID]bool, len(as))
+	for _, a := range as {
+		if seen[a.RoleID] || (a.ExpiresAt != nil && !a.ExpiresAt.After(now)) {
+			continue
+		}
+		seen[a.RoleID] = true
+		rl, err := r.GetRole(ctx, a.RoleID)
+		if errors.Is(err, wardenerr.ErrNotFound) || (err == nil && rl == nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("warden: get role: %w", err)
+		}
+		slugs, err := inheritedSlugs(ctx, r, rl)
+		if err != nil {
+			return nil, err
+		}
+		for _, slug := range slugs {
+			if !slices.Contains(out, slug) {
+				out = append(out, slug)
+			}
+		}
+	}
+	return out, nil
+}
+
+// inheritedSlugs returns the slug of rl followed by the slugs of its
+// ParentSlug ancestors, each looked up in the namespace of its child as
+// the engine does. The walk stops at a missing parent, at a cycle, or
+// after maxInheritanceDepth hops.
+func inheritedSlugs(ctx context.Context, r AssignmentReader, rl *role.Role) ([]string, error) {
+	out := []string{rl.Slug}
+	for cur := rl; cur.ParentSlug != "" && !slices.Contains(out, cur.ParentSlug) && len(out) <= maxInheritanceDepth; {
+		parent, err := r.GetRoleBySlug(ctx, cur.TenantID, cur.NamespacePath, cur.ParentSlug)
+		if errors.Is(err, wardenerr.ErrNotFound) || (err == nil && parent == nil) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("warden: get parent role %q: %w", cur.ParentSlug, err)
+		}
+		out = append(out, parent.Slug)
+		cur = parent
+	}
+	return out, nil
+}
@@ -0,0 +1,114 @@
+// Package sod defines separation-of-duties constraints: sets of roles no
+// subject may combine. Static constraints limit the roles a subject is
+// assigned and are enforced when assignments are created; dynamic
+// constraints limit the roles a subject activates in one session and are
+// enforced when a check uses them together.
+package sod
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xraph/warden/id"
+)
+
+// Kind selects when a constraint applies.
+type Kind string
+
+const (
+	// KindStatic limits the roles of the set a subject may be assigned.
+	KindStatic Kind = "static"
+
+	// KindDynamic limits the roles of the set a subject may activate in
+	// one session. Holding more of them is allowed.
+	KindDynamic Kind = "dynamic"
+)
+
+// Constraint allows a subject at most MaxRoles of Roles. Roles are slugs
+// and match roles of that slug in every namespace of the tenant. Names are
+// unique per tenant.
+type Constraint struct {
+	ID          id.SoDConstraintID `json:"id" db:"id"`
+	TenantID    string             `json:"tenant_id" db:"tenant_id"`
+	AppID       string             `json:"app_id" db:"app_id"`
+	Name        string             `json:"name" db:"name"`
+	Description string             `json:"description,omitempty" db:"description"`
+	Kind        Kind               `json:"kind" db:"kind"`
+	Roles       []string           `json:"roles" db:"roles"`
+	MaxRoles    int                `json:"max_roles,omitempty" db:"max_roles"` // 0 = 1
+	Metadata    map[string]any     `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// Validate reports a constraint without a name, with an unknown kind, with
+// fewer than two distinct roles, or with a MaxRoles that every subject
+// satisfies.
+func (c *Constraint) Validate() error {
+	if c.Name == "" {
+		return errors.New("constraint name is required")
+	}
+	if c.Kind != KindStatic && c.Kind != KindDynamic {
+		return fmt.Errorf("unknown constraint kind %q: want static or dynamic", c.Kind)
+	}
+	roles := slices.Clone(c.Roles)
+	slices.Sort(roles)
+	if roles = slices.Compact(roles); len(roles) < 2 || slices.Contains(roles, "") {
+		return errors.New("a constraint needs at least two distinct roles")
+	}
+	if c.MaxRoles < 0 || c.MaxRoles >= len(roles) {
+		return fmt.Errorf("max_roles %d must be between 1 and %d", c.MaxRoles, len(roles)-1)
+	}
+	return nil
+}
+
+// Limit returns the number of the constraint's roles a subject may hold.
+func (c *Constraint) Limit() int {
+	return max(c.MaxRoles, 1)
+}
+
+// Exceeded returns the constraint's roles among slugs, sorted, when there
+// are more of them than the constraint allows, and nil otherwise.
+func (c *Constraint) Exceeded(slugs []string) []string {
+	var held []string
+	for _, r := range c.Roles {
+		if slices.Contains(slugs, r) && !slices.Contains(held, r) {
+			held = append(held, r)
+		}
+	}
+	if len(held) <= c.Limit() {
+		return nil
+	}
+	slices.Sort(held)
+	return held
+}
+
+// ListFilter contains filters for listing constraints.
+type ListFilter struct {
+	TenantID string `json:"tenant_id,omitempty"`
+	Kind     Kind   `json:"kind,omitempty"`
+	Role     string `json:"role,omitempty"` // constraints naming this role slug
+	Search   string `json:"search,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+}
+
+// Matches reports whether c passes the filter's field criteria. Backends
+// without a query language use it; Limit and Offset are left to the
+// caller.
+func (f *ListFilter) Matches(c *Constraint) bool {
+	if f == nil {
+		return true
+	}
+	switch {
+	case f.TenantID != "" && c.TenantID != f.TenantID,
+		f.Kind != "" && c.Kind != f.Kind,
+		f.Role != "" && !slices.Contains(c.Roles, f.Role),
+		f.Search != "" && !strings.Contains(strings.ToLower(c.Name), strings.ToLower(f.Search)):
+		return false
+	}
+	return true
+}
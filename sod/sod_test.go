@@ -0,0 +1,163 @@
+package sod_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/store/memory"
+	"github.com/xraph/warden/wardenerr"
+)
+
+func TestConstraint_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		c    sod.Constraint
+		ok   bool
+	}{
+		{"valid", sod.Constraint{Name: "c", Kind: sod.KindStatic, Roles: []string{"a", "b"}}, true},
+		{"two of three", sod.Constraint{Name: "c", Kind: sod.KindDynamic, Roles: []string{"a", "b", "c"}, MaxRoles: 2}, true},
+		{"no name", sod.Constraint{Kind: sod.KindStatic, Roles: []string{"a", "b"}}, false},
+		{"unknown kind", sod.Constraint{Name: "c", Kind: "session", Roles: []string{"a", "b"}}, false},
+		{"one role", sod.Constraint{Name: "c", Kind: sod.KindStatic, Roles: []string{"a", "a"}}, false},
+		{"empty role", sod.Constraint{Name: "c", Kind: sod.KindStatic, Roles: []string{"a", ""}}, false},
+		{"max roles allows all", sod.Constraint{Name: "c", Kind: sod.KindStatic, Roles: []string{"a", "b"}, MaxRoles: 2}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.c.Validate(); (err == nil) != tt.ok {
+				t.Fatalf("Validate() = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+// TestAudit reports assignments made before the constraint and the member
+// cap they now break, and ignores expired assignments.
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	roles := map[string]*role.Role{}
+	for _, slug := range []string{"requester", "approver"} {
+		roles[slug] = &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: slug, Slug: slug}
+		if err := s.CreateRole(ctx, roles[slug]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	for _, a := range []struct {
+		subject, role string
+		expiresAt     *time.Time
+	}{
+		{"alice", "requester", nil},
+		{"alice", "approver", nil},
+		{"bob", "requester", nil},
+		{"bob", "approver", &past},
+		{"carol", "approver", nil},
+	} {
+		if err := s.CreateAssignment(ctx, &assignment.Assignment{
+			ID: id.NewAssignmentID(), TenantID: "t1", RoleID: roles[a.role].ID,
+			SubjectKind: "user", SubjectID: a.subject, ExpiresAt: a.expiresAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []*sod.Constraint{
+		{TenantID: "t1", Name: "request-approve", Kind: sod.KindStatic, Roles: []string{"requester", "approver"}},
+		{TenantID: "t1", Name: "session", Kind: sod.KindDynamic, Roles: []string{"requester", "approver"}},
+	} {
+		if err := s.CreateSoDConstraint(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	roles["approver"].MaxMembers = 1
+	if err := s.UpdateRole(ctx, roles["approver"]); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := sod.Audit(ctx, s, "t1", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Constraints != 1 || rep.Assignments != 4 {
+		t.Fatalf("checked %d constraints and %d assignments, want 1 and 4", rep.Constraints, rep.Assignments)
+	}
+	want := []string{
+		`role "approver" has 2 members, max 1`,
+		`user:alice holds approver, requester, constraint "request-approve"`,
+	}
+	if len(rep.Violations) != len(want) {
+		t.Fatalf("violations = %v, want %v", rep.Violations, want)
+	}
+	for i, v := range rep.Violations {
+		if v.String() != want[i] {
+			t.Errorf("violation %d = %q, want %q", i, v.String(), want[i])
+		}
+	}
+}
+
+// TestAudit_InheritedRoles counts the roles a held role inherits.
+func TestAudit_InheritedRoles(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	roles := map[string]*role.Role{}
+	for slug, parent := range map[string]string{"requester": "", "approver": "", "lead": "approver"} {
+		roles[slug] = &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: slug, Slug: slug, ParentSlug: parent}
+		if err := s.CreateRole(ctx, roles[slug]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, slug := range []string{"requester", "lead"} {
+		if err := s.CreateAssignment(ctx, &assignment.Assignment{
+			ID: id.NewAssignmentID(), TenantID: "t1", RoleID: roles[slug].ID, SubjectKind: "user", SubjectID: "alice",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.CreateSoDConstraint(ctx, &sod.Constraint{
+		TenantID: "t1", Name: "request-approve", Kind: sod.KindStatic, Roles: []string{"requester", "approver"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := sod.Audit(ctx, s, "t1", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `user:alice holds approver, requester, constraint "request-approve"`
+	if len(rep.Violations) != 1 || rep.Violations[0].String() != want {
+		t.Fatalf("violations = %v, want [%s]", rep.Violations, want)
+	}
+}
+
+// failingRoleReader fails GetRole with err.
+type failingRoleReader struct {
+	*memory.Store
+	err error
+}
+
+func (r failingRoleReader) GetRole(context.Context, id.RoleID) (*role.Role, error) {
+	return nil, r.err
+}
+
+// TestCheckAssignment_RoleReadErrors lets an assignment to a missing role
+// through to the store but fails one whose role cannot be read.
+func TestCheckAssignment_RoleReadErrors(t *testing.T) {
+	ctx := context.Background()
+	a := &assignment.Assignment{TenantID: "t1", RoleID: id.NewRoleID(), SubjectKind: "user", SubjectID: "alice"}
+
+	missing := failingRoleReader{Store: memory.New(), err: fmt.Errorf("role %s: %w", a.RoleID, wardenerr.ErrNotFound)}
+	if err := sod.CheckAssignment(ctx, missing, a, time.Now()); err != nil {
+		t.Errorf("missing role: %v, want nil", err)
+	}
+	unavailable := failingRoleReader{Store: memory.New(), err: errors.New("connection reset")}
+	if err := sod.CheckAssignment(ctx, unavailable, a, time.Now()); err == nil {
+		t.Error("unreadable role: want an error")
+	}
+}
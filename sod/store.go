@@ -0,0 +1,30 @@
+package sod
+
+import (
+	"context"
+
+	"github.com/xraph/warden/id"
+)
+
+// Store defines persistence operations for separation-of-duties
+// constraints.
+type Store interface {
+	// CreateSoDConstraint persists a new constraint.
+	CreateSoDConstraint(ctx context.Context, c *Constraint) error
+
+	// GetSoDConstraint retrieves a constraint by ID.
+	GetSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) (*Constraint, error)
+
+	// UpdateSoDConstraint persists changes to a constraint.
+	UpdateSoDConstraint(ctx context.Context, c *Constraint) error
+
+	// DeleteSoDConstraint removes a constraint by ID.
+	DeleteSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) error
+
+	// ListSoDConstraints returns constraints matching the filter, ordered
+	// by name.
+	ListSoDConstraints(ctx context.Context, filter *ListFilter) ([]*Constraint, error)
+
+	// DeleteSoDConstraintsByTenant removes all constraints for a tenant.
+	DeleteSoDConstraintsByTenant(ctx context.Context, tenantID string) error
+}
@@ -0,0 +1,262 @@
+package warden
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/store/memory"
+)
+
+// seedSoDRoles gives u1 the requester (expense:create) and approver
+// (expense:approve) roles and declares them dynamically exclusive.
+func seedSoDRoles(t *testing.T, s *memory.Store) {
+	t.Helper()
+	ctx := context.Background()
+	for slug, perm := range map[string]string{"requester": "create", "approver": "approve"} {
+		r := &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: slug, Slug: slug}
+		if err := s.CreateRole(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.CreatePermission(ctx, &permission.Permission{
+			ID: id.NewPermissionID(), TenantID: "t1", Name: "expense:" + perm, Resource: "expense", Action: perm,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AttachPermission(ctx, r.ID, permission.Ref{Name: "expense:" + perm}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.CreateAssignment(ctx, &assignment.Assignment{
+			ID: id.NewAssignmentID(), TenantID: "t1", RoleID: r.ID, SubjectKind: "user", SubjectID: "u1",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.CreateSoDConstraint(ctx, &sod.Constraint{
+		TenantID: "t1", Name: "request-approve", Kind: sod.KindDynamic,
+		Roles: []string{"requester", "approver"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_ActiveRoles(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	seedSoDRoles(t, s)
+	c := &recordingCache{}
+	eng, err := NewEngine(WithStore(s), WithCache(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(action string, active ...string) *CheckResult {
+		t.Helper()
+		res, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1", ActiveRoles: active},
+			Action:   Action{Name: action},
+			Resource: Resource{Type: "expense", ID: "e1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name   string
+		action string
+		active []string
+		want   Decision
+	}{
+		{"all roles without a session break the constraint", "approve", nil, DecisionDenySoD},
+		{"active role grants", "create", []string{"requester"}, DecisionAllow},
+		{"inactive role does not grant", "approve", []string{"requester"}, DecisionDenyNoPerms},
+		{"exclusive roles together", "create", []string{"requester", "approver"}, DecisionDenySoD},
+		{"unassigned active role", "create", []string{"auditor"}, DecisionDenyNoRoles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := check(tt.action, tt.active...); res.Decision != tt.want {
+				t.Fatalf("decision = %s (%s), want %s", res.Decision, res.Reason, tt.want)
+			}
+		})
+	}
+	if c.sets != 1 {
+		t.Fatalf("cached %d results, want only the check without active roles", c.sets)
+	}
+}
+
+// TestEngine_DynamicSoDOverridesAllow denies a session violating a
+// dynamic constraint even when an ABAC policy would allow it.
+func TestEngine_DynamicSoDOverridesAllow(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	seedSoDRoles(t, s)
+	eng, err := NewEngine(WithStore(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreatePolicy(ctx, &policy.Policy{
+		TenantID: "t1", Name: "allow-all", Effect: policy.EffectAllow, IsActive: true,
+		Actions: []string{"*"}, Resources: []string{"expense:*"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := eng.Check(ctx, &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1", ActiveRoles: []string{"approver", "requester"}},
+		Action:   Action{Name: "approve"},
+		Resource: Resource{Type: "expense", ID: "e1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.Decision != DecisionDenySoD {
+		t.Fatalf("decision = %s (%s), want %s", res.Decision, res.Reason, DecisionDenySoD)
+	}
+}
+
+// TestEngine_DynamicSoDInheritedRoles counts the roles an activated role
+// inherits against dynamic constraints.
+func TestEngine_DynamicSoDInheritedRoles(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	seedSoDRoles(t, s)
+	lead := &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: "lead", Slug: "lead", ParentSlug: "approver"}
+	if err := s.CreateRole(ctx, lead); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateAssignment(ctx, &assignment.Assignment{
+		ID: id.NewAssignmentID(), TenantID: "t1", RoleID: lead.ID, SubjectKind: "user", SubjectID: "u1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	eng, err := NewEngine(WithStore(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := eng.Check(ctx, &CheckRequest{
+		Subject:  Subject{Kind: SubjectUser, ID: "u1", ActiveRoles: []string{"lead", "requester"}},
+		Action:   Action{Name: "create"},
+		Resource: Resource{Type: "expense", ID: "e1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Decision != DecisionDenySoD {
+		t.Fatalf("decision = %s (%s), want %s", res.Decision, res.Reason, DecisionDenySoD)
+	}
+}
+
+// TestEngine_DynamicSoDActiveRolesOnly leaves checks without active roles
+// to static constraints when Config.DynamicSoDActiveRolesOnly is set.
+func TestEngine_DynamicSoDActiveRolesOnly(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	s := memory.New()
+	seedSoDRoles(t, s)
+	eng, err := NewEngine(WithStore(s), WithConfig(Config{DynamicSoDActiveRolesOnly: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		active []string
+		want   Decision
+	}{
+		{nil, DecisionAllow},
+		{[]string{"requester", "approver"}, DecisionDenySoD},
+	} {
+		res, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1", ActiveRoles: tt.active},
+			Action:   Action{Name: "approve"},
+			Resource: Resource{Type: "expense", ID: "e1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Decision != tt.want {
+			t.Fatalf("active %v: decision = %s (%s), want %s", tt.active, res.Decision, res.Reason, tt.want)
+		}
+	}
+}
+
+// TestEngine_DynamicSoDCached reads the dynamic constraints from the
+// policy index until one changes, and each role from the store once per
+// check.
+func TestEngine_DynamicSoDCached(t *testing.T) {
+	ctx := WithTenant(context.Background(), "app1", "t1")
+	mem := memory.New()
+	seedSoDRoles(t, mem)
+	s := &countingSoDStore{Store: mem}
+	eng, err := NewEngine(WithStore(s), WithConfig(Config{PolicyIndexTTL: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(active ...string) Decision {
+		t.Helper()
+		res, err := eng.Check(ctx, &CheckRequest{
+			Subject:  Subject{Kind: SubjectUser, ID: "u1", ActiveRoles: active},
+			Action:   Action{Name: "create"},
+			Resource: Resource{Type: "expense", ID: "e1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Decision
+	}
+
+	for i := 0; i < 3; i++ {
+		if got := check("requester"); got != DecisionAllow {
+			t.Fatalf("check %d: decision = %s, want %s", i, got, DecisionAllow)
+		}
+	}
+	if n := s.lists.Load(); n != 1 {
+		t.Fatalf("listed constraints %d times, want once", n)
+	}
+	// Each check reads both assigned roles to match the active slug, and
+	// reuses them to resolve inheritance and constraints.
+	if n := s.gets.Load(); n != 3*2 {
+		t.Fatalf("read roles %d times, want %d", n, 3*2)
+	}
+
+	if got := check("requester", "approver"); got != DecisionDenySoD {
+		t.Fatalf("decision = %s, want %s", got, DecisionDenySoD)
+	}
+	list, err := mem.ListSoDConstraints(ctx, &sod.ListFilter{TenantID: "t1"})
+	if err != nil || len(list) != 1 {
+		t.Fatalf("expected one constraint, got %d (%v)", len(list), err)
+	}
+	if err := eng.Store().DeleteSoDConstraint(ctx, list[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := check("requester", "approver"); got != DecisionAllow {
+		t.Fatalf("after deleting the constraint: decision = %s, want %s", got, DecisionAllow)
+	}
+}
+
+// countingSoDStore counts dynamic constraint listings and role reads.
+type countingSoDStore struct {
+	store.Store
+	lists, gets atomic.Int64
+}
+
+func (s *countingSoDStore) ListSoDConstraints(ctx context.Context, filter *sod.ListFilter) ([]*sod.Constraint, error) {
+	s.lists.Add(1)
+	return s.Store.ListSoDConstraints(ctx, filter)
+}
+
+func (s *countingSoDStore) GetRole(ctx context.Context, roleID id.RoleID) (*role.Role, error) {
+	s.gets.Add(1)
+	return s.Store.GetRole(ctx, roleID)
+}
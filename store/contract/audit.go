@@ -0,0 +1,194 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/store"
+)
+
+// RunAuditContract asserts that audit entries round-trip every field the
+// hash covers (so a stored chain still verifies), that each tenant's chain
+// rejects a duplicate Seq with audit.ErrSequenceConflict, and that every
+// QueryFilter field narrows the result set the same way in every backend.
+func RunAuditContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	t.Run("RoundTrip", func(t *testing.T) { runAuditRoundTrip(t, mk) })
+	t.Run("SequenceConflict", func(t *testing.T) { runAuditSequenceConflict(t, mk) })
+	t.Run("Filters", func(t *testing.T) { runAuditFilters(t, mk) })
+}
+
+func seedAudit(t *testing.T, s store.Store) {
+	t.Helper()
+	ctx := context.Background()
+	rec := audit.NewRecorder(s)
+	entries := []*audit.Entry{
+		{
+			TenantID: "t1", NamespacePath: "eng", AppID: "app",
+			Actor: audit.Actor{Kind: "user", ID: "alice"}, Source: audit.SourceAPI,
+			Operation: audit.OpCreate, EntityType: audit.EntityRole,
+			EntityID: "role_1", EntityName: "editor",
+			After:     json.RawMessage(`{"slug":"editor","name":"Editor"}`),
+			RequestIP: "10.0.0.1", RequestID: "req-1",
+		},
+		{
+			TenantID: "t1", AppID: "app",
+			Actor: audit.Actor{Kind: "user", ID: "bob"}, Source: audit.SourceDSL,
+			Operation: audit.OpUpdate, EntityType: audit.EntityRole,
+			EntityID: "role_1", EntityName: "editor",
+			Before: json.RawMessage(`{"slug":"editor","name":"Editor"}`),
+			After:  json.RawMessage(`{"slug":"editor","name":"Writer"}`),
+		},
+		{
+			TenantID: "t1", AppID: "app",
+			Actor: audit.Actor{Kind: "user", ID: "alice"}, Source: audit.SourceCLI,
+			Operation: audit.OpDelete, EntityType: audit.EntityPolicy,
+			EntityID: "pol_1", EntityName: "freeze",
+			Before: json.RawMessage(`{"name":"freeze"}`),
+		},
+		{
+			TenantID: "t2", AppID: "app",
+			Source:    audit.SourceSDK,
+			Operation: audit.OpCreate, EntityType: audit.EntityRole,
+			EntityID: "role_2", EntityName: "viewer",
+			After: json.RawMessage(`{"slug":"viewer"}`),
+		},
+	}
+	for _, e := range entries {
+		if err := rec.Record(ctx, e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+}
+
+func runAuditRoundTrip(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+	seedAudit(t, s)
+
+	last, err := s.LastAuditEntry(ctx, "t1")
+	if err != nil {
+		t.Fatalf("LastAuditEntry: %v", err)
+	}
+	if last == nil || last.Seq != 3 || last.EntityName != "freeze" {
+		t.Fatalf("LastAuditEntry(t1) = %+v, want seq 3 (freeze)", last)
+	}
+	if none, err := s.LastAuditEntry(ctx, "missing"); err != nil || none != nil {
+		t.Fatalf("LastAuditEntry(missing) = %+v, %v; want nil, nil", none, err)
+	}
+
+	got, err := s.ListAuditEntries(ctx, &audit.QueryFilter{TenantID: "t1", Ascending: true})
+	if err != nil {
+		t.Fatalf("ListAuditEntries: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d entries, want 3", len(got))
+	}
+	first := got[0]
+	if first.Actor != (audit.Actor{Kind: "user", ID: "alice"}) || first.Source != audit.SourceAPI ||
+		first.NamespacePath != "eng" || first.RequestIP != "10.0.0.1" || first.RequestID != "req-1" {
+		t.Errorf("provenance not round-tripped: %+v", first)
+	}
+	if first.Before != nil {
+		t.Errorf("Before = %s, want nil for a create", first.Before)
+	}
+	if err := audit.Verify(nil, got); err != nil {
+		t.Fatalf("stored chain does not verify: %v", err)
+	}
+
+	v, err := audit.VerifyChain(ctx, s, "t1")
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !v.Valid || v.Entries != 3 || v.HeadSeq != 3 || v.HeadHash != last.Hash {
+		t.Errorf("VerifyChain = %+v", v)
+	}
+}
+
+func runAuditSequenceConflict(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+	seedAudit(t, s)
+
+	head, err := s.LastAuditEntry(ctx, "t1")
+	if err != nil {
+		t.Fatalf("LastAuditEntry: %v", err)
+	}
+	dup := &audit.Entry{
+		ID:       id.NewAuditID(),
+		TenantID: "t1", Operation: audit.OpCreate, EntityType: audit.EntityRole,
+		CreatedAt: time.Now(),
+	}
+	audit.Seal(dup, &audit.Entry{Seq: head.Seq - 1, Hash: head.PrevHash})
+	if err := s.AppendAuditEntry(ctx, dup); !errors.Is(err, audit.ErrSequenceConflict) {
+		t.Fatalf("AppendAuditEntry duplicate seq = %v, want ErrSequenceConflict", err)
+	}
+
+	// The same Seq in another tenant's chain is not a conflict.
+	other := &audit.Entry{
+		ID:       id.NewAuditID(),
+		TenantID: "t3", Operation: audit.OpCreate, EntityType: audit.EntityRole,
+		CreatedAt: time.Now(),
+	}
+	audit.Seal(other, nil)
+	if err := s.AppendAuditEntry(ctx, other); err != nil {
+		t.Fatalf("AppendAuditEntry new tenant: %v", err)
+	}
+}
+
+func runAuditFilters(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+	seedAudit(t, s)
+
+	cases := []struct {
+		name   string
+		filter audit.QueryFilter
+		want   []int64 // seqs, newest first
+	}{
+		{"tenant", audit.QueryFilter{TenantID: "t1"}, []int64{3, 2, 1}},
+		{"global scope is its own chain", audit.QueryFilter{}, nil},
+		{"entity_type", audit.QueryFilter{TenantID: "t1", EntityType: audit.EntityPolicy}, []int64{3}},
+		{"entity_id", audit.QueryFilter{TenantID: "t1", EntityID: "role_1"}, []int64{2, 1}},
+		{"operation", audit.QueryFilter{TenantID: "t1", Operation: audit.OpUpdate}, []int64{2}},
+		{"source", audit.QueryFilter{TenantID: "t1", Source: audit.SourceCLI}, []int64{3}},
+		{"actor", audit.QueryFilter{TenantID: "t1", ActorID: "alice"}, []int64{3, 1}},
+		{"after_seq", audit.QueryFilter{TenantID: "t1", AfterSeq: 1}, []int64{3, 2}},
+		{"limit offset", audit.QueryFilter{TenantID: "t1", Limit: 1, Offset: 1}, []int64{2}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.ListAuditEntries(ctx, &tc.filter)
+			if err != nil {
+				t.Fatalf("ListAuditEntries: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(tc.want))
+			}
+			for i, e := range got {
+				if e.Seq != tc.want[i] {
+					t.Errorf("entry %d seq = %d, want %d", i, e.Seq, tc.want[i])
+				}
+			}
+
+			countFilter := tc.filter
+			countFilter.Limit, countFilter.Offset = 0, 0
+			n, err := s.CountAuditEntries(ctx, &countFilter)
+			if err != nil {
+				t.Fatalf("CountAuditEntries: %v", err)
+			}
+			if tc.filter.Limit == 0 && n != int64(len(tc.want)) {
+				t.Errorf("count = %d, want %d", n, len(tc.want))
+			}
+		})
+	}
+}
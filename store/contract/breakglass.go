@@ -0,0 +1,116 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/wardenerr"
+)
+
+// RunBreakGlassContract asserts that break-glass grants behave the same in
+// every backend:
+//
+//   - A grant reads back with every field, including its roles and
+//     bypassed policies; an unknown ID wraps wardenerr.ErrNotFound.
+//   - Revoking a grant through UpdateBreakGlassGrant records who revoked
+//     it and when.
+//   - Lists are newest first and honour every ListFilter field, with
+//     ActiveAt leaving out revoked and expired grants.
+func RunBreakGlassContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	grant := func(tenant, kind, subject string, created time.Time, ttl time.Duration) *breakglass.Grant {
+		t.Helper()
+		g := &breakglass.Grant{
+			ID: id.NewBreakGlassID(), TenantID: tenant, NamespacePath: "/ops", AppID: "app1",
+			SubjectKind: kind, SubjectID: subject, Justification: "INC-42: primary database down",
+			RoleIDs:        []id.RoleID{id.NewRoleID(), id.NewRoleID()},
+			BypassPolicies: []string{"freeze-deploys", "freeze-*"},
+			ExpiresAt:      created.Add(ttl), CreatedAt: created,
+		}
+		if err := s.CreateBreakGlassGrant(ctx, g); err != nil {
+			t.Fatalf("CreateBreakGlassGrant: %v", err)
+		}
+		return g
+	}
+	oldest := grant("t1", "user", "alice", now.Add(-3*time.Hour), time.Hour) // expired
+	revoked := grant("t1", "user", "alice", now.Add(-2*time.Hour), 4*time.Hour)
+	active := grant("t1", "user", "bob", now.Add(-time.Hour), 2*time.Hour)
+	service := grant("t1", "service", "deployer", now.Add(-30*time.Minute), time.Hour)
+	other := grant("t2", "user", "alice", now, time.Hour)
+
+	got, err := s.GetBreakGlassGrant(ctx, active.ID)
+	if err != nil {
+		t.Fatalf("GetBreakGlassGrant: %v", err)
+	}
+	compareGrant(t, "get", got, active)
+	if _, err := s.GetBreakGlassGrant(ctx, id.NewBreakGlassID()); !errors.Is(err, wardenerr.ErrNotFound) {
+		t.Fatalf("GetBreakGlassGrant of an unknown ID: expected ErrNotFound, got %v", err)
+	}
+
+	revokedAt := now.Add(-time.Hour)
+	revoked.RevokedAt, revoked.RevokedBy = &revokedAt, "security"
+	if err := s.UpdateBreakGlassGrant(ctx, revoked); err != nil {
+		t.Fatalf("UpdateBreakGlassGrant: %v", err)
+	}
+	got, err = s.GetBreakGlassGrant(ctx, revoked.ID)
+	if err != nil {
+		t.Fatalf("GetBreakGlassGrant: %v", err)
+	}
+	compareGrant(t, "revoke", got, revoked)
+
+	grantIDs := func(gs ...*breakglass.Grant) []string {
+		out := make([]string, len(gs))
+		for i, g := range gs {
+			out[i] = g.ID.String()
+		}
+		return out
+	}
+	tests := []struct {
+		name   string
+		filter *breakglass.ListFilter
+		want   []*breakglass.Grant
+	}{
+		{"tenant", &breakglass.ListFilter{TenantID: "t1"}, []*breakglass.Grant{service, active, revoked, oldest}},
+		{"subject kind", &breakglass.ListFilter{TenantID: "t1", SubjectKind: "service"}, []*breakglass.Grant{service}},
+		{"subject", &breakglass.ListFilter{SubjectKind: "user", SubjectID: "alice"}, []*breakglass.Grant{other, revoked, oldest}},
+		{"active", &breakglass.ListFilter{TenantID: "t1", ActiveAt: &now}, []*breakglass.Grant{service, active}},
+		{"page", &breakglass.ListFilter{TenantID: "t1", Limit: 2, Offset: 1}, []*breakglass.Grant{active, revoked}},
+	}
+	for _, tt := range tests {
+		list, err := s.ListBreakGlassGrants(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListBreakGlassGrants: %v", tt.name, err)
+		}
+		if got, want := grantIDs(list...), grantIDs(tt.want...); !slices.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func compareGrant(t *testing.T, step string, got, want *breakglass.Grant) {
+	t.Helper()
+
+	if got.ID != want.ID || got.TenantID != want.TenantID || got.NamespacePath != want.NamespacePath ||
+		got.AppID != want.AppID || got.SubjectKind != want.SubjectKind || got.SubjectID != want.SubjectID ||
+		got.Justification != want.Justification || got.RevokedBy != want.RevokedBy {
+		t.Errorf("%s: got %+v, want %+v", step, got, want)
+	}
+	if !slices.Equal(got.RoleIDs, want.RoleIDs) || !slices.Equal(got.BypassPolicies, want.BypassPolicies) {
+		t.Errorf("%s: roles %v bypass %v, want %v and %v", step, got.RoleIDs, got.BypassPolicies, want.RoleIDs, want.BypassPolicies)
+	}
+	if !got.ExpiresAt.Equal(want.ExpiresAt) || !got.CreatedAt.Equal(want.CreatedAt) || !sameInstant(got.RevokedAt, want.RevokedAt) {
+		t.Errorf("%s: expires %s created %s revoked %v, want %s, %s and %v",
+			step, got.ExpiresAt, got.CreatedAt, got.RevokedAt, want.ExpiresAt, want.CreatedAt, want.RevokedAt)
+	}
+}
@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/calendar"
+	"github.com/xraph/warden/wardenerr"
+)
+
+// RunCalendarContract asserts that named calendars behave the same in
+// every backend:
+//
+//   - Creating a calendar assigns its ID and timestamps, and it reads back
+//     with every field by ID and by tenant and name.
+//   - Names are unique per tenant: creating or renaming onto a taken name
+//     wraps wardenerr.ErrDuplicateCalendar, while another tenant may reuse
+//     it.
+//   - Updates are read back.
+//   - Lists are ordered by name and honour the tenant filter, a
+//     case-insensitive name search, and Limit and Offset.
+//   - Deleted calendars wrap wardenerr.ErrNotFound, and deleting a tenant's
+//     calendars leaves other tenants' alone.
+func RunCalendarContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	create := func(tenant, name string) *calendar.Calendar {
+		t.Helper()
+		c := &calendar.Calendar{
+			TenantID: tenant, AppID: "app1", Name: name, Description: "Public holidays",
+			TimeZone: "Europe/Berlin",
+			Dates:    []calendar.Date{{Date: "12-25", Name: "Christmas"}, {Date: "2026-04-03", Name: "Good Friday"}},
+			Metadata: map[string]any{"region": "DE-BE", "source": "ops"},
+		}
+		if err := s.CreateCalendar(ctx, c); err != nil {
+			t.Fatalf("CreateCalendar %q: %v", name, err)
+		}
+		return c
+	}
+	holidays := create("t1", "holidays")
+	if holidays.ID.IsNil() || holidays.CreatedAt.IsZero() || holidays.UpdatedAt.IsZero() {
+		t.Fatalf("CreateCalendar did not assign an ID and timestamps: %+v", holidays)
+	}
+	freeze := create("t1", "change-freeze")
+	blackout := create("t1", "blackout")
+	other := create("t2", "holidays")
+
+	got, err := s.GetCalendar(ctx, holidays.ID)
+	if err != nil {
+		t.Fatalf("GetCalendar: %v", err)
+	}
+	compareCalendar(t, "get", got, holidays)
+	got, err = s.GetCalendarByName(ctx, "t2", "holidays")
+	if err != nil {
+		t.Fatalf("GetCalendarByName: %v", err)
+	}
+	compareCalendar(t, "get by name", got, other)
+
+	dup := &calendar.Calendar{TenantID: "t1", AppID: "app1", Name: "holidays"}
+	if err := s.CreateCalendar(ctx, dup); !errors.Is(err, wardenerr.ErrDuplicateCalendar) {
+		t.Fatalf("CreateCalendar of a taken name: expected ErrDuplicateCalendar, got %v", err)
+	}
+
+	holidays.Description = "Public and company holidays"
+	holidays.TimeZone = "America/New_York"
+	holidays.Dates = append(holidays.Dates, calendar.Date{Date: "07-04", Name: "Independence Day"})
+	holidays.Metadata = map[string]any{"region": "US-NY"}
+	if err := s.UpdateCalendar(ctx, holidays); err != nil {
+		t.Fatalf("UpdateCalendar: %v", err)
+	}
+	got, err = s.GetCalendar(ctx, holidays.ID)
+	if err != nil {
+		t.Fatalf("GetCalendar: %v", err)
+	}
+	compareCalendar(t, "update", got, holidays)
+
+	blackout.Name = "holidays"
+	if err := s.UpdateCalendar(ctx, blackout); !errors.Is(err, wardenerr.ErrDuplicateCalendar) {
+		t.Fatalf("UpdateCalendar onto a taken name: expected ErrDuplicateCalendar, got %v", err)
+	}
+	blackout.Name = "blackout"
+
+	calendarIDs := func(cs ...*calendar.Calendar) []string {
+		out := make([]string, len(cs))
+		for i, c := range cs {
+			out[i] = c.ID.String()
+		}
+		return out
+	}
+	tests := []struct {
+		name   string
+		filter *calendar.ListFilter
+		want   []*calendar.Calendar
+	}{
+		{"tenant", &calendar.ListFilter{TenantID: "t1"}, []*calendar.Calendar{blackout, freeze, holidays}},
+		{"search", &calendar.ListFilter{TenantID: "t1", Search: "FREEZE"}, []*calendar.Calendar{freeze}},
+		{"page", &calendar.ListFilter{TenantID: "t1", Limit: 1, Offset: 1}, []*calendar.Calendar{freeze}},
+	}
+	for _, tt := range tests {
+		list, err := s.ListCalendars(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListCalendars: %v", tt.name, err)
+		}
+		if got, want := calendarIDs(list...), calendarIDs(tt.want...); !slices.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, want)
+		}
+	}
+
+	if err := s.DeleteCalendar(ctx, freeze.ID); err != nil {
+		t.Fatalf("DeleteCalendar: %v", err)
+	}
+	if _, err := s.GetCalendar(ctx, freeze.ID); !errors.Is(err, wardenerr.ErrNotFound) {
+		t.Fatalf("GetCalendar after delete: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.GetCalendarByName(ctx, "t1", freeze.Name); !errors.Is(err, wardenerr.ErrNotFound) {
+		t.Fatalf("GetCalendarByName after delete: expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.DeleteCalendarsByTenant(ctx, "t1"); err != nil {
+		t.Fatalf("DeleteCalendarsByTenant: %v", err)
+	}
+	if list, err := s.ListCalendars(ctx, &calendar.ListFilter{TenantID: "t1"}); err != nil || len(list) != 0 {
+		t.Fatalf("ListCalendars after deleting the tenant = %v, %v; want none", calendarIDs(list...), err)
+	}
+	if _, err := s.GetCalendar(ctx, other.ID); err != nil {
+		t.Fatalf("GetCalendar of another tenant after deleting t1: %v", err)
+	}
+}
+
+func compareCalendar(t *testing.T, step string, got, want *calendar.Calendar) {
+	t.Helper()
+
+	if got.ID != want.ID || got.TenantID != want.TenantID || got.AppID != want.AppID || got.Name != want.Name ||
+		got.Description != want.Description || got.TimeZone != want.TimeZone {
+		t.Errorf("%s: got %+v, want %+v", step, got, want)
+	}
+	if !slices.Equal(got.Dates, want.Dates) {
+		t.Errorf("%s: dates %v, want %v", step, got.Dates, want.Dates)
+	}
+	if g, w := asJSON(t, got.Metadata), asJSON(t, want.Metadata); g != w {
+		t.Errorf("%s: metadata %s, want %s", step, g, w)
+	}
+	// Backends keep created_at to at least the millisecond.
+	if got.CreatedAt.Sub(want.CreatedAt).Abs() >= time.Millisecond {
+		t.Errorf("%s: created %s, want %s", step, got.CreatedAt, want.CreatedAt)
+	}
+}
@@ -0,0 +1,287 @@
+package contract
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/store"
+)
+
+// RunCheckLogQueryContract asserts that check log entries round-trip their
+// decision provenance (namespace, request IP/ID, matched rules,
+// obligations, attribute snapshot) and that every QueryFilter field
+// narrows the result set the same way in every backend.
+func RunCheckLogQueryContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	t.Run("RoundTrip", func(t *testing.T) { runCheckLogRoundTrip(t, mk) })
+	t.Run("Filters", func(t *testing.T) { runCheckLogFilters(t, mk) })
+	t.Run("DeleteByID", func(t *testing.T) { runCheckLogDelete(t, mk) })
+	t.Run("CursorPaging", func(t *testing.T) { runCheckLogCursor(t, mk) })
+	t.Run("Stats", func(t *testing.T) { runCheckLogStats(t, mk) })
+}
+
+func seedCheckLogs(t *testing.T, s store.Store) {
+	t.Helper()
+	entries := []*checklog.Entry{
+		{
+			TenantID: "t1", NamespacePath: "eng/platform",
+			SubjectKind: "user", SubjectID: "u1", Action: "read",
+			ResourceType: "document", ResourceID: "d1", Decision: "allow",
+			EvalTimeNs: 100,
+			RequestIP:  "10.0.0.1", RequestID: "req-1",
+			MatchedBy:   []checklog.Match{{Source: "rbac", RuleID: "editor", Detail: "document:read"}},
+			Obligations: []string{"audit-log"},
+			Metadata: map[string]any{
+				checklog.MetadataSubjectAttributes: map[string]any{"dept": "eng"},
+			},
+		},
+		{
+			TenantID: "t1", NamespacePath: "eng",
+			SubjectKind: "user", SubjectID: "u2", Action: "delete",
+			ResourceType: "document", ResourceID: "d2", Decision: "deny_explicit",
+			EvalTimeNs: 300,
+			RequestIP:  "10.0.0.2", RequestID: "req-2",
+			MatchedBy:   []checklog.Match{{Source: "abac", RuleID: "pol_freeze"}},
+			Obligations: []string{"notify-oncall", "audit-log"},
+		},
+		{
+			TenantID: "t1", NamespacePath: "other",
+			SubjectKind: "user", SubjectID: "u3", Action: "read",
+			ResourceType: "folder", ResourceID: "f1", Decision: "deny_default",
+			EvalTimeNs: 200,
+		},
+	}
+	for _, e := range entries {
+		if err := s.CreateCheckLog(context.Background(), e); err != nil {
+			t.Fatalf("CreateCheckLog: %v", err)
+		}
+	}
+}
+
+func runCheckLogRoundTrip(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+	seedCheckLogs(t, s)
+
+	got, err := s.ListCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1", RequestID: "req-1"})
+	if err != nil {
+		t.Fatalf("ListCheckLogs: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(got))
+	}
+	e := got[0]
+	if e.NamespacePath != "eng/platform" || e.RequestIP != "10.0.0.1" {
+		t.Errorf("scope/ip = %q/%q", e.NamespacePath, e.RequestIP)
+	}
+	if len(e.MatchedBy) != 1 || e.MatchedBy[0].RuleID != "editor" || e.MatchedBy[0].Detail != "document:read" {
+		t.Errorf("MatchedBy = %+v", e.MatchedBy)
+	}
+	if len(e.Obligations) != 1 || e.Obligations[0] != "audit-log" {
+		t.Errorf("Obligations = %v", e.Obligations)
+	}
+	attrs, ok := e.Metadata[checklog.MetadataSubjectAttributes].(map[string]any)
+	if !ok || attrs["dept"] != "eng" {
+		t.Errorf("Metadata = %+v", e.Metadata)
+	}
+
+	fetched, err := s.GetCheckLog(ctx, e.ID)
+	if err != nil {
+		t.Fatalf("GetCheckLog: %v", err)
+	}
+	if fetched.RequestID != "req-1" || len(fetched.MatchedBy) != 1 {
+		t.Errorf("GetCheckLog lost provenance: %+v", fetched)
+	}
+}
+
+func runCheckLogFilters(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+	seedCheckLogs(t, s)
+
+	eng := "eng"
+	cases := []struct {
+		name   string
+		filter checklog.QueryFilter
+		want   []string // subject IDs
+	}{
+		{"namespace path", checklog.QueryFilter{NamespacePath: &eng}, []string{"u2"}},
+		{"namespace prefix", checklog.QueryFilter{NamespacePrefix: "eng"}, []string{"u1", "u2"}},
+		{"namespace prefix wildcards are literal", checklog.QueryFilter{NamespacePrefix: "e_g"}, nil},
+		{"namespace prefix percent is literal", checklog.QueryFilter{NamespacePrefix: "%"}, nil},
+		{"resource id", checklog.QueryFilter{ResourceID: "f1"}, []string{"u3"}},
+		{"request ip", checklog.QueryFilter{RequestIP: "10.0.0.2"}, []string{"u2"}},
+		{"request id", checklog.QueryFilter{RequestID: "req-1"}, []string{"u1"}},
+		{"matched source", checklog.QueryFilter{MatchedSource: "abac"}, []string{"u2"}},
+		{"matched rule", checklog.QueryFilter{MatchedRuleID: "editor"}, []string{"u1"}},
+		{"matched source+rule mismatch", checklog.QueryFilter{MatchedSource: "abac", MatchedRuleID: "editor"}, nil},
+		{"obligation", checklog.QueryFilter{Obligation: "audit-log"}, []string{"u1", "u2"}},
+		{"obligation single", checklog.QueryFilter{Obligation: "notify-oncall"}, []string{"u2"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := tc.filter
+			f.TenantID = "t1"
+			got, err := s.ListCheckLogs(ctx, &f)
+			if err != nil {
+				t.Fatalf("ListCheckLogs: %v", err)
+			}
+			assertSubjects(t, got, tc.want)
+
+			n, err := s.CountCheckLogs(ctx, &f)
+			if err != nil {
+				t.Fatalf("CountCheckLogs: %v", err)
+			}
+			if int(n) != len(tc.want) {
+				t.Errorf("CountCheckLogs = %d, want %d", n, len(tc.want))
+			}
+		})
+	}
+}
+
+func runCheckLogDelete(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+	seedCheckLogs(t, s)
+
+	all, err := s.ListCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"})
+	if err != nil {
+		t.Fatalf("ListCheckLogs: %v", err)
+	}
+	var ids []id.CheckLogID
+	for _, e := range all {
+		if e.SubjectID != "u1" {
+			ids = append(ids, e.ID)
+		}
+	}
+	n, err := s.DeleteCheckLogs(ctx, ids)
+	if err != nil {
+		t.Fatalf("DeleteCheckLogs: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("DeleteCheckLogs = %d, want 2", n)
+	}
+	left, err := s.ListCheckLogs(ctx, &checklog.QueryFilter{TenantID: "t1"})
+	if err != nil {
+		t.Fatalf("ListCheckLogs: %v", err)
+	}
+	assertSubjects(t, left, []string{"u1"})
+
+	if n, err := s.DeleteCheckLogs(ctx, nil); err != nil || n != 0 {
+		t.Errorf("DeleteCheckLogs(nil) = %d, %v", n, err)
+	}
+}
+
+// runCheckLogCursor pages through entries sharing a timestamp one at a
+// time and asserts that an entry written mid-way neither repeats nor
+// skips any of them.
+func runCheckLogCursor(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+
+	at := time.Now().UTC().Truncate(time.Millisecond)
+	for _, subject := range []string{"u1", "u2", "u3"} {
+		if err := s.CreateCheckLog(ctx, &checklog.Entry{
+			TenantID: "t1", SubjectKind: "user", SubjectID: subject,
+			Action: "read", ResourceType: "doc", Decision: "allow", CreatedAt: at,
+		}); err != nil {
+			t.Fatalf("CreateCheckLog: %v", err)
+		}
+	}
+
+	filter := &checklog.QueryFilter{TenantID: "t1", Limit: 1}
+	var seen []*checklog.Entry
+	for range 5 {
+		page, err := s.ListCheckLogs(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListCheckLogs: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		seen = append(seen, page...)
+		if len(seen) == 1 {
+			// A newer entry arriving between pages must not shift the rest.
+			if err := s.CreateCheckLog(ctx, &checklog.Entry{
+				TenantID: "t1", SubjectKind: "user", SubjectID: "late",
+				Action: "read", ResourceType: "doc", Decision: "allow", CreatedAt: at.Add(time.Second),
+			}); err != nil {
+				t.Fatalf("CreateCheckLog: %v", err)
+			}
+		}
+		filter.Cursor = checklog.CursorAfter(page[len(page)-1])
+	}
+	assertSubjects(t, seen, []string{"u1", "u2", "u3"})
+	for i := 1; i < len(seen); i++ {
+		if seen[i].ID.String() >= seen[i-1].ID.String() {
+			t.Errorf("entries with equal timestamps not ordered by descending ID: %s then %s", seen[i-1].ID, seen[i].ID)
+		}
+	}
+}
+
+func assertSubjects(t *testing.T, got []*checklog.Entry, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	seen := make(map[string]bool, len(got))
+	for _, e := range got {
+		seen[e.SubjectID] = true
+	}
+	for _, w := range want {
+		if !seen[w] {
+			t.Errorf("missing entry for subject %q", w)
+		}
+	}
+}
+
+// runCheckLogStats asserts a backend's aggregation agrees with the
+// in-memory reference computed from ListCheckLogs.
+func runCheckLogStats(t *testing.T, mk MakeStore) {
+	ctx := context.Background()
+	s, cleanup := mk(t)
+	defer cleanup()
+	seedCheckLogs(t, s)
+
+	for _, q := range []checklog.StatsQuery{
+		{Filter: checklog.QueryFilter{TenantID: "t1"}},
+		{Filter: checklog.QueryFilter{TenantID: "t1"}, GroupBy: checklog.DimensionSubject, Bucket: time.Hour},
+		{Filter: checklog.QueryFilter{TenantID: "t1", Action: "read"}, GroupBy: checklog.DimensionResourceType, TopN: 1},
+	} {
+		got, err := checklog.QueryStats(ctx, s, &q)
+		if err != nil {
+			t.Fatalf("QueryStats(%s): %v", q.GroupBy, err)
+		}
+		entries, err := s.ListCheckLogs(ctx, &q.Filter)
+		if err != nil {
+			t.Fatalf("ListCheckLogs: %v", err)
+		}
+		if err := q.Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		want := checklog.ComputeStats(entries, &q)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("stats(%s):\n got  %+v\n want %+v", q.GroupBy, got, want)
+		}
+	}
+
+	st, err := checklog.QueryStats(ctx, s, &checklog.StatsQuery{Filter: checklog.QueryFilter{TenantID: "t1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Total != 3 || st.Latency.P50 != 200 || st.Latency.P99 != 300 {
+		t.Errorf("total/latency = %d/%+v", st.Total, st.Latency)
+	}
+	if len(st.TopDeniedSubjects) != 2 {
+		t.Errorf("TopDeniedSubjects = %+v", st.TopDeniedSubjects)
+	}
+}
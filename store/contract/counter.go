@@ -0,0 +1,116 @@
+package contract
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/counter"
+)
+
+// RunCounterContract asserts that a store counts rate events exactly like
+// counter.Memory, which the engine uses when counters are not persisted:
+//
+//   - Increments to the same bucket accumulate.
+//   - SumCounter includes buckets starting at or after since, and only
+//     those of the exact key.
+//   - PurgeCounters removes buckets starting before the cutoff and reports
+//     how many it removed.
+//   - DeleteCountersByTenant leaves other tenants alone.
+func RunCounterContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	s, cleanup := mk(t)
+	defer cleanup()
+	ref := counter.NewMemory()
+	ctx := context.Background()
+
+	t0 := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	key := counter.Key{TenantID: "t1", SubjectKind: "user", SubjectID: "u1", Action: "export"}
+	otherAction := key
+	otherAction.Action = "read"
+	otherTenant := key
+	otherTenant.TenantID = "t2"
+
+	events := []struct {
+		key counter.Key
+		at  time.Time
+		n   int64
+	}{
+		{key, t0, 1},
+		{key, t0.Add(400 * time.Millisecond), 2}, // same bucket as t0
+		{key, t0.Add(30 * time.Minute), 1},
+		{key, t0.Add(time.Hour - time.Second), 4},
+		{otherAction, t0.Add(time.Minute), 7},
+		{otherTenant, t0.Add(time.Minute), 9},
+	}
+	for _, ev := range events {
+		for _, st := range []counter.Store{s, ref} {
+			if err := st.IncrementCounter(ctx, ev.key, counter.Bucket(ev.at), ev.n); err != nil {
+				t.Fatalf("IncrementCounter: %v", err)
+			}
+		}
+	}
+
+	sums := []struct {
+		name  string
+		key   counter.Key
+		since time.Time
+		want  int64
+	}{
+		{"whole window", key, counter.Since(t0.Add(time.Hour-time.Millisecond), time.Hour), 8},
+		{"first bucket slid out", key, counter.Since(t0.Add(time.Hour), time.Hour), 5},
+		{"since is inclusive", key, t0.Add(30 * time.Minute), 5},
+		{"other action", otherAction, t0, 7},
+		{"other tenant", otherTenant, t0, 9},
+		{"unknown key", counter.Key{TenantID: "t1", SubjectKind: "user", SubjectID: "u2", Action: "export"}, t0, 0},
+	}
+	for _, tt := range sums {
+		t.Run("Sum_"+tt.name, func(t *testing.T) {
+			got, err := s.SumCounter(ctx, tt.key, tt.since)
+			if err != nil {
+				t.Fatalf("SumCounter: %v", err)
+			}
+			want, _ := ref.SumCounter(ctx, tt.key, tt.since) //nolint:errcheck // memory never fails
+			if want != tt.want {
+				t.Fatalf("reference sum = %d, want %d", want, tt.want)
+			}
+			if got != tt.want {
+				t.Errorf("SumCounter = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Purge", func(t *testing.T) {
+		purged, err := s.PurgeCounters(ctx, t0.Add(30*time.Minute))
+		if err != nil {
+			t.Fatalf("PurgeCounters: %v", err)
+		}
+		// The t0 bucket of key, plus otherAction and otherTenant.
+		if purged != 3 {
+			t.Errorf("purged %d buckets, want 3", purged)
+		}
+		got, err := s.SumCounter(ctx, key, time.Time{})
+		if err != nil {
+			t.Fatalf("SumCounter: %v", err)
+		}
+		if got != 5 {
+			t.Errorf("SumCounter after purge = %d, want 5", got)
+		}
+	})
+
+	t.Run("DeleteByTenant", func(t *testing.T) {
+		if err := s.IncrementCounter(ctx, otherTenant, counter.Bucket(t0.Add(time.Hour)), 1); err != nil {
+			t.Fatalf("IncrementCounter: %v", err)
+		}
+		if err := s.DeleteCountersByTenant(ctx, "t1"); err != nil {
+			t.Fatalf("DeleteCountersByTenant: %v", err)
+		}
+		if got, err := s.SumCounter(ctx, key, time.Time{}); err != nil || got != 0 {
+			t.Errorf("t1 sum after delete = %d, %v; want 0", got, err)
+		}
+		if got, err := s.SumCounter(ctx, otherTenant, time.Time{}); err != nil || got != 1 {
+			t.Errorf("t2 sum after deleting t1 = %d, %v; want 1", got, err)
+		}
+	})
+}
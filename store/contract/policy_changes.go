@@ -0,0 +1,114 @@
+package contract
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/sod"
+)
+
+// RunPolicyChangeContract asserts that a store reports policy writes to
+// the engine's policy index:
+//
+//   - The store implements policy.ChangeNotifier.
+//   - Creating, updating and deleting a policy, and deleting a tenant's
+//     policies, each report the policy's tenant.
+//   - So do the same writes to separation-of-duties constraints, which
+//     the index also caches.
+//   - Deleting a policy that does not exist reports nothing.
+//   - A stopped watcher is not called again.
+func RunPolicyChangeContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	notifier, ok := s.(policy.ChangeNotifier)
+	if !ok {
+		t.Fatalf("%T does not implement policy.ChangeNotifier", s)
+	}
+	got := make(chan string, 64)
+	stop := notifier.NotifyPolicyChanges(func(tenantID string) { got <- tenantID })
+
+	// expect drains the notifications of one write. Stores that also poll
+	// may report a write again, so only the tenants reported matter.
+	expect := func(step string, want ...string) {
+		t.Helper()
+		seen := map[string]bool{}
+		for len(got) > 0 {
+			seen[<-got] = true
+		}
+		for _, tenant := range want {
+			if !seen[tenant] {
+				t.Fatalf("%s: expected tenant %q to be reported, got %v", step, tenant, seen)
+			}
+			delete(seen, tenant)
+		}
+		if len(seen) > 0 {
+			t.Fatalf("%s: unexpected tenants reported: %v", step, seen)
+		}
+	}
+
+	p := &policy.Policy{
+		ID: id.NewPolicyID(), TenantID: "t1", Name: "allow-read",
+		Effect: policy.EffectAllow, IsActive: true, Actions: []string{"read"},
+	}
+	if err := s.CreatePolicy(ctx, p); err != nil {
+		t.Fatalf("CreatePolicy: %v", err)
+	}
+	expect("create", "t1")
+
+	p.IsActive = false
+	if err := s.UpdatePolicy(ctx, p); err != nil {
+		t.Fatalf("UpdatePolicy: %v", err)
+	}
+	expect("update", "t1")
+
+	if err := s.DeletePolicy(ctx, p.ID); err != nil {
+		t.Fatalf("DeletePolicy: %v", err)
+	}
+	expect("delete", "t1")
+
+	if err := s.DeletePolicy(ctx, id.NewPolicyID()); err != nil {
+		t.Fatalf("DeletePolicy of a missing policy: %v", err)
+	}
+	expect("delete missing")
+
+	if err := s.DeletePoliciesByTenant(ctx, "t2"); err != nil {
+		t.Fatalf("DeletePoliciesByTenant: %v", err)
+	}
+	expect("delete by tenant", "t2")
+
+	c := &sod.Constraint{TenantID: "t3", Name: "request-approve", Kind: sod.KindDynamic, Roles: []string{"requester", "approver"}}
+	if err := s.CreateSoDConstraint(ctx, c); err != nil {
+		t.Fatalf("CreateSoDConstraint: %v", err)
+	}
+	expect("create constraint", "t3")
+
+	c.MaxRoles = 1
+	if err := s.UpdateSoDConstraint(ctx, c); err != nil {
+		t.Fatalf("UpdateSoDConstraint: %v", err)
+	}
+	expect("update constraint", "t3")
+
+	if err := s.DeleteSoDConstraint(ctx, c.ID); err != nil {
+		t.Fatalf("DeleteSoDConstraint: %v", err)
+	}
+	expect("delete constraint", "t3")
+
+	if err := s.DeleteSoDConstraintsByTenant(ctx, "t4"); err != nil {
+		t.Fatalf("DeleteSoDConstraintsByTenant: %v", err)
+	}
+	expect("delete constraints by tenant", "t4")
+
+	stop()
+	if err := s.CreatePolicy(ctx, &policy.Policy{
+		ID: id.NewPolicyID(), TenantID: "t1", Name: "after-stop", Effect: policy.EffectAllow,
+	}); err != nil {
+		t.Fatalf("CreatePolicy: %v", err)
+	}
+	expect("after stop")
+}
@@ -0,0 +1,159 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/store"
+)
+
+// RunPolicyRoundTripContract asserts that every backend stores and returns
+// the policy fields the engine evaluates: Mode, OnError, the validity
+// window, legacy and structured obligations, advice, and conditions with
+// value references, expressions and time zones. Values are compared as
+// JSON, since backends may decode numbers and lists to different Go types.
+// An update that clears fields must clear them in the store too.
+func RunPolicyRoundTripContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	notBefore := time.Now().UTC().Truncate(time.Millisecond)
+	notAfter := notBefore.Add(24 * time.Hour)
+	p := &policy.Policy{
+		ID: id.NewPolicyID(), TenantID: "t1", NamespacePath: "/app", Name: "export-review",
+		Effect: policy.EffectDeny, Priority: 10, IsActive: true,
+		Mode: policy.ModeShadow, OnError: policy.OnErrorSkip,
+		NotBefore: &notBefore, NotAfter: &notAfter,
+		Obligations: []string{"legacy-audit"},
+		StructuredObligations: []policy.Obligation{
+			{Name: "require_mfa", Params: map[string]any{"max_age": 300, "methods": []any{"totp", "webauthn"}}, FulfillOn: policy.FulfillOnAllow},
+			{Name: "notify", Params: map[string]any{"who": "${subject.id}", "note": "export of ${resource.id}"}, FulfillOn: policy.FulfillOnDeny},
+			{Name: "audit_log"},
+		},
+		Advice: []policy.Obligation{
+			{Name: "show_banner", Params: map[string]any{"text": "exports are reviewed"}, FulfillOn: policy.FulfillOnAlways},
+		},
+		Subjects:  []policy.SubjectMatch{{Kind: "user"}, {Role: "analyst"}},
+		Actions:   []string{"export"},
+		Resources: []string{"report:*"},
+		Conditions: []policy.Condition{
+			{Field: "resource.owner", Operator: policy.OpEquals, ValueRef: "subject.id"},
+			{Expression: `resource.rows > 1000 && context.ip != ""`},
+			{Field: "context.region", Operator: policy.OpIn, Value: []any{"eu", "us"}},
+			{Field: "context.time", Operator: policy.OpTimeOfDay, Value: "09:00-17:00", TimeZone: "Europe/Berlin"},
+		},
+	}
+	if err := s.CreatePolicy(ctx, p); err != nil {
+		t.Fatalf("CreatePolicy: %v", err)
+	}
+	checkPolicyRoundTrip(ctx, t, s, "create", p)
+
+	p.Mode = policy.ModeEnforce
+	p.OnError = policy.OnErrorDeny
+	p.NotBefore, p.NotAfter = nil, nil
+	p.Obligations = nil
+	p.StructuredObligations = p.StructuredObligations[2:]
+	p.Advice = nil
+	p.Conditions = p.Conditions[:1]
+	if err := s.UpdatePolicy(ctx, p); err != nil {
+		t.Fatalf("UpdatePolicy: %v", err)
+	}
+	checkPolicyRoundTrip(ctx, t, s, "update", p)
+}
+
+// checkPolicyRoundTrip compares want with the policy read back by ID, by
+// name and from the tenant's active policies.
+func checkPolicyRoundTrip(ctx context.Context, t *testing.T, s store.Store, step string, want *policy.Policy) {
+	t.Helper()
+
+	got, err := s.GetPolicy(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("%s: GetPolicy: %v", step, err)
+	}
+	comparePolicy(t, step+": GetPolicy", got, want)
+
+	got, err = s.GetPolicyByName(ctx, want.TenantID, want.NamespacePath, want.Name)
+	if err != nil {
+		t.Fatalf("%s: GetPolicyByName: %v", step, err)
+	}
+	comparePolicy(t, step+": GetPolicyByName", got, want)
+
+	active, err := s.ListActivePolicies(ctx, want.TenantID, []string{want.NamespacePath})
+	if err != nil {
+		t.Fatalf("%s: ListActivePolicies: %v", step, err)
+	}
+	if len(active) != 1 {
+		t.Fatalf("%s: ListActivePolicies returned %d policies, want 1", step, len(active))
+	}
+	comparePolicy(t, step+": ListActivePolicies", active[0], want)
+}
+
+func comparePolicy(t *testing.T, step string, got, want *policy.Policy) {
+	t.Helper()
+
+	if got.Mode != want.Mode || got.OnError != want.OnError {
+		t.Errorf("%s: mode %q on_error %q, want %q and %q", step, got.Mode, got.OnError, want.Mode, want.OnError)
+	}
+	if !sameInstant(got.NotBefore, want.NotBefore) || !sameInstant(got.NotAfter, want.NotAfter) {
+		t.Errorf("%s: window %v – %v, want %v – %v", step, got.NotBefore, got.NotAfter, want.NotBefore, want.NotAfter)
+	}
+	for _, field := range []struct {
+		name      string
+		got, want any
+	}{
+		{"obligations", nonEmpty(got.Obligations), nonEmpty(want.Obligations)},
+		{"structured obligations", nonEmpty(got.StructuredObligations), nonEmpty(want.StructuredObligations)},
+		{"advice", nonEmpty(got.Advice), nonEmpty(want.Advice)},
+		{"subjects", got.Subjects, want.Subjects},
+		{"actions", got.Actions, want.Actions},
+		{"resources", got.Resources, want.Resources},
+		{"conditions", conditionValues(got.Conditions), conditionValues(want.Conditions)},
+	} {
+		if g, w := asJSON(t, field.got), asJSON(t, field.want); g != w {
+			t.Errorf("%s: %s = %s, want %s", step, field.name, g, w)
+		}
+	}
+}
+
+// conditionValues drops the condition IDs, which a backend may assign.
+func conditionValues(conds []policy.Condition) []policy.Condition {
+	out := make([]policy.Condition, len(conds))
+	for i, c := range conds {
+		out[i] = policy.Condition{
+			Field: c.Field, Operator: c.Operator, Value: c.Value,
+			ValueRef: c.ValueRef, Expression: c.Expression, TimeZone: c.TimeZone,
+		}
+	}
+	return out
+}
+
+// nonEmpty treats a nil and an empty slice alike.
+func nonEmpty[T any](s []T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+func sameInstant(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func asJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal %T: %v", v, err)
+	}
+	return string(b)
+}
@@ -0,0 +1,312 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/wardenerr"
+)
+
+// RunSoDContract asserts that CreateAssignment enforces role member caps
+// and static separation-of-duties constraints the same way in every
+// backend, including through inherited roles and under concurrent
+// assignments, that expired assignments don't count against either, and
+// that constraint lists honour every ListFilter field.
+func RunSoDContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	t.Run("MaxMembers", func(t *testing.T) { runSoDMaxMembers(t, mk) })
+	t.Run("Static", func(t *testing.T) { runSoDStatic(t, mk) })
+	t.Run("Inherited", func(t *testing.T) { runSoDInherited(t, mk) })
+	t.Run("Concurrent", func(t *testing.T) { runSoDConcurrent(t, mk) })
+	t.Run("ExpiredIgnored", func(t *testing.T) { runSoDExpiredIgnored(t, mk) })
+	t.Run("List", func(t *testing.T) { runSoDList(t, mk) })
+}
+
+func assign(ctx context.Context, s store.Store, roleID id.RoleID, subject string, expiresAt *time.Time) error {
+	return s.CreateAssignment(ctx, &assignment.Assignment{
+		ID: id.NewAssignmentID(), TenantID: "t1", NamespacePath: "/app",
+		RoleID: roleID, SubjectKind: "user", SubjectID: subject, ExpiresAt: expiresAt,
+	})
+}
+
+func runSoDMaxMembers(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	r := &role.Role{
+		ID: id.NewRoleID(), TenantID: "t1", NamespacePath: "/app",
+		Name: "Owner", Slug: "owner", MaxMembers: 2,
+	}
+	if err := s.CreateRole(ctx, r); err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	for _, sub := range []string{"alice", "bob"} {
+		if err := assign(ctx, s, r.ID, sub, nil); err != nil {
+			t.Fatalf("assign %s: %v", sub, err)
+		}
+	}
+	if err := assign(ctx, s, r.ID, "carol", nil); !errors.Is(err, wardenerr.ErrMaxMembersExceeded) {
+		t.Fatalf("third member: expected ErrMaxMembersExceeded, got %v", err)
+	}
+	// An existing member gaining a scoped assignment doesn't add a member.
+	err := s.CreateAssignment(ctx, &assignment.Assignment{
+		ID: id.NewAssignmentID(), TenantID: "t1", NamespacePath: "/app",
+		RoleID: r.ID, SubjectKind: "user", SubjectID: "alice",
+		ResourceType: "document", ResourceID: "d1",
+	})
+	if err != nil {
+		t.Fatalf("scoped assignment for existing member: %v", err)
+	}
+}
+
+func runSoDStatic(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	requester := seedRole(t, s, "t1", "/app", "requester")
+	approver := seedRole(t, s, "t1", "/app", "approver")
+	auditor := seedRole(t, s, "t1", "/app", "auditor")
+	err := s.CreateSoDConstraint(ctx, &sod.Constraint{
+		ID: id.NewSoDConstraintID(), TenantID: "t1", Name: "request-approve",
+		Kind: sod.KindStatic, Roles: []string{"requester", "approver", "auditor"}, MaxRoles: 1,
+	})
+	if err != nil {
+		t.Fatalf("CreateSoDConstraint: %v", err)
+	}
+
+	if err := assign(ctx, s, requester, "alice", nil); err != nil {
+		t.Fatalf("assign requester: %v", err)
+	}
+	if err := assign(ctx, s, approver, "alice", nil); !errors.Is(err, wardenerr.ErrSoDViolation) {
+		t.Fatalf("assign approver: expected ErrSoDViolation, got %v", err)
+	}
+	if err := assign(ctx, s, auditor, "alice", nil); !errors.Is(err, wardenerr.ErrSoDViolation) {
+		t.Fatalf("assign auditor: expected ErrSoDViolation, got %v", err)
+	}
+	if err := assign(ctx, s, approver, "bob", nil); err != nil {
+		t.Fatalf("assign approver to another subject: %v", err)
+	}
+
+	// Dynamic constraints restrict what a check activates, not what a
+	// subject may be assigned.
+	err = s.CreateSoDConstraint(ctx, &sod.Constraint{
+		ID: id.NewSoDConstraintID(), TenantID: "t1", Name: "approve-audit",
+		Kind: sod.KindDynamic, Roles: []string{"approver", "auditor"},
+	})
+	if err != nil {
+		t.Fatalf("CreateSoDConstraint dynamic: %v", err)
+	}
+	viewer := seedRole(t, s, "t1", "/app", "viewer")
+	if err := assign(ctx, s, viewer, "bob", nil); err != nil {
+		t.Fatalf("assign unconstrained role: %v", err)
+	}
+}
+
+func runSoDInherited(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	requester := seedRole(t, s, "t1", "/app", "requester")
+	seedRole(t, s, "t1", "/app", "approver")
+	lead := &role.Role{ID: id.NewRoleID(), TenantID: "t1", NamespacePath: "/app", Name: "lead", Slug: "lead", ParentSlug: "senior-approver"}
+	for _, r := range []*role.Role{
+		{ID: id.NewRoleID(), TenantID: "t1", NamespacePath: "/app", Name: "senior-approver", Slug: "senior-approver", ParentSlug: "approver"},
+		lead,
+	} {
+		if err := s.CreateRole(ctx, r); err != nil {
+			t.Fatalf("CreateRole %s: %v", r.Slug, err)
+		}
+	}
+	err := s.CreateSoDConstraint(ctx, &sod.Constraint{
+		ID: id.NewSoDConstraintID(), TenantID: "t1", Name: "request-approve",
+		Kind: sod.KindStatic, Roles: []string{"requester", "approver"},
+	})
+	if err != nil {
+		t.Fatalf("CreateSoDConstraint: %v", err)
+	}
+
+	// lead inherits approver through senior-approver, on either side of
+	// the assignment.
+	if err := assign(ctx, s, requester, "alice", nil); err != nil {
+		t.Fatalf("assign requester: %v", err)
+	}
+	if err := assign(ctx, s, lead.ID, "alice", nil); !errors.Is(err, wardenerr.ErrSoDViolation) {
+		t.Fatalf("assign lead over requester: expected ErrSoDViolation, got %v", err)
+	}
+	if err := assign(ctx, s, lead.ID, "bob", nil); err != nil {
+		t.Fatalf("assign lead: %v", err)
+	}
+	if err := assign(ctx, s, requester, "bob", nil); !errors.Is(err, wardenerr.ErrSoDViolation) {
+		t.Fatalf("assign requester over lead: expected ErrSoDViolation, got %v", err)
+	}
+}
+
+// runSoDConcurrent races assignments that only one may win: members of a
+// single-seat role, and the roles of a static constraint for one subject.
+func runSoDConcurrent(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	owner := &role.Role{
+		ID: id.NewRoleID(), TenantID: "t1", NamespacePath: "/app",
+		Name: "Owner", Slug: "owner", MaxMembers: 1,
+	}
+	if err := s.CreateRole(ctx, owner); err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	constrained := make([]id.RoleID, 4)
+	slugs := make([]string, len(constrained))
+	for i := range constrained {
+		slugs[i] = "duty-" + strconv.Itoa(i)
+		constrained[i] = seedRole(t, s, "t1", "/app", slugs[i])
+	}
+	err := s.CreateSoDConstraint(ctx, &sod.Constraint{
+		ID: id.NewSoDConstraintID(), TenantID: "t1", Name: "one-duty",
+		Kind: sod.KindStatic, Roles: slugs, MaxRoles: 1,
+	})
+	if err != nil {
+		t.Fatalf("CreateSoDConstraint: %v", err)
+	}
+
+	race := func(n int, attempt func(i int) error) int {
+		var wg sync.WaitGroup
+		var won atomic.Int32
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if attempt(i) == nil {
+					won.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		return int(won.Load())
+	}
+	if n := race(4, func(i int) error {
+		return assign(ctx, s, owner.ID, "user-"+strconv.Itoa(i), nil)
+	}); n != 1 {
+		t.Errorf("single-seat role: %d concurrent assignments succeeded, want 1", n)
+	}
+	if n := race(len(constrained), func(i int) error {
+		return assign(ctx, s, constrained[i], "alice", nil)
+	}); n != 1 {
+		t.Errorf("static constraint: %d concurrent assignments succeeded, want 1", n)
+	}
+}
+
+func runSoDExpiredIgnored(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	requester := seedRole(t, s, "t1", "/app", "requester")
+	approver := seedRole(t, s, "t1", "/app", "approver")
+	err := s.CreateSoDConstraint(ctx, &sod.Constraint{
+		ID: id.NewSoDConstraintID(), TenantID: "t1", Name: "request-approve",
+		Kind: sod.KindStatic, Roles: []string{"requester", "approver"},
+	})
+	if err != nil {
+		t.Fatalf("CreateSoDConstraint: %v", err)
+	}
+	owner := &role.Role{
+		ID: id.NewRoleID(), TenantID: "t1", NamespacePath: "/app",
+		Name: "Owner", Slug: "owner", MaxMembers: 1,
+	}
+	if err := s.CreateRole(ctx, owner); err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if err := assign(ctx, s, requester, "alice", &past); err != nil {
+		t.Fatalf("assign expired requester: %v", err)
+	}
+	if err := assign(ctx, s, approver, "alice", nil); err != nil {
+		t.Fatalf("assign approver over expired requester: %v", err)
+	}
+	if err := assign(ctx, s, owner.ID, "alice", &past); err != nil {
+		t.Fatalf("assign expired owner: %v", err)
+	}
+	if err := assign(ctx, s, owner.ID, "bob", nil); err != nil {
+		t.Fatalf("assign owner over expired member: %v", err)
+	}
+}
+
+func runSoDList(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, c := range []*sod.Constraint{
+		{TenantID: "t1", Name: "request-approve", Kind: sod.KindStatic, Roles: []string{"requester", "approver"}},
+		{TenantID: "t1", Name: "approve-audit", Kind: sod.KindDynamic, Roles: []string{"approver", "auditor"}},
+		{TenantID: "t1", Name: "pay-reconcile", Kind: sod.KindStatic, Roles: []string{"payer", "reconciler"}},
+		{TenantID: "t2", Name: "request-approve", Kind: sod.KindStatic, Roles: []string{"requester", "approver"}},
+	} {
+		if err := s.CreateSoDConstraint(ctx, c); err != nil {
+			t.Fatalf("CreateSoDConstraint %s: %v", c.Name, err)
+		}
+	}
+
+	names := func(f *sod.ListFilter) []string {
+		t.Helper()
+		cs, err := s.ListSoDConstraints(ctx, f)
+		if err != nil {
+			t.Fatalf("ListSoDConstraints: %v", err)
+		}
+		out := make([]string, len(cs))
+		for i, c := range cs {
+			out[i] = c.Name
+		}
+		return out
+	}
+	cases := []struct {
+		name   string
+		filter *sod.ListFilter
+		want   []string
+	}{
+		{"tenant", &sod.ListFilter{TenantID: "t1"}, []string{"approve-audit", "pay-reconcile", "request-approve"}},
+		{"kind", &sod.ListFilter{TenantID: "t1", Kind: sod.KindStatic}, []string{"pay-reconcile", "request-approve"}},
+		{"role", &sod.ListFilter{TenantID: "t1", Role: "approver"}, []string{"approve-audit", "request-approve"}},
+		{"search", &sod.ListFilter{TenantID: "t1", Search: "PAY"}, []string{"pay-reconcile"}},
+		{"page", &sod.ListFilter{TenantID: "t1", Limit: 1, Offset: 1}, []string{"pay-reconcile"}},
+	}
+	for _, tc := range cases {
+		got := names(tc.filter)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+
+	if err := s.DeleteSoDConstraintsByTenant(ctx, "t1"); err != nil {
+		t.Fatalf("DeleteSoDConstraintsByTenant: %v", err)
+	}
+	if got := names(&sod.ListFilter{TenantID: "t1"}); len(got) != 0 {
+		t.Errorf("after delete: got %v", got)
+	}
+	if got := names(&sod.ListFilter{TenantID: "t2"}); len(got) != 1 {
+		t.Errorf("other tenant after delete: got %v", got)
+	}
+}
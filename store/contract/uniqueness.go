@@ -5,14 +5,16 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/xraph/warden"
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
 	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/wardenerr"
 )
 
 // RunUniquenessContract asserts the documented uniqueness invariant
@@ -34,6 +36,8 @@ func RunUniquenessContract(t *testing.T, mk MakeStore) {
 	t.Run("Policy", func(t *testing.T) { runPolicyUniqueness(t, mk) })
 	t.Run("ResourceType", func(t *testing.T) { runResourceTypeUniqueness(t, mk) })
 	t.Run("Assignment", func(t *testing.T) { runAssignmentUniqueness(t, mk) })
+	t.Run("Calendar", func(t *testing.T) { runCalendarUniqueness(t, mk) })
+	t.Run("SoDConstraint", func(t *testing.T) { runSoDConstraintUniqueness(t, mk) })
 }
 
 // ───── Role ─────
@@ -54,10 +58,10 @@ func runRoleUniqueness(t *testing.T, mk MakeStore) {
 			t.Fatalf("first create: %v", err)
 		}
 		err := s.CreateRole(ctx, mkRole())
-		if !errors.Is(err, warden.ErrDuplicateRole) {
+		if !errors.Is(err, wardenerr.ErrDuplicateRole) {
 			t.Fatalf("expected ErrDuplicateRole, got %v", err)
 		}
-		if !errors.Is(err, warden.ErrAlreadyExists) {
+		if !errors.Is(err, wardenerr.ErrAlreadyExists) {
 			t.Fatalf("expected error to wrap ErrAlreadyExists, got %v", err)
 		}
 	})
@@ -111,7 +115,7 @@ func runPermissionUniqueness(t *testing.T, mk MakeStore) {
 			t.Fatalf("first create: %v", err)
 		}
 		err := s.CreatePermission(ctx, mkPerm())
-		if !errors.Is(err, warden.ErrDuplicatePermission) {
+		if !errors.Is(err, wardenerr.ErrDuplicatePermission) {
 			t.Fatalf("expected ErrDuplicatePermission, got %v", err)
 		}
 	})
@@ -157,7 +161,7 @@ func runPolicyUniqueness(t *testing.T, mk MakeStore) {
 			t.Fatalf("first create: %v", err)
 		}
 		err := s.CreatePolicy(ctx, mkPol())
-		if !errors.Is(err, warden.ErrDuplicatePolicy) {
+		if !errors.Is(err, wardenerr.ErrDuplicatePolicy) {
 			t.Fatalf("expected ErrDuplicatePolicy, got %v", err)
 		}
 	})
@@ -185,12 +189,66 @@ func runResourceTypeUniqueness(t *testing.T, mk MakeStore) {
 			t.Fatalf("first create: %v", err)
 		}
 		err := s.CreateResourceType(ctx, mkRT())
-		if !errors.Is(err, warden.ErrDuplicateResourceType) {
+		if !errors.Is(err, wardenerr.ErrDuplicateResourceType) {
 			t.Fatalf("expected ErrDuplicateResourceType, got %v", err)
 		}
 	})
 }
 
+// ───── Calendar ─────
+
+func runCalendarUniqueness(t *testing.T, mk MakeStore) {
+	t.Run("DuplicateInSameTenant_Rejected", func(t *testing.T) {
+		s, cleanup := mk(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		mkCal := func(tenantID string) *calendar.Calendar {
+			return &calendar.Calendar{
+				ID: id.NewCalendarID(), TenantID: tenantID, Name: "holidays",
+				Dates: []calendar.Date{{Date: "12-25", Name: "Christmas"}},
+			}
+		}
+		if err := s.CreateCalendar(ctx, mkCal("t1")); err != nil {
+			t.Fatalf("first create: %v", err)
+		}
+		err := s.CreateCalendar(ctx, mkCal("t1"))
+		if !errors.Is(err, wardenerr.ErrDuplicateCalendar) {
+			t.Fatalf("expected ErrDuplicateCalendar, got %v", err)
+		}
+		if err := s.CreateCalendar(ctx, mkCal("t2")); err != nil {
+			t.Fatalf("same name in another tenant: %v", err)
+		}
+	})
+}
+
+// ───── SoD constraint ─────
+
+func runSoDConstraintUniqueness(t *testing.T, mk MakeStore) {
+	t.Run("DuplicateInSameTenant_Rejected", func(t *testing.T) {
+		s, cleanup := mk(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		mkSoD := func(tenantID string) *sod.Constraint {
+			return &sod.Constraint{
+				ID: id.NewSoDConstraintID(), TenantID: tenantID, Name: "request-approve",
+				Kind: sod.KindStatic, Roles: []string{"requester", "approver"},
+			}
+		}
+		if err := s.CreateSoDConstraint(ctx, mkSoD("t1")); err != nil {
+			t.Fatalf("first create: %v", err)
+		}
+		err := s.CreateSoDConstraint(ctx, mkSoD("t1"))
+		if !errors.Is(err, wardenerr.ErrDuplicateSoDConstraint) {
+			t.Fatalf("expected ErrDuplicateSoDConstraint, got %v", err)
+		}
+		if err := s.CreateSoDConstraint(ctx, mkSoD("t2")); err != nil {
+			t.Fatalf("same name in another tenant: %v", err)
+		}
+	})
+}
+
 // ───── Assignment ─────
 
 func runAssignmentUniqueness(t *testing.T, mk MakeStore) {
@@ -214,7 +272,7 @@ func runAssignmentUniqueness(t *testing.T, mk MakeStore) {
 			t.Fatalf("first create: %v", err)
 		}
 		err := s.CreateAssignment(ctx, mkAsg())
-		if !errors.Is(err, warden.ErrDuplicateAssignment) {
+		if !errors.Is(err, wardenerr.ErrDuplicateAssignment) {
 			t.Fatalf("expected ErrDuplicateAssignment, got %v", err)
 		}
 	})
@@ -0,0 +1,218 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/webhook"
+)
+
+// RunWebhookOutboxContract asserts that the webhook outbox behaves the
+// same in every backend:
+//
+//   - Claims return only pending deliveries that are due, oldest first
+//     and up to the limit, leased to the claiming dispatcher.
+//   - A leased delivery is not claimed again until its lease expires.
+//   - Only the dispatcher holding an unexpired lease records an outcome;
+//     anyone else gets webhook.ErrLeaseLost.
+//   - A failed attempt rescheduled with a backoff is claimed again once
+//     it is due, with its attempts, last error and payload intact.
+//   - Purging removes only finished deliveries last updated before the
+//     cutoff.
+func RunWebhookOutboxContract(t *testing.T, mk MakeStore) {
+	t.Helper()
+
+	t.Run("Claim", func(t *testing.T) { runWebhookClaim(t, mk) })
+	t.Run("Lease", func(t *testing.T) { runWebhookLease(t, mk) })
+	t.Run("Retry", func(t *testing.T) { runWebhookRetry(t, mk) })
+	t.Run("Purge", func(t *testing.T) { runWebhookPurge(t, mk) })
+}
+
+// webhookBase is a fixed instant, truncated to the millisecond every
+// backend stores, that the outbox cases schedule deliveries around.
+func webhookBase() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func enqueue(ctx context.Context, t *testing.T, s store.Store, createdAt, nextAttemptAt time.Time) *webhook.Delivery {
+	t.Helper()
+	d := &webhook.Delivery{
+		ID: id.NewWebhookDeliveryID(), Endpoint: "ops", Event: webhook.EventRoleCreated,
+		Payload: json.RawMessage(`{"id":"x","event":"role.created"}`),
+		Status:  webhook.StatusPending, NextAttemptAt: nextAttemptAt,
+		CreatedAt: createdAt, UpdatedAt: createdAt,
+	}
+	if err := s.CreateWebhookDelivery(ctx, d); err != nil {
+		t.Fatalf("CreateWebhookDelivery: %v", err)
+	}
+	return d
+}
+
+func deliveryIDs(ds []*webhook.Delivery) []string {
+	out := make([]string, len(ds))
+	for i, d := range ds {
+		out[i] = d.ID.String()
+	}
+	return out
+}
+
+func runWebhookClaim(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := webhookBase()
+
+	second := enqueue(ctx, t, s, now.Add(-time.Minute), now)
+	first := enqueue(ctx, t, s, now.Add(-time.Hour), now.Add(-time.Second))
+	enqueue(ctx, t, s, now.Add(-2*time.Hour), now.Add(time.Minute)) // not yet due
+	third := enqueue(ctx, t, s, now, now)
+
+	lockUntil := now.Add(time.Minute)
+	due, err := s.ClaimDueWebhookDeliveries(ctx, "a", now, lockUntil, 2)
+	if err != nil {
+		t.Fatalf("ClaimDueWebhookDeliveries: %v", err)
+	}
+	if got, want := deliveryIDs(due), deliveryIDs([]*webhook.Delivery{first, second}); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected the two oldest due deliveries, got %v want %v", got, want)
+	}
+	for _, d := range due {
+		if d.LockedBy != "a" || d.LockedUntil == nil || !d.LockedUntil.Equal(lockUntil) {
+			t.Fatalf("expected the claim leased to a until %s, got %q until %v", lockUntil, d.LockedBy, d.LockedUntil)
+		}
+	}
+
+	rest, err := s.ClaimDueWebhookDeliveries(ctx, "b", now, lockUntil, 0)
+	if err != nil {
+		t.Fatalf("ClaimDueWebhookDeliveries: %v", err)
+	}
+	if got := deliveryIDs(rest); len(got) != 1 || got[0] != third.ID.String() {
+		t.Fatalf("expected a limit of 0 to claim the remaining due delivery, got %v", got)
+	}
+	if got := string(rest[0].Payload); got != string(third.Payload) {
+		t.Fatalf("payload = %s, want %s", got, third.Payload)
+	}
+}
+
+func runWebhookLease(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := webhookBase()
+	lease := time.Minute
+
+	d := enqueue(ctx, t, s, now, now)
+	if due, err := s.ClaimDueWebhookDeliveries(ctx, "a", now, now.Add(lease), 10); err != nil || len(due) != 1 {
+		t.Fatalf("claim by a = %d deliveries, %v; want 1", len(due), err)
+	}
+	if due, err := s.ClaimDueWebhookDeliveries(ctx, "b", now.Add(lease/2), now.Add(lease/2+lease), 10); err != nil || len(due) != 0 {
+		t.Fatalf("claim by b during a's lease = %d deliveries, %v; want 0", len(due), err)
+	}
+
+	// a's lease expires and b claims the delivery.
+	later := now.Add(lease)
+	if due, err := s.ClaimDueWebhookDeliveries(ctx, "b", later, later.Add(lease), 10); err != nil || len(due) != 1 {
+		t.Fatalf("claim by b after a's lease = %d deliveries, %v; want 1", len(due), err)
+	}
+
+	outcome := func(at time.Time) *webhook.Delivery {
+		out := *d
+		out.Status, out.Attempts, out.UpdatedAt = webhook.StatusDelivered, 1, at
+		out.LockedBy, out.LockedUntil = "", nil
+		return &out
+	}
+	if err := s.UpdateWebhookDelivery(ctx, "a", outcome(later)); !errors.Is(err, webhook.ErrLeaseLost) {
+		t.Fatalf("update by a after losing the lease: expected ErrLeaseLost, got %v", err)
+	}
+	if err := s.UpdateWebhookDelivery(ctx, "b", outcome(later.Add(2*lease))); !errors.Is(err, webhook.ErrLeaseLost) {
+		t.Fatalf("update by b after its lease expired: expected ErrLeaseLost, got %v", err)
+	}
+	if err := s.UpdateWebhookDelivery(ctx, "b", outcome(later.Add(lease/2))); err != nil {
+		t.Fatalf("update by b during its lease: %v", err)
+	}
+	if due, err := s.ClaimDueWebhookDeliveries(ctx, "c", later.Add(time.Hour), later.Add(2*time.Hour), 10); err != nil || len(due) != 0 {
+		t.Fatalf("claim after delivery = %d deliveries, %v; want 0", len(due), err)
+	}
+}
+
+func runWebhookRetry(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := webhookBase()
+
+	d := enqueue(ctx, t, s, now, now)
+	due, err := s.ClaimDueWebhookDeliveries(ctx, "a", now, now.Add(time.Minute), 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("claim = %d deliveries, %v; want 1", len(due), err)
+	}
+	failed := due[0]
+	failed.Attempts = 1
+	failed.LastError = "endpoint responded 503 Service Unavailable"
+	failed.NextAttemptAt = now.Add(10 * time.Second)
+	failed.UpdatedAt = now.Add(time.Second)
+	failed.LockedBy, failed.LockedUntil = "", nil
+	if err := s.UpdateWebhookDelivery(ctx, "a", failed); err != nil {
+		t.Fatalf("UpdateWebhookDelivery: %v", err)
+	}
+
+	if due, err := s.ClaimDueWebhookDeliveries(ctx, "b", now.Add(5*time.Second), now.Add(time.Minute), 10); err != nil || len(due) != 0 {
+		t.Fatalf("claim during the backoff = %d deliveries, %v; want 0", len(due), err)
+	}
+	retryAt := now.Add(10 * time.Second)
+	due, err = s.ClaimDueWebhookDeliveries(ctx, "b", retryAt, retryAt.Add(time.Minute), 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("claim after the backoff = %d deliveries, %v; want 1", len(due), err)
+	}
+	got := due[0]
+	if got.ID != d.ID || got.Attempts != 1 || got.LastError != failed.LastError || got.Status != webhook.StatusPending {
+		t.Fatalf("retried delivery = %+v", got)
+	}
+	if !got.NextAttemptAt.Equal(failed.NextAttemptAt) || string(got.Payload) != string(d.Payload) {
+		t.Fatalf("retried delivery next attempt %s payload %s, want %s and %s",
+			got.NextAttemptAt, got.Payload, failed.NextAttemptAt, d.Payload)
+	}
+}
+
+func runWebhookPurge(t *testing.T, mk MakeStore) {
+	s, cleanup := mk(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := webhookBase()
+
+	finish := func(status webhook.Status, at time.Time) {
+		t.Helper()
+		d := enqueue(ctx, t, s, now.Add(-time.Hour), now.Add(-time.Hour))
+		due, err := s.ClaimDueWebhookDeliveries(ctx, "a", now, now.Add(time.Minute), 1)
+		if err != nil || len(due) != 1 || due[0].ID != d.ID {
+			t.Fatalf("claim = %v, %v; want %s", deliveryIDs(due), err, d.ID)
+		}
+		d.Status, d.Attempts, d.UpdatedAt = status, 1, at
+		if err := s.UpdateWebhookDelivery(ctx, "a", d); err != nil {
+			t.Fatalf("UpdateWebhookDelivery: %v", err)
+		}
+	}
+	finish(webhook.StatusDelivered, now.Add(-2*time.Minute))
+	finish(webhook.StatusFailed, now.Add(-2*time.Minute))
+	finish(webhook.StatusDelivered, now.Add(30*time.Second)) // after the cutoff
+	pending := enqueue(ctx, t, s, now.Add(-time.Hour), now.Add(time.Hour))
+
+	n, err := s.PurgeWebhookDeliveries(ctx, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("PurgeWebhookDeliveries: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("purged %d deliveries, want 2", n)
+	}
+	if n, err := s.PurgeWebhookDeliveries(ctx, now.Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("second purge = %d, %v; want 1", n, err)
+	}
+	due, err := s.ClaimDueWebhookDeliveries(ctx, "a", now.Add(2*time.Hour), now.Add(3*time.Hour), 10)
+	if err != nil || len(due) != 1 || due[0].ID != pending.ID {
+		t.Fatalf("expected the pending delivery to survive purges, got %v, %v", deliveryIDs(due), err)
+	}
+}
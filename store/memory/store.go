@@ -5,30 +5,45 @@ package memory
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/counter"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
 	"github.com/xraph/warden/wardenerr"
+	"github.com/xraph/warden/webhook"
 )
 
 // Compile-time interface checks.
 var (
-	_ role.Store         = (*Store)(nil)
-	_ permission.Store   = (*Store)(nil)
-	_ assignment.Store   = (*Store)(nil)
-	_ relation.Store     = (*Store)(nil)
-	_ policy.Store       = (*Store)(nil)
-	_ resourcetype.Store = (*Store)(nil)
-	_ checklog.Store     = (*Store)(nil)
+	_ role.Store          = (*Store)(nil)
+	_ permission.Store    = (*Store)(nil)
+	_ assignment.Store    = (*Store)(nil)
+	_ relation.Store      = (*Store)(nil)
+	_ policy.Store        = (*Store)(nil)
+	_ resourcetype.Store  = (*Store)(nil)
+	_ checklog.Store      = (*Store)(nil)
+	_ checklog.StatsStore = (*Store)(nil)
+	_ webhook.Store       = (*Store)(nil)
+	_ audit.Store         = (*Store)(nil)
+	_ breakglass.Store    = (*Store)(nil)
+	_ counter.Store       = (*Store)(nil)
+	_ sod.Store           = (*Store)(nil)
 )
 
 // Store is a thread-safe in-memory store for all Warden entities.
@@ -48,6 +63,18 @@ type Store struct {
 	policies        map[string]*policy.Policy
 	resourceTypes   map[string]*resourcetype.ResourceType
 	checkLogs       map[string]*checklog.Entry
+	webhooks        map[string]*webhook.Delivery
+	// auditChains holds each tenant's audit entries in ascending Seq order.
+	auditChains map[string][]*audit.Entry
+	breakGlass  map[string]*breakglass.Grant
+	calendars   map[string]*calendar.Calendar
+	sod         map[string]*sod.Constraint
+	// counters is the same implementation the engine counts in by
+	// default, so persisted and in-memory counters agree.
+	counters *counter.Memory
+
+	// policyChanges reports policy writes to the engine's policy index.
+	policyChanges *policy.ChangeFeed
 }
 
 // New creates a new in-memory store.
@@ -61,6 +88,13 @@ func New() *Store {
 		policies:        make(map[string]*policy.Policy),
 		resourceTypes:   make(map[string]*resourcetype.ResourceType),
 		checkLogs:       make(map[string]*checklog.Entry),
+		webhooks:        make(map[string]*webhook.Delivery),
+		auditChains:     make(map[string][]*audit.Entry),
+		breakGlass:      make(map[string]*breakglass.Grant),
+		calendars:       make(map[string]*calendar.Calendar),
+		sod:             make(map[string]*sod.Constraint),
+		counters:        counter.NewMemory(),
+		policyChanges:   policy.NewChangeFeed(nil, 0),
 	}
 }
 
@@ -467,7 +501,7 @@ func (s *Store) DeletePermissionsByTenant(_ context.Context, tenantID string) er
 // Assignment Store
 // ──────────────────────────────────────────────────
 
-func (s *Store) CreateAssignment(_ context.Context, a *assignment.Assignment) error {
+func (s *Store) CreateAssignment(ctx context.Context, a *assignment.Assignment) error {
 	if a.ID.IsNil() {
 		a.ID = id.NewAssignmentID()
 	}
@@ -489,10 +523,60 @@ func (s *Store) CreateAssignment(_ context.Context, a *assignment.Assignment) er
 				wardenerr.ErrDuplicateAssignment)
 		}
 	}
+	// Checked under the same lock as the insert, so concurrent assignments
+	// cannot both pass.
+	if err := sod.CheckAssignment(ctx, lockedReader{s}, a, time.Now()); err != nil {
+		return err
+	}
 	s.assignments[a.ID.String()] = copyAssignment(a)
 	return nil
 }
 
+// lockedReader reads the store for sod.CheckAssignment while the caller
+// holds s.mu.
+type lockedReader struct{ s *Store }
+
+func (r lockedReader) GetRole(_ context.Context, roleID id.RoleID) (*role.Role, error) {
+	rl, ok := r.s.roles[roleID.String()]
+	if !ok {
+		return nil, fmt.Errorf("role %s: %w", roleID, errNotFound)
+	}
+	return rl, nil
+}
+
+func (r lockedReader) GetRoleBySlug(_ context.Context, tenantID, namespacePath, slug string) (*role.Role, error) {
+	for _, rl := range r.s.roles {
+		if rl.TenantID == tenantID && rl.NamespacePath == namespacePath && rl.Slug == slug {
+			return rl, nil
+		}
+	}
+	return nil, fmt.Errorf("role slug %q in ns %q: %w", slug, namespacePath, errNotFound)
+}
+
+func (r lockedReader) ListAssignments(_ context.Context, filter *assignment.ListFilter) ([]*assignment.Assignment, error) {
+	var result []*assignment.Assignment
+	for _, a := range r.s.assignments {
+		if a.TenantID != filter.TenantID ||
+			(filter.RoleID != nil && a.RoleID != *filter.RoleID) ||
+			(filter.SubjectKind != "" && a.SubjectKind != filter.SubjectKind) ||
+			(filter.SubjectID != "" && a.SubjectID != filter.SubjectID) {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+func (r lockedReader) ListSoDConstraints(_ context.Context, filter *sod.ListFilter) ([]*sod.Constraint, error) {
+	var result []*sod.Constraint
+	for _, c := range r.s.sod {
+		if filter.Matches(c) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
 func (s *Store) GetAssignment(_ context.Context, assID id.AssignmentID) (*assignment.Assignment, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -809,6 +893,7 @@ func (s *Store) CreatePolicy(_ context.Context, p *policy.Policy) error {
 		}
 	}
 	s.policies[p.ID.String()] = copyPolicy(p)
+	s.notifyPolicyChange(p.TenantID)
 	return nil
 }
 
@@ -840,13 +925,17 @@ func (s *Store) UpdatePolicy(_ context.Context, p *policy.Policy) error {
 		return fmt.Errorf("policy %s: %w", p.ID, errNotFound)
 	}
 	s.policies[p.ID.String()] = copyPolicy(p)
+	s.notifyPolicyChange(p.TenantID)
 	return nil
 }
 
 func (s *Store) DeletePolicy(_ context.Context, polID id.PolicyID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	delete(s.policies, polID.String())
+	if p, ok := s.policies[polID.String()]; ok {
+		delete(s.policies, polID.String())
+		s.notifyPolicyChange(p.TenantID)
+	}
 	return nil
 }
 
@@ -918,9 +1007,22 @@ func (s *Store) DeletePoliciesByTenant(_ context.Context, tenantID string) error
 			delete(s.policies, k)
 		}
 	}
+	s.notifyPolicyChange(tenantID)
 	return nil
 }
 
+// NotifyPolicyChanges implements policy.ChangeNotifier.
+func (s *Store) NotifyPolicyChanges(fn func(tenantID string)) (stop func()) {
+	return s.policyChanges.NotifyPolicyChanges(fn)
+}
+
+// notifyPolicyChange calls the registered watchers. Callers hold the write
+// lock, so a reader that loaded policies before the change has finished
+// doing so; watchers must not call back into the store.
+func (s *Store) notifyPolicyChange(tenantID string) {
+	s.policyChanges.Notify(tenantID)
+}
+
 // ──────────────────────────────────────────────────
 // Resource Type Store
 // ──────────────────────────────────────────────────
@@ -1073,18 +1175,51 @@ func (s *Store) ListCheckLogs(_ context.Context, filter *checklog.QueryFilter) (
 			if filter.ResourceType != "" && e.ResourceType != filter.ResourceType {
 				continue
 			}
+			if filter.ResourceID != "" && e.ResourceID != filter.ResourceID {
+				continue
+			}
 			if filter.Decision != "" && e.Decision != filter.Decision {
 				continue
 			}
+			if filter.NamespacePath != nil && e.NamespacePath != *filter.NamespacePath {
+				continue
+			}
+			if filter.NamespacePrefix != "" && !strings.HasPrefix(e.NamespacePath, filter.NamespacePrefix) {
+				continue
+			}
+			if filter.RequestIP != "" && e.RequestIP != filter.RequestIP {
+				continue
+			}
+			if filter.RequestID != "" && e.RequestID != filter.RequestID {
+				continue
+			}
+			if (filter.MatchedSource != "" || filter.MatchedRuleID != "") &&
+				!checkLogMatched(e, filter.MatchedSource, filter.MatchedRuleID) {
+				continue
+			}
+			if filter.Obligation != "" && !slices.Contains(e.Obligations, filter.Obligation) {
+				continue
+			}
 			if filter.After != nil && e.CreatedAt.Before(*filter.After) {
 				continue
 			}
 			if filter.Before != nil && e.CreatedAt.After(*filter.Before) {
 				continue
 			}
+			if filter.Cursor != nil && !filter.Cursor.Before(e) {
+				continue
+			}
 		}
 		result = append(result, copyCheckLog(e))
 	}
+	// Newest first, then by descending ID, matching the SQL stores.
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID.String() > b.ID.String()
+	})
 	return applyPaginationCL(result, paginationOptsCL(filter)), nil
 }
 
@@ -1096,6 +1231,16 @@ func (s *Store) CountCheckLogs(ctx context.Context, filter *checklog.QueryFilter
 	return int64(len(list)), nil
 }
 
+func (s *Store) CheckLogStats(ctx context.Context, q *checklog.StatsQuery) (*checklog.Stats, error) {
+	f := q.Filter
+	f.Limit, f.Offset = 0, 0
+	list, err := s.ListCheckLogs(ctx, &f)
+	if err != nil {
+		return nil, err
+	}
+	return checklog.ComputeStats(list, q), nil
+}
+
 func (s *Store) PurgeCheckLogs(_ context.Context, before time.Time) (int64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -1109,6 +1254,20 @@ func (s *Store) PurgeCheckLogs(_ context.Context, before time.Time) (int64, erro
 	return count, nil
 }
 
+func (s *Store) DeleteCheckLogs(_ context.Context, ids []id.CheckLogID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for _, clid := range ids {
+		k := clid.String()
+		if _, ok := s.checkLogs[k]; ok {
+			delete(s.checkLogs, k)
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (s *Store) DeleteCheckLogsByTenant(_ context.Context, tenantID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -1120,11 +1279,406 @@ func (s *Store) DeleteCheckLogsByTenant(_ context.Context, tenantID string) erro
 	return nil
 }
 
+// ──────────────────────────────────────────────────
+// Webhook Outbox Store
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateWebhookDelivery(_ context.Context, d *webhook.Delivery) error {
+	if d.ID.IsNil() {
+		d.ID = id.NewWebhookDeliveryID()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhooks[d.ID.String()] = copyWebhookDelivery(d)
+	return nil
+}
+
+func (s *Store) ClaimDueWebhookDeliveries(_ context.Context, owner string, now, lockUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*webhook.Delivery
+	for _, d := range s.webhooks {
+		if d.Status == webhook.StatusPending && !d.NextAttemptAt.After(now) && (d.LockedUntil == nil || !d.LockedUntil.After(now)) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].CreatedAt.Equal(due[j].CreatedAt) {
+			return due[i].CreatedAt.Before(due[j].CreatedAt)
+		}
+		return due[i].ID.String() < due[j].ID.String()
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	result := make([]*webhook.Delivery, len(due))
+	for i, d := range due {
+		d.LockedBy, d.LockedUntil = owner, &lockUntil
+		result[i] = copyWebhookDelivery(d)
+	}
+	return result, nil
+}
+
+func (s *Store) UpdateWebhookDelivery(_ context.Context, owner string, d *webhook.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := d.ID.String()
+	cur, ok := s.webhooks[k]
+	if !ok {
+		return fmt.Errorf("webhook delivery %s: %w", d.ID, errNotFound)
+	}
+	if cur.LockedBy != owner || cur.LockedUntil == nil || !cur.LockedUntil.After(d.UpdatedAt) {
+		return fmt.Errorf("webhook delivery %s: %w", d.ID, webhook.ErrLeaseLost)
+	}
+	s.webhooks[k] = copyWebhookDelivery(d)
+	return nil
+}
+
+func (s *Store) PurgeWebhookDeliveries(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for k, d := range s.webhooks {
+		if d.Status != webhook.StatusPending && d.UpdatedAt.Before(before) {
+			delete(s.webhooks, k)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ──────────────────────────────────────────────────
+// Audit Trail Store
+// ──────────────────────────────────────────────────
+
+func (s *Store) AppendAuditEntry(_ context.Context, e *audit.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chain := s.auditChains[e.TenantID]
+	if n := len(chain); n > 0 && chain[n-1].Seq >= e.Seq {
+		return fmt.Errorf("audit entry %d in tenant %q: %w", e.Seq, e.TenantID, audit.ErrSequenceConflict)
+	}
+	s.auditChains[e.TenantID] = append(chain, copyAuditEntry(e))
+	return nil
+}
+
+func (s *Store) LastAuditEntry(_ context.Context, tenantID string) (*audit.Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	chain := s.auditChains[tenantID]
+	if len(chain) == 0 {
+		return nil, nil //nolint:nilnil // empty chain
+	}
+	return copyAuditEntry(chain[len(chain)-1]), nil
+}
+
+func (s *Store) ListAuditEntries(_ context.Context, filter *audit.QueryFilter) ([]*audit.Entry, error) {
+	if filter == nil {
+		filter = &audit.QueryFilter{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []*audit.Entry
+	for _, e := range s.auditChains[filter.TenantID] {
+		if filter.Matches(e) {
+			result = append(result, copyAuditEntry(e))
+		}
+	}
+	if !filter.Ascending {
+		slices.Reverse(result)
+	}
+	return applyPagination(result, pagOpts{limit: filter.Limit, offset: filter.Offset}), nil
+}
+
+func (s *Store) CountAuditEntries(_ context.Context, filter *audit.QueryFilter) (int64, error) {
+	if filter == nil {
+		filter = &audit.QueryFilter{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var count int64
+	for _, e := range s.auditChains[filter.TenantID] {
+		if filter.Matches(e) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ──────────────────────────────────────────────────
+// Break-Glass Store
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateBreakGlassGrant(_ context.Context, g *breakglass.Grant) error {
+	if g.ID.IsNil() {
+		g.ID = id.NewBreakGlassID()
+	}
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now().UTC()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakGlass[g.ID.String()] = copyBreakGlassGrant(g)
+	return nil
+}
+
+func (s *Store) GetBreakGlassGrant(_ context.Context, grantID id.BreakGlassID) (*breakglass.Grant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	g, ok := s.breakGlass[grantID.String()]
+	if !ok {
+		return nil, fmt.Errorf("break-glass grant %s: %w", grantID, errNotFound)
+	}
+	return copyBreakGlassGrant(g), nil
+}
+
+func (s *Store) UpdateBreakGlassGrant(_ context.Context, g *breakglass.Grant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := g.ID.String()
+	if _, ok := s.breakGlass[k]; !ok {
+		return fmt.Errorf("break-glass grant %s: %w", g.ID, errNotFound)
+	}
+	s.breakGlass[k] = copyBreakGlassGrant(g)
+	return nil
+}
+
+func (s *Store) ListBreakGlassGrants(_ context.Context, filter *breakglass.ListFilter) ([]*breakglass.Grant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []*breakglass.Grant
+	for _, g := range s.breakGlass {
+		if filter.Matches(g) {
+			result = append(result, copyBreakGlassGrant(g))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID.String() > result[j].ID.String()
+	})
+	if filter == nil {
+		return result, nil
+	}
+	return applyPagination(result, pagOpts{limit: filter.Limit, offset: filter.Offset}), nil
+}
+
+// ──────────────────────────────────────────────────
+// Calendar Store
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateCalendar(_ context.Context, c *calendar.Calendar) error {
+	if c.ID.IsNil() {
+		c.ID = id.NewCalendarID()
+	}
+	now := time.Now().UTC()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = now
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.calendars {
+		if existing.TenantID == c.TenantID && existing.Name == c.Name {
+			return fmt.Errorf("calendar %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateCalendar)
+		}
+	}
+	s.calendars[c.ID.String()] = copyCalendar(c)
+	return nil
+}
+
+func (s *Store) GetCalendar(_ context.Context, calID id.CalendarID) (*calendar.Calendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.calendars[calID.String()]
+	if !ok {
+		return nil, fmt.Errorf("calendar %s: %w", calID, errNotFound)
+	}
+	return copyCalendar(c), nil
+}
+
+func (s *Store) GetCalendarByName(_ context.Context, tenantID, name string) (*calendar.Calendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.calendars {
+		if c.TenantID == tenantID && c.Name == name {
+			return copyCalendar(c), nil
+		}
+	}
+	return nil, fmt.Errorf("calendar %q in tenant %q: %w", name, tenantID, errNotFound)
+}
+
+func (s *Store) UpdateCalendar(_ context.Context, c *calendar.Calendar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.calendars[c.ID.String()]; !ok {
+		return fmt.Errorf("calendar %s: %w", c.ID, errNotFound)
+	}
+	for k, existing := range s.calendars {
+		if k != c.ID.String() && existing.TenantID == c.TenantID && existing.Name == c.Name {
+			return fmt.Errorf("calendar %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateCalendar)
+		}
+	}
+	s.calendars[c.ID.String()] = copyCalendar(c)
+	return nil
+}
+
+func (s *Store) DeleteCalendar(_ context.Context, calID id.CalendarID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.calendars, calID.String())
+	return nil
+}
+
+func (s *Store) ListCalendars(_ context.Context, filter *calendar.ListFilter) ([]*calendar.Calendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []*calendar.Calendar
+	for _, c := range s.calendars {
+		if filter.Matches(c) {
+			result = append(result, copyCalendar(c))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	if filter == nil {
+		return result, nil
+	}
+	return applyPagination(result, pagOpts{limit: filter.Limit, offset: filter.Offset}), nil
+}
+
+func (s *Store) DeleteCalendarsByTenant(_ context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, c := range s.calendars {
+		if c.TenantID == tenantID {
+			delete(s.calendars, k)
+		}
+	}
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// SoD Constraint Store
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateSoDConstraint(_ context.Context, c *sod.Constraint) error {
+	if c.ID.IsNil() {
+		c.ID = id.NewSoDConstraintID()
+	}
+	now := time.Now().UTC()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = now
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.sod {
+		if existing.TenantID == c.TenantID && existing.Name == c.Name {
+			return fmt.Errorf("sod constraint %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateSoDConstraint)
+		}
+	}
+	s.sod[c.ID.String()] = copySoDConstraint(c)
+	s.notifyPolicyChange(c.TenantID)
+	return nil
+}
+
+func (s *Store) GetSoDConstraint(_ context.Context, sodID id.SoDConstraintID) (*sod.Constraint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.sod[sodID.String()]
+	if !ok {
+		return nil, fmt.Errorf("sod constraint %s: %w", sodID, errNotFound)
+	}
+	return copySoDConstraint(c), nil
+}
+
+func (s *Store) UpdateSoDConstraint(_ context.Context, c *sod.Constraint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sod[c.ID.String()]; !ok {
+		return fmt.Errorf("sod constraint %s: %w", c.ID, errNotFound)
+	}
+	for k, existing := range s.sod {
+		if k != c.ID.String() && existing.TenantID == c.TenantID && existing.Name == c.Name {
+			return fmt.Errorf("sod constraint %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateSoDConstraint)
+		}
+	}
+	s.sod[c.ID.String()] = copySoDConstraint(c)
+	s.notifyPolicyChange(c.TenantID)
+	return nil
+}
+
+func (s *Store) DeleteSoDConstraint(_ context.Context, sodID id.SoDConstraintID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.sod[sodID.String()]; ok {
+		delete(s.sod, sodID.String())
+		s.notifyPolicyChange(c.TenantID)
+	}
+	return nil
+}
+
+func (s *Store) ListSoDConstraints(_ context.Context, filter *sod.ListFilter) ([]*sod.Constraint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []*sod.Constraint
+	for _, c := range s.sod {
+		if filter.Matches(c) {
+			result = append(result, copySoDConstraint(c))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	if filter == nil {
+		return result, nil
+	}
+	return applyPagination(result, pagOpts{limit: filter.Limit, offset: filter.Offset}), nil
+}
+
+func (s *Store) DeleteSoDConstraintsByTenant(_ context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, c := range s.sod {
+		if c.TenantID == tenantID {
+			delete(s.sod, k)
+		}
+	}
+	s.notifyPolicyChange(tenantID)
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Counter Store
+// ──────────────────────────────────────────────────
+
+func (s *Store) IncrementCounter(ctx context.Context, key counter.Key, bucket time.Time, n int64) error {
+	return s.counters.IncrementCounter(ctx, key, bucket, n)
+}
+
+func (s *Store) SumCounter(ctx context.Context, key counter.Key, since time.Time) (int64, error) {
+	return s.counters.SumCounter(ctx, key, since)
+}
+
+func (s *Store) PurgeCounters(ctx context.Context, before time.Time) (int64, error) {
+	return s.counters.PurgeCounters(ctx, before)
+}
+
+func (s *Store) DeleteCountersByTenant(ctx context.Context, tenantID string) error {
+	return s.counters.DeleteCountersByTenant(ctx, tenantID)
+}
+
 // ──────────────────────────────────────────────────
 // Helpers
 // ──────────────────────────────────────────────────
 
-var errNotFound = fmt.Errorf("not found")
+var errNotFound = wardenerr.ErrNotFound
 
 // nsHasPrefix reports whether path is namespace prefix or one of its descendants.
 // "" matches anything; "eng" matches "eng" and "eng/...".
@@ -1206,6 +1760,8 @@ func copyPolicy(p *policy.Policy) *policy.Policy {
 		c.Obligations = make([]string, len(p.Obligations))
 		copy(c.Obligations, p.Obligations)
 	}
+	c.StructuredObligations = copyObligations(p.StructuredObligations)
+	c.Advice = copyObligations(p.Advice)
 	if p.NotBefore != nil {
 		v := *p.NotBefore
 		c.NotBefore = &v
@@ -1217,6 +1773,18 @@ func copyPolicy(p *policy.Policy) *policy.Policy {
 	return &c
 }
 
+func copyObligations(obs []policy.Obligation) []policy.Obligation {
+	if obs == nil {
+		return nil
+	}
+	out := make([]policy.Obligation, len(obs))
+	for i, ob := range obs {
+		out[i] = ob
+		out[i].Params = maps.Clone(ob.Params)
+	}
+	return out
+}
+
 func copyResourceType(rt *resourcetype.ResourceType) *resourcetype.ResourceType {
 	c := *rt
 	if rt.Relations != nil {
@@ -1227,14 +1795,73 @@ func copyResourceType(rt *resourcetype.ResourceType) *resourcetype.ResourceType
 		c.Permissions = make([]resourcetype.PermissionDef, len(rt.Permissions))
 		copy(c.Permissions, rt.Permissions)
 	}
+	if rt.Attributes != nil {
+		c.Attributes = make([]resourcetype.AttributeDef, len(rt.Attributes))
+		copy(c.Attributes, rt.Attributes)
+	}
 	return &c
 }
 
 func copyCheckLog(e *checklog.Entry) *checklog.Entry {
 	c := *e
+	c.MatchedBy = slices.Clone(e.MatchedBy)
+	c.Obligations = slices.Clone(e.Obligations)
 	return &c
 }
 
+func copyAuditEntry(e *audit.Entry) *audit.Entry {
+	c := *e
+	c.Before = slices.Clone(e.Before)
+	c.After = slices.Clone(e.After)
+	return &c
+}
+
+func copyWebhookDelivery(d *webhook.Delivery) *webhook.Delivery {
+	c := *d
+	c.Payload = slices.Clone(d.Payload)
+	if d.LockedUntil != nil {
+		t := *d.LockedUntil
+		c.LockedUntil = &t
+	}
+	return &c
+}
+
+func copyCalendar(c *calendar.Calendar) *calendar.Calendar {
+	cp := *c
+	cp.Dates = slices.Clone(c.Dates)
+	cp.Metadata = maps.Clone(c.Metadata)
+	return &cp
+}
+
+func copySoDConstraint(c *sod.Constraint) *sod.Constraint {
+	cp := *c
+	cp.Roles = slices.Clone(c.Roles)
+	cp.Metadata = maps.Clone(c.Metadata)
+	return &cp
+}
+
+func copyBreakGlassGrant(g *breakglass.Grant) *breakglass.Grant {
+	c := *g
+	c.RoleIDs = slices.Clone(g.RoleIDs)
+	c.BypassPolicies = slices.Clone(g.BypassPolicies)
+	if g.RevokedAt != nil {
+		t := *g.RevokedAt
+		c.RevokedAt = &t
+	}
+	return &c
+}
+
+// checkLogMatched reports whether any MatchedBy entry matches the given
+// source and rule ID. Empty arguments match anything.
+func checkLogMatched(e *checklog.Entry, source, ruleID string) bool {
+	for _, m := range e.MatchedBy {
+		if (source == "" || m.Source == source) && (ruleID == "" || m.RuleID == ruleID) {
+			return true
+		}
+	}
+	return false
+}
+
 // Pagination helpers for each entity type.
 type pagOpts struct{ limit, offset int }
 
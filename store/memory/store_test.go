@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
@@ -14,11 +15,29 @@ import (
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
 	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/store/contract"
 )
 
 // Compile-time check that *Store implements store.Store.
 var _ store.Store = (*Store)(nil)
 
+// TestMemory_Contracts runs the shared store contracts against the memory
+// backend. Each contract gets a fresh store.
+func TestMemory_Contracts(t *testing.T) {
+	mk := func(_ *testing.T) (store.Store, func()) {
+		return New(), func() {}
+	}
+	t.Run("CheckLogQuery", func(t *testing.T) { contract.RunCheckLogQueryContract(t, mk) })
+	t.Run("Audit", func(t *testing.T) { contract.RunAuditContract(t, mk) })
+	t.Run("Counter", func(t *testing.T) { contract.RunCounterContract(t, mk) })
+	t.Run("SoD", func(t *testing.T) { contract.RunSoDContract(t, mk) })
+	t.Run("PolicyChange", func(t *testing.T) { contract.RunPolicyChangeContract(t, mk) })
+	t.Run("WebhookOutbox", func(t *testing.T) { contract.RunWebhookOutboxContract(t, mk) })
+	t.Run("PolicyRoundTrip", func(t *testing.T) { contract.RunPolicyRoundTripContract(t, mk) })
+	t.Run("BreakGlass", func(t *testing.T) { contract.RunBreakGlassContract(t, mk) })
+	t.Run("Calendar", func(t *testing.T) { contract.RunCalendarContract(t, mk) })
+}
+
 func TestRoleCRUD(t *testing.T) {
 	ctx := context.Background()
 	s := New()
@@ -356,6 +375,43 @@ func TestResourceTypeCRUD(t *testing.T) {
 	}
 }
 
+func TestCalendarCRUD(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	c := &calendar.Calendar{
+		TenantID: "t1",
+		Name:     "holidays",
+		Dates:    []calendar.Date{{Date: "12-25", Name: "Christmas"}},
+	}
+	if err := s.CreateCalendar(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.ID.IsNil() {
+		t.Fatal("expected an ID to be assigned")
+	}
+
+	got, err := s.GetCalendarByName(ctx, "t1", "holidays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Dates[0].Date = "01-01"
+	again, _ := s.GetCalendar(ctx, c.ID) //nolint:errcheck // checked below
+	if again == nil || again.Dates[0].Date != "12-25" {
+		t.Fatal("returned calendar shares state with the store")
+	}
+
+	if _, err := s.GetCalendarByName(ctx, "t2", "holidays"); err == nil {
+		t.Fatal("expected calendars to be scoped to their tenant")
+	}
+
+	_ = s.DeleteCalendarsByTenant(ctx, "t1")
+	list, _ := s.ListCalendars(ctx, &calendar.ListFilter{TenantID: "t1"}) //nolint:errcheck // empty on error
+	if len(list) != 0 {
+		t.Fatalf("expected no calendars, got %d", len(list))
+	}
+}
+
 func TestCheckLogCRUD(t *testing.T) {
 	ctx := context.Background()
 	s := New()
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/xraph/warden"
 	"github.com/xraph/warden/assignment"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
@@ -14,6 +13,7 @@ import (
 	"github.com/xraph/warden/role"
 	"github.com/xraph/warden/store"
 	"github.com/xraph/warden/store/contract"
+	"github.com/xraph/warden/wardenerr"
 )
 
 // TestMemory_UniquenessContract runs the shared backend-agnostic
@@ -46,10 +46,10 @@ func TestCreateRole_DuplicateInSameScope_Rejected(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected duplicate error, got nil")
 	}
-	if !errors.Is(err, warden.ErrDuplicateRole) {
+	if !errors.Is(err, wardenerr.ErrDuplicateRole) {
 		t.Fatalf("expected ErrDuplicateRole, got %v", err)
 	}
-	if !errors.Is(err, warden.ErrAlreadyExists) {
+	if !errors.Is(err, wardenerr.ErrAlreadyExists) {
 		t.Fatalf("expected error to wrap ErrAlreadyExists, got %v", err)
 	}
 }
@@ -97,10 +97,10 @@ func TestCreatePermission_DuplicateInSameScope_Rejected(t *testing.T) {
 		t.Fatal(err)
 	}
 	err := s.CreatePermission(ctx, mk())
-	if !errors.Is(err, warden.ErrDuplicatePermission) {
+	if !errors.Is(err, wardenerr.ErrDuplicatePermission) {
 		t.Fatalf("expected ErrDuplicatePermission, got %v", err)
 	}
-	if !errors.Is(err, warden.ErrAlreadyExists) {
+	if !errors.Is(err, wardenerr.ErrAlreadyExists) {
 		t.Fatalf("expected to wrap ErrAlreadyExists, got %v", err)
 	}
 }
@@ -132,7 +132,7 @@ func TestCreatePolicy_DuplicateInSameScope_Rejected(t *testing.T) {
 		t.Fatal(err)
 	}
 	err := s.CreatePolicy(ctx, mk())
-	if !errors.Is(err, warden.ErrDuplicatePolicy) {
+	if !errors.Is(err, wardenerr.ErrDuplicatePolicy) {
 		t.Fatalf("expected ErrDuplicatePolicy, got %v", err)
 	}
 }
@@ -150,7 +150,7 @@ func TestCreateResourceType_DuplicateInSameScope_Rejected(t *testing.T) {
 		t.Fatal(err)
 	}
 	err := s.CreateResourceType(ctx, mk())
-	if !errors.Is(err, warden.ErrDuplicateResourceType) {
+	if !errors.Is(err, wardenerr.ErrDuplicateResourceType) {
 		t.Fatalf("expected ErrDuplicateResourceType, got %v", err)
 	}
 }
@@ -170,7 +170,7 @@ func TestCreateAssignment_DuplicateInSameScope_Rejected(t *testing.T) {
 		t.Fatal(err)
 	}
 	err := s.CreateAssignment(ctx, mk())
-	if !errors.Is(err, warden.ErrDuplicateAssignment) {
+	if !errors.Is(err, wardenerr.ErrDuplicateAssignment) {
 		t.Fatalf("expected ErrDuplicateAssignment, got %v", err)
 	}
 }
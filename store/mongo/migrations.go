@@ -628,5 +628,222 @@ func init() {
 				return mexec.DropCollection(ctx, (*checkLogModel)(nil))
 			},
 		},
+		&migrate.Migration{
+			Name:    "check_log_provenance",
+			Version: "20260301000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.CreateIndexes(ctx, colCheckLogs, []mongo.IndexModel{
+					{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "request_id", Value: 1}}},
+					{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "namespace_path", Value: 1}}},
+					{Keys: bson.D{{Key: "matched_by.rule_id", Value: 1}}},
+					{Keys: bson.D{{Key: "obligations", Value: 1}}},
+				})
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				// Default index names, shared with Store.Migrate.
+				idx := mexec.DB().Database().Collection(colCheckLogs).Indexes()
+				for _, name := range []string{
+					"tenant_id_1_request_id_1",
+					"tenant_id_1_namespace_path_1",
+					"matched_by.rule_id_1",
+					"obligations_1",
+				} {
+					_ = idx.DropOne(ctx, name) //nolint:errcheck // idempotent drop, ok if missing
+				}
+				return nil
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_webhook_deliveries",
+			Version: "20260401000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+
+				if err := mexec.CreateCollection(ctx, (*webhookDeliveryModel)(nil)); err != nil {
+					return err
+				}
+
+				return mexec.CreateIndexes(ctx, colWebhookDeliveries, []mongo.IndexModel{
+					{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+					{Keys: bson.D{{Key: "updated_at", Value: 1}}},
+				})
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.DropCollection(ctx, (*webhookDeliveryModel)(nil))
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_audit_entries",
+			Version: "20260501000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+
+				if err := mexec.CreateCollection(ctx, (*auditEntryModel)(nil)); err != nil {
+					return err
+				}
+
+				return mexec.CreateIndexes(ctx, colAuditEntries, []mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "seq", Value: 1}},
+						Options: options.Index().SetUnique(true),
+					},
+					{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}}},
+					{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: 1}}},
+				})
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.DropCollection(ctx, (*auditEntryModel)(nil))
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_break_glass_grants",
+			Version: "20260801000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+
+				if err := mexec.CreateCollection(ctx, (*breakGlassGrantModel)(nil)); err != nil {
+					return err
+				}
+
+				return mexec.CreateIndexes(ctx, colBreakGlassGrants, []mongo.IndexModel{
+					{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "subject_kind", Value: 1}, {Key: "subject_id", Value: 1}, {Key: "expires_at", Value: 1}}},
+					{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}}},
+				})
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.DropCollection(ctx, (*breakGlassGrantModel)(nil))
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_calendars",
+			Version: "20260901000003",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+
+				if err := mexec.CreateCollection(ctx, (*calendarModel)(nil)); err != nil {
+					return err
+				}
+
+				return mexec.CreateIndexes(ctx, colCalendars, []mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+						Options: options.Index().SetUnique(true),
+					},
+				})
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.DropCollection(ctx, (*calendarModel)(nil))
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_rate_counters",
+			Version: "20260901000004",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+
+				if err := mexec.CreateCollection(ctx, (*counterModel)(nil)); err != nil {
+					return err
+				}
+
+				return mexec.CreateIndexes(ctx, colRateCounters, counterIndexes())
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.DropCollection(ctx, (*counterModel)(nil))
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_sod_constraints",
+			Version: "20260901000005",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+
+				if err := mexec.CreateCollection(ctx, (*sodConstraintModel)(nil)); err != nil {
+					return err
+				}
+
+				return mexec.CreateIndexes(ctx, colSoDConstraints, []mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+						Options: options.Index().SetUnique(true),
+					},
+					{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "roles", Value: 1}}},
+				})
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.DropCollection(ctx, (*sodConstraintModel)(nil))
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_policy_versions",
+			Version: "20261101000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				if err := mexec.CreateCollection(ctx, (*policyVersionModel)(nil)); err != nil {
+					return err
+				}
+
+				return mexec.CreateIndexes(ctx, colPolicyVersions, policyVersionIndexes())
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				mexec, ok := exec.(*mongomigrate.Executor)
+				if !ok {
+					return fmt.Errorf("expected mongomigrate executor, got %T", exec)
+				}
+				return mexec.DropCollection(ctx, (*policyVersionModel)(nil))
+			},
+		},
 	)
 }
@@ -1,11 +1,15 @@
 package mongo
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/xraph/grove"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
@@ -13,6 +17,8 @@ import (
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/webhook"
 )
 
 // ──────────────────────────────────────────────────
@@ -278,9 +284,13 @@ type policyModel struct {
 	Effect          string                `grove:"effect"          bson:"effect"`
 	Priority        int                   `grove:"priority"        bson:"priority"`
 	IsActive        bool                  `grove:"is_active"       bson:"is_active"`
+	Mode            string                `grove:"mode"            bson:"mode,omitempty"`
+	OnError         string                `grove:"on_error"        bson:"on_error,omitempty"`
 	NotBefore       *time.Time            `grove:"not_before"      bson:"not_before,omitempty"`
 	NotAfter        *time.Time            `grove:"not_after"       bson:"not_after,omitempty"`
 	Obligations     []string              `grove:"obligations"     bson:"obligations"`
+	Structured      []policy.Obligation   `grove:"structured_obligations" bson:"structured_obligations,omitempty"`
+	Advice          []policy.Obligation   `grove:"advice"          bson:"advice,omitempty"`
 	Version         int                   `grove:"version"         bson:"version"`
 	Subjects        []policy.SubjectMatch `grove:"subjects"        bson:"subjects"`
 	Actions         []string              `grove:"actions"         bson:"actions"`
@@ -306,9 +316,13 @@ func policyToModel(p *policy.Policy) *policyModel {
 		Effect:        string(p.Effect),
 		Priority:      p.Priority,
 		IsActive:      p.IsActive,
+		Mode:          string(p.Mode),
+		OnError:       string(p.OnError),
 		NotBefore:     p.NotBefore,
 		NotAfter:      p.NotAfter,
 		Obligations:   obligations,
+		Structured:    p.StructuredObligations,
+		Advice:        p.Advice,
 		Version:       p.Version,
 		Subjects:      p.Subjects,
 		Actions:       p.Actions,
@@ -323,26 +337,30 @@ func policyToModel(p *policy.Policy) *policyModel {
 func policyFromModel(m *policyModel) *policy.Policy {
 	pid, _ := id.ParsePolicyID(m.ID) //nolint:errcheck // stored IDs are always valid
 	return &policy.Policy{
-		ID:            pid,
-		TenantID:      m.TenantID,
-		NamespacePath: m.NamespacePath,
-		AppID:         m.AppID,
-		Name:          m.Name,
-		Description:   m.Description,
-		Effect:        policy.Effect(m.Effect),
-		Priority:      m.Priority,
-		IsActive:      m.IsActive,
-		NotBefore:     m.NotBefore,
-		NotAfter:      m.NotAfter,
-		Obligations:   m.Obligations,
-		Version:       m.Version,
-		Subjects:      m.Subjects,
-		Actions:       m.Actions,
-		Resources:     m.Resources,
-		Conditions:    m.Conditions,
-		Metadata:      m.Metadata,
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
+		ID:                    pid,
+		TenantID:              m.TenantID,
+		NamespacePath:         m.NamespacePath,
+		AppID:                 m.AppID,
+		Name:                  m.Name,
+		Description:           m.Description,
+		Effect:                policy.Effect(m.Effect),
+		Priority:              m.Priority,
+		IsActive:              m.IsActive,
+		Mode:                  policy.Mode(m.Mode),
+		OnError:               policy.OnError(m.OnError),
+		NotBefore:             m.NotBefore,
+		NotAfter:              m.NotAfter,
+		Obligations:           m.Obligations,
+		StructuredObligations: m.Structured,
+		Advice:                m.Advice,
+		Version:               m.Version,
+		Subjects:              m.Subjects,
+		Actions:               m.Actions,
+		Resources:             m.Resources,
+		Conditions:            m.Conditions,
+		Metadata:              m.Metadata,
+		CreatedAt:             m.CreatedAt,
+		UpdatedAt:             m.UpdatedAt,
 	}
 }
 
@@ -360,6 +378,7 @@ type resourceTypeModel struct {
 	Description     string                       `grove:"description"     bson:"description"`
 	Relations       []resourcetype.RelationDef   `grove:"relations"       bson:"relations"`
 	Permissions     []resourcetype.PermissionDef `grove:"permissions"     bson:"permissions"`
+	Attributes      []resourcetype.AttributeDef  `grove:"attributes"      bson:"attributes,omitempty"`
 	Metadata        map[string]any               `grove:"metadata"        bson:"metadata,omitempty"`
 	CreatedAt       time.Time                    `grove:"created_at"      bson:"created_at"`
 	UpdatedAt       time.Time                    `grove:"updated_at"      bson:"updated_at"`
@@ -375,6 +394,7 @@ func resourceTypeToModel(rt *resourcetype.ResourceType) *resourceTypeModel {
 		Description:   rt.Description,
 		Relations:     rt.Relations,
 		Permissions:   rt.Permissions,
+		Attributes:    rt.Attributes,
 		Metadata:      rt.Metadata,
 		CreatedAt:     rt.CreatedAt,
 		UpdatedAt:     rt.UpdatedAt,
@@ -392,6 +412,7 @@ func resourceTypeFromModel(m *resourceTypeModel) *resourcetype.ResourceType {
 		Description:   m.Description,
 		Relations:     m.Relations,
 		Permissions:   m.Permissions,
+		Attributes:    m.Attributes,
 		Metadata:      m.Metadata,
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
@@ -404,21 +425,55 @@ func resourceTypeFromModel(m *resourceTypeModel) *resourcetype.ResourceType {
 
 type checkLogModel struct {
 	grove.BaseModel `grove:"table:warden_check_logs"`
-	ID              string         `grove:"id,pk"           bson:"_id"`
-	TenantID        string         `grove:"tenant_id"       bson:"tenant_id"`
-	NamespacePath   string         `grove:"namespace_path"  bson:"namespace_path"`
-	AppID           string         `grove:"app_id"          bson:"app_id"`
-	SubjectKind     string         `grove:"subject_kind"    bson:"subject_kind"`
-	SubjectID       string         `grove:"subject_id"      bson:"subject_id"`
-	Action          string         `grove:"action"          bson:"action"`
-	ResourceType    string         `grove:"resource_type"   bson:"resource_type"`
-	ResourceID      string         `grove:"resource_id"     bson:"resource_id"`
-	Decision        string         `grove:"decision"        bson:"decision"`
-	Reason          string         `grove:"reason"          bson:"reason"`
-	EvalTimeNs      int64          `grove:"eval_time_ns"    bson:"eval_time_ns"`
-	RequestIP       string         `grove:"request_ip"      bson:"request_ip"`
-	Metadata        map[string]any `grove:"metadata"        bson:"metadata,omitempty"`
-	CreatedAt       time.Time      `grove:"created_at"      bson:"created_at"`
+	ID              string               `grove:"id,pk"           bson:"_id"`
+	TenantID        string               `grove:"tenant_id"       bson:"tenant_id"`
+	NamespacePath   string               `grove:"namespace_path"  bson:"namespace_path"`
+	AppID           string               `grove:"app_id"          bson:"app_id"`
+	SubjectKind     string               `grove:"subject_kind"    bson:"subject_kind"`
+	SubjectID       string               `grove:"subject_id"      bson:"subject_id"`
+	Action          string               `grove:"action"          bson:"action"`
+	ResourceType    string               `grove:"resource_type"   bson:"resource_type"`
+	ResourceID      string               `grove:"resource_id"     bson:"resource_id"`
+	Decision        string               `grove:"decision"        bson:"decision"`
+	Reason          string               `grove:"reason"          bson:"reason"`
+	EvalTimeNs      int64                `grove:"eval_time_ns"    bson:"eval_time_ns"`
+	RequestIP       string               `grove:"request_ip"      bson:"request_ip"`
+	RequestID       string               `grove:"request_id"      bson:"request_id"`
+	MatchedBy       []checkLogMatchModel `grove:"matched_by"      bson:"matched_by,omitempty"`
+	Obligations     []string             `grove:"obligations"     bson:"obligations,omitempty"`
+	Metadata        map[string]any       `grove:"metadata"        bson:"metadata,omitempty"`
+	CreatedAt       time.Time            `grove:"created_at"      bson:"created_at"`
+}
+
+// checkLogMatchModel pins the BSON field names of a checklog.Match so the
+// matched_by.* query paths stay stable.
+type checkLogMatchModel struct {
+	Source     string `bson:"source"`
+	RuleID     string `bson:"rule_id"`
+	Detail     string `bson:"detail,omitempty"`
+	Permission string `bson:"permission,omitempty"`
+}
+
+func checkLogMatchesToModel(ms []checklog.Match) []checkLogMatchModel {
+	if len(ms) == 0 {
+		return nil
+	}
+	out := make([]checkLogMatchModel, len(ms))
+	for i, m := range ms {
+		out[i] = checkLogMatchModel{Source: m.Source, RuleID: m.RuleID, Detail: m.Detail, Permission: m.Permission}
+	}
+	return out
+}
+
+func checkLogMatchesFromModel(ms []checkLogMatchModel) []checklog.Match {
+	if len(ms) == 0 {
+		return nil
+	}
+	out := make([]checklog.Match, len(ms))
+	for i, m := range ms {
+		out[i] = checklog.Match{Source: m.Source, RuleID: m.RuleID, Detail: m.Detail, Permission: m.Permission}
+	}
+	return out
 }
 
 func checkLogToModel(e *checklog.Entry) *checkLogModel {
@@ -436,6 +491,9 @@ func checkLogToModel(e *checklog.Entry) *checkLogModel {
 		Reason:        e.Reason,
 		EvalTimeNs:    e.EvalTimeNs,
 		RequestIP:     e.RequestIP,
+		RequestID:     e.RequestID,
+		MatchedBy:     checkLogMatchesToModel(e.MatchedBy),
+		Obligations:   e.Obligations,
 		Metadata:      e.Metadata,
 		CreatedAt:     e.CreatedAt,
 	}
@@ -457,7 +515,339 @@ func checkLogFromModel(m *checkLogModel) *checklog.Entry {
 		Reason:        m.Reason,
 		EvalTimeNs:    m.EvalTimeNs,
 		RequestIP:     m.RequestIP,
+		RequestID:     m.RequestID,
+		MatchedBy:     checkLogMatchesFromModel(m.MatchedBy),
+		Obligations:   m.Obligations,
 		Metadata:      m.Metadata,
 		CreatedAt:     m.CreatedAt,
 	}
 }
+
+// ──────────────────────────────────────────────────
+// Webhook delivery model
+// ──────────────────────────────────────────────────
+
+type webhookDeliveryModel struct {
+	grove.BaseModel `grove:"table:warden_webhook_deliveries"`
+	ID              string     `grove:"id,pk"           bson:"_id"`
+	Endpoint        string     `grove:"endpoint"        bson:"endpoint"`
+	Event           string     `grove:"event"           bson:"event"`
+	Payload         string     `grove:"payload"         bson:"payload"` // signed verbatim, so kept as a string
+	Status          string     `grove:"status"          bson:"status"`
+	Attempts        int        `grove:"attempts"        bson:"attempts"`
+	NextAttemptAt   time.Time  `grove:"next_attempt_at" bson:"next_attempt_at"`
+	LastError       string     `grove:"last_error"      bson:"last_error,omitempty"`
+	LockedBy        string     `grove:"locked_by"       bson:"locked_by"`
+	LockedUntil     *time.Time `grove:"locked_until"    bson:"locked_until"`
+	CreatedAt       time.Time  `grove:"created_at"      bson:"created_at"`
+	UpdatedAt       time.Time  `grove:"updated_at"      bson:"updated_at"`
+}
+
+func webhookDeliveryToModel(d *webhook.Delivery) *webhookDeliveryModel {
+	return &webhookDeliveryModel{
+		ID:            d.ID.String(),
+		Endpoint:      d.Endpoint,
+		Event:         d.Event,
+		Payload:       string(d.Payload),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+		LockedBy:      d.LockedBy,
+		LockedUntil:   d.LockedUntil,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+func webhookDeliveryFromModel(m *webhookDeliveryModel) *webhook.Delivery {
+	did, _ := id.ParseWebhookDeliveryID(m.ID) //nolint:errcheck // stored IDs are always valid
+	return &webhook.Delivery{
+		ID:            did,
+		Endpoint:      m.Endpoint,
+		Event:         m.Event,
+		Payload:       json.RawMessage(m.Payload),
+		Status:        webhook.Status(m.Status),
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		LastError:     m.LastError,
+		LockedBy:      m.LockedBy,
+		LockedUntil:   m.LockedUntil,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Audit entry model
+// ──────────────────────────────────────────────────
+
+type auditEntryModel struct {
+	grove.BaseModel `grove:"table:warden_audit_entries"`
+	ID              string    `grove:"id,pk"          bson:"_id"`
+	TenantID        string    `grove:"tenant_id"      bson:"tenant_id"`
+	NamespacePath   string    `grove:"namespace_path" bson:"namespace_path"`
+	AppID           string    `grove:"app_id"         bson:"app_id"`
+	Seq             int64     `grove:"seq"            bson:"seq"`
+	ActorKind       string    `grove:"actor_kind"     bson:"actor_kind,omitempty"`
+	ActorID         string    `grove:"actor_id"       bson:"actor_id,omitempty"`
+	Source          string    `grove:"source"         bson:"source"`
+	Operation       string    `grove:"operation"      bson:"operation"`
+	EntityType      string    `grove:"entity_type"    bson:"entity_type"`
+	EntityID        string    `grove:"entity_id"      bson:"entity_id,omitempty"`
+	EntityName      string    `grove:"entity_name"    bson:"entity_name,omitempty"`
+	Before          string    `grove:"before_state"   bson:"before_state,omitempty"` // hashed verbatim, so kept as a string
+	After           string    `grove:"after_state"    bson:"after_state,omitempty"`  // hashed verbatim, so kept as a string
+	RequestIP       string    `grove:"request_ip"     bson:"request_ip,omitempty"`
+	RequestID       string    `grove:"request_id"     bson:"request_id,omitempty"`
+	PrevHash        string    `grove:"prev_hash"      bson:"prev_hash,omitempty"`
+	Hash            string    `grove:"hash"           bson:"hash"`
+	CreatedAt       time.Time `grove:"created_at"     bson:"created_at"`
+}
+
+func auditEntryToModel(e *audit.Entry) *auditEntryModel {
+	return &auditEntryModel{
+		ID:            e.ID.String(),
+		TenantID:      e.TenantID,
+		NamespacePath: e.NamespacePath,
+		AppID:         e.AppID,
+		Seq:           e.Seq,
+		ActorKind:     e.Actor.Kind,
+		ActorID:       e.Actor.ID,
+		Source:        string(e.Source),
+		Operation:     string(e.Operation),
+		EntityType:    string(e.EntityType),
+		EntityID:      e.EntityID,
+		EntityName:    e.EntityName,
+		Before:        string(e.Before),
+		After:         string(e.After),
+		RequestIP:     e.RequestIP,
+		RequestID:     e.RequestID,
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func auditEntryFromModel(m *auditEntryModel) *audit.Entry {
+	aid, _ := id.ParseAuditID(m.ID) //nolint:errcheck // stored IDs are always valid
+	e := &audit.Entry{
+		ID:            aid,
+		TenantID:      m.TenantID,
+		NamespacePath: m.NamespacePath,
+		AppID:         m.AppID,
+		Seq:           m.Seq,
+		Actor:         audit.Actor{Kind: m.ActorKind, ID: m.ActorID},
+		Source:        audit.Source(m.Source),
+		Operation:     audit.Operation(m.Operation),
+		EntityType:    audit.EntityType(m.EntityType),
+		EntityID:      m.EntityID,
+		EntityName:    m.EntityName,
+		RequestIP:     m.RequestIP,
+		RequestID:     m.RequestID,
+		PrevHash:      m.PrevHash,
+		Hash:          m.Hash,
+		CreatedAt:     m.CreatedAt,
+	}
+	if m.Before != "" {
+		e.Before = json.RawMessage(m.Before)
+	}
+	if m.After != "" {
+		e.After = json.RawMessage(m.After)
+	}
+	return e
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass grant model
+// ──────────────────────────────────────────────────
+
+type breakGlassGrantModel struct {
+	grove.BaseModel `grove:"table:warden_break_glass_grants"`
+	ID              string     `grove:"id,pk"           bson:"_id"`
+	TenantID        string     `grove:"tenant_id"       bson:"tenant_id"`
+	NamespacePath   string     `grove:"namespace_path"  bson:"namespace_path"`
+	AppID           string     `grove:"app_id"          bson:"app_id"`
+	SubjectKind     string     `grove:"subject_kind"    bson:"subject_kind"`
+	SubjectID       string     `grove:"subject_id"      bson:"subject_id"`
+	Justification   string     `grove:"justification"   bson:"justification"`
+	RoleIDs         []string   `grove:"role_ids"        bson:"role_ids,omitempty"`
+	BypassPolicies  []string   `grove:"bypass_policies" bson:"bypass_policies,omitempty"`
+	ExpiresAt       time.Time  `grove:"expires_at"      bson:"expires_at"`
+	RevokedAt       *time.Time `grove:"revoked_at"      bson:"revoked_at"`
+	RevokedBy       string     `grove:"revoked_by"      bson:"revoked_by,omitempty"`
+	CreatedAt       time.Time  `grove:"created_at"      bson:"created_at"`
+}
+
+func breakGlassGrantToModel(g *breakglass.Grant) *breakGlassGrantModel {
+	roleIDs := make([]string, len(g.RoleIDs))
+	for i, rid := range g.RoleIDs {
+		roleIDs[i] = rid.String()
+	}
+	return &breakGlassGrantModel{
+		ID:             g.ID.String(),
+		TenantID:       g.TenantID,
+		NamespacePath:  g.NamespacePath,
+		AppID:          g.AppID,
+		SubjectKind:    g.SubjectKind,
+		SubjectID:      g.SubjectID,
+		Justification:  g.Justification,
+		RoleIDs:        roleIDs,
+		BypassPolicies: g.BypassPolicies,
+		ExpiresAt:      g.ExpiresAt,
+		RevokedAt:      g.RevokedAt,
+		RevokedBy:      g.RevokedBy,
+		CreatedAt:      g.CreatedAt,
+	}
+}
+
+func breakGlassGrantFromModel(m *breakGlassGrantModel) *breakglass.Grant {
+	gid, _ := id.ParseBreakGlassID(m.ID) //nolint:errcheck // stored IDs are always valid
+	roleIDs := make([]id.RoleID, 0, len(m.RoleIDs))
+	for _, s := range m.RoleIDs {
+		if rid, err := id.ParseRoleID(s); err == nil {
+			roleIDs = append(roleIDs, rid)
+		}
+	}
+	return &breakglass.Grant{
+		ID:             gid,
+		TenantID:       m.TenantID,
+		NamespacePath:  m.NamespacePath,
+		AppID:          m.AppID,
+		SubjectKind:    m.SubjectKind,
+		SubjectID:      m.SubjectID,
+		Justification:  m.Justification,
+		RoleIDs:        roleIDs,
+		BypassPolicies: m.BypassPolicies,
+		ExpiresAt:      m.ExpiresAt,
+		RevokedAt:      m.RevokedAt,
+		RevokedBy:      m.RevokedBy,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Calendar model
+// ──────────────────────────────────────────────────
+
+type calendarModel struct {
+	grove.BaseModel `grove:"table:warden_calendars"`
+	ID              string          `grove:"id,pk"       bson:"_id"`
+	TenantID        string          `grove:"tenant_id"   bson:"tenant_id"`
+	AppID           string          `grove:"app_id"      bson:"app_id"`
+	Name            string          `grove:"name"        bson:"name"`
+	Description     string          `grove:"description" bson:"description,omitempty"`
+	TimeZone        string          `grove:"time_zone"   bson:"time_zone,omitempty"`
+	Dates           []calendar.Date `grove:"dates"       bson:"dates,omitempty"`
+	Metadata        map[string]any  `grove:"metadata"    bson:"metadata,omitempty"`
+	CreatedAt       time.Time       `grove:"created_at"  bson:"created_at"`
+	UpdatedAt       time.Time       `grove:"updated_at"  bson:"updated_at"`
+}
+
+func calendarToModel(c *calendar.Calendar) *calendarModel {
+	return &calendarModel{
+		ID:          c.ID.String(),
+		TenantID:    c.TenantID,
+		AppID:       c.AppID,
+		Name:        c.Name,
+		Description: c.Description,
+		TimeZone:    c.TimeZone,
+		Dates:       c.Dates,
+		Metadata:    c.Metadata,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+func calendarFromModel(m *calendarModel) *calendar.Calendar {
+	cid, _ := id.ParseCalendarID(m.ID) //nolint:errcheck // stored IDs are always valid
+	return &calendar.Calendar{
+		ID:          cid,
+		TenantID:    m.TenantID,
+		AppID:       m.AppID,
+		Name:        m.Name,
+		Description: m.Description,
+		TimeZone:    m.TimeZone,
+		Dates:       m.Dates,
+		Metadata:    m.Metadata,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// SoD constraint model
+// ──────────────────────────────────────────────────
+
+type sodConstraintModel struct {
+	grove.BaseModel `grove:"table:warden_sod_constraints"`
+	ID              string         `grove:"id,pk"       bson:"_id"`
+	TenantID        string         `grove:"tenant_id"   bson:"tenant_id"`
+	AppID           string         `grove:"app_id"      bson:"app_id"`
+	Name            string         `grove:"name"        bson:"name"`
+	Description     string         `grove:"description" bson:"description,omitempty"`
+	Kind            string         `grove:"kind"        bson:"kind"`
+	Roles           []string       `grove:"roles"       bson:"roles"`
+	MaxRoles        int            `grove:"max_roles"   bson:"max_roles,omitempty"`
+	Metadata        map[string]any `grove:"metadata"    bson:"metadata,omitempty"`
+	CreatedAt       time.Time      `grove:"created_at"  bson:"created_at"`
+	UpdatedAt       time.Time      `grove:"updated_at"  bson:"updated_at"`
+}
+
+func sodConstraintToModel(c *sod.Constraint) *sodConstraintModel {
+	return &sodConstraintModel{
+		ID:          c.ID.String(),
+		TenantID:    c.TenantID,
+		AppID:       c.AppID,
+		Name:        c.Name,
+		Description: c.Description,
+		Kind:        string(c.Kind),
+		Roles:       c.Roles,
+		MaxRoles:    c.MaxRoles,
+		Metadata:    c.Metadata,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+func sodConstraintFromModel(m *sodConstraintModel) *sod.Constraint {
+	sid, _ := id.ParseSoDConstraintID(m.ID) //nolint:errcheck // stored IDs are always valid
+	return &sod.Constraint{
+		ID:          sid,
+		TenantID:    m.TenantID,
+		AppID:       m.AppID,
+		Name:        m.Name,
+		Description: m.Description,
+		Kind:        sod.Kind(m.Kind),
+		Roles:       m.Roles,
+		MaxRoles:    m.MaxRoles,
+		Metadata:    m.Metadata,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Policy version model
+// ──────────────────────────────────────────────────
+
+type policyVersionModel struct {
+	grove.BaseModel `grove:"table:warden_policy_versions"`
+	TenantID        string    `grove:"id,pk"      bson:"_id"`
+	Version         int64     `grove:"version"    bson:"version"`
+	ChangedAt       time.Time `grove:"changed_at" bson:"changed_at"`
+}
+
+// ──────────────────────────────────────────────────
+// Rate counter model
+// ──────────────────────────────────────────────────
+
+type counterModel struct {
+	grove.BaseModel `grove:"table:warden_rate_counters"`
+	TenantID        string    `grove:"tenant_id"    bson:"tenant_id"`
+	SubjectKind     string    `grove:"subject_kind" bson:"subject_kind"`
+	SubjectID       string    `grove:"subject_id"   bson:"subject_id"`
+	Action          string    `grove:"action"       bson:"action"`
+	Bucket          time.Time `grove:"bucket"       bson:"bucket"`
+	Count           int64     `grove:"count"        bson:"count"`
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -14,46 +15,71 @@ import (
 	"github.com/xraph/grove/drivers/mongodriver"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/counter"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
 	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/wardenerr"
+	"github.com/xraph/warden/webhook"
 )
 
 // Collection name constants.
 const (
-	colRoles           = "warden_roles"
-	colPermissions     = "warden_permissions"
-	colRolePermissions = "warden_role_permissions"
-	colAssignments     = "warden_assignments"
-	colRelations       = "warden_relations"
-	colPolicies        = "warden_policies"
-	colResourceTypes   = "warden_resource_types"
-	colCheckLogs       = "warden_check_logs"
+	colRoles             = "warden_roles"
+	colPermissions       = "warden_permissions"
+	colRolePermissions   = "warden_role_permissions"
+	colAssignments       = "warden_assignments"
+	colRelations         = "warden_relations"
+	colPolicies          = "warden_policies"
+	colResourceTypes     = "warden_resource_types"
+	colCheckLogs         = "warden_check_logs"
+	colWebhookDeliveries = "warden_webhook_deliveries"
+	colAuditEntries      = "warden_audit_entries"
+	colBreakGlassGrants  = "warden_break_glass_grants"
+	colCalendars         = "warden_calendars"
+	colRateCounters      = "warden_rate_counters"
+	colSoDConstraints    = "warden_sod_constraints"
+	colLocks             = "warden_locks"
+	colPolicyVersions    = "warden_policy_versions"
 )
 
 // Compile-time interface check.
-var _ store.Store = (*Store)(nil)
+var (
+	_ store.Store           = (*Store)(nil)
+	_ checklog.StatsStore   = (*Store)(nil)
+	_ policy.ChangeNotifier = (*Store)(nil)
+)
 
 // errNotFound is the sentinel for missing entities.
-var errNotFound = fmt.Errorf("not found")
+var errNotFound = wardenerr.ErrNotFound
 
 // Store is a MongoDB implementation of the composite Warden store.
 type Store struct {
 	db  *grove.DB
 	mdb *mongodriver.MongoDB
+
+	// policyChanges reports policy writes, including those made by other
+	// processes sharing the database, to the engine's policy index.
+	policyChanges *policy.ChangeFeed
 }
 
 // New creates a new MongoDB store backed by Grove ORM.
 func New(db *grove.DB) *Store {
-	return &Store{
+	s := &Store{
 		db:  db,
 		mdb: mongodriver.Unwrap(db),
 	}
+	s.policyChanges = policy.NewChangeFeed(s.pollPolicyChanges, policy.DefaultChangePollInterval)
+	return s
 }
 
 // Migrate creates indexes for all warden collections.
@@ -76,8 +102,10 @@ func (s *Store) Ping(ctx context.Context) error {
 	return s.db.Ping(ctx)
 }
 
-// Close closes the database connection.
+// Close stops polling for policy changes and closes the database
+// connection.
 func (s *Store) Close() error {
+	s.policyChanges.Close()
 	return s.db.Close()
 }
 
@@ -177,6 +205,41 @@ func migrationIndexes() map[string][]mongod.IndexModel {
 			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "resource_type", Value: 1}, {Key: "resource_id", Value: 1}}},
 			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "decision", Value: 1}}},
 			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "request_id", Value: 1}}},
+			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "namespace_path", Value: 1}}},
+			{Keys: bson.D{{Key: "matched_by.rule_id", Value: 1}}},
+			{Keys: bson.D{{Key: "obligations", Value: 1}}},
+		},
+		colWebhookDeliveries: {
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+			{Keys: bson.D{{Key: "updated_at", Value: 1}}},
+		},
+		colAuditEntries: {
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "seq", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}}},
+			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		},
+		colBreakGlassGrants: {
+			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "subject_kind", Value: 1}, {Key: "subject_id", Value: 1}, {Key: "expires_at", Value: 1}}},
+			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		colCalendars: {
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		colRateCounters:   counterIndexes(),
+		colPolicyVersions: policyVersionIndexes(),
+		colSoDConstraints: {
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "roles", Value: 1}}},
 		},
 	}
 }
@@ -673,6 +736,15 @@ func (s *Store) CreateAssignment(ctx context.Context, a *assignment.Assignment)
 	if a.CreatedAt.IsZero() {
 		a.CreatedAt = now()
 	}
+	unlock, err := s.lockAssignments(ctx, a.TenantID, a.ID.String())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := sod.CheckAssignment(ctx, s, a, now()); err != nil {
+		return err
+	}
 	m := assignmentToModel(a)
 	if _, err := s.mdb.NewInsert(m).Exec(ctx); err != nil {
 		return fmt.Errorf("warden: create assignment: %w", err)
@@ -680,6 +752,46 @@ func (s *Store) CreateAssignment(ctx context.Context, a *assignment.Assignment)
 	return nil
 }
 
+// assignmentLockLease bounds how long the assignment lock of a tenant
+// stays held by a CreateAssignment that never released it, e.g. because
+// its process died.
+const assignmentLockLease = 30 * time.Second
+
+// lockAssignments serialises CreateAssignment per tenant, so the
+// separation-of-duties and member checks of concurrent assignments see
+// each other's inserts without needing a replica set for transactions.
+// The lock is a leased document in colLocks: it is taken by upserting the
+// document when it is absent or its lease has run out, which fails with a
+// duplicate key while another owner holds it. The returned func releases
+// the lock.
+func (s *Store) lockAssignments(ctx context.Context, tenantID, owner string) (func(), error) {
+	coll := s.mdb.Collection(colLocks)
+	key := "assignments:" + tenantID
+	for {
+		at := now()
+		_, err := coll.UpdateOne(ctx,
+			bson.M{"_id": key, "$or": bson.A{
+				bson.M{"locked_until": nil},
+				bson.M{"locked_until": bson.M{"$lte": at}},
+			}},
+			bson.M{"$set": bson.M{"owner": owner, "locked_until": at.Add(assignmentLockLease)}},
+			options.UpdateOne().SetUpsert(true))
+		if err == nil {
+			return func() {
+				_, _ = coll.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": key, "owner": owner}) //nolint:errcheck // an unreleased lock expires
+			}, nil
+		}
+		if !mongod.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("warden: lock assignments: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("warden: lock assignments: %w", ctx.Err())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
 func (s *Store) GetAssignment(ctx context.Context, assID id.AssignmentID) (*assignment.Assignment, error) {
 	var m assignmentModel
 	err := s.mdb.NewFind(&m).
@@ -1150,7 +1262,7 @@ func (s *Store) CreatePolicy(ctx context.Context, p *policy.Policy) error {
 	if _, err := s.mdb.NewInsert(m).Exec(ctx); err != nil {
 		return fmt.Errorf("warden: create policy: %w", err)
 	}
-	return nil
+	return s.policyChanged(ctx, p.TenantID)
 }
 
 func (s *Store) GetPolicy(ctx context.Context, polID id.PolicyID) (*policy.Policy, error) {
@@ -1193,17 +1305,21 @@ func (s *Store) UpdatePolicy(ctx context.Context, p *policy.Policy) error {
 	if res.MatchedCount() == 0 {
 		return fmt.Errorf("policy %s: %w", p.ID, errNotFound)
 	}
-	return nil
+	return s.policyChanged(ctx, p.TenantID)
 }
 
 func (s *Store) DeletePolicy(ctx context.Context, polID id.PolicyID) error {
+	before, _ := s.GetPolicy(ctx, polID) //nolint:errcheck // missing → no change to report
 	_, err := s.mdb.NewDelete((*policyModel)(nil)).
 		Filter(bson.M{"_id": polID.String()}).
 		Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("warden: delete policy: %w", err)
 	}
-	return nil
+	if before == nil {
+		return nil
+	}
+	return s.policyChanged(ctx, before.TenantID)
 }
 
 func (s *Store) ListPolicies(ctx context.Context, filter *policy.ListFilter) ([]*policy.Policy, error) {
@@ -1314,9 +1430,58 @@ func (s *Store) DeletePoliciesByTenant(ctx context.Context, tenantID string) err
 	if err != nil {
 		return fmt.Errorf("warden: delete policies by tenant: %w", err)
 	}
+	return s.policyChanged(ctx, tenantID)
+}
+
+// NotifyPolicyChanges implements policy.ChangeNotifier. Every policy and
+// separation-of-duties constraint write bumps its tenant's document in
+// warden_policy_versions, which each store sharing the database polls, so
+// engines in other processes drop their compiled policies within
+// policy.DefaultChangePollInterval.
+func (s *Store) NotifyPolicyChanges(fn func(tenantID string)) (stop func()) {
+	return s.policyChanges.NotifyPolicyChanges(fn)
+}
+
+// policyVersionIndexes lets a poll scan the recently changed tenants.
+func policyVersionIndexes() []mongod.IndexModel {
+	return []mongod.IndexModel{
+		{Keys: bson.D{{Key: "changed_at", Value: 1}}},
+	}
+}
+
+// policyChanged records a policy or constraint write of tenantID and
+// reports it to this store's watchers.
+func (s *Store) policyChanged(ctx context.Context, tenantID string) error {
+	f := bson.M{"_id": tenantID}
+	update := bson.M{"$inc": bson.M{"version": int64(1)}, "$set": bson.M{"changed_at": now()}}
+	coll := s.mdb.Collection(colPolicyVersions)
+	_, err := coll.UpdateOne(ctx, f, update, options.UpdateOne().SetUpsert(true))
+	if mongod.IsDuplicateKeyError(err) {
+		// A concurrent write created the document first.
+		_, err = coll.UpdateOne(ctx, f, update)
+	}
+	if err != nil {
+		return fmt.Errorf("warden: record policy change: %w", err)
+	}
+	s.policyChanges.Notify(tenantID)
 	return nil
 }
 
+func (s *Store) pollPolicyChanges(ctx context.Context, since time.Time) ([]policy.Change, error) {
+	var models []policyVersionModel
+	err := s.mdb.NewFind(&models).
+		Filter(bson.M{"changed_at": bson.M{"$gte": since.UTC()}}).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("warden: poll policy changes: %w", err)
+	}
+	changes := make([]policy.Change, len(models))
+	for i, m := range models {
+		changes[i] = policy.Change{TenantID: m.TenantID, Version: m.Version, ChangedAt: m.ChangedAt}
+	}
+	return changes, nil
+}
+
 // ──────────────────────────────────────────────────
 // Resource type operations (ReBAC schema)
 // ──────────────────────────────────────────────────
@@ -1488,63 +1653,544 @@ func (s *Store) GetCheckLog(ctx context.Context, logID id.CheckLogID) (*checklog
 
 func (s *Store) ListCheckLogs(ctx context.Context, filter *checklog.QueryFilter) ([]*checklog.Entry, error) {
 	var models []checkLogModel
-	f := bson.M{}
+	f := checkLogFilter(filter)
+	q := s.mdb.NewFind(&models).
+		Filter(f).
+		Sort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
 	if filter != nil {
-		if filter.TenantID != "" {
-			f["tenant_id"] = filter.TenantID
+		if filter.Limit > 0 {
+			q = q.Limit(int64(filter.Limit))
 		}
-		if filter.SubjectKind != "" {
-			f["subject_kind"] = filter.SubjectKind
+		if filter.Offset > 0 {
+			q = q.Skip(int64(filter.Offset))
 		}
-		if filter.SubjectID != "" {
-			f["subject_id"] = filter.SubjectID
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list check logs: %w", err)
+	}
+	result := make([]*checklog.Entry, len(models))
+	for i := range models {
+		result[i] = checkLogFromModel(&models[i])
+	}
+	return result, nil
+}
+
+func (s *Store) CountCheckLogs(ctx context.Context, filter *checklog.QueryFilter) (int64, error) {
+	f := checkLogFilter(filter)
+	count, err := s.mdb.NewFind((*checkLogModel)(nil)).
+		Filter(f).
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: count check logs: %w", err)
+	}
+	return count, nil
+}
+
+// checkLogFilter builds the bson filter shared by ListCheckLogs,
+// CountCheckLogs and CheckLogStats. Limit and Offset are not applied.
+func checkLogFilter(filter *checklog.QueryFilter) bson.M {
+	f := bson.M{}
+	if filter == nil {
+		return f
+	}
+	if filter.TenantID != "" {
+		f["tenant_id"] = filter.TenantID
+	}
+	if filter.SubjectKind != "" {
+		f["subject_kind"] = filter.SubjectKind
+	}
+	if filter.SubjectID != "" {
+		f["subject_id"] = filter.SubjectID
+	}
+	if filter.Action != "" {
+		f["action"] = filter.Action
+	}
+	if filter.ResourceType != "" {
+		f["resource_type"] = filter.ResourceType
+	}
+	if filter.ResourceID != "" {
+		f["resource_id"] = filter.ResourceID
+	}
+	if filter.Decision != "" {
+		f["decision"] = filter.Decision
+	}
+	applyCheckLogProvenanceFilter(f, filter)
+	if filter.After != nil || filter.Before != nil {
+		dateFilter := bson.M{}
+		if filter.After != nil {
+			dateFilter["$gte"] = *filter.After
 		}
-		if filter.Action != "" {
-			f["action"] = filter.Action
+		if filter.Before != nil {
+			dateFilter["$lte"] = *filter.Before
 		}
-		if filter.ResourceType != "" {
-			f["resource_type"] = filter.ResourceType
+		f["created_at"] = dateFilter
+	}
+	if c := filter.Cursor; c != nil {
+		f["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": c.CreatedAt}},
+			bson.M{"created_at": c.CreatedAt, "_id": bson.M{"$lt": c.ID.String()}},
 		}
-		if filter.ResourceID != "" {
-			f["resource_id"] = filter.ResourceID
+	}
+	return f
+}
+
+// applyCheckLogProvenanceFilter adds the namespace, request and
+// matched-rule filters.
+func applyCheckLogProvenanceFilter(f bson.M, filter *checklog.QueryFilter) {
+	if filter.NamespacePath != nil || filter.NamespacePrefix != "" {
+		ns := bson.M{}
+		if filter.NamespacePath != nil {
+			ns["$eq"] = *filter.NamespacePath
+		}
+		if filter.NamespacePrefix != "" {
+			ns["$regex"] = "^" + regexp.QuoteMeta(filter.NamespacePrefix)
+		}
+		f["namespace_path"] = ns
+	}
+	if filter.RequestIP != "" {
+		f["request_ip"] = filter.RequestIP
+	}
+	if filter.RequestID != "" {
+		f["request_id"] = filter.RequestID
+	}
+	if filter.MatchedSource != "" || filter.MatchedRuleID != "" {
+		elem := bson.M{}
+		if filter.MatchedSource != "" {
+			elem["source"] = filter.MatchedSource
+		}
+		if filter.MatchedRuleID != "" {
+			elem["rule_id"] = filter.MatchedRuleID
+		}
+		f["matched_by"] = bson.M{"$elemMatch": elem}
+	}
+	if filter.Obligation != "" {
+		f["obligations"] = filter.Obligation
+	}
+}
+
+// checkLogStatsKey maps a stats dimension to its aggregation key expression.
+func checkLogStatsKey(d checklog.Dimension) any {
+	switch d {
+	case checklog.DimensionResourceType:
+		return "$resource_type"
+	case checklog.DimensionAction:
+		return "$action"
+	case checklog.DimensionSubject:
+		return bson.M{"$concat": bson.A{"$subject_kind", ":", "$subject_id"}}
+	default:
+		return "$decision"
+	}
+}
+
+// checkLogStatsRow is one grouped count from a stats pipeline. Bucket is
+// the bucket start in epoch milliseconds (0 when not bucketed).
+type checkLogStatsRow struct {
+	ID struct {
+		Bucket int64  `bson:"bucket"`
+		Key    string `bson:"key"`
+	} `bson:"_id"`
+	Count int64 `bson:"count"`
+}
+
+// CheckLogStats aggregates check logs with $group pipelines. Latency
+// percentiles are read by rank with sort/skip so results match the
+// in-memory implementation on every MongoDB version.
+func (s *Store) CheckLogStats(ctx context.Context, q *checklog.StatsQuery) (*checklog.Stats, error) {
+	f := checkLogFilter(&q.Filter)
+	coll := s.mdb.Collection(colCheckLogs)
+
+	group := func(match bson.M, bucket, key any) ([]checkLogStatsRow, error) {
+		cur, err := coll.Aggregate(ctx, mongod.Pipeline{
+			{{Key: "$match", Value: match}},
+			{{Key: "$group", Value: bson.M{
+				"_id":   bson.M{"bucket": bucket, "key": key},
+				"count": bson.M{"$sum": 1},
+			}}},
+		})
+		if err != nil {
+			return nil, err
 		}
-		if filter.Decision != "" {
-			f["decision"] = filter.Decision
+		var rows []checkLogStatsRow
+		if err := cur.All(ctx, &rows); err != nil {
+			return nil, err
 		}
-		if filter.After != nil || filter.Before != nil {
-			dateFilter := bson.M{}
-			if filter.After != nil {
-				dateFilter["$gte"] = *filter.After
+		return rows, nil
+	}
+
+	var bucket any = int64(0)
+	if width := q.Bucket.Milliseconds(); width > 0 {
+		ms := bson.M{"$toLong": "$created_at"}
+		bucket = bson.M{"$subtract": bson.A{ms, bson.M{"$mod": bson.A{ms, width}}}}
+	}
+	rows, err := group(f, bucket, checkLogStatsKey(q.GroupBy))
+	if err != nil {
+		return nil, fmt.Errorf("warden: check log stats: %w", err)
+	}
+	st := &checklog.Stats{Series: make([]checklog.StatsPoint, 0, len(rows))}
+	for _, r := range rows {
+		p := checklog.StatsPoint{Key: r.ID.Key, Count: r.Count}
+		if q.Bucket > 0 {
+			p.Bucket = time.UnixMilli(r.ID.Bucket).UTC()
+		}
+		st.Series = append(st.Series, p)
+		st.Total += r.Count
+	}
+	checklog.SortSeries(st.Series)
+
+	if st.Total > 0 {
+		rank := func(p float64) (int64, error) {
+			var models []checkLogModel
+			err := s.mdb.NewFind(&models).
+				Filter(f).
+				Sort(bson.D{{Key: "eval_time_ns", Value: 1}}).
+				Skip(checklog.RankIndex(st.Total, p)).
+				Limit(1).
+				Scan(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("warden: check log latency stats: %w", err)
 			}
-			if filter.Before != nil {
-				dateFilter["$lte"] = *filter.Before
+			if len(models) == 0 {
+				return 0, nil
 			}
-			f["created_at"] = dateFilter
+			return models[0].EvalTimeNs, nil
+		}
+		if st.Latency.P50, err = rank(0.50); err != nil {
+			return nil, err
+		}
+		if st.Latency.P95, err = rank(0.95); err != nil {
+			return nil, err
+		}
+		if st.Latency.P99, err = rank(0.99); err != nil {
+			return nil, err
+		}
+	}
+
+	denied := bson.M{}
+	for k, v := range f {
+		denied[k] = v
+	}
+	if d, ok := denied["decision"]; ok {
+		denied["$and"] = bson.A{bson.M{"decision": d}, bson.M{"decision": bson.M{"$ne": "allow"}}}
+		delete(denied, "decision")
+	} else {
+		denied["decision"] = bson.M{"$ne": "allow"}
+	}
+	top := func(key any) ([]checklog.KeyCount, error) {
+		rows, err := group(denied, int64(0), key)
+		if err != nil {
+			return nil, fmt.Errorf("warden: check log top denied: %w", err)
 		}
+		m := make(map[string]int64, len(rows))
+		for _, r := range rows {
+			m[r.ID.Key] = r.Count
+		}
+		return checklog.TopCounts(m, q.TopN), nil
+	}
+	if st.TopDeniedSubjects, err = top(bson.M{"$concat": bson.A{"$subject_kind", ":", "$subject_id"}}); err != nil {
+		return nil, err
+	}
+	if st.TopDeniedResources, err = top(bson.M{"$concat": bson.A{"$resource_type", ":", "$resource_id"}}); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *Store) PurgeCheckLogs(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.mdb.NewDelete((*checkLogModel)(nil)).
+		Many().
+		Filter(bson.M{"created_at": bson.M{"$lt": before}}).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge check logs: %w", err)
+	}
+	return res.DeletedCount(), nil
+}
+
+func (s *Store) DeleteCheckLogs(ctx context.Context, ids []id.CheckLogID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	strs := make([]string, len(ids))
+	for i, clid := range ids {
+		strs[i] = clid.String()
+	}
+	res, err := s.mdb.NewDelete((*checkLogModel)(nil)).
+		Many().
+		Filter(bson.M{"_id": bson.M{"$in": strs}}).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: delete check logs: %w", err)
+	}
+	return res.DeletedCount(), nil
+}
+
+func (s *Store) DeleteCheckLogsByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.mdb.NewDelete((*checkLogModel)(nil)).
+		Many().
+		Filter(bson.M{"tenant_id": tenantID}).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: delete check logs by tenant: %w", err)
+	}
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Webhook outbox operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, d *webhook.Delivery) error {
+	if d.ID.IsNil() {
+		d.ID = id.NewWebhookDeliveryID()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now()
+	}
+	if d.UpdatedAt.IsZero() {
+		d.UpdatedAt = d.CreatedAt
+	}
+	if _, err := s.mdb.NewInsert(webhookDeliveryToModel(d)).Exec(ctx); err != nil {
+		return fmt.Errorf("warden: create webhook delivery: %w", err)
 	}
+	return nil
+}
+
+// ClaimDueWebhookDeliveries lists the oldest due deliveries and then
+// leases each with a conditional update that only matches while it is
+// still due and unclaimed, so of two dispatchers racing for a delivery
+// exactly one wins it.
+func (s *Store) ClaimDueWebhookDeliveries(ctx context.Context, owner string, at, lockUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	var models []webhookDeliveryModel
 	q := s.mdb.NewFind(&models).
-		Filter(f).
-		Sort(bson.D{{Key: "created_at", Value: -1}})
-	if filter != nil {
-		if filter.Limit > 0 {
-			q = q.Limit(int64(filter.Limit))
+		Filter(webhookDueFilter(at)).
+		Sort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		q = q.Limit(int64(limit))
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list due webhook deliveries: %w", err)
+	}
+
+	coll := s.mdb.Collection(colWebhookDeliveries)
+	result := make([]*webhook.Delivery, 0, len(models))
+	for i := range models {
+		claim := webhookDueFilter(at)
+		claim["_id"] = models[i].ID
+		res, err := coll.UpdateOne(ctx, claim, bson.M{"$set": bson.M{"locked_by": owner, "locked_until": lockUntil}})
+		if err != nil {
+			return nil, fmt.Errorf("warden: claim webhook delivery: %w", err)
 		}
-		if filter.Offset > 0 {
-			q = q.Skip(int64(filter.Offset))
+		if res.MatchedCount == 0 {
+			continue // claimed by another dispatcher
 		}
+		models[i].LockedBy, models[i].LockedUntil = owner, &lockUntil
+		result = append(result, webhookDeliveryFromModel(&models[i]))
+	}
+	return result, nil
+}
+
+// webhookDueFilter matches pending deliveries due at at that no
+// dispatcher holds a lease on.
+func webhookDueFilter(at time.Time) bson.M {
+	return bson.M{
+		"status":          string(webhook.StatusPending),
+		"next_attempt_at": bson.M{"$lte": at},
+		"$or": bson.A{
+			bson.M{"locked_until": nil},
+			bson.M{"locked_until": bson.M{"$lte": at}},
+		},
+	}
+}
+
+// UpdateWebhookDelivery writes the attempt's outcome only while owner's
+// lease on the document is unexpired, so a dispatcher whose lease ran out
+// cannot overwrite the outcome recorded by the one that claimed it next.
+func (s *Store) UpdateWebhookDelivery(ctx context.Context, owner string, d *webhook.Delivery) error {
+	m := webhookDeliveryToModel(d)
+	res, err := s.mdb.NewUpdate(m).
+		Filter(bson.M{
+			"_id":          m.ID,
+			"locked_by":    owner,
+			"locked_until": bson.M{"$gt": d.UpdatedAt},
+		}).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: update webhook delivery: %w", err)
+	}
+	if res.MatchedCount() == 0 {
+		return fmt.Errorf("webhook delivery %s: %w", d.ID, webhook.ErrLeaseLost)
+	}
+	return nil
+}
+
+func (s *Store) PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.mdb.NewDelete((*webhookDeliveryModel)(nil)).
+		Many().
+		Filter(bson.M{
+			"status":     bson.M{"$ne": string(webhook.StatusPending)},
+			"updated_at": bson.M{"$lt": before},
+		}).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge webhook deliveries: %w", err)
+	}
+	return res.DeletedCount(), nil
+}
+
+// ──────────────────────────────────────────────────
+// Audit trail operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) AppendAuditEntry(ctx context.Context, e *audit.Entry) error {
+	if _, err := s.mdb.NewInsert(auditEntryToModel(e)).Exec(ctx); err != nil {
+		if mongod.IsDuplicateKeyError(err) {
+			return fmt.Errorf("audit entry %d in tenant %q: %w", e.Seq, e.TenantID, audit.ErrSequenceConflict)
+		}
+		return fmt.Errorf("warden: append audit entry: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) LastAuditEntry(ctx context.Context, tenantID string) (*audit.Entry, error) {
+	var models []auditEntryModel
+	err := s.mdb.NewFind(&models).
+		Filter(bson.M{"tenant_id": tenantID}).
+		Sort(bson.D{{Key: "seq", Value: -1}}).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("warden: last audit entry: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil //nolint:nilnil // empty chain
+	}
+	return auditEntryFromModel(&models[0]), nil
+}
+
+func (s *Store) ListAuditEntries(ctx context.Context, filter *audit.QueryFilter) ([]*audit.Entry, error) {
+	if filter == nil {
+		filter = &audit.QueryFilter{}
+	}
+	order := -1
+	if filter.Ascending {
+		order = 1
+	}
+	var models []auditEntryModel
+	q := s.mdb.NewFind(&models).
+		Filter(auditFilter(filter)).
+		Sort(bson.D{{Key: "seq", Value: order}})
+	if filter.Limit > 0 {
+		q = q.Limit(int64(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		q = q.Skip(int64(filter.Offset))
 	}
 	if err := q.Scan(ctx); err != nil {
-		return nil, fmt.Errorf("warden: list check logs: %w", err)
+		return nil, fmt.Errorf("warden: list audit entries: %w", err)
 	}
-	result := make([]*checklog.Entry, len(models))
+	result := make([]*audit.Entry, len(models))
 	for i := range models {
-		result[i] = checkLogFromModel(&models[i])
+		result[i] = auditEntryFromModel(&models[i])
 	}
 	return result, nil
 }
 
-func (s *Store) CountCheckLogs(ctx context.Context, filter *checklog.QueryFilter) (int64, error) {
+func (s *Store) CountAuditEntries(ctx context.Context, filter *audit.QueryFilter) (int64, error) {
+	if filter == nil {
+		filter = &audit.QueryFilter{}
+	}
+	count, err := s.mdb.NewFind((*auditEntryModel)(nil)).
+		Filter(auditFilter(filter)).
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: count audit entries: %w", err)
+	}
+	return count, nil
+}
+
+// auditFilter builds the bson filter shared by ListAuditEntries and
+// CountAuditEntries. Limit, Offset and ordering are not applied.
+func auditFilter(filter *audit.QueryFilter) bson.M {
+	f := bson.M{"tenant_id": filter.TenantID}
+	if filter.EntityType != "" {
+		f["entity_type"] = string(filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		f["entity_id"] = filter.EntityID
+	}
+	if filter.Operation != "" {
+		f["operation"] = string(filter.Operation)
+	}
+	if filter.Source != "" {
+		f["source"] = string(filter.Source)
+	}
+	if filter.ActorID != "" {
+		f["actor_id"] = filter.ActorID
+	}
+	if filter.AfterSeq > 0 {
+		f["seq"] = bson.M{"$gt": filter.AfterSeq}
+	}
+	created := bson.M{}
+	if filter.After != nil {
+		created["$gte"] = *filter.After
+	}
+	if filter.Before != nil {
+		created["$lte"] = *filter.Before
+	}
+	if len(created) > 0 {
+		f["created_at"] = created
+	}
+	return f
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateBreakGlassGrant(ctx context.Context, g *breakglass.Grant) error {
+	if g.ID.IsNil() {
+		g.ID = id.NewBreakGlassID()
+	}
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = now()
+	}
+	if _, err := s.mdb.NewInsert(breakGlassGrantToModel(g)).Exec(ctx); err != nil {
+		return fmt.Errorf("warden: create break-glass grant: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetBreakGlassGrant(ctx context.Context, grantID id.BreakGlassID) (*breakglass.Grant, error) {
+	var m breakGlassGrantModel
+	err := s.mdb.NewFind(&m).
+		Filter(bson.M{"_id": grantID.String()}).
+		Scan(ctx)
+	if err != nil {
+		if isNoDocuments(err) {
+			return nil, fmt.Errorf("break-glass grant %s: %w", grantID, errNotFound)
+		}
+		return nil, fmt.Errorf("warden: get break-glass grant: %w", err)
+	}
+	return breakGlassGrantFromModel(&m), nil
+}
+
+func (s *Store) UpdateBreakGlassGrant(ctx context.Context, g *breakglass.Grant) error {
+	m := breakGlassGrantToModel(g)
+	res, err := s.mdb.NewUpdate(m).
+		Filter(bson.M{"_id": m.ID}).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: update break-glass grant: %w", err)
+	}
+	if res.MatchedCount() == 0 {
+		return fmt.Errorf("break-glass grant %s: %w", g.ID, errNotFound)
+	}
+	return nil
+}
+
+func (s *Store) ListBreakGlassGrants(ctx context.Context, filter *breakglass.ListFilter) ([]*breakglass.Grant, error) {
 	f := bson.M{}
+	var limit, offset int
 	if filter != nil {
 		if filter.TenantID != "" {
 			f["tenant_id"] = filter.TenantID
@@ -1555,56 +2201,352 @@ func (s *Store) CountCheckLogs(ctx context.Context, filter *checklog.QueryFilter
 		if filter.SubjectID != "" {
 			f["subject_id"] = filter.SubjectID
 		}
-		if filter.Action != "" {
-			f["action"] = filter.Action
+		if filter.ActiveAt != nil {
+			f["revoked_at"] = nil
+			f["expires_at"] = bson.M{"$gt": *filter.ActiveAt}
 		}
-		if filter.ResourceType != "" {
-			f["resource_type"] = filter.ResourceType
+		limit, offset = filter.Limit, filter.Offset
+	}
+	var models []breakGlassGrantModel
+	q := s.mdb.NewFind(&models).
+		Filter(f).
+		Sort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		q = q.Limit(int64(limit))
+	}
+	if offset > 0 {
+		q = q.Skip(int64(offset))
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list break-glass grants: %w", err)
+	}
+	result := make([]*breakglass.Grant, len(models))
+	for i := range models {
+		result[i] = breakGlassGrantFromModel(&models[i])
+	}
+	return result, nil
+}
+
+// ──────────────────────────────────────────────────
+// Calendar operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateCalendar(ctx context.Context, c *calendar.Calendar) error {
+	if c.ID.IsNil() {
+		c.ID = id.NewCalendarID()
+	}
+	t := now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = t
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = t
+	}
+	if _, err := s.mdb.NewInsert(calendarToModel(c)).Exec(ctx); err != nil {
+		if mongod.IsDuplicateKeyError(err) {
+			return fmt.Errorf("calendar %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateCalendar)
 		}
-		if filter.ResourceID != "" {
-			f["resource_id"] = filter.ResourceID
+		return fmt.Errorf("warden: create calendar: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetCalendar(ctx context.Context, calID id.CalendarID) (*calendar.Calendar, error) {
+	var m calendarModel
+	err := s.mdb.NewFind(&m).
+		Filter(bson.M{"_id": calID.String()}).
+		Scan(ctx)
+	if err != nil {
+		if isNoDocuments(err) {
+			return nil, fmt.Errorf("calendar %s: %w", calID, errNotFound)
 		}
-		if filter.Decision != "" {
-			f["decision"] = filter.Decision
+		return nil, fmt.Errorf("warden: get calendar: %w", err)
+	}
+	return calendarFromModel(&m), nil
+}
+
+func (s *Store) GetCalendarByName(ctx context.Context, tenantID, name string) (*calendar.Calendar, error) {
+	var m calendarModel
+	err := s.mdb.NewFind(&m).
+		Filter(bson.M{"tenant_id": tenantID, "name": name}).
+		Scan(ctx)
+	if err != nil {
+		if isNoDocuments(err) {
+			return nil, fmt.Errorf("calendar %q in tenant %q: %w", name, tenantID, errNotFound)
 		}
-		if filter.After != nil || filter.Before != nil {
-			dateFilter := bson.M{}
-			if filter.After != nil {
-				dateFilter["$gte"] = *filter.After
-			}
-			if filter.Before != nil {
-				dateFilter["$lte"] = *filter.Before
-			}
-			f["created_at"] = dateFilter
+		return nil, fmt.Errorf("warden: get calendar by name: %w", err)
+	}
+	return calendarFromModel(&m), nil
+}
+
+func (s *Store) UpdateCalendar(ctx context.Context, c *calendar.Calendar) error {
+	c.UpdatedAt = now()
+	m := calendarToModel(c)
+	res, err := s.mdb.NewUpdate(m).
+		Filter(bson.M{"_id": m.ID}).
+		Exec(ctx)
+	if err != nil {
+		if mongod.IsDuplicateKeyError(err) {
+			return fmt.Errorf("calendar %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateCalendar)
 		}
+		return fmt.Errorf("warden: update calendar: %w", err)
 	}
-	count, err := s.mdb.NewFind((*checkLogModel)(nil)).
+	if res.MatchedCount() == 0 {
+		return fmt.Errorf("calendar %s: %w", c.ID, errNotFound)
+	}
+	return nil
+}
+
+func (s *Store) DeleteCalendar(ctx context.Context, calID id.CalendarID) error {
+	_, err := s.mdb.NewDelete((*calendarModel)(nil)).
+		Filter(bson.M{"_id": calID.String()}).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: delete calendar: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListCalendars(ctx context.Context, filter *calendar.ListFilter) ([]*calendar.Calendar, error) {
+	f := bson.M{}
+	var limit, offset int
+	if filter != nil {
+		if filter.TenantID != "" {
+			f["tenant_id"] = filter.TenantID
+		}
+		if filter.Search != "" {
+			f["name"] = bson.M{"$regex": filter.Search, "$options": "i"}
+		}
+		limit, offset = filter.Limit, filter.Offset
+	}
+	var models []calendarModel
+	q := s.mdb.NewFind(&models).
 		Filter(f).
-		Count(ctx)
+		Sort(bson.D{{Key: "name", Value: 1}})
+	if limit > 0 {
+		q = q.Limit(int64(limit))
+	}
+	if offset > 0 {
+		q = q.Skip(int64(offset))
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list calendars: %w", err)
+	}
+	result := make([]*calendar.Calendar, len(models))
+	for i := range models {
+		result[i] = calendarFromModel(&models[i])
+	}
+	return result, nil
+}
+
+func (s *Store) DeleteCalendarsByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.mdb.NewDelete((*calendarModel)(nil)).
+		Many().
+		Filter(bson.M{"tenant_id": tenantID}).
+		Exec(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("warden: count check logs: %w", err)
+		return fmt.Errorf("warden: delete calendars by tenant: %w", err)
 	}
-	return count, nil
+	return nil
 }
 
-func (s *Store) PurgeCheckLogs(ctx context.Context, before time.Time) (int64, error) {
-	res, err := s.mdb.NewDelete((*checkLogModel)(nil)).
+// ──────────────────────────────────────────────────
+// SoD constraint operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	if c.ID.IsNil() {
+		c.ID = id.NewSoDConstraintID()
+	}
+	t := now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = t
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = t
+	}
+	if _, err := s.mdb.NewInsert(sodConstraintToModel(c)).Exec(ctx); err != nil {
+		return fmt.Errorf("warden: create sod constraint: %w", err)
+	}
+	return s.policyChanged(ctx, c.TenantID)
+}
+
+func (s *Store) GetSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) (*sod.Constraint, error) {
+	var m sodConstraintModel
+	err := s.mdb.NewFind(&m).
+		Filter(bson.M{"_id": sodID.String()}).
+		Scan(ctx)
+	if err != nil {
+		if isNoDocuments(err) {
+			return nil, fmt.Errorf("sod constraint %s: %w", sodID, errNotFound)
+		}
+		return nil, fmt.Errorf("warden: get sod constraint: %w", err)
+	}
+	return sodConstraintFromModel(&m), nil
+}
+
+func (s *Store) UpdateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	c.UpdatedAt = now()
+	m := sodConstraintToModel(c)
+	res, err := s.mdb.NewUpdate(m).
+		Filter(bson.M{"_id": m.ID}).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: update sod constraint: %w", err)
+	}
+	if res.MatchedCount() == 0 {
+		return fmt.Errorf("sod constraint %s: %w", c.ID, errNotFound)
+	}
+	return s.policyChanged(ctx, c.TenantID)
+}
+
+func (s *Store) DeleteSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) error {
+	before, _ := s.GetSoDConstraint(ctx, sodID) //nolint:errcheck // missing → no change to report
+	_, err := s.mdb.NewDelete((*sodConstraintModel)(nil)).
+		Filter(bson.M{"_id": sodID.String()}).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: delete sod constraint: %w", err)
+	}
+	if before == nil {
+		return nil
+	}
+	return s.policyChanged(ctx, before.TenantID)
+}
+
+func (s *Store) ListSoDConstraints(ctx context.Context, filter *sod.ListFilter) ([]*sod.Constraint, error) {
+	f := bson.M{}
+	var limit, offset int
+	if filter != nil {
+		if filter.TenantID != "" {
+			f["tenant_id"] = filter.TenantID
+		}
+		if filter.Kind != "" {
+			f["kind"] = string(filter.Kind)
+		}
+		if filter.Role != "" {
+			f["roles"] = filter.Role
+		}
+		if filter.Search != "" {
+			f["name"] = bson.M{"$regex": filter.Search, "$options": "i"}
+		}
+		limit, offset = filter.Limit, filter.Offset
+	}
+	var models []sodConstraintModel
+	q := s.mdb.NewFind(&models).
+		Filter(f).
+		Sort(bson.D{{Key: "name", Value: 1}})
+	if limit > 0 {
+		q = q.Limit(int64(limit))
+	}
+	if offset > 0 {
+		q = q.Skip(int64(offset))
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list sod constraints: %w", err)
+	}
+	result := make([]*sod.Constraint, len(models))
+	for i := range models {
+		result[i] = sodConstraintFromModel(&models[i])
+	}
+	return result, nil
+}
+
+func (s *Store) DeleteSoDConstraintsByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.mdb.NewDelete((*sodConstraintModel)(nil)).
 		Many().
-		Filter(bson.M{"created_at": bson.M{"$lt": before}}).
+		Filter(bson.M{"tenant_id": tenantID}).
 		Exec(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("warden: purge check logs: %w", err)
+		return fmt.Errorf("warden: delete sod constraints by tenant: %w", err)
+	}
+	return s.policyChanged(ctx, tenantID)
+}
+
+// ──────────────────────────────────────────────────
+// Rate counter operations
+// ──────────────────────────────────────────────────
+
+// counterIndexes makes a bucket unique per counter, so concurrent
+// increments cannot create it twice, and lets purges scan by bucket.
+func counterIndexes() []mongod.IndexModel {
+	return []mongod.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1}, {Key: "subject_kind", Value: 1}, {Key: "subject_id", Value: 1},
+				{Key: "action", Value: 1}, {Key: "bucket", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "bucket", Value: 1}}},
+	}
+}
+
+func counterFilter(key counter.Key) bson.M {
+	return bson.M{
+		"tenant_id":    key.TenantID,
+		"subject_kind": key.SubjectKind,
+		"subject_id":   key.SubjectID,
+		"action":       key.Action,
+	}
+}
+
+func (s *Store) IncrementCounter(ctx context.Context, key counter.Key, bucket time.Time, n int64) error {
+	f := counterFilter(key)
+	f["bucket"] = bucket.UTC()
+	update := bson.M{"$inc": bson.M{"count": n}}
+	coll := s.mdb.Collection(colRateCounters)
+	_, err := coll.UpdateOne(ctx, f, update, options.UpdateOne().SetUpsert(true))
+	if mongod.IsDuplicateKeyError(err) {
+		// A concurrent increment created the bucket first.
+		_, err = coll.UpdateOne(ctx, f, update)
+	}
+	if err != nil {
+		return fmt.Errorf("warden: increment counter: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) SumCounter(ctx context.Context, key counter.Key, since time.Time) (int64, error) {
+	match := counterFilter(key)
+	match["bucket"] = bson.M{"$gte": since.UTC()}
+	cur, err := s.mdb.Collection(colRateCounters).Aggregate(ctx, mongod.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "total": bson.M{"$sum": "$count"}}}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("warden: sum counter: %w", err)
+	}
+	var rows []struct {
+		Total int64 `bson:"total"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return 0, fmt.Errorf("warden: sum counter: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Total, nil
+}
+
+func (s *Store) PurgeCounters(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.mdb.NewDelete((*counterModel)(nil)).
+		Many().
+		Filter(bson.M{"bucket": bson.M{"$lt": before.UTC()}}).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge counters: %w", err)
 	}
 	return res.DeletedCount(), nil
 }
 
-func (s *Store) DeleteCheckLogsByTenant(ctx context.Context, tenantID string) error {
-	_, err := s.mdb.NewDelete((*checkLogModel)(nil)).
+func (s *Store) DeleteCountersByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.mdb.NewDelete((*counterModel)(nil)).
 		Many().
 		Filter(bson.M{"tenant_id": tenantID}).
 		Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("warden: delete check logs by tenant: %w", err)
+		return fmt.Errorf("warden: delete counters by tenant: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,27 @@
+//go:build integration
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/store/contract"
+)
+
+// TestPostgres_Contracts runs the shared store contracts against a real
+// postgres instance, a fresh database per contract.
+func TestPostgres_Contracts(t *testing.T) {
+	mk := func(t *testing.T) (store.Store, func()) {
+		return setupPostgres(t)
+	}
+	t.Run("CheckLogQuery", func(t *testing.T) { contract.RunCheckLogQueryContract(t, mk) })
+	t.Run("Audit", func(t *testing.T) { contract.RunAuditContract(t, mk) })
+	t.Run("Counter", func(t *testing.T) { contract.RunCounterContract(t, mk) })
+	t.Run("SoD", func(t *testing.T) { contract.RunSoDContract(t, mk) })
+	t.Run("PolicyChange", func(t *testing.T) { contract.RunPolicyChangeContract(t, mk) })
+	t.Run("WebhookOutbox", func(t *testing.T) { contract.RunWebhookOutboxContract(t, mk) })
+	t.Run("PolicyRoundTrip", func(t *testing.T) { contract.RunPolicyRoundTripContract(t, mk) })
+	t.Run("BreakGlass", func(t *testing.T) { contract.RunBreakGlassContract(t, mk) })
+	t.Run("Calendar", func(t *testing.T) { contract.RunCalendarContract(t, mk) })
+}
@@ -530,6 +530,335 @@ ALTER TABLE warden_resource_types   ADD  CONSTRAINT warden_resource_types_tenant
 ALTER TABLE warden_assignments      DROP CONSTRAINT IF EXISTS warden_assignments_scope_key;
 ALTER TABLE warden_assignments      ADD  CONSTRAINT warden_assignments_tenant_id_role_id_subject_kind_subject_i_key
     UNIQUE (tenant_id, role_id, subject_kind, subject_id, resource_type, resource_id);
+`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "check_log_provenance",
+			Version: "20260301000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_check_logs
+    ADD COLUMN request_id  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN matched_by  JSONB NOT NULL DEFAULT '[]'::jsonb,
+    ADD COLUMN obligations JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_warden_clogs_request ON warden_check_logs (tenant_id, request_id);
+CREATE INDEX IF NOT EXISTS idx_warden_clogs_namespace ON warden_check_logs (tenant_id, namespace_path);
+CREATE INDEX IF NOT EXISTS idx_warden_clogs_matched ON warden_check_logs USING GIN (matched_by jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_warden_clogs_obligations ON warden_check_logs USING GIN (obligations jsonb_path_ops);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+DROP INDEX IF EXISTS idx_warden_clogs_obligations;
+DROP INDEX IF EXISTS idx_warden_clogs_matched;
+DROP INDEX IF EXISTS idx_warden_clogs_namespace;
+DROP INDEX IF EXISTS idx_warden_clogs_request;
+
+ALTER TABLE warden_check_logs
+    DROP COLUMN IF EXISTS request_id,
+    DROP COLUMN IF EXISTS matched_by,
+    DROP COLUMN IF EXISTS obligations;
+`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_webhook_deliveries",
+			Version: "20260401000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS warden_webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    endpoint        TEXT NOT NULL,
+    event           TEXT NOT NULL,
+    payload         TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_warden_webhooks_due ON warden_webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_warden_webhooks_updated ON warden_webhook_deliveries (updated_at);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `DROP TABLE IF EXISTS warden_webhook_deliveries`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_audit_entries",
+			Version: "20260501000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS warden_audit_entries (
+    id             TEXT PRIMARY KEY,
+    tenant_id      TEXT NOT NULL,
+    namespace_path TEXT NOT NULL DEFAULT '',
+    app_id         TEXT NOT NULL DEFAULT '',
+    seq            BIGINT NOT NULL,
+    actor_kind     TEXT NOT NULL DEFAULT '',
+    actor_id       TEXT NOT NULL DEFAULT '',
+    source         TEXT NOT NULL,
+    operation      TEXT NOT NULL,
+    entity_type    TEXT NOT NULL,
+    entity_id      TEXT NOT NULL DEFAULT '',
+    entity_name    TEXT NOT NULL DEFAULT '',
+    before_state   TEXT NOT NULL DEFAULT '',
+    after_state    TEXT NOT NULL DEFAULT '',
+    request_ip     TEXT NOT NULL DEFAULT '',
+    request_id     TEXT NOT NULL DEFAULT '',
+    prev_hash      TEXT NOT NULL DEFAULT '',
+    hash           TEXT NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warden_audit_chain ON warden_audit_entries (tenant_id, seq);
+CREATE INDEX IF NOT EXISTS idx_warden_audit_entity ON warden_audit_entries (tenant_id, entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_warden_audit_created ON warden_audit_entries (tenant_id, created_at);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `DROP TABLE IF EXISTS warden_audit_entries`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "policy_structured_obligations",
+			Version: "20260601000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_policies
+    ADD COLUMN IF NOT EXISTS structured_obligations JSONB,
+    ADD COLUMN IF NOT EXISTS advice                 JSONB;
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_policies
+    DROP COLUMN IF EXISTS structured_obligations,
+    DROP COLUMN IF EXISTS advice;
+`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "policy_mode",
+			Version: "20260701000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_policies ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT '';
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_policies DROP COLUMN IF EXISTS mode;
+`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_break_glass_grants",
+			Version: "20260801000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS warden_break_glass_grants (
+    id              TEXT PRIMARY KEY,
+    tenant_id       TEXT NOT NULL,
+    namespace_path  TEXT NOT NULL DEFAULT '',
+    app_id          TEXT NOT NULL DEFAULT '',
+    subject_kind    TEXT NOT NULL,
+    subject_id      TEXT NOT NULL,
+    justification   TEXT NOT NULL,
+    role_ids        JSONB,
+    bypass_policies JSONB,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    revoked_at      TIMESTAMPTZ,
+    revoked_by      TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_warden_break_glass_subject ON warden_break_glass_grants (tenant_id, subject_kind, subject_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_warden_break_glass_created ON warden_break_glass_grants (tenant_id, created_at);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `DROP TABLE IF EXISTS warden_break_glass_grants`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "resource_type_attributes",
+			Version: "20260901000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_resource_types ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '[]';
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_resource_types DROP COLUMN IF EXISTS attributes;
+`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "policy_on_error",
+			Version: "20260901000002",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_policies ADD COLUMN IF NOT EXISTS on_error TEXT NOT NULL DEFAULT '';
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_policies DROP COLUMN IF EXISTS on_error;
+`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_calendars",
+			Version: "20260901000003",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS warden_calendars (
+    id          TEXT PRIMARY KEY,
+    tenant_id   TEXT NOT NULL,
+    app_id      TEXT NOT NULL DEFAULT '',
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    time_zone   TEXT NOT NULL DEFAULT '',
+    dates       JSONB NOT NULL DEFAULT '[]',
+    metadata    JSONB NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warden_calendars_name ON warden_calendars (tenant_id, name);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `DROP TABLE IF EXISTS warden_calendars`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_rate_counters",
+			Version: "20260901000004",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS warden_rate_counters (
+    tenant_id    TEXT NOT NULL,
+    subject_kind TEXT NOT NULL,
+    subject_id   TEXT NOT NULL,
+    action       TEXT NOT NULL,
+    bucket       TIMESTAMPTZ NOT NULL,
+    count        BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, subject_kind, subject_id, action, bucket)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warden_rate_counters_bucket ON warden_rate_counters (bucket);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `DROP TABLE IF EXISTS warden_rate_counters`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_sod_constraints",
+			Version: "20260901000005",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS warden_sod_constraints (
+    id          TEXT PRIMARY KEY,
+    tenant_id   TEXT NOT NULL,
+    app_id      TEXT NOT NULL DEFAULT '',
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind        TEXT NOT NULL DEFAULT 'static',
+    roles       JSONB NOT NULL DEFAULT '[]',
+    max_roles   INTEGER NOT NULL DEFAULT 0,
+    metadata    JSONB NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warden_sod_constraints_name ON warden_sod_constraints (tenant_id, name);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `DROP TABLE IF EXISTS warden_sod_constraints`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "webhook_delivery_leases",
+			Version: "20261001000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_webhook_deliveries ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_webhook_deliveries DROP COLUMN IF EXISTS locked_until;
+`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "create_policy_versions",
+			Version: "20261101000001",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS warden_policy_versions (
+    tenant_id  TEXT PRIMARY KEY,
+    version    BIGINT NOT NULL DEFAULT 0,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_warden_policy_versions_changed ON warden_policy_versions (changed_at);
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `DROP TABLE IF EXISTS warden_policy_versions`)
+				return err
+			},
+		},
+		&migrate.Migration{
+			Name:    "webhook_delivery_lease_owners",
+			Version: "20261101000002",
+			Up: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_webhook_deliveries ADD COLUMN IF NOT EXISTS locked_by TEXT NOT NULL DEFAULT '';
+`)
+				return err
+			},
+			Down: func(ctx context.Context, exec migrate.Executor) error {
+				_, err := exec.Exec(ctx, `
+ALTER TABLE warden_webhook_deliveries DROP COLUMN IF EXISTS locked_by;
 `)
 				return err
 			},
@@ -1,12 +1,16 @@
 package postgres
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/xraph/grove"
 	"github.com/xraph/grove/drivers/pgdriver"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
@@ -14,6 +18,8 @@ import (
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/webhook"
 )
 
 // ──────────────────────────────────────────────────
@@ -295,9 +301,13 @@ type policyModel struct {
 	Effect          string                          `grove:"effect,notnull"`
 	Priority        int                             `grove:"priority,notnull"`
 	IsActive        bool                            `grove:"is_active,notnull"`
+	Mode            string                          `grove:"mode"`
+	OnError         string                          `grove:"on_error"`
 	NotBefore       *time.Time                      `grove:"not_before"`
 	NotAfter        *time.Time                      `grove:"not_after"`
 	Obligations     jsonbSlice[string]              `grove:"obligations,type:jsonb"`
+	Structured      jsonbSlice[policy.Obligation]   `grove:"structured_obligations,type:jsonb"`
+	Advice          jsonbSlice[policy.Obligation]   `grove:"advice,type:jsonb"`
 	Version         int                             `grove:"version,notnull"`
 	Subjects        jsonbSlice[policy.SubjectMatch] `grove:"subjects,type:jsonb"`
 	Actions         jsonbSlice[string]              `grove:"actions,type:jsonb"`
@@ -323,9 +333,13 @@ func policyToModel(p *policy.Policy) *policyModel {
 		Effect:        string(p.Effect),
 		Priority:      p.Priority,
 		IsActive:      p.IsActive,
+		Mode:          string(p.Mode),
+		OnError:       string(p.OnError),
 		NotBefore:     p.NotBefore,
 		NotAfter:      p.NotAfter,
 		Obligations:   jsonbSlice[string](p.Obligations),
+		Structured:    jsonbSlice[policy.Obligation](p.StructuredObligations),
+		Advice:        jsonbSlice[policy.Obligation](p.Advice),
 		Version:       p.Version,
 		Subjects:      jsonbSlice[policy.SubjectMatch](p.Subjects),
 		Actions:       jsonbSlice[string](p.Actions),
@@ -340,26 +354,30 @@ func policyToModel(p *policy.Policy) *policyModel {
 func policyFromModel(m *policyModel) *policy.Policy {
 	pid, _ := id.ParsePolicyID(m.ID) //nolint:errcheck // stored IDs are always valid
 	return &policy.Policy{
-		ID:            pid,
-		TenantID:      m.TenantID,
-		NamespacePath: m.NamespacePath,
-		AppID:         m.AppID,
-		Name:          m.Name,
-		Description:   m.Description,
-		Effect:        policy.Effect(m.Effect),
-		Priority:      m.Priority,
-		IsActive:      m.IsActive,
-		NotBefore:     m.NotBefore,
-		NotAfter:      m.NotAfter,
-		Obligations:   []string(m.Obligations),
-		Version:       m.Version,
-		Subjects:      []policy.SubjectMatch(m.Subjects),
-		Actions:       []string(m.Actions),
-		Resources:     []string(m.Resources),
-		Conditions:    []policy.Condition(m.Conditions),
-		Metadata:      map[string]any(m.Metadata),
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
+		ID:                    pid,
+		TenantID:              m.TenantID,
+		NamespacePath:         m.NamespacePath,
+		AppID:                 m.AppID,
+		Name:                  m.Name,
+		Description:           m.Description,
+		Effect:                policy.Effect(m.Effect),
+		Priority:              m.Priority,
+		IsActive:              m.IsActive,
+		Mode:                  policy.Mode(m.Mode),
+		OnError:               policy.OnError(m.OnError),
+		NotBefore:             m.NotBefore,
+		NotAfter:              m.NotAfter,
+		Obligations:           []string(m.Obligations),
+		StructuredObligations: []policy.Obligation(m.Structured),
+		Advice:                []policy.Obligation(m.Advice),
+		Version:               m.Version,
+		Subjects:              []policy.SubjectMatch(m.Subjects),
+		Actions:               []string(m.Actions),
+		Resources:             []string(m.Resources),
+		Conditions:            []policy.Condition(m.Conditions),
+		Metadata:              map[string]any(m.Metadata),
+		CreatedAt:             m.CreatedAt,
+		UpdatedAt:             m.UpdatedAt,
 	}
 }
 
@@ -377,6 +395,7 @@ type resourceTypeModel struct {
 	Description     string                                 `grove:"description"`
 	Relations       jsonbSlice[resourcetype.RelationDef]   `grove:"relations,type:jsonb"`
 	Permissions     jsonbSlice[resourcetype.PermissionDef] `grove:"permissions,type:jsonb"`
+	Attributes      jsonbSlice[resourcetype.AttributeDef]  `grove:"attributes,type:jsonb"`
 	Metadata        pgdriver.JSONMap                       `grove:"metadata,type:jsonb"`
 	CreatedAt       time.Time                              `grove:"created_at,notnull"`
 	UpdatedAt       time.Time                              `grove:"updated_at,notnull"`
@@ -396,6 +415,7 @@ func resourceTypeToModel(rt *resourcetype.ResourceType) *resourceTypeModel {
 		Description:   rt.Description,
 		Relations:     jsonbSlice[resourcetype.RelationDef](rt.Relations),
 		Permissions:   jsonbSlice[resourcetype.PermissionDef](rt.Permissions),
+		Attributes:    jsonbSlice[resourcetype.AttributeDef](rt.Attributes),
 		Metadata:      md,
 		CreatedAt:     rt.CreatedAt,
 		UpdatedAt:     rt.UpdatedAt,
@@ -413,6 +433,7 @@ func resourceTypeFromModel(m *resourceTypeModel) *resourcetype.ResourceType {
 		Description:   m.Description,
 		Relations:     []resourcetype.RelationDef(m.Relations),
 		Permissions:   []resourcetype.PermissionDef(m.Permissions),
+		Attributes:    []resourcetype.AttributeDef(m.Attributes),
 		Metadata:      map[string]any(m.Metadata),
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
@@ -425,21 +446,24 @@ func resourceTypeFromModel(m *resourceTypeModel) *resourcetype.ResourceType {
 
 type checkLogModel struct {
 	grove.BaseModel `grove:"table:warden_check_logs"`
-	ID              string           `grove:"id,pk"`
-	TenantID        string           `grove:"tenant_id,notnull"`
-	NamespacePath   string           `grove:"namespace_path,notnull"`
-	AppID           string           `grove:"app_id,notnull"`
-	SubjectKind     string           `grove:"subject_kind,notnull"`
-	SubjectID       string           `grove:"subject_id,notnull"`
-	Action          string           `grove:"action,notnull"`
-	ResourceType    string           `grove:"resource_type,notnull"`
-	ResourceID      string           `grove:"resource_id,notnull"`
-	Decision        string           `grove:"decision,notnull"`
-	Reason          string           `grove:"reason"`
-	EvalTimeNs      int64            `grove:"eval_time_ns,notnull"`
-	RequestIP       string           `grove:"request_ip"`
-	Metadata        pgdriver.JSONMap `grove:"metadata,type:jsonb"`
-	CreatedAt       time.Time        `grove:"created_at,notnull"`
+	ID              string                     `grove:"id,pk"`
+	TenantID        string                     `grove:"tenant_id,notnull"`
+	NamespacePath   string                     `grove:"namespace_path,notnull"`
+	AppID           string                     `grove:"app_id,notnull"`
+	SubjectKind     string                     `grove:"subject_kind,notnull"`
+	SubjectID       string                     `grove:"subject_id,notnull"`
+	Action          string                     `grove:"action,notnull"`
+	ResourceType    string                     `grove:"resource_type,notnull"`
+	ResourceID      string                     `grove:"resource_id,notnull"`
+	Decision        string                     `grove:"decision,notnull"`
+	Reason          string                     `grove:"reason"`
+	EvalTimeNs      int64                      `grove:"eval_time_ns,notnull"`
+	RequestIP       string                     `grove:"request_ip"`
+	RequestID       string                     `grove:"request_id"`
+	MatchedBy       jsonbSlice[checklog.Match] `grove:"matched_by,type:jsonb"`
+	Obligations     jsonbSlice[string]         `grove:"obligations,type:jsonb"`
+	Metadata        pgdriver.JSONMap           `grove:"metadata,type:jsonb"`
+	CreatedAt       time.Time                  `grove:"created_at,notnull"`
 }
 
 func checkLogToModel(e *checklog.Entry) *checkLogModel {
@@ -447,6 +471,15 @@ func checkLogToModel(e *checklog.Entry) *checkLogModel {
 	if md == nil {
 		md = pgdriver.JSONMap{}
 	}
+	// The JSONB array columns are NOT NULL; store empty arrays, not NULL.
+	matched := jsonbSlice[checklog.Match](e.MatchedBy)
+	if matched == nil {
+		matched = jsonbSlice[checklog.Match]{}
+	}
+	obligations := jsonbSlice[string](e.Obligations)
+	if obligations == nil {
+		obligations = jsonbSlice[string]{}
+	}
 	return &checkLogModel{
 		ID:            e.ID.String(),
 		TenantID:      e.TenantID,
@@ -461,6 +494,9 @@ func checkLogToModel(e *checklog.Entry) *checkLogModel {
 		Reason:        e.Reason,
 		EvalTimeNs:    e.EvalTimeNs,
 		RequestIP:     e.RequestIP,
+		RequestID:     e.RequestID,
+		MatchedBy:     matched,
+		Obligations:   obligations,
 		Metadata:      md,
 		CreatedAt:     e.CreatedAt,
 	}
@@ -482,7 +518,345 @@ func checkLogFromModel(m *checkLogModel) *checklog.Entry {
 		Reason:        m.Reason,
 		EvalTimeNs:    m.EvalTimeNs,
 		RequestIP:     m.RequestIP,
+		RequestID:     m.RequestID,
+		MatchedBy:     []checklog.Match(m.MatchedBy),
+		Obligations:   []string(m.Obligations),
 		Metadata:      map[string]any(m.Metadata),
 		CreatedAt:     m.CreatedAt,
 	}
 }
+
+// ──────────────────────────────────────────────────
+// Webhook delivery model
+// ──────────────────────────────────────────────────
+
+type webhookDeliveryModel struct {
+	grove.BaseModel `grove:"table:warden_webhook_deliveries"`
+	ID              string     `grove:"id,pk"`
+	Endpoint        string     `grove:"endpoint,notnull"`
+	Event           string     `grove:"event,notnull"`
+	Payload         string     `grove:"payload,notnull"` // signed verbatim, so kept as TEXT
+	Status          string     `grove:"status,notnull"`
+	Attempts        int        `grove:"attempts,notnull"`
+	NextAttemptAt   time.Time  `grove:"next_attempt_at,notnull"`
+	LastError       string     `grove:"last_error"`
+	LockedBy        string     `grove:"locked_by"`
+	LockedUntil     *time.Time `grove:"locked_until"`
+	CreatedAt       time.Time  `grove:"created_at,notnull"`
+	UpdatedAt       time.Time  `grove:"updated_at,notnull"`
+}
+
+func webhookDeliveryToModel(d *webhook.Delivery) *webhookDeliveryModel {
+	return &webhookDeliveryModel{
+		ID:            d.ID.String(),
+		Endpoint:      d.Endpoint,
+		Event:         d.Event,
+		Payload:       string(d.Payload),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+		LockedBy:      d.LockedBy,
+		LockedUntil:   d.LockedUntil,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+func webhookDeliveryFromModel(m *webhookDeliveryModel) *webhook.Delivery {
+	did, _ := id.ParseWebhookDeliveryID(m.ID) //nolint:errcheck // stored IDs are always valid
+	return &webhook.Delivery{
+		ID:            did,
+		Endpoint:      m.Endpoint,
+		Event:         m.Event,
+		Payload:       json.RawMessage(m.Payload),
+		Status:        webhook.Status(m.Status),
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		LastError:     m.LastError,
+		LockedBy:      m.LockedBy,
+		LockedUntil:   m.LockedUntil,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Audit entry model
+// ──────────────────────────────────────────────────
+
+type auditEntryModel struct {
+	grove.BaseModel `grove:"table:warden_audit_entries"`
+	ID              string    `grove:"id,pk"`
+	TenantID        string    `grove:"tenant_id,notnull"`
+	NamespacePath   string    `grove:"namespace_path,notnull"`
+	AppID           string    `grove:"app_id,notnull"`
+	Seq             int64     `grove:"seq,notnull"`
+	ActorKind       string    `grove:"actor_kind"`
+	ActorID         string    `grove:"actor_id"`
+	Source          string    `grove:"source,notnull"`
+	Operation       string    `grove:"operation,notnull"`
+	EntityType      string    `grove:"entity_type,notnull"`
+	EntityID        string    `grove:"entity_id"`
+	EntityName      string    `grove:"entity_name"`
+	Before          string    `grove:"before_state"` // hashed verbatim, so kept as TEXT
+	After           string    `grove:"after_state"`  // hashed verbatim, so kept as TEXT
+	RequestIP       string    `grove:"request_ip"`
+	RequestID       string    `grove:"request_id"`
+	PrevHash        string    `grove:"prev_hash"`
+	Hash            string    `grove:"hash,notnull"`
+	CreatedAt       time.Time `grove:"created_at,notnull"`
+}
+
+func auditEntryToModel(e *audit.Entry) *auditEntryModel {
+	return &auditEntryModel{
+		ID:            e.ID.String(),
+		TenantID:      e.TenantID,
+		NamespacePath: e.NamespacePath,
+		AppID:         e.AppID,
+		Seq:           e.Seq,
+		ActorKind:     e.Actor.Kind,
+		ActorID:       e.Actor.ID,
+		Source:        string(e.Source),
+		Operation:     string(e.Operation),
+		EntityType:    string(e.EntityType),
+		EntityID:      e.EntityID,
+		EntityName:    e.EntityName,
+		Before:        string(e.Before),
+		After:         string(e.After),
+		RequestIP:     e.RequestIP,
+		RequestID:     e.RequestID,
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func auditEntryFromModel(m *auditEntryModel) *audit.Entry {
+	aid, _ := id.ParseAuditID(m.ID) //nolint:errcheck // stored IDs are always valid
+	e := &audit.Entry{
+		ID:            aid,
+		TenantID:      m.TenantID,
+		NamespacePath: m.NamespacePath,
+		AppID:         m.AppID,
+		Seq:           m.Seq,
+		Actor:         audit.Actor{Kind: m.ActorKind, ID: m.ActorID},
+		Source:        audit.Source(m.Source),
+		Operation:     audit.Operation(m.Operation),
+		EntityType:    audit.EntityType(m.EntityType),
+		EntityID:      m.EntityID,
+		EntityName:    m.EntityName,
+		RequestIP:     m.RequestIP,
+		RequestID:     m.RequestID,
+		PrevHash:      m.PrevHash,
+		Hash:          m.Hash,
+		CreatedAt:     m.CreatedAt,
+	}
+	if m.Before != "" {
+		e.Before = json.RawMessage(m.Before)
+	}
+	if m.After != "" {
+		e.After = json.RawMessage(m.After)
+	}
+	return e
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass grant model
+// ──────────────────────────────────────────────────
+
+type breakGlassGrantModel struct {
+	grove.BaseModel `grove:"table:warden_break_glass_grants"`
+	ID              string                `grove:"id,pk"`
+	TenantID        string                `grove:"tenant_id,notnull"`
+	NamespacePath   string                `grove:"namespace_path,notnull"`
+	AppID           string                `grove:"app_id,notnull"`
+	SubjectKind     string                `grove:"subject_kind,notnull"`
+	SubjectID       string                `grove:"subject_id,notnull"`
+	Justification   string                `grove:"justification,notnull"`
+	RoleIDs         jsonbSlice[id.RoleID] `grove:"role_ids,type:jsonb"`
+	BypassPolicies  jsonbSlice[string]    `grove:"bypass_policies,type:jsonb"`
+	ExpiresAt       time.Time             `grove:"expires_at,notnull"`
+	RevokedAt       *time.Time            `grove:"revoked_at"`
+	RevokedBy       string                `grove:"revoked_by"`
+	CreatedAt       time.Time             `grove:"created_at,notnull"`
+}
+
+func breakGlassGrantToModel(g *breakglass.Grant) *breakGlassGrantModel {
+	return &breakGlassGrantModel{
+		ID:             g.ID.String(),
+		TenantID:       g.TenantID,
+		NamespacePath:  g.NamespacePath,
+		AppID:          g.AppID,
+		SubjectKind:    g.SubjectKind,
+		SubjectID:      g.SubjectID,
+		Justification:  g.Justification,
+		RoleIDs:        jsonbSlice[id.RoleID](g.RoleIDs),
+		BypassPolicies: jsonbSlice[string](g.BypassPolicies),
+		ExpiresAt:      g.ExpiresAt,
+		RevokedAt:      g.RevokedAt,
+		RevokedBy:      g.RevokedBy,
+		CreatedAt:      g.CreatedAt,
+	}
+}
+
+func breakGlassGrantFromModel(m *breakGlassGrantModel) *breakglass.Grant {
+	gid, _ := id.ParseBreakGlassID(m.ID) //nolint:errcheck // stored IDs are always valid
+	return &breakglass.Grant{
+		ID:             gid,
+		TenantID:       m.TenantID,
+		NamespacePath:  m.NamespacePath,
+		AppID:          m.AppID,
+		SubjectKind:    m.SubjectKind,
+		SubjectID:      m.SubjectID,
+		Justification:  m.Justification,
+		RoleIDs:        []id.RoleID(m.RoleIDs),
+		BypassPolicies: []string(m.BypassPolicies),
+		ExpiresAt:      m.ExpiresAt,
+		RevokedAt:      m.RevokedAt,
+		RevokedBy:      m.RevokedBy,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Calendar model
+// ──────────────────────────────────────────────────
+
+type calendarModel struct {
+	grove.BaseModel `grove:"table:warden_calendars"`
+	ID              string                    `grove:"id,pk"`
+	TenantID        string                    `grove:"tenant_id,notnull"`
+	AppID           string                    `grove:"app_id,notnull"`
+	Name            string                    `grove:"name,notnull"`
+	Description     string                    `grove:"description"`
+	TimeZone        string                    `grove:"time_zone,notnull"`
+	Dates           jsonbSlice[calendar.Date] `grove:"dates,type:jsonb"`
+	Metadata        pgdriver.JSONMap          `grove:"metadata,type:jsonb"`
+	CreatedAt       time.Time                 `grove:"created_at,notnull"`
+	UpdatedAt       time.Time                 `grove:"updated_at,notnull"`
+}
+
+func calendarToModel(c *calendar.Calendar) *calendarModel {
+	md := pgdriver.JSONMap(c.Metadata)
+	if md == nil {
+		md = pgdriver.JSONMap{}
+	}
+	return &calendarModel{
+		ID:          c.ID.String(),
+		TenantID:    c.TenantID,
+		AppID:       c.AppID,
+		Name:        c.Name,
+		Description: c.Description,
+		TimeZone:    c.TimeZone,
+		Dates:       jsonbSlice[calendar.Date](c.Dates),
+		Metadata:    md,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+func calendarFromModel(m *calendarModel) *calendar.Calendar {
+	cid, _ := id.ParseCalendarID(m.ID) //nolint:errcheck // stored IDs are always valid
+	return &calendar.Calendar{
+		ID:          cid,
+		TenantID:    m.TenantID,
+		AppID:       m.AppID,
+		Name:        m.Name,
+		Description: m.Description,
+		TimeZone:    m.TimeZone,
+		Dates:       []calendar.Date(m.Dates),
+		Metadata:    map[string]any(m.Metadata),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// SoD constraint model
+// ──────────────────────────────────────────────────
+
+type sodConstraintModel struct {
+	grove.BaseModel `grove:"table:warden_sod_constraints"`
+	ID              string             `grove:"id,pk"`
+	TenantID        string             `grove:"tenant_id,notnull"`
+	AppID           string             `grove:"app_id,notnull"`
+	Name            string             `grove:"name,notnull"`
+	Description     string             `grove:"description"`
+	Kind            string             `grove:"kind,notnull"`
+	Roles           jsonbSlice[string] `grove:"roles,type:jsonb"`
+	MaxRoles        int                `grove:"max_roles,notnull"`
+	Metadata        pgdriver.JSONMap   `grove:"metadata,type:jsonb"`
+	CreatedAt       time.Time          `grove:"created_at,notnull"`
+	UpdatedAt       time.Time          `grove:"updated_at,notnull"`
+}
+
+func sodConstraintToModel(c *sod.Constraint) *sodConstraintModel {
+	md := pgdriver.JSONMap(c.Metadata)
+	if md == nil {
+		md = pgdriver.JSONMap{}
+	}
+	roles := c.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	return &sodConstraintModel{
+		ID:          c.ID.String(),
+		TenantID:    c.TenantID,
+		AppID:       c.AppID,
+		Name:        c.Name,
+		Description: c.Description,
+		Kind:        string(c.Kind),
+		Roles:       jsonbSlice[string](roles),
+		MaxRoles:    c.MaxRoles,
+		Metadata:    md,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+func sodConstraintFromModel(m *sodConstraintModel) *sod.Constraint {
+	sid, _ := id.ParseSoDConstraintID(m.ID) //nolint:errcheck // stored IDs are always valid
+	return &sod.Constraint{
+		ID:          sid,
+		TenantID:    m.TenantID,
+		AppID:       m.AppID,
+		Name:        m.Name,
+		Description: m.Description,
+		Kind:        sod.Kind(m.Kind),
+		Roles:       []string(m.Roles),
+		MaxRoles:    m.MaxRoles,
+		Metadata:    map[string]any(m.Metadata),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Policy version model
+// ──────────────────────────────────────────────────
+
+type policyVersionModel struct {
+	grove.BaseModel `grove:"table:warden_policy_versions"`
+	TenantID        string    `grove:"tenant_id,pk"`
+	Version         int64     `grove:"version,notnull"`
+	ChangedAt       time.Time `grove:"changed_at,notnull"`
+}
+
+// ──────────────────────────────────────────────────
+// Rate counter model
+// ──────────────────────────────────────────────────
+
+type counterModel struct {
+	grove.BaseModel `grove:"table:warden_rate_counters"`
+	TenantID        string    `grove:"tenant_id,pk"`
+	SubjectKind     string    `grove:"subject_kind,pk"`
+	SubjectID       string    `grove:"subject_id,pk"`
+	Action          string    `grove:"action,pk"`
+	Bucket          time.Time `grove:"bucket,pk"`
+	Count           int64     `grove:"count,notnull"`
+}
+
+type counterSumRow struct {
+	Total int64 `grove:"total"`
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/policy"
+)
+
+// BenchmarkSQLite_ABACPolicyCount is warden's BenchmarkABACPolicyCount
+// against an on-disk sqlite store, where listing the active policies is a
+// query rather than a map scan. With the policy index, which sqlite
+// enables by default since it reports policy changes, a check evaluates
+// only the matching policy; without it every check queries and decodes
+// all N policies.
+func BenchmarkSQLite_ABACPolicyCount(b *testing.B) {
+	for _, indexed := range []bool{true, false} {
+		for _, n := range []int{10, 100, 1000} {
+			name := "index/N=" + strconv.Itoa(n)
+			cfg := warden.Config{EnableCheckLog: new(bool)}
+			if !indexed {
+				name = "store/N=" + strconv.Itoa(n)
+				cfg.PolicyIndexTTL = -1
+			}
+			b.Run(name, func(b *testing.B) {
+				ctx := warden.WithTenant(context.Background(), "app1", "t1")
+				s, cleanup := openStore(b, filepath.Join(b.TempDir(), "warden.db"))
+				defer cleanup()
+				eng, err := warden.NewEngine(warden.WithStore(s), warden.WithConfig(cfg))
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer eng.Stop(ctx) //nolint:errcheck // benchmark teardown
+				for i := 0; i < n; i++ {
+					err := s.CreatePolicy(ctx, &policy.Policy{
+						ID: id.NewPolicyID(), TenantID: "t1", Name: "p" + strconv.Itoa(i),
+						Effect: policy.EffectAllow, IsActive: true,
+						Actions: []string{"read"}, Resources: []string{"type" + strconv.Itoa(i) + ":*"},
+						Conditions: []policy.Condition{
+							{Field: "subject.email", Operator: policy.OpRegex, Value: `@example\.com$`},
+							{Field: "context.ip", Operator: policy.OpIPInCIDR, Value: "10.0.0.0/8"},
+						},
+					})
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				req := &warden.CheckRequest{
+					Subject:  warden.Subject{Kind: warden.SubjectUser, ID: "u1", Attributes: map[string]any{"email": "u1@example.com"}},
+					Action:   warden.Action{Name: "read"},
+					Resource: warden.Resource{Type: "type" + strconv.Itoa(n/2), ID: "r1"},
+					Context:  map[string]any{"ip": "10.1.2.3"},
+				}
+				// Warm the index so its one-off build is not measured.
+				if _, err := eng.Check(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					result, err := eng.Check(ctx, req)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if !result.Allowed {
+						b.Fatalf("expected allow at N=%d, got %s", n, result.Decision)
+					}
+				}
+			})
+		}
+	}
+}
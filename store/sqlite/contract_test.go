@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xraph/grove"
+	"github.com/xraph/grove/drivers/sqlitedriver"
+	_ "github.com/xraph/grove/drivers/sqlitedriver/sqlitemigrate"
+
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/store"
+	"github.com/xraph/warden/store/contract"
+)
+
+// openStore opens a migrated store on the sqlite file at dbPath.
+func openStore(t testing.TB, dbPath string) (*Store, func()) {
+	t.Helper()
+	drv := sqlitedriver.New()
+	if err := drv.Open(context.Background(), dbPath); err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db, err := grove.Open(drv)
+	if err != nil {
+		_ = drv.Close()
+		t.Fatalf("grove open: %v", err)
+	}
+	s := New(db)
+	if err := s.Migrate(context.Background()); err != nil {
+		_ = drv.Close()
+		t.Fatalf("migrate: %v", err)
+	}
+	return s, func() {
+		s.policyChanges.Close()
+		_ = drv.Close()
+	}
+}
+
+// TestSQLite_Contracts runs the shared store contracts against an on-disk
+// sqlite store, a fresh file per contract.
+func TestSQLite_Contracts(t *testing.T) {
+	mk := func(t *testing.T) (store.Store, func()) {
+		return openStore(t, filepath.Join(t.TempDir(), "warden.db"))
+	}
+	t.Run("CheckLogQuery", func(t *testing.T) { contract.RunCheckLogQueryContract(t, mk) })
+	t.Run("Audit", func(t *testing.T) { contract.RunAuditContract(t, mk) })
+	t.Run("Counter", func(t *testing.T) { contract.RunCounterContract(t, mk) })
+	t.Run("SoD", func(t *testing.T) { contract.RunSoDContract(t, mk) })
+	t.Run("PolicyChange", func(t *testing.T) { contract.RunPolicyChangeContract(t, mk) })
+	t.Run("WebhookOutbox", func(t *testing.T) { contract.RunWebhookOutboxContract(t, mk) })
+	t.Run("PolicyRoundTrip", func(t *testing.T) { contract.RunPolicyRoundTripContract(t, mk) })
+	t.Run("BreakGlass", func(t *testing.T) { contract.RunBreakGlassContract(t, mk) })
+	t.Run("Calendar", func(t *testing.T) { contract.RunCalendarContract(t, mk) })
+}
+
+// TestSQLite_PolicyChangesAcrossStores checks that a policy written through
+// one store is reported to the watchers of another store sharing the
+// database file, as happens when several processes share it.
+func TestSQLite_PolicyChangesAcrossStores(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "warden.db")
+	writer, closeWriter := openStore(t, dbPath)
+	defer closeWriter()
+	reader, closeReader := openStore(t, dbPath)
+	defer closeReader()
+	reader.policyChanges = policy.NewChangeFeed(reader.pollPolicyChanges, 10*time.Millisecond)
+
+	got := make(chan string, 16)
+	stop := reader.NotifyPolicyChanges(func(tenantID string) { got <- tenantID })
+	defer stop()
+
+	if err := writer.CreatePolicy(context.Background(), &policy.Policy{
+		ID: id.NewPolicyID(), TenantID: "t1", Name: "allow-read", Effect: policy.EffectAllow,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case tenant := <-got:
+		if tenant != "t1" {
+			t.Fatalf("expected t1 to be reported, got %q", tenant)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the other store's write to be reported")
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/xraph/grove"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
@@ -15,6 +18,8 @@ import (
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/webhook"
 )
 
 // ──────────────────────────────────────────────────
@@ -328,9 +333,13 @@ type policyModel struct {
 	Effect          string      `grove:"effect,notnull"`
 	Priority        int         `grove:"priority,notnull"`
 	IsActive        bool        `grove:"is_active,notnull"`
+	Mode            string      `grove:"mode"`
+	OnError         string      `grove:"on_error"`
 	NotBefore       *sqliteTime `grove:"not_before"`
 	NotAfter        *sqliteTime `grove:"not_after"`
-	Obligations     string      `grove:"obligations"` // JSON text
+	Obligations     string      `grove:"obligations"`            // JSON text
+	Structured      string      `grove:"structured_obligations"` // JSON text
+	Advice          string      `grove:"advice"`                 // JSON text
 	Version         int         `grove:"version,notnull"`
 	Subjects        string      `grove:"subjects"`   // JSON text
 	Actions         string      `grove:"actions"`    // JSON text
@@ -366,6 +375,14 @@ func policyToModel(p *policy.Policy) (*policyModel, error) {
 	if err != nil {
 		return nil, fmt.Errorf("marshal policy obligations: %w", err)
 	}
+	structured, err := json.Marshal(p.StructuredObligations)
+	if err != nil {
+		return nil, fmt.Errorf("marshal policy structured obligations: %w", err)
+	}
+	advice, err := json.Marshal(p.Advice)
+	if err != nil {
+		return nil, fmt.Errorf("marshal policy advice: %w", err)
+	}
 	m := &policyModel{
 		ID:            p.ID.String(),
 		TenantID:      p.TenantID,
@@ -376,8 +393,12 @@ func policyToModel(p *policy.Policy) (*policyModel, error) {
 		Effect:        string(p.Effect),
 		Priority:      p.Priority,
 		IsActive:      p.IsActive,
+		Mode:          string(p.Mode),
+		OnError:       string(p.OnError),
 		Version:       p.Version,
 		Obligations:   string(obligations),
+		Structured:    string(structured),
+		Advice:        string(advice),
 		Subjects:      string(subjects),
 		Actions:       string(actions),
 		Resources:     string(resources),
@@ -436,25 +457,41 @@ func policyFromModel(m *policyModel) (*policy.Policy, error) {
 			return nil, fmt.Errorf("unmarshal policy obligations: %w", err)
 		}
 	}
+	var structured []policy.Obligation
+	if m.Structured != "" {
+		if err := json.Unmarshal([]byte(m.Structured), &structured); err != nil {
+			return nil, fmt.Errorf("unmarshal policy structured obligations: %w", err)
+		}
+	}
+	var advice []policy.Obligation
+	if m.Advice != "" {
+		if err := json.Unmarshal([]byte(m.Advice), &advice); err != nil {
+			return nil, fmt.Errorf("unmarshal policy advice: %w", err)
+		}
+	}
 	out := &policy.Policy{
-		ID:            pid,
-		TenantID:      m.TenantID,
-		NamespacePath: m.NamespacePath,
-		AppID:         m.AppID,
-		Name:          m.Name,
-		Description:   m.Description,
-		Effect:        policy.Effect(m.Effect),
-		Priority:      m.Priority,
-		IsActive:      m.IsActive,
-		Obligations:   obligations,
-		Version:       m.Version,
-		Subjects:      subjects,
-		Actions:       actions,
-		Resources:     resources,
-		Conditions:    conditions,
-		Metadata:      metadata,
-		CreatedAt:     time.Time(m.CreatedAt),
-		UpdatedAt:     time.Time(m.UpdatedAt),
+		ID:                    pid,
+		TenantID:              m.TenantID,
+		NamespacePath:         m.NamespacePath,
+		AppID:                 m.AppID,
+		Name:                  m.Name,
+		Description:           m.Description,
+		Effect:                policy.Effect(m.Effect),
+		Priority:              m.Priority,
+		IsActive:              m.IsActive,
+		Mode:                  policy.Mode(m.Mode),
+		OnError:               policy.OnError(m.OnError),
+		Obligations:           obligations,
+		StructuredObligations: structured,
+		Advice:                advice,
+		Version:               m.Version,
+		Subjects:              subjects,
+		Actions:               actions,
+		Resources:             resources,
+		Conditions:            conditions,
+		Metadata:              metadata,
+		CreatedAt:             time.Time(m.CreatedAt),
+		UpdatedAt:             time.Time(m.UpdatedAt),
 	}
 	if m.NotBefore != nil {
 		v := time.Time(*m.NotBefore)
@@ -491,6 +528,7 @@ type resourceTypeModel struct {
 	Description     string     `grove:"description"`
 	Relations       string     `grove:"relations"`   // JSON text
 	Permissions     string     `grove:"permissions"` // JSON text
+	Attributes      string     `grove:"attributes"`  // JSON text
 	Metadata        string     `grove:"metadata"`    // JSON text
 	CreatedAt       sqliteTime `grove:"created_at,notnull"`
 	UpdatedAt       sqliteTime `grove:"updated_at,notnull"`
@@ -505,6 +543,10 @@ func resourceTypeToModel(rt *resourcetype.ResourceType) (*resourceTypeModel, err
 	if err != nil {
 		return nil, fmt.Errorf("marshal resource type permissions: %w", err)
 	}
+	attributes, err := json.Marshal(rt.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("marshal resource type attributes: %w", err)
+	}
 	metadata, err := json.Marshal(rt.Metadata)
 	if err != nil {
 		return nil, fmt.Errorf("marshal resource type metadata: %w", err)
@@ -518,6 +560,7 @@ func resourceTypeToModel(rt *resourcetype.ResourceType) (*resourceTypeModel, err
 		Description:   rt.Description,
 		Relations:     string(relations),
 		Permissions:   string(permissions),
+		Attributes:    string(attributes),
 		Metadata:      string(metadata),
 		CreatedAt:     sqliteTime(rt.CreatedAt),
 		UpdatedAt:     sqliteTime(rt.UpdatedAt),
@@ -539,6 +582,12 @@ func resourceTypeFromModel(m *resourceTypeModel) (*resourcetype.ResourceType, er
 			return nil, fmt.Errorf("unmarshal resource type permissions: %w", err)
 		}
 	}
+	var attributes []resourcetype.AttributeDef
+	if m.Attributes != "" {
+		if err := json.Unmarshal([]byte(m.Attributes), &attributes); err != nil {
+			return nil, fmt.Errorf("unmarshal resource type attributes: %w", err)
+		}
+	}
 	var metadata map[string]any
 	if m.Metadata != "" {
 		if err := json.Unmarshal([]byte(m.Metadata), &metadata); err != nil {
@@ -554,6 +603,7 @@ func resourceTypeFromModel(m *resourceTypeModel) (*resourcetype.ResourceType, er
 		Description:   m.Description,
 		Relations:     relations,
 		Permissions:   permissions,
+		Attributes:    attributes,
 		Metadata:      metadata,
 		CreatedAt:     time.Time(m.CreatedAt),
 		UpdatedAt:     time.Time(m.UpdatedAt),
@@ -579,7 +629,10 @@ type checkLogModel struct {
 	Reason          string     `grove:"reason"`
 	EvalTimeNs      int64      `grove:"eval_time_ns,notnull"`
 	RequestIP       string     `grove:"request_ip"`
-	Metadata        string     `grove:"metadata"` // JSON text
+	RequestID       string     `grove:"request_id"`
+	MatchedBy       string     `grove:"matched_by"`  // JSON text
+	Obligations     string     `grove:"obligations"` // JSON text
+	Metadata        string     `grove:"metadata"`    // JSON text
 	CreatedAt       sqliteTime `grove:"created_at,notnull"`
 }
 
@@ -588,6 +641,22 @@ func checkLogToModel(e *checklog.Entry) (*checkLogModel, error) {
 	if err != nil {
 		return nil, fmt.Errorf("marshal check log metadata: %w", err)
 	}
+	matched := e.MatchedBy
+	if matched == nil {
+		matched = []checklog.Match{}
+	}
+	matchedJSON, err := json.Marshal(matched)
+	if err != nil {
+		return nil, fmt.Errorf("marshal check log matched_by: %w", err)
+	}
+	obligations := e.Obligations
+	if obligations == nil {
+		obligations = []string{}
+	}
+	obligationsJSON, err := json.Marshal(obligations)
+	if err != nil {
+		return nil, fmt.Errorf("marshal check log obligations: %w", err)
+	}
 	return &checkLogModel{
 		ID:            e.ID.String(),
 		TenantID:      e.TenantID,
@@ -602,6 +671,9 @@ func checkLogToModel(e *checklog.Entry) (*checkLogModel, error) {
 		Reason:        e.Reason,
 		EvalTimeNs:    e.EvalTimeNs,
 		RequestIP:     e.RequestIP,
+		RequestID:     e.RequestID,
+		MatchedBy:     string(matchedJSON),
+		Obligations:   string(obligationsJSON),
 		Metadata:      string(metadata),
 		CreatedAt:     sqliteTime(e.CreatedAt),
 	}, nil
@@ -615,6 +687,18 @@ func checkLogFromModel(m *checkLogModel) (*checklog.Entry, error) {
 			return nil, fmt.Errorf("unmarshal check log metadata: %w", err)
 		}
 	}
+	var matched []checklog.Match
+	if m.MatchedBy != "" {
+		if err := json.Unmarshal([]byte(m.MatchedBy), &matched); err != nil {
+			return nil, fmt.Errorf("unmarshal check log matched_by: %w", err)
+		}
+	}
+	var obligations []string
+	if m.Obligations != "" {
+		if err := json.Unmarshal([]byte(m.Obligations), &obligations); err != nil {
+			return nil, fmt.Errorf("unmarshal check log obligations: %w", err)
+		}
+	}
 	return &checklog.Entry{
 		ID:            clid,
 		TenantID:      m.TenantID,
@@ -629,7 +713,409 @@ func checkLogFromModel(m *checkLogModel) (*checklog.Entry, error) {
 		Reason:        m.Reason,
 		EvalTimeNs:    m.EvalTimeNs,
 		RequestIP:     m.RequestIP,
+		RequestID:     m.RequestID,
+		MatchedBy:     matched,
+		Obligations:   obligations,
 		Metadata:      metadata,
 		CreatedAt:     time.Time(m.CreatedAt),
 	}, nil
 }
+
+// ──────────────────────────────────────────────────
+// Webhook delivery model
+// ──────────────────────────────────────────────────
+
+type webhookDeliveryModel struct {
+	grove.BaseModel `grove:"table:warden_webhook_deliveries"`
+	ID              string      `grove:"id,pk"`
+	Endpoint        string      `grove:"endpoint,notnull"`
+	Event           string      `grove:"event,notnull"`
+	Payload         string      `grove:"payload,notnull"` // JSON text
+	Status          string      `grove:"status,notnull"`
+	Attempts        int         `grove:"attempts,notnull"`
+	NextAttemptAt   sqliteTime  `grove:"next_attempt_at,notnull"`
+	LastError       string      `grove:"last_error"`
+	LockedBy        string      `grove:"locked_by"`
+	LockedUntil     *sqliteTime `grove:"locked_until"`
+	CreatedAt       sqliteTime  `grove:"created_at,notnull"`
+	UpdatedAt       sqliteTime  `grove:"updated_at,notnull"`
+}
+
+func webhookDeliveryToModel(d *webhook.Delivery) *webhookDeliveryModel {
+	m := &webhookDeliveryModel{
+		ID:            d.ID.String(),
+		Endpoint:      d.Endpoint,
+		Event:         d.Event,
+		Payload:       string(d.Payload),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: sqliteTime(d.NextAttemptAt),
+		LastError:     d.LastError,
+		LockedBy:      d.LockedBy,
+		CreatedAt:     sqliteTime(d.CreatedAt),
+		UpdatedAt:     sqliteTime(d.UpdatedAt),
+	}
+	if d.LockedUntil != nil {
+		v := sqliteTime(*d.LockedUntil)
+		m.LockedUntil = &v
+	}
+	return m
+}
+
+func webhookDeliveryFromModel(m *webhookDeliveryModel) *webhook.Delivery {
+	did, _ := id.ParseWebhookDeliveryID(m.ID) //nolint:errcheck // stored IDs are always valid
+	out := &webhook.Delivery{
+		ID:            did,
+		Endpoint:      m.Endpoint,
+		Event:         m.Event,
+		Payload:       json.RawMessage(m.Payload),
+		Status:        webhook.Status(m.Status),
+		Attempts:      m.Attempts,
+		NextAttemptAt: time.Time(m.NextAttemptAt),
+		LastError:     m.LastError,
+		LockedBy:      m.LockedBy,
+		CreatedAt:     time.Time(m.CreatedAt),
+		UpdatedAt:     time.Time(m.UpdatedAt),
+	}
+	if m.LockedUntil != nil {
+		v := time.Time(*m.LockedUntil)
+		out.LockedUntil = &v
+	}
+	return out
+}
+
+// ──────────────────────────────────────────────────
+// Audit entry model
+// ──────────────────────────────────────────────────
+
+type auditEntryModel struct {
+	grove.BaseModel `grove:"table:warden_audit_entries"`
+	ID              string     `grove:"id,pk"`
+	TenantID        string     `grove:"tenant_id,notnull"`
+	NamespacePath   string     `grove:"namespace_path,notnull"`
+	AppID           string     `grove:"app_id,notnull"`
+	Seq             int64      `grove:"seq,notnull"`
+	ActorKind       string     `grove:"actor_kind"`
+	ActorID         string     `grove:"actor_id"`
+	Source          string     `grove:"source,notnull"`
+	Operation       string     `grove:"operation,notnull"`
+	EntityType      string     `grove:"entity_type,notnull"`
+	EntityID        string     `grove:"entity_id"`
+	EntityName      string     `grove:"entity_name"`
+	Before          string     `grove:"before_state"` // JSON text, hashed verbatim
+	After           string     `grove:"after_state"`  // JSON text, hashed verbatim
+	RequestIP       string     `grove:"request_ip"`
+	RequestID       string     `grove:"request_id"`
+	PrevHash        string     `grove:"prev_hash"`
+	Hash            string     `grove:"hash,notnull"`
+	CreatedAt       sqliteTime `grove:"created_at,notnull"`
+}
+
+func auditEntryToModel(e *audit.Entry) *auditEntryModel {
+	return &auditEntryModel{
+		ID:            e.ID.String(),
+		TenantID:      e.TenantID,
+		NamespacePath: e.NamespacePath,
+		AppID:         e.AppID,
+		Seq:           e.Seq,
+		ActorKind:     e.Actor.Kind,
+		ActorID:       e.Actor.ID,
+		Source:        string(e.Source),
+		Operation:     string(e.Operation),
+		EntityType:    string(e.EntityType),
+		EntityID:      e.EntityID,
+		EntityName:    e.EntityName,
+		Before:        string(e.Before),
+		After:         string(e.After),
+		RequestIP:     e.RequestIP,
+		RequestID:     e.RequestID,
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+		CreatedAt:     sqliteTime(e.CreatedAt),
+	}
+}
+
+func auditEntryFromModel(m *auditEntryModel) *audit.Entry {
+	aid, _ := id.ParseAuditID(m.ID) //nolint:errcheck // stored IDs are always valid
+	e := &audit.Entry{
+		ID:            aid,
+		TenantID:      m.TenantID,
+		NamespacePath: m.NamespacePath,
+		AppID:         m.AppID,
+		Seq:           m.Seq,
+		Actor:         audit.Actor{Kind: m.ActorKind, ID: m.ActorID},
+		Source:        audit.Source(m.Source),
+		Operation:     audit.Operation(m.Operation),
+		EntityType:    audit.EntityType(m.EntityType),
+		EntityID:      m.EntityID,
+		EntityName:    m.EntityName,
+		RequestIP:     m.RequestIP,
+		RequestID:     m.RequestID,
+		PrevHash:      m.PrevHash,
+		Hash:          m.Hash,
+		CreatedAt:     time.Time(m.CreatedAt),
+	}
+	if m.Before != "" {
+		e.Before = json.RawMessage(m.Before)
+	}
+	if m.After != "" {
+		e.After = json.RawMessage(m.After)
+	}
+	return e
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass grant model
+// ──────────────────────────────────────────────────
+
+type breakGlassGrantModel struct {
+	grove.BaseModel `grove:"table:warden_break_glass_grants"`
+	ID              string      `grove:"id,pk"`
+	TenantID        string      `grove:"tenant_id,notnull"`
+	NamespacePath   string      `grove:"namespace_path,notnull"`
+	AppID           string      `grove:"app_id,notnull"`
+	SubjectKind     string      `grove:"subject_kind,notnull"`
+	SubjectID       string      `grove:"subject_id,notnull"`
+	Justification   string      `grove:"justification,notnull"`
+	RoleIDs         string      `grove:"role_ids"`        // JSON text
+	BypassPolicies  string      `grove:"bypass_policies"` // JSON text
+	ExpiresAt       sqliteTime  `grove:"expires_at,notnull"`
+	RevokedAt       *sqliteTime `grove:"revoked_at"`
+	RevokedBy       string      `grove:"revoked_by"`
+	CreatedAt       sqliteTime  `grove:"created_at,notnull"`
+}
+
+func breakGlassGrantToModel(g *breakglass.Grant) (*breakGlassGrantModel, error) {
+	roleIDs, err := json.Marshal(g.RoleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("marshal break-glass role IDs: %w", err)
+	}
+	bypass, err := json.Marshal(g.BypassPolicies)
+	if err != nil {
+		return nil, fmt.Errorf("marshal break-glass bypass policies: %w", err)
+	}
+	m := &breakGlassGrantModel{
+		ID:             g.ID.String(),
+		TenantID:       g.TenantID,
+		NamespacePath:  g.NamespacePath,
+		AppID:          g.AppID,
+		SubjectKind:    g.SubjectKind,
+		SubjectID:      g.SubjectID,
+		Justification:  g.Justification,
+		RoleIDs:        string(roleIDs),
+		BypassPolicies: string(bypass),
+		ExpiresAt:      sqliteTime(g.ExpiresAt),
+		RevokedBy:      g.RevokedBy,
+		CreatedAt:      sqliteTime(g.CreatedAt),
+	}
+	if g.RevokedAt != nil {
+		v := sqliteTime(*g.RevokedAt)
+		m.RevokedAt = &v
+	}
+	return m, nil
+}
+
+func breakGlassGrantFromModel(m *breakGlassGrantModel) (*breakglass.Grant, error) {
+	gid, _ := id.ParseBreakGlassID(m.ID) //nolint:errcheck // stored IDs are always valid
+	var roleIDs []id.RoleID
+	if m.RoleIDs != "" {
+		if err := json.Unmarshal([]byte(m.RoleIDs), &roleIDs); err != nil {
+			return nil, fmt.Errorf("unmarshal break-glass role IDs: %w", err)
+		}
+	}
+	var bypass []string
+	if m.BypassPolicies != "" {
+		if err := json.Unmarshal([]byte(m.BypassPolicies), &bypass); err != nil {
+			return nil, fmt.Errorf("unmarshal break-glass bypass policies: %w", err)
+		}
+	}
+	out := &breakglass.Grant{
+		ID:             gid,
+		TenantID:       m.TenantID,
+		NamespacePath:  m.NamespacePath,
+		AppID:          m.AppID,
+		SubjectKind:    m.SubjectKind,
+		SubjectID:      m.SubjectID,
+		Justification:  m.Justification,
+		RoleIDs:        roleIDs,
+		BypassPolicies: bypass,
+		ExpiresAt:      time.Time(m.ExpiresAt),
+		RevokedBy:      m.RevokedBy,
+		CreatedAt:      time.Time(m.CreatedAt),
+	}
+	if m.RevokedAt != nil {
+		v := time.Time(*m.RevokedAt)
+		out.RevokedAt = &v
+	}
+	return out, nil
+}
+
+// ──────────────────────────────────────────────────
+// Calendar model
+// ──────────────────────────────────────────────────
+
+type calendarModel struct {
+	grove.BaseModel `grove:"table:warden_calendars"`
+	ID              string     `grove:"id,pk"`
+	TenantID        string     `grove:"tenant_id,notnull"`
+	AppID           string     `grove:"app_id,notnull"`
+	Name            string     `grove:"name,notnull"`
+	Description     string     `grove:"description"`
+	TimeZone        string     `grove:"time_zone,notnull"`
+	Dates           string     `grove:"dates"`    // JSON text
+	Metadata        string     `grove:"metadata"` // JSON text
+	CreatedAt       sqliteTime `grove:"created_at,notnull"`
+	UpdatedAt       sqliteTime `grove:"updated_at,notnull"`
+}
+
+func calendarToModel(c *calendar.Calendar) (*calendarModel, error) {
+	dates, err := json.Marshal(c.Dates)
+	if err != nil {
+		return nil, fmt.Errorf("marshal calendar dates: %w", err)
+	}
+	metadata, err := json.Marshal(c.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("marshal calendar metadata: %w", err)
+	}
+	return &calendarModel{
+		ID:          c.ID.String(),
+		TenantID:    c.TenantID,
+		AppID:       c.AppID,
+		Name:        c.Name,
+		Description: c.Description,
+		TimeZone:    c.TimeZone,
+		Dates:       string(dates),
+		Metadata:    string(metadata),
+		CreatedAt:   sqliteTime(c.CreatedAt),
+		UpdatedAt:   sqliteTime(c.UpdatedAt),
+	}, nil
+}
+
+func calendarFromModel(m *calendarModel) (*calendar.Calendar, error) {
+	cid, _ := id.ParseCalendarID(m.ID) //nolint:errcheck // stored IDs are always valid
+	var dates []calendar.Date
+	if m.Dates != "" {
+		if err := json.Unmarshal([]byte(m.Dates), &dates); err != nil {
+			return nil, fmt.Errorf("unmarshal calendar dates: %w", err)
+		}
+	}
+	var metadata map[string]any
+	if m.Metadata != "" {
+		if err := json.Unmarshal([]byte(m.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("unmarshal calendar metadata: %w", err)
+		}
+	}
+	return &calendar.Calendar{
+		ID:          cid,
+		TenantID:    m.TenantID,
+		AppID:       m.AppID,
+		Name:        m.Name,
+		Description: m.Description,
+		TimeZone:    m.TimeZone,
+		Dates:       dates,
+		Metadata:    metadata,
+		CreatedAt:   time.Time(m.CreatedAt),
+		UpdatedAt:   time.Time(m.UpdatedAt),
+	}, nil
+}
+
+// ──────────────────────────────────────────────────
+// SoD constraint model
+// ──────────────────────────────────────────────────
+
+type sodConstraintModel struct {
+	grove.BaseModel `grove:"table:warden_sod_constraints"`
+	ID              string     `grove:"id,pk"`
+	TenantID        string     `grove:"tenant_id,notnull"`
+	AppID           string     `grove:"app_id,notnull"`
+	Name            string     `grove:"name,notnull"`
+	Description     string     `grove:"description"`
+	Kind            string     `grove:"kind,notnull"`
+	Roles           string     `grove:"roles"` // JSON text
+	MaxRoles        int        `grove:"max_roles,notnull"`
+	Metadata        string     `grove:"metadata"` // JSON text
+	CreatedAt       sqliteTime `grove:"created_at,notnull"`
+	UpdatedAt       sqliteTime `grove:"updated_at,notnull"`
+}
+
+func sodConstraintToModel(c *sod.Constraint) (*sodConstraintModel, error) {
+	roles, err := json.Marshal(c.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sod constraint roles: %w", err)
+	}
+	metadata, err := json.Marshal(c.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sod constraint metadata: %w", err)
+	}
+	return &sodConstraintModel{
+		ID:          c.ID.String(),
+		TenantID:    c.TenantID,
+		AppID:       c.AppID,
+		Name:        c.Name,
+		Description: c.Description,
+		Kind:        string(c.Kind),
+		Roles:       string(roles),
+		MaxRoles:    c.MaxRoles,
+		Metadata:    string(metadata),
+		CreatedAt:   sqliteTime(c.CreatedAt),
+		UpdatedAt:   sqliteTime(c.UpdatedAt),
+	}, nil
+}
+
+func sodConstraintFromModel(m *sodConstraintModel) (*sod.Constraint, error) {
+	sid, _ := id.ParseSoDConstraintID(m.ID) //nolint:errcheck // stored IDs are always valid
+	var roles []string
+	if m.Roles != "" {
+		if err := json.Unmarshal([]byte(m.Roles), &roles); err != nil {
+			return nil, fmt.Errorf("unmarshal sod constraint roles: %w", err)
+		}
+	}
+	var metadata map[string]any
+	if m.Metadata != "" {
+		if err := json.Unmarshal([]byte(m.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("unmarshal sod constraint metadata: %w", err)
+		}
+	}
+	return &sod.Constraint{
+		ID:          sid,
+		TenantID:    m.TenantID,
+		AppID:       m.AppID,
+		Name:        m.Name,
+		Description: m.Description,
+		Kind:        sod.Kind(m.Kind),
+		Roles:       roles,
+		MaxRoles:    m.MaxRoles,
+		Metadata:    metadata,
+		CreatedAt:   time.Time(m.CreatedAt),
+		UpdatedAt:   time.Time(m.UpdatedAt),
+	}, nil
+}
+
+// ──────────────────────────────────────────────────
+// Policy version model
+// ──────────────────────────────────────────────────
+
+type policyVersionModel struct {
+	grove.BaseModel `grove:"table:warden_policy_versions"`
+	TenantID        string `grove:"tenant_id,pk"`
+	Version         int64  `grove:"version,notnull"`
+	ChangedAt       int64  `grove:"changed_at,notnull"` // Unix milliseconds
+}
+
+// ──────────────────────────────────────────────────
+// Rate counter model
+// ──────────────────────────────────────────────────
+
+type counterModel struct {
+	grove.BaseModel `grove:"table:warden_rate_counters"`
+	TenantID        string `grove:"tenant_id,pk"`
+	SubjectKind     string `grove:"subject_kind,pk"`
+	SubjectID       string `grove:"subject_id,pk"`
+	Action          string `grove:"action,pk"`
+	Bucket          int64  `grove:"bucket,pk"` // Unix seconds
+	Count           int64  `grove:"count,notnull"`
+}
+
+type counterSumRow struct {
+	Total int64 `grove:"total"`
+}
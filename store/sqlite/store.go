@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,35 +14,51 @@ import (
 	"github.com/xraph/grove/migrate"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/counter"
 	"github.com/xraph/warden/id"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
 	"github.com/xraph/warden/store"
 	"github.com/xraph/warden/wardenerr"
+	"github.com/xraph/warden/webhook"
 )
 
 // Compile-time interface check.
-var _ store.Store = (*Store)(nil)
+var (
+	_ store.Store           = (*Store)(nil)
+	_ checklog.StatsStore   = (*Store)(nil)
+	_ policy.ChangeNotifier = (*Store)(nil)
+)
 
 // errNotFound is the sentinel for missing entities.
-var errNotFound = fmt.Errorf("not found")
+var errNotFound = wardenerr.ErrNotFound
 
 // Store is a SQLite implementation of the composite Warden store.
 type Store struct {
 	db  *grove.DB
 	sdb *sqlitedriver.SqliteDB
+
+	// policyChanges reports policy writes, including those made by other
+	// processes sharing the database, to the engine's policy index.
+	policyChanges *policy.ChangeFeed
 }
 
 // New creates a new SQLite store.
 func New(db *grove.DB) *Store {
-	return &Store{
+	s := &Store{
 		db:  db,
 		sdb: sqlitedriver.Unwrap(db),
 	}
+	s.policyChanges = policy.NewChangeFeed(s.pollPolicyChanges, policy.DefaultChangePollInterval)
+	return s
 }
 
 // inPlaceholders builds an "IN (?, ?, …)" body and the matching []any args for
@@ -76,8 +93,10 @@ func (s *Store) Ping(ctx context.Context) error {
 	return s.db.Ping(ctx)
 }
 
-// Close closes the database connection.
+// Close stops polling for policy changes and closes the database
+// connection.
 func (s *Store) Close() error {
+	s.policyChanges.Close()
 	return s.db.Close()
 }
 
@@ -587,7 +606,18 @@ func (s *Store) CreateAssignment(ctx context.Context, a *assignment.Assignment)
 	if err != nil {
 		return fmt.Errorf("warden: create assignment: %w", err)
 	}
-	if _, err := s.sdb.NewInsert(m).Exec(ctx); err != nil {
+	tx, err := s.sdb.BeginTxQuery(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("warden: begin tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback on error is intentional
+
+	// The insert comes first so the transaction takes the database's write
+	// lock before the check, as BEGIN IMMEDIATE would: a concurrent
+	// assignment waits on its own insert until this one commits or rolls
+	// back, and its check then sees the outcome. The check reads committed
+	// rows, which do not include this insert.
+	if _, err := tx.NewInsert(m).Exec(ctx); err != nil {
 		if isUniqueViolation(err) {
 			return fmt.Errorf("assignment role=%s subject=%s:%s in tenant %q ns %q: %w",
 				a.RoleID, a.SubjectKind, a.SubjectID, a.TenantID, a.NamespacePath,
@@ -595,6 +625,12 @@ func (s *Store) CreateAssignment(ctx context.Context, a *assignment.Assignment)
 		}
 		return fmt.Errorf("warden: create assignment: %w", err)
 	}
+	if err := sod.CheckAssignment(ctx, s, a, time.Now()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("warden: commit tx: %w", err)
+	}
 	return nil
 }
 
@@ -1066,7 +1102,7 @@ func (s *Store) CreatePolicy(ctx context.Context, p *policy.Policy) error {
 		}
 		return fmt.Errorf("warden: create policy: %w", err)
 	}
-	return nil
+	return s.policyChanged(ctx, p.TenantID)
 }
 
 func (s *Store) GetPolicy(ctx context.Context, polID id.PolicyID) (*policy.Policy, error) {
@@ -1114,16 +1150,20 @@ func (s *Store) UpdatePolicy(ctx context.Context, p *policy.Policy) error {
 	if _, err := s.sdb.NewUpdate(m).WherePK().Exec(ctx); err != nil {
 		return fmt.Errorf("warden: update policy: %w", err)
 	}
-	return nil
+	return s.policyChanged(ctx, p.TenantID)
 }
 
 func (s *Store) DeletePolicy(ctx context.Context, polID id.PolicyID) error {
+	before, _ := s.GetPolicy(ctx, polID) //nolint:errcheck // missing → no change to report
 	_, err := s.sdb.NewDelete((*policyModel)(nil)).
 		Where("id = ?", polID.String()).Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("warden: delete policy: %w", err)
 	}
-	return nil
+	if before == nil {
+		return nil
+	}
+	return s.policyChanged(ctx, before.TenantID)
 }
 
 func (s *Store) ListPolicies(ctx context.Context, filter *policy.ListFilter) ([]*policy.Policy, error) {
@@ -1228,9 +1268,45 @@ func (s *Store) DeletePoliciesByTenant(ctx context.Context, tenantID string) err
 	if err != nil {
 		return fmt.Errorf("warden: delete policies by tenant: %w", err)
 	}
+	return s.policyChanged(ctx, tenantID)
+}
+
+// NotifyPolicyChanges implements policy.ChangeNotifier. Every policy and
+// separation-of-duties constraint write bumps its tenant's row in
+// warden_policy_versions, which each store sharing the database file polls, so
+// engines in other processes drop their compiled policies within
+// policy.DefaultChangePollInterval.
+func (s *Store) NotifyPolicyChanges(fn func(tenantID string)) (stop func()) {
+	return s.policyChanges.NotifyPolicyChanges(fn)
+}
+
+// policyChanged records a policy or constraint write of tenantID and
+// reports it to this store's watchers.
+func (s *Store) policyChanged(ctx context.Context, tenantID string) error {
+	m := &policyVersionModel{TenantID: tenantID, Version: 1, ChangedAt: time.Now().UnixMilli()}
+	_, err := s.sdb.NewInsert(m).
+		OnConflict("(tenant_id) DO UPDATE SET version = version + 1, changed_at = excluded.changed_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: record policy change: %w", err)
+	}
+	s.policyChanges.Notify(tenantID)
 	return nil
 }
 
+func (s *Store) pollPolicyChanges(ctx context.Context, since time.Time) ([]policy.Change, error) {
+	var models []policyVersionModel
+	err := s.sdb.NewSelect(&models).Where("changed_at >= ?", since.UnixMilli()).Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("warden: poll policy changes: %w", err)
+	}
+	changes := make([]policy.Change, len(models))
+	for i, m := range models {
+		changes[i] = policy.Change{TenantID: m.TenantID, Version: m.Version, ChangedAt: time.UnixMilli(m.ChangedAt).UTC()}
+	}
+	return changes, nil
+}
+
 // ──────────────────────────────────────────────────
 // Resource type operations (ReBAC schema)
 // ──────────────────────────────────────────────────
@@ -1413,7 +1489,521 @@ func (s *Store) GetCheckLog(ctx context.Context, logID id.CheckLogID) (*checklog
 
 func (s *Store) ListCheckLogs(ctx context.Context, filter *checklog.QueryFilter) ([]*checklog.Entry, error) {
 	var models []checkLogModel
-	q := s.sdb.NewSelect(&models).OrderExpr("created_at DESC")
+	q := s.sdb.NewSelect(&models).OrderExpr("created_at DESC, id DESC")
+	if filter != nil {
+		for _, c := range filter.SQLConditions(checkLogDialect) {
+			q = q.Where(c.Query, c.Args...)
+		}
+		if filter.Limit > 0 {
+			q = q.Limit(filter.Limit)
+		}
+		if filter.Offset > 0 {
+			q = q.Offset(filter.Offset)
+		}
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list check logs: %w", err)
+	}
+	result := make([]*checklog.Entry, len(models))
+	for i := range models {
+		e, err := checkLogFromModel(&models[i])
+		if err != nil {
+			return nil, fmt.Errorf("warden: list check logs: %w", err)
+		}
+		result[i] = e
+	}
+	return result, nil
+}
+
+func (s *Store) CountCheckLogs(ctx context.Context, filter *checklog.QueryFilter) (int64, error) {
+	q := s.sdb.NewSelect((*checkLogModel)(nil))
+	if filter != nil {
+		for _, c := range filter.SQLConditions(checkLogDialect) {
+			q = q.Where(c.Query, c.Args...)
+		}
+	}
+	count, err := q.Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: count check logs: %w", err)
+	}
+	return count, nil
+}
+
+// checkLogDialect renders the check-log JSON filters with json_each, and
+// time arguments in the RFC3339 text created_at is stored as.
+var checkLogDialect = checklog.SQLDialect{
+	MatchedBy: matchedByExists,
+	Obligation: func(name string) checklog.SQLCondition {
+		return checklog.SQLCondition{Query: "EXISTS (SELECT 1 FROM json_each(obligations) WHERE value = ?)", Args: []any{name}}
+	},
+	Time: func(t time.Time) any { return sqliteTime(t) },
+}
+
+// matchedByExists builds an EXISTS clause selecting entries with a
+// matched_by element carrying the given source and/or rule ID.
+func matchedByExists(source, ruleID string) checklog.SQLCondition {
+	var conds []string
+	var args []any
+	if source != "" {
+		conds = append(conds, "json_extract(value, '$.source') = ?")
+		args = append(args, source)
+	}
+	if ruleID != "" {
+		conds = append(conds, "json_extract(value, '$.rule_id') = ?")
+		args = append(args, ruleID)
+	}
+	return checklog.SQLCondition{Query: "EXISTS (SELECT 1 FROM json_each(matched_by) WHERE " + strings.Join(conds, " AND ") + ")", Args: args}
+}
+
+// checkLogStatsRow is one grouped count from a stats query. Bucket is the
+// epoch second the bucket starts at (0 when not bucketed).
+type checkLogStatsRow struct {
+	Bucket int64  `grove:"bucket"`
+	Key    string `grove:"key"`
+	Count  int64  `grove:"count"`
+}
+
+type checkLogLatencyRow struct {
+	EvalTimeNs int64 `grove:"eval_time_ns"`
+}
+
+// checkLogStatsKey maps a stats dimension to its SQL key expression.
+func checkLogStatsKey(d checklog.Dimension) string {
+	switch d {
+	case checklog.DimensionResourceType:
+		return "resource_type"
+	case checklog.DimensionAction:
+		return "action"
+	case checklog.DimensionSubject:
+		return "subject_kind || ':' || subject_id"
+	default:
+		return "decision"
+	}
+}
+
+// checkLogStatsWhere renders filter as a raw WHERE clause for the
+// aggregate queries, which bypass the select builder.
+func checkLogStatsWhere(filter *checklog.QueryFilter) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	for _, c := range filter.SQLConditions(checkLogDialect) {
+		conds = append(conds, c.Query)
+		args = append(args, c.Args...)
+	}
+	if len(conds) == 0 {
+		return "1 = 1", args
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// CheckLogStats aggregates check logs with GROUP BY. SQLite has no
+// percentile function, so latency percentiles are read by rank offset.
+func (s *Store) CheckLogStats(ctx context.Context, q *checklog.StatsQuery) (*checklog.Stats, error) {
+	where, args := checkLogStatsWhere(&q.Filter)
+
+	bucketExpr, groupBy := "0", "2"
+	var seriesArgs []any
+	if width := int64(q.Bucket / time.Second); width > 0 {
+		bucketExpr = "(CAST(strftime('%s', created_at) AS INTEGER) / ?) * ?"
+		groupBy = "1, 2"
+		seriesArgs = append(seriesArgs, width, width)
+	}
+	seriesArgs = append(seriesArgs, args...)
+	var rows []checkLogStatsRow
+	seriesSQL := fmt.Sprintf(
+		"SELECT %s AS bucket, %s AS key, COUNT(*) AS count FROM warden_check_logs WHERE %s GROUP BY %s ORDER BY 1, 2",
+		bucketExpr, checkLogStatsKey(q.GroupBy), where, groupBy)
+	if err := s.sdb.NewRaw(seriesSQL, seriesArgs...).Scan(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("warden: check log stats: %w", err)
+	}
+	st := &checklog.Stats{Series: make([]checklog.StatsPoint, 0, len(rows))}
+	for _, r := range rows {
+		p := checklog.StatsPoint{Key: r.Key, Count: r.Count}
+		if q.Bucket > 0 {
+			p.Bucket = time.Unix(r.Bucket, 0).UTC()
+		}
+		st.Series = append(st.Series, p)
+		st.Total += r.Count
+	}
+	checklog.SortSeries(st.Series)
+
+	if st.Total > 0 {
+		rank := func(p float64) (int64, error) {
+			var lat []checkLogLatencyRow
+			latSQL := fmt.Sprintf(
+				"SELECT eval_time_ns FROM warden_check_logs WHERE %s ORDER BY eval_time_ns LIMIT 1 OFFSET %d",
+				where, checklog.RankIndex(st.Total, p))
+			if err := s.sdb.NewRaw(latSQL, args...).Scan(ctx, &lat); err != nil {
+				return 0, fmt.Errorf("warden: check log latency stats: %w", err)
+			}
+			if len(lat) == 0 {
+				return 0, nil
+			}
+			return lat[0].EvalTimeNs, nil
+		}
+		var err error
+		if st.Latency.P50, err = rank(0.50); err != nil {
+			return nil, err
+		}
+		if st.Latency.P95, err = rank(0.95); err != nil {
+			return nil, err
+		}
+		if st.Latency.P99, err = rank(0.99); err != nil {
+			return nil, err
+		}
+	}
+
+	top := func(keyExpr string) ([]checklog.KeyCount, error) {
+		var rows []checkLogStatsRow
+		topSQL := fmt.Sprintf(
+			"SELECT 0 AS bucket, %s AS key, COUNT(*) AS count FROM warden_check_logs WHERE %s AND decision <> 'allow' GROUP BY 2 ORDER BY 3 DESC, 2 LIMIT %d",
+			keyExpr, where, q.TopN)
+		if err := s.sdb.NewRaw(topSQL, args...).Scan(ctx, &rows); err != nil {
+			return nil, fmt.Errorf("warden: check log top denied: %w", err)
+		}
+		out := make([]checklog.KeyCount, len(rows))
+		for i, r := range rows {
+			out[i] = checklog.KeyCount{Key: r.Key, Count: r.Count}
+		}
+		return out, nil
+	}
+	var err error
+	if st.TopDeniedSubjects, err = top("subject_kind || ':' || subject_id"); err != nil {
+		return nil, err
+	}
+	if st.TopDeniedResources, err = top("resource_type || ':' || resource_id"); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *Store) PurgeCheckLogs(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.sdb.NewDelete((*checkLogModel)(nil)).
+		Where("created_at < ?", before).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge check logs: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge check logs rows: %w", err)
+	}
+	return n, nil
+}
+
+func (s *Store) DeleteCheckLogs(ctx context.Context, ids []id.CheckLogID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	strs := make([]string, len(ids))
+	for i, clid := range ids {
+		strs[i] = clid.String()
+	}
+	ph, args := inPlaceholders(strs)
+	res, err := s.sdb.NewDelete((*checkLogModel)(nil)).
+		Where("id IN ("+ph+")", args...).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: delete check logs: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("warden: delete check logs rows: %w", err)
+	}
+	return n, nil
+}
+
+func (s *Store) DeleteCheckLogsByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.sdb.NewDelete((*checkLogModel)(nil)).
+		Where("tenant_id = ?", tenantID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: delete check logs by tenant: %w", err)
+	}
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// Webhook outbox operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, d *webhook.Delivery) error {
+	if d.ID.IsNil() {
+		d.ID = id.NewWebhookDeliveryID()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	if d.UpdatedAt.IsZero() {
+		d.UpdatedAt = d.CreatedAt
+	}
+	if _, err := s.sdb.NewInsert(webhookDeliveryToModel(d)).Exec(ctx); err != nil {
+		return fmt.Errorf("warden: create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// claimWebhookDeliveriesSQL leases the oldest due deliveries in one
+// statement. SQLite serialises writers, so two dispatchers never claim the
+// same row; LIMIT -1 means no limit.
+const claimWebhookDeliveriesSQL = `
+UPDATE warden_webhook_deliveries SET locked_by = ?, locked_until = ?
+WHERE id IN (
+    SELECT id FROM warden_webhook_deliveries
+    WHERE status = ?
+      AND next_attempt_at <= ?
+      AND (locked_until IS NULL OR locked_until <= ?)
+    ORDER BY created_at ASC, id ASC
+    LIMIT ?
+)
+RETURNING id, endpoint, event, payload, status, attempts, next_attempt_at,
+          last_error, locked_by, locked_until, created_at, updated_at
+`
+
+func (s *Store) ClaimDueWebhookDeliveries(ctx context.Context, owner string, now, lockUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	var models []webhookDeliveryModel
+	err := s.sdb.NewRaw(claimWebhookDeliveriesSQL,
+		owner, sqliteTime(lockUntil), string(webhook.StatusPending), sqliteTime(now), sqliteTime(now), limit).
+		Scan(ctx, &models)
+	if err != nil {
+		return nil, fmt.Errorf("warden: claim due webhook deliveries: %w", err)
+	}
+	result := make([]*webhook.Delivery, len(models))
+	for i := range models {
+		result[i] = webhookDeliveryFromModel(&models[i])
+	}
+	// RETURNING does not keep the subquery's order.
+	sortWebhookDeliveries(result)
+	return result, nil
+}
+
+// sortWebhookDeliveries orders deliveries oldest first, as they were
+// claimed.
+func sortWebhookDeliveries(ds []*webhook.Delivery) {
+	slices.SortFunc(ds, func(a, b *webhook.Delivery) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+}
+
+// UpdateWebhookDelivery writes the attempt's outcome only while owner's
+// lease on the row is unexpired, so a dispatcher whose lease ran out cannot
+// overwrite the outcome recorded by the one that claimed the row next.
+func (s *Store) UpdateWebhookDelivery(ctx context.Context, owner string, d *webhook.Delivery) error {
+	m := webhookDeliveryToModel(d)
+	res, err := s.sdb.NewUpdate((*webhookDeliveryModel)(nil)).
+		Set("status = ?", m.Status).
+		Set("attempts = ?", m.Attempts).
+		Set("next_attempt_at = ?", m.NextAttemptAt).
+		Set("last_error = ?", m.LastError).
+		Set("locked_by = ?", m.LockedBy).
+		Set("locked_until = ?", m.LockedUntil).
+		Set("updated_at = ?", m.UpdatedAt).
+		Where("id = ?", m.ID).
+		Where("locked_by = ?", owner).
+		Where("locked_until > ?", m.UpdatedAt).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: update webhook delivery: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("warden: update webhook delivery rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("webhook delivery %s: %w", d.ID, webhook.ErrLeaseLost)
+	}
+	return nil
+}
+
+func (s *Store) PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.sdb.NewDelete((*webhookDeliveryModel)(nil)).
+		Where("status <> ?", string(webhook.StatusPending)).
+		Where("updated_at < ?", before).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge webhook deliveries: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge webhook deliveries rows: %w", err)
+	}
+	return n, nil
+}
+
+// ──────────────────────────────────────────────────
+// Audit trail operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) AppendAuditEntry(ctx context.Context, e *audit.Entry) error {
+	if _, err := s.sdb.NewInsert(auditEntryToModel(e)).Exec(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("audit entry %d in tenant %q: %w", e.Seq, e.TenantID, audit.ErrSequenceConflict)
+		}
+		return fmt.Errorf("warden: append audit entry: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) LastAuditEntry(ctx context.Context, tenantID string) (*audit.Entry, error) {
+	m := new(auditEntryModel)
+	err := s.sdb.NewSelect(m).
+		Where("tenant_id = ?", tenantID).
+		OrderExpr("seq DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil //nolint:nilnil // empty chain
+		}
+		return nil, fmt.Errorf("warden: last audit entry: %w", err)
+	}
+	return auditEntryFromModel(m), nil
+}
+
+func (s *Store) ListAuditEntries(ctx context.Context, filter *audit.QueryFilter) ([]*audit.Entry, error) {
+	if filter == nil {
+		filter = &audit.QueryFilter{}
+	}
+	var models []auditEntryModel
+	order := "seq DESC"
+	if filter.Ascending {
+		order = "seq ASC"
+	}
+	q := s.sdb.NewSelect(&models).
+		Where("tenant_id = ?", filter.TenantID).
+		OrderExpr(order)
+	if filter.EntityType != "" {
+		q = q.Where("entity_type = ?", string(filter.EntityType))
+	}
+	if filter.EntityID != "" {
+		q = q.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Operation != "" {
+		q = q.Where("operation = ?", string(filter.Operation))
+	}
+	if filter.Source != "" {
+		q = q.Where("source = ?", string(filter.Source))
+	}
+	if filter.ActorID != "" {
+		q = q.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.AfterSeq > 0 {
+		q = q.Where("seq > ?", filter.AfterSeq)
+	}
+	if filter.After != nil {
+		q = q.Where("created_at >= ?", *filter.After)
+	}
+	if filter.Before != nil {
+		q = q.Where("created_at <= ?", *filter.Before)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list audit entries: %w", err)
+	}
+	result := make([]*audit.Entry, len(models))
+	for i := range models {
+		result[i] = auditEntryFromModel(&models[i])
+	}
+	return result, nil
+}
+
+func (s *Store) CountAuditEntries(ctx context.Context, filter *audit.QueryFilter) (int64, error) {
+	if filter == nil {
+		filter = &audit.QueryFilter{}
+	}
+	q := s.sdb.NewSelect((*auditEntryModel)(nil)).
+		Where("tenant_id = ?", filter.TenantID)
+	if filter.EntityType != "" {
+		q = q.Where("entity_type = ?", string(filter.EntityType))
+	}
+	if filter.EntityID != "" {
+		q = q.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Operation != "" {
+		q = q.Where("operation = ?", string(filter.Operation))
+	}
+	if filter.Source != "" {
+		q = q.Where("source = ?", string(filter.Source))
+	}
+	if filter.ActorID != "" {
+		q = q.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.AfterSeq > 0 {
+		q = q.Where("seq > ?", filter.AfterSeq)
+	}
+	if filter.After != nil {
+		q = q.Where("created_at >= ?", *filter.After)
+	}
+	if filter.Before != nil {
+		q = q.Where("created_at <= ?", *filter.Before)
+	}
+	count, err := q.Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: count audit entries: %w", err)
+	}
+	return count, nil
+}
+
+// ──────────────────────────────────────────────────
+// Break-glass operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateBreakGlassGrant(ctx context.Context, g *breakglass.Grant) error {
+	if g.ID.IsNil() {
+		g.ID = id.NewBreakGlassID()
+	}
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now().UTC()
+	}
+	m, err := breakGlassGrantToModel(g)
+	if err != nil {
+		return fmt.Errorf("warden: create break-glass grant: %w", err)
+	}
+	if _, err := s.sdb.NewInsert(m).Exec(ctx); err != nil {
+		return fmt.Errorf("warden: create break-glass grant: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetBreakGlassGrant(ctx context.Context, grantID id.BreakGlassID) (*breakglass.Grant, error) {
+	m := new(breakGlassGrantModel)
+	err := s.sdb.NewSelect(m).Where("id = ?", grantID.String()).Scan(ctx)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, fmt.Errorf("break-glass grant %s: %w", grantID, errNotFound)
+		}
+		return nil, fmt.Errorf("warden: get break-glass grant: %w", err)
+	}
+	g, err := breakGlassGrantFromModel(m)
+	if err != nil {
+		return nil, fmt.Errorf("warden: get break-glass grant: %w", err)
+	}
+	return g, nil
+}
+
+func (s *Store) UpdateBreakGlassGrant(ctx context.Context, g *breakglass.Grant) error {
+	m, err := breakGlassGrantToModel(g)
+	if err != nil {
+		return fmt.Errorf("warden: update break-glass grant: %w", err)
+	}
+	if _, err := s.sdb.NewUpdate(m).WherePK().Exec(ctx); err != nil {
+		return fmt.Errorf("warden: update break-glass grant: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListBreakGlassGrants(ctx context.Context, filter *breakglass.ListFilter) ([]*breakglass.Grant, error) {
+	var models []breakGlassGrantModel
+	q := s.sdb.NewSelect(&models).OrderExpr("created_at DESC, id DESC")
 	if filter != nil {
 		if filter.TenantID != "" {
 			q = q.Where("tenant_id = ?", filter.TenantID)
@@ -1424,23 +2014,126 @@ func (s *Store) ListCheckLogs(ctx context.Context, filter *checklog.QueryFilter)
 		if filter.SubjectID != "" {
 			q = q.Where("subject_id = ?", filter.SubjectID)
 		}
-		if filter.Action != "" {
-			q = q.Where("action = ?", filter.Action)
+		if filter.ActiveAt != nil {
+			q = q.Where("revoked_at IS NULL").Where("expires_at > ?", *filter.ActiveAt)
 		}
-		if filter.ResourceType != "" {
-			q = q.Where("resource_type = ?", filter.ResourceType)
+		if filter.Limit > 0 {
+			q = q.Limit(filter.Limit)
 		}
-		if filter.ResourceID != "" {
-			q = q.Where("resource_id = ?", filter.ResourceID)
+		if filter.Offset > 0 {
+			q = q.Offset(filter.Offset)
 		}
-		if filter.Decision != "" {
-			q = q.Where("decision = ?", filter.Decision)
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list break-glass grants: %w", err)
+	}
+	result := make([]*breakglass.Grant, len(models))
+	for i := range models {
+		g, err := breakGlassGrantFromModel(&models[i])
+		if err != nil {
+			return nil, fmt.Errorf("warden: list break-glass grants: %w", err)
 		}
-		if filter.After != nil {
-			q = q.Where("created_at >= ?", *filter.After)
+		result[i] = g
+	}
+	return result, nil
+}
+
+// ──────────────────────────────────────────────────
+// Calendar operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateCalendar(ctx context.Context, c *calendar.Calendar) error {
+	if c.ID.IsNil() {
+		c.ID = id.NewCalendarID()
+	}
+	now := time.Now().UTC()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = now
+	}
+	m, err := calendarToModel(c)
+	if err != nil {
+		return fmt.Errorf("warden: create calendar: %w", err)
+	}
+	if _, err := s.sdb.NewInsert(m).Exec(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("calendar %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateCalendar)
 		}
-		if filter.Before != nil {
-			q = q.Where("created_at <= ?", *filter.Before)
+		return fmt.Errorf("warden: create calendar: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetCalendar(ctx context.Context, calID id.CalendarID) (*calendar.Calendar, error) {
+	m := new(calendarModel)
+	err := s.sdb.NewSelect(m).Where("id = ?", calID.String()).Scan(ctx)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, fmt.Errorf("calendar %s: %w", calID, errNotFound)
+		}
+		return nil, fmt.Errorf("warden: get calendar: %w", err)
+	}
+	c, err := calendarFromModel(m)
+	if err != nil {
+		return nil, fmt.Errorf("warden: get calendar: %w", err)
+	}
+	return c, nil
+}
+
+func (s *Store) GetCalendarByName(ctx context.Context, tenantID, name string) (*calendar.Calendar, error) {
+	m := new(calendarModel)
+	err := s.sdb.NewSelect(m).
+		Where("tenant_id = ?", tenantID).
+		Where("name = ?", name).
+		Scan(ctx)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, fmt.Errorf("calendar %q in tenant %q: %w", name, tenantID, errNotFound)
+		}
+		return nil, fmt.Errorf("warden: get calendar by name: %w", err)
+	}
+	c, err := calendarFromModel(m)
+	if err != nil {
+		return nil, fmt.Errorf("warden: get calendar by name: %w", err)
+	}
+	return c, nil
+}
+
+func (s *Store) UpdateCalendar(ctx context.Context, c *calendar.Calendar) error {
+	c.UpdatedAt = time.Now().UTC()
+	m, err := calendarToModel(c)
+	if err != nil {
+		return fmt.Errorf("warden: update calendar: %w", err)
+	}
+	if _, err := s.sdb.NewUpdate(m).WherePK().Exec(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("calendar %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateCalendar)
+		}
+		return fmt.Errorf("warden: update calendar: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteCalendar(ctx context.Context, calID id.CalendarID) error {
+	_, err := s.sdb.NewDelete((*calendarModel)(nil)).
+		Where("id = ?", calID.String()).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: delete calendar: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListCalendars(ctx context.Context, filter *calendar.ListFilter) ([]*calendar.Calendar, error) {
+	var models []calendarModel
+	q := s.sdb.NewSelect(&models).OrderExpr("name ASC")
+	if filter != nil {
+		if filter.TenantID != "" {
+			q = q.Where("tenant_id = ?", filter.TenantID)
+		}
+		if filter.Search != "" {
+			q = q.Where("LOWER(name) LIKE LOWER(?)", "%"+filter.Search+"%")
 		}
 		if filter.Limit > 0 {
 			q = q.Limit(filter.Limit)
@@ -1450,75 +2143,209 @@ func (s *Store) ListCheckLogs(ctx context.Context, filter *checklog.QueryFilter)
 		}
 	}
 	if err := q.Scan(ctx); err != nil {
-		return nil, fmt.Errorf("warden: list check logs: %w", err)
+		return nil, fmt.Errorf("warden: list calendars: %w", err)
 	}
-	result := make([]*checklog.Entry, len(models))
+	result := make([]*calendar.Calendar, len(models))
 	for i := range models {
-		e, err := checkLogFromModel(&models[i])
+		c, err := calendarFromModel(&models[i])
 		if err != nil {
-			return nil, fmt.Errorf("warden: list check logs: %w", err)
+			return nil, fmt.Errorf("warden: list calendars: %w", err)
 		}
-		result[i] = e
+		result[i] = c
 	}
 	return result, nil
 }
 
-func (s *Store) CountCheckLogs(ctx context.Context, filter *checklog.QueryFilter) (int64, error) {
-	q := s.sdb.NewSelect((*checkLogModel)(nil))
+func (s *Store) DeleteCalendarsByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.sdb.NewDelete((*calendarModel)(nil)).
+		Where("tenant_id = ?", tenantID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: delete calendars by tenant: %w", err)
+	}
+	return nil
+}
+
+// ──────────────────────────────────────────────────
+// SoD constraint operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) CreateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	if c.ID.IsNil() {
+		c.ID = id.NewSoDConstraintID()
+	}
+	now := time.Now().UTC()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = now
+	}
+	m, err := sodConstraintToModel(c)
+	if err != nil {
+		return fmt.Errorf("warden: create sod constraint: %w", err)
+	}
+	if _, err := s.sdb.NewInsert(m).Exec(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("sod constraint %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateSoDConstraint)
+		}
+		return fmt.Errorf("warden: create sod constraint: %w", err)
+	}
+	return s.policyChanged(ctx, c.TenantID)
+}
+
+func (s *Store) GetSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) (*sod.Constraint, error) {
+	m := new(sodConstraintModel)
+	err := s.sdb.NewSelect(m).Where("id = ?", sodID.String()).Scan(ctx)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, fmt.Errorf("sod constraint %s: %w", sodID, errNotFound)
+		}
+		return nil, fmt.Errorf("warden: get sod constraint: %w", err)
+	}
+	c, err := sodConstraintFromModel(m)
+	if err != nil {
+		return nil, fmt.Errorf("warden: get sod constraint: %w", err)
+	}
+	return c, nil
+}
+
+func (s *Store) UpdateSoDConstraint(ctx context.Context, c *sod.Constraint) error {
+	c.UpdatedAt = time.Now().UTC()
+	m, err := sodConstraintToModel(c)
+	if err != nil {
+		return fmt.Errorf("warden: update sod constraint: %w", err)
+	}
+	if _, err := s.sdb.NewUpdate(m).WherePK().Exec(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("sod constraint %q in tenant %q: %w", c.Name, c.TenantID, wardenerr.ErrDuplicateSoDConstraint)
+		}
+		return fmt.Errorf("warden: update sod constraint: %w", err)
+	}
+	return s.policyChanged(ctx, c.TenantID)
+}
+
+func (s *Store) DeleteSoDConstraint(ctx context.Context, sodID id.SoDConstraintID) error {
+	before, _ := s.GetSoDConstraint(ctx, sodID) //nolint:errcheck // missing → no change to report
+	_, err := s.sdb.NewDelete((*sodConstraintModel)(nil)).
+		Where("id = ?", sodID.String()).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("warden: delete sod constraint: %w", err)
+	}
+	if before == nil {
+		return nil
+	}
+	return s.policyChanged(ctx, before.TenantID)
+}
+
+func (s *Store) ListSoDConstraints(ctx context.Context, filter *sod.ListFilter) ([]*sod.Constraint, error) {
+	var models []sodConstraintModel
+	q := s.sdb.NewSelect(&models).OrderExpr("name ASC")
 	if filter != nil {
 		if filter.TenantID != "" {
 			q = q.Where("tenant_id = ?", filter.TenantID)
 		}
-		if filter.SubjectKind != "" {
-			q = q.Where("subject_kind = ?", filter.SubjectKind)
-		}
-		if filter.SubjectID != "" {
-			q = q.Where("subject_id = ?", filter.SubjectID)
-		}
-		if filter.Action != "" {
-			q = q.Where("action = ?", filter.Action)
+		if filter.Kind != "" {
+			q = q.Where("kind = ?", string(filter.Kind))
 		}
-		if filter.ResourceType != "" {
-			q = q.Where("resource_type = ?", filter.ResourceType)
+		if filter.Role != "" {
+			q = q.Where("EXISTS (SELECT 1 FROM json_each(roles) WHERE value = ?)", filter.Role)
 		}
-		if filter.ResourceID != "" {
-			q = q.Where("resource_id = ?", filter.ResourceID)
+		if filter.Search != "" {
+			q = q.Where("LOWER(name) LIKE LOWER(?)", "%"+filter.Search+"%")
 		}
-		if filter.Decision != "" {
-			q = q.Where("decision = ?", filter.Decision)
+		if filter.Limit > 0 {
+			q = q.Limit(filter.Limit)
 		}
-		if filter.After != nil {
-			q = q.Where("created_at >= ?", *filter.After)
+		if filter.Offset > 0 {
+			q = q.Offset(filter.Offset)
 		}
-		if filter.Before != nil {
-			q = q.Where("created_at <= ?", *filter.Before)
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("warden: list sod constraints: %w", err)
+	}
+	result := make([]*sod.Constraint, len(models))
+	for i := range models {
+		c, err := sodConstraintFromModel(&models[i])
+		if err != nil {
+			return nil, fmt.Errorf("warden: list sod constraints: %w", err)
 		}
+		result[i] = c
 	}
-	count, err := q.Count(ctx)
+	return result, nil
+}
+
+func (s *Store) DeleteSoDConstraintsByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.sdb.NewDelete((*sodConstraintModel)(nil)).
+		Where("tenant_id = ?", tenantID).Exec(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("warden: count check logs: %w", err)
+		return fmt.Errorf("warden: delete sod constraints by tenant: %w", err)
 	}
-	return count, nil
+	return s.policyChanged(ctx, tenantID)
 }
 
-func (s *Store) PurgeCheckLogs(ctx context.Context, before time.Time) (int64, error) {
-	res, err := s.sdb.NewDelete((*checkLogModel)(nil)).
-		Where("created_at < ?", before).Exec(ctx)
+// ──────────────────────────────────────────────────
+// Rate counter operations
+// ──────────────────────────────────────────────────
+
+func (s *Store) IncrementCounter(ctx context.Context, key counter.Key, bucket time.Time, n int64) error {
+	m := &counterModel{
+		TenantID:    key.TenantID,
+		SubjectKind: key.SubjectKind,
+		SubjectID:   key.SubjectID,
+		Action:      key.Action,
+		Bucket:      bucket.Unix(),
+		Count:       n,
+	}
+	_, err := s.sdb.NewInsert(m).
+		OnConflict("(tenant_id, subject_kind, subject_id, action, bucket) DO UPDATE SET count = count + excluded.count").
+		Exec(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("warden: purge check logs: %w", err)
+		return fmt.Errorf("warden: increment counter: %w", err)
+	}
+	return nil
+}
+
+const sumCounterSQL = `
+SELECT COALESCE(SUM(count), 0) AS total
+FROM warden_rate_counters
+WHERE tenant_id = ?
+  AND subject_kind = ?
+  AND subject_id = ?
+  AND action = ?
+  AND bucket >= ?
+`
+
+func (s *Store) SumCounter(ctx context.Context, key counter.Key, since time.Time) (int64, error) {
+	var rows []counterSumRow
+	err := s.sdb.NewRaw(sumCounterSQL, key.TenantID, key.SubjectKind, key.SubjectID, key.Action, since.Unix()).
+		Scan(ctx, &rows)
+	if err != nil {
+		return 0, fmt.Errorf("warden: sum counter: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Total, nil
+}
+
+func (s *Store) PurgeCounters(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.sdb.NewDelete((*counterModel)(nil)).
+		Where("bucket < ?", before.Unix()).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warden: purge counters: %w", err)
 	}
 	n, err := res.RowsAffected()
 	if err != nil {
-		return 0, fmt.Errorf("warden: purge check logs rows: %w", err)
+		return 0, fmt.Errorf("warden: purge counters rows: %w", err)
 	}
 	return n, nil
 }
 
-func (s *Store) DeleteCheckLogsByTenant(ctx context.Context, tenantID string) error {
-	_, err := s.sdb.NewDelete((*checkLogModel)(nil)).
+func (s *Store) DeleteCountersByTenant(ctx context.Context, tenantID string) error {
+	_, err := s.sdb.NewDelete((*counterModel)(nil)).
 		Where("tenant_id = ?", tenantID).Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("warden: delete check logs by tenant: %w", err)
+		return fmt.Errorf("warden: delete counters by tenant: %w", err)
 	}
 	return nil
 }
@@ -1,6 +1,6 @@
 // Package store defines the aggregate persistence interface. Each subsystem
-// (role, permission, assignment, relation, policy, resourcetype, checklog)
-// defines its own store interface. The composite Store composes them all.
+// (role, permission, assignment, relation, policy, resourcetype, checklog,
+// webhook, audit, breakglass, calendar, counter, sod) defines its own store interface. The composite Store composes them all.
 // Backends: Postgres, SQLite, and Memory.
 package store
 
@@ -8,12 +8,18 @@ import (
 	"context"
 
 	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/calendar"
 	"github.com/xraph/warden/checklog"
+	"github.com/xraph/warden/counter"
 	"github.com/xraph/warden/permission"
 	"github.com/xraph/warden/policy"
 	"github.com/xraph/warden/relation"
 	"github.com/xraph/warden/resourcetype"
 	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/sod"
+	"github.com/xraph/warden/webhook"
 )
 
 // Store is the aggregate persistence interface.
@@ -27,6 +33,12 @@ type Store interface {
 	policy.Store
 	resourcetype.Store
 	checklog.Store
+	webhook.Store
+	audit.Store
+	breakglass.Store
+	calendar.Store
+	counter.Store
+	sod.Store
 
 	// Migrate runs all schema migrations.
 	Migrate(ctx context.Context) error
@@ -0,0 +1,156 @@
+package warden
+
+import (
+	"context"
+
+	"github.com/xraph/warden/plugin"
+)
+
+// Tracer is implemented by plugins that trace check evaluation. Each Check
+// produces a "warden.check" span with children for the evaluation stages
+// ("warden.rbac", "warden.rebac", "warden.abac") and for every store call
+// on the check path ("warden.store.<Method>").
+//
+// The shape mirrors OpenTelemetry's trace.Tracer, so an OTel tracer can be
+// adapted in a few lines; the observability package provides a built-in
+// implementation that needs no external services.
+type Tracer interface {
+	plugin.Plugin
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is one traced operation.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	End()
+}
+
+// Attribute is a key/value pair recorded on a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr returns an Attribute.
+func Attr(key string, value any) Attribute { return Attribute{Key: key, Value: value} }
+
+// Span names.
+const (
+	SpanCheck       = "warden.check"
+	SpanRBAC        = "warden.rbac"
+	SpanReBAC       = "warden.rebac"
+	SpanABAC        = "warden.abac"
+	SpanStorePrefix = "warden.store."
+)
+
+// Span attribute keys.
+const (
+	AttrTenantID     = "warden.tenant_id"
+	AttrSubjectKind  = "warden.subject.kind"
+	AttrAction       = "warden.action"
+	AttrResourceType = "warden.resource.type"
+	AttrDecision     = "warden.decision"
+	AttrSource       = "warden.source" // first MatchInfo source, "none" when nothing matched
+	AttrCacheHit     = "warden.cache_hit"
+	AttrGraphDepth   = "warden.graph_depth"  // deepest ReBAC graph level visited
+	AttrPolicyCount  = "warden.policy_count" // ABAC policies evaluated
+	AttrError        = "error"
+)
+
+// SpanFromContext returns the innermost span started by the engine for
+// ctx, or a no-op span when the check is not traced. Custom Evaluators and
+// GraphWalkers can use it to annotate the current stage.
+func SpanFromContext(ctx context.Context) Span {
+	if s, ok := ctx.Value(ctxKeySpan).(Span); ok {
+		return s
+	}
+	return noopSpan{}
+}
+
+// withTracers makes the engine's tracers reachable from ctx so every stage
+// of the check, including the graph walker, can start spans.
+func withTracers(ctx context.Context, tracers []Tracer) context.Context {
+	if len(tracers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKeyTracers, tracers)
+}
+
+// startSpan starts a span on every tracer attached to ctx.
+func startSpan(ctx context.Context, name string) (context.Context, Span) {
+	tracers, _ := ctx.Value(ctxKeyTracers).([]Tracer) //nolint:errcheck // absent → untraced
+	if len(tracers) == 0 {
+		return ctx, noopSpan{}
+	}
+	spans := make(multiSpan, len(tracers))
+	for i, t := range tracers {
+		ctx, spans[i] = t.Start(ctx, name)
+	}
+	var s Span = spans
+	if len(spans) == 1 {
+		s = spans[0]
+	}
+	return context.WithValue(ctx, ctxKeySpan, s), s
+}
+
+// traceStore runs one store call inside a "warden.store.<op>" span.
+func traceStore[T any](ctx context.Context, op string, call func(context.Context) (T, error)) (T, error) {
+	ctx, span := startSpan(ctx, SpanStorePrefix+op)
+	v, err := call(ctx)
+	if err != nil {
+		span.SetAttributes(Attr(AttrError, err.Error()))
+	}
+	span.End()
+	return v, err
+}
+
+// endStageSpan records the outcome of an evaluation stage and ends its span.
+func endStageSpan(span Span, result *CheckResult, err error) {
+	switch {
+	case err != nil:
+		span.SetAttributes(Attr(AttrError, err.Error()))
+	case result != nil:
+		span.SetAttributes(Attr(AttrDecision, string(result.Decision)))
+	}
+	span.End()
+}
+
+// matchSource is the evaluator that decided result, for metrics labels.
+func matchSource(result *CheckResult) string {
+	if result == nil || len(result.MatchedBy) == 0 {
+		return "none"
+	}
+	return result.MatchedBy[0].Source
+}
+
+func newTracers(reg *plugin.Registry) []Tracer {
+	if reg == nil {
+		return nil
+	}
+	var tracers []Tracer
+	for _, p := range reg.Plugins() {
+		if t, ok := p.(Tracer); ok {
+			tracers = append(tracers, t)
+		}
+	}
+	return tracers
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) End()                       {}
+
+type multiSpan []Span
+
+func (m multiSpan) SetAttributes(attrs ...Attribute) {
+	for _, s := range m {
+		s.SetAttributes(attrs...)
+	}
+}
+
+func (m multiSpan) End() {
+	for _, s := range m {
+		s.End()
+	}
+}
@@ -37,6 +37,13 @@ type Subject struct {
 	Kind       SubjectKind    `json:"kind"`
 	ID         string         `json:"id"`
 	Attributes map[string]any `json:"attributes,omitempty"`
+
+	// ActiveRoles are the role slugs the subject activated for this
+	// session. When set, only assigned roles named here grant permissions
+	// and dynamic separation-of-duties constraints apply to them. Empty
+	// activates every assigned role, so the constraints apply to all of
+	// them.
+	ActiveRoles []string `json:"active_roles,omitempty"`
 }
 
 // Resource represents the target of an authorization check.
@@ -70,6 +77,17 @@ type CheckRequest struct {
 // Allowed/Decision outcome. The engine also fires the
 // PolicyObligationFired plugin hook per obligation, so existing plugin
 // pipelines (Chronicle audit, dispatchers) get them automatically.
+//
+// StructuredObligations and Advice carry the parameterised obligations
+// and advice of matched policies whose FulfillOn applies to the final
+// decision. Structured obligation names are also listed in Obligations.
+//
+// Shadow lists the matched shadow-mode policies that would have changed
+// the decision had they been enforced. It never affects Allowed.
+//
+// Errors lists the policies whose conditions failed to evaluate. Each was
+// resolved by its OnError setting: deny makes the decision indeterminate,
+// skip ignores the policy.
 type CheckResult struct {
 	Allowed     bool        `json:"allowed"`
 	Decision    Decision    `json:"decision"`
@@ -77,6 +95,38 @@ type CheckResult struct {
 	MatchedBy   []MatchInfo `json:"matched_by,omitempty"`
 	Obligations []string    `json:"obligations,omitempty"`
 	EvalTimeNs  int64       `json:"eval_time_ns"`
+
+	StructuredObligations []Obligation `json:"structured_obligations,omitempty"`
+	Advice                []Obligation `json:"advice,omitempty"`
+
+	Shadow []ShadowDecision `json:"shadow,omitempty"`
+	Errors []PolicyError    `json:"errors,omitempty"`
+
+	// Annotations are key/value notes attached by check hook plugins.
+	// They do not affect the decision.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Trace records work done while reaching the decision, such as
+	// attribute provider lookups. Nil when there is nothing to report.
+	Trace *DecisionTrace `json:"trace,omitempty"`
+}
+
+// DecisionTrace describes how a decision was reached beyond the matched
+// rules.
+type DecisionTrace struct {
+	// Attributes lists every attribute fetched from an AttributeProvider
+	// plugin, in lookup order.
+	Attributes []AttributeLookup `json:"attributes,omitempty"`
+}
+
+// AttributeLookup is one attribute provider lookup made during a check.
+type AttributeLookup struct {
+	Field    string `json:"field"`
+	Provider string `json:"provider,omitempty"` // plugin that supplied the value or failed; "" when none did
+	Found    bool   `json:"found"`
+	Cached   bool   `json:"cached,omitempty"` // served from the cross-check attribute cache
+	Error    string `json:"error,omitempty"`  // why the lookup failed
+	TimeNs   int64  `json:"time_ns"`
 }
 
 // Decision is the authorization outcome.
@@ -101,16 +151,30 @@ const (
 	// DecisionDenyNoPerms means no role grants the required permission.
 	DecisionDenyNoPerms Decision = "deny_no_perms"
 
+	// DecisionDenySoD means the session's active roles violate a dynamic
+	// separation-of-duties constraint.
+	DecisionDenySoD Decision = "deny_sod"
+
 	// DecisionDenyCondition means an ABAC condition blocked the request.
 	DecisionDenyCondition Decision = "deny_condition"
 
 	// DecisionDenyRelation means no matching relation was found.
 	DecisionDenyRelation Decision = "deny_relation"
+
+	// DecisionDenyPlugin means a check hook plugin vetoed the request or,
+	// under plugin.FailClosed, failed.
+	DecisionDenyPlugin Decision = "deny_plugin"
+
+	// DecisionIndeterminate means the request could not be evaluated, for
+	// example because an attribute did not match its declared schema. It
+	// is never allowed.
+	DecisionIndeterminate Decision = "indeterminate"
 )
 
 // MatchInfo describes what rule matched during evaluation.
 type MatchInfo struct {
-	Source string `json:"source"` // "rbac", "abac", "rebac"
-	RuleID string `json:"rule_id,omitempty"`
-	Detail string `json:"detail,omitempty"`
+	Source     string `json:"source"` // "rbac", "abac", "rebac", "plugin"
+	RuleID     string `json:"rule_id,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Permission string `json:"permission,omitempty"` // rbac: the granting "resource:action"
 }
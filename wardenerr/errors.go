@@ -41,3 +41,24 @@ var ErrDuplicateAssignment = fmt.Errorf("warden: role already assigned to subjec
 // ErrDuplicateRelation is returned when a relation tuple already exists.
 // Wraps ErrAlreadyExists.
 var ErrDuplicateRelation = fmt.Errorf("warden: relation tuple already exists: %w", ErrAlreadyExists)
+
+// ErrDuplicateCalendar is returned when a calendar would violate the
+// (tenant_id, name) uniqueness constraint.
+var ErrDuplicateCalendar = fmt.Errorf("warden: calendar already exists in this tenant: %w", ErrAlreadyExists)
+
+// ErrDuplicateSoDConstraint is returned when a separation-of-duties
+// constraint would violate the (tenant_id, name) uniqueness constraint.
+var ErrDuplicateSoDConstraint = fmt.Errorf("warden: separation-of-duties constraint already exists in this tenant: %w", ErrAlreadyExists)
+
+// ErrNotFound is wrapped by the errors the bundled stores return for an
+// entity that does not exist. Use errors.Is(err, ErrNotFound) to tell it
+// apart from a failed read.
+var ErrNotFound = errors.New("not found")
+
+// ErrMaxMembersExceeded is returned when an assignment would give a role
+// more members than its MaxMembers.
+var ErrMaxMembersExceeded = errors.New("warden: role max members exceeded")
+
+// ErrSoDViolation is returned when an assignment would give a subject more
+// roles of a static separation-of-duties constraint than it allows.
+var ErrSoDViolation = errors.New("warden: separation of duties violated")
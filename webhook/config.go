@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Defaults applied by New to zero Config fields.
+const (
+	DefaultMaxAttempts    = 10
+	DefaultInitialBackoff = 5 * time.Second
+	DefaultMaxBackoff     = time.Hour
+	DefaultPollInterval   = 5 * time.Second
+	DefaultTimeout        = 10 * time.Second
+	DefaultBatchSize      = 100
+)
+
+// Config configures the webhook plugin. It is also the YAML form used by
+// the Forge extension under "webhooks":
+//
+//	extensions:
+//	  warden:
+//	    webhooks:
+//	      max_attempts: 8
+//	      endpoints:
+//	        - name: security
+//	          url: https://hooks.example.com/warden
+//	          secret: change-me
+//	          events: ["role.assigned", "policy.obligation_fired"]
+//	          match:
+//	            obligation: ["notify-security"]
+type Config struct {
+	// Endpoints are the delivery targets.
+	Endpoints []Endpoint `json:"endpoints" mapstructure:"endpoints" yaml:"endpoints"`
+
+	// MaxAttempts is how many times a delivery is tried before it is
+	// marked failed (default: 10).
+	MaxAttempts int `json:"max_attempts" mapstructure:"max_attempts" yaml:"max_attempts"`
+
+	// InitialBackoff is the wait after the first failed attempt; it doubles
+	// on each further failure up to MaxBackoff (defaults: 5s and 1h).
+	InitialBackoff time.Duration `json:"initial_backoff" mapstructure:"initial_backoff" yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff" mapstructure:"max_backoff" yaml:"max_backoff"`
+
+	// PollInterval is how often the dispatcher checks the outbox for due
+	// retries (default: 5s). New events are dispatched immediately.
+	PollInterval time.Duration `json:"poll_interval" mapstructure:"poll_interval" yaml:"poll_interval"`
+
+	// Timeout bounds each HTTP request (default: 10s).
+	Timeout time.Duration `json:"timeout" mapstructure:"timeout" yaml:"timeout"`
+
+	// BatchSize is the number of due deliveries loaded per poll
+	// (default: 100).
+	BatchSize int `json:"batch_size" mapstructure:"batch_size" yaml:"batch_size"`
+
+	// Retention is how long delivered and failed deliveries stay in the
+	// outbox. Zero keeps them forever.
+	Retention time.Duration `json:"retention" mapstructure:"retention" yaml:"retention"`
+}
+
+// Endpoint is one webhook target and its subscription.
+type Endpoint struct {
+	// Name identifies the endpoint in the outbox. Renaming an endpoint
+	// fails its pending deliveries.
+	Name string `json:"name" mapstructure:"name" yaml:"name"`
+
+	// URL receives the POST requests.
+	URL string `json:"url" mapstructure:"url" yaml:"url"`
+
+	// Secret keys the HMAC-SHA256 signature sent in X-Warden-Signature.
+	// Deliveries are unsigned when empty.
+	Secret string `json:"secret" mapstructure:"secret" yaml:"secret"`
+
+	// Events are the subscribed event names. Glob patterns are allowed:
+	// "policy.*" or "*".
+	Events []string `json:"events" mapstructure:"events" yaml:"events"`
+
+	// Match narrows the subscription by event data. Keys are dot paths
+	// into the envelope's "data" object ("role.slug", "obligation",
+	// "result.decision"); values are glob patterns, any of which may
+	// match. Every key must match. Array values match when any element
+	// does.
+	Match map[string][]string `json:"match" mapstructure:"match" yaml:"match"`
+
+	// Headers are added to every request. They may not set Content-Type
+	// or the X-Warden-* headers the dispatcher sends.
+	Headers map[string]string `json:"headers" mapstructure:"headers" yaml:"headers"`
+}
+
+func (c *Config) setDefaults() {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+}
+
+// Validate reports configuration errors.
+func (c *Config) Validate() error {
+	var errs []error
+	seen := make(map[string]bool, len(c.Endpoints))
+	for i, ep := range c.Endpoints {
+		name := ep.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+			errs = append(errs, fmt.Errorf("webhook endpoint %s: name is required", name))
+		} else if seen[name] {
+			errs = append(errs, fmt.Errorf("webhook endpoint %s: duplicate name", name))
+		}
+		seen[name] = true
+		if !strings.HasPrefix(ep.URL, "http://") && !strings.HasPrefix(ep.URL, "https://") {
+			errs = append(errs, fmt.Errorf("webhook endpoint %s: url must be http(s)", name))
+		}
+		if len(ep.Events) == 0 {
+			errs = append(errs, fmt.Errorf("webhook endpoint %s: at least one event is required", name))
+		}
+		for _, pattern := range ep.Events {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("webhook endpoint %s: event %q: %w", name, pattern, err))
+			}
+		}
+		for key, patterns := range ep.Match {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					errs = append(errs, fmt.Errorf("webhook endpoint %s: match %s %q: %w", name, key, pattern, err))
+				}
+			}
+		}
+		for key := range ep.Headers {
+			if reservedHeader(key) {
+				errs = append(errs, fmt.Errorf("webhook endpoint %s: header %q is set by the dispatcher", name, key))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reservedHeader reports whether name is a header the dispatcher sets on
+// every request, which an endpoint's Headers may not replace.
+func reservedHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	return name == "Content-Type" || strings.HasPrefix(name, "X-Warden-")
+}
+
+// subscribes reports whether the endpoint wants the event.
+func (ep *Endpoint) subscribes(event string) bool {
+	for _, pattern := range ep.Events {
+		if ok, _ := path.Match(pattern, event); ok { //nolint:errcheck // validated by Config.Validate
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether data satisfies every Match filter.
+func (ep *Endpoint) matches(data map[string]any) bool {
+	for key, patterns := range ep.Match {
+		if !matchValue(lookup(data, key), patterns) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup follows a dot path through nested JSON objects.
+func lookup(data map[string]any, key string) any {
+	var cur any = data
+	for part := range strings.SplitSeq(key, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+func matchValue(v any, patterns []string) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case []any:
+		for _, el := range v {
+			if matchValue(el, patterns) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		return false
+	}
+	s := fmt.Sprint(v)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, s); ok { //nolint:errcheck // validated by Config.Validate
+			return true
+		}
+	}
+	return false
+}
+
+// normalize round-trips data through JSON so Match filters see the same
+// field names and shapes as the receiver.
+func normalize(data map[string]any) (map[string]any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/xraph/go-utils/log"
+)
+
+// Start launches the background dispatcher, which delivers pending
+// deliveries (including those left from a previous run) and retries
+// failures. It is a no-op when already running.
+func (p *Plugin) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	p.stop = func() {
+		cancel()
+		<-done
+	}
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(p.cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := p.Flush(ctx); err != nil && ctx.Err() == nil {
+				p.logger.Error("webhook: dispatch failed", log.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-p.wake:
+			}
+		}
+	}()
+}
+
+// Stop stops the dispatcher and waits for an in-flight delivery to
+// finish. Pending deliveries stay in the outbox for the next Start.
+func (p *Plugin) Stop() {
+	p.mu.Lock()
+	stop := p.stop
+	p.stop = nil
+	p.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// Flush attempts every delivery that is due now and returns how many were
+// delivered. The dispatcher calls it on each poll; it is exported for
+// tests and one-shot tools.
+//
+// Deliveries are claimed a batch at a time with a lease long enough to
+// attempt the whole batch, so another replica polling the same store
+// skips them. A dispatcher that dies mid-batch leaves its unattempted
+// claims to be picked up once the lease expires.
+func (p *Plugin) Flush(ctx context.Context) (int, error) {
+	delivered := 0
+	lease := p.cfg.Timeout * time.Duration(p.cfg.BatchSize)
+	for {
+		now := p.now().UTC()
+		due, err := p.store.ClaimDueWebhookDeliveries(ctx, p.owner, now, now.Add(lease), p.cfg.BatchSize)
+		if err != nil {
+			return delivered, fmt.Errorf("webhook: claim due deliveries: %w", err)
+		}
+		for _, d := range due {
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+			ok, err := p.attempt(ctx, d)
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				delivered++
+			}
+		}
+		if len(due) < p.cfg.BatchSize {
+			break
+		}
+	}
+	return delivered, p.purge(ctx)
+}
+
+// attempt sends one delivery and records the outcome.
+func (p *Plugin) attempt(ctx context.Context, d *Delivery) (bool, error) {
+	ep, ok := p.endpoints[d.Endpoint]
+	var sendErr error
+	if ok {
+		sendErr = p.send(ctx, ep, d)
+	} else {
+		sendErr = fmt.Errorf("endpoint %q is no longer configured", d.Endpoint)
+	}
+
+	now := p.now().UTC()
+	d.Attempts++
+	d.UpdatedAt = now
+	d.LockedBy, d.LockedUntil = "", nil
+	switch {
+	case sendErr == nil:
+		d.Status = StatusDelivered
+		d.LastError = ""
+	case !ok || d.Attempts >= p.cfg.MaxAttempts:
+		d.Status = StatusFailed
+		d.LastError = sendErr.Error()
+	default:
+		d.LastError = sendErr.Error()
+		d.NextAttemptAt = now.Add(p.backoff(d.Attempts))
+	}
+	if sendErr != nil {
+		p.logger.Warn("webhook: delivery failed",
+			log.String("delivery", d.ID.String()),
+			log.String("endpoint", d.Endpoint),
+			log.String("event", d.Event),
+			log.Int("attempt", d.Attempts),
+			log.String("status", string(d.Status)),
+			log.String("error", sendErr.Error()),
+		)
+	}
+	if err := p.store.UpdateWebhookDelivery(ctx, p.owner, d); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			// The attempt outlasted the lease and the delivery may have been
+			// claimed again; that claim's outcome is the one recorded.
+			p.logger.Warn("webhook: delivery lease lost",
+				log.String("delivery", d.ID.String()),
+				log.String("endpoint", d.Endpoint),
+			)
+			return sendErr == nil, nil
+		}
+		return false, fmt.Errorf("webhook: update delivery %s: %w", d.ID, err)
+	}
+	return sendErr == nil, nil
+}
+
+// send POSTs the delivery payload. Any 2xx response is success.
+func (p *Plugin) send(ctx context.Context, ep *Endpoint, d *Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	// Custom headers go first so they can replace the User-Agent but never
+	// the headers receivers rely on to verify the delivery.
+	req.Header.Set("User-Agent", "warden-webhook")
+	for k, v := range ep.Headers {
+		req.Header.Set(k, v)
+	}
+	ts := p.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	if ep.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(ep.Secret, ts, d.Payload))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck // drain for connection reuse
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return nil
+}
+
+// backoff is the wait before the next attempt after n failures:
+// InitialBackoff doubled n-1 times, capped at MaxBackoff.
+func (p *Plugin) backoff(n int) time.Duration {
+	d := p.cfg.InitialBackoff
+	for i := 1; i < n && d < p.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.cfg.MaxBackoff)
+}
+
+// purge removes finished deliveries past Retention, at most hourly.
+func (p *Plugin) purge(ctx context.Context) error {
+	if p.cfg.Retention <= 0 {
+		return nil
+	}
+	now := p.now()
+	p.mu.Lock()
+	if now.Sub(p.lastPurge) < time.Hour {
+		p.mu.Unlock()
+		return nil
+	}
+	p.lastPurge = now
+	p.mu.Unlock()
+	if _, err := p.store.PurgeWebhookDeliveries(ctx, now.Add(-p.cfg.Retention)); err != nil {
+		return fmt.Errorf("webhook: purge deliveries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/xraph/go-utils/log"
+
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/breakglass"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/permission"
+	"github.com/xraph/warden/plugin"
+	"github.com/xraph/warden/policy"
+	"github.com/xraph/warden/relation"
+	"github.com/xraph/warden/role"
+)
+
+// Compile-time interface checks.
+var (
+	_ plugin.AfterCheck            = (*Plugin)(nil)
+	_ plugin.RoleCreated           = (*Plugin)(nil)
+	_ plugin.RoleUpdated           = (*Plugin)(nil)
+	_ plugin.RoleDeleted           = (*Plugin)(nil)
+	_ plugin.PermissionCreated     = (*Plugin)(nil)
+	_ plugin.PermissionDeleted     = (*Plugin)(nil)
+	_ plugin.PermissionAttached    = (*Plugin)(nil)
+	_ plugin.PermissionDetached    = (*Plugin)(nil)
+	_ plugin.RoleAssigned          = (*Plugin)(nil)
+	_ plugin.RoleUnassigned        = (*Plugin)(nil)
+	_ plugin.RelationWritten       = (*Plugin)(nil)
+	_ plugin.RelationDeleted       = (*Plugin)(nil)
+	_ plugin.PolicyCreated         = (*Plugin)(nil)
+	_ plugin.PolicyUpdated         = (*Plugin)(nil)
+	_ plugin.PolicyDeleted         = (*Plugin)(nil)
+	_ plugin.PolicyObligationFired = (*Plugin)(nil)
+	_ plugin.PolicyShadowDecision  = (*Plugin)(nil)
+	_ plugin.BreakGlassActivated   = (*Plugin)(nil)
+	_ plugin.BreakGlassUsed        = (*Plugin)(nil)
+	_ plugin.BreakGlassRevoked     = (*Plugin)(nil)
+	_ plugin.Shutdown              = (*Plugin)(nil)
+)
+
+// roleGetter is implemented by stores that can resolve a role, used to add
+// the role to assignment events so endpoints can filter on "role.slug".
+type roleGetter interface {
+	GetRole(ctx context.Context, roleID id.RoleID) (*role.Role, error)
+}
+
+// Plugin writes subscribed events to the outbox and delivers them. Create
+// it with New and call Start to run the dispatcher.
+type Plugin struct {
+	store     Store
+	cfg       Config
+	endpoints map[string]*Endpoint
+	client    *http.Client
+	logger    log.Logger
+	now       func() time.Time
+	owner     string // identifies this dispatcher's leases in the outbox
+
+	wake chan struct{}
+
+	mu        sync.Mutex
+	stop      func()
+	lastPurge time.Time
+}
+
+// Option configures the Plugin.
+type Option func(*Plugin)
+
+// WithHTTPClient sets the client used for deliveries.
+func WithHTTPClient(c *http.Client) Option { return func(p *Plugin) { p.client = c } }
+
+// WithLogger sets the logger used for delivery failures.
+func WithLogger(l log.Logger) Option { return func(p *Plugin) { p.logger = l } }
+
+// New returns a webhook plugin using s as its outbox. Every warden store
+// backend implements Store.
+func New(s Store, cfg Config, opts ...Option) (*Plugin, error) {
+	if s == nil {
+		return nil, errors.New("webhook: store is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.setDefaults()
+	p := &Plugin{
+		store:     s,
+		cfg:       cfg,
+		endpoints: make(map[string]*Endpoint, len(cfg.Endpoints)),
+		client:    http.DefaultClient,
+		logger:    log.NewNoopLogger(),
+		now:       time.Now,
+		owner:     rand.Text(),
+		wake:      make(chan struct{}, 1),
+	}
+	for i := range p.cfg.Endpoints {
+		ep := &p.cfg.Endpoints[i]
+		p.endpoints[ep.Name] = ep
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// Name implements plugin.Plugin.
+func (p *Plugin) Name() string { return "webhook" }
+
+// publish writes one outbox delivery per endpoint that subscribes to the
+// event and whose filters match data.
+func (p *Plugin) publish(ctx context.Context, event string, data map[string]any) error {
+	var targets []*Endpoint
+	filtered := false
+	for i := range p.cfg.Endpoints {
+		ep := &p.cfg.Endpoints[i]
+		if ep.subscribes(event) {
+			targets = append(targets, ep)
+			filtered = filtered || len(ep.Match) > 0
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	data, err := normalize(data)
+	if err != nil {
+		return fmt.Errorf("webhook: encode %s: %w", event, err)
+	}
+	if actor, ok := audit.ActorFromContext(ctx); ok && data != nil {
+		if _, set := data["actor"]; !set {
+			data["actor"] = actor.String()
+		}
+	}
+
+	now := p.now().UTC()
+	var errs []error
+	for _, ep := range targets {
+		if filtered && !ep.matches(data) {
+			continue
+		}
+		d := &Delivery{
+			ID:            id.NewWebhookDeliveryID(),
+			Endpoint:      ep.Name,
+			Event:         event,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		d.Payload, err = json.Marshal(Envelope{ID: d.ID.String(), Event: event, CreatedAt: now, Data: data})
+		if err != nil {
+			return fmt.Errorf("webhook: encode %s: %w", event, err)
+		}
+		if err := p.store.CreateWebhookDelivery(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: enqueue %s for %s: %w", event, ep.Name, err))
+		}
+	}
+	p.notify()
+	return errors.Join(errs...)
+}
+
+// notify wakes the dispatcher without blocking.
+func (p *Plugin) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ──────────────────────────────────────────────────
+// Hooks
+// ──────────────────────────────────────────────────
+
+// OnAfterCheck implements plugin.AfterCheck.
+func (p *Plugin) OnAfterCheck(ctx context.Context, req, result any) error {
+	return p.publish(ctx, EventCheckCompleted, map[string]any{"request": req, "result": result})
+}
+
+// OnRoleCreated implements plugin.RoleCreated.
+func (p *Plugin) OnRoleCreated(ctx context.Context, r *role.Role) error {
+	return p.publish(ctx, EventRoleCreated, map[string]any{"role": r})
+}
+
+// OnRoleUpdated implements plugin.RoleUpdated.
+func (p *Plugin) OnRoleUpdated(ctx context.Context, r *role.Role) error {
+	return p.publish(ctx, EventRoleUpdated, map[string]any{"role": r})
+}
+
+// OnRoleDeleted implements plugin.RoleDeleted.
+func (p *Plugin) OnRoleDeleted(ctx context.Context, roleID id.RoleID) error {
+	return p.publish(ctx, EventRoleDeleted, map[string]any{"role_id": roleID})
+}
+
+// OnPermissionCreated implements plugin.PermissionCreated.
+func (p *Plugin) OnPermissionCreated(ctx context.Context, perm *permission.Permission) error {
+	return p.publish(ctx, EventPermissionCreated, map[string]any{"permission": perm})
+}
+
+// OnPermissionDeleted implements plugin.PermissionDeleted.
+func (p *Plugin) OnPermissionDeleted(ctx context.Context, permID id.PermissionID) error {
+	return p.publish(ctx, EventPermissionDeleted, map[string]any{"permission_id": permID})
+}
+
+// OnPermissionAttached implements plugin.PermissionAttached.
+func (p *Plugin) OnPermissionAttached(ctx context.Context, roleID id.RoleID, permID id.PermissionID) error {
+	return p.publish(ctx, EventPermissionAttached, map[string]any{"role_id": roleID, "permission_id": permID})
+}
+
+// OnPermissionDetached implements plugin.PermissionDetached.
+func (p *Plugin) OnPermissionDetached(ctx context.Context, roleID id.RoleID, permID id.PermissionID) error {
+	return p.publish(ctx, EventPermissionDetached, map[string]any{"role_id": roleID, "permission_id": permID})
+}
+
+// OnRoleAssigned implements plugin.RoleAssigned.
+func (p *Plugin) OnRoleAssigned(ctx context.Context, a *assignment.Assignment) error {
+	return p.publish(ctx, EventRoleAssigned, p.assignmentData(ctx, a))
+}
+
+// OnRoleUnassigned implements plugin.RoleUnassigned.
+func (p *Plugin) OnRoleUnassigned(ctx context.Context, a *assignment.Assignment) error {
+	return p.publish(ctx, EventRoleUnassigned, p.assignmentData(ctx, a))
+}
+
+// OnRelationWritten implements plugin.RelationWritten.
+func (p *Plugin) OnRelationWritten(ctx context.Context, t *relation.Tuple) error {
+	return p.publish(ctx, EventRelationWritten, map[string]any{"relation": t})
+}
+
+// OnRelationDeleted implements plugin.RelationDeleted.
+func (p *Plugin) OnRelationDeleted(ctx context.Context, relID id.RelationID) error {
+	return p.publish(ctx, EventRelationDeleted, map[string]any{"relation_id": relID})
+}
+
+// OnPolicyCreated implements plugin.PolicyCreated.
+func (p *Plugin) OnPolicyCreated(ctx context.Context, pol *policy.Policy) error {
+	return p.publish(ctx, EventPolicyCreated, map[string]any{"policy": pol})
+}
+
+// OnPolicyUpdated implements plugin.PolicyUpdated.
+func (p *Plugin) OnPolicyUpdated(ctx context.Context, pol *policy.Policy) error {
+	return p.publish(ctx, EventPolicyUpdated, map[string]any{"policy": pol})
+}
+
+// OnPolicyDeleted implements plugin.PolicyDeleted.
+func (p *Plugin) OnPolicyDeleted(ctx context.Context, polID id.PolicyID) error {
+	return p.publish(ctx, EventPolicyDeleted, map[string]any{"policy_id": polID})
+}
+
+// OnPolicyObligationFired implements plugin.PolicyObligationFired.
+func (p *Plugin) OnPolicyObligationFired(ctx context.Context, polID id.PolicyID, obligation string, req, result any) error {
+	return p.publish(ctx, EventPolicyObligation, map[string]any{
+		"policy_id":  polID,
+		"obligation": obligation,
+		"request":    req,
+		"result":     result,
+	})
+}
+
+// OnPolicyShadowDecision implements plugin.PolicyShadowDecision.
+func (p *Plugin) OnPolicyShadowDecision(ctx context.Context, polID id.PolicyID, shadow, req, result any) error {
+	return p.publish(ctx, EventPolicyShadow, map[string]any{
+		"policy_id": polID,
+		"shadow":    shadow,
+		"request":   req,
+		"result":    result,
+	})
+}
+
+// OnBreakGlassActivated implements plugin.BreakGlassActivated.
+func (p *Plugin) OnBreakGlassActivated(ctx context.Context, g *breakglass.Grant) error {
+	return p.publish(ctx, EventBreakGlassOn, map[string]any{"grant": g})
+}
+
+// OnBreakGlassUsed implements plugin.BreakGlassUsed.
+func (p *Plugin) OnBreakGlassUsed(ctx context.Context, g *breakglass.Grant, req, result any) error {
+	return p.publish(ctx, EventBreakGlassUsed, map[string]any{
+		"grant":   g,
+		"request": req,
+		"result":  result,
+	})
+}
+
+// OnBreakGlassRevoked implements plugin.BreakGlassRevoked.
+func (p *Plugin) OnBreakGlassRevoked(ctx context.Context, g *breakglass.Grant) error {
+	return p.publish(ctx, EventBreakGlassRevoked, map[string]any{"grant": g})
+}
+
+// OnShutdown implements plugin.Shutdown by stopping the dispatcher.
+func (p *Plugin) OnShutdown(context.Context) error {
+	p.Stop()
+	return nil
+}
+
+// assignmentData includes the assigned role when the store can resolve it.
+func (p *Plugin) assignmentData(ctx context.Context, a *assignment.Assignment) map[string]any {
+	data := map[string]any{"assignment": a}
+	if rg, ok := p.store.(roleGetter); ok {
+		if r, err := rg.GetRole(ctx, a.RoleID); err == nil {
+			data["role"] = r
+		}
+	}
+	return data
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Request headers set on every delivery.
+const (
+	HeaderEvent     = "X-Warden-Event"
+	HeaderDelivery  = "X-Warden-Delivery"
+	HeaderTimestamp = "X-Warden-Timestamp"
+	HeaderSignature = "X-Warden-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the X-Warden-Signature value for body sent at timestamp
+// (Unix seconds): "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed by secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for body and the timestamp
+// header value. Receivers should also reject stale timestamps to limit
+// replays.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLeaseLost is returned by UpdateWebhookDelivery when the dispatcher's
+// lease on a delivery expired or was taken over before the outcome of its
+// attempt was recorded.
+var ErrLeaseLost = errors.New("webhook: delivery lease lost")
+
+// Store defines persistence operations for the webhook outbox.
+type Store interface {
+	// CreateWebhookDelivery adds a delivery to the outbox.
+	CreateWebhookDelivery(ctx context.Context, d *Delivery) error
+
+	// ClaimDueWebhookDeliveries atomically claims up to limit pending
+	// deliveries whose NextAttemptAt is not after now and whose LockedUntil
+	// has passed, oldest first, setting their LockedBy to owner and their
+	// LockedUntil to lockUntil. Dispatchers sharing the store never claim
+	// the same delivery while its lease lasts.
+	ClaimDueWebhookDeliveries(ctx context.Context, owner string, now, lockUntil time.Time, limit int) ([]*Delivery, error)
+
+	// UpdateWebhookDelivery persists the outcome of a delivery attempt,
+	// including its LockedBy and LockedUntil, if owner still holds the
+	// delivery's lease at d.UpdatedAt. Otherwise the delivery is left as
+	// it is, for the dispatcher that may have claimed it since, and
+	// ErrLeaseLost is returned.
+	UpdateWebhookDelivery(ctx context.Context, owner string, d *Delivery) error
+
+	// PurgeWebhookDeliveries removes delivered and failed deliveries last
+	// updated before the given time.
+	PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
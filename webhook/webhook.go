@@ -0,0 +1,95 @@
+// Package webhook is a first-party Warden plugin that delivers
+// authorization events to HTTP endpoints.
+//
+// Each configured Endpoint subscribes to events (role.assigned,
+// policy.updated, policy.obligation_fired, …) and may narrow them with
+// field filters. Matching events are written to a persistent outbox in the
+// store and POSTed as HMAC-signed JSON by a background dispatcher, which
+// retries failures with exponential backoff. Because the outbox lives in
+// the store, events survive restarts; delivery is at-least-once, so
+// receivers should de-duplicate on the X-Warden-Delivery header. Replicas
+// sharing a store claim due deliveries with a lease, so each attempt is
+// made by one dispatcher.
+//
+//	wh, _ := webhook.New(s, webhook.Config{Endpoints: []webhook.Endpoint{{
+//	    Name:   "security",
+//	    URL:    "https://hooks.example.com/warden",
+//	    Secret: os.Getenv("WARDEN_WEBHOOK_SECRET"),
+//	    Events: []string{"role.assigned", "policy.obligation_fired"},
+//	    Match:  map[string][]string{"role.slug": {"admin", "*-admin"}},
+//	}}})
+//	eng, _ := warden.NewEngine(warden.WithStore(s), warden.WithPlugin(wh))
+//	wh.Start()
+//	defer eng.Stop(ctx)
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/xraph/warden/id"
+)
+
+// Event names, one per plugin hook.
+const (
+	EventCheckCompleted     = "check.completed"
+	EventRoleCreated        = "role.created"
+	EventRoleUpdated        = "role.updated"
+	EventRoleDeleted        = "role.deleted"
+	EventPermissionCreated  = "permission.created"
+	EventPermissionDeleted  = "permission.deleted"
+	EventPermissionAttached = "permission.attached"
+	EventPermissionDetached = "permission.detached"
+	EventRoleAssigned       = "role.assigned"
+	EventRoleUnassigned     = "role.unassigned"
+	EventRelationWritten    = "relation.written"
+	EventRelationDeleted    = "relation.deleted"
+	EventPolicyCreated      = "policy.created"
+	EventPolicyUpdated      = "policy.updated"
+	EventPolicyDeleted      = "policy.deleted"
+	EventPolicyObligation   = "policy.obligation_fired"
+	EventPolicyShadow       = "policy.shadow_decision"
+	EventBreakGlassOn       = "break_glass.activated"
+	EventBreakGlassUsed     = "break_glass.used"
+	EventBreakGlassRevoked  = "break_glass.revoked"
+)
+
+// Status is the state of an outbox Delivery.
+type Status string
+
+const (
+	// StatusPending deliveries are waiting for their next attempt.
+	StatusPending Status = "pending"
+
+	// StatusDelivered deliveries were accepted with a 2xx response.
+	StatusDelivered Status = "delivered"
+
+	// StatusFailed deliveries exhausted their attempts, or their endpoint
+	// was removed from the configuration.
+	StatusFailed Status = "failed"
+)
+
+// Delivery is one event queued for one endpoint in the outbox.
+type Delivery struct {
+	ID            id.WebhookDeliveryID `json:"id" db:"id"`
+	Endpoint      string               `json:"endpoint" db:"endpoint"`
+	Event         string               `json:"event" db:"event"`
+	Payload       json.RawMessage      `json:"payload" db:"payload"`
+	Status        Status               `json:"status" db:"status"`
+	Attempts      int                  `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time            `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string               `json:"last_error,omitempty" db:"last_error"`
+	LockedBy      string               `json:"locked_by,omitempty" db:"locked_by"`       // dispatcher holding the lease
+	LockedUntil   *time.Time           `json:"locked_until,omitempty" db:"locked_until"` // claim lease; nil when unclaimed
+	CreatedAt     time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// Envelope is the JSON body POSTed to endpoints and stored as the
+// Delivery payload.
+type Envelope struct {
+	ID        string         `json:"id"`
+	Event     string         `json:"event"`
+	CreatedAt time.Time      `json:"created_at"`
+	Data      map[string]any `json:"data"`
+}
@@ -0,0 +1,250 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xraph/warden"
+	"github.com/xraph/warden/assignment"
+	"github.com/xraph/warden/audit"
+	"github.com/xraph/warden/id"
+	"github.com/xraph/warden/role"
+	"github.com/xraph/warden/store/memory"
+	"github.com/xraph/warden/webhook"
+)
+
+// receiver records webhook requests and answers with the queued status
+// codes (200 once they run out).
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	got      chan struct{}
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	t.Helper()
+	rc := &receiver{statuses: statuses, got: make(chan struct{}, 16)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck // test server
+		rc.mu.Lock()
+		rc.requests = append(rc.requests, r)
+		rc.bodies = append(rc.bodies, body)
+		status := http.StatusOK
+		if len(rc.statuses) > 0 {
+			status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+		}
+		rc.mu.Unlock()
+		w.WriteHeader(status)
+		rc.got <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return rc, srv
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+func TestWebhook_SignedFilteredDelivery(t *testing.T) {
+	ctx := context.Background()
+	rc, srv := newReceiver(t)
+	s := memory.New()
+	admin := &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: "Admin", Slug: "admin"}
+	viewer := &role.Role{ID: id.NewRoleID(), TenantID: "t1", Name: "Viewer", Slug: "viewer"}
+	_ = s.CreateRole(ctx, admin)
+	_ = s.CreateRole(ctx, viewer)
+
+	wh, err := webhook.New(s, webhook.Config{Endpoints: []webhook.Endpoint{{
+		Name:    "security",
+		URL:     srv.URL,
+		Secret:  "s3cret",
+		Events:  []string{"role.*"},
+		Match:   map[string][]string{"role.slug": {"admin", "*-admin"}},
+		Headers: map[string]string{"Authorization": "Bearer t"},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eng, err := warden.NewEngine(warden.WithStore(s), warden.WithPlugin(wh))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eng.Plugins().EmitRoleAssigned(ctx, &assignment.Assignment{TenantID: "t1", RoleID: viewer.ID, SubjectKind: "user", SubjectID: "u1"})
+	actorCtx := audit.WithActor(ctx, audit.Actor{Kind: "user", ID: "alice"})
+	eng.Plugins().EmitRoleAssigned(actorCtx, &assignment.Assignment{TenantID: "t1", RoleID: admin.ID, SubjectKind: "user", SubjectID: "u2"})
+	eng.Plugins().EmitPolicyDeleted(ctx, id.NewPolicyID()) // not subscribed
+
+	n, err := wh.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || rc.count() != 1 {
+		t.Fatalf("expected only the admin assignment delivered, got %d (%d requests)", n, rc.count())
+	}
+
+	req, body := rc.requests[0], rc.bodies[0]
+	if req.Header.Get(webhook.HeaderEvent) != webhook.EventRoleAssigned {
+		t.Fatalf("event header = %q", req.Header.Get(webhook.HeaderEvent))
+	}
+	if req.Header.Get("Authorization") != "Bearer t" {
+		t.Fatalf("custom header not sent: %v", req.Header)
+	}
+	if !webhook.Verify("s3cret", req.Header.Get(webhook.HeaderTimestamp), body, req.Header.Get(webhook.HeaderSignature)) {
+		t.Fatal("signature does not verify")
+	}
+	if webhook.Verify("wrong", req.Header.Get(webhook.HeaderTimestamp), body, req.Header.Get(webhook.HeaderSignature)) {
+		t.Fatal("signature verified with the wrong secret")
+	}
+	var env webhook.Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.ID != req.Header.Get(webhook.HeaderDelivery) {
+		t.Fatalf("envelope id %q != delivery header %q", env.ID, req.Header.Get(webhook.HeaderDelivery))
+	}
+	if a, _ := env.Data["assignment"].(map[string]any); a["subject_id"] != "u2" {
+		t.Fatalf("unexpected data %v", env.Data)
+	}
+	if env.Data["actor"] != "user:alice" {
+		t.Fatalf("actor = %v, want user:alice", env.Data["actor"])
+	}
+}
+
+func TestWebhook_RetriesSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	rc, srv := newReceiver(t, http.StatusServiceUnavailable)
+	s := memory.New()
+	cfg := webhook.Config{
+		InitialBackoff: 20 * time.Millisecond,
+		PollInterval:   10 * time.Millisecond,
+		Endpoints: []webhook.Endpoint{{
+			Name:   "ops",
+			URL:    srv.URL,
+			Events: []string{webhook.EventPolicyObligation},
+			Match:  map[string][]string{"obligation": {"notify-security"}},
+		}},
+	}
+
+	first, err := webhook.New(s, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = first.OnPolicyObligationFired(ctx, id.NewPolicyID(), "notify-security", map[string]string{"action": "delete"}, nil)
+	_ = first.OnPolicyObligationFired(ctx, id.NewPolicyID(), "log", nil, nil)
+
+	// The first attempt fails and is rescheduled with backoff.
+	if n, err := first.Flush(ctx); err != nil || n != 0 {
+		t.Fatalf("Flush = %d, %v; want 0 delivered", n, err)
+	}
+	if due, _ := s.ClaimDueWebhookDeliveries(ctx, "probe", time.Now(), time.Now(), 10); len(due) != 0 {
+		t.Fatalf("failed delivery should wait for its backoff, got %d due", len(due))
+	}
+
+	// A new plugin on the same store (a restarted process) picks the
+	// delivery up from the outbox once the backoff has passed.
+	second, err := webhook.New(s, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Start()
+
+	deadline := time.After(2 * time.Second)
+	for rc.count() < 2 {
+		select {
+		case <-rc.got:
+		case <-deadline:
+			t.Fatalf("retry not delivered; %d requests", rc.count())
+		}
+	}
+	second.Stop()
+	if rc.requests[0].Header.Get(webhook.HeaderDelivery) != rc.requests[1].Header.Get(webhook.HeaderDelivery) {
+		t.Fatal("retry should reuse the delivery ID")
+	}
+	if rc.requests[1].Header.Get(webhook.HeaderSignature) != "" {
+		t.Fatal("endpoints without a secret must not be signed")
+	}
+}
+
+func TestWebhook_ReplicasDeliverOnce(t *testing.T) {
+	ctx := context.Background()
+	rc, srv := newReceiver(t)
+	s := memory.New()
+	cfg := webhook.Config{
+		BatchSize: 2,
+		Endpoints: []webhook.Endpoint{{Name: "ops", URL: srv.URL, Events: []string{"*"}}},
+	}
+
+	var replicas []*webhook.Plugin
+	for range 2 {
+		wh, err := webhook.New(s, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicas = append(replicas, wh)
+	}
+	const events = 10
+	for range events {
+		_ = replicas[0].OnPolicyObligationFired(ctx, id.NewPolicyID(), "notify", nil, nil)
+	}
+
+	var wg sync.WaitGroup
+	for _, wh := range replicas {
+		wg.Go(func() {
+			if _, err := wh.Flush(ctx); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if rc.count() != events {
+		t.Fatalf("expected %d requests, got %d", events, rc.count())
+	}
+	seen := make(map[string]bool, events)
+	for _, req := range rc.requests {
+		d := req.Header.Get(webhook.HeaderDelivery)
+		if seen[d] {
+			t.Fatalf("delivery %s sent twice", d)
+		}
+		seen[d] = true
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := webhook.Config{Endpoints: []webhook.Endpoint{
+		{Name: "a", URL: "https://example.com", Events: []string{"role.*"}},
+		{Name: "a", URL: "ftp://example.com", Events: []string{"["}},
+		{URL: "https://example.com"},
+	}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation errors")
+	}
+	if _, err := webhook.New(nil, webhook.Config{}); err == nil {
+		t.Fatal("expected an error for a nil store")
+	}
+
+	// Custom headers may not replace the ones receivers verify.
+	headers := func(h map[string]string) error {
+		cfg := webhook.Config{Endpoints: []webhook.Endpoint{{Name: "a", URL: "https://example.com", Events: []string{"*"}, Headers: h}}}
+		return cfg.Validate()
+	}
+	for _, name := range []string{"content-type", webhook.HeaderSignature, "x-warden-timestamp"} {
+		if err := headers(map[string]string{name: "x"}); err == nil {
+			t.Errorf("expected header %q to be rejected", name)
+		}
+	}
+	if err := headers(map[string]string{"Authorization": "Bearer t", "User-Agent": "acme"}); err != nil {
+		t.Errorf("expected custom headers to be accepted, got %v", err)
+	}
+}